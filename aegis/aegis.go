@@ -0,0 +1,327 @@
+// Package aegis reads and writes the encrypted vault backups of Aegis
+// Authenticator, allowing TOTP secrets to be moved to and from the app.
+//
+// An encrypted vault is a JSON document whose database, holding the entries,
+// is encrypted using AES-256-GCM by a random master key. The master key is
+// in turn encrypted by each of the header slots, of which only password slots
+// are supported, deriving their key from the password using scrypt:
+//
+//	{"version": 1, "header": {"slots": [...], "params": {...}}, "db": "<base64>"}
+package aegis
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	vaultVersion = 1
+	dbVersion    = 2
+
+	// slotTypePassword is the type of the slots encrypting the master key
+	// by a key derived from a password.
+	slotTypePassword = 1
+
+	// scrypt parameters, matching the ones used by the app.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+
+	keySize  = 32
+	saltSize = 32
+)
+
+var (
+	ErrUnsupportedVault = errors.New("unsupported aegis vault")
+	ErrWrongPassword    = errors.New("wrong password or corrupted aegis vault")
+)
+
+// Entry is a TOTP entry of a vault.
+type Entry struct {
+	Name      string // Name is the account name.
+	Issuer    string
+	Secret    []byte
+	Algorithm string // Algorithm is one of SHA1, SHA256 or SHA512.
+	Digits    int
+	Period    int // Period is the code period, in seconds.
+}
+
+type vault struct {
+	Version int    `json:"version"`
+	Header  header `json:"header"`
+	DB      string `json:"db"`
+}
+
+type header struct {
+	Slots  []slot    `json:"slots"`
+	Params keyParams `json:"params"`
+}
+
+type slot struct {
+	Type      int       `json:"type"`
+	UUID      string    `json:"uuid"`
+	Key       string    `json:"key"`
+	KeyParams keyParams `json:"key_params"`
+	N         int       `json:"n"`
+	R         int       `json:"r"`
+	P         int       `json:"p"`
+	Salt      string    `json:"salt"`
+	Repaired  bool      `json:"repaired"`
+	IsBackup  bool      `json:"is_backup"`
+}
+
+// keyParams are the nonce and tag of an AES-256-GCM ciphertext, hex encoded.
+type keyParams struct {
+	Nonce string `json:"nonce"`
+	Tag   string `json:"tag"`
+}
+
+type db struct {
+	Version int       `json:"version"`
+	Entries []dbEntry `json:"entries"`
+}
+
+type dbEntry struct {
+	Type     string  `json:"type"`
+	UUID     string  `json:"uuid"`
+	Name     string  `json:"name"`
+	Issuer   string  `json:"issuer"`
+	Note     string  `json:"note"`
+	Favorite bool    `json:"favorite"`
+	Icon     *string `json:"icon"`
+	Info     info    `json:"info"`
+}
+
+type info struct {
+	Secret string `json:"secret"`
+	Algo   string `json:"algo"`
+	Digits int    `json:"digits"`
+	Period int    `json:"period"`
+}
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Encrypt returns the entries as an encrypted vault,
+// with a single slot unlocked by password.
+func Encrypt(password string, entries []Entry) ([]byte, error) {
+	d := db{Version: dbVersion, Entries: make([]dbEntry, 0, len(entries))}
+
+	for _, e := range entries {
+		d.Entries = append(d.Entries, dbEntry{
+			Type:   "totp",
+			UUID:   newUUID(),
+			Name:   e.Name,
+			Issuer: e.Issuer,
+			Info: info{
+				Secret: secretEncoding.EncodeToString(e.Secret),
+				Algo:   e.Algorithm,
+				Digits: e.Digits,
+				Period: e.Period,
+			},
+		})
+	}
+
+	plaintext, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	salt, masterKey := make([]byte, saltSize), make([]byte, keySize)
+	_, _ = rand.Read(salt)
+	_, _ = rand.Read(masterKey)
+
+	key, err := scrypt(password, salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	encKey, keyParams, err := seal(key, masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encDB, dbParams, err := seal(masterKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	v := vault{
+		Version: vaultVersion,
+		Header: header{
+			Slots: []slot{{
+				Type:      slotTypePassword,
+				UUID:      newUUID(),
+				Key:       hex.EncodeToString(encKey),
+				KeyParams: keyParams,
+				N:         scryptN,
+				R:         scryptR,
+				P:         scryptP,
+				Salt:      hex.EncodeToString(salt),
+				Repaired:  true,
+			}},
+			Params: dbParams,
+		},
+		DB: base64.StdEncoding.EncodeToString(encDB),
+	}
+
+	return json.MarshalIndent(v, "", "    ")
+}
+
+// Decrypt returns the TOTP entries of the encrypted vault,
+// unlocking it using the first password slot password opens.
+func Decrypt(password string, data []byte) ([]Entry, error) {
+	var v vault
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedVault, err)
+	}
+
+	if v.Version != vaultVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupportedVault, v.Version)
+	}
+
+	masterKey, err := unlock(password, v.Header.Slots)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(v.DB)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedVault, err)
+	}
+
+	plaintext, err := open(masterKey, ciphertext, v.Header.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	var d db
+	if err := json.Unmarshal(plaintext, &d); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedVault, err)
+	}
+
+	entries := make([]Entry, 0, len(d.Entries))
+
+	for _, e := range d.Entries {
+		if e.Type != "totp" {
+			continue
+		}
+
+		secret, err := secretEncoding.DecodeString(strings.TrimRight(strings.ToUpper(e.Info.Secret), "="))
+		if err != nil {
+			return nil, fmt.Errorf("%w: entry %q: %v", ErrUnsupportedVault, e.Name, err)
+		}
+
+		entries = append(entries, Entry{
+			Name:      e.Name,
+			Issuer:    e.Issuer,
+			Secret:    secret,
+			Algorithm: e.Info.Algo,
+			Digits:    e.Info.Digits,
+			Period:    e.Info.Period,
+		})
+	}
+
+	return entries, nil
+}
+
+// unlock returns the master key decrypted by the first password slot
+// that password opens.
+func unlock(password string, slots []slot) ([]byte, error) {
+	for _, s := range slots {
+		if s.Type != slotTypePassword {
+			continue
+		}
+
+		salt, err := hex.DecodeString(s.Salt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedVault, err)
+		}
+
+		key, err := scrypt(password, salt, s.N, s.R, s.P, keySize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedVault, err)
+		}
+
+		encKey, err := hex.DecodeString(s.Key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedVault, err)
+		}
+
+		if masterKey, err := open(key, encKey, s.KeyParams); err == nil {
+			return masterKey, nil
+		}
+	}
+
+	return nil, ErrWrongPassword
+}
+
+// seal encrypts plaintext using AES-256-GCM,
+// returning the ciphertext and its params separately.
+func seal(key, plaintext []byte) ([]byte, keyParams, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, keyParams{}, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, _ = rand.Read(nonce)
+
+	sealed := aead.Seal(nil, nonce, plaintext, nil)
+	ciphertext, tag := sealed[:len(plaintext)], sealed[len(plaintext):]
+
+	return ciphertext, keyParams{Nonce: hex.EncodeToString(nonce), Tag: hex.EncodeToString(tag)}, nil
+}
+
+// open decrypts the ciphertext sealed using params.
+func open(key, ciphertext []byte, params keyParams) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := hex.DecodeString(params.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrUnsupportedVault)
+	}
+
+	tag, err := hex.DecodeString(params.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid tag", ErrUnsupportedVault)
+	}
+
+	plaintext, err := aead.Open(nil, nonce, append(ciphertext[:len(ciphertext):len(ciphertext)], tag...), nil)
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+
+	_, _ = rand.Read(b[:])
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
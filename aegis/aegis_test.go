@@ -0,0 +1,77 @@
+package aegis
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestScrypt checks the test vectors of RFC 7914 section 12.
+func TestScrypt(t *testing.T) {
+	tests := []struct {
+		password string
+		salt     string
+		n, r, p  int
+		want     string
+	}{
+		{
+			password: "", salt: "", n: 16, r: 1, p: 1,
+			want: "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906",
+		},
+		{
+			password: "password", salt: "NaCl", n: 1024, r: 8, p: 16,
+			want: "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := scrypt(tt.password, []byte(tt.salt), tt.n, tt.r, tt.p, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("scrypt(%q, %q): got %x, want %s", tt.password, tt.salt, got, tt.want)
+		}
+	}
+
+	if _, err := scrypt("password", nil, 1000, 8, 1, 32); !errors.Is(err, errInvalidScryptParams) {
+		t.Errorf("non power of two n: got %v, want %v", err, errInvalidScryptParams)
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	entries := []Entry{
+		{Name: "me@example.com", Issuer: "GitHub", Secret: []byte("12345678901234567890"), Algorithm: "SHA1", Digits: 6, Period: 30},
+		{Name: "admin", Secret: []byte("0123456789abcdef"), Algorithm: "SHA256", Digits: 8, Period: 60},
+	}
+
+	data, err := Encrypt("correct horse", entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v vault
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := v.Header.Slots; len(s) != 1 || s[0].Type != slotTypePassword || s[0].N != scryptN || len(s[0].Salt) != 2*saltSize {
+		t.Errorf("unexpected slots: %+v", s)
+	}
+
+	got, err := Decrypt("correct horse", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, entries) {
+		t.Errorf("got %+v, want %+v", got, entries)
+	}
+
+	if _, err := Decrypt("wrong horse", data); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("wrong password: got %v, want %v", err, ErrWrongPassword)
+	}
+}
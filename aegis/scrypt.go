@@ -0,0 +1,100 @@
+package aegis
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+
+	"golang.org/x/crypto/salsa20/salsa"
+)
+
+var errInvalidScryptParams = errors.New("invalid scrypt parameters")
+
+// scrypt derives a key of keyLen bytes from password and salt,
+// as specified by RFC 7914.
+//
+// n is the CPU/memory cost, a power of two greater than one,
+// r the block size and p the parallelization.
+func scrypt(password string, salt []byte, n, r, p, keyLen int) ([]byte, error) {
+	if n <= 1 || n&(n-1) != 0 || r <= 0 || p <= 0 || r*p >= 1<<30 {
+		return nil, errInvalidScryptParams
+	}
+
+	b, err := pbkdf2.Key(sha256.New, password, salt, 1, p*128*r)
+	if err != nil {
+		return nil, err
+	}
+
+	x := make([]uint32, 32*r)
+	v := make([]uint32, 32*r*n)
+
+	for i := range p {
+		romix(b[i*128*r:(i+1)*128*r], r, n, x, v)
+	}
+
+	return pbkdf2.Key(sha256.New, password, b, 1, keyLen)
+}
+
+// romix mixes the block b in place, using x and v as scratch space.
+func romix(b []byte, r, n int, x, v []uint32) {
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+
+	y := make([]uint32, len(x))
+
+	for i := range n {
+		copy(v[i*len(x):], x)
+		blockMix(x, y, r)
+	}
+
+	for range n {
+		// integerify, the first word of the last 64 byte block suffices,
+		// since n is at most 2^32.
+		j := int(x[(2*r-1)*16]) & (n - 1)
+
+		for k, w := range v[j*len(x) : (j+1)*len(x)] {
+			x[k] ^= w
+		}
+
+		blockMix(x, y, r)
+	}
+
+	for i, w := range x {
+		binary.LittleEndian.PutUint32(b[i*4:], w)
+	}
+}
+
+// blockMix computes the scryptBlockMix of b in place, using y as scratch space.
+func blockMix(b, y []uint32, r int) {
+	var t, in, out [64]byte
+
+	last := b[(2*r-1)*16 : 2*r*16]
+	putWords(t[:], last)
+
+	for i := range 2 * r {
+		putWords(in[:], b[i*16:(i+1)*16])
+
+		for k := range t {
+			in[k] ^= t[k]
+		}
+
+		salsa.Core208(&out, &in)
+		t = out
+
+		// even blocks are gathered first, followed by the odd ones.
+		dst := (i/2 + (i%2)*r) * 16
+		for k := range 16 {
+			y[dst+k] = binary.LittleEndian.Uint32(out[k*4:])
+		}
+	}
+
+	copy(b, y)
+}
+
+func putWords(dst []byte, words []uint32) {
+	for i, w := range words {
+		binary.LittleEndian.PutUint32(dst[i*4:], w)
+	}
+}
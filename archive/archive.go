@@ -0,0 +1,227 @@
+// Package archive reads and writes vltx archives: password encrypted exports
+// of vault secrets, importable into any vault.
+//
+// The secrets are json encoded and sealed as a single payload using
+// AES-256-GCM, under a key derived from the archive password using Argon2id.
+// The payload is authenticated along with the header, so that a modified
+// header, e.g., weakened key derivation parameters, is detected as well.
+//
+// An archive is made of a header followed by the sealed payload:
+//
+//	magic "vltx" | version (1 byte) | argon2id memory (4 bytes) | time (4 bytes) |
+//	parallelism (1 byte) | salt (16 bytes) | nonce (12 bytes) | payload...
+//
+// Integers are big-endian.
+package archive
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vaultcrypto"
+)
+
+const (
+	// Ext is the extension of archive files.
+	Ext = ".vltx"
+
+	// Version is the version of the archive format written by [Write].
+	Version = 1
+
+	magic     = "vltx"
+	saltSize  = 16
+	nonceSize = 12
+
+	headerSize = len(magic) + 1 + 4 + 4 + 1 + saltSize + nonceSize
+
+	// maxMemory bounds the Argon2id memory cost read from headers, in KiB.
+	maxMemory = 4 << 20
+)
+
+var (
+	// ErrFormat indicates input that is not a vltx archive.
+	ErrFormat = errors.New("not a vltx archive")
+
+	// ErrVersion indicates an archive written by a newer format version.
+	ErrVersion = errors.New("unsupported vltx archive version")
+
+	// ErrDecrypt indicates an archive sealed using another password, or modified.
+	ErrDecrypt = errors.New("decryption failed: wrong password, or the archive was modified")
+)
+
+// Secret is an archived secret.
+type Secret struct {
+	Name     string   `json:"name"`
+	Value    string   `json:"value"`
+	Labels   []string `json:"labels,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Notes    string   `json:"notes,omitempty"`
+	Kind     string   `json:"kind,omitempty"` // Kind is the kind of secrets with a dedicated format, e.g., "totp".
+
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+// Archive is the content of an archive.
+type Archive struct {
+	CreatedAt time.Time `json:"created_at"`
+	Secrets   []Secret  `json:"secrets"`
+}
+
+// header is the header of an archive.
+type header struct {
+	version byte
+	params  vaultcrypto.Argon2Params
+	salt    []byte
+	nonce   []byte
+	raw     []byte // raw is the encoded header, authenticated along with the payload.
+}
+
+// Sniff reports whether the input starting with head is an archive.
+func Sniff(head []byte) bool {
+	return bytes.HasPrefix(head, []byte(magic))
+}
+
+// Write seals a under a key derived from password to w.
+func Write(w io.Writer, a Archive, password []byte) error {
+	if len(password) == 0 {
+		return errors.New("empty password")
+	}
+
+	salt, err := vaultcrypto.RandBytes(saltSize)
+	if err != nil {
+		return err
+	}
+
+	nonce, err := vaultcrypto.RandBytes(nonceSize)
+	if err != nil {
+		return err
+	}
+
+	kdf := vaultcrypto.NewArgon2idKDF(vaultcrypto.WithSalt(salt))
+
+	h := &header{version: Version, params: kdf.PHC().Argon2Params, salt: salt, nonce: nonce}
+	h.raw = h.encode()
+
+	payload, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	aead, err := h.aead(password)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(h.raw); err != nil {
+		return err
+	}
+
+	_, err = w.Write(aead.Seal(nil, h.nonce, payload, h.raw))
+
+	return err
+}
+
+// Read opens the archive read from r using password.
+//
+// It returns [ErrFormat] if r is not an archive, [ErrVersion] if written by
+// a newer version, and [ErrDecrypt] if the password is wrong or the archive
+// was modified.
+func Read(r io.Reader, password []byte) (Archive, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return Archive{}, err
+	}
+
+	sealed, err := io.ReadAll(r)
+	if err != nil {
+		return Archive{}, err
+	}
+
+	aead, err := h.aead(password)
+	if err != nil {
+		return Archive{}, err
+	}
+
+	payload, err := aead.Open(nil, h.nonce, sealed, h.raw)
+	if err != nil {
+		return Archive{}, ErrDecrypt
+	}
+
+	var a Archive
+	if err := json.Unmarshal(payload, &a); err != nil {
+		return Archive{}, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+
+	return a, nil
+}
+
+func (h *header) encode() []byte {
+	raw := make([]byte, 0, headerSize)
+	raw = append(raw, magic...)
+	raw = append(raw, h.version)
+	raw = binary.BigEndian.AppendUint32(raw, h.params.Memory)
+	raw = binary.BigEndian.AppendUint32(raw, h.params.Time)
+	raw = append(raw, h.params.Parallelism)
+	raw = append(raw, h.salt...)
+
+	return append(raw, h.nonce...)
+}
+
+func readHeader(r io.Reader) (*header, error) {
+	raw := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, raw); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrFormat
+		}
+
+		return nil, err
+	}
+
+	if !Sniff(raw) {
+		return nil, ErrFormat
+	}
+
+	rest := raw[len(magic):]
+
+	h := &header{version: rest[0], raw: raw}
+	if h.version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrVersion, h.version)
+	}
+
+	h.params = vaultcrypto.Argon2Params{
+		Memory:      binary.BigEndian.Uint32(rest[1:5]),
+		Time:        binary.BigEndian.Uint32(rest[5:9]),
+		Parallelism: rest[9],
+	}
+
+	// bounded, not to exhaust memory deriving the key of a crafted archive.
+	if h.params.Memory == 0 || h.params.Memory > maxMemory || h.params.Time == 0 || h.params.Parallelism == 0 {
+		return nil, fmt.Errorf("%w: invalid key derivation parameters", ErrFormat)
+	}
+
+	h.salt = rest[10 : 10+saltSize]
+	h.nonce = rest[10+saltSize:]
+
+	return h, nil
+}
+
+// aead returns the cipher of the key derived from password and the header parameters.
+func (h *header) aead(password []byte) (cipher.AEAD, error) {
+	kdf := vaultcrypto.NewArgon2idKDF(vaultcrypto.WithSalt(h.salt), vaultcrypto.WithParams(h.params))
+
+	block, err := aes.NewCipher(kdf.Derive(password))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,84 @@
+package archive_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/archive"
+)
+
+func TestWriteRead(t *testing.T) {
+	want := archive.Archive{
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Secrets: []archive.Secret{
+			{Name: "github", Value: "hunter2", Labels: []string{"work", "git"}, URL: "https://github.com", Username: "alice"},
+			{Name: "github-otp", Value: "JBSWY3DPEHPK3PXP", Kind: "totp"},
+			{Name: "empty", Value: ""},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := archive.Write(&buf, want, []byte("password")); err != nil {
+		t.Fatal(err)
+	}
+
+	if !archive.Sniff(buf.Bytes()) {
+		t.Error("Sniff() of an archive: got false, want true")
+	}
+
+	sealed := buf.Bytes()
+
+	got, err := archive.Read(bytes.NewReader(sealed), []byte("password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read(): got %+v, want %+v", got, want)
+	}
+
+	if _, err := archive.Read(bytes.NewReader(sealed), []byte("wrong")); !errors.Is(err, archive.ErrDecrypt) {
+		t.Errorf("Read() using a wrong password: got err %v, want %v", err, archive.ErrDecrypt)
+	}
+}
+
+func TestRead_Tampered(t *testing.T) {
+	var buf bytes.Buffer
+	if err := archive.Write(&buf, archive.Archive{Secrets: []archive.Secret{{Name: "a", Value: "b"}}}, []byte("password")); err != nil {
+		t.Fatal(err)
+	}
+
+	sealed := buf.Bytes()
+
+	tamper := func(i int) []byte {
+		b := bytes.Clone(sealed)
+		b[i] ^= 1
+
+		return b
+	}
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  error
+	}{
+		{name: "not an archive", input: []byte("name,secret,labels\n"), want: archive.ErrFormat},
+		{name: "truncated header", input: sealed[:10], want: archive.ErrFormat},
+		{name: "newer version", input: tamper(4), want: archive.ErrVersion},
+		{name: "invalid parameters", input: append(bytes.Clone(sealed[:5]), append(make([]byte, 4), sealed[9:]...)...), want: archive.ErrFormat},
+		{name: "modified salt", input: tamper(15), want: archive.ErrDecrypt},
+		{name: "modified payload", input: tamper(len(sealed) - 1), want: archive.ErrDecrypt},
+		{name: "truncated payload", input: sealed[:len(sealed)-1], want: archive.ErrDecrypt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := archive.Read(bytes.NewReader(tt.input), []byte("password")); !errors.Is(err, tt.want) {
+				t.Errorf("Read(): got err %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
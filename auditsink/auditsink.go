@@ -0,0 +1,86 @@
+// Package auditsink mirrors vault audit log entries to host-level
+// logging facilities, such as syslog or the systemd journal.
+//
+// Only operation metadata is forwarded; secret values never leave the vault.
+package auditsink
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+const (
+	KindSyslog   = "syslog"
+	KindJournald = "journald"
+)
+
+// Kinds lists the supported sink kinds.
+var Kinds = []string{KindSyslog, KindJournald}
+
+// identifier is the program identifier attached to mirrored entries.
+const identifier = "vlt"
+
+// Sink writes audit log entries to an external destination.
+type Sink interface {
+	Write(vaultPath string, entry vaultdb.AuditEntry) error
+	Close() error
+}
+
+// New returns a connected [Sink] of the given kind.
+//
+//nolint:ireturn
+func New(kind string) (Sink, error) {
+	switch kind {
+	case KindSyslog:
+		return newSyslog()
+	case KindJournald:
+		return newJournald()
+	default:
+		return nil, fmt.Errorf("audit sink: unknown kind %q (supported: %v)", kind, Kinds)
+	}
+}
+
+// IsValidKind reports whether kind is a supported sink kind.
+func IsValidKind(kind string) bool {
+	return slices.Contains(Kinds, kind)
+}
+
+type field struct {
+	key, value string
+}
+
+// fields returns the structured representation of the given entry.
+func fields(vaultPath string, e vaultdb.AuditEntry) []field {
+	fs := []field{
+		{"operation", string(e.Operation)},
+		{"audit_id", strconv.Itoa(e.ID)},
+		{"audit_hash", hex.EncodeToString(e.Hash)},
+		{"vault", vaultPath},
+	}
+
+	if e.SecretID > 0 {
+		fs = append(fs,
+			field{"secret_id", strconv.Itoa(e.SecretID)},
+			field{"secret_name", e.SecretName},
+		)
+	}
+
+	if len(e.Actor) > 0 {
+		fs = append(fs, field{"actor", e.Actor})
+	}
+
+	return fs
+}
+
+// message returns a human-readable summary of the given entry.
+func message(vaultPath string, e vaultdb.AuditEntry) string {
+	if e.SecretID > 0 {
+		return fmt.Sprintf("%s: secret %q (id=%d) in vault %q", e.Operation, e.SecretName, e.SecretID, vaultPath)
+	}
+
+	return fmt.Sprintf("%s: vault %q", e.Operation, vaultPath)
+}
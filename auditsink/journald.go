@@ -0,0 +1,70 @@
+package auditsink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strings"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+// journalSocket is the systemd journal native protocol socket.
+const journalSocket = "/run/systemd/journal/socket"
+
+// journalPriorityInfo is the syslog info priority level.
+const journalPriorityInfo = "6"
+
+// Journald mirrors audit entries to the systemd journal
+// using the native journal protocol.
+//
+// Entry fields are attached as VLT_-prefixed journal fields.
+//
+// See: https://systemd.io/JOURNAL_NATIVE_PROTOCOL/
+type Journald struct {
+	conn *net.UnixConn
+}
+
+var _ Sink = &Journald{}
+
+func newJournald() (*Journald, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journalSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Journald{conn: conn}, nil
+}
+
+func (j *Journald) Write(vaultPath string, entry vaultdb.AuditEntry) error {
+	var buf bytes.Buffer
+
+	writeJournalField(&buf, "MESSAGE", message(vaultPath, entry))
+	writeJournalField(&buf, "PRIORITY", journalPriorityInfo)
+	writeJournalField(&buf, "SYSLOG_IDENTIFIER", identifier)
+
+	for _, f := range fields(vaultPath, entry) {
+		writeJournalField(&buf, "VLT_"+strings.ToUpper(f.key), f.value)
+	}
+
+	_, err := j.conn.Write(buf.Bytes())
+
+	return err
+}
+
+func (j *Journald) Close() error {
+	return j.conn.Close()
+}
+
+// writeJournalField serializes a single field. Values containing newlines
+// use the length-prefixed binary form of the protocol.
+func writeJournalField(buf *bytes.Buffer, key, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(key + "=" + value + "\n")
+		return
+	}
+
+	buf.WriteString(key + "\n")
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value + "\n")
+}
@@ -0,0 +1,46 @@
+//go:build !windows
+
+package auditsink
+
+import (
+	"log/syslog"
+	"strconv"
+	"strings"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+// Syslog mirrors audit entries to the local syslog daemon
+// using the authpriv facility.
+type Syslog struct {
+	w *syslog.Writer
+}
+
+var _ Sink = &Syslog{}
+
+func newSyslog() (*Syslog, error) {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_AUTHPRIV, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Syslog{w: w}, nil
+}
+
+// Write logs the entry as a message followed by
+// space separated key=value pairs.
+func (s *Syslog) Write(vaultPath string, entry vaultdb.AuditEntry) error {
+	var sb strings.Builder
+
+	sb.WriteString(message(vaultPath, entry))
+
+	for _, f := range fields(vaultPath, entry) {
+		sb.WriteString(" " + f.key + "=" + strconv.Quote(f.value))
+	}
+
+	return s.w.Info(sb.String())
+}
+
+func (s *Syslog) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,20 @@
+package auditsink
+
+import (
+	"errors"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+// Syslog is unsupported on Windows, which has no syslog daemon.
+type Syslog struct{}
+
+var _ Sink = &Syslog{}
+
+func newSyslog() (*Syslog, error) {
+	return nil, errors.New("audit sink: syslog is not supported on windows")
+}
+
+func (*Syslog) Write(string, vaultdb.AuditEntry) error { return nil }
+
+func (*Syslog) Close() error { return nil }
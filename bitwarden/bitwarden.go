@@ -0,0 +1,320 @@
+// Package bitwarden implements the client-side cryptography of the Bitwarden
+// protocol, allowing vlt to serve its secrets to the official Bitwarden clients.
+//
+// Bitwarden clients never send their master password. They derive a master key
+// from it (PBKDF2-SHA256, salted by the account email), authenticate using a
+// hash of the master key, and decrypt the account symmetric key (the "user key")
+// using the master key. All item fields are encrypted by the user key as
+// encrypted strings of the form:
+//
+//	2.<iv>|<ciphertext>|<mac>
+//
+// using AES-256-CBC and HMAC-SHA256, base64 encoded.
+package bitwarden
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultKDFIterations is the default PBKDF2 iteration count of new accounts,
+	// matching the Bitwarden default.
+	DefaultKDFIterations = 600_000
+
+	// KDFPBKDF2 identifies PBKDF2-SHA256 in the protocol, the only supported KDF.
+	KDFPBKDF2 = 0
+
+	// KeySize is the size of a symmetric key: an AES-256 key followed by an HMAC-SHA256 key.
+	KeySize = 64
+
+	// encTypeAESCBC256HMAC is the type of encrypted strings using AES-256-CBC and HMAC-SHA256.
+	encTypeAESCBC256HMAC = "2"
+
+	// passwordHashIterations is the iteration count of the server side hash of
+	// master password hashes, guarding against offline attacks on stored hashes.
+	passwordHashIterations = 100_000
+)
+
+var (
+	ErrInvalidEncString = errors.New("invalid encrypted string")
+	ErrMACMismatch      = errors.New("encrypted string mac mismatch")
+)
+
+// Key is a symmetric key used to encrypt and authenticate encrypted strings.
+type Key struct {
+	enc []byte
+	mac []byte
+}
+
+// NewKey returns the key represented by the given [KeySize] bytes.
+func NewKey(b []byte) (Key, error) {
+	if len(b) != KeySize {
+		return Key{}, fmt.Errorf("invalid key size %d", len(b))
+	}
+
+	return Key{enc: bytes.Clone(b[:32]), mac: bytes.Clone(b[32:])}, nil
+}
+
+// Bytes returns the raw key, the encryption key followed by the mac key.
+func (k Key) Bytes() []byte {
+	return append(bytes.Clone(k.enc), k.mac...)
+}
+
+// MasterKey derives the master key of an account from its password.
+func MasterKey(password string, email string, iterations int) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, password, []byte(normalizeEmail(email)), iterations, 32)
+}
+
+// MasterPasswordHash returns the hash clients authenticate with,
+// derived from the master key and the password.
+func MasterPasswordHash(masterKey []byte, password string) (string, error) {
+	h, err := pbkdf2.Key(sha256.New, string(masterKey), []byte(password), 1, 32)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(h), nil
+}
+
+// StretchMasterKey expands a master key into the key encrypting the user key.
+func StretchMasterKey(masterKey []byte) (Key, error) {
+	enc, err := hkdf.Expand(sha256.New, masterKey, "enc", 32)
+	if err != nil {
+		return Key{}, err
+	}
+
+	mac, err := hkdf.Expand(sha256.New, masterKey, "mac", 32)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{enc: enc, mac: mac}, nil
+}
+
+// Encrypt returns plaintext as an encrypted string.
+func (k Key) Encrypt(plaintext []byte) (string, error) {
+	block, err := aes.NewCipher(k.enc)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	padded := pkcs7Pad(plaintext, aes.BlockSize)
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+
+	b64 := base64.StdEncoding.EncodeToString
+
+	return encTypeAESCBC256HMAC + "." + b64(iv) + "|" + b64(ciphertext) + "|" + b64(k.sum(iv, ciphertext)), nil
+}
+
+// EncryptString returns s as an encrypted string.
+func (k Key) EncryptString(s string) (string, error) {
+	return k.Encrypt([]byte(s))
+}
+
+// Decrypt returns the plaintext of the given encrypted string.
+func (k Key) Decrypt(encString string) ([]byte, error) {
+	typ, data, ok := strings.Cut(encString, ".")
+	if !ok || typ != encTypeAESCBC256HMAC {
+		return nil, ErrInvalidEncString
+	}
+
+	parts := strings.Split(data, "|")
+	if len(parts) != 3 {
+		return nil, ErrInvalidEncString
+	}
+
+	var decoded [3][]byte
+
+	for i, p := range parts {
+		b, err := base64.StdEncoding.DecodeString(p)
+		if err != nil {
+			return nil, ErrInvalidEncString
+		}
+
+		decoded[i] = b
+	}
+
+	iv, ciphertext, mac := decoded[0], decoded[1], decoded[2]
+
+	if !hmac.Equal(mac, k.sum(iv, ciphertext)) {
+		return nil, ErrMACMismatch
+	}
+
+	if len(iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, ErrInvalidEncString
+	}
+
+	block, err := aes.NewCipher(k.enc)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	return pkcs7Unpad(plaintext, aes.BlockSize)
+}
+
+// DecryptString returns the plaintext of the given encrypted string as a string.
+func (k Key) DecryptString(encString string) (string, error) {
+	b, err := k.Decrypt(encString)
+	return string(b), err
+}
+
+func (k Key) sum(iv, ciphertext []byte) []byte {
+	h := hmac.New(sha256.New, k.mac)
+	h.Write(iv)
+	h.Write(ciphertext)
+
+	return h.Sum(nil)
+}
+
+// Account holds the key material of a Bitwarden account, as stored by the server.
+//
+// The user key itself is not part of the account, it is only stored encrypted
+// by the stretched master key, for clients to decrypt on login.
+type Account struct {
+	Email         string
+	KDFIterations int
+
+	PasswordSalt []byte // PasswordSalt salts PasswordHash.
+	PasswordHash []byte // PasswordHash is the server side hash of the master password hash.
+
+	ProtectedKey        string // ProtectedKey is the user key, encrypted by the stretched master key.
+	PublicKey           string // PublicKey is the base64 encoded PKIX account public key.
+	ProtectedPrivateKey string // ProtectedPrivateKey is the PKCS #8 account private key, encrypted by the user key.
+}
+
+// NewAccount returns a new account with the given email and password,
+// protecting the given user key. A new account key pair is generated.
+func NewAccount(email string, password string, iterations int, userKey Key) (Account, error) {
+	masterKey, err := MasterKey(password, email, iterations)
+	if err != nil {
+		return Account{}, err
+	}
+
+	stretched, err := StretchMasterKey(masterKey)
+	if err != nil {
+		return Account{}, err
+	}
+
+	protectedKey, err := stretched.Encrypt(userKey.Bytes())
+	if err != nil {
+		return Account{}, err
+	}
+
+	hash, err := MasterPasswordHash(masterKey, password)
+	if err != nil {
+		return Account{}, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return Account{}, err
+	}
+
+	serverHash, err := hashPassword(hash, salt)
+	if err != nil {
+		return Account{}, err
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return Account{}, err
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return Account{}, err
+	}
+
+	pkix, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return Account{}, err
+	}
+
+	protectedPrivateKey, err := userKey.Encrypt(pkcs8)
+	if err != nil {
+		return Account{}, err
+	}
+
+	a := Account{
+		Email:               normalizeEmail(email),
+		KDFIterations:       iterations,
+		PasswordSalt:        salt,
+		PasswordHash:        serverHash,
+		ProtectedKey:        protectedKey,
+		PublicKey:           base64.StdEncoding.EncodeToString(pkix),
+		ProtectedPrivateKey: protectedPrivateKey,
+	}
+
+	return a, nil
+}
+
+// VerifyPassword reports whether the given master password hash,
+// as sent by clients, matches the account password.
+func (a Account) VerifyPassword(masterPasswordHash string) bool {
+	h, err := hashPassword(masterPasswordHash, a.PasswordSalt)
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(h, a.PasswordHash) == 1
+}
+
+// MatchesEmail reports whether email is the account email, ignoring case.
+func (a Account) MatchesEmail(email string) bool {
+	return normalizeEmail(email) == a.Email
+}
+
+func hashPassword(masterPasswordHash string, salt []byte) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, masterPasswordHash, salt, passwordHashIterations, 32)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func pkcs7Pad(b []byte, blockSize int) []byte {
+	n := blockSize - len(b)%blockSize
+	return append(bytes.Clone(b), bytes.Repeat([]byte{byte(n)}, n)...)
+}
+
+func pkcs7Unpad(b []byte, blockSize int) ([]byte, error) {
+	if len(b) == 0 || len(b)%blockSize != 0 {
+		return nil, ErrInvalidEncString
+	}
+
+	n := int(b[len(b)-1])
+	if n == 0 || n > blockSize || n > len(b) {
+		return nil, ErrInvalidEncString
+	}
+
+	for _, c := range b[len(b)-n:] {
+		if int(c) != n {
+			return nil, ErrInvalidEncString
+		}
+	}
+
+	return b[:len(b)-n], nil
+}
@@ -0,0 +1,150 @@
+package bitwarden_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/bitwarden"
+)
+
+// vectors were computed independently, using python's hashlib and openssl.
+const (
+	testPassword   = "correct horse"
+	testEmail      = " User@Example.com"
+	testIterations = 5000
+
+	testMasterKey = "5b3d29e95eb8e6f4508030265d0a6d9c8633f0030cc3436ece013e2a42a67406"
+	testHash      = "GawDT/kzVPcgqsGddJHjFOGS1q79ym7be4hCTiGCvrk="
+	testEncString = "2.AAECAwQFBgcICQoLDA0ODw==|Lu9EqdZjUvIBvuQdEBfxFg==|XqP0OWEpGvwHCgG2x77vOjORgPoIZlKYG3ro96woYog="
+)
+
+func TestMasterKey(t *testing.T) {
+	masterKey, err := bitwarden.MasterKey(testPassword, testEmail, testIterations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := hex.EncodeToString(masterKey); got != testMasterKey {
+		t.Errorf("master key: got %s, want %s", got, testMasterKey)
+	}
+
+	hash, err := bitwarden.MasterPasswordHash(masterKey, testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hash != testHash {
+		t.Errorf("master password hash: got %s, want %s", hash, testHash)
+	}
+
+	stretched, err := bitwarden.StretchMasterKey(masterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := stretched.DecryptString(testEncString)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "hello bitwarden" {
+		t.Errorf("decrypt: got %q, want %q", got, "hello bitwarden")
+	}
+}
+
+func TestKey_Encrypt(t *testing.T) {
+	key, err := bitwarden.NewKey(bytes.Repeat([]byte{7}, bitwarden.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, plaintext := range []string{"", "secret", strings.Repeat("x", 16)} {
+		enc, err := key.EncryptString(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(enc, "2.") {
+			t.Errorf("encrypt %q: got %s, want type 2 encrypted string", plaintext, enc)
+		}
+
+		got, err := key.DecryptString(enc)
+		if err != nil {
+			t.Fatalf("decrypt %q: %v", plaintext, err)
+		}
+
+		if got != plaintext {
+			t.Errorf("decrypt: got %q, want %q", got, plaintext)
+		}
+	}
+
+	other, err := bitwarden.NewKey(bytes.Repeat([]byte{8}, bitwarden.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enc, err := key.EncryptString("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := other.Decrypt(enc); !errors.Is(err, bitwarden.ErrMACMismatch) {
+		t.Errorf("decrypt using another key: got err %v, want %v", err, bitwarden.ErrMACMismatch)
+	}
+
+	for _, invalid := range []string{"", "secret", "0.abc", "2.a|b", "2.!|!|!"} {
+		if _, err := key.Decrypt(invalid); !errors.Is(err, bitwarden.ErrInvalidEncString) {
+			t.Errorf("decrypt %q: got err %v, want %v", invalid, err, bitwarden.ErrInvalidEncString)
+		}
+	}
+}
+
+func TestNewAccount(t *testing.T) {
+	userKey, err := bitwarden.NewKey(bytes.Repeat([]byte{1}, bitwarden.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := bitwarden.NewAccount(testEmail, testPassword, testIterations, userKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !a.MatchesEmail("user@example.com") {
+		t.Errorf("account email %q does not match", a.Email)
+	}
+
+	if !a.VerifyPassword(testHash) {
+		t.Error("verify password: got false, want true")
+	}
+
+	if a.VerifyPassword(testHash[1:]) {
+		t.Error("verify wrong password: got true, want false")
+	}
+
+	masterKey, err := bitwarden.MasterKey(testPassword, testEmail, testIterations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stretched, err := bitwarden.StretchMasterKey(masterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := stretched.Decrypt(a.ProtectedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, userKey.Bytes()) {
+		t.Error("protected key does not decrypt to the user key")
+	}
+
+	if _, err := userKey.Decrypt(a.ProtectedPrivateKey); err != nil {
+		t.Errorf("decrypt private key: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Package card validates and formats payment cards stored as secrets.
+//
+// Cards are stored as JSON objects holding the fields of [Card], so that
+// their fields can be selected as the ones of any other JSON secret.
+package card
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Label marks the secrets holding cards.
+const Label = "type=card"
+
+// Fields lists the JSON fields of a card.
+var Fields = []string{"number", "expiry", "cvv", "holder"}
+
+var (
+	ErrInvalidNumber = errors.New("invalid card number")
+	ErrInvalidExpiry = errors.New("invalid card expiry (expected MM/YY)")
+	ErrInvalidCVV    = errors.New("invalid card cvv (expected 3 or 4 digits)")
+)
+
+// Card holds the details of a payment card.
+type Card struct {
+	Number string `json:"number"`
+	Expiry string `json:"expiry"` // Expiry is the expiry month, formatted as MM/YY.
+	CVV    string `json:"cvv,omitempty"`
+	Holder string `json:"holder,omitempty"`
+}
+
+// Parse parses a card stored as a JSON object.
+func Parse(value string) (Card, error) {
+	var c Card
+	if err := json.Unmarshal([]byte(value), &c); err != nil {
+		return Card{}, fmt.Errorf("parse card: %w", err)
+	}
+
+	return c, nil
+}
+
+// Normalize strips the separators of the card number and formats the
+// expiry as MM/YY, accepting MM/YYYY and MM-YY forms, then validates the card.
+func (c *Card) Normalize() error {
+	c.Number = strings.NewReplacer(" ", "", "-", "").Replace(c.Number)
+	c.Holder = strings.TrimSpace(c.Holder)
+
+	if !ValidNumber(c.Number) {
+		return ErrInvalidNumber
+	}
+
+	expiry, err := normalizeExpiry(c.Expiry)
+	if err != nil {
+		return err
+	}
+
+	c.Expiry = expiry
+
+	if len(c.CVV) > 0 && (len(c.CVV) < 3 || len(c.CVV) > 4 || !digits(c.CVV)) {
+		return ErrInvalidCVV
+	}
+
+	return nil
+}
+
+// Expired reports whether the card expired before now,
+// cards being valid until the end of their expiry month.
+func (c *Card) Expired(now time.Time) bool {
+	t, err := time.Parse("01/06", c.Expiry)
+	if err != nil {
+		return false
+	}
+
+	return !now.Before(t.AddDate(0, 1, 0))
+}
+
+// Field returns the value of the given field, see [Fields].
+func (c *Card) Field(name string) (string, bool) {
+	switch name {
+	case "number":
+		return c.Number, true
+	case "expiry":
+		return c.Expiry, true
+	case "cvv":
+		return c.CVV, true
+	case "holder":
+		return c.Holder, true
+	default:
+		return "", false
+	}
+}
+
+// Masked returns the card number masked up to its last 4 digits, e.g., "•••• 4242".
+func (c *Card) Masked() string {
+	if len(c.Number) <= 4 {
+		return strings.Repeat("•", len(c.Number))
+	}
+
+	return "•••• " + c.Number[len(c.Number)-4:]
+}
+
+// ValidNumber reports whether number is a valid card number: 12 to 19 digits,
+// passing the Luhn check.
+func ValidNumber(number string) bool {
+	if len(number) < 12 || len(number) > 19 || !digits(number) {
+		return false
+	}
+
+	sum := 0
+
+	for i := range len(number) {
+		d := int(number[len(number)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+	}
+
+	return sum%10 == 0
+}
+
+func normalizeExpiry(expiry string) (string, error) {
+	month, year, ok := strings.Cut(strings.ReplaceAll(strings.TrimSpace(expiry), "-", "/"), "/")
+	if !ok || len(month) == 0 || len(month) > 2 || !digits(month) || !digits(year) {
+		return "", ErrInvalidExpiry
+	}
+
+	switch len(year) {
+	case 2:
+	case 4:
+		year = year[2:]
+	default:
+		return "", ErrInvalidExpiry
+	}
+
+	if len(month) == 1 {
+		month = "0" + month
+	}
+
+	if month < "01" || month > "12" {
+		return "", ErrInvalidExpiry
+	}
+
+	return month + "/" + year, nil
+}
+
+func digits(s string) bool {
+	for _, c := range []byte(s) {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return len(s) > 0
+}
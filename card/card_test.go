@@ -0,0 +1,88 @@
+package card_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/card"
+)
+
+func TestValidNumber(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"4242424242424242", true},
+		{"4242424242424241", false},
+		{"378282246310005", true}, // amex, 15 digits
+		{"4242", false},
+		{"4242x24242424242", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := card.ValidNumber(tt.number); got != tt.want {
+			t.Errorf("ValidNumber(%q) = %v, want %v", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestCard_Normalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		card    card.Card
+		want    card.Card
+		wantErr error
+	}{
+		{
+			name: "separators and long year",
+			card: card.Card{Number: "4242 4242-4242 4242", Expiry: "3/2031", CVV: "123", Holder: " Jane Doe "},
+			want: card.Card{Number: "4242424242424242", Expiry: "03/31", CVV: "123", Holder: "Jane Doe"},
+		},
+		{
+			name: "no cvv",
+			card: card.Card{Number: "378282246310005", Expiry: "12-29"},
+			want: card.Card{Number: "378282246310005", Expiry: "12/29"},
+		},
+		{name: "luhn", card: card.Card{Number: "4242424242424241", Expiry: "01/30"}, wantErr: card.ErrInvalidNumber},
+		{name: "month", card: card.Card{Number: "4242424242424242", Expiry: "13/30"}, wantErr: card.ErrInvalidExpiry},
+		{name: "year", card: card.Card{Number: "4242424242424242", Expiry: "01/3"}, wantErr: card.ErrInvalidExpiry},
+		{name: "cvv", card: card.Card{Number: "4242424242424242", Expiry: "01/30", CVV: "12"}, wantErr: card.ErrInvalidCVV},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.card
+
+			err := c.Normalize()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Normalize() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && c != tt.want {
+				t.Errorf("Normalize() = %+v, want %+v", c, tt.want)
+			}
+		})
+	}
+}
+
+func TestCard_Expired(t *testing.T) {
+	c := card.Card{Expiry: "03/31"}
+
+	if c.Expired(time.Date(2031, time.March, 31, 23, 0, 0, 0, time.UTC)) {
+		t.Error("Expired() during the expiry month = true, want false")
+	}
+
+	if !c.Expired(time.Date(2031, time.April, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Expired() after the expiry month = false, want true")
+	}
+}
+
+func TestCard_Masked(t *testing.T) {
+	c := card.Card{Number: "4242424242424242"}
+
+	if got, want := c.Masked(), "•••• 4242"; got != want {
+		t.Errorf("Masked() = %q, want %q", got, want)
+	}
+}
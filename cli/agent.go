@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/sshkey"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+// defaultConfirmCmd is the program confirming key uses if $SSH_ASKPASS is not set.
+const defaultConfirmCmd = "ssh-askpass"
+
+type AgentError struct {
+	Err error
+}
+
+func (e *AgentError) Error() string { return "agent: " + e.Err.Error() }
+
+func (e *AgentError) Unwrap() error { return e.Err }
+
+// AgentOptions holds data required to run the command.
+type AgentOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	ssh        bool   // ssh enables the ssh-agent protocol.
+	socket     string // socket is the path of the ssh-agent socket.
+	noConfirm  bool   // noConfirm disables the confirmation of key uses.
+	confirmCmd string // confirmCmd is the askpass program confirming key uses.
+}
+
+var _ genericclioptions.CmdOptions = &AgentOptions{}
+
+// NewAgentOptions initializes the options struct.
+func NewAgentOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *AgentOptions {
+	return &AgentOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (o *AgentOptions) Complete() error {
+	o.socket = cmp.Or(o.socket, defaultAgentSocket())
+	o.confirmCmd = cmp.Or(o.confirmCmd, os.Getenv("SSH_ASKPASS"), defaultConfirmCmd)
+
+	return nil
+}
+
+func (o *AgentOptions) Validate() error {
+	if !o.ssh {
+		return &AgentError{errors.New("no agent protocol enabled, use --ssh")}
+	}
+
+	return nil
+}
+
+func (o *AgentOptions) Run(ctx context.Context, _ ...string) error {
+	keys, err := o.loadSSHKeys(ctx)
+	if err != nil {
+		return &AgentError{err}
+	}
+
+	if len(keys) == 0 {
+		o.Warnf("vlt: no ssh keys found, add keys using 'vlt ssh-key add'.\n")
+	}
+
+	var confirm sshkey.ConfirmFunc
+	if !o.noConfirm {
+		confirm = o.confirm
+	}
+
+	a, err := sshkey.NewAgent(keys, confirm)
+	if err != nil {
+		return &AgentError{err}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	l, err := listenAgentSocket(ctx, o.socket)
+	if err != nil {
+		return &AgentError{err}
+	}
+	defer func() { _ = os.Remove(o.socket) }() //nolint:wsl
+
+	// printed as shell commands, as ssh-agent does.
+	o.Infof("SSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK;\n", o.socket)
+	o.Infof("echo Serving %d ssh keys;\n", len(keys))
+
+	if err := a.Serve(ctx, l); err != nil {
+		return &AgentError{err}
+	}
+
+	return nil
+}
+
+// loadSSHKeys returns the ssh keys stored in the vault and its mounts, by name.
+// Keys of the same name are told apart by their ids.
+func (o *AgentOptions) loadSSHKeys(ctx context.Context) (map[string]sshkey.Key, error) {
+	secrets, err := o.searchAll(ctx, o.StdioOptions, &SearchableOptions{Labels: []string{sshkey.Label}})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]sshkey.Key, len(secrets))
+
+	for _, s := range secrets {
+		k, err := o.readSSHKey(ctx, o.StdioOptions, s)
+		if err != nil {
+			return nil, fmt.Errorf("ssh key %q: %w", s.name, err)
+		}
+
+		name := s.name
+		if _, ok := keys[name]; ok {
+			name += " #" + s.displayID()
+		}
+
+		keys[name] = k
+	}
+
+	return keys, nil
+}
+
+// confirm asks for the use of the key of the given name to be confirmed
+// using the askpass program, which confirms by exiting successfully.
+func (o *AgentOptions) confirm(name string, key ssh.PublicKey) error {
+	prompt := fmt.Sprintf("Allow use of ssh key %q?\n%s", name, ssh.FingerprintSHA256(key))
+
+	args := strings.Fields(o.confirmCmd)
+
+	cmd := exec.Command(args[0], append(args[1:], prompt)...) //nolint:gosec,noctx // configured by the user.
+	cmd.Env = append(os.Environ(), "SSH_ASKPASS_PROMPT=confirm")
+
+	if err := cmd.Run(); err != nil {
+		o.Warnf("vlt: agent: use of ssh key %q denied: %v\n", name, err)
+		return fmt.Errorf("use of %q denied", name)
+	}
+
+	o.Debugf("vlt: agent: use of ssh key %q confirmed\n", name)
+
+	return nil
+}
+
+// defaultAgentSocket returns the default ssh-agent socket path,
+// under $XDG_RUNTIME_DIR if set.
+func defaultAgentSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); len(dir) > 0 {
+		return filepath.Join(dir, "vlt-ssh-agent.sock")
+	}
+
+	return filepath.Join(os.TempDir(), fmt.Sprintf("vlt-ssh-agent-%d.sock", os.Getuid()))
+}
+
+// listenAgentSocket listens on the unix socket at path, accessible by
+// the current user only. Stale sockets are removed, unless in use.
+func listenAgentSocket(ctx context.Context, path string) (net.Listener, error) {
+	if conn, err := (&net.Dialer{}).DialContext(ctx, "unix", path); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("%s: socket in use by another agent", path)
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return listenPrivateUnix(ctx, path)
+}
+
+// NewCmdAgent creates the agent cobra command.
+func NewCmdAgent(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewAgentOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Serve stored SSH keys using the ssh-agent protocol",
+		Long: `Serve the SSH keys stored in the vault ('vlt ssh-key') to SSH clients,
+using the ssh-agent protocol, until interrupted.
+
+The agent listens on a unix socket, accessible by the current user only, and
+prints the shell commands setting SSH_AUTH_SOCK to it, as ssh-agent does.
+Keys are loaded when the agent starts, restart it to serve keys added since.
+
+Each use of a key is confirmed using $SSH_ASKPASS, or ssh-askpass if not set,
+which is run with SSH_ASKPASS_PROMPT=confirm, and allows the use by exiting
+successfully. Keys cannot be added or removed using ssh-add.`,
+		Example: `  # Serve the stored keys, confirming each use
+  vlt agent --ssh
+
+  # Serve the stored keys on a given socket without confirmation
+  vlt agent --ssh --socket ~/.ssh/vlt.sock --no-confirm
+
+  # Use the agent from another shell
+  export SSH_AUTH_SOCK="$XDG_RUNTIME_DIR/vlt-ssh-agent.sock"
+  ssh-add -l`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.ssh, "ssh", "", false, "serve the stored ssh keys using the ssh-agent protocol")
+	cmd.Flags().StringVarP(&o.socket, "socket", "", "", "ssh-agent socket path (default: $XDG_RUNTIME_DIR/vlt-ssh-agent.sock)")
+	cmd.Flags().BoolVarP(&o.noConfirm, "no-confirm", "", false, "sign without confirming each use of a key")
+	cmd.Flags().StringVarP(&o.confirmCmd, "confirm-cmd", "", "", "askpass program confirming key uses (default: $SSH_ASKPASS, or ssh-askpass)")
+
+	return cmd
+}
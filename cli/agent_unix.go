@@ -0,0 +1,19 @@
+//go:build !windows
+
+package cli
+
+import (
+	"context"
+	"net"
+	"syscall"
+)
+
+// listenPrivateUnix listens on the unix socket at path,
+// created accessible by the current user only.
+func listenPrivateUnix(ctx context.Context, path string) (net.Listener, error) {
+	old := syscall.Umask(0o177)
+	l, err := (&net.ListenConfig{}).Listen(ctx, "unix", path)
+	syscall.Umask(old)
+
+	return l, err
+}
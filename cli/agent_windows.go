@@ -0,0 +1,13 @@
+package cli
+
+import (
+	"context"
+	"net"
+)
+
+// listenPrivateUnix listens on the unix socket at path. On Windows, the
+// socket inherits the ACL of its directory, private to the user under
+// the user profile.
+func listenPrivateUnix(ctx context.Context, path string) (net.Listener, error) {
+	return (&net.ListenConfig{}).Listen(ctx, "unix", path)
+}
@@ -0,0 +1,427 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+type AttachmentError struct {
+	Err error
+}
+
+func (e *AttachmentError) Error() string { return "attachment: " + e.Err.Error() }
+
+func (e *AttachmentError) Unwrap() error { return e.Err }
+
+// AttachOptions holds data required to run the command.
+type AttachOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	name string // name is the attachment name, defaults to the file name.
+}
+
+var _ genericclioptions.CmdOptions = &AttachOptions{}
+
+// NewAttachOptions initializes the options struct.
+func NewAttachOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *AttachOptions {
+	return &AttachOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*AttachOptions) Complete() error { return nil }
+
+func (*AttachOptions) Validate() error { return nil }
+
+func (o *AttachOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &AttachmentError{retErr}
+			return
+		}
+	}()
+
+	secretName, path := args[0], args[1]
+
+	var r io.Reader = o.In
+
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }() //nolint:wsl
+
+		r, o.name = f, cmp.Or(o.name, filepath.Base(path))
+	}
+
+	if len(o.name) == 0 {
+		return errors.New("--name is required when reading the file from stdin")
+	}
+
+	s, err := o.secretNamed(ctx, o.StdioOptions, secretName)
+	if err != nil {
+		return err
+	}
+
+	v, err := o.vaultOf(ctx, o.StdioOptions, s)
+	if err != nil {
+		return err
+	}
+
+	a, err := v.Attach(ctx, s.id, o.name, r)
+	if err != nil {
+		return err
+	}
+
+	o.Infof("Attached %q to %q (%s)\n", a.Name, s.name, formatSize(a.Size))
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		o.Warnf("Post-write hook failed: %v", err)
+	}
+
+	return nil
+}
+
+// secretNamed returns the secret with exactly the given name.
+func (o *VaultOptions) secretNamed(ctx context.Context, io *genericclioptions.StdioOptions, name string) (secretWithLabels, error) {
+	secrets, err := o.searchAll(ctx, io, &SearchableOptions{Name: name})
+	if err != nil {
+		return secretWithLabels{}, err
+	}
+
+	secrets = slices.DeleteFunc(secrets, func(s secretWithLabels) bool { return s.name != name })
+
+	switch {
+	case len(secrets) == 0:
+		return secretWithLabels{}, o.notFound(ctx, io, name)
+	case len(secrets) > 1:
+		return secretWithLabels{}, fmt.Errorf("%q: %w", name, vaulterrors.ErrAmbiguousSecretMatch)
+	}
+
+	return secrets[0], nil
+}
+
+// formatSize formats n bytes in binary units, e.g., 1.5 KiB.
+func formatSize(n int64) string {
+	const unit = 1024
+
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// NewCmdAttachment creates the attachment cobra command with its sub-commands.
+func NewCmdAttachment(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "attachment",
+		Aliases: []string{"attachments"},
+		Short:   "Manage the files attached to secrets (subcommands available)",
+		Long: `Manage the files attached to secrets using 'vlt attach', e.g., certificates
+or kubeconfigs kept along with their credentials.
+
+Attachments are stored in the vault file, outside of the encrypted vault, in
+separately encrypted chunks, so that they are streamed without being loaded
+into memory. As such, they are not included in backups or sync, and are only
+exported using 'vlt export --attachments'.
+Attachments are removed along with their secret.`,
+	}
+
+	cmd.AddCommand(NewCmdAttachmentList(defaults))
+	cmd.AddCommand(NewCmdAttachmentGet(defaults))
+	cmd.AddCommand(NewCmdAttachmentRemove(defaults))
+
+	return cmd
+}
+
+// AttachmentListOptions holds data required to run the command.
+type AttachmentListOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &AttachmentListOptions{}
+
+// NewAttachmentListOptions initializes the options struct.
+func NewAttachmentListOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *AttachmentListOptions {
+	return &AttachmentListOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*AttachmentListOptions) Complete() error { return nil }
+
+func (*AttachmentListOptions) Validate() error { return nil }
+
+func (o *AttachmentListOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &AttachmentError{retErr}
+			return
+		}
+	}()
+
+	s, err := o.secretNamed(ctx, o.StdioOptions, args[0])
+	if err != nil {
+		return err
+	}
+
+	v, err := o.vaultOf(ctx, o.StdioOptions, s)
+	if err != nil {
+		return err
+	}
+
+	attachments, err := v.Attachments(ctx, s.id)
+	if err != nil {
+		return err
+	}
+
+	if len(attachments) == 0 {
+		o.Warnf("No attachments found.\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "NAME\tSIZE\tCREATED")
+
+	for _, a := range attachments {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", a.Name, formatSize(a.Size), a.CreatedAt.Local().Format(time.DateTime))
+	}
+
+	return nil
+}
+
+// AttachmentGetOptions holds data required to run the command.
+type AttachmentGetOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	output string // output is the path the attachment is written to, or '-' for stdout.
+}
+
+var _ genericclioptions.CmdOptions = &AttachmentGetOptions{}
+
+// NewAttachmentGetOptions initializes the options struct.
+func NewAttachmentGetOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *AttachmentGetOptions {
+	return &AttachmentGetOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*AttachmentGetOptions) Complete() error { return nil }
+
+func (*AttachmentGetOptions) Validate() error { return nil }
+
+func (o *AttachmentGetOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &AttachmentError{retErr}
+			return
+		}
+	}()
+
+	secretName, name := args[0], args[1]
+
+	s, err := o.secretNamed(ctx, o.StdioOptions, secretName)
+	if err != nil {
+		return err
+	}
+
+	v, err := o.vaultOf(ctx, o.StdioOptions, s)
+	if err != nil {
+		return err
+	}
+
+	if o.output == "-" {
+		_, err := v.WriteAttachment(ctx, s.id, name, o.Out)
+		return err
+	}
+
+	// by default, the attachment is written to the current directory,
+	// never overwriting an existing file.
+	path, flag := o.output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC
+	if len(path) == 0 {
+		path, flag = filepath.Base(name), os.O_WRONLY|os.O_CREATE|os.O_EXCL
+	}
+
+	f, err := os.OpenFile(path, flag, 0o600)
+	if err != nil {
+		return err
+	}
+
+	n, err := v.WriteAttachment(ctx, s.id, name, f)
+	if err := errors.Join(err, f.Close()); err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+
+	o.Infof("Wrote %q to %s (%s)\n", name, path, formatSize(n))
+
+	return nil
+}
+
+// AttachmentRemoveOptions holds data required to run the command.
+type AttachmentRemoveOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &AttachmentRemoveOptions{}
+
+// NewAttachmentRemoveOptions initializes the options struct.
+func NewAttachmentRemoveOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *AttachmentRemoveOptions {
+	return &AttachmentRemoveOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*AttachmentRemoveOptions) Complete() error { return nil }
+
+func (*AttachmentRemoveOptions) Validate() error { return nil }
+
+func (o *AttachmentRemoveOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &AttachmentError{retErr}
+			return
+		}
+	}()
+
+	secretName, name := args[0], args[1]
+
+	s, err := o.secretNamed(ctx, o.StdioOptions, secretName)
+	if err != nil {
+		return err
+	}
+
+	v, err := o.vaultOf(ctx, o.StdioOptions, s)
+	if err != nil {
+		return err
+	}
+
+	if err := v.RemoveAttachment(ctx, s.id, name); err != nil {
+		return err
+	}
+
+	o.Infof("Removed %q from %q\n", name, s.name)
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		o.Warnf("Post-write hook failed: %v", err)
+	}
+
+	return nil
+}
+
+// NewCmdAttach creates the attach cobra command.
+func NewCmdAttach(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewAttachOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "attach SECRET FILE",
+		Short: "Attach a file to a secret",
+		Long: `Attach FILE to the secret with exactly the given name, replacing any
+attachment of the same name. FILE '-' reads the file from stdin.
+
+The file is streamed into the vault in encrypted chunks, see 'vlt attachment'.`,
+		Example: `  # Attach a kubeconfig to the cluster token
+  vlt attach prod/cluster ~/.kube/config --name kubeconfig
+
+  # Attach a certificate read from stdin
+  openssl x509 -in cert.pem | vlt attach tls - --name cert.pem`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.name, "name", "", "", "the attachment name (default: the file name)")
+
+	return cmd
+}
+
+// NewCmdAttachmentList creates the attachment list cobra command.
+func NewCmdAttachmentList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewAttachmentListOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "list SECRET",
+		Aliases: []string{"ls"},
+		Short:   "List the files attached to a secret",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
+
+// NewCmdAttachmentGet creates the attachment get cobra command.
+func NewCmdAttachmentGet(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewAttachmentGetOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "get SECRET NAME",
+		Short: "Write a file attached to a secret",
+		Long: `Write the attachment NAME of the given secret, decrypted one chunk at a time.
+
+The attachment is written to a file of the same name in the current
+directory by default, failing if it exists. Files are created readable
+by the current user only.`,
+		Example: `  # Write the kubeconfig to a given path
+  vlt attachment get prod/cluster kubeconfig -o ~/.kube/config
+
+  # Print a certificate
+  vlt attachment get tls cert.pem -o - | openssl x509 -noout -text`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", "write the attachment to the given path, or '-' for stdout")
+
+	return cmd
+}
+
+// NewCmdAttachmentRemove creates the attachment remove cobra command.
+func NewCmdAttachmentRemove(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewAttachmentRemoveOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "remove SECRET NAME",
+		Aliases: []string{"rm"},
+		Short:   "Remove a file attached to a secret",
+		Args:    cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
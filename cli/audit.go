@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/policy"
+
+	"github.com/spf13/cobra"
+)
+
+type AuditError struct {
+	Err error
+}
+
+func (e *AuditError) Error() string { return "audit: " + e.Err.Error() }
+
+func (e *AuditError) Unwrap() error { return e.Err }
+
+// AuditOptions holds data required to run the command.
+type AuditOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &AuditOptions{}
+
+// NewAuditOptions initializes the options struct.
+func NewAuditOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *AuditOptions {
+	return &AuditOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*AuditOptions) Complete() error { return nil }
+
+func (*AuditOptions) Validate() error { return nil }
+
+// policyViolation is a password policy violation of a stored secret.
+type policyViolation struct {
+	id        int
+	name      string
+	violation policy.Violation
+}
+
+func (o *AuditOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &AuditError{retErr}
+			return
+		}
+	}()
+
+	set := o.passwordPolicy.set
+	if set.IsZero() {
+		o.Infof("No password policy configured; nothing to audit.\n")
+		return nil
+	}
+
+	secrets, err := o.vault.ExportSecrets(ctx)
+	if err != nil {
+		return err
+	}
+
+	changedAt, err := o.vault.SecretsChangedAt(ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(secrets))
+	for id := range secrets {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	var (
+		now        = time.Now()
+		violations []policyViolation
+		violating  = 0
+	)
+
+	for _, id := range ids {
+		s := secrets[id]
+		p, _ := set.For(s.Labels)
+
+		vs := p.Check(s.Value)
+		if v, ok := p.CheckLabels(s.Labels); ok {
+			vs = append(vs, v)
+		}
+
+		if v, ok := p.CheckAge(changedAt[id], now); ok {
+			vs = append(vs, v)
+		}
+
+		if len(vs) > 0 {
+			violating++
+		}
+
+		for _, v := range vs {
+			violations = append(violations, policyViolation{id: id, name: s.Name, violation: v})
+		}
+	}
+
+	if violating == 0 {
+		o.Infof("All %d secrets comply with the password policy.\n", len(ids))
+		return nil
+	}
+
+	printViolationsTable(o.Out, violations)
+
+	return fmt.Errorf("%d of %d secrets violate the password policy", violating, len(ids))
+}
+
+func printViolationsTable(w io.Writer, violations []policyViolation) {
+	tw := tabwriter.NewWriter(w, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tRULE\tDETAILS")
+
+	for _, v := range violations {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", v.id, v.name, v.violation.Rule, v.violation.Message)
+	}
+
+	fmt.Fprintln(tw) // add padding
+}
+
+// NewCmdAudit creates the audit cobra command.
+func NewCmdAudit(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewAuditOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "audit",
+		Short: "Report stored secrets that violate the password policy",
+		Long: `Evaluate all stored secrets against the configured password policy.
+
+Every secret is checked against the policy effective for its labels, including
+label-scoped overrides: its length, required character classes and banned words,
+the '2fa' label if required, and the time since its value was last changed.
+
+The command exits with a non-zero status if any secret violates the policy,
+regardless of the configured policy mode.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
@@ -0,0 +1,452 @@
+package cli
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/gpg"
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+
+	"github.com/spf13/cobra"
+)
+
+// auditFormatJSONL is the json lines audit log export format.
+const auditFormatJSONL = "jsonl"
+
+// auditOperations lists the operations recorded in the audit log.
+var auditOperations = []vaultdb.Operation{
+	vaultdb.OpInsert,
+	vaultdb.OpShow,
+	vaultdb.OpUpdate,
+	vaultdb.OpUpdateMetadata,
+	vaultdb.OpDelete,
+	vaultdb.OpExport,
+}
+
+type AuditLogError struct {
+	Err error
+}
+
+func (e *AuditLogError) Error() string { return "audit-log: " + e.Err.Error() }
+
+func (e *AuditLogError) Unwrap() error { return e.Err }
+
+// NewCmdAuditLog creates the audit-log cobra command tree.
+func NewCmdAuditLog(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit-log",
+		Short: "Inspect the vault audit log (subcommands available)",
+		Long: `Inspect the vault audit log.
+
+Every access to a secret value and every vault mutation is recorded
+in the encrypted audit log. Secret values are never recorded.
+
+Entries are hash-chained, so modifying or removing an entry breaks the chain.
+Signed checkpoints additionally protect against rewriting the chain as a whole.`,
+	}
+
+	cmd.AddCommand(NewCmdAuditLogExport(defaults))
+	cmd.AddCommand(NewCmdAuditLogVerify(defaults))
+	cmd.AddCommand(NewCmdAuditLogCheckpoint(defaults))
+
+	return cmd
+}
+
+// AuditLogExportOptions holds data required to run the command.
+type AuditLogExportOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	since      string
+	format     string
+	operations []string
+	secret     string
+
+	filters vaultdb.AuditFilters
+}
+
+var _ genericclioptions.CmdOptions = &AuditLogExportOptions{}
+
+// NewAuditLogExportOptions initializes the options struct.
+func NewAuditLogExportOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *AuditLogExportOptions {
+	return &AuditLogExportOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		format:       auditFormatJSONL,
+	}
+}
+
+func (o *AuditLogExportOptions) Complete() error {
+	if len(o.since) > 0 {
+		t, err := parseSince(o.since, time.Now())
+		if err != nil {
+			return &AuditLogError{err}
+		}
+
+		o.filters.Since = t
+	}
+
+	for _, op := range o.operations {
+		o.filters.Operations = append(o.filters.Operations, vaultdb.Operation(op))
+	}
+
+	o.filters.Secret = o.secret
+
+	return nil
+}
+
+func (o *AuditLogExportOptions) Validate() error {
+	if o.format != auditFormatJSONL {
+		return &AuditLogError{fmt.Errorf("unsupported output format %q (supported: %s)", o.format, auditFormatJSONL)}
+	}
+
+	for _, op := range o.filters.Operations {
+		if !slices.Contains(auditOperations, op) {
+			return &AuditLogError{fmt.Errorf("unknown operation %q", op)}
+		}
+	}
+
+	return nil
+}
+
+// auditRecord is the exported representation of a [vaultdb.AuditEntry].
+//
+//nolint:tagliatelle
+type auditRecord struct {
+	ID         int       `json:"id"`
+	Time       time.Time `json:"time"`
+	Vault      string    `json:"vault"`
+	Operation  string    `json:"operation"`
+	SecretID   int       `json:"secret_id,omitempty"`
+	SecretName string    `json:"secret_name,omitempty"`
+	Actor      string    `json:"actor,omitempty"`
+	Hash       string    `json:"hash,omitempty"`
+}
+
+func (o *AuditLogExportOptions) Run(ctx context.Context, _ ...string) error {
+	enc := json.NewEncoder(o.Out)
+
+	for entry, err := range o.vault.AuditLog(ctx, o.filters) {
+		if err != nil {
+			return &AuditLogError{err}
+		}
+
+		r := auditRecord{
+			ID:         entry.ID,
+			Time:       entry.CreatedAt,
+			Vault:      o.path,
+			Operation:  string(entry.Operation),
+			SecretID:   entry.SecretID,
+			SecretName: entry.SecretName,
+			Actor:      entry.Actor,
+			Hash:       hex.EncodeToString(entry.Hash),
+		}
+
+		if err := enc.Encode(r); err != nil {
+			return &AuditLogError{err}
+		}
+	}
+
+	return nil
+}
+
+// NewCmdAuditLogExport creates the audit-log export cobra command.
+func NewCmdAuditLogExport(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewAuditLogExportOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	ops := make([]string, len(auditOperations))
+	for i, op := range auditOperations {
+		ops[i] = string(op)
+	}
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Stream audit log entries in a machine-readable format",
+		Long: fmt.Sprintf(`Stream audit log entries to stdout, one JSON object per line.
+
+The output is intended for ingestion into log processing (e.g., SIEM) tooling.
+
+Supported operations: %s.`, strings.Join(ops, ", ")),
+		Example: `  # Export the last 30 days of the audit log
+  vlt audit-log export --since 30d -o jsonl
+
+  # Export all secret value accesses of secrets matching a glob pattern
+  vlt audit-log export --operation show --secret "*github*"`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.since, "since", "", "", "only include entries newer than a relative age (e.g., 12h, 30d) or a date (e.g., 2006-01-02)")
+	cmd.Flags().StringVarP(&o.format, "output", "o", auditFormatJSONL, "output format (jsonl)")
+	cmd.Flags().StringSliceVarP(&o.operations, "operation", "", nil, "filter by operation type (comma-separated or repeated)")
+	cmd.Flags().StringVarP(&o.secret, "secret", "", "", "filter by secret name (supports glob patterns)")
+
+	return cmd
+}
+
+// AuditLogVerifyOptions holds data required to run the command.
+type AuditLogVerifyOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config *ResolvedConfig
+
+	// gpgKey is the key checkpoints must be signed by. If set,
+	// at least one checkpoint is required.
+	gpgKey string
+}
+
+var _ genericclioptions.CmdOptions = &AuditLogVerifyOptions{}
+
+// NewAuditLogVerifyOptions initializes the options struct.
+func NewAuditLogVerifyOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *AuditLogVerifyOptions {
+	return &AuditLogVerifyOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+	}
+}
+
+func (o *AuditLogVerifyOptions) Complete() error {
+	if len(o.gpgKey) == 0 {
+		o.gpgKey = o.config.AuditGPGKey
+	}
+
+	return nil
+}
+
+func (*AuditLogVerifyOptions) Validate() error { return nil }
+
+func (o *AuditLogVerifyOptions) Run(ctx context.Context, _ ...string) error {
+	v, err := o.vault.VerifyAuditLog(ctx)
+	if err != nil {
+		return &AuditLogError{err}
+	}
+
+	// the stored checkpoint signer is not trusted, signatures
+	// are checked against the fingerprint of the configured key instead.
+	var fingerprint string
+
+	if len(o.gpgKey) > 0 {
+		if fingerprint, err = gpg.Fingerprint(ctx, o.gpgKey); err != nil {
+			return &AuditLogError{err}
+		}
+	}
+
+	for _, c := range v.Checkpoints {
+		payload := vault.AuditCheckpointPayload(c.EntryID, c.Hash)
+
+		signer, err := gpg.Verify(ctx, c.Signature, payload)
+		if err != nil {
+			return &AuditLogError{fmt.Errorf("%w: checkpoint %d at entry %d: %w", vault.ErrAuditLogTampered, c.ID, c.EntryID, err)}
+		}
+
+		if len(fingerprint) > 0 && !strings.EqualFold(signer, fingerprint) {
+			return &AuditLogError{fmt.Errorf("%w: checkpoint %d at entry %d: signed by %s, expecting %s", vault.ErrAuditLogTampered, c.ID, c.EntryID, signer, fingerprint)}
+		}
+
+		o.Debugf("checkpoint %d at entry %d: signature by %s verified\n", c.ID, c.EntryID, signer)
+	}
+
+	if v.Unchained > 0 {
+		o.Warnf("%d entries predate hash chaining and cannot be verified.\n", v.Unchained)
+	}
+
+	o.Infof("audit log: %d entries verified\n", v.Entries)
+
+	if v.Entries > 0 {
+		o.Infof("chain head: entry %d (%s)\n", v.Head.ID, hex.EncodeToString(v.Head.Hash))
+	}
+
+	if len(v.Checkpoints) == 0 {
+		// checkpoints are stored in the vault, and can be removed along with the chain rewritten.
+		if len(fingerprint) > 0 {
+			return &AuditLogError{fmt.Errorf("%w: no signed checkpoints, expecting checkpoints signed by %s; use 'vlt audit-log checkpoint' to create one", vault.ErrAuditLogTampered, fingerprint)}
+		}
+
+		o.Warnf("No signed checkpoints found; use 'vlt audit-log checkpoint' to create one.\n")
+
+		return nil
+	}
+
+	if len(fingerprint) == 0 {
+		o.Warnf("Checkpoints signed by any trusted key were accepted; set 'audit.gpg_key' or use --gpg-key to require a specific signer.\n")
+	}
+
+	latest := v.Checkpoints[len(v.Checkpoints)-1]
+	o.Infof("signed checkpoints: %d verified (latest at entry %d)\n", len(v.Checkpoints), latest.EntryID)
+
+	if latest.EntryID != v.Head.ID {
+		o.Warnf("%d entries were recorded after the latest checkpoint.\n", v.Head.ID-latest.EntryID)
+	}
+
+	return nil
+}
+
+// NewCmdAuditLogVerify creates the audit-log verify cobra command.
+func NewCmdAuditLogVerify(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewAuditLogVerifyOptions(
+		defaults.StdioOptions,
+		defaults.vaultOptions,
+		defaults.configOptions.resolved,
+	)
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the audit log hash chain and signed checkpoints",
+		Long: `Verify the integrity of the audit log.
+
+Every entry is checked against the hash of its predecessor, and every signed
+checkpoint is checked against the chain and its signature verified using gpg.
+
+If 'audit.gpg_key' is set, or --gpg-key is given, checkpoints must be signed by
+that key, and verification fails if there are no checkpoints at all, as they
+are stored in the vault and could be removed along with a rewritten chain.
+Otherwise, signatures by any trusted key in the keyring are accepted.`,
+		Example: `  # Verify the audit log, requiring checkpoints signed by the configured 'audit.gpg_key'
+  vlt audit-log verify
+
+  # Verify the audit log, requiring checkpoints signed by a specific key
+  vlt audit-log verify --gpg-key alice@example.com`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.gpgKey, "gpg-key", "", "", "gpg key checkpoints must be signed by (overrides 'audit.gpg_key')")
+
+	return cmd
+}
+
+// AuditLogCheckpointOptions holds data required to run the command.
+type AuditLogCheckpointOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config *ResolvedConfig
+	gpgKey string
+}
+
+var _ genericclioptions.CmdOptions = &AuditLogCheckpointOptions{}
+
+// NewAuditLogCheckpointOptions initializes the options struct.
+func NewAuditLogCheckpointOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *AuditLogCheckpointOptions {
+	return &AuditLogCheckpointOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+	}
+}
+
+func (o *AuditLogCheckpointOptions) Complete() error {
+	if len(o.gpgKey) == 0 {
+		o.gpgKey = o.config.AuditGPGKey
+	}
+
+	return nil
+}
+
+func (o *AuditLogCheckpointOptions) Validate() error {
+	if len(o.gpgKey) == 0 {
+		return &AuditLogError{errors.New("no signing key; use --gpg-key or set 'audit.gpg_key' in the config")}
+	}
+
+	return nil
+}
+
+func (o *AuditLogCheckpointOptions) Run(ctx context.Context, _ ...string) error {
+	if _, err := o.vault.VerifyAuditLog(ctx); err != nil {
+		return &AuditLogError{fmt.Errorf("refusing to checkpoint: %w", err)}
+	}
+
+	head, ok, err := o.vault.AuditHead(ctx)
+	if err != nil {
+		return &AuditLogError{err}
+	}
+
+	if !ok || head.Hash == nil {
+		return &AuditLogError{errors.New("no chained audit log entries to checkpoint")}
+	}
+
+	fingerprint, err := gpg.Fingerprint(ctx, o.gpgKey)
+	if err != nil {
+		return &AuditLogError{err}
+	}
+
+	signature, err := gpg.Sign(ctx, fingerprint, vault.AuditCheckpointPayload(head.ID, head.Hash))
+	if err != nil {
+		return &AuditLogError{err}
+	}
+
+	if err := o.vault.InsertAuditCheckpoint(ctx, head.ID, head.Hash, fingerprint, signature); err != nil {
+		return &AuditLogError{err}
+	}
+
+	o.Infof("checkpoint signed at entry %d (%s)\n", head.ID, hex.EncodeToString(head.Hash))
+
+	return nil
+}
+
+// NewCmdAuditLogCheckpoint creates the audit-log checkpoint cobra command.
+func NewCmdAuditLogCheckpoint(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewAuditLogCheckpointOptions(
+		defaults.StdioOptions,
+		defaults.vaultOptions,
+		defaults.configOptions.resolved,
+	)
+
+	cmd := &cobra.Command{
+		Use:   "checkpoint",
+		Short: "Sign the current audit log chain head",
+		Long: `Sign the current audit log chain head using gpg and store the signature in the vault.
+
+An attacker with access to the vault password can recompute the hash chain,
+but cannot forge checkpoint signatures without the signing key.
+
+Checkpoints are signed using gpg only: age keys encrypt, but cannot sign.`,
+		Example: `  # Sign a checkpoint using the configured 'audit.gpg_key'
+  vlt audit-log checkpoint
+
+  # Sign a checkpoint using a specific key
+  vlt audit-log checkpoint --gpg-key alice@example.com`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.gpgKey, "gpg-key", "", "", "gpg key used for signing (overrides 'audit.gpg_key')")
+
+	return cmd
+}
+
+// parseSince parses s as either a relative age counted back from now,
+// or an absolute date.
+//
+// Relative ages accept any [time.ParseDuration] value,
+// as well as whole days (e.g., 30d) and weeks (e.g., 2w).
+// Absolute dates accept [time.DateOnly], [time.DateTime] with or without seconds,
+// and [time.RFC3339] layouts.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	for _, layout := range []string{time.DateOnly, "2006-01-02 15:04", time.DateTime, time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	if d, err := cmdutil.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time value %q", s)
+}
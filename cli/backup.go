@@ -0,0 +1,596 @@
+package cli
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/input"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaultbackup"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+type BackupError struct {
+	Err error
+}
+
+func (e *BackupError) Error() string { return "backup: " + e.Err.Error() }
+
+func (e *BackupError) Unwrap() error { return e.Err }
+
+// BackupOptions holds data required to run the command.
+type BackupOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config      *ResolvedConfig
+	incremental bool
+	fullEvery   int
+	retention   vaultbackup.Retention
+}
+
+var _ genericclioptions.CmdOptions = &BackupOptions{}
+
+// NewBackupOptions initializes the options struct.
+func NewBackupOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *BackupOptions {
+	return &BackupOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+	}
+}
+
+func (o *BackupOptions) Complete() error {
+	o.fullEvery = cmp.Or(o.fullEvery, o.config.BackupFullEvery)
+
+	if !o.retention.Enabled() {
+		o.retention = o.config.BackupRetention
+	}
+
+	return nil
+}
+
+func (o *BackupOptions) Validate() error {
+	if o.fullEvery < 0 {
+		return &BackupError{errors.New("--full-every must not be negative")}
+	}
+
+	return validateRetention(o.retention)
+}
+
+func (o *BackupOptions) Run(ctx context.Context, args ...string) error {
+	dir, err := backupDir(o.config, args)
+	if err != nil {
+		return err
+	}
+
+	opts := vaultbackup.Options{
+		Incremental: o.incremental,
+		FullEvery:   o.fullEvery,
+	}
+
+	if err := runBackup(ctx, o.StdioOptions, o.VaultOptions, dir, opts, o.retention); err != nil {
+		return &BackupError{err}
+	}
+
+	return nil
+}
+
+// runBackup backs up the vault to the given directory, prunes the backups
+// outside of the retention policy, if enabled, and runs the post backup hook.
+func runBackup(ctx context.Context, io *genericclioptions.StdioOptions, o *VaultOptions, dir string, opts vaultbackup.Options, retention vaultbackup.Retention) error {
+	d, err := vaultbackup.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	e, err := d.Backup(ctx, o.vault, opts)
+	if err != nil {
+		return err
+	}
+
+	if e.Kind == vaultbackup.KindDelta {
+		io.Infof("Created incremental backup %s (%d bytes), applying to snapshot %s.\n", e.File, e.Size, e.Base)
+	} else {
+		io.Infof("Created full snapshot %s (%d bytes).\n", e.File, e.Size)
+	}
+
+	if retention.Enabled() {
+		removed, err := d.Prune(retention, false)
+		if err != nil {
+			return err
+		}
+
+		if len(removed) > 0 {
+			io.Infof("Pruned %d backups outside of the retention policy.\n", len(removed))
+		}
+	}
+
+	if hook := o.hooks.postBackup; len(hook) > 0 {
+		io.Infof("running hook: %q %q\n", hook[0], hook[1:])
+
+		path := bytes.NewBufferString(filepath.Join(d.Path, e.File) + "\n")
+		if err := genericclioptions.RunCommandWithInput(ctx, io, path, hook[0], hook[1:]...); err != nil {
+			return fmt.Errorf("post backup hook: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// backupBeforeDestructive backs up the vault before running the given
+// destructive command, if enabled by the 'backup.before_destructive' config.
+//
+// The command is aborted if the backup fails.
+func (o *DefaultVltOptions) backupBeforeDestructive(ctx context.Context, cmd string) error {
+	c := o.configOptions.resolved
+	if !c.BackupBeforeDestructive {
+		return nil
+	}
+
+	o.Infof("vlt: backing up the vault before %q\n", cmd)
+
+	if err := o.autoBackup(ctx); err != nil {
+		return &BackupError{fmt.Errorf("refusing to run %q: %w", cmd, err)}
+	}
+
+	return nil
+}
+
+// backupOnMutation backs up the vault once the number of secrets changed since
+// the latest backup reaches the 'backup.every' config.
+//
+// Failures are only reported, as the changes made by the command are not saved yet.
+func (o *DefaultVltOptions) backupOnMutation(ctx context.Context) {
+	c := o.configOptions.resolved
+	if c.BackupEvery == 0 || o.vaultOptions.vault == nil || len(o.vaultOptions.token) > 0 {
+		return
+	}
+
+	d, err := vaultbackup.Open(c.BackupDir)
+	if err != nil {
+		o.Warnf("vlt: automatic backup failed: %v\n", err)
+		return
+	}
+
+	var since vault.BackupState
+	if latest, ok := d.Latest(); ok {
+		since = latest.State
+	}
+
+	n, err := o.vaultOptions.vault.ChangesSince(ctx, &since)
+	if err != nil {
+		o.Warnf("vlt: automatic backup failed: %v\n", err)
+		return
+	}
+
+	if n < c.BackupEvery {
+		o.Debugf("vlt: %d of %d secret changes until the next automatic backup\n", n, c.BackupEvery)
+		return
+	}
+
+	o.Infof("vlt: backing up the vault after %d secret changes\n", n)
+
+	if err := o.autoBackup(ctx); err != nil {
+		o.Warnf("vlt: automatic backup failed: %v\n", err)
+	}
+}
+
+func (o *DefaultVltOptions) autoBackup(ctx context.Context) error {
+	c := o.configOptions.resolved
+
+	opts := vaultbackup.Options{
+		Incremental: true,
+		FullEvery:   c.BackupFullEvery,
+	}
+
+	return runBackup(ctx, o.StdioOptions, o.vaultOptions, c.BackupDir, opts, c.BackupRetention)
+}
+
+// NewCmdBackup creates the backup cobra command.
+func NewCmdBackup(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewBackupOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "backup [DIR]",
+		Short: "Back up the vault to a directory (subcommands available)",
+		Long: `Back up the vault to a directory.
+
+If DIR is not given, the 'backup.dir' config is used.
+
+By default, a full snapshot of the vault is created. A snapshot is a copy of the
+encrypted vault file, it can be opened using the master password, e.g., 'vlt -f <snapshot> find'.
+
+With --incremental, only the changes made since the latest snapshot are saved,
+encrypted using a key derived from the master key. A new full snapshot is still
+created every --full-every backups, or if the master password was changed since
+the latest snapshot.
+
+Backups are tracked by an index file in DIR (see 'vlt backup list').
+
+If a retention policy is configured, backups outside of it are pruned
+after each backup (see 'vlt backup prune').
+
+Automatic backups are configured in the 'backup' config section.`,
+		Example: `  # Daily incremental backups, with a weekly full snapshot
+  vlt backup --incremental ~/backups/vlt`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.incremental, "incremental", "i", false, "save the changes made since the latest snapshot only")
+	cmd.Flags().IntVarP(&o.fullEvery, "full-every", "", 0,
+		fmt.Sprintf("number of backups per full snapshot in incremental mode (default: 'backup.full_every' or %d)", vaultbackup.DefaultFullEvery))
+	addRetentionFlags(cmd, &o.retention)
+
+	cmd.AddCommand(NewCmdBackupList(defaults))
+	cmd.AddCommand(NewCmdBackupPrune(defaults))
+	cmd.AddCommand(NewCmdBackupVerify(defaults))
+
+	return cmd
+}
+
+// BackupListOptions holds data required to run the command.
+type BackupListOptions struct {
+	*genericclioptions.StdioOptions
+
+	config *ResolvedConfig
+}
+
+var _ genericclioptions.CmdOptions = &BackupListOptions{}
+
+// NewBackupListOptions initializes the options struct.
+func NewBackupListOptions(stdio *genericclioptions.StdioOptions, config *ResolvedConfig) *BackupListOptions {
+	return &BackupListOptions{
+		StdioOptions: stdio,
+		config:       config,
+	}
+}
+
+func (*BackupListOptions) Complete() error { return nil }
+
+func (*BackupListOptions) Validate() error { return nil }
+
+func (o *BackupListOptions) Run(_ context.Context, args ...string) error {
+	dir, err := backupDir(o.config, args)
+	if err != nil {
+		return err
+	}
+
+	d, err := vaultbackup.Open(dir)
+	if err != nil {
+		return &BackupError{err}
+	}
+
+	entries := d.Entries()
+	if len(entries) == 0 {
+		o.Infof("No backups found in %s.\n", dir)
+		return nil
+	}
+
+	printBackupsTable(o.Out, entries)
+
+	return nil
+}
+
+// NewCmdBackupList creates the backup list cobra command.
+func NewCmdBackupList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewBackupListOptions(defaults.StdioOptions, defaults.configOptions.resolved)
+
+	return &cobra.Command{
+		Use:     "list [DIR]",
+		Aliases: []string{"ls"},
+		Short:   "List the backups in a directory",
+		Long: `List the backups in a directory, oldest first.
+
+If DIR is not given, the 'backup.dir' config is used.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
+
+// BackupPruneOptions holds data required to run the command.
+type BackupPruneOptions struct {
+	*genericclioptions.StdioOptions
+
+	config    *ResolvedConfig
+	retention vaultbackup.Retention
+	dryRun    bool
+}
+
+var _ genericclioptions.CmdOptions = &BackupPruneOptions{}
+
+// NewBackupPruneOptions initializes the options struct.
+func NewBackupPruneOptions(stdio *genericclioptions.StdioOptions, config *ResolvedConfig) *BackupPruneOptions {
+	return &BackupPruneOptions{
+		StdioOptions: stdio,
+		config:       config,
+	}
+}
+
+func (o *BackupPruneOptions) Complete() error {
+	if !o.retention.Enabled() {
+		o.retention = o.config.BackupRetention
+	}
+
+	return nil
+}
+
+func (o *BackupPruneOptions) Validate() error {
+	if !o.retention.Enabled() {
+		return &BackupError{errors.New("no retention policy; use --keep-daily, --keep-weekly, --keep-monthly or set them in the 'backup' config section")}
+	}
+
+	return validateRetention(o.retention)
+}
+
+func (o *BackupPruneOptions) Run(_ context.Context, args ...string) error {
+	dir, err := backupDir(o.config, args)
+	if err != nil {
+		return err
+	}
+
+	d, err := vaultbackup.Open(dir)
+	if err != nil {
+		return &BackupError{err}
+	}
+
+	removed, err := d.Prune(o.retention, o.dryRun)
+	if err != nil {
+		return &BackupError{err}
+	}
+
+	if len(removed) == 0 {
+		o.Infof("No backups to prune.\n")
+		return nil
+	}
+
+	if o.dryRun {
+		o.Infof("Would remove %d of %d backups:\n\n", len(removed), len(d.Entries()))
+	} else {
+		o.Infof("Removed %d backups:\n\n", len(removed))
+	}
+
+	printBackupsTable(o.Out, removed)
+
+	return nil
+}
+
+// NewCmdBackupPrune creates the backup prune cobra command.
+func NewCmdBackupPrune(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewBackupPruneOptions(defaults.StdioOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "prune [DIR]",
+		Short: "Remove backups outside of the retention policy",
+		Long: `Remove backups outside of the retention policy.
+
+For each of the last --keep-daily days, --keep-weekly weeks and --keep-monthly months,
+the latest backup made during that period is kept. The latest backup is always kept,
+along with the snapshots incremental backups apply to.
+
+If no retention flags are given, the policy set in the 'backup' config section is used.
+If DIR is not given, the 'backup.dir' config is used.`,
+		Example: `  # List the backups that would be removed
+  vlt backup prune --keep-daily 7 --keep-weekly 4 --keep-monthly 12 --dry-run ~/backups/vlt
+
+  # Prune using the configured retention policy
+  vlt backup prune ~/backups/vlt`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	addRetentionFlags(cmd, &o.retention)
+	cmd.Flags().BoolVarP(&o.dryRun, "dry-run", "n", false, "list the backups that would be removed, without removing them")
+
+	return cmd
+}
+
+// BackupVerifyOptions holds data required to run the command.
+type BackupVerifyOptions struct {
+	*genericclioptions.StdioOptions
+
+	config *ResolvedConfig
+	sample int
+}
+
+var _ genericclioptions.CmdOptions = &BackupVerifyOptions{}
+
+// NewBackupVerifyOptions initializes the options struct.
+func NewBackupVerifyOptions(stdio *genericclioptions.StdioOptions, config *ResolvedConfig) *BackupVerifyOptions {
+	return &BackupVerifyOptions{
+		StdioOptions: stdio,
+		config:       config,
+	}
+}
+
+func (*BackupVerifyOptions) Complete() error { return nil }
+
+func (o *BackupVerifyOptions) Validate() error {
+	if o.NonInteractive {
+		return vaulterrors.ErrNonInteractiveUnsupported
+	}
+
+	if o.sample < 0 {
+		return &BackupError{errors.New("--sample must not be negative")}
+	}
+
+	return nil
+}
+
+func (o *BackupVerifyOptions) Run(ctx context.Context, args ...string) error {
+	d, e, err := o.resolveBackup(args)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := d.Snapshot(e)
+	if err != nil {
+		return &BackupError{err}
+	}
+
+	password, err := input.PromptReadSecure(o.Out, int(o.In.Fd()), "[vlt] Password for backup %q:", snapshot.ID)
+	if err != nil {
+		return fmt.Errorf("prompt password: %v", err)
+	}
+
+	res, err := d.Verify(ctx, e, o.sample, vault.WithPassword(password))
+	if err != nil {
+		return &BackupError{err}
+	}
+
+	o.Infof("Verified backup %s (%s), restored to audit log entry %d:\n", e.ID, e.Kind, res.State.AuditID)
+	o.Infof("  integrity:    ok\n")
+
+	if len(e.State.KeyID) > 0 {
+		o.Infof("  state:        ok (%d secrets, %d audit log entries)\n", res.State.Rows["secrets"], res.State.Rows["audit_log"])
+	} else {
+		o.Infof("  state:        not recorded, %s is not in the backup index\n", e.File)
+	}
+
+	o.Infof("  audit log:    ok (%d chained entries, %d checkpoints)\n", res.Audit.Entries, len(res.Audit.Checkpoints))
+	o.Infof("  decryption:   ok (%d of %d secrets)\n", res.Decrypted, res.State.Rows["secrets"])
+
+	return nil
+}
+
+// resolveBackup returns the backup to verify, given either as a backup file path
+// or a backup id in the configured backup directory, defaulting to the latest backup.
+func (o *BackupVerifyOptions) resolveBackup(args []string) (*vaultbackup.Dir, vaultbackup.Entry, error) {
+	if len(args) > 0 {
+		if _, err := os.Stat(args[0]); err == nil {
+			d, err := vaultbackup.Open(filepath.Dir(args[0]))
+			if err != nil {
+				return nil, vaultbackup.Entry{}, &BackupError{err}
+			}
+
+			name := filepath.Base(args[0])
+			if e, ok := d.EntryByFile(name); ok {
+				return d, e, nil
+			}
+
+			// a standalone snapshot, e.g., a copied vault file.
+			return d, vaultbackup.Entry{ID: name, Kind: vaultbackup.KindFull, File: name}, nil
+		}
+	}
+
+	dir, err := backupDir(o.config, nil)
+	if err != nil {
+		return nil, vaultbackup.Entry{}, err
+	}
+
+	d, err := vaultbackup.Open(dir)
+	if err != nil {
+		return nil, vaultbackup.Entry{}, &BackupError{err}
+	}
+
+	if len(args) > 0 {
+		e, ok := d.Entry(args[0])
+		if !ok {
+			return nil, vaultbackup.Entry{}, &BackupError{fmt.Errorf("no backup file or id %q in %s", args[0], dir)}
+		}
+
+		return d, e, nil
+	}
+
+	e, ok := d.Latest()
+	if !ok {
+		return nil, vaultbackup.Entry{}, &BackupError{fmt.Errorf("no backups found in %s", dir)}
+	}
+
+	return d, e, nil
+}
+
+// NewCmdBackupVerify creates the backup verify cobra command.
+func NewCmdBackupVerify(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewBackupVerifyOptions(defaults.StdioOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "verify [BACKUP]",
+		Short: "Verify that a backup restores",
+		Long: `Verify that a backup restores.
+
+BACKUP is either a backup file path, or a backup id in the 'backup.dir' config directory.
+If BACKUP is not given, the latest backup in the 'backup.dir' directory is verified.
+
+The backup is restored into a temporary file and opened read-only using the master
+password the vault had at the time of the backup, then:
+  - the vault integrity is verified.
+  - the restored state and row counts are compared to the ones recorded in the backup index.
+  - the audit log hash chain is verified, checkpoint signatures are not (see 'vlt audit-log verify').
+  - a sample of secrets is test-decrypted.
+
+The backup itself is never modified.`,
+		Example: `  # Verify the latest backup in the configured backup directory
+  vlt backup verify
+
+  # Verify a backup file, test-decrypting all secrets
+  vlt backup verify --sample 0 ~/backups/vlt/vlt-20240601T120000Z.full`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().IntVarP(&o.sample, "sample", "", 10, "number of randomly sampled secrets to test-decrypt, 0 for all")
+
+	return cmd
+}
+
+// backupDir returns the backup directory given in args, or the configured one.
+func backupDir(config *ResolvedConfig, args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	if len(config.BackupDir) == 0 {
+		return "", &BackupError{errors.New("no backup directory; pass DIR or set 'backup.dir' in the config")}
+	}
+
+	return config.BackupDir, nil
+}
+
+func addRetentionFlags(cmd *cobra.Command, r *vaultbackup.Retention) {
+	cmd.Flags().IntVarP(&r.Daily, "keep-daily", "", 0, "keep the latest backup of each of the last N days (overrides the configured policy)")
+	cmd.Flags().IntVarP(&r.Weekly, "keep-weekly", "", 0, "keep the latest backup of each of the last N weeks (overrides the configured policy)")
+	cmd.Flags().IntVarP(&r.Monthly, "keep-monthly", "", 0, "keep the latest backup of each of the last N months (overrides the configured policy)")
+}
+
+func validateRetention(r vaultbackup.Retention) error {
+	if r.Daily < 0 || r.Weekly < 0 || r.Monthly < 0 {
+		return &BackupError{errors.New("--keep-daily, --keep-weekly and --keep-monthly must not be negative")}
+	}
+
+	return nil
+}
+
+func printBackupsTable(w io.Writer, entries []vaultbackup.Entry) {
+	tw := tabwriter.NewWriter(w, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tKIND\tBASE\tCREATED\tSIZE")
+
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", e.ID, e.Kind, e.Base, e.CreatedAt.Local().Format(time.DateTime), e.Size)
+	}
+
+	fmt.Fprintln(tw) // add padding
+}
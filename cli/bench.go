@@ -0,0 +1,421 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaultcrypto"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats of 'vlt bench'.
+const (
+	benchOutputTable = "table"
+	benchOutputJSON  = "json"
+)
+
+const (
+	// benchKDFRuns is the number of key derivations timed.
+	benchKDFRuns = 3
+
+	// benchCryptoOps is the number of values encrypted and decrypted.
+	benchCryptoOps = 100_000
+
+	// benchSearchRuns is the number of times each search is run.
+	benchSearchRuns = 100
+
+	// benchLabels is the number of distinct labels of the scratch vault.
+	benchLabels = 10
+)
+
+type BenchError struct {
+	Err error
+}
+
+func (e *BenchError) Error() string { return "bench: " + e.Err.Error() }
+
+func (e *BenchError) Unwrap() error { return e.Err }
+
+// benchReport is the json output of 'vlt bench'.
+type benchReport struct {
+	GoVersion string        `json:"go_version"`
+	OS        string        `json:"os"`
+	Arch      string        `json:"arch"`
+	CPUs      int           `json:"cpus"`
+	KDF       benchKDF      `json:"kdf"`
+	Secrets   int           `json:"secrets"`
+	ValueSize int           `json:"value_size"`
+	Results   []benchResult `json:"results"`
+}
+
+// benchKDF describes the key derivation parameters benchmarked.
+type benchKDF struct {
+	Memory      uint32 `json:"memory_kib"`
+	Time        uint32 `json:"time"`
+	Parallelism uint8  `json:"parallelism"`
+	Source      string `json:"source"` // Source is where the parameters come from: vault, default or flags.
+}
+
+// benchResult is the timing of a benchmarked operation.
+type benchResult struct {
+	Name      string  `json:"name"`
+	Ops       int     `json:"ops"`
+	TotalNS   int64   `json:"total_ns"`
+	PerOpNS   int64   `json:"per_op_ns"`
+	OpsPerSec float64 `json:"ops_per_sec"`
+}
+
+func newBenchResult(name string, ops int, total time.Duration) benchResult {
+	return benchResult{
+		Name:      name,
+		Ops:       ops,
+		TotalNS:   total.Nanoseconds(),
+		PerOpNS:   total.Nanoseconds() / int64(ops),
+		OpsPerSec: float64(ops) / total.Seconds(),
+	}
+}
+
+// BenchOptions holds data required to run the command.
+type BenchOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	secrets   int    // secrets is the number of secrets of the scratch vault.
+	valueSize int    // valueSize is the size of the benchmarked secret values, in bytes.
+	output    string // output is the output format.
+
+	kdfMemory      uint32 // kdfMemory overrides the KDF memory cost, in KiB.
+	kdfTime        uint32 // kdfTime overrides the KDF time cost.
+	kdfParallelism uint8  // kdfParallelism overrides the KDF parallelism.
+}
+
+var _ genericclioptions.CmdOptions = &BenchOptions{}
+
+// NewBenchOptions initializes the options struct.
+func NewBenchOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *BenchOptions {
+	return &BenchOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		secrets:      1000,
+		valueSize:    32,
+		output:       benchOutputTable,
+	}
+}
+
+func (*BenchOptions) Complete() error { return nil }
+
+func (o *BenchOptions) Validate() error {
+	if o.secrets < 1 {
+		return &BenchError{fmt.Errorf("--secrets must be positive, got %d", o.secrets)}
+	}
+
+	if o.valueSize < 1 {
+		return &BenchError{fmt.Errorf("--value-size must be positive, got %d", o.valueSize)}
+	}
+
+	if outputs := []string{benchOutputTable, benchOutputJSON}; !slices.Contains(outputs, o.output) {
+		return &BenchError{fmt.Errorf("unsupported output format %q (supported: %s)", o.output, strings.Join(outputs, ", "))}
+	}
+
+	return nil
+}
+
+func (o *BenchOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &BenchError{retErr}
+		}
+	}()
+
+	kdf, err := o.kdfParams(ctx)
+	if err != nil {
+		return err
+	}
+
+	report := benchReport{
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		KDF:       kdf,
+		Secrets:   o.secrets,
+		ValueSize: o.valueSize,
+	}
+
+	o.Debugf("Benchmarking unlock\n")
+
+	unlock, err := benchUnlock(kdf)
+	if err != nil {
+		return err
+	}
+
+	o.Debugf("Benchmarking encryption\n")
+
+	crypto, err := benchCrypto(o.valueSize)
+	if err != nil {
+		return err
+	}
+
+	o.Debugf("Benchmarking a scratch vault of %d secrets\n", o.secrets)
+
+	scratch, err := o.benchScratchVault(ctx)
+	if err != nil {
+		return err
+	}
+
+	report.Results = slices.Concat([]benchResult{unlock}, crypto, scratch)
+
+	if o.output == benchOutputJSON {
+		enc := json.NewEncoder(o.Out)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(report)
+	}
+
+	printBenchReport(o, report)
+
+	return nil
+}
+
+// kdfParams returns the key derivation parameters to benchmark: those of the
+// vault, if any, overridden by the flags.
+func (o *BenchOptions) kdfParams(ctx context.Context) (benchKDF, error) {
+	params, source := vaultcrypto.NewArgon2idKDF().PHC().Argon2Params, "default"
+
+	exists, err := o.vaultExists()
+	if err != nil {
+		return benchKDF{}, err
+	}
+
+	if exists {
+		if params, err = vault.KDFParams(ctx, o.path); err != nil {
+			return benchKDF{}, err
+		}
+
+		source = "vault"
+	}
+
+	if o.kdfMemory > 0 || o.kdfTime > 0 || o.kdfParallelism > 0 {
+		source = "flags"
+	}
+
+	if o.kdfMemory > 0 {
+		params.Memory = o.kdfMemory
+	}
+
+	if o.kdfTime > 0 {
+		params.Time = o.kdfTime
+	}
+
+	if o.kdfParallelism > 0 {
+		params.Parallelism = o.kdfParallelism
+	}
+
+	return benchKDF{
+		Memory:      params.Memory,
+		Time:        params.Time,
+		Parallelism: params.Parallelism,
+		Source:      source,
+	}, nil
+}
+
+// benchUnlock times the key derivation unlocking a vault.
+func benchUnlock(kdf benchKDF) (benchResult, error) {
+	salt, err := vaultcrypto.RandBytes(16)
+	if err != nil {
+		return benchResult{}, err
+	}
+
+	k := vaultcrypto.NewArgon2idKDF(
+		vaultcrypto.WithSalt(salt),
+		vaultcrypto.WithParams(vaultcrypto.Argon2Params{Memory: kdf.Memory, Time: kdf.Time, Parallelism: kdf.Parallelism}),
+	)
+
+	start := time.Now()
+
+	for range benchKDFRuns {
+		_ = k.Derive([]byte("password"))
+	}
+
+	return newBenchResult("unlock (kdf)", benchKDFRuns, time.Since(start)), nil
+}
+
+// benchCrypto times encrypting and decrypting secret values of the given
+// size, each using a fresh nonce as the vault does.
+func benchCrypto(size int) ([]benchResult, error) {
+	key, err := vaultcrypto.RandBytes(32)
+	if err != nil {
+		return nil, err
+	}
+
+	aes, err := vaultcrypto.NewAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := vaultcrypto.RandBytes(size)
+	if err != nil {
+		return nil, err
+	}
+
+	nonces := make([][]byte, benchCryptoOps)
+	ciphertexts := make([][]byte, benchCryptoOps)
+
+	start := time.Now()
+
+	for i := range benchCryptoOps {
+		if nonces[i], err = vaultcrypto.RandBytes(12); err != nil {
+			return nil, err
+		}
+
+		if ciphertexts[i], err = aes.Seal(nonces[i], value); err != nil {
+			return nil, err
+		}
+	}
+
+	encrypt := newBenchResult("encrypt", benchCryptoOps, time.Since(start))
+
+	start = time.Now()
+
+	for i := range benchCryptoOps {
+		if _, err := aes.Open(nonces[i], ciphertexts[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	decrypt := newBenchResult("decrypt", benchCryptoOps, time.Since(start))
+
+	return []benchResult{encrypt, decrypt}, nil
+}
+
+// benchScratchVault times inserting secrets into, and searching, a scratch
+// vault created in a temporary directory. The vault in use is not modified.
+func (o *BenchOptions) benchScratchVault(ctx context.Context) (_ []benchResult, retErr error) {
+	dir, err := os.MkdirTemp("", "vlt-bench-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }() //nolint:wsl
+
+	v, err := vault.New(ctx, filepath.Join(dir, "bench.vlt"), "password")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { //nolint:wsl
+		retErr = errors.Join(retErr, v.Close(ctx))
+	}()
+
+	value, err := vaultcrypto.RandBytes(o.valueSize)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+
+	for i := range o.secrets {
+		name, label := "secret-"+strconv.Itoa(i), "bench/"+strconv.Itoa(i%benchLabels)
+		if _, err := v.InsertNewSecret(ctx, name, string(value), []string{label}); err != nil {
+			return nil, err
+		}
+	}
+
+	results := []benchResult{newBenchResult("insert", o.secrets, time.Since(start))}
+
+	searches := []struct {
+		name                 string
+		wildcard, secretName string
+		labels               []string
+	}{
+		{name: "search all"},
+		{name: "search name prefix", secretName: "secret-1*"},
+		{name: "search label prefix", labels: []string{"bench/1*"}},
+		{name: "search wildcard", wildcard: "*-1*"},
+	}
+
+	for _, s := range searches {
+		start := time.Now()
+
+		for range benchSearchRuns {
+			if _, err := v.FilterSecrets(ctx, s.wildcard, s.secretName, s.labels); err != nil {
+				return nil, err
+			}
+		}
+
+		results = append(results, newBenchResult(s.name, benchSearchRuns, time.Since(start)))
+	}
+
+	return results, nil
+}
+
+func printBenchReport(o *BenchOptions, r benchReport) {
+	o.Infof("%s %s/%s, %d CPUs\n", r.GoVersion, r.OS, r.Arch, r.CPUs)
+	o.Infof("argon2id m=%d KiB, t=%d, p=%d (%s)\n", r.KDF.Memory, r.KDF.Time, r.KDF.Parallelism, r.KDF.Source)
+	o.Infof("%d secrets of %d bytes\n\n", r.Secrets, r.ValueSize)
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "BENCHMARK\tOPS\tPER OP\tOPS/S")
+
+	for _, res := range r.Results {
+		perOp := time.Duration(res.PerOpNS)
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%.0f\n", res.Name, res.Ops, perOp, res.OpsPerSec)
+	}
+}
+
+// NewCmdBench creates the bench cobra command.
+func NewCmdBench(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewBenchOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmark unlocking, encryption, insertion and search on this machine",
+		Long: `Benchmark unlocking, encryption, insertion and search on this machine.
+
+Unlocking is timed by deriving a key using the argon2id parameters of the
+vault, or the defaults if there is no vault, overridden using the --kdf-*
+flags, e.g., to compare candidate parameters. Secret values are encrypted and
+decrypted using AES-GCM, each with a fresh nonce, as the vault does.
+
+Insertion and search are timed on a scratch vault of --secrets secrets, created
+in a temporary directory and removed afterwards. The vault is neither unlocked
+nor modified.
+
+The json output is meant to be kept, e.g., to compare runs across versions.`,
+		Example: `  # Benchmark using the parameters of the vault
+  vlt bench
+
+  # Compare with a higher KDF memory cost, on a larger vault
+  vlt bench --kdf-memory 262144 --secrets 10000
+
+  # Keep the results
+  vlt bench --output json > bench.json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().IntVarP(&o.secrets, "secrets", "", o.secrets, "number of secrets inserted into the scratch vault")
+	cmd.Flags().IntVarP(&o.valueSize, "value-size", "", o.valueSize, "size of the secret values, in bytes")
+	cmd.Flags().StringVarP(&o.output, "output", "o", o.output, "output format: table or json")
+	cmd.Flags().Uint32VarP(&o.kdfMemory, "kdf-memory", "", 0, "argon2id memory cost, in KiB (default: the vault's)")
+	cmd.Flags().Uint32VarP(&o.kdfTime, "kdf-time", "", 0, "argon2id time cost (default: the vault's)")
+	cmd.Flags().Uint8VarP(&o.kdfParallelism, "kdf-parallelism", "", 0, "argon2id parallelism (default: the vault's)")
+
+	return cmd
+}
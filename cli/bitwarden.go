@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ladzaretti/vlt-cli/bitwarden"
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/input"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+// errNoBitwardenAccount indicates that --bitwarden-compat was set before setting up an account.
+var errNoBitwardenAccount = errors.New("no Bitwarden account set up, run 'vlt serve bitwarden setup' first")
+
+// NewCmdServeBitwarden creates the serve bitwarden cobra command with its sub-commands.
+func NewCmdServeBitwarden(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bitwarden",
+		Short: "Manage the account Bitwarden clients log in to (subcommands available)",
+		Long: `Manage the account Bitwarden clients log in to, when serving using 'vlt serve --bitwarden-compat'.
+
+The account has its own email and password, used by Bitwarden clients only.
+The vault password is neither used nor exposed by it.`,
+	}
+
+	cmd.AddCommand(NewCmdServeBitwardenSetup(defaults))
+	cmd.AddCommand(NewCmdServeBitwardenRemove(defaults))
+
+	return cmd
+}
+
+// ServeBitwardenSetupOptions holds data required to run the command.
+type ServeBitwardenSetupOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	email      string
+	iterations int
+}
+
+var _ genericclioptions.CmdOptions = &ServeBitwardenSetupOptions{}
+
+// NewServeBitwardenSetupOptions initializes the options struct.
+func NewServeBitwardenSetupOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *ServeBitwardenSetupOptions {
+	return &ServeBitwardenSetupOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		iterations:   bitwarden.DefaultKDFIterations,
+	}
+}
+
+func (*ServeBitwardenSetupOptions) Complete() error { return nil }
+
+func (o *ServeBitwardenSetupOptions) Validate() error {
+	if o.NonInteractive {
+		return vaulterrors.ErrNonInteractiveUnsupported
+	}
+
+	if !strings.Contains(o.email, "@") {
+		return &ServeError{fmt.Errorf("invalid --email value %q", o.email)}
+	}
+
+	// the minimum accepted by Bitwarden clients.
+	if o.iterations < 5000 {
+		return &ServeError{fmt.Errorf("invalid --kdf-iterations value %d (must be at least 5000)", o.iterations)}
+	}
+
+	return nil
+}
+
+func (o *ServeBitwardenSetupOptions) Run(ctx context.Context, _ ...string) error {
+	o.Infof("Choose the password Bitwarden clients log in with, it should differ from the vault password.\n")
+
+	password, err := input.PromptNewPassword(o.Out, int(o.In.Fd()), masterPasswordMinLen)
+	if err != nil {
+		return &ServeError{err}
+	}
+
+	if err := o.vault.SetBitwardenAccount(ctx, o.email, password, o.iterations); err != nil {
+		return &ServeError{err}
+	}
+
+	o.Infof("Bitwarden account %s set up, serve it using 'vlt serve --bitwarden-compat'.\n", o.email)
+
+	return nil
+}
+
+// NewCmdServeBitwardenSetup creates the serve bitwarden setup cobra command.
+func NewCmdServeBitwardenSetup(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewServeBitwardenSetupOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "setup",
+		Short: "Set up the account Bitwarden clients log in to",
+		Long: `Set up the account Bitwarden clients log in to, prompting for its password.
+
+An existing account is replaced, logging out its clients.
+Secrets remain readable by clients logging in again, as the key encrypting them
+is derived from the vault key.`,
+		Example: `  # Set up the account, and serve it
+  vlt serve bitwarden setup --email me@example.com
+  vlt serve --bitwarden-compat`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.email, "email", "", "", "account email, used as the client login (required)")
+	cmd.Flags().IntVarP(&o.iterations, "kdf-iterations", "", o.iterations, "PBKDF2 iterations clients derive the account key with")
+
+	_ = cmd.MarkFlagRequired("email")
+
+	return cmd
+}
+
+// ServeBitwardenRemoveOptions holds data required to run the command.
+type ServeBitwardenRemoveOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &ServeBitwardenRemoveOptions{}
+
+// NewServeBitwardenRemoveOptions initializes the options struct.
+func NewServeBitwardenRemoveOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *ServeBitwardenRemoveOptions {
+	return &ServeBitwardenRemoveOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*ServeBitwardenRemoveOptions) Complete() error { return nil }
+
+func (*ServeBitwardenRemoveOptions) Validate() error { return nil }
+
+func (o *ServeBitwardenRemoveOptions) Run(ctx context.Context, _ ...string) error {
+	ok, err := o.vault.RemoveBitwardenAccount(ctx)
+	if err != nil {
+		return &ServeError{err}
+	}
+
+	if !ok {
+		o.Warnf("No Bitwarden account found.\n")
+		return nil
+	}
+
+	o.Infof("Removed the Bitwarden account.\n")
+
+	return nil
+}
+
+// NewCmdServeBitwardenRemove creates the serve bitwarden remove cobra command.
+func NewCmdServeBitwardenRemove(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewServeBitwardenRemoveOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "remove",
+		Aliases: []string{"rm"},
+		Short:   "Remove the account Bitwarden clients log in to",
+		Long:    "Remove the account Bitwarden clients log in to. Secrets are not affected.",
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
@@ -0,0 +1,389 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/card"
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/clipboard"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/input"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+type CardError struct {
+	Err error
+}
+
+func (e *CardError) Error() string { return "card: " + e.Err.Error() }
+
+func (e *CardError) Unwrap() error { return e.Err }
+
+// NewCmdCard creates the card cobra command with its sub-commands.
+func NewCmdCard(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "card",
+		Short: "Manage payment cards (subcommands available)",
+		Long: fmt.Sprintf(`Manage payment cards stored in the vault.
+
+Cards are stored as secrets labeled %q, holding a JSON object of their
+number, expiry, cvv and holder fields. Card numbers are validated using the
+Luhn check when added, and masked up to their last 4 digits when listed.
+
+As with any other JSON secret, fields can also be printed using 'vlt get',
+e.g., 'vlt get visa/expiry'.`, card.Label),
+	}
+
+	cmd.AddCommand(NewCmdCardAdd(defaults))
+	cmd.AddCommand(NewCmdCardList(defaults))
+	cmd.AddCommand(NewCmdCardCopy(defaults))
+
+	return cmd
+}
+
+// CardAddOptions holds data required to run the command.
+type CardAddOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	name   string
+	labels []string
+	holder string
+	expiry string
+}
+
+var _ genericclioptions.CmdOptions = &CardAddOptions{}
+
+// NewCardAddOptions initializes the options struct.
+func NewCardAddOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *CardAddOptions {
+	return &CardAddOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*CardAddOptions) Complete() error { return nil }
+
+func (o *CardAddOptions) Validate() error {
+	if o.NonInteractive && len(o.name) == 0 {
+		return &CardError{errors.New("--name is required in non-interactive mode")}
+	}
+
+	return nil
+}
+
+func (o *CardAddOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &CardError{retErr}
+			return
+		}
+	}()
+
+	c, err := o.readCard()
+	if err != nil {
+		return err
+	}
+
+	if err := c.Normalize(); err != nil {
+		return err
+	}
+
+	if c.Expired(time.Now()) {
+		o.Warnf("vlt: card %q expired on %s\n", o.name, c.Expiry)
+	}
+
+	value, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	labels := append(slices.Clone(o.labels), card.Label)
+
+	n, err := o.vault.InsertNewSecret(ctx, o.name, string(value), labels)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNoSecretInserted
+	}
+
+	o.Infof("Added card %q (%s)\n", o.name, c.Masked())
+
+	return nil
+}
+
+// readCard reads the card from stdin as a JSON object if piped or redirected,
+// or else prompts for its details. Flags take precedence in both cases.
+func (o *CardAddOptions) readCard() (card.Card, error) {
+	if o.NonInteractive {
+		s, err := input.ReadTrim(o.In)
+		if err != nil {
+			return card.Card{}, err
+		}
+
+		c, err := card.Parse(s)
+		if err != nil {
+			return card.Card{}, err
+		}
+
+		c.Holder, c.Expiry = cmp.Or(o.holder, c.Holder), cmp.Or(o.expiry, c.Expiry)
+
+		return c, nil
+	}
+
+	var (
+		c   = card.Card{Holder: o.holder, Expiry: o.expiry}
+		err error
+	)
+
+	if len(o.name) == 0 {
+		if o.name, err = input.PromptRead(o.Out, o.In, "Enter name: "); err != nil {
+			return card.Card{}, fmt.Errorf("name read interactive: %w", err)
+		}
+	}
+
+	if c.Number, err = input.PromptReadSecure(o.Out, int(o.In.Fd()), "Enter card number: "); err != nil {
+		return card.Card{}, err
+	}
+
+	if len(c.Expiry) == 0 {
+		if c.Expiry, err = input.PromptRead(o.Out, o.In, "Enter expiry (MM/YY): "); err != nil {
+			return card.Card{}, fmt.Errorf("expiry read interactive: %w", err)
+		}
+	}
+
+	if c.CVV, err = input.PromptReadSecure(o.Out, int(o.In.Fd()), "Enter cvv, or press Enter to skip: "); err != nil {
+		return card.Card{}, err
+	}
+
+	if len(c.Holder) == 0 {
+		if c.Holder, err = input.PromptRead(o.Out, o.In, "Enter holder name, or press Enter to skip: "); err != nil {
+			return card.Card{}, fmt.Errorf("holder read interactive: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// CardListOptions holds data required to run the command.
+type CardListOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &CardListOptions{}
+
+// NewCardListOptions initializes the options struct.
+func NewCardListOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *CardListOptions {
+	return &CardListOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*CardListOptions) Complete() error { return nil }
+
+func (*CardListOptions) Validate() error { return nil }
+
+func (o *CardListOptions) Run(ctx context.Context, _ ...string) error {
+	secrets, err := o.searchAll(ctx, o.StdioOptions, &SearchableOptions{Labels: []string{card.Label}})
+	if err != nil {
+		return &CardError{err}
+	}
+
+	if len(secrets) == 0 {
+		o.Warnf("No cards found.\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tNUMBER\tEXPIRY\tHOLDER")
+
+	now := time.Now()
+
+	for _, s := range secrets {
+		c, err := o.readCard(ctx, o.StdioOptions, s)
+		if err != nil {
+			o.Warnf("vlt: card %q: %v\n", s.name, err)
+			continue
+		}
+
+		expiry := c.Expiry
+		if c.Expired(now) {
+			expiry += " (expired)"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.displayID(), s.name, c.Masked(), expiry, c.Holder)
+	}
+
+	return nil
+}
+
+// readCard reads the card held by the given secret.
+func (o *VaultOptions) readCard(ctx context.Context, io *genericclioptions.StdioOptions, s secretWithLabels) (card.Card, error) {
+	v, err := o.vaultOf(ctx, io, s)
+	if err != nil {
+		return card.Card{}, err
+	}
+
+	value, err := v.ShowSecret(ctx, s.id)
+	if err != nil {
+		return card.Card{}, err
+	}
+
+	return card.Parse(value)
+}
+
+// CardCopyOptions holds data required to run the command.
+type CardCopyOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &CardCopyOptions{}
+
+// NewCardCopyOptions initializes the options struct.
+func NewCardCopyOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *CardCopyOptions {
+	return &CardCopyOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*CardCopyOptions) Complete() error { return nil }
+
+func (*CardCopyOptions) Validate() error { return nil }
+
+func (o *CardCopyOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &CardError{retErr}
+			return
+		}
+	}()
+
+	name, field := args[0], "number"
+	if len(args) > 1 {
+		field = args[1]
+	}
+
+	if !slices.Contains(card.Fields, field) {
+		return fmt.Errorf("unsupported field %q (supported: %s)", field, strings.Join(card.Fields, ", "))
+	}
+
+	secrets, err := o.searchAll(ctx, o.StdioOptions, &SearchableOptions{Name: name, Labels: []string{card.Label}})
+	if err != nil {
+		return err
+	}
+
+	secrets = slices.DeleteFunc(secrets, func(s secretWithLabels) bool {
+		return s.name != name || !slices.Contains(s.labels, card.Label)
+	})
+
+	switch {
+	case len(secrets) == 0:
+		return fmt.Errorf("%q: %w", name, vaulterrors.ErrSearchNoMatch)
+	case len(secrets) > 1:
+		return fmt.Errorf("%q: %w", name, vaulterrors.ErrAmbiguousSecretMatch)
+	}
+
+	c, err := o.readCard(ctx, o.StdioOptions, secrets[0])
+	if err != nil {
+		return err
+	}
+
+	value, _ := c.Field(field)
+	if len(value) == 0 {
+		return fmt.Errorf("card %q has no %s", name, field)
+	}
+
+	o.Debugf("copying card %s to clipboard\n", field)
+
+	return clipboard.Copy(value)
+}
+
+// NewCmdCardAdd creates the card add cobra command.
+func NewCmdCardAdd(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewCardAddOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a payment card to the vault",
+		Long: `Add a payment card to the vault.
+
+The card number and cvv are prompted for without echo, along with the
+missing details. If input is piped or redirected, the card is read as a JSON
+object instead, e.g., {"number": "...", "expiry": "03/31", "cvv": "...", "holder": "..."}.
+
+The card number must pass the Luhn check, and the expiry be given as MM/YY
+or MM/YYYY. The cvv and holder are optional.`,
+		Example: `  # Add a card, prompting for its details
+  vlt card add --name visa --holder "Jane Doe"
+
+  # Add a card read from a JSON object
+  vlt card add --name visa < card.json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.name, "name", "", "", "the card name (e.g., visa)")
+	cmd.Flags().StringVarP(&o.holder, "holder", "", "", "the card holder name")
+	cmd.Flags().StringVarP(&o.expiry, "expiry", "", "", "the card expiry (MM/YY)")
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "optional label to associate with the card (comma-separated or repeated)")
+
+	return cmd
+}
+
+// NewCmdCardList creates the card list cobra command.
+func NewCmdCardList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewCardListOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the payment cards, masking their numbers",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
+
+// NewCmdCardCopy creates the card copy cobra command.
+func NewCmdCardCopy(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewCardCopyOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "copy NAME [FIELD]",
+		Short: "Copy a field of a payment card to the clipboard",
+		Long: fmt.Sprintf(`Copy a field of the payment card with exactly the given name to the clipboard.
+
+Supported fields: %s (default: number).`, strings.Join(card.Fields, ", ")),
+		Example: `  # Copy the card number
+  vlt card copy visa
+
+  # Copy the card cvv
+  vlt card copy visa cvv`,
+		Args:      cobra.RangeArgs(1, 2),
+		ValidArgs: card.Fields,
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/notify"
+	"github.com/ladzaretti/vlt-cli/policy"
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// defaultCheckWindow is the default window secrets expiring within are reported.
+	defaultCheckWindow = "14d"
+
+	// maxNotifiedSecrets is the maximum number of secrets listed in a desktop notification.
+	maxNotifiedSecrets = 10
+)
+
+type CheckError struct {
+	Err error
+}
+
+func (e *CheckError) Error() string { return "check: " + e.Err.Error() }
+
+func (e *CheckError) Unwrap() error { return e.Err }
+
+// CheckOptions holds data required to run the command.
+type CheckOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	within       string
+	notify       bool
+	withinWindow time.Duration
+}
+
+var _ genericclioptions.CmdOptions = &CheckOptions{}
+
+// NewCheckOptions initializes the options struct.
+func NewCheckOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *CheckOptions {
+	return &CheckOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (o *CheckOptions) Complete() error {
+	d, err := cmdutil.ParseDuration(o.within)
+	if err != nil {
+		return &CheckError{fmt.Errorf("--within: %w", err)}
+	}
+
+	o.withinWindow = d
+
+	return nil
+}
+
+func (o *CheckOptions) Validate() error {
+	if o.withinWindow <= 0 {
+		return &CheckError{errors.New("--within must be positive")}
+	}
+
+	return nil
+}
+
+// expiringSecret is a secret exceeding its maximum age within the checked window.
+type expiringSecret struct {
+	id        int
+	name      string
+	changedAt time.Time
+	expiresAt time.Time
+}
+
+func (o *CheckOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &CheckError{retErr}
+			return
+		}
+	}()
+
+	set := o.passwordPolicy.set
+	if !hasMaxAge(set) {
+		o.Infof("No maximum secret age configured; nothing to check.\n")
+		return nil
+	}
+
+	secrets, err := o.vault.FilterSecrets(ctx, "", "", nil)
+	if err != nil {
+		return err
+	}
+
+	changedAt, err := o.vault.SecretsChangedAt(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		deadline = time.Now().Add(o.withinWindow)
+		expiring []expiringSecret
+	)
+
+	for id, s := range secrets {
+		p, _ := set.For(s.Labels)
+
+		expiresAt, ok := p.ExpiresAt(changedAt[id])
+		if !ok || expiresAt.After(deadline) {
+			continue
+		}
+
+		expiring = append(expiring, expiringSecret{id: id, name: s.Name, changedAt: changedAt[id], expiresAt: expiresAt})
+	}
+
+	if len(expiring) == 0 {
+		o.Infof("No secrets expire within %s.\n", o.within)
+		return nil
+	}
+
+	slices.SortFunc(expiring, func(a, b expiringSecret) int {
+		return a.expiresAt.Compare(b.expiresAt)
+	})
+
+	printExpiringTable(o.Out, expiring)
+
+	if o.notify {
+		if err := notify.Send(ctx, "vlt: expiring secrets", notificationBody(expiring, o.within)); err != nil {
+			o.Warnf("Desktop notification failed: %v\n", err)
+		}
+	}
+
+	return fmt.Errorf("%d secrets expire within %s", len(expiring), o.within)
+}
+
+// hasMaxAge reports whether the default policy or any of the overrides sets a maximum age.
+func hasMaxAge(set policy.Set) bool {
+	return set.Default.MaxAge > 0 || slices.ContainsFunc(set.Overrides, func(o policy.Override) bool {
+		return o.Policy.MaxAge > 0
+	})
+}
+
+func printExpiringTable(w io.Writer, expiring []expiringSecret) {
+	now := time.Now()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tCHANGED\tEXPIRES")
+
+	for _, s := range expiring {
+		expires := s.expiresAt.Local().Format(time.DateTime)
+		if !now.Before(s.expiresAt) {
+			expires += " (expired)"
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", s.id, s.name, s.changedAt.Local().Format(time.DateTime), expires)
+	}
+
+	fmt.Fprintln(tw) // add padding
+}
+
+// notificationBody returns the desktop notification text listing the given secrets.
+func notificationBody(expiring []expiringSecret, within string) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%d secrets expire within %s:\n", len(expiring), within)
+
+	for i, s := range expiring {
+		if i == maxNotifiedSecrets {
+			fmt.Fprintf(&sb, "and %d more", len(expiring)-i)
+			break
+		}
+
+		fmt.Fprintf(&sb, "%s (%s)\n", s.name, s.expiresAt.Local().Format(time.DateOnly))
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// NewCmdCheck creates the check cobra command.
+func NewCmdCheck(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewCheckOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Report secrets expiring soon",
+		Long: `Report secrets exceeding the maximum age of the password policy
+('max_age') within the given window, including already expired ones.
+
+With --notify, the expiring secrets are also shown in a desktop notification
+(using 'notify-send', or the notification center on macOS), making the
+command suitable for a scheduled job (e.g., a systemd timer).
+
+The command exits with a non-zero status if any secret is expiring.`,
+		Example: `  # Report secrets expiring within the next two weeks
+  vlt check
+
+  # Pop a desktop notification for secrets expiring within a month
+  vlt check --within 30d --notify`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.within, "within", "", defaultCheckWindow, "report secrets expiring within this window (e.g., '30d', '2w')")
+	cmd.Flags().BoolVarP(&o.notify, "notify", "", false, "show the expiring secrets in a desktop notification")
+
+	return cmd
+}
@@ -9,6 +9,7 @@ import (
 	"slices"
 	"time"
 
+	"github.com/ladzaretti/vlt-cli/auditsink"
 	"github.com/ladzaretti/vlt-cli/clierror"
 	"github.com/ladzaretti/vlt-cli/clipboard"
 	"github.com/ladzaretti/vlt-cli/genericclioptions"
@@ -16,6 +17,7 @@ import (
 	"github.com/ladzaretti/vlt-cli/vault"
 	"github.com/ladzaretti/vlt-cli/vaultdaemon"
 	"github.com/ladzaretti/vlt-cli/vaulterrors"
+	"github.com/ladzaretti/vlt-cli/webhook"
 
 	"github.com/spf13/cobra"
 )
@@ -31,31 +33,109 @@ const (
 
 	// defaultSessionDuration is the fallback when no session duration is set.
 	defaultSessionDuration = "1m"
+
+	// defaultLockTimeout is the fallback when no lock timeout is set.
+	defaultLockTimeout = "30s"
 )
 
 var (
 	// preRunSkipCommands lists command names that should
 	// bypass the persistent pre-run logic.
-	preRunSkipCommands = []string{"config", "generate", "validate"}
+	preRunSkipCommands = []string{"config", "generate", "validate", "direnv stdlib", "pam-helper", "widget"}
 
 	// preRunPartialCommands lists commands that require partial
 	// preRunPartialCommands run setup like path resolution, but skip vault opening.
-	preRunPartialCommands = []string{"create", "login", "logout"}
+	preRunPartialCommands = []string{"create", "login", "logout", "pam-helper", "doctor", "bench", "restore", "backup list", "backup prune", "backup verify", "plugin list", "keepassxc install", "get", "terraform-external", "direnv export", "dmenu", "tmux", "watch", "clipboard-clear", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd}
+
+	// mutatingCommands lists command names that write to the vault,
+	// these are rejected in read-only mode before the vault is opened.
+	mutatingCommands = []string{"create", "remove", "update", "secret", "import", "save", "checkpoint", "issue", "revoke", "init", "pull", "link add", "link refresh", "card add", "identity add", "note add", "attach", "attachment remove", "ssh-key add", "recovery add", "recovery use", "labels set", "labels unset", "labels bulk", "rotate", "scheduler", "scheduler set", "scheduler unset", "share accept", "search save", "search remove", "frecency reset", "edit", "history restore", "trash restore", "trash purge", "totp add", "rotate-master", "generate --save", "split --move", "bitwarden setup", "bitwarden remove", "keepassxc pair", "keepassxc revoke", "cloud aws push", "cloud aws pull", "cloud gcp push", "cloud gcp pull", "cloud azure push", "cloud azure pull"}
 
 	// postRunSkipCommands lists command names that should
 	// bypass the persistent post-run logic.
-	postRunSkipCommands = []string{"config", "generate", "validate", "create", "login", "logout"}
+	postRunSkipCommands = []string{"config", "generate", "validate", "widget", "create", "login", "logout", "pam-helper", "doctor", "bench", "restore", "backup list", "backup prune", "backup verify", "plugin list", "keepassxc install", "get", "terraform-external", "direnv export", "direnv stdlib", "dmenu", "tmux", "watch", "clipboard-clear", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd}
+
+	// destructiveCommands lists command names that are backed up before
+	// running, if enabled by the 'backup.before_destructive' config.
+	destructiveCommands = []string{"remove", "import", "split --move", "rotate", "rotate-master", "pull", "cloud aws pull", "cloud gcp pull", "cloud azure pull"}
+
+	// serverCommands lists long-running commands holding the vault in memory,
+	// these lock the vault exclusively even if they do not modify it.
+	serverCommands = []string{"serve", "rpc", "keepassxc proxy"}
+
+	// jsonOutputCommands lists commands that print JSON using '-o json',
+	// these also print errors as JSON objects, see [clierror.JSONMode].
+	jsonOutputCommands = []string{"find", "bench", "watch"}
+
+	// vaultFlagCommands maps commands to the flag they write to the vault with,
+	// listed above as "<command> --<flag>" if set, e.g., "generate --save".
+	vaultFlagCommands = map[string]string{"generate": "save", "split": "move"}
+
+	// qualifiedCommands lists commands whose sub-commands are listed above
+	// qualified by their parents' names, e.g., "backup list" or "cloud aws push".
+	qualifiedCommands = []string{"backup", "history", "trash", "totp", "link", "card", "identity", "note", "attachment", "ssh-key", "recovery", "labels", "search", "frecency", "scheduler", "share", "sops", "bitwarden", "keepassxc", "direnv", "cloud", "aws", "gcp", "azure", "plugin", "tmux"}
 )
 
+// commandName returns the name a command is listed by in the command lists.
+func commandName(cmd *cobra.Command) string {
+	name := cmd.Name()
+
+	for p := cmd.Parent(); p != nil && slices.Contains(qualifiedCommands, p.Name()); p = p.Parent() {
+		name = p.Name() + " " + name
+	}
+
+	if flag, ok := vaultFlagCommands[name]; ok {
+		if f := cmd.Flags().Lookup(flag); f != nil && f.Changed {
+			name += " --" + flag
+		}
+	}
+
+	return name
+}
+
 type vaultHooks struct {
-	postLogin []string
-	postWrite []string
+	postLogin   []string
+	postWrite   []string
+	postMonitor []string
+	postBackup  []string
 }
 
 type VaultOptions struct {
 	path  string
 	vault *vault.Vault
 	hooks vaultHooks
+
+	auditSinkKind string         // auditSinkKind is the configured audit sink kind, empty if disabled.
+	auditSink     auditsink.Sink // auditSink is connected in [VaultOptions.Open] if configured.
+
+	passwordPolicy passwordPolicy // passwordPolicy is evaluated when storing secrets.
+
+	readOnly      bool // readOnly opens the vault in read-only mode.
+	acceptChanges bool // acceptChanges trusts vault contents that fail the integrity check.
+	confirmOutput bool // confirmOutput requires confirmation before printing secrets to a terminal.
+	strict        bool // strict refuses to open vaults with unsafe ownership or permissions.
+	noPrompt      bool // noPrompt fails with [vaulterrors.ErrVaultLocked] instead of prompting for the password.
+	keychain      bool // keychain caches the vault key in the macOS Keychain, see [VaultOptions.keychainKey].
+
+	// lockMode is the mode the vault is locked in while open, see [vault.Lock].
+	// Commands that do not modify the vault share it, others hold it exclusively.
+	lockMode    vault.LockMode
+	lockTimeout time.Duration // lockTimeout is how long opening waits for the lock.
+	lock        *vault.Lock   // lock is held from opening the vault until [VaultOptions.Close].
+
+	token string // token is the access token used instead of the master password, if set.
+
+	resolver vault.Resolver // resolver resolves linked secrets.
+
+	webhooks    []webhook.Endpoint // webhooks are notified of vault events, if configured.
+	webhookSink *webhookSink       // webhookSink is set in [VaultOptions.Open] if webhooks are configured.
+
+	mounts  map[string]string        // mounts maps mount names to the paths of the vaults searched along with the vault.
+	mounted map[string]*VaultOptions // mounted holds the mounts opened by [VaultOptions.mountedVault].
+
+	// sessionClient and sessionDuration are kept by [VaultOptions.Open] for opening mounts.
+	sessionClient   *vaultdaemon.SessionClient
+	sessionDuration time.Duration
 }
 
 var _ genericclioptions.BaseOptions = &VaultOptions{}
@@ -89,8 +169,46 @@ func (o *VaultOptions) Open(ctx context.Context, io *genericclioptions.StdioOpti
 		return fmt.Errorf("%w: %s", vaulterrors.ErrVaultFileNotFound, o.path)
 	}
 
+	if err := o.checkPermissions(io); err != nil {
+		return err
+	}
+
+	o.sessionClient, o.sessionDuration = sessionClient, sessionDuration
+
 	opts := []vault.Option{}
 
+	if o.readOnly {
+		opts = append(opts, vault.WithReadOnly())
+	}
+
+	if o.acceptChanges {
+		opts = append(opts, vault.WithAcceptChanges())
+	}
+
+	if o.resolver != nil {
+		opts = append(opts, vault.WithResolver(o.resolver))
+	}
+
+	if len(o.auditSinkKind) > 0 {
+		sink, err := auditsink.New(o.auditSinkKind)
+		if err != nil {
+			return fmt.Errorf("audit sink: %w", err)
+		}
+
+		o.auditSink = sink
+		opts = append(opts, vault.WithAuditSink(sink))
+	}
+
+	if len(o.webhooks) > 0 {
+		o.webhookSink = &webhookSink{notifier: webhook.New(o.webhooks), io: io}
+		opts = append(opts, vault.WithAuditSink(o.webhookSink))
+	}
+
+	if len(o.token) > 0 {
+		io.Debugf("vlt: opening vault using the %s access token\n", tokenEnv)
+		return o.unlock(ctx, io, append(opts, vault.WithToken(o.token))...)
+	}
+
 	// nil-safe: sessionClient methods handle nil receivers safely.
 	key, nonce, err := sessionClient.GetSessionKey(ctx, o.path)
 	if err != nil {
@@ -98,19 +216,51 @@ func (o *VaultOptions) Open(ctx context.Context, io *genericclioptions.StdioOpti
 	}
 
 	if key == nil || nonce == nil {
+		if ok, err := o.unlockKeychain(ctx, io, opts...); ok || err != nil {
+			return err
+		}
+
+		if o.noPrompt {
+			return vaulterrors.ErrVaultLocked
+		}
+
 		password, err := o.login(ctx, io, sessionClient, sessionDuration)
 		if err != nil {
 			return err
 		}
 
-		opts = append(opts, vault.WithPassword(password))
-	} else {
-		opts = append(opts, vault.WithSessionKey(key, nonce))
+		return o.unlock(ctx, io, append(opts, vault.WithPassword(password))...)
+	}
+
+	return o.open(ctx, io, append(opts, vault.WithSessionKey(key, nonce))...)
+}
+
+// unlock opens the vault using the master password or an access token,
+// notifying webhooks of the unlock.
+func (o *VaultOptions) unlock(ctx context.Context, io *genericclioptions.StdioOptions, opts ...vault.Option) error {
+	if err := o.open(ctx, io, opts...); err != nil {
+		return err
+	}
+
+	if o.webhookSink != nil {
+		o.webhookSink.notifyUnlock(ctx, o.vault)
+	}
+
+	return nil
+}
+
+func (o *VaultOptions) open(ctx context.Context, io *genericclioptions.StdioOptions, opts ...vault.Option) error {
+	if err := o.acquireLock(ctx, io); err != nil {
+		return err
 	}
 
 	v, err := vault.Open(ctx, o.path, opts...)
 	if err != nil {
-		return err
+		return errors.Join(err, o.lock.Unlock())
+	}
+
+	if v.ChangesAccepted() {
+		io.Warnf("vlt: accepted vault contents modified outside of vlt: %s\n", o.path)
 	}
 
 	o.vault = v
@@ -118,8 +268,83 @@ func (o *VaultOptions) Open(ctx context.Context, io *genericclioptions.StdioOpti
 	return nil
 }
 
+// acquireLock locks the vault in [VaultOptions.lockMode], waiting up to
+// [VaultOptions.lockTimeout] for other commands using it to finish.
+func (o *VaultOptions) acquireLock(ctx context.Context, io *genericclioptions.StdioOptions) error {
+	l, ok, err := vault.TryLock(o.path, o.lockMode)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		io.Warnf("vlt: waiting for another vlt command using the vault to finish...\n")
+
+		ctx, cancel := context.WithTimeout(ctx, o.lockTimeout)
+		defer cancel()
+
+		if l, err = vault.AcquireLock(ctx, o.path, o.lockMode); errors.Is(err, vaulterrors.ErrVaultBusy) {
+			return fmt.Errorf("%w: gave up after %s, see the 'vault.lock_timeout' config", vaulterrors.ErrVaultBusy, o.lockTimeout)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	o.lock = l
+
+	return nil
+}
+
+// closeVault closes the vault, writing it back unless read-only.
+//
+// A shared lock is upgraded first, as commands only reading from the vault
+// still write back the audit log entries they record, see [vault.Lock].
+func (o *VaultOptions) closeVault(ctx context.Context) error {
+	if o.lock != nil && o.lockMode == vault.LockShared && !o.readOnly {
+		ctx, cancel := context.WithTimeout(ctx, o.lockTimeout)
+		defer cancel()
+
+		err := o.lock.Upgrade(ctx)
+		if errors.Is(err, vaulterrors.ErrVaultBusy) {
+			return fmt.Errorf("%w: gave up after %s, see the 'vault.lock_timeout' config", vaulterrors.ErrVaultBusy, o.lockTimeout)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return o.vault.Close(ctx)
+}
+
+// Close closes the audit sink, if connected, and releases the vault lock.
+// It is called once the vault is closed.
+func (o *VaultOptions) Close() error {
+	err := o.lock.Unlock()
+	o.lock = nil
+
+	if o.auditSink == nil {
+		return err
+	}
+
+	return errors.Join(err, o.auditSink.Close())
+}
+
 func (o *VaultOptions) login(ctx context.Context, io *genericclioptions.StdioOptions, sessionClient *vaultdaemon.SessionClient, sessionDuration time.Duration) (string, error) {
-	password, err := input.PromptReadSecure(io.Out, int(io.In.Fd()), "[vlt] Password for %q:", o.path)
+	w, fd := io.Out, int(io.In.Fd())
+
+	// the password is prompted for on the terminal if stdin or stdout are
+	// piped or redirected, e.g., 'tar c dir | vlt encrypt - > dir.tar.vlt'.
+	if _, ok := terminalFd(io.Out); !ok || io.NonInteractive {
+		if tty, err := input.OpenTTY(); err == nil {
+			defer func() { _ = tty.Close() }()
+
+			w, fd = tty, int(tty.Fd())
+		}
+	}
+
+	password, err := input.PromptReadSecure(w, fd, "[vlt] Password for %q:", o.path)
 	if err != nil {
 		return "", fmt.Errorf("prompt password: %v", err)
 	}
@@ -129,6 +354,7 @@ func (o *VaultOptions) login(ctx context.Context, io *genericclioptions.StdioOpt
 		io.Debugf("%v", err)
 	} else {
 		_ = sessionClient.Login(ctx, o.path, key, nonce, sessionDuration)
+		o.cacheKeychainKey(ctx, io, key, nonce)
 	}
 
 	if err := genericclioptions.RunHook(ctx, io, o.hooks.postLogin); err != nil {
@@ -203,6 +429,10 @@ func (o *DefaultVltOptions) complete() error {
 		opts = append(opts, clipboard.WithPasteCmd(pasteCmd))
 	}
 
+	if name := o.configOptions.resolved.ClipboardPlugin; len(name) > 0 {
+		opts = append(opts, clipboard.WithBackend(pluginClipboard{name: name}))
+	}
+
 	if len(opts) > 0 {
 		clipboard.SetDefault(clipboard.New(opts...))
 	}
@@ -210,10 +440,29 @@ func (o *DefaultVltOptions) complete() error {
 	o.vaultOptions.path = o.configOptions.resolved.VaultPath
 
 	o.vaultOptions.hooks = vaultHooks{
-		postLogin: o.configOptions.resolved.PostLoginCmd,
-		postWrite: o.configOptions.resolved.PostWriteCmd,
+		postLogin:   o.configOptions.resolved.PostLoginCmd,
+		postWrite:   o.configOptions.resolved.PostWriteCmd,
+		postMonitor: o.configOptions.resolved.PostMonitorCmd,
+		postBackup:  o.configOptions.resolved.PostBackupCmd,
 	}
 
+	o.vaultOptions.auditSinkKind = o.configOptions.resolved.AuditSink
+	o.vaultOptions.readOnly = o.configOptions.resolved.ReadOnly
+	o.vaultOptions.confirmOutput = o.configOptions.resolved.ConfirmOutput
+	o.vaultOptions.keychain = o.configOptions.resolved.Keychain
+	o.vaultOptions.lockTimeout = time.Duration(o.configOptions.resolved.LockTimeout)
+	o.vaultOptions.token = os.Getenv(tokenEnv)
+	o.vaultOptions.passwordPolicy = newPasswordPolicy(o.configOptions.resolved)
+	o.vaultOptions.resolver = newProviderRegistry(o.configOptions.resolved)
+
+	webhooks, err := newWebhookEndpoints(o.configOptions.resolved)
+	if err != nil {
+		return err
+	}
+
+	o.vaultOptions.webhooks = webhooks
+	o.vaultOptions.mounts = o.configOptions.resolved.Mounts
+
 	return nil
 }
 
@@ -239,10 +488,24 @@ func (o *DefaultVltOptions) Run(ctx context.Context, args ...string) error {
 		cmd = args[0]
 	}
 
+	if o.vaultOptions.readOnly && slices.Contains(mutatingCommands, cmd) {
+		return fmt.Errorf("%s: %w", cmd, vaulterrors.ErrReadOnly)
+	}
+
+	o.vaultOptions.lockMode = vault.LockExclusive
+	if o.vaultOptions.readOnly || !slices.Contains(mutatingCommands, cmd) && !slices.Contains(serverCommands, cmd) {
+		o.vaultOptions.lockMode = vault.LockShared
+	}
+
 	if slices.Contains(preRunPartialCommands, cmd) {
 		return nil
 	}
 
+	// tokens are used by scripts, where no session daemon is expected.
+	if len(o.vaultOptions.token) > 0 {
+		return o.vaultOptions.Open(ctx, o.StdioOptions, nil, 0)
+	}
+
 	c, err := vaultdaemon.NewSessionClient()
 	if err != nil {
 		o.Infof("vlt: daemon unavailable, continuing without session support\nTo enable session support, make sure the 'vltd' daemon is running.\n\n")
@@ -251,7 +514,15 @@ func (o *DefaultVltOptions) Run(ctx context.Context, args ...string) error {
 	o.sessionClient = c
 	sessionDuration := time.Duration(o.configOptions.resolved.SessionDuration)
 
-	return o.vaultOptions.Open(ctx, o.StdioOptions, o.sessionClient, sessionDuration)
+	if err := o.vaultOptions.Open(ctx, o.StdioOptions, o.sessionClient, sessionDuration); err != nil {
+		return err
+	}
+
+	if slices.Contains(destructiveCommands, cmd) {
+		return o.backupBeforeDestructive(ctx, cmd)
+	}
+
+	return nil
 }
 
 // NewDefaultVltCommand creates the `vlt` command with its sub-commands.
@@ -265,22 +536,43 @@ func NewDefaultVltCommand(iostreams *genericclioptions.IOStreams, args []string)
 		Long: `vlt is an encrypted in-memory command-line secret manager.
 
 Environment Variables:
-    VLT_CONFIG_PATH: overrides the default config path: "~/.vlt.toml".`,
+    VLT_CONFIG_PATH: overrides the default config path: "~/.vlt.toml".
+    VLT_TOKEN:       opens the vault using a scoped access token instead of the master password.
+
+Commands not built into vlt are passed through to 'vlt-<command>' plugins found on PATH (see 'vlt plugin').
+
+Commands printing JSON using '-o json' print errors to stderr as JSON as well:
+    {"error": {"code": "SECRET_NOT_FOUND", "message": "..."}}
+Error codes are stable, and shared with the APIs of 'vlt serve'.`,
 		SilenceUsage: true,
 		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
-			if slices.Contains(preRunSkipCommands, cmd.Name()) {
+			if slices.Contains(jsonOutputCommands, commandName(cmd)) {
+				if f := cmd.Flags().Lookup("output"); f != nil && f.Value.String() == "json" {
+					clierror.JSONMode(true)
+				}
+			}
+
+			if slices.Contains(preRunSkipCommands, commandName(cmd)) {
 				return
 			}
 
-			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, cmd.Name()))
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, commandName(cmd)))
 		},
 		PersistentPostRun: func(cmd *cobra.Command, _ []string) {
-			if slices.Contains(postRunSkipCommands, cmd.Name()) {
+			name := commandName(cmd)
+			if slices.Contains(postRunSkipCommands, name) {
 				return
 			}
 
+			if slices.Contains(mutatingCommands, name) {
+				o.backupOnMutation(cmd.Context())
+				o.touchDirenvStamp()
+			}
+
 			clierror.Check(errors.Join(
-				o.vaultOptions.vault.Close(cmd.Context()),
+				o.vaultOptions.closeMounts(cmd.Context()),
+				o.vaultOptions.closeVault(cmd.Context()),
+				o.vaultOptions.Close(),
 				o.sessionClient.Close(),
 			))
 		},
@@ -291,6 +583,9 @@ Environment Variables:
 	cmd.PersistentFlags().BoolVarP(&o.Verbose, "verbose", "v", false, "enable verbose output")
 	cmd.PersistentFlags().StringVarP(&o.configOptions.cliFlags.vaultPath, "file", "f", "",
 		fmt.Sprintf("database file path (default: ~/%s)", defaultDatabaseFilename))
+	cmd.PersistentFlags().BoolVarP(&o.configOptions.cliFlags.readOnly, "read-only", "", false, "reject commands that modify the vault")
+	cmd.PersistentFlags().BoolVarP(&o.vaultOptions.acceptChanges, "accept-changes", "", false, "trust vault contents modified outside of vlt")
+	cmd.PersistentFlags().BoolVarP(&o.vaultOptions.strict, "strict", "", false, "refuse to open a vault with unsafe ownership or permissions")
 	cmd.PersistentFlags().StringVarP(
 		&o.configOptions.cliFlags.configPath,
 		"config",
@@ -305,12 +600,64 @@ Environment Variables:
 	cmd.AddCommand(NewCmdCreate(o))
 	cmd.AddCommand(NewCmdRemove(o))
 	cmd.AddCommand(NewCmdUpdate(o))
+	cmd.AddCommand(NewCmdRotate(o))
+	cmd.AddCommand(NewCmdRotateMaster(o))
+	cmd.AddCommand(NewCmdScheduler(o))
+	cmd.AddCommand(NewCmdEdit(o))
+	cmd.AddCommand(NewCmdHistory(o))
+	cmd.AddCommand(NewCmdTrash(o))
+	cmd.AddCommand(NewCmdTOTP(o))
+	cmd.AddCommand(NewCmdAttach(o))
+	cmd.AddCommand(NewCmdAttachment(o))
+	cmd.AddCommand(NewCmdEncrypt(o))
+	cmd.AddCommand(NewCmdDecrypt(o))
 	cmd.AddCommand(NewCmdImport(o))
 	cmd.AddCommand(NewCmdExport(o))
+	cmd.AddCommand(NewCmdSplit(o))
+	cmd.AddCommand(NewCmdShare(o))
 	cmd.AddCommand(NewCmdLogin(o))
+	cmd.AddCommand(NewCmdPAMHelper(o))
 	cmd.AddCommand(NewCmdSave(o))
 	cmd.AddCommand(NewCmdFind(o))
+	cmd.AddCommand(NewCmdMatch(o))
+	cmd.AddCommand(NewCmdLabels(o))
+	cmd.AddCommand(NewCmdSearch(o))
+	cmd.AddCommand(NewCmdFrecency(o))
+	cmd.AddCommand(NewCmdCard(o))
+	cmd.AddCommand(NewCmdIdentity(o))
+	cmd.AddCommand(NewCmdNote(o))
+	cmd.AddCommand(NewCmdSSHKey(o))
+	cmd.AddCommand(NewCmdRecovery(o))
 	cmd.AddCommand(NewCmdShow(o))
+	cmd.AddCommand(NewCmdClipboardClear(o))
+	cmd.AddCommand(NewCmdGet(o))
+	cmd.AddCommand(NewCmdTerraformExternal(o))
+	cmd.AddCommand(NewCmdDirenv(o))
+	cmd.AddCommand(NewCmdDmenu(o))
+	cmd.AddCommand(NewCmdWidget(o))
+	cmd.AddCommand(NewCmdTmux(o))
+	cmd.AddCommand(NewCmdOpen(o))
+	cmd.AddCommand(NewCmdAuditLog(o))
+	cmd.AddCommand(NewCmdAudit(o))
+	cmd.AddCommand(NewCmdWatch(o))
+	cmd.AddCommand(NewCmdMonitor(o))
+	cmd.AddCommand(NewCmdCheck(o))
+	cmd.AddCommand(NewCmdDoctor(o))
+	cmd.AddCommand(NewCmdBench(o))
+	cmd.AddCommand(NewCmdToken(o))
+	cmd.AddCommand(NewCmdServe(o))
+	cmd.AddCommand(NewCmdAgent(o))
+	cmd.AddCommand(NewCmdRPC(o))
+	cmd.AddCommand(NewCmdKeePassXC(o))
+	cmd.AddCommand(NewCmdSync(o))
+	cmd.AddCommand(NewCmdBackup(o))
+	cmd.AddCommand(NewCmdRestore(o))
+	cmd.AddCommand(NewCmdLink(o))
+	cmd.AddCommand(NewCmdCloud(o))
+	cmd.AddCommand(NewCmdSOPS(o))
+	cmd.AddCommand(NewCmdPlugin(o))
+
+	addPluginCommand(cmd, o, args)
 
 	return cmd
 }
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/clipboard"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultClipboardClearAfter is the fallback when no clipboard clear delay is set.
+const defaultClipboardClearAfter = "45s"
+
+// scheduleClipboardClear starts a detached 'vlt clipboard-clear' process,
+// clearing the clipboard after the given duration if it still holds the
+// given value. The value digest is passed on stdin, not to be listed
+// among the process arguments.
+func scheduleClipboardClear(flags *Flags, value string, after time.Duration) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	var args []string
+
+	if len(flags.configPath) > 0 {
+		path, err := filepath.Abs(flags.configPath)
+		if err != nil {
+			return err
+		}
+
+		args = append(args, "--config", path)
+	}
+
+	args = append(args, "clipboard-clear", "--after", after.String())
+
+	// the digest is written before starting the process, fitting in the pipe buffer,
+	// so that nothing is left to copy once vlt exits.
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer func() { //nolint:wsl
+		_ = r.Close()
+	}()
+
+	_, err = w.WriteString(clipboard.Digest(value) + "\n")
+	if err := errors.Join(err, w.Close()); err != nil {
+		return err
+	}
+
+	//nolint:gosec // G204: runs the vlt executable itself.
+	cmd := exec.Command(exe, args...)
+	cmd.Stdin = r
+	cmd.SysProcAttr = detachedProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	return cmd.Process.Release()
+}
+
+type ClipboardClearError struct {
+	Err error
+}
+
+func (e *ClipboardClearError) Error() string { return "clipboard-clear: " + e.Err.Error() }
+
+func (e *ClipboardClearError) Unwrap() error { return e.Err }
+
+// ClipboardClearOptions holds data required to run the command.
+type ClipboardClearOptions struct {
+	*genericclioptions.StdioOptions
+
+	after time.Duration // after is how long to wait before clearing the clipboard.
+}
+
+var _ genericclioptions.CmdOptions = &ClipboardClearOptions{}
+
+// NewClipboardClearOptions initializes the options struct.
+func NewClipboardClearOptions(stdio *genericclioptions.StdioOptions) *ClipboardClearOptions {
+	return &ClipboardClearOptions{
+		StdioOptions: stdio,
+	}
+}
+
+func (*ClipboardClearOptions) Complete() error { return nil }
+
+func (o *ClipboardClearOptions) Validate() error {
+	if o.after < 0 {
+		return &ClipboardClearError{errors.New("--after must not be negative")}
+	}
+
+	return nil
+}
+
+func (o *ClipboardClearOptions) Run(ctx context.Context, _ ...string) error {
+	digest, err := bufio.NewReader(o.In).ReadString('\n')
+	if err != nil {
+		return &ClipboardClearError{err}
+	}
+
+	select {
+	case <-time.After(o.after):
+	case <-ctx.Done():
+		return nil
+	}
+
+	cleared, err := clipboard.ClearIf(strings.TrimSpace(digest))
+	if err != nil {
+		return &ClipboardClearError{err}
+	}
+
+	o.Debugf("clipboard cleared: %t\n", cleared)
+
+	return nil
+}
+
+// NewCmdClipboardClear creates the clipboard-clear cobra command.
+func NewCmdClipboardClear(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewClipboardClearOptions(defaults.StdioOptions)
+
+	cmd := &cobra.Command{
+		Use:    "clipboard-clear",
+		Short:  "Clear the clipboard after a delay if it still holds a value, run by 'vlt show --copy'",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().DurationVarP(&o.after, "after", "", 0, "how long to wait before clearing the clipboard")
+
+	return cmd
+}
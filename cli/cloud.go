@@ -0,0 +1,414 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/cloudsync"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/provider"
+
+	"github.com/spf13/cobra"
+)
+
+type CloudError struct {
+	Err error
+}
+
+func (e *CloudError) Error() string { return "cloud: " + e.Err.Error() }
+
+func (e *CloudError) Unwrap() error { return e.Err }
+
+const (
+	// defaultGCPProfile is the profile used when none is set, if defined.
+	defaultGCPProfile = "default"
+
+	// defaultGCPPrefix is the fallback when no gcp prefix is set,
+	// secret ids cannot contain '/'.
+	defaultGCPPrefix = "vlt-"
+
+	// defaultAzurePrefix is the fallback when no azure prefix is set,
+	// secret names cannot contain '/'.
+	defaultAzurePrefix = "vlt-"
+)
+
+// cloudProvider describes a cloud secret manager synced by 'vlt cloud'.
+type cloudProvider struct {
+	name         string // name is the sub-command name.
+	title        string
+	profileUsage string // profileUsage is the usage of the --profile flag, if supported.
+
+	// target returns the store secrets are synced with using
+	// the given profile, or the default one if empty.
+	target func(config *ResolvedConfig, profile string) (cloudTarget, error)
+}
+
+// cloudTarget is a store secrets are synced with.
+type cloudTarget struct {
+	store  provider.Store
+	key    string // key identifies the store, sync mappings are recorded by it.
+	prefix string // prefix is prepended to vault secret names to form remote names.
+}
+
+var cloudProviders = []cloudProvider{
+	{
+		name:         "aws",
+		title:        "AWS Secrets Manager",
+		profileUsage: "the shared credentials profile (default: 'providers.aws.profile' config)",
+		target: func(config *ResolvedConfig, profile string) (cloudTarget, error) {
+			return cloudTarget{store: newAWSProvider(config, profile), key: "aws", prefix: config.AWSPrefix}, nil
+		},
+	},
+	{
+		name:         "gcp",
+		title:        "Google Cloud Secret Manager",
+		profileUsage: "the 'providers.gcp.profiles' project (default: 'providers.gcp.profile' config)",
+		target: func(config *ResolvedConfig, profile string) (cloudTarget, error) {
+			p, err := gcpProfile(config, profile)
+			if err != nil {
+				return cloudTarget{}, err
+			}
+
+			gcp := newGCPProvider(p)
+
+			// projects are separate stores, a secret may be synced with several.
+			return cloudTarget{store: gcp, key: "gcp:" + gcp.Project(), prefix: config.GCPPrefix}, nil
+		},
+	},
+	{
+		name:  "azure",
+		title: "Azure Key Vault",
+		target: func(config *ResolvedConfig, _ string) (cloudTarget, error) {
+			azure := newAzureProvider(config)
+
+			// key vaults are separate stores, a secret may be synced with several.
+			return cloudTarget{store: azure, key: "azure:" + azure.Vault(), prefix: config.AzurePrefix}, nil
+		},
+	},
+}
+
+// newAWSProvider returns an AWS Secrets Manager provider configured by the
+// given config, using the given shared credentials profile if set.
+func newAWSProvider(config *ResolvedConfig, profile string) *provider.AWS {
+	var opts []provider.AWSOpt
+
+	if len(config.AWSRegion) > 0 {
+		opts = append(opts, provider.WithAWSRegion(config.AWSRegion))
+	}
+
+	if p := cmp.Or(profile, config.AWSProfile); len(p) > 0 {
+		opts = append(opts, provider.WithAWSProfile(p))
+	}
+
+	if len(config.AWSEndpoint) > 0 {
+		opts = append(opts, provider.WithAWSEndpoint(config.AWSEndpoint))
+	}
+
+	return provider.NewAWS(opts...)
+}
+
+// gcpProfile returns the given Google Cloud profile, or the configured one if empty.
+// An undefined default profile falls back to the environment's project.
+func gcpProfile(config *ResolvedConfig, name string) (GCPProfileConfig, error) {
+	if len(name) == 0 && len(config.GCPProfile) == 0 {
+		if p, ok := config.GCPProfiles[defaultGCPProfile]; ok {
+			return *p, nil
+		}
+
+		return GCPProfileConfig{}, nil
+	}
+
+	name = cmp.Or(name, config.GCPProfile)
+
+	p, ok := config.GCPProfiles[name]
+	if !ok {
+		return GCPProfileConfig{}, fmt.Errorf("undefined gcp profile %q (defined: %v)", name, slices.Sorted(maps.Keys(config.GCPProfiles)))
+	}
+
+	return *p, nil
+}
+
+// newGCPProvider returns a Google Cloud Secret Manager provider of the given profile.
+func newGCPProvider(p GCPProfileConfig) *provider.GCP {
+	return provider.NewGCP(provider.WithGCPProject(p.Project), provider.WithGCPLocations(p.Locations...))
+}
+
+// newAzureProvider returns an Azure Key Vault provider configured by the given config.
+func newAzureProvider(config *ResolvedConfig) *provider.Azure {
+	return provider.NewAzure(provider.WithAzureVault(config.AzureVault), provider.WithAzureDNSSuffix(config.AzureDNSSuffix))
+}
+
+// NewCmdCloud creates the cloud cobra command with its sub-commands.
+func NewCmdCloud(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cloud",
+		Short: "Sync secrets with cloud secret managers (subcommands available)",
+		Long: `Push secrets to, and pull secrets from, cloud secret managers.
+
+Secrets are selected by their labels, which are stored along with their
+values in the cloud, e.g., as tags. Remote secrets are named after the
+vault secrets, prefixed by the provider's 'prefix' config.
+
+Each sync records a digest of the synced value and labels, so that
+changes made on either side since are detected:
+    in-sync:         the secret is the same on both sides.
+    local-changed:   the secret changed in the vault.
+    remote-changed:  the secret changed in the cloud.
+    diverged:        the secret changed on both sides, or was never synced
+                     and differs.
+    local-only:      the secret is only in the vault.
+    remote-only:     the secret is only in the cloud.
+
+Pushing and pulling skip secrets that would overwrite changes made
+on the other side, unless --force is set.`,
+	}
+
+	for _, p := range cloudProviders {
+		cmd.AddCommand(newCmdCloudProvider(defaults, p))
+	}
+
+	return cmd
+}
+
+// newCmdCloudProvider creates the cloud sub-command of the given provider.
+func newCmdCloudProvider(defaults *DefaultVltOptions, p cloudProvider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   p.name,
+		Short: fmt.Sprintf("Sync secrets with %s (subcommands available)", p.title),
+		Long:  fmt.Sprintf("Push secrets to, and pull secrets from, %s.\n\nSee 'vlt cloud --help' for details.", p.title),
+	}
+
+	cmd.AddCommand(NewCmdCloudStatus(defaults, p))
+	cmd.AddCommand(NewCmdCloudPush(defaults, p))
+	cmd.AddCommand(NewCmdCloudPull(defaults, p))
+
+	return cmd
+}
+
+type cloudAction int
+
+const (
+	cloudStatus cloudAction = iota
+	cloudPush
+	cloudPull
+)
+
+// CloudOptions holds data required to run the command.
+type CloudOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config   *ResolvedConfig
+	provider cloudProvider
+	action   cloudAction
+
+	profile string
+	labels  []string
+	force   bool
+	dryRun  bool
+}
+
+var _ genericclioptions.CmdOptions = &CloudOptions{}
+
+// NewCloudOptions initializes the options struct.
+func NewCloudOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig, p cloudProvider, action cloudAction) *CloudOptions {
+	return &CloudOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+		provider:     p,
+		action:       action,
+	}
+}
+
+func (*CloudOptions) Complete() error { return nil }
+
+func (o *CloudOptions) Validate() error {
+	// pushing every secret is rarely intended, require an explicit selection.
+	if o.action == cloudPush && len(o.labels) == 0 {
+		return &CloudError{errors.New("no secrets selected; use --label (e.g., --label 'prod/*')")}
+	}
+
+	return nil
+}
+
+func (o *CloudOptions) Run(ctx context.Context, _ ...string) error {
+	target, err := o.provider.target(o.config, o.profile)
+	if err != nil {
+		return &CloudError{err}
+	}
+
+	s := &cloudsync.Syncer{
+		Vault:    o.vault,
+		Store:    target.store,
+		Provider: target.key,
+		Prefix:   target.prefix,
+	}
+
+	entries, err := s.Plan(ctx, o.labels)
+	if err != nil {
+		return &CloudError{err}
+	}
+
+	if len(entries) == 0 {
+		o.Warnf("No matching secrets found.\n")
+		return nil
+	}
+
+	if o.action == cloudStatus || o.dryRun {
+		o.printEntries(entries)
+		return nil
+	}
+
+	var (
+		res  *cloudsync.Result
+		verb string
+	)
+
+	switch o.action {
+	case cloudPush:
+		res, err = s.Push(ctx, entries, o.force)
+		verb = "Pushed %d secret(s) to %s.\n"
+	case cloudPull:
+		res, err = s.Pull(ctx, entries, o.force)
+		verb = "Pulled %d secret(s) from %s.\n"
+	}
+
+	// report partial progress before failing.
+	o.Infof(verb, len(res.Applied), o.provider.title)
+
+	if err != nil {
+		return &CloudError{err}
+	}
+
+	for _, e := range res.Skipped {
+		o.Warnf("Skipped %q (%s); use --force to overwrite.\n", e.Name, e.Status)
+	}
+
+	if len(res.Applied) == 0 {
+		return nil
+	}
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		o.Warnf("Post-write hook failed: %v", err)
+	}
+
+	return nil
+}
+
+func (o *CloudOptions) printEntries(entries []cloudsync.Entry) {
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tREMOTE\tSTATUS")
+
+	for _, e := range entries {
+		id := "-"
+		if e.SecretID > 0 {
+			id = strconv.Itoa(e.SecretID)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", id, e.Name, e.RemoteName, e.Status)
+	}
+
+	fmt.Fprintln(tw) // add padding
+}
+
+// NewCmdCloudStatus creates the cloud status cobra command of the given provider.
+func NewCmdCloudStatus(defaults *DefaultVltOptions, p cloudProvider) *cobra.Command {
+	o := NewCloudOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved, p, cloudStatus)
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: fmt.Sprintf("Show the sync status of secrets with %s", p.title),
+		Long: fmt.Sprintf(`Show the sync status of vault secrets and %s secrets.
+
+Secrets with a label matching any of the given --label glob patterns are
+shown, or all secrets if none are given.`, p.title),
+		Example: fmt.Sprintf(`  # Show the status of all production secrets
+  vlt cloud %s status --label 'prod/*'`, p.name),
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	if len(p.profileUsage) > 0 {
+		cmd.Flags().StringVarP(&o.profile, "profile", "", "", p.profileUsage)
+	}
+
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "select secrets with a label matching the glob pattern (comma-separated or repeated)")
+
+	return cmd
+}
+
+// NewCmdCloudPush creates the cloud push cobra command of the given provider.
+func NewCmdCloudPush(defaults *DefaultVltOptions, p cloudProvider) *cobra.Command {
+	o := NewCloudOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved, p, cloudPush)
+
+	cmd := &cobra.Command{
+		Use:   "push --label PATTERN",
+		Short: fmt.Sprintf("Push secrets to %s", p.title),
+		Long: fmt.Sprintf(`Push vault secrets with a label matching any of the given --label
+glob patterns to %s, creating or updating remote secrets.
+
+Secrets changed in the cloud since last synced are skipped, unless --force is set.`, p.title),
+		Example: fmt.Sprintf(`  # Push all production secrets
+  vlt cloud %[1]s push --label 'prod/*'
+
+  # Show what would be pushed
+  vlt cloud %[1]s push --label 'prod/*' --dry-run`, p.name),
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	if len(p.profileUsage) > 0 {
+		cmd.Flags().StringVarP(&o.profile, "profile", "", "", p.profileUsage)
+	}
+
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "push secrets with a label matching the glob pattern (comma-separated or repeated)")
+	cmd.Flags().BoolVarP(&o.force, "force", "", false, "overwrite secrets changed in the cloud")
+	cmd.Flags().BoolVarP(&o.dryRun, "dry-run", "", false, "show the sync status without pushing")
+
+	return cmd
+}
+
+// NewCmdCloudPull creates the cloud pull cobra command of the given provider.
+func NewCmdCloudPull(defaults *DefaultVltOptions, p cloudProvider) *cobra.Command {
+	o := NewCloudOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved, p, cloudPull)
+
+	cmd := &cobra.Command{
+		Use:   "pull [--label PATTERN]",
+		Short: fmt.Sprintf("Pull secrets from %s", p.title),
+		Long: fmt.Sprintf(`Pull %s secrets with a label matching any of the given
+--label glob patterns, or all secrets under the configured prefix, into the vault.
+
+Secrets only in the cloud are added, changed secrets are updated.
+Secrets changed in the vault since last synced are skipped, unless --force is set.`, p.title),
+		Example: fmt.Sprintf(`  # Pull all production secrets
+  vlt cloud %s pull --label 'prod/*'`, p.name),
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	if len(p.profileUsage) > 0 {
+		cmd.Flags().StringVarP(&o.profile, "profile", "", "", p.profileUsage)
+	}
+
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "pull secrets with a label matching the glob pattern (comma-separated or repeated)")
+	cmd.Flags().BoolVarP(&o.force, "force", "", false, "overwrite secrets changed in the vault")
+	cmd.Flags().BoolVarP(&o.dryRun, "dry-run", "", false, "show the sync status without pulling")
+
+	return cmd
+}
@@ -5,12 +5,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/cloudsync"
 	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/policy"
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+	"github.com/ladzaretti/vlt-cli/vaultbackup"
 
 	"github.com/pelletier/go-toml/v2"
 	"github.com/spf13/cobra"
@@ -30,6 +36,7 @@ type ConfigOptions struct {
 type Flags struct {
 	configPath string
 	vaultPath  string
+	readOnly   bool
 }
 
 // ResolvedConfig contains the final merged configuration.
@@ -39,11 +46,80 @@ type Flags struct {
 type ResolvedConfig struct {
 	CopyCmd         string   `json:"copy_cmd,omitempty"`
 	PasteCmd        string   `json:"paste_cmd,omitempty"`
+	ClipboardPlugin string   `json:"clipboard_plugin,omitempty"`
+	ClipboardClear  Duration `json:"clipboard_clear_after,omitempty"`
 	SessionDuration Duration `json:"session_duration,omitempty"`
+	LockTimeout     Duration `json:"lock_timeout,omitempty"`
 	VaultPath       string   `json:"vault_path,omitempty"`
 	FindPipeCmd     []string `json:"find_pipe_cmd,omitempty"`
 	PostLoginCmd    []string `json:"post_login_cmd,omitempty"`
 	PostWriteCmd    []string `json:"post_write_cmd,omitempty"`
+	PostMonitorCmd  []string `json:"post_monitor_cmd,omitempty"`
+	PostBackupCmd   []string `json:"post_backup_cmd,omitempty"`
+	AuditSink       string   `json:"audit_sink,omitempty"`
+	AuditGPGKey     string   `json:"audit_gpg_key,omitempty"`
+	ReadOnly        bool     `json:"read_only,omitempty"`
+	ConfirmOutput   bool     `json:"confirm_output,omitempty"`
+	Keychain        bool     `json:"keychain,omitempty"`
+	PolicyMode      string   `json:"policy_mode,omitempty"`
+
+	BackupDir               string `json:"backup_dir,omitempty"`
+	BackupEvery             int    `json:"backup_every,omitempty"`
+	BackupBeforeDestructive bool   `json:"backup_before_destructive,omitempty"`
+	BackupFullEvery         int    `json:"backup_full_every,omitempty"`
+
+	LinkCacheTTL       Duration `json:"link_cache_ttl,omitempty"`
+	HashiCorpAddress   string   `json:"hashicorp_address,omitempty"`
+	HashiCorpNamespace string   `json:"hashicorp_namespace,omitempty"`
+	AWSRegion          string   `json:"aws_region,omitempty"`
+	AWSProfile         string   `json:"aws_profile,omitempty"`
+	AWSEndpoint        string   `json:"aws_endpoint,omitempty"`
+	AWSPrefix          string   `json:"aws_prefix,omitempty"`
+	GCPProfile         string   `json:"gcp_profile,omitempty"`
+	GCPPrefix          string   `json:"gcp_prefix,omitempty"`
+	AzureVault         string   `json:"azure_vault,omitempty"`
+	AzureDNSSuffix     string   `json:"azure_dns_suffix,omitempty"`
+	AzurePrefix        string   `json:"azure_prefix,omitempty"`
+	SOPSKey            string   `json:"sops_key,omitempty"`
+	ShareKey           string   `json:"share_key,omitempty"`
+
+	// GCPProfiles maps profile names to the Google Cloud projects secrets are stored in.
+	GCPProfiles map[string]*GCPProfileConfig `json:"gcp_profiles,omitempty"`
+
+	// Webhooks maps endpoint names to the endpoints notified of vault events.
+	Webhooks map[string]*WebhookConfig `json:"webhooks,omitempty"`
+
+	DmenuPicker  []string `json:"dmenu_picker,omitempty"`
+	DmenuAction  string   `json:"dmenu_action,omitempty"`
+	DmenuTypeCmd []string `json:"dmenu_type_cmd,omitempty"`
+
+	// DmenuKeybindings maps rofi key bindings to dmenu actions.
+	DmenuKeybindings map[string]string `json:"dmenu_keybindings,omitempty"`
+
+	OpenBrowserCmd    []string `json:"open_browser_cmd,omitempty"`
+	OpenPasswordDelay Duration `json:"open_password_delay,omitempty"`
+	OpenWaitForKey    bool     `json:"open_wait_for_key,omitempty"`
+
+	FrecencyHalfLife Duration `json:"frecency_half_life,omitempty"`
+
+	// Mounts maps mount names to the paths of the mounted vaults.
+	Mounts map[string]string `json:"mounts,omitempty"`
+
+	// RotationHooks maps label glob patterns to the commands rotating
+	// the secrets having a matching label.
+	RotationHooks map[string][]string `json:"rotation_hooks,omitempty"`
+
+	// RotationIntervals maps label glob patterns to how often
+	// the secrets having a matching label are rotated.
+	RotationIntervals map[string]Duration `json:"rotation_intervals,omitempty"`
+
+	RotationReminderCmd []string `json:"rotation_reminder_cmd,omitempty"`
+
+	// BackupRetention is the retention policy applied after each backup.
+	BackupRetention vaultbackup.Retention `json:"backup_retention,omitzero"`
+
+	// Policy is the password policy set built from the policy config section.
+	Policy policy.Set `json:"-"`
 }
 
 type Duration time.Duration
@@ -80,10 +156,98 @@ func (o *ConfigOptions) Complete() error {
 func (o *ConfigOptions) resolve() error {
 	o.resolved.CopyCmd = o.fileConfig.Clipboard.CopyCmd
 	o.resolved.PasteCmd = o.fileConfig.Clipboard.PasteCmd
+	o.resolved.ClipboardPlugin = o.fileConfig.Clipboard.Plugin
 	o.resolved.FindPipeCmd = o.fileConfig.Pipeline.FindPipeCmd
 	o.resolved.PostLoginCmd = o.fileConfig.Hooks.PostLoginCmd
 	o.resolved.PostWriteCmd = o.fileConfig.Hooks.PostWriteCmd
+	o.resolved.PostMonitorCmd = o.fileConfig.Hooks.PostMonitorCmd
+	o.resolved.AuditSink = o.fileConfig.Audit.Sink
+	o.resolved.AuditGPGKey = o.fileConfig.Audit.GPGKey
 	o.resolved.VaultPath = cmp.Or(o.cliFlags.vaultPath, o.fileConfig.Vault.Path)
+	o.resolved.ReadOnly = o.cliFlags.readOnly || o.fileConfig.Vault.ReadOnly
+	o.resolved.ConfirmOutput = o.fileConfig.Vault.ConfirmOutput
+	o.resolved.Keychain = o.fileConfig.Vault.Keychain
+	o.resolved.PostBackupCmd = o.fileConfig.Hooks.PostBackupCmd
+	o.resolved.BackupDir = o.fileConfig.Backup.Dir
+	o.resolved.BackupEvery = o.fileConfig.Backup.Every
+	o.resolved.BackupBeforeDestructive = o.fileConfig.Backup.BeforeDestructive
+	o.resolved.BackupFullEvery = cmp.Or(o.fileConfig.Backup.FullEvery, vaultbackup.DefaultFullEvery)
+	o.resolved.BackupRetention = vaultbackup.Retention{
+		Daily:   o.fileConfig.Backup.KeepDaily,
+		Weekly:  o.fileConfig.Backup.KeepWeekly,
+		Monthly: o.fileConfig.Backup.KeepMonthly,
+	}
+
+	o.resolved.HashiCorpAddress = o.fileConfig.Providers.HashiCorp.Address
+	o.resolved.HashiCorpNamespace = o.fileConfig.Providers.HashiCorp.Namespace
+	o.resolved.AWSRegion = o.fileConfig.Providers.AWS.Region
+	o.resolved.AWSProfile = o.fileConfig.Providers.AWS.Profile
+	o.resolved.AWSEndpoint = o.fileConfig.Providers.AWS.Endpoint
+	o.resolved.AWSPrefix = cmp.Or(o.fileConfig.Providers.AWS.Prefix, cloudsync.DefaultPrefix)
+	o.resolved.GCPProfile = o.fileConfig.Providers.GCP.Profile
+	o.resolved.GCPPrefix = cmp.Or(o.fileConfig.Providers.GCP.Prefix, defaultGCPPrefix)
+	o.resolved.GCPProfiles = o.fileConfig.Providers.GCP.Profiles
+	o.resolved.Webhooks = o.fileConfig.Webhooks.Endpoints
+	o.resolved.Mounts = make(map[string]string, len(o.fileConfig.Mounts.Vaults))
+	o.resolved.AzureVault = o.fileConfig.Providers.Azure.Vault
+	o.resolved.AzureDNSSuffix = o.fileConfig.Providers.Azure.DNSSuffix
+	o.resolved.AzurePrefix = cmp.Or(o.fileConfig.Providers.Azure.Prefix, defaultAzurePrefix)
+	o.resolved.SOPSKey = o.fileConfig.SOPS.Key
+	o.resolved.ShareKey = o.fileConfig.Share.Key
+	o.resolved.DmenuPicker = o.fileConfig.Dmenu.Picker
+	o.resolved.DmenuAction = cmp.Or(o.fileConfig.Dmenu.Action, dmenuActionCopy)
+	o.resolved.DmenuTypeCmd = o.fileConfig.Dmenu.TypeCmd
+	o.resolved.DmenuKeybindings = o.fileConfig.Dmenu.Keybindings
+	o.resolved.OpenBrowserCmd = o.fileConfig.Open.BrowserCmd
+	o.resolved.OpenWaitForKey = o.fileConfig.Open.WaitForKey
+	o.resolved.RotationHooks = o.fileConfig.Rotation.Hooks
+	o.resolved.RotationReminderCmd = o.fileConfig.Rotation.ReminderCmd
+	o.resolved.RotationIntervals = make(map[string]Duration, len(o.fileConfig.Rotation.Intervals))
+
+	for name, m := range o.fileConfig.Mounts.Vaults {
+		o.resolved.Mounts[name] = m.Path
+	}
+
+	for label, every := range o.fileConfig.Rotation.Intervals {
+		d, err := cmdutil.ParseDuration(every)
+		if err != nil {
+			return fmt.Errorf("invalid rotation interval %q: %w", label, err)
+		}
+
+		o.resolved.RotationIntervals[label] = Duration(d)
+	}
+
+	linkCacheTTL, err := cmdutil.ParseDuration(cmp.Or(o.fileConfig.Providers.CacheTTL, defaultLinkCacheTTL))
+	if err != nil {
+		return fmt.Errorf("invalid link cache ttl: %w", err)
+	}
+
+	o.resolved.LinkCacheTTL = Duration(linkCacheTTL)
+
+	clearAfter, err := cmdutil.ParseDuration(cmp.Or(o.fileConfig.Clipboard.ClearAfter, defaultClipboardClearAfter))
+	if err != nil {
+		return fmt.Errorf("invalid clipboard clear after: %w", err)
+	}
+
+	o.resolved.ClipboardClear = Duration(clearAfter)
+
+	passwordDelay, err := cmdutil.ParseDuration(cmp.Or(o.fileConfig.Open.PasswordDelay, defaultOpenPasswordDelay))
+	if err != nil {
+		return fmt.Errorf("invalid open password delay: %w", err)
+	}
+
+	o.resolved.OpenPasswordDelay = Duration(passwordDelay)
+
+	halfLife, err := cmdutil.ParseDuration(cmp.Or(o.fileConfig.Frecency.HalfLife, defaultFrecencyHalfLife))
+	if err != nil {
+		return fmt.Errorf("invalid frecency half-life: %w", err)
+	}
+
+	o.resolved.FrecencyHalfLife = Duration(halfLife)
+
+	if err := o.resolvePolicy(); err != nil {
+		return err
+	}
 
 	if len(o.resolved.VaultPath) == 0 {
 		vaultPath, err := defaultVaultPath()
@@ -103,9 +267,75 @@ func (o *ConfigOptions) resolve() error {
 
 	o.resolved.SessionDuration = Duration(t)
 
+	lockTimeout, err := cmdutil.ParseDuration(cmp.Or(o.fileConfig.Vault.LockTimeout, defaultLockTimeout))
+	if err != nil {
+		return fmt.Errorf("invalid lock timeout: %w", err)
+	}
+
+	o.resolved.LockTimeout = Duration(lockTimeout)
+
+	return nil
+}
+
+func (o *ConfigOptions) resolvePolicy() error {
+	c := o.fileConfig.Policy
+
+	o.resolved.PolicyMode = cmp.Or(c.Mode, string(policy.ModeWarn))
+
+	p, err := resolvePolicyRules(&c.PolicyRulesConfig)
+	if err != nil {
+		return err
+	}
+
+	set := policy.Set{
+		Default: p,
+		Mode:    policy.Mode(o.resolved.PolicyMode),
+	}
+
+	// sorted for a deterministic merge order.
+	for _, label := range slices.Sorted(maps.Keys(c.Labels)) {
+		rules := c.Labels[label]
+
+		p, err := resolvePolicyRules(rules)
+		if err != nil {
+			return fmt.Errorf("policy label %q: %w", label, err)
+		}
+
+		set.Overrides = append(set.Overrides, policy.Override{
+			Label:  label,
+			Policy: p,
+			Mode:   policy.Mode(rules.Mode),
+		})
+	}
+
+	o.resolved.Policy = set
+
 	return nil
 }
 
+func resolvePolicyRules(c *PolicyRulesConfig) (policy.Policy, error) {
+	p := policy.Policy{
+		MinLength:   c.MinLength,
+		BannedWords: c.BannedWords,
+		Require2FA:  c.Require2FA,
+	}
+
+	for _, class := range c.RequiredClasses {
+		p.RequiredClasses = append(p.RequiredClasses, policy.Class(class))
+	}
+
+	if len(c.MaxAge) > 0 {
+		d, err := cmdutil.ParseDuration(c.MaxAge)
+		if err != nil {
+			return policy.Policy{}, fmt.Errorf("invalid policy max age: %w", err)
+		}
+
+		p.MaxAge = d
+	}
+
+	return p, nil
+}
+
 func defaultVaultPath() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
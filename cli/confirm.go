@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/input"
+
+	"golang.org/x/term"
+)
+
+// ErrConfirmationUnavailable indicates that a confirmation is required,
+// but cannot be prompted for since stdin is not a terminal.
+var ErrConfirmationUnavailable = errors.New("confirmation required before printing secrets to a terminal, but stdin is not interactive")
+
+// terminalFd returns the file descriptor of w
+// and reports whether it is a terminal.
+func terminalFd(w io.Writer) (int, bool) {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return 0, false
+	}
+
+	fd := int(f.Fd())
+
+	return fd, term.IsTerminal(fd)
+}
+
+// secretDescription describes the named secret in confirmation prompts.
+func secretDescription(name string) string { return fmt.Sprintf("secret %q", name) }
+
+// confirmPrint reports whether the plaintext secret described by what
+// (e.g., 'secret "github"') may be printed to stdout. If 'vault.confirm_output' is set and stdout is a terminal,
+// the user is asked to confirm, otherwise output is always allowed.
+func (o *VaultOptions) confirmPrint(io *genericclioptions.StdioOptions, what string) (bool, error) {
+	if !o.confirmOutput {
+		return true, nil
+	}
+
+	if _, ok := terminalFd(io.Out); !ok {
+		return true, nil
+	}
+
+	if io.NonInteractive {
+		return false, ErrConfirmationUnavailable
+	}
+
+	answer, err := input.PromptRead(io.Out, io.In, "Print plaintext %s to the terminal? [y/N]: ", what)
+	if err != nil {
+		return false, err
+	}
+
+	if a := strings.ToLower(answer); a == "y" || a == "yes" {
+		return true, nil
+	}
+
+	io.Warnf("Not printing %s.\n", what)
+
+	return false, nil
+}
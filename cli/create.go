@@ -64,6 +64,10 @@ func (o *CreateOptions) Run(ctx context.Context, _ ...string) error {
 		return fmt.Errorf("create vault: %w", err)
 	}
 
+	if err := os.Chmod(o.vaultOptions.path, vaultFilePerm); err != nil {
+		return fmt.Errorf("create vault: %w", err)
+	}
+
 	o.Infof("new vault successfully created at %q\n", o.vaultOptions.path)
 
 	return nil
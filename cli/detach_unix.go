@@ -0,0 +1,11 @@
+//go:build !windows
+
+package cli
+
+import "syscall"
+
+// detachedProcAttr returns the attributes of processes outliving vlt,
+// started in a new session, so they are not hung up with the terminal.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
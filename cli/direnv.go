@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vault"
+
+	"github.com/spf13/cobra"
+)
+
+// direnvActor is recorded in the audit log entries of secrets exported to direnv.
+const direnvActor = "direnv"
+
+// direnvStdlib defines the 'use vlt' .envrc helper.
+const direnvStdlib = `# use_vlt exports the vault secrets having any of the given labels to the
+# environment, reloading when the vault changes. Usage in .envrc:
+#
+#   use vlt project/foo
+#
+use_vlt() {
+  local args=() label
+  for label in "$@"; do
+    args+=(--label "$label")
+  done
+
+  eval "$(vlt direnv export --watch "${args[@]}")"
+}
+`
+
+type DirenvError struct {
+	Err error
+}
+
+func (e *DirenvError) Error() string { return "direnv: " + e.Err.Error() }
+
+func (e *DirenvError) Unwrap() error { return e.Err }
+
+// NewCmdDirenv creates the direnv cobra command with its sub-commands.
+func NewCmdDirenv(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "direnv",
+		Short: "Export secrets to direnv environments (subcommands available)",
+		Long: `Export secrets to per-project environments loaded by direnv.
+
+Add the 'use vlt' helper to ~/.config/direnv/direnvrc:
+
+  vlt direnv stdlib >> ~/.config/direnv/direnvrc
+
+Then load the secrets having any of the given labels in a project .envrc:
+
+  use vlt project/foo
+
+Secrets are exported as environment variables named by their upper-cased
+names, e.g., "db-password" is exported as DB_PASSWORD. As with 'vlt get',
+the vault is opened using the current session ('vlt login') or the VLT_TOKEN
+access token, without prompting.`,
+	}
+
+	cmd.AddCommand(NewCmdDirenvExport(defaults))
+	cmd.AddCommand(NewCmdDirenvStdlib(defaults))
+
+	return cmd
+}
+
+// DirenvExportOptions holds data required to run the command.
+type DirenvExportOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	search *SearchableOptions
+	watch  bool
+}
+
+var _ genericclioptions.CmdOptions = &DirenvExportOptions{}
+
+// NewDirenvExportOptions initializes the options struct.
+func NewDirenvExportOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *DirenvExportOptions {
+	return &DirenvExportOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		search:       NewSearchableOptions(),
+	}
+}
+
+func (*DirenvExportOptions) Complete() error { return nil }
+
+func (o *DirenvExportOptions) Validate() error {
+	if len(o.search.Labels) == 0 && len(o.search.Name) == 0 {
+		return &DirenvError{errors.New("at least one of --label or --name is required")}
+	}
+
+	return nil
+}
+
+func (o *DirenvExportOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &DirenvError{retErr}
+			return
+		}
+	}()
+
+	sessionClient, err := o.openNoPrompt(ctx, o.StdioOptions)
+	if err != nil {
+		return err
+	}
+	defer func() { //nolint:wsl
+		retErr = errors.Join(retErr, o.closeNoPrompt(ctx, sessionClient))
+	}()
+
+	ctx = vault.WithPrincipal(ctx, vault.Principal{Actor: direnvActor, ReadOnly: true})
+
+	secrets, err := o.searchAll(ctx, o.StdioOptions, o.search)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]string, len(secrets)) // env var name to secret name
+	lines := make([]string, 0, len(secrets)+1)
+
+	for _, s := range secrets {
+		key := envName(s.name)
+		if other, ok := names[key]; ok {
+			return fmt.Errorf("secrets %q and %q are both exported as %s", other, s.name, key)
+		}
+
+		names[key] = s.name
+
+		v, err := o.vaultOf(ctx, o.StdioOptions, s)
+		if err != nil {
+			return err
+		}
+
+		value, err := v.ShowSecret(ctx, s.id)
+		if err != nil {
+			return err
+		}
+
+		lines = append(lines, "export "+key+"="+shellQuote(value))
+	}
+
+	if o.watch {
+		stamp, err := createDirenvStamp(o.path)
+		if err != nil {
+			return err
+		}
+
+		lines = append(lines, "watch_file "+shellQuote(stamp))
+	}
+
+	for _, l := range lines {
+		o.Infof("%s\n", l)
+	}
+
+	return nil
+}
+
+// envName returns the environment variable name a secret is exported as:
+// its upper-cased name, with characters other than letters, digits and
+// underscores replaced by underscores.
+func envName(name string) string {
+	b := []byte(strings.ToUpper(name))
+	for i, c := range b {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '_' {
+			b[i] = '_'
+		}
+	}
+
+	if len(b) == 0 || b[0] >= '0' && b[0] <= '9' {
+		return "_" + string(b)
+	}
+
+	return string(b)
+}
+
+// direnvStampPath returns the path of the file touched when the given vault changes,
+// watched by direnv for reloading environments.
+func direnvStampPath(vaultPath string) (string, error) {
+	abs, err := filepath.Abs(vaultPath)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := userStateDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(abs))
+
+	return filepath.Join(dir, "vlt", "direnv", hex.EncodeToString(sum[:8])+".stamp"), nil
+}
+
+// createDirenvStamp creates the stamp file of the given vault,
+// if it does not exist, returning its path.
+func createDirenvStamp(vaultPath string) (string, error) {
+	path, err := direnvStampPath(vaultPath)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+
+	return path, os.WriteFile(path, nil, 0o600)
+}
+
+// touchDirenvStamp updates the modification time of the stamp file
+// of the vault, if watched, reloading direnv environments.
+func (o *DefaultVltOptions) touchDirenvStamp() {
+	path, err := direnvStampPath(o.vaultOptions.path)
+	if err != nil {
+		o.Warnf("vlt: direnv stamp: %v\n", err)
+		return
+	}
+
+	now := time.Now()
+
+	if err := os.Chtimes(path, now, now); err != nil && !errors.Is(err, os.ErrNotExist) {
+		o.Warnf("vlt: direnv stamp: %v\n", err)
+	}
+}
+
+// NewCmdDirenvExport creates the direnv export cobra command.
+func NewCmdDirenvExport(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewDirenvExportOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Print shell exports of the matching secrets, evaluated by direnv",
+		Long: `Print 'export KEY=value' lines of the secrets matching the given filters,
+for evaluation by direnv, e.g., in .envrc: eval "$(vlt direnv export --label project/foo)".
+
+Multiple --label flags are logically ORed, and support UNIX glob patterns.
+
+With --watch, a 'watch_file' line is printed as well, naming a stamp file
+touched by vlt commands modifying the vault, so direnv reloads the environment.`,
+		Example: `  # Print the exports of secrets labeled "project/foo"
+  vlt direnv export --label project/foo`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&o.search.Labels, "label", "", nil, FilterByLabels.Help())
+	cmd.Flags().StringVarP(&o.search.Name, "name", "", "", FilterByName.Help())
+	cmd.Flags().BoolVarP(&o.watch, "watch", "", false, "reload the environment when the vault changes")
+
+	return cmd
+}
+
+// NewCmdDirenvStdlib creates the direnv stdlib cobra command.
+func NewCmdDirenvStdlib(defaults *DefaultVltOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "stdlib",
+		Short: "Print the 'use vlt' helper for ~/.config/direnv/direnvrc",
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			defaults.Infof("%s", direnvStdlib)
+		},
+	}
+}
@@ -0,0 +1,333 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/clipboard"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/notify"
+	"github.com/ladzaretti/vlt-cli/urlmatch"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+// Actions applied by 'vlt dmenu' to the chosen secret.
+const (
+	dmenuActionCopy = "copy"
+	dmenuActionType = "type"
+)
+
+const (
+	// dmenuMaxKeybindings is the number of rofi custom key bindings, 'kb-custom-1' to 'kb-custom-19'.
+	dmenuMaxKeybindings = 19
+
+	// dmenuCustomExitCode is the exit code of rofi for 'kb-custom-1', incremented for the following ones.
+	dmenuCustomExitCode = 10
+)
+
+var dmenuActions = []string{dmenuActionCopy, dmenuActionType}
+
+// dmenuPickers are looked up on PATH in order, unless a picker is configured.
+var dmenuPickers = [][]string{
+	{"rofi", "-dmenu", "-i", "-p", "vlt"},
+	{"wofi", "--dmenu", "--insensitive", "--prompt", "vlt"},
+	{"fuzzel", "--dmenu", "--prompt", "vlt: "},
+	{"dmenu", "-i", "-p", "vlt"},
+}
+
+type DmenuError struct {
+	Err error
+}
+
+func (e *DmenuError) Error() string { return "dmenu: " + e.Err.Error() }
+
+func (e *DmenuError) Unwrap() error { return e.Err }
+
+// DmenuOptions holds data required to run the command.
+type DmenuOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config *ResolvedConfig
+	search *SearchableOptions
+	action string
+	url    string // url lists the logins matching the page URL, best match first.
+}
+
+var _ genericclioptions.CmdOptions = &DmenuOptions{}
+
+// NewDmenuOptions initializes the options struct.
+func NewDmenuOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *DmenuOptions {
+	return &DmenuOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+		search:       NewSearchableOptions(),
+	}
+}
+
+func (o *DmenuOptions) Complete() error {
+	if len(o.action) == 0 {
+		o.action = o.config.DmenuAction
+	}
+
+	return nil
+}
+
+func (o *DmenuOptions) Validate() error {
+	if !slices.Contains(dmenuActions, o.action) {
+		return &DmenuError{fmt.Errorf("unsupported action %q (supported: %v)", o.action, dmenuActions)}
+	}
+
+	return nil
+}
+
+func (o *DmenuOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &DmenuError{retErr}
+
+			// run from the desktop, where stderr is not seen.
+			_ = notify.Send(context.WithoutCancel(ctx), "vlt dmenu", retErr.Error())
+		}
+	}()
+
+	sessionClient, err := o.openNoPrompt(ctx, o.StdioOptions)
+	if err != nil {
+		return err
+	}
+	defer func() { //nolint:wsl
+		retErr = errors.Join(retErr, o.closeNoPrompt(ctx, sessionClient))
+	}()
+
+	secrets, err := o.searchAll(ctx, o.StdioOptions, o.search)
+	if err != nil {
+		return err
+	}
+
+	secrets, err = o.rankByFrecency(ctx, o.StdioOptions, secrets, time.Duration(o.config.FrecencyHalfLife))
+	if err != nil {
+		return err
+	}
+
+	if len(o.url) > 0 {
+		page, err := urlmatch.ParsePage(o.url)
+		if err != nil {
+			return err
+		}
+
+		secrets = rankByURL(secrets, page)
+	}
+
+	if len(secrets) == 0 {
+		return errors.New("no secrets found")
+	}
+
+	entries, lines := dmenuEntries(secrets)
+	keybindings := slices.Sorted(maps.Keys(o.config.DmenuKeybindings))
+
+	picker, err := o.picker(keybindings)
+	if err != nil {
+		return err
+	}
+
+	chosen, code, err := o.pick(ctx, picker, lines)
+	if err != nil || len(chosen) == 0 {
+		return err
+	}
+
+	action := o.action
+	if i := code - dmenuCustomExitCode; i >= 0 && i < len(keybindings) {
+		action = o.config.DmenuKeybindings[keybindings[i]]
+	}
+
+	secret, ok := entries[chosen]
+	if !ok {
+		return fmt.Errorf("%q: %w", chosen, vaulterrors.ErrSearchNoMatch)
+	}
+
+	v, err := o.vaultOf(ctx, o.StdioOptions, secret)
+	if err != nil {
+		return err
+	}
+
+	value, err := v.ShowSecret(ctx, secret.id)
+	if err != nil {
+		return err
+	}
+
+	if action == dmenuActionType {
+		return o.typeValue(ctx, value)
+	}
+
+	return clipboard.Copy(value)
+}
+
+// dmenuEntries returns the picker lines of the given secrets, their names
+// followed by their labels, mapped to the secrets.
+// Lines shared by several secrets are suffixed by the secret ids.
+func dmenuEntries(secrets []secretWithLabels) (map[string]secretWithLabels, []string) {
+	line := func(s secretWithLabels) string {
+		if len(s.labels) == 0 {
+			return s.name
+		}
+
+		return s.name + "  [" + strings.Join(s.labels, ",") + "]"
+	}
+
+	counts := make(map[string]int, len(secrets))
+	for _, s := range secrets {
+		counts[line(s)]++
+	}
+
+	entries := make(map[string]secretWithLabels, len(secrets))
+	lines := make([]string, 0, len(secrets))
+
+	for _, s := range secrets {
+		l := line(s)
+		if counts[l] > 1 {
+			l += "  #" + s.displayID()
+		}
+
+		entries[l] = s
+		lines = append(lines, l)
+	}
+
+	return entries, lines
+}
+
+// picker returns the configured picker command, or else the first of
+// [dmenuPickers] found on PATH. The given key bindings are passed to rofi.
+func (o *DmenuOptions) picker(keybindings []string) ([]string, error) {
+	picker := o.config.DmenuPicker
+
+	if len(picker) == 0 {
+		for _, p := range dmenuPickers {
+			if _, err := exec.LookPath(p[0]); err == nil {
+				picker = p
+				break
+			}
+		}
+	}
+
+	if len(picker) == 0 {
+		return nil, errors.New("no picker found, install rofi, wofi, fuzzel or dmenu, or set 'dmenu.picker'")
+	}
+
+	if filepath.Base(picker[0]) != "rofi" {
+		return picker, nil
+	}
+
+	picker = slices.Clone(picker)
+	for i, key := range keybindings {
+		picker = append(picker, fmt.Sprintf("-kb-custom-%d", i+1), key)
+	}
+
+	return picker, nil
+}
+
+// pick runs the picker on the given lines, returning the chosen one
+// along with the picker exit code. Nothing is returned if cancelled.
+func (o *DmenuOptions) pick(ctx context.Context, picker []string, lines []string) (string, int, error) {
+	var stdout bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, picker[0], picker[1:]...) //nolint:gosec // configured by the user.
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n") + "\n")
+	cmd.Stdout = &stdout
+	cmd.Stderr = o.ErrOut
+
+	code := 0
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", 0, fmt.Errorf("picker: %w", err)
+		}
+
+		code = exitErr.ExitCode()
+		if code < dmenuCustomExitCode {
+			o.Debugf("vlt: picker exited with code %d, nothing chosen\n", code)
+			return "", code, nil
+		}
+	}
+
+	return strings.TrimRight(stdout.String(), "\n"), code, nil
+}
+
+// typeValue types the value using the configured type command, or else
+// wtype on Wayland and xdotool otherwise.
+func (o *DmenuOptions) typeValue(ctx context.Context, value string) error {
+	typeCmd := o.config.DmenuTypeCmd
+
+	if len(typeCmd) == 0 {
+		typeCmd = []string{"xdotool", "type", "--clearmodifiers", "--file", "-"}
+
+		if len(os.Getenv("WAYLAND_DISPLAY")) > 0 {
+			typeCmd = []string{"wtype", "-"}
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, typeCmd[0], typeCmd[1:]...) //nolint:gosec // configured by the user.
+	cmd.Stdin = strings.NewReader(value)
+	cmd.Stderr = o.ErrOut
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("type: %w", err)
+	}
+
+	return nil
+}
+
+// NewCmdDmenu creates the dmenu cobra command.
+func NewCmdDmenu(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewDmenuOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "dmenu",
+		Short: "Pick a secret using rofi, wofi, fuzzel or dmenu, then copy or type it",
+		Long: `Pick a secret using a desktop picker, then copy its value to the clipboard
+or type it into the focused window, like passmenu.
+
+Intended to be bound to a desktop key, dmenu never prompts: the vault is opened
+using the current session ('vlt login') or the VLT_TOKEN access token. Errors
+are shown as desktop notifications.
+
+The picker, the default action and the typing command are set in the 'dmenu'
+config section. With rofi, 'dmenu.keybindings' maps keys to actions, e.g.,
+choosing a secret using Alt+t types it, using Enter applies the default action.
+
+Secrets are listed most frecent first, as ranked by 'vlt frecency'. With --url,
+only the logins matching the page URL are listed, best match first, as by
+'vlt match', then by frecency.`,
+		Example: `  # Pick a secret and type it
+  vlt dmenu --action type
+
+  # Pick one of the secrets labeled "work"
+  vlt dmenu --label work
+
+  # Pick one of the logins of a page
+  vlt dmenu --url https://github.com/login`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.action, "action", "a", "", "action applied to the chosen secret: copy or type (default: 'dmenu.action', or copy)")
+	cmd.Flags().StringSliceVarP(&o.search.Labels, "label", "", nil, FilterByLabels.Help())
+	cmd.Flags().StringVarP(&o.url, "url", "", "", "list the logins matching the page URL, best match first")
+
+	return cmd
+}
@@ -0,0 +1,407 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/clipboard"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/plugin"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaultdaemon"
+
+	"github.com/spf13/cobra"
+)
+
+// doctorDaemonTimeout bounds the time the session daemon is waited for.
+const doctorDaemonTimeout = 2 * time.Second
+
+type DoctorError struct {
+	Err error
+}
+
+func (e *DoctorError) Error() string { return "doctor: " + e.Err.Error() }
+
+func (e *DoctorError) Unwrap() error { return e.Err }
+
+// DoctorOptions holds data required to run the command.
+type DoctorOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config *ResolvedConfig
+	fix    bool // fix applies the fixes that are safe to apply automatically.
+}
+
+var _ genericclioptions.CmdOptions = &DoctorOptions{}
+
+// NewDoctorOptions initializes the options struct.
+func NewDoctorOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *DoctorOptions {
+	return &DoctorOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+	}
+}
+
+func (*DoctorOptions) Complete() error { return nil }
+
+func (*DoctorOptions) Validate() error { return nil }
+
+// doctorCheck is a diagnostic run by 'vlt doctor'.
+type doctorCheck struct {
+	name string
+	run  func(ctx context.Context) doctorResult
+}
+
+// doctorResult is the outcome of a [doctorCheck].
+type doctorResult struct {
+	note   string // note details a passed or skipped check.
+	issues []doctorIssue
+}
+
+// doctorIssue is a problem found by a [doctorCheck].
+type doctorIssue struct {
+	problem string
+	hint    string       // hint suggests how to fix the issue manually.
+	fix     func() error // fix corrects the issue, nil if it is not safe to fix automatically.
+}
+
+func (o *DoctorOptions) checks() []doctorCheck {
+	return []doctorCheck{
+		{"vault", o.checkVault},
+		{"schema", o.checkSchema},
+		{"clipboard", o.checkClipboard},
+		{"daemon", o.checkDaemon},
+		{"pinentry", o.checkPinentry},
+		{"terminal", o.checkTerminal},
+		{"hooks", o.checkHooks},
+	}
+}
+
+func (o *DoctorOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &DoctorError{retErr}
+		}
+	}()
+
+	unresolved, fixable := 0, 0
+
+	for _, c := range o.checks() {
+		res := c.run(ctx)
+
+		if len(res.issues) == 0 {
+			o.Infof("%s: %s\n", c.name, cmp.Or(res.note, "ok"))
+			continue
+		}
+
+		for _, issue := range res.issues {
+			if !o.fix || issue.fix == nil {
+				o.Warnf("%s: %s\n", c.name, issue.problem)
+
+				if len(issue.hint) > 0 {
+					o.Warnf("  hint: %s\n", issue.hint)
+				}
+
+				unresolved++
+
+				if issue.fix != nil {
+					fixable++
+				}
+
+				continue
+			}
+
+			if err := issue.fix(); err != nil {
+				return fmt.Errorf("fix %s: %w", issue.problem, err)
+			}
+
+			o.Infof("%s: fixed: %s\n", c.name, issue.problem)
+		}
+	}
+
+	if unresolved > 0 {
+		if fixable > 0 {
+			o.Warnf("\nRun 'vlt doctor --fix' to fix %d of them.\n", fixable)
+		}
+
+		return fmt.Errorf("%d unresolved issues found", unresolved)
+	}
+
+	return nil
+}
+
+// checkVault checks that the vault exists, and that its ownership and
+// permissions are safe. Permissions are fixed by restricting them.
+func (o *DoctorOptions) checkVault(context.Context) doctorResult {
+	exists, err := o.vaultExists()
+	if err != nil {
+		return doctorResult{issues: []doctorIssue{{problem: err.Error()}}}
+	}
+
+	if !exists {
+		return doctorResult{issues: []doctorIssue{{
+			problem: "no vault at " + o.path,
+			hint:    "create one using 'vlt create', or set 'vault.path' in the config",
+		}}}
+	}
+
+	perms, err := checkVaultPermissions(o.path)
+	if err != nil {
+		return doctorResult{issues: []doctorIssue{{problem: err.Error()}}}
+	}
+
+	issues := make([]doctorIssue, 0, len(perms))
+
+	for _, p := range perms {
+		hint := "restrict permissions using 'vlt doctor --fix'"
+		if p.fix == nil {
+			hint = "change the owner to the current user, e.g., using chown"
+		}
+
+		issues = append(issues, doctorIssue{problem: "unsafe permissions: " + p.String(), hint: hint, fix: p.fix})
+	}
+
+	return doctorResult{note: "ok (" + o.path + ")", issues: issues}
+}
+
+// checkSchema checks that the vault was not written by a newer vlt version,
+// reading the schema version of its unencrypted container.
+func (o *DoctorOptions) checkSchema(ctx context.Context) doctorResult {
+	if exists, err := o.vaultExists(); err != nil || !exists {
+		return doctorResult{note: "skipped (no vault)"}
+	}
+
+	version, supported, err := vault.ContainerSchemaVersion(ctx, o.path)
+	if err != nil {
+		return doctorResult{issues: []doctorIssue{{
+			problem: err.Error(),
+			hint:    "the vault file may be corrupted, restore it from a backup using 'vlt restore'",
+		}}}
+	}
+
+	if version > supported {
+		return doctorResult{issues: []doctorIssue{{
+			problem: fmt.Sprintf("vault written by a newer vlt version (container schema version %d, supported %d)", version, supported),
+			hint:    "upgrade vlt",
+		}}}
+	}
+
+	return doctorResult{note: fmt.Sprintf("ok (container schema version %d)", version)}
+}
+
+// checkClipboard checks that the configured clipboard commands,
+// or the clipboard plugin, are installed.
+func (o *DoctorOptions) checkClipboard(context.Context) doctorResult {
+	if name := o.config.ClipboardPlugin; len(name) > 0 {
+		if _, err := exec.LookPath(plugin.Prefix + name); err != nil {
+			return doctorResult{issues: []doctorIssue{{
+				problem: fmt.Sprintf("clipboard plugin %q: %v", name, err),
+				hint:    "install the plugin, or fix 'clipboard.plugin' in the config",
+			}}}
+		}
+
+		return doctorResult{note: "ok (plugin " + name + ")"}
+	}
+
+	if err := clipboard.Check(); err != nil {
+		problem := err.Error()
+
+		if ce := (*clipboard.ConfigurationError)(nil); errors.As(err, &ce) {
+			problem = ce.Op + ": " + ce.Err.Error()
+		}
+
+		return doctorResult{issues: []doctorIssue{{
+			problem: problem,
+			hint:    "install wl-clipboard on Wayland, or xsel or xclip on X11, or set 'clipboard.copy_cmd' and 'clipboard.paste_cmd' in the config",
+		}}}
+	}
+
+	defaultCmds := len(o.config.CopyCmd) == 0
+	if defaultCmds && runtime.GOOS != "windows" && runtime.GOOS != "darwin" && len(os.Getenv("DISPLAY")) == 0 && len(os.Getenv("WAYLAND_DISPLAY")) == 0 {
+		return doctorResult{issues: []doctorIssue{{
+			problem: "no display, the clipboard is unavailable",
+			hint:    "print secrets using 'vlt show --output', or type them using 'vlt tmux' within tmux",
+		}}}
+	}
+
+	return doctorResult{}
+}
+
+// checkDaemon checks that sessions can be kept, using the 'vltd' daemon
+// on Unix systems.
+func (o *DoctorOptions) checkDaemon(ctx context.Context) doctorResult {
+	c, err := vaultdaemon.NewSessionClient()
+	if err != nil {
+		return doctorResult{issues: []doctorIssue{{
+			problem: err.Error(),
+			hint:    "start the 'vltd' daemon, e.g., from the session startup, to stay logged in between commands",
+		}}}
+	}
+	defer func() { _ = c.Close() }() //nolint:wsl
+
+	ctx, cancel := context.WithTimeout(ctx, doctorDaemonTimeout)
+	defer cancel()
+
+	if err := c.Ping(ctx, o.path); err != nil {
+		return doctorResult{issues: []doctorIssue{{
+			problem: "daemon not responding: " + err.Error(),
+			hint:    "restart the 'vltd' daemon",
+		}}}
+	}
+
+	return doctorResult{}
+}
+
+// checkPinentry checks that gpg can prompt for the passphrase of the
+// audit log signing key, if configured.
+func (o *DoctorOptions) checkPinentry(context.Context) doctorResult {
+	if len(o.config.AuditGPGKey) == 0 {
+		return doctorResult{note: "skipped (no 'audit.gpg_key')"}
+	}
+
+	var issues []doctorIssue
+
+	for _, name := range []string{"gpg", "pinentry"} {
+		if _, err := exec.LookPath(name); err != nil {
+			issues = append(issues, doctorIssue{
+				problem: err.Error(),
+				hint:    "install " + name + ", used for signing audit log checkpoints using 'audit.gpg_key'",
+			})
+		}
+	}
+
+	return doctorResult{issues: issues}
+}
+
+// checkTerminal checks that the locale is UTF-8, and that the terminal
+// supports the interactive pickers.
+func (o *DoctorOptions) checkTerminal(context.Context) doctorResult {
+	if runtime.GOOS == "windows" {
+		return doctorResult{note: "skipped (windows)"}
+	}
+
+	var issues []doctorIssue
+
+	locale := cmp.Or(os.Getenv("LC_ALL"), os.Getenv("LC_CTYPE"), os.Getenv("LANG"))
+	if normalized := strings.ReplaceAll(strings.ToLower(locale), "-", ""); !strings.Contains(normalized, "utf8") {
+		issues = append(issues, doctorIssue{
+			problem: fmt.Sprintf("locale %q is not UTF-8, non-ASCII names and labels may be garbled", locale),
+			hint:    "set a UTF-8 locale, e.g., 'export LANG=C.UTF-8'",
+		})
+	}
+
+	if _, ok := terminalFd(o.Out); ok {
+		if term := os.Getenv("TERM"); len(term) == 0 || term == "dumb" {
+			issues = append(issues, doctorIssue{
+				problem: fmt.Sprintf("TERM=%q, colors and interactive pickers are unavailable", term),
+				hint:    "set TERM to the terminal in use, e.g., 'xterm-256color'",
+			})
+		}
+	}
+
+	return doctorResult{issues: issues}
+}
+
+// checkHooks checks that the commands configured as hooks are installed.
+func (o *DoctorOptions) checkHooks(context.Context) doctorResult {
+	cmds := []struct {
+		key string
+		cmd []string
+	}{
+		{"hooks.post_login_cmd", o.config.PostLoginCmd},
+		{"hooks.post_write_cmd", o.config.PostWriteCmd},
+		{"hooks.post_monitor_cmd", o.config.PostMonitorCmd},
+		{"hooks.post_backup_cmd", o.config.PostBackupCmd},
+		{"pipeline.find_pipe_cmd", o.config.FindPipeCmd},
+		{"dmenu.picker", o.config.DmenuPicker},
+		{"dmenu.type_cmd", o.config.DmenuTypeCmd},
+		{"open.browser_cmd", o.config.OpenBrowserCmd},
+		{"rotation.reminder_cmd", o.config.RotationReminderCmd},
+	}
+
+	for _, glob := range slices.Sorted(maps.Keys(o.config.RotationHooks)) {
+		cmds = append(cmds, struct {
+			key string
+			cmd []string
+		}{fmt.Sprintf("rotation.hooks.%q", glob), o.config.RotationHooks[glob]})
+	}
+
+	var (
+		issues     []doctorIssue
+		configured int
+	)
+
+	for _, c := range cmds {
+		if len(c.cmd) == 0 {
+			continue
+		}
+
+		configured++
+
+		if _, err := exec.LookPath(c.cmd[0]); err != nil {
+			issues = append(issues, doctorIssue{
+				problem: fmt.Sprintf("%s: %v", c.key, err),
+				hint:    fmt.Sprintf("install %s, or fix '%s' in the config", c.cmd[0], c.key),
+			})
+		}
+	}
+
+	if configured == 0 {
+		return doctorResult{note: "skipped (none configured)"}
+	}
+
+	return doctorResult{note: fmt.Sprintf("ok (%d configured)", configured), issues: issues}
+}
+
+// NewCmdDoctor creates the doctor cobra command.
+func NewCmdDoctor(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewDoctorOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the vault and its environment for common problems",
+		Long: `Check the vault and its environment for common problems without unlocking
+the vault, suggesting how to fix each one.
+
+Checks:
+  vault      the vault exists, is owned by the current user with mode 0600,
+             and its directory with mode 0700. A vault in the home directory
+             only requires the home directory not to be writable by others.
+  schema     the vault was not written by a newer vlt version.
+  clipboard  the clipboard commands or plugin are installed, and a display is available.
+  daemon     the 'vltd' session daemon is running and responding.
+  pinentry   gpg and pinentry are installed, if 'audit.gpg_key' is set.
+  terminal   the locale is UTF-8, and TERM supports the interactive pickers.
+  hooks      the commands configured as hooks are installed.
+
+Use --fix to apply the fixes that are safe to apply automatically, currently
+restricting the vault file and directory permissions. Other issues, e.g.,
+ownership, must be fixed manually.`,
+		Example: `  # Report problems
+  vlt doctor
+
+  # Fix the problems that are safe to fix automatically
+  vlt doctor --fix`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.fix, "fix", "", false, "apply the fixes that are safe to apply automatically")
+	cmd.Flags().BoolVarP(&o.fix, "fix-perms", "", false, "restrict the vault file and directory permissions")
+	_ = cmd.Flags().MarkDeprecated("fix-perms", "use --fix instead")
+
+	return cmd
+}
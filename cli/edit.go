@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultEditor is the editor used if neither $VISUAL nor $EDITOR are set.
+const defaultEditor = "vi"
+
+type EditError struct {
+	Err error
+}
+
+func (e *EditError) Error() string { return "edit: " + e.Err.Error() }
+
+func (e *EditError) Unwrap() error { return e.Err }
+
+// EditOptions holds data required to run the command.
+type EditOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	search *SearchableOptions
+}
+
+var _ genericclioptions.CmdOptions = &EditOptions{}
+
+// NewEditOptions initializes the options struct.
+func NewEditOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *EditOptions {
+	return &EditOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		search:       NewSearchableOptions(),
+	}
+}
+
+func (*EditOptions) Complete() error { return nil }
+
+func (o *EditOptions) Validate() error {
+	if o.NonInteractive {
+		return &EditError{errors.New("edit requires an interactive terminal")}
+	}
+
+	if err := o.search.Validate(); err != nil {
+		return &EditError{err}
+	}
+
+	return nil
+}
+
+func (o *EditOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &EditError{retErr}
+			return
+		}
+	}()
+
+	o.search.WildcardFrom(args)
+
+	matchingSecrets, err := o.search.search(ctx, o.vault)
+	if err != nil {
+		return err
+	}
+
+	switch count := len(matchingSecrets); count {
+	case 1:
+	case 0:
+		o.Warnf("No match found.\n")
+		return vaulterrors.ErrSearchNoMatch
+	default:
+		o.Warnf("Expecting exactly one match, but found %d.\n\n", count)
+		printTable(o.ErrOut, matchingSecrets, o.labelColors(ctx, o.ErrOut))
+
+		return vaulterrors.ErrAmbiguousSecretMatch
+	}
+
+	s := matchingSecrets[0]
+
+	value, err := o.vault.ShowSecret(ctx, s.id)
+	if err != nil {
+		return err
+	}
+
+	edited, err := editInEditor(ctx, o.StdioOptions, s.name, value)
+	if err != nil {
+		return err
+	}
+
+	if edited == value {
+		o.Infof("No changes made to %q.\n", s.name)
+		return nil
+	}
+
+	if len(strings.TrimSpace(edited)) == 0 {
+		return vaulterrors.ErrEmptySecret
+	}
+
+	n, err := o.vault.UpdateSecret(ctx, s.id, edited)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNoSecretUpdated
+	}
+
+	o.Infof("Updated %q.\n", s.name)
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		o.Warnf("Post-write hook failed: %v", err)
+	}
+
+	return nil
+}
+
+// editInEditor opens value in $VISUAL or $EDITOR, falling back to vi,
+// and returns the edited value once the editor exits.
+//
+// The value is written to a private temporary directory, preferably under
+// $XDG_RUNTIME_DIR, which is usually memory backed. The file is overwritten
+// before being removed, along with any other file the editor left behind.
+func editInEditor(ctx context.Context, io *genericclioptions.StdioOptions, name string, value string) (_ string, retErr error) {
+	dir, err := os.MkdirTemp(os.Getenv("XDG_RUNTIME_DIR"), "vlt-edit-")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, editFileName(name))
+
+	defer func() { retErr = errors.Join(retErr, shredFile(path), os.RemoveAll(dir)) }()
+
+	if err := os.WriteFile(path, []byte(value), 0o600); err != nil {
+		return "", err
+	}
+
+	editor := strings.Fields(cmp.Or(os.Getenv("VISUAL"), os.Getenv("EDITOR"), defaultEditor))
+
+	cmd := exec.CommandContext(ctx, editor[0], append(editor[1:], path)...) //nolint:gosec // configured by the user.
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = io.In, io.Out, io.ErrOut
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w", editor[0], err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	if len(b) > vault.MaxSecretSize {
+		return "", fmt.Errorf("%w (%d MiB)", vaulterrors.ErrSecretTooLarge, vault.MaxSecretSize>>20)
+	}
+
+	return string(b), nil
+}
+
+// editFileName returns the name of the file a secret is edited in,
+// keeping its name recognizable in the editor.
+func editFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == os.PathSeparator || r < ' ' {
+			return '_'
+		}
+
+		return r
+	}, name)
+
+	return cmp.Or(strings.TrimLeft(name, "."), "secret") + ".txt"
+}
+
+// shredFile overwrites the file at path with zeros, if it exists.
+func shredFile(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, make([]byte, info.Size()), 0o600)
+}
+
+// NewCmdEdit creates the edit cobra command.
+func NewCmdEdit(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewEditOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "edit [glob]",
+		Short: "Edit the value of an existing secret in $EDITOR",
+		Long: fmt.Sprintf(`Open the value of an existing secret in $EDITOR, saving it once the editor exits.
+
+Meant for multi-line secrets, such as notes ('vlt note'). The value is edited
+in a private temporary file, preferably under $XDG_RUNTIME_DIR, that is
+overwritten and removed afterwards.
+
+The edit is performed only if exactly one secret matches the provided criteria.
+Values are limited to %d MiB.`, vault.MaxSecretSize>>20),
+		Example: `  # Edit a note
+  vlt edit recovery-codes
+
+  # Edit the secret with the given id using a specific editor
+  EDITOR="code --wait" vlt edit --id 42`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().IntVarP(&o.search.ID, "id", "", 0, FilterByID.Help())
+	cmd.Flags().StringVarP(&o.search.Name, "name", "", "", FilterByName.Help())
+	cmd.Flags().StringSliceVarP(&o.search.Labels, "label", "", nil, FilterByLabels.Help())
+
+	return cmd
+}
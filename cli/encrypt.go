@@ -0,0 +1,296 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/filecrypt"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+
+	"github.com/spf13/cobra"
+)
+
+type EncryptError struct {
+	Err error
+}
+
+func (e *EncryptError) Error() string { return "encrypt: " + e.Err.Error() }
+
+func (e *EncryptError) Unwrap() error { return e.Err }
+
+type DecryptError struct {
+	Err error
+}
+
+func (e *DecryptError) Error() string { return "decrypt: " + e.Err.Error() }
+
+func (e *DecryptError) Unwrap() error { return e.Err }
+
+// EncryptOptions holds data required to run the command.
+type EncryptOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	key    string // key is the reference of the stored key, the vault key is used if empty.
+	output string // output is the path the encrypted file is written to, or '-' for stdout.
+}
+
+var _ genericclioptions.CmdOptions = &EncryptOptions{}
+
+// NewEncryptOptions initializes the options struct.
+func NewEncryptOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *EncryptOptions {
+	return &EncryptOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*EncryptOptions) Complete() error { return nil }
+
+func (*EncryptOptions) Validate() error { return nil }
+
+func (o *EncryptOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &EncryptError{retErr}
+			return
+		}
+	}()
+
+	path := args[0]
+
+	output := o.output
+	if len(output) == 0 && path != "-" {
+		output = path + filecrypt.Ext
+	}
+
+	if _, ok := terminalFd(o.Out); ok && (len(output) == 0 || output == "-") {
+		return errors.New("refusing to write encrypted data to a terminal, redirect stdout or use --output")
+	}
+
+	keyType, key, err := o.fileKey(ctx, o.StdioOptions, o.key)
+	if err != nil {
+		return err
+	}
+
+	r, closeInput, err := openInput(o.StdioOptions, path)
+	if err != nil {
+		return err
+	}
+	defer closeInput() //nolint:wsl
+
+	return writeOutput(o.StdioOptions, output, len(o.output) > 0, func(w io.Writer) error {
+		return filecrypt.Encrypt(w, r, keyType, key)
+	})
+}
+
+// DecryptOptions holds data required to run the command.
+type DecryptOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	key    string // key is the reference of the stored key the file was encrypted with.
+	output string // output is the path the decrypted file is written to, or '-' for stdout.
+}
+
+var _ genericclioptions.CmdOptions = &DecryptOptions{}
+
+// NewDecryptOptions initializes the options struct.
+func NewDecryptOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *DecryptOptions {
+	return &DecryptOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*DecryptOptions) Complete() error { return nil }
+
+func (*DecryptOptions) Validate() error { return nil }
+
+func (o *DecryptOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &DecryptError{retErr}
+			return
+		}
+	}()
+
+	path := args[0]
+
+	output := o.output
+	if len(output) == 0 && path != "-" {
+		if !strings.HasSuffix(path, filecrypt.Ext) || len(path) == len(filecrypt.Ext) {
+			return fmt.Errorf("%s: no %s extension to strip, use --output", path, filecrypt.Ext)
+		}
+
+		output = strings.TrimSuffix(path, filecrypt.Ext)
+	}
+
+	r, closeInput, err := openInput(o.StdioOptions, path)
+	if err != nil {
+		return err
+	}
+	defer closeInput() //nolint:wsl
+
+	h, err := filecrypt.ReadHeader(r)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case h.KeyType == filecrypt.KeyStored && len(o.key) == 0:
+		return errors.New("file was encrypted using a stored key, use --key")
+	case h.KeyType == filecrypt.KeyVault && len(o.key) > 0:
+		return errors.New("file was encrypted using the vault key, not a stored key")
+	}
+
+	_, key, err := o.fileKey(ctx, o.StdioOptions, o.key)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(o.StdioOptions, output, len(o.output) > 0, func(w io.Writer) error {
+		return h.Decrypt(w, r, key)
+	})
+}
+
+// fileKey returns the key material of standalone encrypted files: the value
+// referenced by ref, as looked up by [VaultOptions.lookup], or else the vault file key.
+func (o *VaultOptions) fileKey(ctx context.Context, io *genericclioptions.StdioOptions, ref string) (filecrypt.KeyType, []byte, error) {
+	if len(ref) == 0 {
+		key, err := o.vault.FileKey(ctx)
+		return filecrypt.KeyVault, key, err
+	}
+
+	value, err := o.lookupValue(ctx, io, ref)
+	if err != nil {
+		return 0, nil, fmt.Errorf("key: %w", err)
+	}
+
+	return filecrypt.KeyStored, []byte(value), nil
+}
+
+// openInput opens the file at path for reading, or stdin if path is '-'.
+func openInput(stdio *genericclioptions.StdioOptions, path string) (_ io.Reader, closeFunc func(), _ error) {
+	if path == "-" {
+		return stdio.In, func() {}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, func() { _ = f.Close() }, nil
+}
+
+// writeOutput calls write with the file at path, created readable by the
+// current user only, or with stdout if path is empty or '-'.
+//
+// Unless overwrite is set, an existing file is never overwritten.
+// The file is removed if write fails.
+func writeOutput(stdio *genericclioptions.StdioOptions, path string, overwrite bool, write func(w io.Writer) error) error {
+	if len(path) == 0 || path == "-" {
+		return write(stdio.Out)
+	}
+
+	flag := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(path, flag, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if err := errors.Join(write(f), f.Close()); err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+
+	stdio.Infof("Wrote %s\n", path)
+
+	return nil
+}
+
+// NewCmdEncrypt creates the encrypt cobra command.
+func NewCmdEncrypt(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewEncryptOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "encrypt FILE",
+		Short: "Encrypt a standalone file using the vault key",
+		Long: fmt.Sprintf(`Encrypt FILE, without adding it to the vault, into FILE%s.
+
+The file is encrypted using a key derived from the vault key, and can only
+be decrypted using the same vault, also once its master password is rotated
+using 'vlt rotate-master'. Alternatively, --key names a stored
+secret, or a field of one, whose value the key is derived from, so that the
+file can be decrypted using any vault holding it.
+
+FILE '-' reads the file from stdin, written to stdout unless --output is set.
+Files are streamed in chunks, using bounded memory however large, so that
+archives can be piped through. Existing files are only overwritten if given
+using --output.`, filecrypt.Ext),
+		Example: `  # Encrypt a file using the vault key into report.pdf.vlt
+  vlt encrypt report.pdf
+
+  # Encrypt an archive streamed through a pipe
+  tar c dir | vlt encrypt - > backup.vlt
+
+  # Encrypt a file using a stored key, shared with another vault
+  tar -c photos | vlt encrypt - --key shared/archive-key -o photos.tar.vlt`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.key, "key", "k", "", "the name of the secret holding the key (default: the vault key)")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", fmt.Sprintf("write the encrypted file to the given path, or '-' for stdout (default: FILE%s)", filecrypt.Ext))
+
+	return cmd
+}
+
+// NewCmdDecrypt creates the decrypt cobra command.
+func NewCmdDecrypt(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewDecryptOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "decrypt FILE",
+		Short: "Decrypt a file encrypted using 'vlt encrypt'",
+		Long: fmt.Sprintf(`Decrypt FILE, encrypted using 'vlt encrypt', into FILE without
+its %s extension.
+
+Files encrypted using a stored key require the same --key. The file is
+authenticated as it is decrypted, and the output is removed if it was
+encrypted using another key, or was modified.
+
+FILE '-' reads the file from stdin, written to stdout unless --output is set.
+Files are streamed in chunks, using bounded memory however large. Existing
+files are only overwritten if given using --output.`, filecrypt.Ext),
+		Example: `  # Decrypt report.pdf.vlt into report.pdf
+  vlt decrypt report.pdf.vlt
+
+  # Decrypt an archive streamed through a pipe
+  vlt decrypt - < backup.vlt | tar x
+
+  # Decrypt a file encrypted using a stored key to stdout
+  vlt decrypt photos.tar.vlt --key shared/archive-key -o - | tar -x`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.key, "key", "k", "", "the name of the secret holding the key the file was encrypted with")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", fmt.Sprintf("write the decrypted file to the given path, or '-' for stdout (default: FILE without %s)", filecrypt.Ext))
+
+	return cmd
+}
@@ -1,15 +1,30 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"maps"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
+	"github.com/ladzaretti/vlt-cli/archive"
 	"github.com/ladzaretti/vlt-cli/clierror"
 	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/gpg"
+	"github.com/ladzaretti/vlt-cli/input"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
 
 	"github.com/spf13/cobra"
 )
@@ -17,6 +32,13 @@ import (
 // vltExportHeader is the CSV header for exported vlt data.
 const vltExportHeader = "name,secret,labels"
 
+// Export formats, selected using --format.
+const (
+	exportFormatCSV  = "csv"
+	exportFormatJSON = "json"
+	exportFormatVLTX = "vltx" // exportFormatVLTX is the password encrypted archive format, see [archive].
+)
+
 type ExportError struct {
 	Err error
 }
@@ -29,8 +51,13 @@ type ExportOptions struct {
 	*genericclioptions.StdioOptions
 	*VaultOptions
 
-	output string
-	stdout bool
+	output         string
+	stdout         bool
+	format         string
+	attachments    string // attachments is the directory attachments are exported to, if any.
+	fieldsArg      string // fieldsArg is the comma separated fields given using --fields.
+	fields         []string
+	includeSecrets bool // includeSecrets allows exporting secret values.
 }
 
 var _ genericclioptions.CmdOptions = &ExportOptions{}
@@ -43,13 +70,63 @@ func NewExportOptions(stdio *genericclioptions.StdioOptions, vaultOptions *Vault
 	}
 }
 
-func (*ExportOptions) Complete() error { return nil }
+func (o *ExportOptions) Complete() error {
+	if o.format == exportFormatVLTX {
+		return nil
+	}
+
+	fields := vltExportHeader
+	if len(o.fieldsArg) > 0 {
+		fields = o.fieldsArg
+	}
+
+	parsed, err := parseExportFields(fields)
+	if err != nil {
+		return &ExportError{err}
+	}
+
+	o.fields = parsed
+
+	return nil
+}
 
 func (o *ExportOptions) Validate() error {
+	switch o.format {
+	case exportFormatCSV, exportFormatJSON:
+	case exportFormatVLTX:
+		return o.validateArchive()
+	default:
+		return &ExportError{fmt.Errorf("unknown format %q (formats: %s, %s, %s)", o.format, exportFormatCSV, exportFormatJSON, exportFormatVLTX)}
+	}
+
 	if len(o.output) == 0 && !o.stdout {
 		return &ExportError{errors.New("either specify an --output path or use --stdout")}
 	}
 
+	if slices.Contains(o.fields, exportFieldSecret) && !o.includeSecrets {
+		return &ExportError{errors.New("secret values are exported only using --include-secrets, or select other --fields")}
+	}
+
+	return nil
+}
+
+func (o *ExportOptions) validateArchive() error {
+	if len(o.output) == 0 || o.stdout {
+		return &ExportError{fmt.Errorf("%s archives are written to an --output path only", exportFormatVLTX)}
+	}
+
+	if len(o.attachments) > 0 {
+		return &ExportError{fmt.Errorf("attachments are not part of %s archives", exportFormatVLTX)}
+	}
+
+	if len(o.fieldsArg) > 0 {
+		return &ExportError{fmt.Errorf("%s archives hold all fields, --fields is not supported", exportFormatVLTX)}
+	}
+
+	if o.NonInteractive {
+		return &ExportError{vaulterrors.ErrNonInteractiveUnsupported}
+	}
+
 	return nil
 }
 
@@ -61,46 +138,175 @@ func (o *ExportOptions) Run(ctx context.Context, _ ...string) (retErr error) {
 		}
 	}()
 
-	var out io.Writer
+	if o.format == exportFormatVLTX {
+		return o.exportArchive(ctx)
+	}
+
+	out, closeOut, err := o.exportOutput(o.StdioOptions, o.output, o.stdout)
+	if out == nil {
+		return err
+	}
+	defer closeOut()
+
+	var w secretWriter = newJSONSecretWriter(out, o.fields)
+
+	if o.format == exportFormatCSV {
+		if w, err = newCSVSecretWriter(out, o.fields); err != nil {
+			return err
+		}
+	}
+
+	withMeta := slices.ContainsFunc(o.fields, func(f string) bool { return slices.Contains(exportMetaFields, f) })
 
-	if len(o.output) > 0 {
-		f, err := os.Create(o.output)
+	// secrets are written as they are decrypted, keeping memory flat regardless of the vault size.
+	var secrets, attachments int
+
+	for secret, err := range o.vault.StreamSecrets(ctx) {
 		if err != nil {
 			return err
 		}
-		defer func() { //nolint:wsl
-			_ = f.Close()
-		}()
 
-		out = f
+		var meta vaultdb.SecretMeta
+
+		if withMeta {
+			metas, err := o.vault.SecretMetas(ctx, secret.ID)
+			if err != nil {
+				return err
+			}
+
+			meta = metas[secret.ID]
+		}
+
+		values := make([]any, len(o.fields))
+		for i, f := range o.fields {
+			values[i] = exportValue(f, secret, meta)
+		}
+
+		if err := w.Write(values); err != nil {
+			return err
+		}
+
+		secrets++
+
+		if len(o.attachments) > 0 {
+			n, err := o.exportAttachments(ctx, secret.ID)
+			if err != nil {
+				return fmt.Errorf("secret %q: %w", secret.Name, err)
+			}
+
+			attachments += n
+		}
 	}
 
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	// nothing is printed alongside secrets exported to stdout.
 	if o.stdout {
-		out = o.Out
+		return nil
+	}
+
+	o.Infof("Exported %d secrets to %s.\n", secrets, o.output)
+
+	if len(o.attachments) > 0 {
+		o.Infof("Exported %d attachments to %s.\n", attachments, o.attachments)
 	}
 
-	w := csv.NewWriter(out)
-	defer w.Flush()
+	return nil
+}
 
+// exportArchive writes all secrets, along with their labels and metadata,
+// to a vltx archive sealed using a password prompted for.
+func (o *ExportOptions) exportArchive(ctx context.Context) error {
 	secrets, err := o.vault.ExportSecrets(ctx)
 	if err != nil {
 		return err
 	}
 
-	if err := w.Write(strings.Split(vltExportHeader, ",")); err != nil {
-		return err
-	}
+	ids := slices.Sorted(maps.Keys(secrets))
 
-	for _, secret := range secrets {
-		labels := strings.Join(secret.Labels, ",")
-		if err := w.Write([]string{secret.Name, secret.Value, labels}); err != nil {
+	metas := map[int]vaultdb.SecretMeta{}
+	if len(ids) > 0 {
+		if metas, err = o.vault.SecretMetas(ctx, ids...); err != nil {
 			return err
 		}
 	}
 
+	a := archive.Archive{CreatedAt: time.Now().UTC(), Secrets: make([]archive.Secret, 0, len(ids))}
+
+	for _, id := range ids {
+		s, m := secrets[id], metas[id]
+
+		a.Secrets = append(a.Secrets, archive.Secret{
+			Name:      s.Name,
+			Value:     s.Value,
+			Labels:    s.Labels,
+			URL:       m.URL,
+			Username:  m.Username,
+			Notes:     m.Notes,
+			Kind:      m.Kind,
+			CreatedAt: m.CreatedAt,
+			UpdatedAt: m.UpdatedAt,
+		})
+	}
+
+	o.Infof("Choose a password for the archive, it is required to import it.\n")
+
+	password, err := input.PromptNewPassword(o.Out, int(o.In.Fd()), masterPasswordMinLen)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(o.output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if err := errors.Join(archive.Write(f, a, []byte(password)), f.Close()); err != nil {
+		_ = os.Remove(o.output)
+		return err
+	}
+
+	o.Infof("Exported %d secrets to %s.\n", len(a.Secrets), o.output)
+
 	return nil
 }
 
+// exportAttachments writes the attachments of the given secret to
+// <attachments dir>/<secret id>/<attachment name>, streaming each one chunk
+// at a time, and returns the number of attachments written.
+//
+// Existing files are never overwritten.
+func (o *ExportOptions) exportAttachments(ctx context.Context, secretID int) (int, error) {
+	attachments, err := o.vault.Attachments(ctx, secretID)
+	if err != nil || len(attachments) == 0 {
+		return 0, err
+	}
+
+	dir := filepath.Join(o.attachments, strconv.Itoa(secretID))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return 0, err
+	}
+
+	for _, a := range attachments {
+		path := filepath.Join(dir, filepath.Base(a.Name))
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = o.vault.WriteAttachment(ctx, secretID, a.Name, f)
+		if err := errors.Join(err, f.Close()); err != nil {
+			_ = os.Remove(path)
+			return 0, err
+		}
+	}
+
+	return len(attachments), nil
+}
+
 // NewCmdExport creates the export cobra command.
 func NewCmdExport(defaults *DefaultVltOptions) *cobra.Command {
 	o := NewExportOptions(
@@ -110,16 +316,438 @@ func NewCmdExport(defaults *DefaultVltOptions) *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "export",
-		Short: "Export secrets to a CSV file or stdout",
-		Long: `Export secrets in CSV format.
+		Short: "Export secrets to a CSV or JSON file, an encrypted archive or stdout",
+		Long: `Export secrets in CSV or JSON format, or to an encrypted archive using --format vltx.
 	
-Use --output to specify a file path or --stdout to print to standard output (unsafe).`,
+Use --output to specify a file path or --stdout to print to standard output (unsafe).
+
+The exported fields are selected using --fields, defaulting to name,secret,labels,
+the CSV layout imported by 'vlt import'. Fields: ` + strings.Join(exportFields, ", ") + `.
+Secret values are exported only using --include-secrets.
+
+Secrets are decrypted and written one at a time, so memory use does not grow
+with the vault size. Attachments are not part of the CSV or JSON, use --attachments
+to write them, decrypted, to <DIR>/<secret id>/<attachment name>.
+
+vltx archives hold all secrets along with their labels and metadata, encrypted
+using a password prompted for, and are imported using 'vlt import'. Unlike the
+vault file, they are independent of the master password, e.g., to move secrets
+to another vault. Attachments are not part of archives.`,
+		Example: `  # Export secrets to a CSV file
+  vlt export --include-secrets --output secrets.csv
+
+  # List the names, labels and URLs of all secrets as JSON, without their values
+  vlt export --format json --fields name,labels,url --stdout
+
+  # Export secrets to an encrypted archive, and import it into another vault
+  vlt export --format vltx --output backup.vltx
+  vlt import --file other.db backup.vltx`,
 		Run: func(cmd *cobra.Command, _ []string) {
 			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
 		},
 	}
 	cmd.Flags().StringVarP(&o.output, "output", "o", "", "export secrets to the specified file path")
 	cmd.Flags().BoolVarP(&o.stdout, "stdout", "", false, "print exported secrets to standard output (unsafe)")
+	cmd.Flags().StringVarP(&o.format, "format", "", exportFormatCSV, fmt.Sprintf("export format (one of: %s, %s, %s)", exportFormatCSV, exportFormatJSON, exportFormatVLTX))
+	cmd.Flags().StringVarP(&o.fieldsArg, "fields", "", "", fmt.Sprintf("comma separated fields to export (default %q)", vltExportHeader))
+	cmd.Flags().BoolVarP(&o.includeSecrets, "include-secrets", "", false, "export secret values, required by the secret field")
+	cmd.Flags().StringVarP(&o.attachments, "attachments", "", "", "also export attachments to the specified directory")
+
+	cmd.AddCommand(NewCmdExportPass(defaults))
+	cmd.AddCommand(NewCmdExportTemplate(defaults))
+
+	return cmd
+}
+
+// exportOutput returns the writer secrets are exported to: the file at path,
+// or stdout, if confirmed. A nil writer is returned if the export is not
+// confirmed, along with the confirmation error, if any.
+func (o *VaultOptions) exportOutput(stdio *genericclioptions.StdioOptions, path string, stdout bool) (io.Writer, func(), error) {
+	if stdout {
+		if ok, err := o.confirmPrint(stdio, "secrets export"); !ok {
+			return nil, nil, err
+		}
+
+		return stdio.Out, func() {}, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, func() { _ = f.Close() }, nil
+}
+
+// passGPGIDFile is the file listing the keys a password store is encrypted to.
+const passGPGIDFile = ".gpg-id"
+
+type ExportPassOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	gpgIDs []string
+	force  bool
+}
+
+var _ genericclioptions.CmdOptions = &ExportPassOptions{}
+
+// NewExportPassOptions initializes the options struct.
+func NewExportPassOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *ExportPassOptions {
+	return &ExportPassOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*ExportPassOptions) Complete() error { return nil }
+
+func (o *ExportPassOptions) Validate() error {
+	if len(o.gpgIDs) == 0 {
+		return &ExportError{errors.New("at least one --gpg-id is required")}
+	}
+
+	return nil
+}
+
+func (o *ExportPassOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &ExportError{retErr}
+			return
+		}
+	}()
+
+	dir, err := passStoreDir(args)
+	if err != nil {
+		return err
+	}
+
+	secrets, err := o.vault.ExportSecrets(ctx)
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string]string) // store relative path to secret value
+	ids := slices.Sorted(maps.Keys(secrets))
+
+	for _, id := range ids {
+		s := secrets[id]
+
+		labels := s.Labels
+		if len(labels) == 0 {
+			labels = []string{""}
+		}
+
+		for _, label := range labels {
+			path, ok := passPath(label, s.Name, id, files)
+			if !ok {
+				o.Warnf("Skipping secret %d under label %q: %q is not a valid path.\n", id, label, s.Name)
+				continue
+			}
+
+			files[path] = s.Value
+		}
+	}
+
+	gpgID := []byte(strings.Join(o.gpgIDs, "\n") + "\n")
+
+	if err := o.checkOverwrite(dir, gpgID, files); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, passGPGIDFile), gpgID, 0o600); err != nil {
+		return err
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		value := files[path]
+		if !strings.HasSuffix(value, "\n") {
+			value += "\n"
+		}
+
+		encrypted, err := gpg.Encrypt(ctx, o.gpgIDs, []byte(value))
+		if err != nil {
+			return err
+		}
+
+		full := filepath.Join(dir, path)
+
+		if err := os.MkdirAll(filepath.Dir(full), 0o700); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(full, encrypted, 0o600); err != nil {
+			return err
+		}
+	}
+
+	o.Infof("Exported %d secrets to %s (%d files).\n", len(secrets), dir, len(files))
+
+	return nil
+}
+
+// checkOverwrite fails if writing the store would overwrite existing files,
+// unless --force is set. A matching [passGPGIDFile] is left as is.
+func (o *ExportPassOptions) checkOverwrite(dir string, gpgID []byte, files map[string]string) error {
+	if o.force {
+		return nil
+	}
+
+	existing, err := os.ReadFile(filepath.Join(dir, passGPGIDFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err == nil && !bytes.Equal(existing, gpgID) {
+		return fmt.Errorf("%s is initialized for other keys, use --force to overwrite", dir)
+	}
+
+	for path := range files {
+		if _, err := os.Stat(filepath.Join(dir, path)); err == nil {
+			return fmt.Errorf("refusing to overwrite %s, use --force to overwrite", filepath.Join(dir, path))
+		}
+	}
+
+	return nil
+}
+
+// passStoreDir returns the given password store directory, defaulting to
+// $PASSWORD_STORE_DIR, then to ~/.password-store, like pass(1).
+func passStoreDir(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	if dir := os.Getenv("PASSWORD_STORE_DIR"); len(dir) > 0 {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".password-store"), nil
+}
+
+// passPath returns the store relative path of the secret with the given name
+// under the directory of the given label, suffixing the name by the secret id
+// if taken. URL labels are mapped to their host names, as expected by browserpass.
+func passPath(label string, name string, id int, taken map[string]string) (string, bool) {
+	if u, err := url.Parse(label); err == nil && len(u.Host) > 0 {
+		label = u.Hostname()
+	}
+
+	if !filepath.IsLocal(name) || len(label) > 0 && !filepath.IsLocal(label) {
+		return "", false
+	}
+
+	path := filepath.Join(label, name) + ".gpg"
+	if _, ok := taken[path]; ok {
+		path = filepath.Join(label, name+"-"+strconv.Itoa(id)) + ".gpg"
+	}
+
+	return path, true
+}
+
+// NewCmdExportPass creates the export pass cobra command.
+func NewCmdExportPass(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewExportPassOptions(
+		defaults.StdioOptions,
+		defaults.vaultOptions,
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pass [DIR]",
+		Short: "Export secrets to a pass password store",
+		Long: `Export secrets to a password store compatible with pass(1), so tools such as
+browserpass and passmenu keep working during or after migration.
+
+Each secret is written to <label>/<name>.gpg, encrypted to the --gpg-id keys
+using gpg, once for every label it has; unlabeled secrets are written to the
+store root. URL labels are written as their host names.
+
+DIR defaults to $PASSWORD_STORE_DIR, then to ~/.password-store.
+Existing files are not overwritten unless --force is set.`,
+		Example: `  # Export to the default password store
+  vlt export pass --gpg-id me@example.com`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&o.gpgIDs, "gpg-id", "", nil, "gpg key to encrypt the store to (repeatable)")
+	cmd.Flags().BoolVarP(&o.force, "force", "", false, "overwrite existing files in the store")
+
+	return cmd
+}
+
+// templateSecret is a secret exported using 'vlt export template'.
+type templateSecret struct {
+	ID     int
+	Name   string
+	Secret string
+	Labels []string
+}
+
+// templateFuncs are the functions available to export templates,
+// in addition to the text/template builtins.
+var templateFuncs = template.FuncMap{
+	"join": func(sep string, elems []string) string { return strings.Join(elems, sep) },
+	"csv": func(fields ...string) (string, error) {
+		var sb strings.Builder
+
+		w := csv.NewWriter(&sb)
+		if err := w.Write(fields); err != nil {
+			return "", err
+		}
+
+		w.Flush()
+
+		return strings.TrimSuffix(sb.String(), "\n"), w.Error()
+	},
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+type ExportTemplateOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	search *SearchableOptions
+	in     string // in is the path of the template file.
+	output string
+	stdout bool
+}
+
+var _ genericclioptions.CmdOptions = &ExportTemplateOptions{}
+
+// NewExportTemplateOptions initializes the options struct.
+func NewExportTemplateOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *ExportTemplateOptions {
+	return &ExportTemplateOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		search:       NewSearchableOptions(),
+	}
+}
+
+func (*ExportTemplateOptions) Complete() error { return nil }
+
+func (o *ExportTemplateOptions) Validate() error {
+	if len(o.in) == 0 {
+		return &ExportError{errors.New("no template provided; specify the template file using --in")}
+	}
+
+	if len(o.output) == 0 && !o.stdout {
+		return &ExportError{errors.New("either specify an --output path or use --stdout")}
+	}
+
+	return nil
+}
+
+func (o *ExportTemplateOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &ExportError{retErr}
+			return
+		}
+	}()
+
+	tmpl, err := template.New(filepath.Base(o.in)).Funcs(templateFuncs).ParseFiles(o.in)
+	if err != nil {
+		return err
+	}
+
+	o.search.WildcardFrom(args)
+
+	matching, err := o.search.search(ctx, o.vault)
+	if err != nil {
+		return err
+	}
+
+	secrets, err := o.vault.ExportSecrets(ctx)
+	if err != nil {
+		return err
+	}
+
+	data := make([]templateSecret, 0, len(matching))
+
+	for _, m := range matching {
+		if s, ok := secrets[m.id]; ok {
+			data = append(data, templateSecret{ID: m.id, Name: s.Name, Secret: s.Value, Labels: s.Labels})
+		}
+	}
+
+	// rendered fully first, so that no partial output is written on failure.
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	out, closeOut, err := o.exportOutput(o.StdioOptions, o.output, o.stdout)
+	if out == nil {
+		return err
+	}
+	defer closeOut()
+
+	_, err = buf.WriteTo(out)
+
+	return err
+}
+
+// NewCmdExportTemplate creates the export template cobra command.
+func NewCmdExportTemplate(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewExportTemplateOptions(
+		defaults.StdioOptions,
+		defaults.vaultOptions,
+	)
+
+	cmd := &cobra.Command{
+		Use:   "template [glob] --in FILE",
+		Short: "Export secrets rendered through a Go template",
+		Long: `Export secrets rendered through the Go template (see text/template) read from --in,
+e.g., to an HTML credential inventory, or to a CSV file shaped for another importer.
+
+The template is executed once, with the list of the exported secrets, each having
+the ID, Name, Secret and Labels fields. All secrets are exported, unless filtered
+by a glob pattern matched against secret names or labels, or using --id, --name
+or --label. In addition to the builtin functions, templates may use:
+
+  join SEP LIST   the elements of LIST joined by SEP, e.g., {{join "," .Labels}}
+  csv FIELD...    the fields as a CSV record, quoted as needed
+  json VALUE      VALUE encoded as JSON
+
+Use --output to specify a file path or --stdout to print to standard output (unsafe).`,
+		Example: `  # Export the work secrets as a CSV file, shaped for another importer
+  cat > import.tmpl <<'EOF'
+  url,username,password
+  {{range .}}{{csv (join " " .Labels) .Name .Secret}}
+  {{end}}
+  EOF
+  vlt export template --in import.tmpl --label 'work/*' --output work.csv
+
+  # Export an HTML inventory of the secret names and labels
+  vlt export template --in inventory.html.tmpl --output inventory.html`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.in, "in", "", "", "path to the template file")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", "export secrets to the specified file path")
+	cmd.Flags().BoolVarP(&o.stdout, "stdout", "", false, "print exported secrets to standard output (unsafe)")
+	cmd.Flags().IntVarP(&o.search.ID, "id", "", 0, FilterByID.Help())
+	cmd.Flags().StringVarP(&o.search.Name, "name", "", "", FilterByName.Help())
+	cmd.Flags().StringSliceVarP(&o.search.Labels, "label", "", nil, FilterByLabels.Help())
 
 	return cmd
 }
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+// exportFieldSecret is the field of secret values, exported only using --include-secrets.
+const exportFieldSecret = "secret"
+
+// exportFields are the fields selectable using --fields.
+var exportFields = []string{"id", "name", exportFieldSecret, "labels", "url", "username", "notes", "kind", "created_at", "updated_at"}
+
+// exportMetaFields are the fields read from the secret metadata, see [vaultdb.SecretMeta].
+var exportMetaFields = []string{"url", "username", "notes", "kind", "created_at", "updated_at"}
+
+// parseExportFields parses the comma separated fields selected using --fields.
+func parseExportFields(s string) ([]string, error) {
+	fields := strings.Split(s, ",")
+
+	for i, f := range fields {
+		f = strings.ToLower(strings.TrimSpace(f))
+
+		if !slices.Contains(exportFields, f) {
+			return nil, fmt.Errorf("unknown field %q (fields: %s)", f, strings.Join(exportFields, ", "))
+		}
+
+		if slices.Contains(fields[:i], f) {
+			return nil, fmt.Errorf("field %q is selected more than once", f)
+		}
+
+		fields[i] = f
+	}
+
+	return fields, nil
+}
+
+// exportValue returns the value of the given field of an exported secret:
+// an int, a string, a []string, or a [time.Time].
+func exportValue(field string, s vault.ExportedSecret, m vaultdb.SecretMeta) any {
+	switch field {
+	case "id":
+		return s.ID
+	case "name":
+		return s.Name
+	case exportFieldSecret:
+		return s.Value
+	case "labels":
+		return s.Labels
+	case "url":
+		return m.URL
+	case "username":
+		return m.Username
+	case "notes":
+		return m.Notes
+	case "kind":
+		return m.Kind
+	case "created_at":
+		return m.CreatedAt
+	case "updated_at":
+		return m.UpdatedAt
+	default:
+		return nil
+	}
+}
+
+// secretWriter writes exported secrets, one record at a time.
+type secretWriter interface {
+	// Write writes the record of a secret, holding the values of the selected fields.
+	Write(values []any) error
+
+	// Close writes any buffered or trailing data, without closing the underlying writer.
+	Close() error
+}
+
+// csvSecretWriter writes secrets as CSV rows, labels joined by commas.
+type csvSecretWriter struct {
+	w *csv.Writer
+}
+
+func newCSVSecretWriter(out io.Writer, fields []string) (*csvSecretWriter, error) {
+	w := csv.NewWriter(out)
+	if err := w.Write(fields); err != nil {
+		return nil, err
+	}
+
+	return &csvSecretWriter{w: w}, nil
+}
+
+func (c *csvSecretWriter) Write(values []any) error {
+	row := make([]string, len(values))
+
+	for i, v := range values {
+		switch v := v.(type) {
+		case int:
+			row[i] = strconv.Itoa(v)
+		case string:
+			row[i] = v
+		case []string:
+			row[i] = strings.Join(v, ",")
+		case time.Time:
+			if !v.IsZero() {
+				row[i] = v.UTC().Format(time.RFC3339)
+			}
+		}
+	}
+
+	return c.w.Write(row)
+}
+
+func (c *csvSecretWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonSecretWriter writes secrets as a JSON array of objects, one object at a
+// time, keeping the order of the selected fields. Zero times are written as null.
+type jsonSecretWriter struct {
+	w      *bufio.Writer
+	fields []string
+	n      int // n is the number of objects written.
+}
+
+func newJSONSecretWriter(out io.Writer, fields []string) *jsonSecretWriter {
+	return &jsonSecretWriter{w: bufio.NewWriter(out), fields: fields}
+}
+
+func (j *jsonSecretWriter) Write(values []any) error {
+	sep := ",\n  "
+	if j.n == 0 {
+		sep = "[\n  "
+	}
+
+	_, _ = j.w.WriteString(sep + "{")
+
+	for i, v := range values {
+		if t, ok := v.(time.Time); ok && t.IsZero() {
+			v = nil
+		}
+
+		if s, ok := v.([]string); ok && s == nil {
+			v = []string{}
+		}
+
+		key, err := json.Marshal(j.fields[i])
+		if err != nil {
+			return err
+		}
+
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			_ = j.w.WriteByte(',')
+		}
+
+		_, _ = j.w.Write(key)
+		_ = j.w.WriteByte(':')
+		_, _ = j.w.Write(value)
+	}
+
+	_ = j.w.WriteByte('}')
+
+	j.n++
+
+	return nil
+}
+
+func (j *jsonSecretWriter) Close() error {
+	if j.n == 0 {
+		_, _ = j.w.WriteString("[]\n")
+	} else {
+		_, _ = j.w.WriteString("\n]\n")
+	}
+
+	return j.w.Flush()
+}
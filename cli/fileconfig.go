@@ -5,8 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/auditsink"
+	"github.com/ladzaretti/vlt-cli/policy"
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+	"github.com/ladzaretti/vlt-cli/webhook"
 
 	"github.com/pelletier/go-toml/v2"
 )
@@ -34,6 +44,18 @@ type FileConfig struct {
 	Clipboard *ClipboardConfig `toml:"clipboard,commented" comment:"Clipboard configuration: Both copy and paste commands must be either both set or both unset." json:"clipboard"`
 	Pipeline  *PipelineConfig  `toml:"pipeline,commented" comment:"Pipeline configuration for vault search commands (e.g., 'vlt find')"`
 	Hooks     *HooksConfig     `toml:"hooks,commented" comment:"Optional lifecycle hooks for vault events" json:"hooks"`
+	Audit     *AuditConfig     `toml:"audit,commented" comment:"Audit log configuration" json:"audit"`
+	Policy    *PolicyConfig    `toml:"policy,commented" comment:"Password policy evaluated when storing or generating secrets.\nLabel-scoped overrides are defined as [policy.labels.'<glob>'] tables accepting the same rules." json:"policy"`
+	Backup    *BackupConfig    `toml:"backup,commented" comment:"Backup configuration (see 'vlt backup')" json:"backup"`
+	Providers *ProvidersConfig `toml:"providers,commented" comment:"External secret providers linked secrets are resolved from (see 'vlt link')" json:"providers"`
+	SOPS      *SOPSConfig      `toml:"sops,commented" comment:"SOPS integration (see 'vlt sops')" json:"sops"`
+	Share     *ShareConfig     `toml:"share,commented" comment:"Secret sharing (see 'vlt share')" json:"share"`
+	Webhooks  *WebhooksConfig  `toml:"webhooks,commented" comment:"Webhooks posted metadata-only vault events: 'add', 'update' (name or labels), 'rotate' (value), 'delete' and 'unlock'.\nEndpoints are defined as [webhooks.endpoints.<name>] tables accepting 'url', 'events', 'secret' and 'secret_env'." json:"webhooks"`
+	Dmenu     *DmenuConfig     `toml:"dmenu,commented" comment:"Desktop picker configuration (see 'vlt dmenu')" json:"dmenu"`
+	Open      *OpenConfig      `toml:"open,commented" comment:"Login opening configuration (see 'vlt open')" json:"open"`
+	Frecency  *FrecencyConfig  `toml:"frecency,commented" comment:"Frecency ranking of secrets in 'vlt dmenu' and shell completions (see 'vlt frecency')" json:"frecency"`
+	Mounts    *MountsConfig    `toml:"mounts,commented" comment:"Other vaults mounted under name prefixes, searched by 'vlt find' and 'vlt show' along with the vault, e.g., 'team/db' names the 'db' secret of the 'team' mount.\nMounts are defined as [mounts.vaults.<name>] tables accepting 'path'." json:"mounts"`
+	Rotation  *RotationConfig  `toml:"rotation,commented" comment:"Rotation hooks run by 'vlt rotate', and rotation intervals run by 'vlt scheduler'.\nHooks are defined as [rotation.hooks] entries mapping label globs to commands, e.g., 'prod/db/*' = [\"rotate-pg\"].\nIntervals are defined as [rotation.intervals] entries mapping label globs to durations, e.g., 'prod/*' = '90d'." json:"rotation"`
 
 	path string // path to the loaded config file. Empty if no config file was used.
 }
@@ -43,6 +65,18 @@ func newFileConfig() *FileConfig {
 		Clipboard: &ClipboardConfig{},
 		Pipeline:  &PipelineConfig{},
 		Hooks:     &HooksConfig{},
+		Audit:     &AuditConfig{},
+		Policy:    &PolicyConfig{},
+		Backup:    &BackupConfig{},
+		Providers: &ProvidersConfig{},
+		SOPS:      &SOPSConfig{},
+		Share:     &ShareConfig{},
+		Webhooks:  &WebhooksConfig{},
+		Dmenu:     &DmenuConfig{},
+		Open:      &OpenConfig{},
+		Frecency:  &FrecencyConfig{},
+		Mounts:    &MountsConfig{},
+		Rotation:  &RotationConfig{},
 	}
 }
 
@@ -52,14 +86,20 @@ func newFileConfig() *FileConfig {
 type VaultConfig struct {
 	Path            string `toml:"path,commented" comment:"Vlt database path (default: '~/.vlt' if not set)" json:"path,omitempty"`
 	SessionDuration string `toml:"session_duration,commented" comment:"How long a session lasts before requiring login again (default: '1m')" json:"session_duration,omitempty"`
+	ReadOnly        bool   `toml:"read_only,commented" comment:"Reject all mutating commands, the vault file is never written to (default: false)" json:"read_only,omitempty"`
+	ConfirmOutput   bool   `toml:"confirm_output,commented" comment:"Require a y/N confirmation before printing a plaintext secret to a terminal, piped output is never affected (default: false)" json:"confirm_output,omitempty"`
+	LockTimeout     string `toml:"lock_timeout,commented" comment:"How long commands wait for other vlt commands using the vault to finish, e.g., a running 'vlt import' (default: '30s')" json:"lock_timeout,omitempty"`
+	Keychain        bool   `toml:"keychain,commented" comment:"Cache the vault key in the macOS Keychain on login, later unlocks are allowed in a macOS prompt instead of typing the password, Touch ID is not supported, see 'vlt logout --keychain' (default: false)" json:"keychain,omitempty"`
 }
 
 // ClipboardConfig defines commands for clipboard ops.
 //
 //nolint:tagalign,tagliatelle
 type ClipboardConfig struct {
-	CopyCmd  string `toml:"copy_cmd,commented"  comment:"The command used for copying to the clipboard (default: 'xsel -ib' if not set)" json:"copy_cmd,omitempty"`
-	PasteCmd string `toml:"paste_cmd,commented" comment:"The command used for pasting from the clipboard (default: 'xsel -ob' if not set)" json:"paste_cmd,omitempty"`
+	CopyCmd    string `toml:"copy_cmd,commented"  comment:"The command used for copying to the clipboard (default if not set: detected per platform, e.g., 'wl-copy' on Wayland, 'xsel -ib' or 'xclip -selection clipboard' on X11, 'pbcopy' on macOS).\nClipboard manager hints are passed in the VLT_CLIPBOARD_HINTS environment variable as space-separated 'format=value' pairs." json:"copy_cmd,omitempty"`
+	PasteCmd   string `toml:"paste_cmd,commented" comment:"The command used for pasting from the clipboard (default if not set: detected per platform, e.g., 'wl-paste -n' on Wayland, 'xsel -ob' or 'xclip -selection clipboard -o' on X11, 'pbpaste' on macOS)" json:"paste_cmd,omitempty"`
+	Plugin     string `toml:"plugin,commented" comment:"The clipboard plugin ('vlt-<name>' on PATH) used instead of the copy and paste commands (see 'vlt plugin')" json:"plugin,omitempty"`
+	ClearAfter string `toml:"clear_after,commented" comment:"How long after 'vlt show --copy' the clipboard is cleared, if it still holds the secret, '0' disables clearing (default: '45s')" json:"clear_after,omitempty"`
 }
 
 // Pipeline configuration for vault search commands.
@@ -73,8 +113,200 @@ type PipelineConfig struct {
 //
 //nolint:tagalign,tagliatelle
 type HooksConfig struct {
-	PostLoginCmd []string `toml:"post_login_cmd,commented" comment:"Command to run after a successful login" json:"post_login_cmd"`
-	PostWriteCmd []string `toml:"post_write_cmd,commented" comment:"Command to run after any vault write (e.g., create, update, delete)" json:"post_write_cmd"`
+	PostLoginCmd   []string `toml:"post_login_cmd,commented" comment:"Command to run after a successful login" json:"post_login_cmd"`
+	PostWriteCmd   []string `toml:"post_write_cmd,commented" comment:"Command to run after any vault write (e.g., create, update, delete)" json:"post_write_cmd"`
+	PostMonitorCmd []string `toml:"post_monitor_cmd,commented" comment:"Command to run when 'vlt monitor' finds breached secrets, the findings are written to its stdin (e.g., [\"notify-send\", \"vlt\"])" json:"post_monitor_cmd"`
+	PostBackupCmd  []string `toml:"post_backup_cmd,commented" comment:"Command to run after each backup, e.g., to copy backups to a remote, the backup file path is written to its stdin (e.g., [\"rclone\", \"sync\", \"/path/to/backups\", \"remote:vlt\"])" json:"post_backup_cmd"`
+}
+
+// AuditConfig defines audit log related settings.
+//
+//nolint:tagalign,tagliatelle
+type AuditConfig struct {
+	Sink   string `toml:"sink,commented" comment:"Mirror audit log entries (never secret values) to a host-level sink: 'syslog' or 'journald' (default: disabled)" json:"sink,omitempty"`
+	GPGKey string `toml:"gpg_key,commented" comment:"GPG key used to sign audit log checkpoints, and required of them by 'vlt audit-log verify'" json:"gpg_key,omitempty"`
+}
+
+// PolicyConfig defines the password policy secrets are evaluated against,
+// along with its label-scoped overrides.
+//
+//nolint:tagalign,tagliatelle
+type PolicyConfig struct {
+	PolicyRulesConfig
+
+	// Labels maps label glob patterns to policy overrides,
+	// merged into the stricter policy for matching secrets.
+	Labels map[string]*PolicyRulesConfig `toml:"labels" json:"labels,omitempty"`
+}
+
+// PolicyRulesConfig defines the rules of a password policy.
+//
+//nolint:tagalign,tagliatelle
+type PolicyRulesConfig struct {
+	Mode            string   `toml:"mode,commented" comment:"How policy violations are handled: 'warn' or 'enforce' (default: 'warn')" json:"mode,omitempty"`
+	MinLength       int      `toml:"min_length,commented" comment:"Minimum secret length" json:"min_length,omitempty"`
+	RequiredClasses []string `toml:"required_classes,commented" comment:"Character classes secrets must contain: 'upper', 'lower', 'digit', 'special'" json:"required_classes,omitempty"`
+	BannedWords     []string `toml:"banned_words,commented" comment:"Words secrets must not contain, matched case-insensitively" json:"banned_words,omitempty"`
+	MaxAge          string   `toml:"max_age,commented" comment:"Maximum time since a secret was last changed, reported by 'vlt audit' and 'vlt check' (e.g., '90d')" json:"max_age,omitempty"`
+	Require2FA      bool     `toml:"require_2fa,commented" comment:"Require secrets to be labeled '2fa', marking accounts protected by a second factor" json:"require_2fa,omitempty"`
+}
+
+// BackupConfig defines backup related settings.
+//
+//nolint:tagalign,tagliatelle
+type BackupConfig struct {
+	Dir               string `toml:"dir,commented" comment:"Backup directory, used by 'vlt backup' commands if no directory is given, and by automatic backups" json:"dir,omitempty"`
+	Every             int    `toml:"every,commented" comment:"Back up automatically once N secrets were changed since the latest backup (default: disabled)" json:"every,omitempty"`
+	BeforeDestructive bool   `toml:"before_destructive,commented" comment:"Back up automatically before running 'vlt remove', 'vlt import', 'vlt split', 'vlt rotate', 'vlt sync pull' and 'vlt cloud pull' commands (default: false)" json:"before_destructive,omitempty"`
+	FullEvery         int    `toml:"full_every,commented" comment:"Number of backups per full snapshot, the rest are incremental (default: 7)" json:"full_every,omitempty"`
+	KeepDaily         int    `toml:"keep_daily,commented" comment:"Keep the latest backup of each of the last N days, older backups are pruned after each 'vlt backup' (default: keep all backups)" json:"keep_daily,omitempty"`
+	KeepWeekly        int    `toml:"keep_weekly,commented" comment:"Keep the latest backup of each of the last N weeks" json:"keep_weekly,omitempty"`
+	KeepMonthly       int    `toml:"keep_monthly,commented" comment:"Keep the latest backup of each of the last N months" json:"keep_monthly,omitempty"`
+}
+
+// SOPSConfig defines SOPS related settings.
+//
+//nolint:tagalign,tagliatelle
+type SOPSConfig struct {
+	Key string `toml:"key,commented" comment:"Name of the secret holding the age identity or armored GPG private key SOPS files are decrypted with, unless set using --key" json:"key,omitempty"`
+}
+
+// ShareConfig defines secret sharing related settings.
+//
+//nolint:tagalign,tagliatelle
+type ShareConfig struct {
+	Key string `toml:"key,commented" comment:"Name of the secret holding the age identity shared bundles are decrypted with, unless set using --key" json:"key,omitempty"`
+}
+
+// WebhooksConfig defines the endpoints notified of vault events.
+//
+//nolint:tagalign,tagliatelle
+type WebhooksConfig struct {
+	// Endpoints maps endpoint names to their settings.
+	Endpoints map[string]*WebhookConfig `toml:"endpoints" json:"endpoints,omitempty"`
+}
+
+// WebhookConfig defines an endpoint notified of vault events.
+//
+//nolint:tagalign,tagliatelle
+type WebhookConfig struct {
+	URL       string   `toml:"url" comment:"URL the events are posted to" json:"url,omitempty"`
+	Events    []string `toml:"events,commented" comment:"Events posted to the endpoint (default: all events)" json:"events,omitempty"`
+	Secret    string   `toml:"secret,commented" comment:"Key payloads are signed with using HMAC-SHA256, sent in the X-Vlt-Signature header as 'sha256=<hex>' (default: unsigned)" json:"-"`
+	SecretEnv string   `toml:"secret_env,commented" comment:"Environment variable holding the signing key, instead of 'secret'" json:"secret_env,omitempty"`
+}
+
+// DmenuConfig defines the desktop picker settings.
+//
+//nolint:tagalign,tagliatelle
+type DmenuConfig struct {
+	Picker      []string          `toml:"picker,commented" comment:"Picker command, reading the secret names from stdin and printing the chosen one (default: the first of rofi, wofi, fuzzel and dmenu found on PATH)" json:"picker,omitempty"`
+	Action      string            `toml:"action,commented" comment:"Action applied to the chosen secret: 'copy' or 'type' (default: 'copy')" json:"action,omitempty"`
+	TypeCmd     []string          `toml:"type_cmd,commented" comment:"Command typing the secret value written to its stdin (default: 'wtype -' on Wayland, 'xdotool type --clearmodifiers --file -' otherwise)" json:"type_cmd,omitempty"`
+	Keybindings map[string]string `toml:"keybindings,commented" comment:"rofi key bindings choosing a secret along with an action, e.g., { 'Alt+t' = 'type' }" json:"keybindings,omitempty"`
+}
+
+// OpenConfig defines how logins are opened.
+//
+//nolint:tagalign,tagliatelle
+type OpenConfig struct {
+	BrowserCmd    []string `toml:"browser_cmd,commented" comment:"Command opening the login URL, given as its last argument (default: 'open' on macOS, 'xdg-open' otherwise)" json:"browser_cmd,omitempty"`
+	PasswordDelay string   `toml:"password_delay,commented" comment:"How long after the username the password is copied to the clipboard (default: '10s')" json:"password_delay,omitempty"`
+	WaitForKey    bool     `toml:"wait_for_key,commented" comment:"Copy the password once Enter is pressed, instead of after 'password_delay' (default: false)" json:"wait_for_key,omitempty"`
+}
+
+// FrecencyConfig defines how secrets are ranked by frecency.
+//
+//nolint:tagalign,tagliatelle
+type FrecencyConfig struct {
+	HalfLife string `toml:"half_life,commented" comment:"How long it takes for the weight of an access to halve, e.g., '12h' or '2w' (default: '7d')" json:"half_life,omitempty"`
+}
+
+// MountsConfig defines the vaults mounted under name prefixes.
+//
+//nolint:tagalign,tagliatelle
+type MountsConfig struct {
+	// Vaults maps mount names to the mounted vaults.
+	Vaults map[string]*MountConfig `toml:"vaults" json:"vaults,omitempty"`
+}
+
+// MountConfig defines a mounted vault.
+//
+//nolint:tagalign,tagliatelle
+type MountConfig struct {
+	Path string `toml:"path" comment:"Mounted vault database path" json:"path,omitempty"`
+}
+
+// RotationConfig defines the hooks secrets are rotated with.
+//
+//nolint:tagalign,tagliatelle
+type RotationConfig struct {
+	ReminderCmd []string `toml:"reminder_cmd,commented" comment:"Command to run when 'vlt scheduler' finds secrets due for rotation without a rotation hook, the reminders are written to its stdin (e.g., [\"notify-send\", \"vlt\"])" json:"reminder_cmd,omitempty"`
+
+	// Hooks maps label glob patterns to the commands rotating
+	// the secrets having a matching label.
+	Hooks map[string][]string `toml:"hooks" json:"hooks,omitempty"`
+
+	// Intervals maps label glob patterns to how often the secrets
+	// having a matching label are rotated.
+	Intervals map[string]string `toml:"intervals" json:"intervals,omitempty"`
+}
+
+// ProvidersConfig defines external secret provider settings.
+//
+//nolint:tagalign,tagliatelle
+type ProvidersConfig struct {
+	CacheTTL  string          `toml:"cache_ttl,commented" comment:"How long the resolved value of a linked secret is cached in the vault, unless set using 'vlt link add --ttl' (default: '1h')" json:"cache_ttl,omitempty"`
+	HashiCorp HashiCorpConfig `toml:"hashicorp,commented" comment:"HashiCorp Vault, resolves 'hv://' links. The token is read from VAULT_TOKEN, or the token file written by 'vault login'" json:"hashicorp"`
+	AWS       AWSConfig       `toml:"aws,commented" comment:"AWS Secrets Manager, resolves 'aws://' links and is synced by 'vlt cloud aws'. Credentials are read from the standard AWS environment variables, or the shared credentials file" json:"aws"`
+	GCP       GCPConfig       `toml:"gcp,commented" comment:"Google Cloud Secret Manager, resolves 'gcp://' links and is synced by 'vlt cloud gcp'. The access token is obtained from gcloud, or the metadata server.\nProjects are defined as [providers.gcp.profiles.<name>] tables accepting 'project' and 'locations'." json:"gcp"`
+	Azure     AzureConfig     `toml:"azure,commented" comment:"Azure Key Vault, resolves 'azure://' links and is synced by 'vlt cloud azure'. The access token is obtained from the azure cli, or the managed identity" json:"azure"`
+}
+
+// HashiCorpConfig defines HashiCorp Vault settings.
+//
+//nolint:tagalign,tagliatelle
+type HashiCorpConfig struct {
+	Address   string `toml:"address,commented" comment:"Vault server address (default: VAULT_ADDR)" json:"address,omitempty"`
+	Namespace string `toml:"namespace,commented" comment:"Vault Enterprise namespace (default: VAULT_NAMESPACE)" json:"namespace,omitempty"`
+}
+
+// AWSConfig defines AWS Secrets Manager settings.
+//
+//nolint:tagalign,tagliatelle
+type AWSConfig struct {
+	Region   string `toml:"region,commented" comment:"AWS region (default: AWS_REGION)" json:"region,omitempty"`
+	Profile  string `toml:"profile,commented" comment:"Shared credentials profile (default: AWS_PROFILE, or 'default')" json:"profile,omitempty"`
+	Endpoint string `toml:"endpoint,commented" comment:"Secrets Manager endpoint, e.g., of a VPC endpoint (default: the regional endpoint)" json:"endpoint,omitempty"`
+	Prefix   string `toml:"prefix,commented" comment:"Prefix of the names of secrets synced by 'vlt cloud aws' (default: 'vlt/')" json:"prefix,omitempty"`
+}
+
+// AzureConfig defines Azure Key Vault settings.
+//
+//nolint:tagalign,tagliatelle
+type AzureConfig struct {
+	Vault     string `toml:"vault,commented" comment:"Key vault name, e.g., 'my-vault' for 'https://my-vault.vault.azure.net'" json:"vault,omitempty"`
+	DNSSuffix string `toml:"dns_suffix,commented" comment:"Key vault DNS suffix of sovereign clouds (default: 'vault.azure.net')" json:"dns_suffix,omitempty"`
+	Prefix    string `toml:"prefix,commented" comment:"Prefix of the names of secrets synced by 'vlt cloud azure' (default: 'vlt-')" json:"prefix,omitempty"`
+}
+
+// GCPConfig defines Google Cloud Secret Manager settings.
+//
+//nolint:tagalign,tagliatelle
+type GCPConfig struct {
+	Profile string `toml:"profile,commented" comment:"Profile used by links, and by 'vlt cloud gcp' unless set using --profile (default: 'default', or GOOGLE_CLOUD_PROJECT if not defined)" json:"profile,omitempty"`
+	Prefix  string `toml:"prefix,commented" comment:"Prefix of the ids of secrets synced by 'vlt cloud gcp' (default: 'vlt-')" json:"prefix,omitempty"`
+
+	// Profiles maps profile names to the projects secrets are stored in.
+	Profiles map[string]*GCPProfileConfig `toml:"profiles" json:"profiles,omitempty"`
+}
+
+// GCPProfileConfig defines a Google Cloud project secrets are stored in.
+//
+//nolint:tagalign,tagliatelle
+type GCPProfileConfig struct {
+	Project   string   `toml:"project" comment:"Project id" json:"project,omitempty"`
+	Locations []string `toml:"locations,commented" comment:"Locations created secrets are replicated to (default: automatic replication)" json:"locations,omitempty"`
 }
 
 // LoadFileConfig loads the config from the given or default path.
@@ -90,7 +322,7 @@ func LoadFileConfig(path string) (*FileConfig, error) {
 	if err != nil {
 		// config file not found at default location; fallback to empty config
 		if len(path) == 0 && errors.Is(err, fs.ErrNotExist) { //nolint:revive // clearer with explicit fallback logic
-			c = &FileConfig{}
+			c = newFileConfig()
 		} else {
 			return nil, err
 		}
@@ -138,6 +370,16 @@ func (c *FileConfig) validate() error {
 		return &ConfigError{Opt: "clipboard", Err: errors.New("both 'copy_cmd' and 'paste_cmd' must be set or unset together")}
 	}
 
+	if len(c.Clipboard.Plugin) > 0 && len(c.Clipboard.CopyCmd) > 0 {
+		return &ConfigError{Opt: "clipboard.plugin", Err: errors.New("cannot be used with 'copy_cmd' and 'paste_cmd'")}
+	}
+
+	if len(c.Clipboard.ClearAfter) > 0 {
+		if _, err := cmdutil.ParseDuration(c.Clipboard.ClearAfter); err != nil {
+			return &ConfigError{Opt: "clipboard.clear_after", Err: err}
+		}
+	}
+
 	if c.Pipeline.FindPipeCmd != nil && len(c.Pipeline.FindPipeCmd) == 0 {
 		return &ConfigError{Opt: "pipeline.find_pipe_cmd", Err: errors.New("defined but contains no values")}
 	}
@@ -150,6 +392,268 @@ func (c *FileConfig) validate() error {
 		return &ConfigError{Opt: "hooks.post_write_cmd", Err: errors.New("defined but contains no values")}
 	}
 
+	if c.Hooks.PostMonitorCmd != nil && len(c.Hooks.PostMonitorCmd) == 0 {
+		return &ConfigError{Opt: "hooks.post_monitor_cmd", Err: errors.New("defined but contains no values")}
+	}
+
+	if c.Hooks.PostBackupCmd != nil && len(c.Hooks.PostBackupCmd) == 0 {
+		return &ConfigError{Opt: "hooks.post_backup_cmd", Err: errors.New("defined but contains no values")}
+	}
+
+	if len(c.Audit.Sink) > 0 && !auditsink.IsValidKind(c.Audit.Sink) {
+		return &ConfigError{Opt: "audit.sink", Err: fmt.Errorf("unsupported sink %q (supported: %v)", c.Audit.Sink, auditsink.Kinds)}
+	}
+
+	if err := c.Backup.validate(); err != nil {
+		return err
+	}
+
+	if len(c.Providers.CacheTTL) > 0 {
+		if _, err := cmdutil.ParseDuration(c.Providers.CacheTTL); err != nil {
+			return &ConfigError{Opt: "providers.cache_ttl", Err: err}
+		}
+	}
+
+	if err := c.Providers.GCP.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Webhooks.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Dmenu.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Open.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Frecency.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Mounts.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Rotation.validate(); err != nil {
+		return err
+	}
+
+	return c.Policy.validate()
+}
+
+func (c *BackupConfig) validate() error {
+	for opt, n := range map[string]int{
+		"backup.every":        c.Every,
+		"backup.full_every":   c.FullEvery,
+		"backup.keep_daily":   c.KeepDaily,
+		"backup.keep_weekly":  c.KeepWeekly,
+		"backup.keep_monthly": c.KeepMonthly,
+	} {
+		if n < 0 {
+			return &ConfigError{Opt: opt, Err: fmt.Errorf("must not be negative, got %d", n)}
+		}
+	}
+
+	if (c.Every > 0 || c.BeforeDestructive) && len(c.Dir) == 0 {
+		return &ConfigError{Opt: "backup.dir", Err: errors.New("required for automatic backups")}
+	}
+
+	return nil
+}
+
+func (c *GCPConfig) validate() error {
+	if _, ok := c.Profiles[c.Profile]; len(c.Profile) > 0 && !ok {
+		return &ConfigError{Opt: "providers.gcp.profile", Err: fmt.Errorf("undefined profile %q", c.Profile)}
+	}
+
+	for name, p := range c.Profiles {
+		if p == nil || len(p.Project) == 0 {
+			return &ConfigError{Opt: "providers.gcp.profiles." + name + ".project", Err: errors.New("required")}
+		}
+
+		if slices.Contains(p.Locations, "") {
+			return &ConfigError{Opt: "providers.gcp.profiles." + name + ".locations", Err: errors.New("contains an empty location")}
+		}
+	}
+
+	return nil
+}
+
+func (c *WebhooksConfig) validate() error {
+	for name, w := range c.Endpoints {
+		opt := "webhooks.endpoints." + name
+
+		if w == nil || len(w.URL) == 0 {
+			return &ConfigError{Opt: opt + ".url", Err: errors.New("required")}
+		}
+
+		if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return &ConfigError{Opt: opt + ".url", Err: fmt.Errorf("invalid http url %q", w.URL)}
+		}
+
+		for _, e := range w.Events {
+			if !webhook.IsValidEvent(e) {
+				return &ConfigError{Opt: opt + ".events", Err: fmt.Errorf("unsupported event %q (supported: %v)", e, webhook.Events)}
+			}
+		}
+
+		if len(w.Secret) > 0 && len(w.SecretEnv) > 0 {
+			return &ConfigError{Opt: opt + ".secret_env", Err: errors.New("cannot be used with 'secret'")}
+		}
+	}
+
+	return nil
+}
+
+func (c *DmenuConfig) validate() error {
+	if c.Picker != nil && len(c.Picker) == 0 {
+		return &ConfigError{Opt: "dmenu.picker", Err: errors.New("defined but contains no values")}
+	}
+
+	if c.TypeCmd != nil && len(c.TypeCmd) == 0 {
+		return &ConfigError{Opt: "dmenu.type_cmd", Err: errors.New("defined but contains no values")}
+	}
+
+	if len(c.Action) > 0 && !slices.Contains(dmenuActions, c.Action) {
+		return &ConfigError{Opt: "dmenu.action", Err: fmt.Errorf("unsupported action %q (supported: %v)", c.Action, dmenuActions)}
+	}
+
+	if len(c.Keybindings) > dmenuMaxKeybindings {
+		return &ConfigError{Opt: "dmenu.keybindings", Err: fmt.Errorf("at most %d key bindings are supported", dmenuMaxKeybindings)}
+	}
+
+	for key, action := range c.Keybindings {
+		if !slices.Contains(dmenuActions, action) {
+			return &ConfigError{Opt: "dmenu.keybindings." + key, Err: fmt.Errorf("unsupported action %q (supported: %v)", action, dmenuActions)}
+		}
+	}
+
+	return nil
+}
+
+func (c *OpenConfig) validate() error {
+	if c.BrowserCmd != nil && len(c.BrowserCmd) == 0 {
+		return &ConfigError{Opt: "open.browser_cmd", Err: errors.New("defined but contains no values")}
+	}
+
+	if len(c.PasswordDelay) > 0 {
+		if _, err := cmdutil.ParseDuration(c.PasswordDelay); err != nil {
+			return &ConfigError{Opt: "open.password_delay", Err: err}
+		}
+	}
+
+	return nil
+}
+
+func (c *FrecencyConfig) validate() error {
+	if len(c.HalfLife) == 0 {
+		return nil
+	}
+
+	d, err := cmdutil.ParseDuration(c.HalfLife)
+	if err != nil {
+		return &ConfigError{Opt: "frecency.half_life", Err: err}
+	}
+
+	if d <= 0 {
+		return &ConfigError{Opt: "frecency.half_life", Err: errors.New("must be positive")}
+	}
+
+	return nil
+}
+
+func (c *MountsConfig) validate() error {
+	for name, m := range c.Vaults {
+		opt := "mounts.vaults." + name
+
+		if len(name) == 0 || strings.Contains(name, "/") {
+			return &ConfigError{Opt: opt, Err: fmt.Errorf("invalid mount name %q", name)}
+		}
+
+		if m == nil || len(m.Path) == 0 {
+			return &ConfigError{Opt: opt + ".path", Err: errors.New("required")}
+		}
+	}
+
+	return nil
+}
+
+func (c *RotationConfig) validate() error {
+	for label, hook := range c.Hooks {
+		if _, err := path.Match(label, ""); err != nil {
+			return &ConfigError{Opt: "rotation.hooks", Err: fmt.Errorf("invalid label pattern %q: %w", label, err)}
+		}
+
+		if len(hook) == 0 {
+			return &ConfigError{Opt: "rotation.hooks." + label, Err: errors.New("defined but contains no values")}
+		}
+	}
+
+	for label, every := range c.Intervals {
+		if _, err := path.Match(label, ""); err != nil {
+			return &ConfigError{Opt: "rotation.intervals", Err: fmt.Errorf("invalid label pattern %q: %w", label, err)}
+		}
+
+		d, err := cmdutil.ParseDuration(every)
+		if err != nil {
+			return &ConfigError{Opt: "rotation.intervals." + label, Err: err}
+		}
+
+		if d < time.Second {
+			return &ConfigError{Opt: "rotation.intervals." + label, Err: errors.New("must be at least one second")}
+		}
+	}
+
+	if c.ReminderCmd != nil && len(c.ReminderCmd) == 0 {
+		return &ConfigError{Opt: "rotation.reminder_cmd", Err: errors.New("defined but contains no values")}
+	}
+
+	return nil
+}
+
+func (c *PolicyConfig) validate() error {
+	if err := c.PolicyRulesConfig.validate("policy"); err != nil {
+		return err
+	}
+
+	for label, rules := range c.Labels {
+		if _, err := path.Match(label, ""); err != nil {
+			return &ConfigError{Opt: "policy.labels", Err: fmt.Errorf("invalid label pattern %q: %w", label, err)}
+		}
+
+		if err := rules.validate("policy.labels." + label); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *PolicyRulesConfig) validate(prefix string) error {
+	if len(c.Mode) > 0 && !policy.IsValidMode(c.Mode) {
+		return &ConfigError{Opt: prefix + ".mode", Err: fmt.Errorf("unsupported mode %q (supported: %v)", c.Mode, policy.Modes)}
+	}
+
+	if c.MinLength < 0 {
+		return &ConfigError{Opt: prefix + ".min_length", Err: errors.New("must not be negative")}
+	}
+
+	for _, class := range c.RequiredClasses {
+		if !policy.IsValidClass(class) {
+			return &ConfigError{Opt: prefix + ".required_classes", Err: fmt.Errorf("unsupported class %q (supported: %v)", class, policy.Classes)}
+		}
+	}
+
+	if len(c.MaxAge) > 0 {
+		if _, err := cmdutil.ParseDuration(c.MaxAge); err != nil {
+			return &ConfigError{Opt: prefix + ".max_age", Err: err}
+		}
+	}
+
 	return nil
 }
 
@@ -2,17 +2,43 @@ package cli
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"strings"
 
 	"github.com/ladzaretti/vlt-cli/clierror"
 	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/jq"
+	"github.com/ladzaretti/vlt-cli/query"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
 
 	"github.com/spf13/cobra"
 )
 
+// Output formats of 'vlt find'.
+const (
+	findOutputTable = "table"
+	findOutputJSON  = "json"
+)
+
+// findOutputs lists the output formats of 'vlt find'.
+var findOutputs = []string{findOutputTable, findOutputJSON, findOutputAlfred, findOutputRaycast}
+
+var (
+	// findJSONFields lists the fields of secrets listed in json, in their default order.
+	// Fields are only ever added, keeping the output stable across versions.
+	findJSONFields = []string{"id", "name", "labels", "username", "url", "mount"}
+
+	// findDefaultJSONFields are the fields listed in json unless set using --fields,
+	// or filtered using --jq, given all fields.
+	findDefaultJSONFields = []string{"id", "name", "labels"}
+)
+
 type FindError struct {
 	Err error
 }
@@ -32,6 +58,15 @@ type FindOptions struct {
 	pipe       bool
 	rawPipeCmd string
 	pipeCmd    []string
+
+	output   string       // output is the output format.
+	fields   []string     // fields are the secret fields listed in json.
+	rawQuery string       // rawQuery is the search query the secrets must match, see [query].
+	query    *query.Query // query is the parsed rawQuery, nil if unset.
+
+	rawJQ     string    // rawJQ is the jq filter applied to the json output, see [jq].
+	jq        *jq.Query // jq is the parsed rawJQ, nil if unset.
+	rawOutput bool      // rawOutput writes string outputs of the jq filter as is, rather than as json.
 }
 
 var _ genericclioptions.CmdOptions = &FindOptions{}
@@ -43,6 +78,7 @@ func NewFindOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOp
 		VaultOptions: vaultOptions,
 		config:       config,
 		search:       NewSearchableOptions(),
+		output:       findOutputTable,
 	}
 }
 
@@ -67,6 +103,46 @@ func (o *FindOptions) Validate() error {
 		return errors.New("cannot use --pipe: 'find_pipe_cmd' is not configured")
 	}
 
+	if !slices.Contains(findOutputs, o.output) {
+		return fmt.Errorf("unsupported output format %q (supported: %s)", o.output, strings.Join(findOutputs, ", "))
+	}
+
+	if len(o.fields) > 0 && o.output != findOutputJSON {
+		return fmt.Errorf("--fields requires --output %s", findOutputJSON)
+	}
+
+	for _, f := range o.fields {
+		if !slices.Contains(findJSONFields, f) {
+			return fmt.Errorf("unsupported field %q (supported: %s)", f, strings.Join(findJSONFields, ", "))
+		}
+	}
+
+	if len(o.rawQuery) > 0 {
+		q, err := query.Parse(o.rawQuery)
+		if err != nil {
+			return fmt.Errorf("invalid --query: %w", err)
+		}
+
+		o.query = q
+	}
+
+	if len(o.rawJQ) > 0 {
+		if o.output != findOutputJSON {
+			return fmt.Errorf("--jq requires --output %s", findOutputJSON)
+		}
+
+		q, err := jq.Parse(o.rawJQ)
+		if err != nil {
+			return fmt.Errorf("invalid --jq: %w", err)
+		}
+
+		o.jq = q
+	}
+
+	if o.rawOutput && o.jq == nil {
+		return errors.New("--raw-output requires --jq")
+	}
+
 	return nil
 }
 
@@ -80,14 +156,38 @@ func (o *FindOptions) Run(ctx context.Context, args ...string) (retErr error) {
 
 	o.search.WildcardFrom(args)
 
-	matchingSecrets, err := o.search.search(ctx, o.vault)
+	matchingSecrets, err := o.searchAll(ctx, o.StdioOptions, o.search)
 	if err != nil {
 		return err
 	}
 
+	if o.query != nil {
+		if matchingSecrets, err = o.filterQuery(ctx, o.StdioOptions, matchingSecrets, o.query); err != nil {
+			return err
+		}
+	}
+
 	var buf bytes.Buffer
 
-	printTable(&buf, matchingSecrets)
+	switch o.output {
+	case findOutputJSON:
+		err = o.printJSON(ctx, &buf, matchingSecrets)
+	case findOutputAlfred:
+		err = o.printAlfred(ctx, &buf, matchingSecrets)
+	case findOutputRaycast:
+		err = o.printRaycast(ctx, &buf, matchingSecrets)
+	default:
+		var colors map[string]string
+		if !o.pipe {
+			colors = o.labelColors(ctx, o.Out)
+		}
+
+		printTable(&buf, matchingSecrets, colors)
+	}
+
+	if err != nil {
+		return err
+	}
 
 	if o.pipe {
 		cmd := o.config.FindPipeCmd
@@ -103,6 +203,119 @@ func (o *FindOptions) Run(ctx context.Context, args ...string) (retErr error) {
 	return err
 }
 
+// printJSON writes the given secrets as a flat json array of objects
+// holding the fields set using --fields, or the outputs of the --jq filter
+// applied to it.
+func (o *FindOptions) printJSON(ctx context.Context, w io.Writer, secrets []secretWithLabels) error {
+	fields := o.fields
+	if len(fields) == 0 {
+		fields = findDefaultJSONFields
+		if o.jq != nil {
+			fields = findJSONFields
+		}
+	}
+
+	metas, err := o.secretMetas(ctx, o.StdioOptions, secrets)
+	if err != nil {
+		return err
+	}
+
+	objects := make([]map[string]any, 0, len(secrets))
+
+	for _, s := range secrets {
+		labels := s.labels
+		if labels == nil {
+			labels = []string{}
+		}
+
+		username, url := secretLogin(s, metas[s.displayID()])
+
+		values := map[string]any{
+			"id":       s.id,
+			"name":     s.name,
+			"labels":   labels,
+			"username": username,
+			"url":      url,
+			"mount":    s.mount,
+		}
+
+		object := make(map[string]any, len(fields))
+		for _, f := range fields {
+			object[f] = values[f]
+		}
+
+		objects = append(objects, object)
+	}
+
+	if o.jq == nil {
+		return encodeIndent(w, objects)
+	}
+
+	outputs, err := o.jq.Run(objects)
+	if err != nil {
+		return fmt.Errorf("--jq: %w", err)
+	}
+
+	for _, out := range outputs {
+		if s, ok := out.(string); ok && o.rawOutput {
+			fmt.Fprintln(w, s)
+			continue
+		}
+
+		if err := encodeIndent(w, out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// secretMetas returns the metadata of the given secrets of the vault
+// and its mounts, keyed by secret display id.
+func (o *VaultOptions) secretMetas(ctx context.Context, io *genericclioptions.StdioOptions, secrets []secretWithLabels) (map[string]vaultdb.SecretMeta, error) {
+	ids := make(map[string][]int) // mount name to secret ids
+	for _, s := range secrets {
+		ids[s.mount] = append(ids[s.mount], s.id)
+	}
+
+	metas := make(map[string]vaultdb.SecretMeta, len(secrets))
+
+	for mount, ids := range ids {
+		v, err := o.vaultOf(ctx, io, secretWithLabels{mount: mount})
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := v.SecretMetas(ctx, ids...)
+		if err != nil {
+			return nil, err
+		}
+
+		for id, meta := range m {
+			metas[secretWithLabels{id: id, mount: mount}.displayID()] = meta
+		}
+	}
+
+	return metas, nil
+}
+
+// secretLogin returns the username and url of a login: the ones of its
+// metadata if set, or else its name and first url label.
+func secretLogin(s secretWithLabels, meta vaultdb.SecretMeta) (username string, url string) {
+	return cmp.Or(meta.Username, strings.TrimPrefix(s.name, s.mount+"/")), cmp.Or(meta.URL, secretURL(s.labels))
+}
+
+// secretURL returns the first label of a secret holding a URL, if any.
+func secretURL(labels []string) string {
+	for _, l := range labels {
+		if strings.Contains(l, "://") {
+			return l
+		}
+	}
+
+	return ""
+}
+
 // NewCmdFind creates the find cobra command.
 func NewCmdFind(defaults *DefaultVltOptions) *cobra.Command {
 	o := NewFindOptions(
@@ -120,10 +333,43 @@ func NewCmdFind(defaults *DefaultVltOptions) *cobra.Command {
 
 You may optionally provide a glob pattern to match against secret names or labels.
 
-Filters can be applied using --id, --name, or --label.
+Filters can be applied using --id, --name, --label, --username or --url,
+the last two matching the metadata saved using 'vlt save'.
 Multiple --label flags can be applied and are logically ORed.
 
-Name and label values support UNIX glob patterns (e.g., "foo*", "*bar*").`,
+Name, label and metadata values support UNIX glob patterns (e.g., "foo*", "*bar*").
+
+Filters that cannot be expressed using flags are given using --query, a search
+query the secrets must match as well, as saved by 'vlt search save', e.g.,
+'name:github label:work url:*.example.com modified:<30d'. See 'vlt search --help'
+for the query syntax.
+
+The json output is filtered using --jq, a filter written in a subset of the jq
+language, extracting values without requiring jq to be installed. Filters are
+given all the fields of the secrets, unless set using --fields. The supported
+filters are paths (e.g., '.[0].labels[1]', '.[].name'), pipes, '[...]' and
+'{...}' constructions, comparisons, 'and', 'or', and the functions select,
+map, join, length, keys and not.
+
+The username and url fields hold the metadata of the secret if set, and
+otherwise its name and first label holding a URL.
+
+Vaults mounted using the 'mounts' config are searched as well, listing their
+secrets prefixed by the mount name, e.g., "team/db". Names and patterns
+prefixed by a mount name are searched in that mount only.
+
+The alfred and raycast output formats list the secrets for launchers, with
+actions copying the password or username, and opening the url label. Listed
+items never hold secret values: copying the password runs 'vlt get', so the
+vault must be unlocked ('vlt login'), and the secret name unique. For Alfred, a script filter passes the
+secret name to the next workflow action, along with the "vlt", "vault" and
+"action" variables, e.g., run using:
+
+  case "$action" in
+    copy-password) "$vlt" --file "$vault" get "$1" | pbcopy ;;
+    copy-username) printf '%s' "$1" | pbcopy ;;
+    open-url) open "$1" ;;
+  esac`,
 		Example: `  # Find secrets with names or labels containing "dev"
   vlt find "*dev*"
 
@@ -133,11 +379,26 @@ Name and label values support UNIX glob patterns (e.g., "foo*", "*bar*").`,
   # List all secrets in the vault
   vlt find
 
+  # Find the work logins of example.com changed within 30 days
+  vlt find --query 'label:work url:*.example.com modified:<30d'
+
+  # List the secrets of the "team" mount
+  vlt find "team/*"
+
   # Use a custom pipeline to process the results
   vlt find --pipe-cmd '[ "sh", "-c", "fzf --header-line=1 | awk '{print $1}' | xargs -r vlt show -c --id" ]'
   
   # Use the config configured pipeline to process results
-  vlt find --pipe`,
+  vlt find --pipe
+
+  # List the names and urls of secrets labeled "infra/*" as a json array
+  vlt find -o json --fields name,url --label "infra/*"
+
+  # Print the names of the secrets having a url, one per line
+  vlt find -o json --jq '.[] | select(.url != "") | .name' -r
+
+  # Alfred script filter
+  vlt ls -o alfred`,
 		Run: func(cmd *cobra.Command, args []string) {
 			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
 		},
@@ -146,6 +407,9 @@ Name and label values support UNIX glob patterns (e.g., "foo*", "*bar*").`,
 	cmd.Flags().IntSliceVarP(&o.search.IDs, "id", "", nil, FilterByID.Help())
 	cmd.Flags().StringVarP(&o.search.Name, "name", "", "", FilterByName.Help())
 	cmd.Flags().StringSliceVarP(&o.search.Labels, "label", "", nil, FilterByLabels.Help())
+	cmd.Flags().StringVarP(&o.search.Username, "username", "", "", FilterByUsername.Help())
+	cmd.Flags().StringVarP(&o.search.URL, "url", "", "", FilterByURL.Help())
+	cmd.Flags().StringVarP(&o.rawQuery, "query", "q", "", "search query the secrets must match, e.g., 'label:work -label:archive modified:<30d'")
 	cmd.Flags().BoolVarP(&o.pipe, "pipe", "p", false, "pipe output using 'find_pipe_cmd' if configured")
 	cmd.Flags().StringVarP(
 		&o.rawPipeCmd,
@@ -153,6 +417,10 @@ Name and label values support UNIX glob patterns (e.g., "foo*", "*bar*").`,
 		"",
 		"json string array to override 'find_pipe_cmd'",
 	)
+	cmd.Flags().StringVarP(&o.output, "output", "o", o.output, "output format ("+strings.Join(findOutputs, ", ")+")")
+	cmd.Flags().StringSliceVarP(&o.fields, "fields", "", nil, "fields listed in json (comma-separated or repeated): "+strings.Join(findJSONFields, ", "))
+	cmd.Flags().StringVarP(&o.rawJQ, "jq", "", "", "jq filter applied to the json output, e.g., '.[] | {name, url}'")
+	cmd.Flags().BoolVarP(&o.rawOutput, "raw-output", "r", false, "write strings output by --jq as is, rather than as json strings")
 
 	return cmd
 }
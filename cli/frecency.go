@@ -0,0 +1,307 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultFrecencyHalfLife is the fallback when no frecency half-life is set.
+const defaultFrecencyHalfLife = "7d"
+
+type FrecencyError struct {
+	Err error
+}
+
+func (e *FrecencyError) Error() string { return "frecency: " + e.Err.Error() }
+
+func (e *FrecencyError) Unwrap() error { return e.Err }
+
+// FrecencyOptions holds data required to run the command.
+type FrecencyOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config *ResolvedConfig
+}
+
+var _ genericclioptions.CmdOptions = &FrecencyOptions{}
+
+// NewFrecencyOptions initializes the options struct.
+func NewFrecencyOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *FrecencyOptions {
+	return &FrecencyOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+	}
+}
+
+func (*FrecencyOptions) Complete() error { return nil }
+
+func (*FrecencyOptions) Validate() error { return nil }
+
+func (o *FrecencyOptions) Run(ctx context.Context, _ ...string) error {
+	secrets, err := o.searchAll(ctx, o.StdioOptions, NewSearchableOptions())
+	if err != nil {
+		return &FrecencyError{err}
+	}
+
+	scores, err := o.frecency(ctx, o.StdioOptions, secrets, time.Duration(o.config.FrecencyHalfLife))
+	if err != nil {
+		return &FrecencyError{err}
+	}
+
+	if len(scores) == 0 {
+		o.Warnf("No accessed secrets found.\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tSCORE")
+
+	for _, s := range rankByScore(secrets, scores) {
+		if score, ok := scores[s.displayID()]; ok {
+			fmt.Fprintf(tw, "%s\t%s\t%.2f\n", s.displayID(), s.name, score)
+		}
+	}
+
+	return nil
+}
+
+// FrecencyResetOptions holds data required to run the command.
+type FrecencyResetOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &FrecencyResetOptions{}
+
+// NewFrecencyResetOptions initializes the options struct.
+func NewFrecencyResetOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *FrecencyResetOptions {
+	return &FrecencyResetOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*FrecencyResetOptions) Complete() error { return nil }
+
+func (*FrecencyResetOptions) Validate() error { return nil }
+
+func (o *FrecencyResetOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &FrecencyError{retErr}
+			return
+		}
+	}()
+
+	if len(args) == 0 {
+		if err := o.vault.ResetFrecency(ctx); err != nil {
+			return err
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(o.mounts)) {
+			v, err := o.mountedVault(ctx, o.StdioOptions, name)
+			if err != nil {
+				return err
+			}
+
+			if err := v.ResetFrecency(ctx); err != nil {
+				return fmt.Errorf("mount %s: %w", name, err)
+			}
+		}
+
+		return genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite)
+	}
+
+	for _, name := range args {
+		s, err := o.secretNamed(ctx, o.StdioOptions, name)
+		if err != nil {
+			return err
+		}
+
+		v, err := o.vaultOf(ctx, o.StdioOptions, s)
+		if err != nil {
+			return err
+		}
+
+		if err := v.ResetFrecency(ctx, s.id); err != nil {
+			return err
+		}
+	}
+
+	return genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite)
+}
+
+// frecency returns the frecency scores of the given secrets, keyed by
+// display id. Secrets never accessed are not scored.
+func (o *VaultOptions) frecency(ctx context.Context, io *genericclioptions.StdioOptions, secrets []secretWithLabels, halfLife time.Duration) (map[string]float64, error) {
+	var (
+		now    = time.Now()
+		mounts = make(map[string]map[int]float64) // mount name to secret scores
+		scores = make(map[string]float64)
+	)
+
+	for _, s := range secrets {
+		if _, ok := mounts[s.mount]; !ok {
+			v, err := o.vaultOf(ctx, io, s)
+			if err != nil {
+				return nil, err
+			}
+
+			if mounts[s.mount], err = v.Frecency(ctx, now, halfLife); err != nil {
+				return nil, err
+			}
+		}
+
+		if score, ok := mounts[s.mount][s.id]; ok {
+			scores[s.displayID()] = score
+		}
+	}
+
+	return scores, nil
+}
+
+// rankByFrecency returns the given secrets ordered by frecency, most frecent
+// first. Secrets never accessed follow, in order.
+func (o *VaultOptions) rankByFrecency(ctx context.Context, io *genericclioptions.StdioOptions, secrets []secretWithLabels, halfLife time.Duration) ([]secretWithLabels, error) {
+	scores, err := o.frecency(ctx, io, secrets, halfLife)
+	if err != nil {
+		return nil, err
+	}
+
+	return rankByScore(secrets, scores), nil
+}
+
+// rankByScore returns the given secrets ordered by their scores, keyed by
+// display id, highest first. Secrets having equal scores keep their order.
+func rankByScore(secrets []secretWithLabels, scores map[string]float64) []secretWithLabels {
+	ranked := slices.Clone(secrets)
+	slices.SortStableFunc(ranked, func(a, b secretWithLabels) int {
+		return cmp.Compare(scores[b.displayID()], scores[a.displayID()])
+	})
+
+	return ranked
+}
+
+// completeSecretNames completes the first argument using the secret names of
+// the vault and its mounts, most frecent first. The vault is opened without
+// prompting: nothing is completed if it is locked.
+func completeSecretNames(defaults *DefaultVltOptions) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		names, err := defaults.frecentNames(cmd.Context())
+		if err != nil {
+			cobra.CompDebugln(err.Error(), true)
+			return nil, cobra.ShellCompDirectiveError | cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+	}
+}
+
+// frecentNames returns the secret names of the vault and its mounts, most
+// frecent first.
+//
+// Flags are only parsed once the completed command is found, after the
+// persistent pre-run, so the configuration is resolved again here.
+func (o *DefaultVltOptions) frecentNames(ctx context.Context) (_ []string, retErr error) {
+	if err := genericclioptions.ExecuteCommand(ctx, o, cobra.ShellCompRequestCmd); err != nil {
+		return nil, err
+	}
+
+	sessionClient, err := o.vaultOptions.openNoPrompt(ctx, o.StdioOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { //nolint:wsl
+		retErr = errors.Join(retErr, o.vaultOptions.closeNoPrompt(ctx, sessionClient))
+	}()
+
+	secrets, err := o.vaultOptions.searchAll(ctx, o.StdioOptions, NewSearchableOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err = o.vaultOptions.rankByFrecency(ctx, o.StdioOptions, secrets, time.Duration(o.configOptions.resolved.FrecencyHalfLife))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(secrets))
+
+	for _, s := range secrets {
+		if !slices.Contains(names, s.name) {
+			names = append(names, s.name)
+		}
+	}
+
+	return names, nil
+}
+
+// NewCmdFrecency creates the frecency cobra command.
+func NewCmdFrecency(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewFrecencyOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "frecency",
+		Short: "List the secrets ranked by frecency (subcommands available)",
+		Long: `List the accessed secrets of the vault and its mounts, ranked by frecency.
+
+Every access to a secret value is recorded, e.g., using 'vlt show', 'vlt get'
+or 'vlt dmenu'. Secrets accessed both often and recently rank first in
+'vlt dmenu' and in the shell completions of secret names, e.g., of 'vlt open'.
+The weight of an access halves over time, every 'frecency.half_life' (7 days
+unless configured), and only the last 10 accesses of a secret are kept.
+
+Accesses are part of the vault, but are not synced between devices.
+They are forgotten using 'vlt frecency reset'.`,
+		Example: `  # List the secrets ranked by frecency
+  vlt frecency
+
+  # Forget the accesses of a secret
+  vlt frecency reset work-vpn
+
+  # Forget all accesses
+  vlt frecency reset`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.AddCommand(NewCmdFrecencyReset(defaults))
+
+	return cmd
+}
+
+// NewCmdFrecencyReset creates the frecency reset cobra command.
+func NewCmdFrecencyReset(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewFrecencyResetOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:               "reset [NAME...]",
+		Short:             "Forget the accesses of the given secrets, or of all secrets",
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeSecretNames(defaults),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
@@ -1,48 +1,127 @@
 package cli
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/ladzaretti/vlt-cli/clierror"
 	"github.com/ladzaretti/vlt-cli/clipboard"
 	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/genpass"
 	"github.com/ladzaretti/vlt-cli/randstring"
 
 	"github.com/spf13/cobra"
 )
 
+type GenerateError struct {
+	Err error
+}
+
+func (e *GenerateError) Error() string { return "generate: " + e.Err.Error() }
+
+func (e *GenerateError) Unwrap() error { return e.Err }
+
 type GenerateOptions struct {
 	*genericclioptions.StdioOptions
+	*VaultOptions
 
-	policy randstring.PasswordPolicy
-	copy   bool
+	// configOptions is loaded by the command itself,
+	// as generate bypasses the persistent pre-run, unless saving.
+	configOptions *ConfigOptions
+
+	policy        randstring.PasswordPolicy
+	length        int  // length is the length of pronounceable passwords, or the minimum length of random ones.
+	pronounceable bool // pronounceable generates alternating consonants and vowels.
+	passphrase    bool // passphrase generates a diceware passphrase.
+	words         int
+	separator     string
+	capitalize    bool
+	wordlistPath  string // wordlistPath is the passphrase wordlist file, if not the default one.
+	labels        []string
+	copy          bool
+	save          string // save is the name the generated password is stored under, if any.
+
+	wordlist []string
 }
 
 var _ genericclioptions.CmdOptions = &GenerateOptions{}
 
 // NewGenerateOptions initializes the options struct.
-func NewGenerateOptions(stdio *genericclioptions.StdioOptions) *GenerateOptions {
+func NewGenerateOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, configOptions *ConfigOptions) *GenerateOptions {
 	return &GenerateOptions{
-		StdioOptions: stdio,
+		StdioOptions:  stdio,
+		VaultOptions:  vaultOptions,
+		configOptions: configOptions,
+	}
+}
+
+func (o *GenerateOptions) Complete() error {
+	if len(o.wordlistPath) > 0 {
+		f, err := os.Open(o.wordlistPath)
+		if err != nil {
+			return &GenerateError{err}
+		}
+		defer func() { //nolint:wsl
+			_ = f.Close()
+		}()
+
+		wordlist, err := genpass.ParseWordlist(f)
+		if err != nil {
+			return &GenerateError{fmt.Errorf("%s: %w", o.wordlistPath, err)}
+		}
+
+		o.wordlist = wordlist
 	}
+
+	return o.configOptions.Complete()
 }
 
-func (*GenerateOptions) Complete() error { return nil }
+func (o *GenerateOptions) Validate() error {
+	if o.pronounceable && o.passphrase {
+		return &GenerateError{errors.New("--pronounceable and --passphrase cannot be used together")}
+	}
+
+	classes := o.policy
+	classes.MinLength = 0
 
-func (*GenerateOptions) Validate() error { return nil }
+	if (o.pronounceable || o.passphrase) && classes != (randstring.PasswordPolicy{}) {
+		return &GenerateError{errors.New("character requirements cannot be used with --pronounceable or --passphrase")}
+	}
 
-func (o *GenerateOptions) Run(context.Context, ...string) error {
-	policy := o.policy
+	if o.passphrase && o.length > 0 {
+		return &GenerateError{errors.New("--length cannot be used with --passphrase, use --words")}
+	}
 
-	zero := randstring.PasswordPolicy{}
-	if policy == zero {
-		policy = randstring.DefaultPasswordPolicy
+	if o.length > 0 && o.policy.MinLength > 0 {
+		return &GenerateError{errors.New("--length and --min-length cannot be used together")}
 	}
 
-	s, err := randstring.NewWithPolicy(policy)
+	if len(o.wordlistPath) > 0 && !o.passphrase {
+		return &GenerateError{errors.New("--wordlist requires --passphrase")}
+	}
+
+	if o.length < 0 || o.words < 0 {
+		return &GenerateError{errors.New("--length and --words must be positive")}
+	}
+
+	return nil
+}
+
+func (o *GenerateOptions) Run(ctx context.Context, _ ...string) error {
+	passwordPolicy := newPasswordPolicy(o.configOptions.resolved)
+
+	s, err := o.generate(passwordPolicy)
 	if err != nil {
-		return err
+		return &GenerateError{err}
+	}
+
+	if len(o.save) > 0 {
+		if err := o.saveGenerated(ctx, passwordPolicy, s); err != nil {
+			return &GenerateError{err}
+		}
 	}
 
 	if o.copy {
@@ -50,19 +129,90 @@ func (o *GenerateOptions) Run(context.Context, ...string) error {
 		return clipboard.Copy(s)
 	}
 
+	// saved passwords are shown using 'vlt show'.
+	if len(o.save) > 0 {
+		o.Infof("Saved the generated password as %q.\n", o.save)
+		return nil
+	}
+
 	o.Infof("%s", s)
 
 	return nil
 }
 
+// generate returns a password satisfying the configured password policy.
+//
+// If explicit requirements or another mode were given, they are used as is
+// and the result is evaluated against the policy instead.
+func (o *GenerateOptions) generate(passwordPolicy passwordPolicy) (string, error) {
+	opts := genpass.Options{
+		Mode:       genpass.ModeRandom,
+		Policy:     o.policy,
+		Words:      cmp.Or(o.words, genpass.DefaultWords),
+		Separator:  o.separator,
+		Capitalize: o.capitalize,
+		Wordlist:   o.wordlist,
+		Length:     cmp.Or(o.length, genpass.DefaultLength),
+	}
+
+	switch {
+	case o.pronounceable:
+		opts.Mode = genpass.ModePronounceable
+	case o.passphrase:
+		opts.Mode = genpass.ModePassphrase
+	case o.length > 0:
+		opts.Policy.MinLength = o.length
+	case o.policy == (randstring.PasswordPolicy{}):
+		return passwordPolicy.generate(o.labels)
+	}
+
+	s, err := genpass.Generate(opts)
+	if err != nil {
+		return "", err
+	}
+
+	// saved passwords are checked along with their labels.
+	if len(o.save) > 0 {
+		return s, nil
+	}
+
+	if err := passwordPolicy.checkValue(o.StdioOptions, s, o.labels); err != nil {
+		return "", err
+	}
+
+	return s, nil
+}
+
+// saveGenerated stores the generated password under the name given by --save.
+func (o *GenerateOptions) saveGenerated(ctx context.Context, passwordPolicy passwordPolicy, s string) error {
+	if err := passwordPolicy.check(o.StdioOptions, s, o.labels); err != nil {
+		return err
+	}
+
+	n, err := o.vault.InsertNewSecret(ctx, o.save, s, o.labels)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNoSecretInserted
+	}
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		o.Warnf("Post-write hook failed: %v", err)
+	}
+
+	return nil
+}
+
 // NewCmdGenerate creates the Generate cobra command.
 func NewCmdGenerate(defaults *DefaultVltOptions) *cobra.Command {
-	o := NewGenerateOptions(defaults.StdioOptions)
+	o := NewGenerateOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions)
 
 	cmd := &cobra.Command{
 		Use:     "generate",
 		Aliases: []string{"gen", "rand"},
-		Short:   "Generate a random password",
+		Short:   "Generate a random password, pronounceable password or passphrase",
 		Long: fmt.Sprintf(`Generate a random password based on the provided character requirements and minimum length.
 
 If no flags are provided, the default policy is:
@@ -75,13 +225,37 @@ If no flags are provided, the default policy is:
 If a specific requirement is provided (e.g., '--digits 4'), the generated password will 
 contain at least that many characters of the specified type. Any remaining characters will 
 be randomly chosen to meet the minimum total length.
+
+If a password policy is configured, the default policy is extended to satisfy it,
+including any label-scoped overrides matching the labels given by '--label'.
+Passwords generated from explicit requirements are evaluated against the policy instead.
+
+Modes:
+  - '--pronounceable': alternating consonants and vowels, of '--length' characters (default %d).
+  - '--passphrase': diceware passphrase of '--words' words (default %d), drawn from
+    the built-in wordlist or from '--wordlist', a file of one word per line,
+    optionally numbered by dice rolls.
+
+Use '--save NAME' to store the generated password in the vault right away,
+labeled by '--label'. Saved passwords are not printed, use '--copy-clipboard'
+or 'vlt show' to retrieve them.
 `,
 			randstring.DefaultPasswordPolicy.MinUppercase,
 			randstring.DefaultPasswordPolicy.MinLowercase,
 			randstring.DefaultPasswordPolicy.MinNumeric,
 			randstring.DefaultPasswordPolicy.MinSpecial,
 			randstring.DefaultPasswordPolicy.MinLength,
+			genpass.DefaultLength,
+			genpass.DefaultWords,
 		),
+		Example: `  # Generate a password of the default policy
+  vlt generate
+
+  # Generate a pronounceable password of 12 characters
+  vlt generate --pronounceable --length 12
+
+  # Generate and save a passphrase of 5 capitalized words
+  vlt generate --passphrase --words 5 --capitalize --save wifi`,
 		Run: func(cmd *cobra.Command, _ []string) {
 			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
 		},
@@ -92,6 +266,15 @@ be randomly chosen to meet the minimum total length.
 	cmd.Flags().IntVarP(&o.policy.MinSpecial, "special", "s", 0, "minimum number of special characters")
 	cmd.Flags().IntVarP(&o.policy.MinNumeric, "numeric", "d", 0, "minimum number of numeric characters")
 	cmd.Flags().IntVarP(&o.policy.MinLength, "min-length", "m", 0, "minimum total length of the password")
+	cmd.Flags().IntVarP(&o.length, "length", "n", 0, "length of the password, the minimum length for random passwords")
+	cmd.Flags().BoolVarP(&o.pronounceable, "pronounceable", "", false, "generate a pronounceable password")
+	cmd.Flags().BoolVarP(&o.passphrase, "passphrase", "", false, "generate a diceware passphrase")
+	cmd.Flags().IntVarP(&o.words, "words", "w", genpass.DefaultWords, "number of passphrase words")
+	cmd.Flags().StringVarP(&o.separator, "separator", "", genpass.DefaultSeparator, "separator of passphrase words")
+	cmd.Flags().BoolVarP(&o.capitalize, "capitalize", "", false, "capitalize passphrase words")
+	cmd.Flags().StringVarP(&o.wordlistPath, "wordlist", "", "", "path to a passphrase wordlist file")
+	cmd.Flags().StringVarP(&o.save, "save", "", "", "save the generated password under the given name")
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "labels selecting password policy overrides, and of the saved password (comma-separated or repeated)")
 	cmd.Flags().BoolVarP(&o.copy, "copy-clipboard", "c", false, "copy the generated password to the clipboard")
 
 	return cmd
@@ -0,0 +1,282 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vaultdaemon"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of 'vlt get', distinguishing the failures scripts may handle.
+const (
+	getExitNotFound = 2
+	getExitLocked   = 3
+)
+
+// getShowHint points users of the former 'vlt get' alias of 'vlt show'
+// to the latter, on usage only supported by it.
+const getShowHint = "'vlt get' takes an exact name and is no longer an alias of 'vlt show', use 'vlt show' to search by glob or label"
+
+// errFieldNotFound indicates that the secret value has no such field.
+var errFieldNotFound = errors.New("field not found")
+
+// errBinaryValue indicates a binary secret value, not printed to a terminal unless --raw is set.
+var errBinaryValue = errors.New("the secret value is binary, use --raw to print it as is")
+
+type GetError struct {
+	Err error
+}
+
+func (e *GetError) Error() string { return "get: " + e.Err.Error() }
+
+func (e *GetError) Unwrap() error { return e.Err }
+
+// GetOptions holds data required to run the command.
+type GetOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	newline bool // newline appends a newline to the printed value.
+	raw     bool // raw prints the value exactly as stored, even if binary.
+}
+
+var _ genericclioptions.CmdOptions = &GetOptions{}
+
+// NewGetOptions initializes the options struct.
+func NewGetOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *GetOptions {
+	return &GetOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*GetOptions) Complete() error { return nil }
+
+func (o *GetOptions) Validate() error {
+	if o.raw && o.newline {
+		return &GetError{errors.New("--raw and --newline cannot be used together")}
+	}
+
+	return nil
+}
+
+func (o *GetOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr == nil {
+			return
+		}
+
+		retErr = &GetError{retErr}
+
+		switch {
+		case errors.Is(retErr, vaulterrors.ErrVaultLocked):
+			retErr = &clierror.ExitCodeError{Code: getExitLocked, Err: retErr}
+		case errors.Is(retErr, vaulterrors.ErrSearchNoMatch), errors.Is(retErr, errFieldNotFound):
+			retErr = &clierror.ExitCodeError{Code: getExitNotFound, Err: retErr}
+		}
+	}()
+
+	if _, ok := terminalFd(o.Out); ok && o.confirmOutput {
+		return ErrConfirmationUnavailable
+	}
+
+	sessionClient, err := o.openNoPrompt(ctx, o.StdioOptions)
+	if err != nil {
+		return err
+	}
+	defer func() { //nolint:wsl
+		retErr = errors.Join(retErr, o.closeNoPrompt(ctx, sessionClient))
+	}()
+
+	value, err := o.lookupValue(ctx, o.StdioOptions, args[0])
+	if errors.Is(err, vaulterrors.ErrSearchNoMatch) && strings.ContainsAny(args[0], "*?[") {
+		o.Warnf("%s\n", getShowHint)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if _, ok := terminalFd(o.Out); ok && !o.raw && !utf8.ValidString(value) {
+		return errBinaryValue
+	}
+
+	if o.newline {
+		value += "\n"
+	}
+
+	o.Infof("%s", value)
+
+	return nil
+}
+
+// openNoPrompt opens the vault using the access token or the current session,
+// failing with [vaulterrors.ErrVaultLocked] instead of prompting for the password.
+// It is used by commands reading secrets on behalf of other programs,
+// which open the vault themselves, and close it using [VaultOptions.closeNoPrompt].
+func (o *VaultOptions) openNoPrompt(ctx context.Context, io *genericclioptions.StdioOptions) (*vaultdaemon.SessionClient, error) {
+	o.noPrompt = true
+
+	if len(o.token) > 0 {
+		return nil, o.Open(ctx, io, nil, 0)
+	}
+
+	c, err := vaultdaemon.NewSessionClient()
+	if err != nil {
+		io.Debugf("vlt: daemon unavailable: %v\n", err)
+		return nil, vaulterrors.ErrVaultLocked
+	}
+
+	if err := o.Open(ctx, io, c, 0); err != nil {
+		return nil, errors.Join(err, c.Close())
+	}
+
+	return c, nil
+}
+
+// closeNoPrompt closes the vault opened by [VaultOptions.openNoPrompt], along with its mounts.
+func (o *VaultOptions) closeNoPrompt(ctx context.Context, sessionClient *vaultdaemon.SessionClient) error {
+	return errors.Join(o.closeMounts(ctx), o.closeVault(ctx), o.Close(), sessionClient.Close())
+}
+
+// lookupValue returns the value referenced by ref, as looked up by [VaultOptions.lookup].
+func (o *VaultOptions) lookupValue(ctx context.Context, io *genericclioptions.StdioOptions, ref string) (string, error) {
+	secret, field, err := o.lookup(ctx, io, ref)
+	if err != nil {
+		return "", err
+	}
+
+	v, err := o.vaultOf(ctx, io, secret)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := v.ShowSecret(ctx, secret.id)
+	if err != nil {
+		return "", err
+	}
+
+	if len(field) == 0 {
+		return value, nil
+	}
+
+	return selectJSONField(value, field)
+}
+
+// lookup returns the secret named ref, or else the secret named by ref up to
+// its last '/', along with the field following it.
+func (o *VaultOptions) lookup(ctx context.Context, io *genericclioptions.StdioOptions, ref string) (secretWithLabels, string, error) {
+	name, field := ref, ""
+
+	for {
+		secrets, err := o.searchAll(ctx, io, &SearchableOptions{Name: name})
+		if err != nil {
+			return secretWithLabels{}, "", err
+		}
+
+		secrets = slices.DeleteFunc(secrets, func(s secretWithLabels) bool { return s.name != name })
+
+		switch {
+		case len(secrets) == 1:
+			return secrets[0], field, nil
+		case len(secrets) > 1:
+			return secretWithLabels{}, "", fmt.Errorf("%q: %w", name, vaulterrors.ErrAmbiguousSecretMatch)
+		}
+
+		i := strings.LastIndex(name, "/")
+		if i < 0 || len(field) > 0 {
+			return secretWithLabels{}, "", o.notFound(ctx, io, ref)
+		}
+
+		name, field = name[:i], name[i+1:]
+	}
+}
+
+// selectJSONField returns the given field of a secret holding a JSON object.
+// Non-string values are returned in their JSON encoding.
+func selectJSONField(value string, field string) (string, error) {
+	var values map[string]any
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return "", fmt.Errorf("%w: %q: the secret value is not a JSON object", errFieldNotFound, field)
+	}
+
+	v, ok := values[field]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", errFieldNotFound, field)
+	}
+
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("field %q: %w", field, err)
+	}
+
+	return string(encoded), nil
+}
+
+// NewCmdGet creates the get cobra command.
+func NewCmdGet(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewGetOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "get NAME[/FIELD]",
+		Short: "Print a single secret value, for templates and scripts",
+		Long: fmt.Sprintf(`Print the value of the secret with exactly the given name, and nothing else.
+
+Intended for templating tools and scripts (e.g., chezmoi, envsubst, Makefiles),
+get never prompts: the vault is opened using the current session ('vlt login')
+or the VLT_TOKEN access token. The value is printed as is, without a trailing
+newline unless --newline is set. Binary values (see 'vlt save --binary') are
+not printed to a terminal, unless --raw is set.
+
+If no secret has the given name, the name up to its last '/' is looked up
+instead, and the rest selects a field of the secret, holding a JSON object.
+Secrets of mounted vaults are named with their mount prefix, e.g., "team/db".
+
+'get' used to be an alias of 'show', use 'vlt show' to search by glob or label.
+
+Exit codes:
+    %d: the secret or field was not found
+    %d: the vault is locked, no session or access token was found
+    1: any other error`, getExitNotFound, getExitLocked),
+		Example: `  # Print a secret
+  vlt get github
+
+  # Print the "password" field of a secret holding a JSON object
+  vlt get db/password
+
+  # Write a binary secret to a file
+  vlt get --raw tls/key.der > key.der
+
+  # Use in a chezmoi template
+  {{ output "vlt" "get" "github" }}`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(defaults),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	// flags of 'vlt show' are most likely passed by users of the former alias.
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return fmt.Errorf("%w\n%s", err, getShowHint)
+	})
+
+	cmd.Flags().BoolVarP(&o.newline, "newline", "n", false, "print a trailing newline after the value")
+	cmd.Flags().BoolVarP(&o.raw, "raw", "", false, "print the exact bytes of the value, even if binary, without a trailing newline")
+
+	return cmd
+}
@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/clipboard"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+
+	"github.com/spf13/cobra"
+)
+
+type HistoryError struct {
+	Err error
+}
+
+func (e *HistoryError) Error() string { return "history: " + e.Err.Error() }
+
+func (e *HistoryError) Unwrap() error { return e.Err }
+
+// HistoryOptions holds data required to run the command.
+type HistoryOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	show int  // show is the version whose value is output, zero lists the versions.
+	copy bool // copy controls whether to copy the value to the clipboard instead.
+}
+
+var _ genericclioptions.CmdOptions = &HistoryOptions{}
+
+// NewHistoryOptions initializes the options struct.
+func NewHistoryOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *HistoryOptions {
+	return &HistoryOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*HistoryOptions) Complete() error { return nil }
+
+func (o *HistoryOptions) Validate() error {
+	if o.copy && o.show == 0 {
+		return &HistoryError{errors.New("--copy-clipboard requires --show")}
+	}
+
+	return nil
+}
+
+func (o *HistoryOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &HistoryError{retErr}
+			return
+		}
+	}()
+
+	id, err := parseSecretID(args[0])
+	if err != nil {
+		return err
+	}
+
+	if o.show > 0 {
+		return o.showVersion(ctx, id)
+	}
+
+	versions, err := o.vault.SecretHistory(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if len(versions) == 0 {
+		o.Warnf("No previous versions found.\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "VERSION\tREPLACED")
+
+	for _, v := range versions {
+		fmt.Fprintf(tw, "%d\t%s\n", v.Version, v.ReplacedAt.Local().Format(time.DateTime))
+	}
+
+	return nil
+}
+
+func (o *HistoryOptions) showVersion(ctx context.Context, id int) error {
+	value, err := o.vault.SecretAtVersion(ctx, id, o.show)
+	if err != nil {
+		return err
+	}
+
+	if o.copy {
+		o.Debugf("copying secret to clipboard\n")
+		return clipboard.Copy(value)
+	}
+
+	if ok, err := o.confirmPrint(o.StdioOptions, fmt.Sprintf("version %d of secret %d", o.show, id)); !ok {
+		return err
+	}
+
+	o.Infof("%s", value)
+
+	return nil
+}
+
+// parseSecretID parses the given secret id argument.
+func parseSecretID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid secret id %q", arg)
+	}
+
+	return id, nil
+}
+
+// NewCmdHistory creates the history cobra command.
+func NewCmdHistory(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewHistoryOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "history ID",
+		Short: "List the previous values of a secret (subcommands available)",
+		Long: `List the previous versions of the value of a secret, newest first.
+
+Every update of a secret value, e.g., using 'vlt update secret', 'vlt edit',
+'vlt rotate' or a sync pull, keeps the value replaced as a new version.
+Versions are listed without their values: use --show to output one, and
+'vlt history restore' to set the secret back to it.
+
+Versions are part of the vault and covered by its integrity check, but are
+not synced between devices, nor exported. They are removed along with the secret.`,
+		Example: `  # List the previous values of the secret with id 12
+  vlt history 12
+
+  # Copy the value of version 3 to the clipboard
+  vlt history 12 --show 3 -c
+
+  # Set the secret back to its value at version 3
+  vlt history restore 12 3`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().IntVarP(&o.show, "show", "", 0, "output the value of the given version (unsafe)")
+	cmd.Flags().BoolVarP(&o.copy, "copy-clipboard", "c", false, "copy the value of the version given by --show to the clipboard")
+
+	cmd.AddCommand(NewCmdHistoryRestore(defaults))
+
+	return cmd
+}
+
+// HistoryRestoreOptions holds data required to run the command.
+type HistoryRestoreOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &HistoryRestoreOptions{}
+
+// NewHistoryRestoreOptions initializes the options struct.
+func NewHistoryRestoreOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *HistoryRestoreOptions {
+	return &HistoryRestoreOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*HistoryRestoreOptions) Complete() error { return nil }
+
+func (*HistoryRestoreOptions) Validate() error { return nil }
+
+func (o *HistoryRestoreOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &HistoryError{retErr}
+			return
+		}
+	}()
+
+	id, err := parseSecretID(args[0])
+	if err != nil {
+		return err
+	}
+
+	version, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid version %q", args[1])
+	}
+
+	n, err := o.vault.RestoreSecretVersion(ctx, id, version)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNoSecretUpdated
+	}
+
+	o.Infof("Restored secret %d to version %d.\n", id, version)
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		o.Warnf("Post-write hook failed: %v", err)
+	}
+
+	return nil
+}
+
+// NewCmdHistoryRestore creates the history restore cobra command.
+func NewCmdHistoryRestore(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewHistoryRestoreOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "restore ID VERSION",
+		Short: "Set a secret back to a previous value",
+		Long: `Set the value of a secret back to its value at the given version, as listed
+by 'vlt history'. The value replaced is kept as a new version.`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
@@ -0,0 +1,401 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/identity"
+	"github.com/ladzaretti/vlt-cli/input"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+type IdentityError struct {
+	Err error
+}
+
+func (e *IdentityError) Error() string { return "identity: " + e.Err.Error() }
+
+func (e *IdentityError) Unwrap() error { return e.Err }
+
+// NewCmdIdentity creates the identity cobra command with its sub-commands.
+func NewCmdIdentity(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "identity",
+		Short: "Manage personal identities (subcommands available)",
+		Long: fmt.Sprintf(`Manage personal identities stored in the vault.
+
+Identities are stored as secrets labeled %q, holding a JSON object of their
+fields: %s.
+
+Identities are imported from 1Password and Bitwarden identity items by
+'vlt import'. As with any other JSON secret, fields can also be printed using
+'vlt get', e.g., 'vlt get me/passport_number'.`, identity.Label, strings.Join(identity.Fields, ", ")),
+	}
+
+	cmd.AddCommand(NewCmdIdentityAdd(defaults))
+	cmd.AddCommand(NewCmdIdentityList(defaults))
+	cmd.AddCommand(NewCmdIdentityShow(defaults))
+
+	return cmd
+}
+
+// IdentityAddOptions holds data required to run the command.
+type IdentityAddOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	name   string
+	labels []string
+}
+
+var _ genericclioptions.CmdOptions = &IdentityAddOptions{}
+
+// NewIdentityAddOptions initializes the options struct.
+func NewIdentityAddOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *IdentityAddOptions {
+	return &IdentityAddOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*IdentityAddOptions) Complete() error { return nil }
+
+func (o *IdentityAddOptions) Validate() error {
+	if o.NonInteractive && len(o.name) == 0 {
+		return &IdentityError{errors.New("--name is required in non-interactive mode")}
+	}
+
+	return nil
+}
+
+func (o *IdentityAddOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &IdentityError{retErr}
+			return
+		}
+	}()
+
+	id, err := o.readIdentity()
+	if err != nil {
+		return err
+	}
+
+	if err := id.Validate(); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(id)
+	if err != nil {
+		return err
+	}
+
+	labels := append(slices.Clone(o.labels), identity.Label)
+
+	n, err := o.vault.InsertNewSecret(ctx, o.name, string(value), labels)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNoSecretInserted
+	}
+
+	o.Infof("Added identity %q (%s)\n", o.name, id.FullName())
+
+	return nil
+}
+
+// readIdentity reads the identity from stdin as a JSON object if piped or
+// redirected, or else prompts for each of its fields.
+func (o *IdentityAddOptions) readIdentity() (identity.Identity, error) {
+	if o.NonInteractive {
+		s, err := input.ReadTrim(o.In)
+		if err != nil {
+			return identity.Identity{}, err
+		}
+
+		return identity.Parse(s)
+	}
+
+	if len(o.name) == 0 {
+		name, err := input.PromptRead(o.Out, o.In, "Enter name: ")
+		if err != nil {
+			return identity.Identity{}, fmt.Errorf("name read interactive: %w", err)
+		}
+
+		o.name = name
+	}
+
+	values := make(map[string]string, len(identity.Fields))
+
+	for _, f := range identity.Fields {
+		v, err := input.PromptRead(o.Out, o.In, "Enter %s, or press Enter to skip: ", strings.ReplaceAll(f, "_", " "))
+		if err != nil {
+			return identity.Identity{}, fmt.Errorf("%s read interactive: %w", f, err)
+		}
+
+		if len(v) > 0 {
+			values[f] = v
+		}
+	}
+
+	b, err := json.Marshal(values)
+	if err != nil {
+		return identity.Identity{}, err
+	}
+
+	return identity.Parse(string(b))
+}
+
+// IdentityListOptions holds data required to run the command.
+type IdentityListOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &IdentityListOptions{}
+
+// NewIdentityListOptions initializes the options struct.
+func NewIdentityListOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *IdentityListOptions {
+	return &IdentityListOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*IdentityListOptions) Complete() error { return nil }
+
+func (*IdentityListOptions) Validate() error { return nil }
+
+func (o *IdentityListOptions) Run(ctx context.Context, _ ...string) error {
+	secrets, err := o.searchAll(ctx, o.StdioOptions, &SearchableOptions{Labels: []string{identity.Label}})
+	if err != nil {
+		return &IdentityError{err}
+	}
+
+	if len(secrets) == 0 {
+		o.Warnf("No identities found.\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tFULL NAME\tEMAIL")
+
+	for _, s := range secrets {
+		id, err := o.readIdentity(ctx, o.StdioOptions, s)
+		if err != nil {
+			o.Warnf("vlt: identity %q: %v\n", s.name, err)
+			continue
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.displayID(), s.name, id.FullName(), id.Email)
+	}
+
+	return nil
+}
+
+// readIdentity reads the identity held by the given secret.
+func (o *VaultOptions) readIdentity(ctx context.Context, io *genericclioptions.StdioOptions, s secretWithLabels) (identity.Identity, error) {
+	v, err := o.vaultOf(ctx, io, s)
+	if err != nil {
+		return identity.Identity{}, err
+	}
+
+	value, err := v.ShowSecret(ctx, s.id)
+	if err != nil {
+		return identity.Identity{}, err
+	}
+
+	return identity.Parse(value)
+}
+
+// IdentityShowOptions holds data required to run the command.
+type IdentityShowOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	reveal bool // reveal prints the identity numbers unmasked.
+}
+
+var _ genericclioptions.CmdOptions = &IdentityShowOptions{}
+
+// NewIdentityShowOptions initializes the options struct.
+func NewIdentityShowOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *IdentityShowOptions {
+	return &IdentityShowOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*IdentityShowOptions) Complete() error { return nil }
+
+func (*IdentityShowOptions) Validate() error { return nil }
+
+func (o *IdentityShowOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &IdentityError{retErr}
+			return
+		}
+	}()
+
+	name := args[0]
+
+	secrets, err := o.searchAll(ctx, o.StdioOptions, &SearchableOptions{Name: name, Labels: []string{identity.Label}})
+	if err != nil {
+		return err
+	}
+
+	secrets = slices.DeleteFunc(secrets, func(s secretWithLabels) bool {
+		return s.name != name || !slices.Contains(s.labels, identity.Label)
+	})
+
+	switch {
+	case len(secrets) == 0:
+		return fmt.Errorf("%q: %w", name, vaulterrors.ErrSearchNoMatch)
+	case len(secrets) > 1:
+		return fmt.Errorf("%q: %w", name, vaulterrors.ErrAmbiguousSecretMatch)
+	}
+
+	id, err := o.readIdentity(ctx, o.StdioOptions, secrets[0])
+	if err != nil {
+		return err
+	}
+
+	printIdentity(o.Out, id, o.reveal)
+
+	return nil
+}
+
+// printIdentity writes the identity as a two-column layout, omitting empty
+// fields. Identity numbers are masked up to their last 4 characters,
+// unless reveal is set.
+func printIdentity(w io.Writer, id identity.Identity, reveal bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	row := func(title string, lines ...string) {
+		lines = slices.DeleteFunc(lines, func(l string) bool { return len(l) == 0 })
+		for i, l := range lines {
+			if i > 0 {
+				title = ""
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\n", title, l)
+		}
+	}
+
+	number := func(n string) string {
+		if reveal || len(n) == 0 {
+			return n
+		}
+
+		return maskTail(n)
+	}
+
+	passport := number(id.PassportNumber)
+	if len(passport) > 0 && len(id.PassportExpiry) > 0 {
+		passport += " (expires " + id.PassportExpiry + ")"
+	}
+
+	row("Name", id.FullName())
+	row("Birth date", id.BirthDate)
+	row("Email", id.Email)
+	row("Phone", id.Phone)
+	row("Company", id.Company)
+	row("Address", append(strings.Split(id.Address, "\n"), id.Locality(), id.Country)...)
+	row("National ID", number(id.NationalID))
+	row("Passport", passport)
+	row("License", number(id.LicenseNumber))
+}
+
+// maskTail masks s up to its last 4 characters, e.g., "•••• 6789".
+func maskTail(s string) string {
+	r := []rune(s)
+	if len(r) <= 4 {
+		return "••••"
+	}
+
+	return "•••• " + string(r[len(r)-4:])
+}
+
+// NewCmdIdentityAdd creates the identity add cobra command.
+func NewCmdIdentityAdd(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewIdentityAddOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a personal identity to the vault",
+		Long: `Add a personal identity to the vault.
+
+Each field is prompted for, and may be skipped. If input is piped or
+redirected, the identity is read as a JSON object instead,
+e.g., {"first_name": "Jane", "last_name": "Doe", "birth_date": "1990-01-31"}.
+
+Identities must have a name, and dates be given as YYYY-MM-DD.`,
+		Example: `  # Add an identity, prompting for its fields
+  vlt identity add --name me
+
+  # Add an identity read from a JSON object
+  vlt identity add --name me < me.json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.name, "name", "", "", "the identity name (e.g., me)")
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "optional label to associate with the identity (comma-separated or repeated)")
+
+	return cmd
+}
+
+// NewCmdIdentityList creates the identity list cobra command.
+func NewCmdIdentityList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewIdentityListOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the personal identities",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
+
+// NewCmdIdentityShow creates the identity show cobra command.
+func NewCmdIdentityShow(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewIdentityShowOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "show NAME",
+		Short: "Show a personal identity, formatted",
+		Long: `Show the personal identity with exactly the given name, formatted.
+
+National ID, passport and license numbers are masked up to their last
+4 characters, unless --reveal is set.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.reveal, "reveal", "r", false, "show the identity numbers unmasked")
+
+	return cmd
+}
@@ -2,17 +2,27 @@ package cli
 
 import (
 	"context"
-	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 
+	"github.com/ladzaretti/vlt-cli/archive"
 	"github.com/ladzaretti/vlt-cli/clierror"
 	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/gpg"
+	"github.com/ladzaretti/vlt-cli/importer"
+	"github.com/ladzaretti/vlt-cli/input"
+	"github.com/ladzaretti/vlt-cli/kdbx"
+	"github.com/ladzaretti/vlt-cli/plugin"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
 
 	"github.com/spf13/cobra"
 )
@@ -25,64 +35,25 @@ func (e *ImportError) Error() string { return "import: " + e.Err.Error() }
 
 func (e *ImportError) Unwrap() error { return e.Err }
 
-const (
-	// firefoxHeader defines the expected CSV header for exported Firefox passwords.
-	firefoxHeader = "url,username,password,httpRealm,formActionOrigin,guid,timeCreated,timeLastUsed,timePasswordChanged"
-
-	// chromiumHeader defines the expected CSV header for exported Chromium passwords.
-	chromiumHeader = "name,url,username,password,note"
-)
-
-var (
-	// firefoxImporter is a custom password importer for exported Firefox password data.
-	firefoxImporter = CustomImporter{
-		NameIndex:    ptr(1),
-		SecretIndex:  ptr(2),
-		LabelIndexes: []int{0, 3, 4},
-	}
-
-	// chromiumImporter is a custom password importer for exported Chromium password data.
-	chromiumImporter = CustomImporter{
-		NameIndex:    ptr(2),
-		SecretIndex:  ptr(3),
-		LabelIndexes: []int{0, 1, 4},
-	}
-
-	// vltImporter is a password importer for exported vlt password data.
-	vltImporter = VltImporter{}
-)
-
-type VltImporter struct{}
-
-var _ Importer = VltImporter{}
-
-func (VltImporter) validate(record []string) error {
-	if len(record) != 3 {
-		return &ImportError{errors.New("expected 3 fields per record for vlt csv record")}
-	}
-
-	return nil
-}
-
-func (VltImporter) convert(record []string) secret {
-	// assumes validate has run.
-	// panicking on out-of-bounds access is acceptable in this context.
-	return secret{
-		name:   record[0],
-		secret: record[1],
-		labels: strings.Split(record[2], ","),
-	}
-}
-
-type secret struct {
-	name   string
-	secret string
-	labels []string
-}
-
-type Importer interface {
-	convert(record []string) secret
-	validate(record []string) error
+// vltFormat is the import format of exported vlt password data.
+var vltFormat = importer.CSVFormat("vlt", "vlt CSV export ('vlt export')", vltExportHeader, importer.CSVColumns{
+	Name:     0,
+	Secret:   1,
+	Labels:   []int{2},
+	LabelSep: ",",
+})
+
+// importFormatPass is the pass(1) password store format, imported from
+// a directory rather than a file, see [ImportOptions.importPass].
+const importFormatPass = "pass"
+
+// newImportFormats returns the registry of the import formats
+// auto-detected or selected using --format.
+func newImportFormats() *importer.Registry {
+	r := importer.NewDefaultRegistry()
+	r.Register(vltFormat)
+
+	return r
 }
 
 // CustomImporter defines custom column indexes used to extract fields from a CSV row.
@@ -92,50 +63,21 @@ type CustomImporter struct {
 	LabelIndexes []int `json:"labels,omitempty"` // LabelIndexes are the indexes of the label columns.
 }
 
-var _ Importer = CustomImporter{}
-
-func (ic CustomImporter) validate(record []string) error {
+// columns returns the CSV columns selected by the custom indexes.
+func (ic CustomImporter) columns() (importer.CSVColumns, error) {
 	if ic.NameIndex == nil {
-		return errors.New("name index is not set")
+		return importer.CSVColumns{}, errors.New("name index is not set")
 	}
 
 	if ic.SecretIndex == nil {
-		return errors.New("secret index is not set")
+		return importer.CSVColumns{}, errors.New("secret index is not set")
 	}
 
-	if *ic.NameIndex >= len(record) {
-		return fmt.Errorf("name index %d is out of range (record has %d columns)", *ic.NameIndex, len(record))
-	}
-
-	if *ic.SecretIndex >= len(record) {
-		return fmt.Errorf("secret index %d is out of range (record has %d columns)", *ic.SecretIndex, len(record))
-	}
-
-	for _, index := range ic.LabelIndexes {
-		if index >= len(record) {
-			return fmt.Errorf("label index %d is out of range (record has %d columns)", index, len(record))
-		}
-	}
-
-	return nil
-}
-
-func (ic CustomImporter) convert(record []string) secret {
-	// safe to dereference since validate is expected to run first.
-	s := secret{
-		name:   record[*ic.NameIndex],
-		secret: record[*ic.SecretIndex],
-		labels: make([]string, 0, len(ic.LabelIndexes)),
-	}
-
-	for _, labelIndex := range ic.LabelIndexes {
-		label := record[labelIndex]
-		if len(label) > 0 {
-			s.labels = append(s.labels, label)
-		}
-	}
-
-	return s
+	return importer.CSVColumns{
+		Name:   *ic.NameIndex,
+		Secret: *ic.SecretIndex,
+		Labels: ic.LabelIndexes,
+	}, nil
 }
 
 func (ic CustomImporter) String() string {
@@ -152,27 +94,138 @@ func (ic CustomImporter) String() string {
 	return fmt.Sprintf(`{"name": %s, "secret": %s, "labels": %v}`, name, secret, ic.LabelIndexes)
 }
 
-func ptr[T any](v T) *T {
-	return &v
-}
-
 type ImportOptions struct {
 	*genericclioptions.StdioOptions
 	*VaultOptions
 
 	CSVPath string
+	pathArg string // pathArg is the input file path given as an argument.
 	indexes string
+	format  string
+	plugin  string
+
+	namePrefix  string // namePrefix prefixes the names of the imported secrets.
+	labelPrefix string // labelPrefix prefixes the labels of the imported secrets.
+	dryRun      bool   // dryRun lists the secrets that would be imported instead.
 
 	importConfig CustomImporter
+	formats      *importer.Registry
 }
 
 var _ genericclioptions.CmdOptions = &ImportOptions{}
 
 // NewImportOptions initializes the options struct.
 func NewImportOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *ImportOptions {
-	return &ImportOptions{
+	o := &ImportOptions{
 		StdioOptions: stdio,
 		VaultOptions: vaultOptions,
+		formats:      newImportFormats(),
+	}
+
+	o.formats.Register(o.archiveFormat())
+	o.formats.Register(o.keepassFormat())
+
+	return o
+}
+
+// archiveFormat returns the import format of vltx archives, prompting for
+// the archive password, see 'vlt export --format vltx'.
+func (o *ImportOptions) archiveFormat() importer.Format {
+	return importer.Format{
+		Name:        exportFormatVLTX,
+		Description: "vlt encrypted archive ('vlt export --format vltx')",
+		Sniff:       archive.Sniff,
+		Parse: func(r io.Reader) ([]importer.Record, error) {
+			if o.NonInteractive {
+				return nil, vaulterrors.ErrNonInteractiveUnsupported
+			}
+
+			password, err := input.PromptReadSecure(o.Out, int(o.In.Fd()), "Archive password: ")
+			if err != nil {
+				return nil, err
+			}
+
+			a, err := archive.Read(r, []byte(password))
+			if err != nil {
+				return nil, err
+			}
+
+			records := make([]importer.Record, 0, len(a.Secrets))
+			for _, s := range a.Secrets {
+				records = append(records, importer.Record{
+					Name:     s.Name,
+					Secret:   s.Value,
+					Labels:   s.Labels,
+					URL:      s.URL,
+					Username: s.Username,
+					Notes:    s.Notes,
+					Kind:     s.Kind,
+				})
+			}
+
+			return records, nil
+		},
+	}
+}
+
+// keepassFormat returns the import format of KeePass databases, prompting
+// for the database password. Groups are imported as labels, e.g., "Internet/Email".
+func (o *ImportOptions) keepassFormat() importer.Format {
+	return importer.Format{
+		Name:        "keepass",
+		Description: "KeePass KDBX 3.1/4 database, protected by a password only",
+		Sniff:       kdbx.Sniff,
+		Parse: func(r io.Reader) ([]importer.Record, error) {
+			if o.NonInteractive {
+				return nil, vaulterrors.ErrNonInteractiveUnsupported
+			}
+
+			password, err := input.PromptReadSecure(o.Out, int(o.In.Fd()), "KeePass password: ")
+			if err != nil {
+				return nil, err
+			}
+
+			entries, err := kdbx.Read(r, []byte(password))
+			if err != nil {
+				return nil, err
+			}
+
+			records := make([]importer.Record, 0, len(entries))
+			for _, e := range entries {
+				if rec, ok := keepassRecord(e); ok {
+					records = append(records, rec)
+				}
+			}
+
+			return records, nil
+		},
+	}
+}
+
+// keepassRecord maps a KeePass entry to a record. Entries holding no password
+// are imported as notes, entries holding neither are skipped.
+func keepassRecord(e kdbx.Entry) (importer.Record, bool) {
+	name := e.Title
+	if len(name) == 0 {
+		name = e.UserName
+	}
+
+	var labels []string
+	if len(e.Groups) > 0 {
+		labels = append(labels, strings.Join(e.Groups, "/"))
+	}
+
+	labels = append(labels, e.Tags...)
+
+	switch {
+	case len(name) == 0:
+		return importer.Record{}, false
+	case len(e.Password) > 0:
+		return importer.Record{Name: name, Secret: e.Password, Labels: labels, URL: e.URL, Username: e.UserName, Notes: e.Notes}, true
+	case len(e.Notes) > 0:
+		return importer.Record{Name: name, Secret: e.Notes, Labels: labels, URL: e.URL, Username: e.UserName}, true
+	default:
+		return importer.Record{}, false
 	}
 }
 
@@ -183,12 +236,47 @@ func (o *ImportOptions) Complete() error {
 		}
 	}
 
+	if len(o.pathArg) > 0 {
+		if len(o.CSVPath) > 0 {
+			return &ImportError{errors.New("the input file is given both as an argument and using --path")}
+		}
+
+		o.CSVPath = o.pathArg
+	}
+
+	if o.format == importFormatPass && len(o.CSVPath) == 0 {
+		dir, err := passStoreDir(nil)
+		if err != nil {
+			return &ImportError{err}
+		}
+
+		o.CSVPath = dir
+	}
+
 	return nil
 }
 
 func (o *ImportOptions) Validate() error {
 	if !o.NonInteractive && len(o.CSVPath) == 0 {
-		return &ImportError{errors.New("no path provided; use --path to specify the input file")}
+		return &ImportError{errors.New("no path provided; specify the input file as an argument")}
+	}
+
+	selected := 0
+
+	for _, s := range []string{o.indexes, o.format, o.plugin} {
+		if len(s) > 0 {
+			selected++
+		}
+	}
+
+	if selected > 1 {
+		return &ImportError{errors.New("--format, --indexes and --plugin cannot be used together")}
+	}
+
+	if len(o.format) > 0 && o.format != importFormatPass {
+		if len(o.formats.Provider(o.format).Formats()) == 0 {
+			return &ImportError{fmt.Errorf("unknown format %q (formats: %s)", o.format, strings.Join(append(o.formats.Names(), importFormatPass), ", "))}
+		}
 	}
 
 	return nil
@@ -202,6 +290,47 @@ func (o *ImportOptions) Run(ctx context.Context, _ ...string) (retErr error) {
 		}
 	}()
 
+	var (
+		records []importer.Record
+		err     error
+	)
+
+	if o.format == importFormatPass {
+		records, err = o.importPass(ctx, o.CSVPath)
+	} else {
+		records, err = o.importFile(ctx)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if o.dryRun {
+		o.printDryRun(records)
+		return nil
+	}
+
+	for i, r := range records {
+		r = r.Prefixed(o.namePrefix, o.labelPrefix)
+
+		meta := vaultdb.SecretMeta{URL: r.URL, Username: r.Username, Notes: r.Notes, Kind: r.Kind}
+
+		if _, err := o.vault.InsertSecretWithMeta(ctx, r.Name, r.Secret, r.Labels, meta); err != nil {
+			return fmt.Errorf("record %d (%s): %w", i+1, r.Name, err)
+		}
+	}
+
+	o.Infof("successfully imported %d records.\n", len(records))
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		o.Warnf("Post-write hook failed: %v", err)
+	}
+
+	return nil
+}
+
+// importFile returns the records of the input file, or of stdin if non-interactive.
+func (o *ImportOptions) importFile(ctx context.Context) ([]importer.Record, error) {
 	var in io.Reader
 
 	if o.NonInteractive {
@@ -211,7 +340,7 @@ func (o *ImportOptions) Run(ctx context.Context, _ ...string) (retErr error) {
 	if len(o.CSVPath) > 0 {
 		f, err := os.Open(o.CSVPath)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		defer func() { //nolint:wsl
 			_ = f.Close()
@@ -220,66 +349,157 @@ func (o *ImportOptions) Run(ctx context.Context, _ ...string) (retErr error) {
 		in = f
 	}
 
-	r := csv.NewReader(in)
-
-	header, err := r.Read()
-	if err != nil {
-		return err
+	if len(o.plugin) > 0 {
+		return o.importPlugin(ctx, in)
 	}
 
-	importer := o.importerForHeader(strings.Join(header, ","))
-	if err := importer.validate(header); err != nil {
-		return err
-	}
+	return o.importFormat(in)
+}
 
-	i := 0
-	for {
-		record, err := r.Read()
-		if err == io.EOF {
-			break
-		}
+// importPass returns the records of the entries of the given pass(1) password
+// store, decrypted using gpg. Hidden files and directories, e.g., .git, are skipped.
+func (o *ImportOptions) importPass(ctx context.Context, dir string) ([]importer.Record, error) {
+	var records []importer.Record
 
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		s := importer.convert(record)
+		hidden := path != dir && strings.HasPrefix(d.Name(), ".")
+
+		switch {
+		case d.IsDir() && hidden:
+			return filepath.SkipDir
+		case d.IsDir() || hidden || filepath.Ext(path) != importer.PassExt:
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
 
-		if _, err := o.vault.InsertNewSecret(ctx, s.name, s.secret, s.labels); err != nil {
+		encrypted, err := os.ReadFile(path) //nolint:gosec // walked store entry.
+		if err != nil {
 			return err
 		}
 
-		i++
-	}
+		content, err := gpg.Decrypt(ctx, encrypted)
+		if err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		}
 
-	o.Infof("successfully imported %d records.\n", i)
+		r, ok := importer.PassRecord(filepath.ToSlash(rel), string(content))
+		if !ok {
+			o.Warnf("Skipping empty entry %s.\n", rel)
+			return nil
+		}
 
-	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
-		o.Warnf("Post-write hook failed: %v", err)
+		records = append(records, r)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return records, nil
 }
 
-//nolint:ireturn
-func (o *ImportOptions) importerForHeader(header string) Importer {
-	switch header {
-	case firefoxHeader:
-		o.Infof("firefox export file detected.\n")
-		return firefoxImporter
+// printDryRun prints the secrets the given records would be imported as.
+func (o *ImportOptions) printDryRun(records []importer.Record) {
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
 
-	case chromiumHeader:
-		o.Infof("chromium export file detected.\n")
-		return chromiumImporter
+	fmt.Fprintln(tw, "NAME\tLABELS\tUSERNAME\tURL")
 
-	case vltExportHeader:
-		o.Infof("vlt export file detected.\n")
-		return vltImporter
+	for _, r := range records {
+		r = r.Prefixed(o.namePrefix, o.labelPrefix)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Name, strings.Join(r.Labels, ","), r.Username, r.URL)
+	}
 
-	default:
+	_ = tw.Flush()
+
+	o.Infof("\n%d records would be imported, nothing was written (dry run).\n", len(records))
+}
+
+// importFormat returns the records of the given input, parsed using the
+// format selected by --format or --indexes, or detected otherwise.
+func (o *ImportOptions) importFormat(in io.Reader) ([]importer.Record, error) {
+	if len(o.indexes) > 0 {
 		o.Debugf("using custom import config: %s\n", o.importConfig)
-		return o.importConfig
+
+		cols, err := o.importConfig.columns()
+		if err != nil {
+			return nil, err
+		}
+
+		return importer.ParseCSV(in, cols)
+	}
+
+	if len(o.format) > 0 {
+		// providers of several formats, e.g., bitwarden, are selected by name.
+		if f, ok := o.formats.Lookup(o.format); ok && len(o.formats.Provider(o.format).Formats()) == 1 {
+			o.Debugf("using the %s import format\n", f.Name)
+			return f.Parse(in)
+		}
+
+		f, in, err := o.formats.Provider(o.format).Detect(in)
+		if err != nil {
+			return nil, err
+		}
+
+		o.Debugf("using the %s import format\n", f.Name)
+
+		return f.Parse(in)
+	}
+
+	f, in, err := o.formats.Detect(in)
+	if err != nil {
+		return nil, fmt.Errorf("%w; select one using --format, or use --indexes for other CSV files", err)
+	}
+
+	o.Infof("%s export file detected.\n", f.Name)
+
+	return f.Parse(in)
+}
+
+// importPlugin returns the records the importer plugin converts the given input to.
+func (o *ImportOptions) importPlugin(ctx context.Context, in io.Reader) ([]importer.Record, error) {
+	p, err := plugin.Find(o.plugin)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
 	}
+
+	o.Infof("importing using the %s plugin.\n", p.Path)
+
+	secrets, err := p.Import(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]importer.Record, 0, len(secrets))
+	for _, s := range secrets {
+		records = append(records, importer.Record{Name: s.Name, Secret: s.Secret, Labels: s.Labels})
+	}
+
+	return records, nil
+}
+
+// formatsUsage returns the help text listing the given import formats.
+func formatsUsage(r *importer.Registry) string {
+	var sb strings.Builder
+
+	for _, f := range r.Formats() {
+		fmt.Fprintf(&sb, "    %s: %s\n", f.Name, f.Description)
+	}
+
+	return sb.String()
 }
 
 // NewCmdImport creates the import cobra command.
@@ -290,33 +510,79 @@ func NewCmdImport(defaults *DefaultVltOptions) *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:   "import",
-		Short: "Import secrets from a CSV file",
-		Long: `Import secrets into the vault from a CSV file.
-
-The input must be a CSV file with at least two columns: one for the secret's name and one for its value (e.g., password). 
+		Use:   "import [FILE|DIR]",
+		Short: "Import secrets from a file",
+		Long: `Import secrets into the vault from a file exported by a password manager.
+
+The format of the file is auto-detected, or selected using --format, or --from.
+Formats of the same provider, e.g., bitwarden and bitwarden-csv, are detected
+among those of the provider selected by its name. Supported formats:
+` + formatsUsage(o.formats) + `
+A pass(1) password store is imported from its directory using --from pass,
+decrypting its entries using gpg. DIR defaults to $PASSWORD_STORE_DIR, then to
+~/.password-store. Directories are imported as labels, e.g., "work/aws", and
+entries follow the conventional layout: the password on the first line, the
+rest being notes, where "login:" and "url:" lines set the username and URL.
+
+Other CSV files must have at least two columns: one for the secret's name and one for its value (e.g., password).
 Additional columns can be used for optional labels.
 
-Use the --indexes flag to specify how to extract each field. 
+Use the --indexes flag to specify how to extract each field.
 Indexes are zero-based and refer to column positions in the header row.
 
-Firefox and Chromium-based CSV files are auto-detected for import and do not require manual index specification.
+Other input formats are converted by importer plugins, selected using --plugin (see 'vlt plugin').
+
+Use --dry-run to list the secrets the file would be imported as, without their
+values, before importing it.
+
+Imported secrets are segregated using --prefix-name and --prefix-label, prefixing their names
+and labels. Unlabeled secrets are labeled by the label prefix itself, so that all the imported
+secrets are listed, e.g., using 'vlt find --label "imported/*"'.
 `,
 		Example: `
 # Import using Firefox-compatible format (auto-detected)
-vlt import --path passwords.csv
+vlt import passwords.csv
 
 # Import from custom CSV data using a column mapping
 echo -e "password,username,label_1,label_2\npass,some_username,meta1,meta2" | \
   vlt import \
-      --indexes '{"name":1,"secret":0,"labels":[2,3]}'`,
-		Run: func(cmd *cobra.Command, _ []string) {
+      --indexes '{"name":1,"secret":0,"labels":[2,3]}'
+
+# Import an encrypted archive, prompting for its password
+vlt import backup.vltx
+
+# Import the default pass password store
+vlt import --from pass
+
+# Import a KeePass database, prompting for its password
+vlt import --from keepass vault.kdbx
+
+# Preview the secrets a Bitwarden export would be imported as
+vlt import --from bitwarden --dry-run bitwarden.json
+
+# Import a LastPass export, segregated for review
+vlt import --prefix-name lastpass: --prefix-label imported/ lastpass.csv
+
+# Import using the 'vlt-enpass' importer plugin
+vlt import --plugin enpass export.json`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) > 0 {
+				o.pathArg = args[0]
+			}
+
 			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
 		},
 	}
 
 	cmd.Flags().StringVarP(&o.indexes, "indexes", "i", "", "json with column indexes (e.g., '{\"name\":0,\"secret\":1,\"labels\":[2]}')")
-	cmd.Flags().StringVarP(&o.CSVPath, "path", "p", "", "path to the input CSV file")
+	cmd.Flags().StringVarP(&o.CSVPath, "path", "p", "", "path to the input file, same as the FILE argument")
+	cmd.Flags().StringVarP(&o.format, "format", "", "", fmt.Sprintf("format of the input file, instead of detecting it (one of: %s)", strings.Join(append(o.formats.Names(), importFormatPass), ", ")))
+	cmd.Flags().StringVarP(&o.format, "from", "", "", "same as --format (e.g., '--from bitwarden')")
+	cmd.Flags().BoolVarP(&o.dryRun, "dry-run", "", false, "list the secrets that would be imported, without importing them")
+	cmd.Flags().StringVarP(&o.plugin, "plugin", "", "", "name of the importer plugin converting the input file (see 'vlt plugin')")
+	cmd.Flags().StringVarP(&o.namePrefix, "prefix-name", "", "", "prefix of the imported secret names (e.g., 'lastpass:')")
+	cmd.Flags().StringVarP(&o.labelPrefix, "prefix-label", "", "", "prefix of the imported secret labels, or the label of unlabeled ones (e.g., 'imported/')")
 
 	return cmd
 }
@@ -0,0 +1,519 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/keepassxc"
+	"github.com/ladzaretti/vlt-cli/vaultserver"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// defaultPairingTTL is how long 'vlt keepassxc pair' waits for an extension to associate.
+	defaultPairingTTL = 2 * time.Minute
+
+	// keepassxcProxyName is the name of the script browsers run as the native messaging host.
+	keepassxcProxyName = "vlt-keepassxc-proxy"
+)
+
+// keepassxcManifestDirs maps browsers to their native messaging manifest
+// directories, relative to the home directory, on linux and darwin.
+var keepassxcManifestDirs = map[string][2]string{
+	"firefox":  {".mozilla/native-messaging-hosts", "Library/Application Support/Mozilla/NativeMessagingHosts"},
+	"chrome":   {".config/google-chrome/NativeMessagingHosts", "Library/Application Support/Google/Chrome/NativeMessagingHosts"},
+	"chromium": {".config/chromium/NativeMessagingHosts", "Library/Application Support/Chromium/NativeMessagingHosts"},
+	"brave":    {".config/BraveSoftware/Brave-Browser/NativeMessagingHosts", "Library/Application Support/BraveSoftware/Brave-Browser/NativeMessagingHosts"},
+}
+
+var (
+	// keepassxcFirefoxExtensions are the ids of the KeePassXC-Browser Firefox extension.
+	keepassxcFirefoxExtensions = []string{"keepassxc-browser@keepassxc.org"}
+
+	// keepassxcChromiumOrigins are the origins of the KeePassXC-Browser extension in the Chrome and Edge stores.
+	keepassxcChromiumOrigins = []string{
+		"chrome-extension://oboonakemofpalcgghocfoadofidjkkk/",
+		"chrome-extension://pdffhmdngciaglkoonimfcmckehcpafo/",
+	}
+)
+
+type KeePassXCError struct {
+	Err error
+}
+
+func (e *KeePassXCError) Error() string { return "keepassxc: " + e.Err.Error() }
+
+func (e *KeePassXCError) Unwrap() error { return e.Err }
+
+// NewCmdKeePassXC creates the keepassxc cobra command with its sub-commands.
+func NewCmdKeePassXC(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keepassxc",
+		Short: "Serve the vault to KeePassXC-Browser extensions (subcommands available)",
+		Long: `Serve the vault to the KeePassXC-Browser extensions, in place of KeePassXC.
+
+The browser runs vlt as its native messaging host ('vlt keepassxc proxy'),
+registered using 'vlt keepassxc install'. Since the browser cannot enter the
+vault password, log in beforehand ('vlt login') for the extension to connect.
+
+Extensions must be associated with the vault before accessing it: run
+'vlt keepassxc pair', then click 'Connect' in the extension.
+
+Secrets are matched to pages by labels holding URLs, such as the ones added by
+importing browser passwords ('vlt import'): a label matches pages of the same
+host and of its subdomains. Logins saved by the extension are stored as secrets
+named by the login and labeled by the page origin.`,
+	}
+
+	cmd.AddCommand(NewCmdKeePassXCProxy(defaults))
+	cmd.AddCommand(NewCmdKeePassXCInstall(defaults))
+	cmd.AddCommand(NewCmdKeePassXCPair(defaults))
+	cmd.AddCommand(NewCmdKeePassXCList(defaults))
+	cmd.AddCommand(NewCmdKeePassXCRevoke(defaults))
+
+	return cmd
+}
+
+// KeePassXCProxyOptions holds data required to run the command.
+type KeePassXCProxyOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &KeePassXCProxyOptions{}
+
+// NewKeePassXCProxyOptions initializes the options struct.
+func NewKeePassXCProxyOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *KeePassXCProxyOptions {
+	return &KeePassXCProxyOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*KeePassXCProxyOptions) Complete() error { return nil }
+
+func (*KeePassXCProxyOptions) Validate() error { return nil }
+
+func (o *KeePassXCProxyOptions) Run(ctx context.Context, _ ...string) error {
+	o.Debugf("vlt: serving keepassxc-browser for vault %s on stdio\n", o.path)
+
+	if err := vaultserver.New(o.vault).ServeKeePassXC(ctx, o.In, o.Out); err != nil {
+		return &KeePassXCError{err}
+	}
+
+	return nil
+}
+
+// NewCmdKeePassXCProxy creates the keepassxc proxy cobra command.
+func NewCmdKeePassXCProxy(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewKeePassXCProxyOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "proxy",
+		Short: "Serve the KeePassXC-Browser protocol on stdin and stdout",
+		Long: `Serve the KeePassXC-Browser native messaging protocol on stdin and stdout.
+
+Run by the browser, as registered by 'vlt keepassxc install'.
+Arguments passed by the browser are ignored.`,
+		Args: cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
+
+// KeePassXCInstallOptions holds data required to run the command.
+type KeePassXCInstallOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	flags    *Flags
+	browsers []string
+}
+
+var _ genericclioptions.CmdOptions = &KeePassXCInstallOptions{}
+
+// NewKeePassXCInstallOptions initializes the options struct.
+func NewKeePassXCInstallOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, flags *Flags) *KeePassXCInstallOptions {
+	return &KeePassXCInstallOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		flags:        flags,
+		browsers:     []string{"firefox", "chrome", "chromium"},
+	}
+}
+
+func (*KeePassXCInstallOptions) Complete() error { return nil }
+
+func (o *KeePassXCInstallOptions) Validate() error {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		return &KeePassXCError{fmt.Errorf("install is not supported on %s", runtime.GOOS)}
+	}
+
+	for _, b := range o.browsers {
+		if _, ok := keepassxcManifestDirs[b]; !ok {
+			return &KeePassXCError{fmt.Errorf("unsupported browser %q", b)}
+		}
+	}
+
+	return nil
+}
+
+func (o *KeePassXCInstallOptions) Run(_ context.Context, _ ...string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return &KeePassXCError{err}
+	}
+
+	proxy, err := o.writeProxy()
+	if err != nil {
+		return &KeePassXCError{err}
+	}
+
+	o.Infof("Wrote native messaging host %s\n", proxy)
+
+	for _, b := range o.browsers {
+		dir := keepassxcManifestDirs[b][0]
+		if runtime.GOOS == "darwin" {
+			dir = keepassxcManifestDirs[b][1]
+		}
+
+		path := filepath.Join(home, dir, keepassxc.HostName+".json")
+
+		if err := writeKeePassXCManifest(path, proxy, b == "firefox"); err != nil {
+			return &KeePassXCError{fmt.Errorf("%s: %w", b, err)}
+		}
+
+		o.Infof("Registered it for %s at %s\n", b, path)
+	}
+
+	return nil
+}
+
+// writeProxy writes the script browsers run, which runs 'vlt keepassxc proxy'
+// using the current vault and configuration files, returning its path.
+func (o *KeePassXCInstallOptions) writeProxy() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	vaultPath, err := filepath.Abs(o.path)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{shellQuote(exe), "--file", shellQuote(vaultPath)}
+
+	if len(o.flags.configPath) > 0 {
+		configPath, err := filepath.Abs(o.flags.configPath)
+		if err != nil {
+			return "", err
+		}
+
+		args = append(args, "--config", shellQuote(configPath))
+	}
+
+	dir, err := userDataDir()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "vlt", keepassxcProxyName)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+
+	script := "#!/bin/sh\nexec " + strings.Join(args, " ") + " keepassxc proxy \"$@\"\n"
+
+	if err := os.WriteFile(path, []byte(script), 0o700); err != nil { //nolint:gosec // the script must be executable.
+		return "", err
+	}
+
+	return path, nil
+}
+
+// writeKeePassXCManifest writes the native messaging manifest registering proxy.
+func writeKeePassXCManifest(path string, proxy string, firefox bool) error {
+	manifest := map[string]any{
+		"name":        keepassxc.HostName,
+		"description": "vlt integration with KeePassXC-Browser",
+		"path":        proxy,
+		"type":        "stdio",
+	}
+
+	if firefox {
+		manifest["allowed_extensions"] = keepassxcFirefoxExtensions
+	} else {
+		manifest["allowed_origins"] = keepassxcChromiumOrigins
+	}
+
+	b, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:gosec // browser directory.
+		return err
+	}
+
+	return os.WriteFile(path, append(b, '\n'), 0o600)
+}
+
+// userDataDir returns $XDG_DATA_HOME, falling back to ~/.local/share.
+func userDataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); len(dir) > 0 {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// shellQuote returns s quoted for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// NewCmdKeePassXCInstall creates the keepassxc install cobra command.
+func NewCmdKeePassXCInstall(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewKeePassXCInstallOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.cliFlags)
+
+	browsers := slices.Sorted(func(yield func(string) bool) {
+		for b := range keepassxcManifestDirs {
+			if !yield(b) {
+				return
+			}
+		}
+	})
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Register vlt as the KeePassXC-Browser native messaging host",
+		Long: fmt.Sprintf(`Register vlt as the native messaging host of the KeePassXC-Browser extensions.
+
+A script running 'vlt keepassxc proxy' on the current vault (and configuration
+file, if set) is written to $XDG_DATA_HOME/vlt/%s, and registered in the
+native messaging manifests of the given browsers, replacing KeePassXC.
+
+Supported browsers: %s. Only linux and macOS are supported.`, keepassxcProxyName, strings.Join(browsers, ", ")),
+		Example: `  # Register vlt for Firefox
+  vlt keepassxc install --browser firefox`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&o.browsers, "browser", "", o.browsers, "browsers to register vlt for (comma-separated or repeated)")
+
+	return cmd
+}
+
+// KeePassXCPairOptions holds data required to run the command.
+type KeePassXCPairOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	name string
+	ttl  time.Duration
+}
+
+var _ genericclioptions.CmdOptions = &KeePassXCPairOptions{}
+
+// NewKeePassXCPairOptions initializes the options struct.
+func NewKeePassXCPairOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *KeePassXCPairOptions {
+	return &KeePassXCPairOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		name:         "browser",
+		ttl:          defaultPairingTTL,
+	}
+}
+
+func (*KeePassXCPairOptions) Complete() error { return nil }
+
+func (o *KeePassXCPairOptions) Validate() error {
+	if len(o.name) == 0 {
+		return &KeePassXCError{errors.New("--name must not be empty")}
+	}
+
+	if o.ttl <= 0 {
+		return &KeePassXCError{fmt.Errorf("invalid --ttl value %s (must be positive)", o.ttl)}
+	}
+
+	return nil
+}
+
+func (o *KeePassXCPairOptions) Run(ctx context.Context, _ ...string) error {
+	a, err := o.vault.PairKeePassXC(ctx, o.name, o.ttl)
+	if err != nil {
+		return &KeePassXCError{err}
+	}
+
+	o.Infof("Pairing %q until %s, click 'Connect' in the KeePassXC-Browser extension.\n", a.ID, a.PairingExpiresAt.Local().Format(time.TimeOnly))
+
+	return nil
+}
+
+// NewCmdKeePassXCPair creates the keepassxc pair cobra command.
+func NewCmdKeePassXCPair(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewKeePassXCPairOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "pair",
+		Short: "Let a KeePassXC-Browser extension associate with the vault",
+		Long: `Let a KeePassXC-Browser extension associate with the vault.
+
+The first extension connecting before --ttl elapses is granted access to all
+secrets, under the given name. An existing association of the same name is replaced.`,
+		Example: `  # Pair Firefox
+  vlt keepassxc pair --name firefox`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.name, "name", "", o.name, "association name, identifying the browser")
+	cmd.Flags().DurationVarP(&o.ttl, "ttl", "", o.ttl, "how long to wait for the extension to connect")
+
+	return cmd
+}
+
+// KeePassXCListOptions holds data required to run the command.
+type KeePassXCListOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &KeePassXCListOptions{}
+
+// NewKeePassXCListOptions initializes the options struct.
+func NewKeePassXCListOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *KeePassXCListOptions {
+	return &KeePassXCListOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*KeePassXCListOptions) Complete() error { return nil }
+
+func (*KeePassXCListOptions) Validate() error { return nil }
+
+func (o *KeePassXCListOptions) Run(ctx context.Context, _ ...string) error {
+	associations, err := o.vault.KeePassXCAssociations(ctx)
+	if err != nil {
+		return &KeePassXCError{err}
+	}
+
+	if len(associations) == 0 {
+		o.Warnf("No KeePassXC-Browser associations found.\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "NAME\tSTATUS\tCREATED")
+
+	now := time.Now()
+
+	for _, a := range associations {
+		status := "associated"
+
+		switch {
+		case a.Pending() && now.Before(a.PairingExpiresAt):
+			status = "pairing until " + a.PairingExpiresAt.Local().Format(time.TimeOnly)
+		case a.Pending():
+			status = "pairing expired"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", a.ID, status, a.CreatedAt.Local().Format(time.DateTime))
+	}
+
+	fmt.Fprintln(tw) // add padding
+
+	return nil
+}
+
+// NewCmdKeePassXCList creates the keepassxc list cobra command.
+func NewCmdKeePassXCList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewKeePassXCListOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List KeePassXC-Browser associations",
+		Long:    "List KeePassXC-Browser associations, including pending ones.",
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
+
+// KeePassXCRevokeOptions holds data required to run the command.
+type KeePassXCRevokeOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &KeePassXCRevokeOptions{}
+
+// NewKeePassXCRevokeOptions initializes the options struct.
+func NewKeePassXCRevokeOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *KeePassXCRevokeOptions {
+	return &KeePassXCRevokeOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*KeePassXCRevokeOptions) Complete() error { return nil }
+
+func (*KeePassXCRevokeOptions) Validate() error { return nil }
+
+func (o *KeePassXCRevokeOptions) Run(ctx context.Context, names ...string) error {
+	n, err := o.vault.RevokeKeePassXCAssociations(ctx, names...)
+	if err != nil {
+		return &KeePassXCError{err}
+	}
+
+	if int(n) != len(names) {
+		o.Warnf("Revoked %d of %d associations, the rest were not found.\n", n, len(names))
+		return nil
+	}
+
+	o.Infof("Revoked %d associations.\n", n)
+
+	return nil
+}
+
+// NewCmdKeePassXCRevoke creates the keepassxc revoke cobra command.
+func NewCmdKeePassXCRevoke(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewKeePassXCRevokeOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "revoke NAME...",
+		Short: "Revoke KeePassXC-Browser associations",
+		Long:  "Revoke KeePassXC-Browser associations by their names, as listed by 'vlt keepassxc list'.",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
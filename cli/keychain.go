@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/keychain"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// keychainKeyLen is the length of the vault key,
+// the rest of the value cached in the Keychain is the vault nonce.
+const keychainKeyLen = 32
+
+// keychainAccount returns the Keychain account the vault key is cached under,
+// the absolute vault path.
+func (o *VaultOptions) keychainAccount() (string, error) {
+	return filepath.Abs(o.path)
+}
+
+// cacheKeychainKey caches the vault key in the Keychain, if enabled.
+// Failures are only warned about, the vault is unlocked either way.
+func (o *VaultOptions) cacheKeychainKey(ctx context.Context, io *genericclioptions.StdioOptions, key []byte, nonce []byte) {
+	if !o.keychain {
+		return
+	}
+
+	if len(key) != keychainKeyLen {
+		io.Warnf("vlt: keychain: unexpected vault key length %d, not cached\n", len(key))
+		return
+	}
+
+	account, err := o.keychainAccount()
+	if err == nil {
+		err = keychain.Store(ctx, account, slices.Concat(key, nonce))
+	}
+
+	if err != nil {
+		io.Warnf("vlt: %v\n", err)
+		return
+	}
+
+	io.Debugf("vlt: cached the vault key in the Keychain under %q\n", account)
+}
+
+// keychainKey returns the vault key cached in the Keychain, if enabled.
+// It returns a nil key if disabled, not cached, or if the read was denied.
+func (o *VaultOptions) keychainKey(ctx context.Context, io *genericclioptions.StdioOptions) (key []byte, nonce []byte) {
+	if !o.keychain {
+		return nil, nil
+	}
+
+	account, err := o.keychainAccount()
+	if err != nil {
+		io.Debugf("vlt: keychain: %v\n", err)
+		return nil, nil
+	}
+
+	value, err := keychain.Load(ctx, account)
+	if err != nil {
+		io.Debugf("vlt: no vault key in the Keychain, falling back to password: %v\n", err)
+		return nil, nil
+	}
+
+	if len(value) <= keychainKeyLen {
+		io.Warnf("vlt: keychain: malformed vault key cached under %q\n", account)
+		return nil, nil
+	}
+
+	return value[:keychainKeyLen], value[keychainKeyLen:]
+}
+
+// unlockKeychain opens the vault using the key cached in the Keychain, if
+// any, starting a session using it. A stale key, e.g., cached before the
+// master password was changed, is removed.
+//
+// It reports whether the vault was opened, the password is prompted for otherwise.
+func (o *VaultOptions) unlockKeychain(ctx context.Context, io *genericclioptions.StdioOptions, opts ...vault.Option) (bool, error) {
+	key, nonce := o.keychainKey(ctx, io)
+	if key == nil {
+		return false, nil
+	}
+
+	err := o.unlock(ctx, io, append(opts, vault.WithSessionKey(key, nonce))...)
+	if errors.Is(err, vaulterrors.ErrVaultBusy) {
+		return false, err
+	}
+
+	if err != nil {
+		io.Warnf("vlt: the vault key cached in the Keychain is stale, removing it: %v\n", err)
+		o.forgetKeychainKey(ctx, io)
+
+		return false, nil
+	}
+
+	// nil-safe: sessionClient methods handle nil receivers safely.
+	_ = o.sessionClient.Login(ctx, o.path, key, nonce, o.sessionDuration)
+
+	return true, nil
+}
+
+// forgetKeychainKey removes the vault key cached in the Keychain, if any.
+func (o *VaultOptions) forgetKeychainKey(ctx context.Context, io *genericclioptions.StdioOptions) bool {
+	account, err := o.keychainAccount()
+	if err == nil {
+		err = keychain.Delete(ctx, account)
+	}
+
+	if errors.Is(err, keychain.ErrNotFound) {
+		return false
+	}
+
+	if err != nil {
+		io.Warnf("vlt: %v\n", err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,492 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+
+	"github.com/spf13/cobra"
+)
+
+// labelColorCodes maps the supported label colors to their ANSI foreground codes.
+var labelColorCodes = map[string]string{
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"gray":    "90",
+}
+
+type LabelsError struct {
+	Err error
+}
+
+func (e *LabelsError) Error() string { return "labels: " + e.Err.Error() }
+
+func (e *LabelsError) Unwrap() error { return e.Err }
+
+// LabelsOptions holds data required to run the command.
+type LabelsOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &LabelsOptions{}
+
+// NewLabelsOptions initializes the options struct.
+func NewLabelsOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *LabelsOptions {
+	return &LabelsOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*LabelsOptions) Complete() error { return nil }
+
+func (*LabelsOptions) Validate() error { return nil }
+
+func (o *LabelsOptions) Run(ctx context.Context, _ ...string) error {
+	secrets, err := o.vault.FilterSecrets(ctx, "", "", nil)
+	if err != nil {
+		return &LabelsError{err}
+	}
+
+	metas, err := o.vault.LabelMetas(ctx)
+	if err != nil {
+		return &LabelsError{err}
+	}
+
+	counts := make(map[string]int)
+
+	for _, s := range secrets {
+		for _, l := range s.Labels {
+			counts[l]++
+		}
+	}
+
+	described := make(map[string]vaultdb.LabelMeta, len(metas))
+
+	for _, m := range metas {
+		described[m.Name] = m
+
+		if _, ok := counts[m.Name]; !ok {
+			counts[m.Name] = 0
+		}
+	}
+
+	if len(counts) == 0 {
+		o.Warnf("No labels found.\n")
+		return nil
+	}
+
+	colors := o.labelColors(ctx, o.Out)
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "LABEL\tSECRETS\tCOLOR\tDESCRIPTION")
+
+	for _, l := range slices.Sorted(maps.Keys(counts)) {
+		m := described[l]
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", labelBadge(l, colors), counts[l], m.Color, m.Description)
+	}
+
+	return nil
+}
+
+// LabelsSetOptions holds data required to run the command.
+type LabelsSetOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	description *string // description is the new label description, nil to keep the current one.
+	color       *string // color is the new label color, nil to keep the current one.
+}
+
+var _ genericclioptions.CmdOptions = &LabelsSetOptions{}
+
+// NewLabelsSetOptions initializes the options struct.
+func NewLabelsSetOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *LabelsSetOptions {
+	return &LabelsSetOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*LabelsSetOptions) Complete() error { return nil }
+
+func (o *LabelsSetOptions) Validate() error {
+	if o.description == nil && o.color == nil {
+		return &LabelsError{errors.New("nothing to set, use --description or --color")}
+	}
+
+	if o.color != nil && len(*o.color) > 0 {
+		if _, ok := labelColorCodes[*o.color]; !ok {
+			return &LabelsError{fmt.Errorf("unsupported color %q (supported: %v)", *o.color, slices.Sorted(maps.Keys(labelColorCodes)))}
+		}
+	}
+
+	return nil
+}
+
+func (o *LabelsSetOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &LabelsError{retErr}
+			return
+		}
+	}()
+
+	m := vaultdb.LabelMeta{Name: args[0]}
+
+	metas, err := o.vault.LabelMetas(ctx)
+	if err != nil {
+		return err
+	}
+
+	if i := slices.IndexFunc(metas, func(m vaultdb.LabelMeta) bool { return m.Name == args[0] }); i >= 0 {
+		m = metas[i]
+	}
+
+	if o.description != nil {
+		m.Description = *o.description
+	}
+
+	if o.color != nil {
+		m.Color = *o.color
+	}
+
+	return o.setLabelMeta(ctx, o.StdioOptions, m)
+}
+
+// LabelsUnsetOptions holds data required to run the command.
+type LabelsUnsetOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &LabelsUnsetOptions{}
+
+// NewLabelsUnsetOptions initializes the options struct.
+func NewLabelsUnsetOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *LabelsUnsetOptions {
+	return &LabelsUnsetOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*LabelsUnsetOptions) Complete() error { return nil }
+
+func (*LabelsUnsetOptions) Validate() error { return nil }
+
+func (o *LabelsUnsetOptions) Run(ctx context.Context, args ...string) error {
+	if err := o.setLabelMeta(ctx, o.StdioOptions, vaultdb.LabelMeta{Name: args[0]}); err != nil {
+		return &LabelsError{err}
+	}
+
+	return nil
+}
+
+// LabelsBulkOptions holds data required to run the command.
+type LabelsBulkOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	from   string // from is the label to rename, or a label prefix followed by '*'.
+	to     string // to is the new label, or the prefix replacing the one of from.
+	match  string // match is the glob the relabeled secret names must match.
+	dryRun bool
+}
+
+var _ genericclioptions.CmdOptions = &LabelsBulkOptions{}
+
+// NewLabelsBulkOptions initializes the options struct.
+func NewLabelsBulkOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *LabelsBulkOptions {
+	return &LabelsBulkOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*LabelsBulkOptions) Complete() error { return nil }
+
+func (o *LabelsBulkOptions) Validate() error {
+	if len(o.from) == 0 || o.from == "*" {
+		return &LabelsError{errors.New("--from must be a label, or a label prefix followed by '*'")}
+	}
+
+	if strings.ContainsAny(strings.TrimSuffix(o.from, "*"), "*?[") {
+		return &LabelsError{fmt.Errorf("--from %q: only a single trailing '*' is supported", o.from)}
+	}
+
+	if len(o.to) == 0 {
+		return &LabelsError{errors.New("--to must not be empty")}
+	}
+
+	return nil
+}
+
+// relabeled returns the new name of the given label, and whether it is renamed.
+func (o *LabelsBulkOptions) relabeled(label string) (string, bool) {
+	renamed := o.to
+
+	if prefix, ok := strings.CutSuffix(o.from, "*"); ok {
+		rest, ok := strings.CutPrefix(label, prefix)
+		if !ok {
+			return "", false
+		}
+
+		renamed += rest
+	} else if label != o.from {
+		return "", false
+	}
+
+	return renamed, renamed != label
+}
+
+func (o *LabelsBulkOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &LabelsError{retErr}
+			return
+		}
+	}()
+
+	secrets, err := o.vault.FilterSecrets(ctx, "", o.match, nil)
+	if err != nil {
+		return err
+	}
+
+	var (
+		changes = make([]vault.LabelChange, 0, len(secrets))
+		renames int
+	)
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+
+	fmt.Fprintln(tw, "ID\tNAME\tOLD\tNEW")
+
+	for _, id := range slices.Sorted(maps.Keys(secrets)) {
+		s, c := secrets[id], vault.LabelChange{ID: id}
+
+		for _, l := range s.Labels {
+			renamed, ok := o.relabeled(l)
+			if !ok {
+				continue
+			}
+
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", id, s.Name, l, renamed)
+			renames++
+
+			c.Remove = append(c.Remove, l)
+			c.Add = append(c.Add, renamed)
+		}
+
+		if len(c.Remove) > 0 {
+			changes = append(changes, c)
+		}
+	}
+
+	if len(changes) == 0 {
+		o.Infof("No labels to relabel.\n")
+		return nil
+	}
+
+	if o.dryRun {
+		o.Infof("Would relabel %d labels of %d secrets:\n\n", renames, len(changes))
+		return tw.Flush()
+	}
+
+	if err := o.vault.RelabelSecrets(ctx, changes); err != nil {
+		return err
+	}
+
+	o.Infof("Relabeled %d labels of %d secrets:\n\n", renames, len(changes))
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	return genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite)
+}
+
+// setLabelMeta sets the given label metadata, then runs the post-write hook.
+func (o *VaultOptions) setLabelMeta(ctx context.Context, io *genericclioptions.StdioOptions, m vaultdb.LabelMeta) error {
+	if err := o.vault.SetLabelMeta(ctx, m); err != nil {
+		return err
+	}
+
+	return genericclioptions.RunHook(ctx, io, o.hooks.postWrite)
+}
+
+// labelColors returns the configured label colors, mapped by label name,
+// if colored output is written to w: w is a terminal, and NO_COLOR is unset.
+//
+// Colors are cosmetic, nil is returned if they cannot be read.
+func (o *VaultOptions) labelColors(ctx context.Context, w io.Writer) map[string]string {
+	if _, ok := terminalFd(w); !ok || len(os.Getenv("NO_COLOR")) > 0 {
+		return nil
+	}
+
+	metas, err := o.vault.LabelMetas(ctx)
+	if err != nil {
+		return nil
+	}
+
+	colors := make(map[string]string, len(metas))
+
+	for _, m := range metas {
+		if code, ok := labelColorCodes[m.Color]; ok {
+			colors[m.Name] = code
+		}
+	}
+
+	return colors
+}
+
+// labelBadge returns the label, colored using the given label colors, if any.
+//
+// Uncolored labels are wrapped in escape sequences of the same width as colored
+// ones, so that columns written using a [tabwriter.Writer] remain aligned.
+func labelBadge(label string, colors map[string]string) string {
+	if len(colors) == 0 {
+		return label
+	}
+
+	code, ok := colors[label]
+	if !ok {
+		code = "39" // default foreground color
+	}
+
+	return "\x1b[" + code + "m" + label + "\x1b[0m"
+}
+
+// NewCmdLabels creates the labels cobra command.
+func NewCmdLabels(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewLabelsOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:     "labels",
+		Aliases: []string{"label"},
+		Short:   "List the labels, along with their descriptions (subcommands available)",
+		Long: `List the labels of the vault secrets, along with the number of secrets
+having each, and their descriptions and colors.
+
+Labels are described using 'vlt labels set', whether or not any secret has
+them. Colored labels are shown as colored badges in listings written to a
+terminal, unless NO_COLOR is set. Label descriptions and colors are part of
+the vault, but are not synced between devices.`,
+		Example: `  # Describe a label, shown in red
+  vlt labels set q --description "quarantined, pending rotation" --color red
+
+  # List the labels
+  vlt labels`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.AddCommand(NewCmdLabelsSet(defaults))
+	cmd.AddCommand(NewCmdLabelsUnset(defaults))
+	cmd.AddCommand(NewCmdLabelsBulk(defaults))
+
+	return cmd
+}
+
+// NewCmdLabelsSet creates the labels set cobra command.
+func NewCmdLabelsSet(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewLabelsSetOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	var description, color string
+
+	cmd := &cobra.Command{
+		Use:   "set LABEL",
+		Short: "Set the description or color of a label",
+		Long: fmt.Sprintf(`Set the description or color of a label, keeping the one not given.
+An empty value clears it.
+
+Supported colors: %v.`, slices.Sorted(maps.Keys(labelColorCodes))),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if cmd.Flags().Changed("description") {
+				o.description = &description
+			}
+
+			if cmd.Flags().Changed("color") {
+				o.color = &color
+			}
+
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&description, "description", "d", "", "the label description")
+	cmd.Flags().StringVarP(&color, "color", "c", "", "the color label badges are shown in")
+
+	return cmd
+}
+
+// NewCmdLabelsUnset creates the labels unset cobra command.
+func NewCmdLabelsUnset(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewLabelsUnsetOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "unset LABEL",
+		Short: "Remove the description and color of a label",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
+
+// NewCmdLabelsBulk creates the labels bulk cobra command.
+func NewCmdLabelsBulk(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewLabelsBulkOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "bulk --from LABEL --to LABEL",
+		Short: "Rename a label, or a label prefix, across many secrets at once",
+		Long: `Rename a label across the vault secrets, in a single transaction.
+
+If --from ends with '*', it is a label prefix, replaced by --to in every label
+starting with it. Otherwise, labels equal to --from are renamed to --to.
+Only the secrets whose names match the --match glob are relabeled, if given.
+
+The relabeled labels are listed, use --dry-run to preview them without
+relabeling. Secrets of mounted vaults are not relabeled.`,
+		Example: `  # Preview moving the labels under 'old/' to 'new/'
+  vlt label bulk --from 'old/*' --to 'new/' --dry-run
+
+  # Rename the 'prod' label of the secrets named 'db-*'
+  vlt label bulk --from prod --to production --match 'db-*'`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.from, "from", "", "", "the label to rename, or a label prefix followed by '*'")
+	cmd.Flags().StringVarP(&o.to, "to", "", "", "the new label, or the prefix replacing the one of --from")
+	cmd.Flags().StringVarP(&o.match, "match", "m", "", "relabel only the secrets whose names match the glob")
+	cmd.Flags().BoolVarP(&o.dryRun, "dry-run", "n", false, "list the labels that would be relabeled, without relabeling them")
+
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Launcher output formats of 'vlt find'.
+const (
+	findOutputAlfred  = "alfred"
+	findOutputRaycast = "raycast"
+)
+
+// Actions of the items listed for launchers.
+const (
+	launcherActionCopyPassword = "copy-password"
+	launcherActionCopyUsername = "copy-username"
+	launcherActionOpenURL      = "open-url"
+)
+
+// alfredItem is an item of an Alfred script filter.
+// See https://www.alfredapp.com/help/workflows/inputs/script-filter/json/.
+type alfredItem struct {
+	UID          string               `json:"uid"`
+	Title        string               `json:"title"`
+	Subtitle     string               `json:"subtitle"`
+	Arg          string               `json:"arg"`
+	Autocomplete string               `json:"autocomplete"`
+	Match        string               `json:"match"`
+	Variables    map[string]string    `json:"variables"`
+	Mods         map[string]alfredMod `json:"mods"`
+}
+
+// alfredMod overrides an [alfredItem] while holding a modifier key.
+type alfredMod struct {
+	Valid     bool              `json:"valid"`
+	Arg       string            `json:"arg"`
+	Subtitle  string            `json:"subtitle"`
+	Variables map[string]string `json:"variables"`
+}
+
+// raycastItem is a list item of a Raycast extension or script.
+type raycastItem struct {
+	ID       string          `json:"id"`
+	Title    string          `json:"title"`
+	Subtitle string          `json:"subtitle"`
+	Keywords []string        `json:"keywords"`
+	Actions  []raycastAction `json:"actions"`
+}
+
+// raycastAction is an action of a [raycastItem]. Copy actions either copy the
+// content as is, or else the output of the command, not to list secret values.
+type raycastAction struct {
+	Type    string   `json:"type"`
+	Title   string   `json:"title"`
+	Content string   `json:"content,omitempty"`
+	Command []string `json:"command,omitempty"`
+	URL     string   `json:"url,omitempty"`
+}
+
+// launcherCommand returns the command printing the value of the given secret,
+// run by launchers. The executable and the vault are given by absolute paths,
+// as launchers do not share the shell environment.
+func (o *FindOptions) launcherCommand(name string) []string {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "vlt"
+	}
+
+	path, err := filepath.Abs(o.path)
+	if err != nil {
+		path = o.path
+	}
+
+	return []string{exe, "--file", path, "get", name}
+}
+
+// printAlfred writes the given secrets as an Alfred script filter.
+// Selecting an item passes the secret name as the argument, along with
+// the variables of the action: "vlt" and "vault" name the executable
+// and the vault, and "action" is one of [launcherActionCopyPassword],
+// [launcherActionCopyUsername] (cmd) and [launcherActionOpenURL] (alt).
+func (o *FindOptions) printAlfred(ctx context.Context, w io.Writer, secrets []secretWithLabels) error {
+	metas, err := o.secretMetas(ctx, o.StdioOptions, secrets)
+	if err != nil {
+		return err
+	}
+
+	items := make([]alfredItem, 0, len(secrets))
+
+	for _, s := range secrets {
+		command := o.launcherCommand(s.name)
+		variables := func(action string) map[string]string {
+			return map[string]string{"vlt": command[0], "vault": command[2], "action": action}
+		}
+
+		username, url := secretLogin(s, metas[s.displayID()])
+
+		urlSubtitle := "No url"
+		if len(url) > 0 {
+			urlSubtitle = "Open " + url
+		}
+
+		items = append(items, alfredItem{
+			UID:          s.displayID(),
+			Title:        s.name,
+			Subtitle:     strings.Join(s.labels, ", "),
+			Arg:          s.name,
+			Autocomplete: s.name,
+			Match:        strings.Join(append([]string{s.name}, s.labels...), " "),
+			Variables:    variables(launcherActionCopyPassword),
+			Mods: map[string]alfredMod{
+				"cmd": {
+					Valid:     true,
+					Arg:       username,
+					Subtitle:  "Copy username",
+					Variables: variables(launcherActionCopyUsername),
+				},
+				"alt": {
+					Valid:     len(url) > 0,
+					Arg:       url,
+					Subtitle:  urlSubtitle,
+					Variables: variables(launcherActionOpenURL),
+				},
+			},
+		})
+	}
+
+	return encodeIndent(w, map[string]any{"items": items})
+}
+
+// printRaycast writes the given secrets as a list of Raycast items, each
+// holding the copy password, copy username and, if any, open url actions.
+func (o *FindOptions) printRaycast(ctx context.Context, w io.Writer, secrets []secretWithLabels) error {
+	metas, err := o.secretMetas(ctx, o.StdioOptions, secrets)
+	if err != nil {
+		return err
+	}
+
+	items := make([]raycastItem, 0, len(secrets))
+
+	for _, s := range secrets {
+		labels := s.labels
+		if labels == nil {
+			labels = []string{}
+		}
+
+		username, url := secretLogin(s, metas[s.displayID()])
+
+		actions := []raycastAction{
+			{Type: launcherActionCopyPassword, Title: "Copy Password", Command: o.launcherCommand(s.name)},
+			{Type: launcherActionCopyUsername, Title: "Copy Username", Content: username},
+		}
+
+		if len(url) > 0 {
+			actions = append(actions, raycastAction{Type: launcherActionOpenURL, Title: "Open URL", URL: url})
+		}
+
+		items = append(items, raycastItem{
+			ID:       s.displayID(),
+			Title:    s.name,
+			Subtitle: strings.Join(s.labels, ", "),
+			Keywords: labels,
+			Actions:  actions,
+		})
+	}
+
+	return encodeIndent(w, map[string]any{"items": items})
+}
+
+func encodeIndent(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
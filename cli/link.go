@@ -0,0 +1,325 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/provider"
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultLinkCacheTTL is the fallback when no link cache ttl is set.
+const defaultLinkCacheTTL = "1h"
+
+type LinkError struct {
+	Err error
+}
+
+func (e *LinkError) Error() string { return "link: " + e.Err.Error() }
+
+func (e *LinkError) Unwrap() error { return e.Err }
+
+// newProviderRegistry returns a registry of the providers linked secrets are resolved from.
+func newProviderRegistry(config *ResolvedConfig) *provider.Registry {
+	r := provider.NewRegistry()
+
+	r.Register(provider.SchemeHashiCorp, provider.NewHashiCorp(
+		provider.WithHashiCorpAddress(config.HashiCorpAddress),
+		provider.WithHashiCorpNamespace(config.HashiCorpNamespace),
+	))
+	r.Register(provider.SchemeAWS, newAWSProvider(config, ""))
+
+	// the default profile is validated with the config.
+	if p, err := gcpProfile(config, ""); err == nil {
+		r.Register(provider.SchemeGCP, newGCPProvider(p))
+	}
+
+	r.Register(provider.SchemeAzure, newAzureProvider(config))
+
+	// other schemes are resolved by plugins named after them.
+	r.RegisterFallback(pluginProviderFallback)
+
+	return r
+}
+
+// NewCmdLink creates the link cobra command with its sub-commands.
+func NewCmdLink(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "link",
+		Short: "Manage secrets linked to external providers (subcommands available)",
+		Long: `Manage secrets linked to external secret providers.
+
+The value of a linked secret is resolved from its provider when it is read,
+e.g., by 'vlt show', and cached in the vault until its ttl expires.
+Linked secrets are otherwise regular secrets: they are searched, labeled
+and removed as usual, but their value cannot be updated.
+
+Links have the form '<scheme>://<path>#<field>', supported schemes:
+    hv: HashiCorp Vault, the path is the API path of the secret
+        (e.g., 'hv://secret/data/db#password' for a KV v2 engine mounted at 'secret').
+    aws: AWS Secrets Manager, the path is the secret name, the field selects
+         a key of a JSON secret (e.g., 'aws://prod/db#password').
+    gcp: Google Cloud Secret Manager, the path is the secret id in the project
+         of the default profile, or its full resource name, optionally followed
+         by a version, the field selects a key of a JSON secret
+         (e.g., 'gcp://db/versions/3#password').
+    azure: Azure Key Vault, the path is the secret name in the configured key
+           vault, or a key vault name followed by a secret name and optional
+           version, the field selects a key of a JSON secret
+           (e.g., 'azure://my-vault/db#password').
+
+Links of other schemes are resolved by the 'vlt-<scheme>' plugin,
+if found on PATH (see 'vlt plugin').`,
+	}
+
+	cmd.AddCommand(NewCmdLinkAdd(defaults))
+	cmd.AddCommand(NewCmdLinkList(defaults))
+	cmd.AddCommand(NewCmdLinkRefresh(defaults))
+
+	return cmd
+}
+
+// LinkAddOptions holds data required to run the command.
+type LinkAddOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config *ResolvedConfig
+	name   string
+	labels []string
+	ttl    string
+
+	cacheTTL time.Duration
+}
+
+var _ genericclioptions.CmdOptions = &LinkAddOptions{}
+
+// NewLinkAddOptions initializes the options struct.
+func NewLinkAddOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *LinkAddOptions {
+	return &LinkAddOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+	}
+}
+
+func (o *LinkAddOptions) Complete() error {
+	o.cacheTTL = time.Duration(o.config.LinkCacheTTL)
+
+	if len(o.ttl) > 0 {
+		ttl, err := cmdutil.ParseDuration(o.ttl)
+		if err != nil {
+			return &LinkError{fmt.Errorf("invalid --ttl value %q: %w", o.ttl, err)}
+		}
+
+		o.cacheTTL = ttl
+	}
+
+	return nil
+}
+
+func (o *LinkAddOptions) Validate() error {
+	if len(o.name) == 0 {
+		return &LinkError{errors.New("no secret name; use --name")}
+	}
+
+	if o.cacheTTL < 0 {
+		return &LinkError{fmt.Errorf("invalid --ttl value %q (must not be negative)", o.ttl)}
+	}
+
+	return nil
+}
+
+func (o *LinkAddOptions) Run(ctx context.Context, args ...string) error {
+	uri := args[0]
+
+	if _, err := provider.ParseRef(uri); err != nil {
+		return &LinkError{err}
+	}
+
+	id, err := o.vault.InsertLink(ctx, o.name, uri, o.cacheTTL, o.labels)
+	if err != nil {
+		return &LinkError{err}
+	}
+
+	o.Infof("Linked secret %q (id: %d) to %s.\n", o.name, id, uri)
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		o.Warnf("Post-write hook failed: %v", err)
+	}
+
+	return nil
+}
+
+// NewCmdLinkAdd creates the link add cobra command.
+func NewCmdLinkAdd(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewLinkAddOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "add LINK --name NAME",
+		Short: "Add a secret linked to an external provider",
+		Long: `Add a new secret whose value is resolved from an external provider.
+
+The link is resolved once when added, failing if the secret cannot be read.`,
+		Example: `  # Link the password field of a HashiCorp Vault KV v2 secret
+  vlt link add hv://secret/data/db#password --name db-password --label prod
+
+  # Resolve the secret on every read, without caching
+  vlt link add hv://secret/data/api#key --name api-key --ttl 0`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.name, "name", "", "", "the secret name")
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "optional label to associate with the secret (comma-separated or repeated)")
+	cmd.Flags().StringVarP(&o.ttl, "ttl", "", "", "how long the resolved value is cached, 0 disables caching (e.g., 30m, 1d) (default: 'providers.cache_ttl' config)")
+
+	return cmd
+}
+
+// LinkListOptions holds data required to run the command.
+type LinkListOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &LinkListOptions{}
+
+// NewLinkListOptions initializes the options struct.
+func NewLinkListOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *LinkListOptions {
+	return &LinkListOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*LinkListOptions) Complete() error { return nil }
+
+func (*LinkListOptions) Validate() error { return nil }
+
+func (o *LinkListOptions) Run(ctx context.Context, _ ...string) error {
+	links, err := o.vault.Links(ctx)
+	if err != nil {
+		return &LinkError{err}
+	}
+
+	if len(links) == 0 {
+		o.Warnf("No linked secrets found.\n")
+		return nil
+	}
+
+	ids := make([]int, 0, len(links))
+	for _, l := range links {
+		ids = append(ids, l.SecretID)
+	}
+
+	secrets, err := o.vault.SecretsByIDs(ctx, ids...)
+	if err != nil {
+		return &LinkError{err}
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tLINK\tTTL\tRESOLVED")
+
+	now := time.Now()
+
+	for _, l := range links {
+		resolved := "never"
+		if !l.ResolvedAt.IsZero() {
+			resolved = l.ResolvedAt.Local().Format(time.DateTime)
+		}
+
+		if l.Expired(now) {
+			resolved += " (expired)"
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", l.SecretID, secrets[l.SecretID].Name, l.URI, l.TTL, resolved)
+	}
+
+	fmt.Fprintln(tw) // add padding
+
+	return nil
+}
+
+// NewCmdLinkList creates the link list cobra command.
+func NewCmdLinkList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewLinkListOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List linked secrets",
+		Long:    "List linked secrets, along with the time their links were last resolved.",
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
+
+// LinkRefreshOptions holds data required to run the command.
+type LinkRefreshOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &LinkRefreshOptions{}
+
+// NewLinkRefreshOptions initializes the options struct.
+func NewLinkRefreshOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *LinkRefreshOptions {
+	return &LinkRefreshOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*LinkRefreshOptions) Complete() error { return nil }
+
+func (*LinkRefreshOptions) Validate() error { return nil }
+
+func (o *LinkRefreshOptions) Run(ctx context.Context, args ...string) error {
+	ids := make([]int, 0, len(args))
+
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return &LinkError{fmt.Errorf("invalid secret id %q", arg)}
+		}
+
+		ids = append(ids, id)
+	}
+
+	n, err := o.vault.RefreshLinks(ctx, ids...)
+	if err != nil {
+		return &LinkError{err}
+	}
+
+	o.Infof("Refreshed linked secrets, %d changed.\n", n)
+
+	return nil
+}
+
+// NewCmdLinkRefresh creates the link refresh cobra command.
+func NewCmdLinkRefresh(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewLinkRefreshOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "refresh [ID...]",
+		Short: "Resolve linked secrets, ignoring their cache",
+		Long:  "Resolve the given linked secrets by their ids, or all linked secrets, regardless of their cache expiry.",
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
@@ -63,6 +63,10 @@ func (o *LoginOptions) Run(ctx context.Context, _ ...string) error {
 
 	path := o.path
 
+	if err := o.checkPermissions(o.StdioOptions); err != nil {
+		return err
+	}
+
 	password, err := input.PromptReadSecure(o.Out, int(o.In.Fd()), "[vlt] Password for %q:", path)
 	if err != nil {
 		return fmt.Errorf("prompt password: %v", err)
@@ -78,6 +82,8 @@ func (o *LoginOptions) Run(ctx context.Context, _ ...string) error {
 		return err
 	}
 
+	o.cacheKeychainKey(ctx, o.StdioOptions, key, nonce)
+
 	o.Infof("login successful")
 
 	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postLogin); err != nil {
@@ -14,7 +14,8 @@ type LogoutOptions struct {
 	*genericclioptions.StdioOptions
 	*VaultOptions
 
-	sessionClient *vaultdaemon.SessionClient
+	sessionClient  *vaultdaemon.SessionClient
+	forgetKeychain bool // forgetKeychain also removes the vault key cached in the macOS Keychain.
 }
 
 var _ genericclioptions.CmdOptions = &LogoutOptions{}
@@ -47,6 +48,10 @@ func (o *LogoutOptions) Run(ctx context.Context, _ ...string) error {
 		return err
 	}
 
+	if o.forgetKeychain && o.forgetKeychainKey(ctx, o.StdioOptions) {
+		o.Infof("removed the vault key from the Keychain\n")
+	}
+
 	o.Infof("logout successful")
 
 	return nil
@@ -66,11 +71,16 @@ func NewCmdLogout(defaults *DefaultVltOptions) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "logout",
 		Short: "Logs the user out of the current session",
-		Long:  "Logs the user out of the current session.",
+		Long: `Logs the user out of the current session.
+
+Use --keychain to also remove the vault key cached in the macOS Keychain,
+see the 'vault.keychain' config.`,
 		Run: func(cmd *cobra.Command, _ []string) {
 			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
 		},
 	}
 
+	cmd.Flags().BoolVarP(&o.forgetKeychain, "keychain", "", false, "also remove the vault key cached in the macOS Keychain")
+
 	return cmd
 }
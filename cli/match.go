@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/urlmatch"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+type MatchError struct {
+	Err error
+}
+
+func (e *MatchError) Error() string { return "match: " + e.Err.Error() }
+
+func (e *MatchError) Unwrap() error { return e.Err }
+
+// MatchOptions holds data required to run the command.
+type MatchOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	limit int // limit is the maximum number of listed logins, 0 for all.
+}
+
+var _ genericclioptions.CmdOptions = &MatchOptions{}
+
+// NewMatchOptions initializes the options struct.
+func NewMatchOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *MatchOptions {
+	return &MatchOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*MatchOptions) Complete() error { return nil }
+
+func (o *MatchOptions) Validate() error {
+	if o.limit < 0 {
+		return &MatchError{errors.New("--limit must not be negative")}
+	}
+
+	return nil
+}
+
+func (o *MatchOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &MatchError{retErr}
+			return
+		}
+	}()
+
+	page, err := urlmatch.ParsePage(args[0])
+	if err != nil {
+		return err
+	}
+
+	secrets, err := o.matchURL(ctx, o.StdioOptions, page)
+	if err != nil {
+		return err
+	}
+
+	if len(secrets) == 0 {
+		return vaulterrors.ErrSearchNoMatch
+	}
+
+	if o.limit > 0 && len(secrets) > o.limit {
+		secrets = secrets[:o.limit]
+	}
+
+	printTable(o.Out, secrets, o.labelColors(ctx, o.Out))
+
+	return nil
+}
+
+// matchURL returns the secrets of the vault and its mounts having labels
+// matching the given page, from the best match to the worst.
+// See [urlmatch] for the ranking of matches.
+func (o *VaultOptions) matchURL(ctx context.Context, io *genericclioptions.StdioOptions, page *url.URL) ([]secretWithLabels, error) {
+	secrets, err := o.searchAll(ctx, io, NewSearchableOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	return rankByURL(secrets, page), nil
+}
+
+// rankByURL returns the given secrets having labels matching page,
+// from the best match to the worst, keeping the order of equal matches.
+func rankByURL(secrets []secretWithLabels, page *url.URL) []secretWithLabels {
+	ranks := make(map[string]int, len(secrets)) // secret display id to rank
+	for _, s := range secrets {
+		ranks[s.displayID()] = urlmatch.RankAny(s.labels, page)
+	}
+
+	matching := slices.DeleteFunc(slices.Clone(secrets), func(s secretWithLabels) bool { return ranks[s.displayID()] == 0 })
+	slices.SortStableFunc(matching, func(a, b secretWithLabels) int { return ranks[b.displayID()] - ranks[a.displayID()] })
+
+	return matching
+}
+
+// NewCmdMatch creates the match cobra command.
+func NewCmdMatch(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewMatchOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "match URL",
+		Short: "List the logins matching a URL, best match first",
+		Long: `List the secrets whose URL labels match the given page URL, from the
+best match to the worst.
+
+Logins store their URL patterns as labels, e.g., "https://github.com/login",
+as imported from browsers or added using 'vlt save --url'. A pattern matches
+pages of its host and of its subdomains. Pages of the pattern host rank above
+the ones of its subdomains, then the longer the path shared by the pattern and
+the page, the better the match. Patterns of https URLs never match http pages.
+
+The same matching is used by 'vlt keepassxc' and 'vlt dmenu --url'.`,
+		Example: `  # List the logins of a page
+  vlt match https://github.com/login
+
+  # Show the best matching login of a page
+  vlt match github.com -n 1`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().IntVarP(&o.limit, "limit", "n", 0, "list at most the given number of logins")
+
+	return cmd
+}
@@ -0,0 +1,325 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/hibp"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultMonitorStateFile is the monitor state file name,
+// created under the user's state directory.
+const defaultMonitorStateFile = "monitor.json"
+
+type MonitorError struct {
+	Err error
+}
+
+func (e *MonitorError) Error() string { return "monitor: " + e.Err.Error() }
+
+func (e *MonitorError) Unwrap() error { return e.Err }
+
+// monitorState is the local breach monitoring state, keyed by vault path.
+//
+// It holds no secret values or hashes, only the time each secret
+// was last checked and its breach count at the time.
+type monitorState struct {
+	Vaults map[string]*monitorVaultState `json:"vaults"`
+}
+
+type monitorVaultState struct {
+	LastRun time.Time                      `json:"last_run"`
+	Secrets map[string]*monitorSecretState `json:"secrets"`
+}
+
+//nolint:tagliatelle
+type monitorSecretState struct {
+	ChangedAt time.Time `json:"changed_at"` // ChangedAt is the time the checked secret value was set.
+	CheckedAt time.Time `json:"checked_at"`
+	Breaches  int       `json:"breaches"`
+}
+
+// MonitorOptions holds data required to run the command.
+type MonitorOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	stateFile string
+	apiURL    string
+	full      bool
+	client    *hibp.Client
+}
+
+var _ genericclioptions.CmdOptions = &MonitorOptions{}
+
+// NewMonitorOptions initializes the options struct.
+func NewMonitorOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *MonitorOptions {
+	return &MonitorOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (o *MonitorOptions) Complete() error {
+	o.client = hibp.New(hibp.WithBaseURL(o.apiURL))
+
+	if len(o.stateFile) > 0 {
+		return nil
+	}
+
+	dir, err := userStateDir()
+	if err != nil {
+		return &MonitorError{err}
+	}
+
+	o.stateFile = filepath.Join(dir, "vlt", defaultMonitorStateFile)
+
+	return nil
+}
+
+func (*MonitorOptions) Validate() error { return nil }
+
+// monitorFinding is a secret newly found in known breaches.
+type monitorFinding struct {
+	id       int
+	name     string
+	breaches int
+}
+
+func (o *MonitorOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &MonitorError{retErr}
+			return
+		}
+	}()
+
+	state, err := loadMonitorState(o.stateFile)
+	if err != nil {
+		return err
+	}
+
+	vaultPath, err := filepath.Abs(o.path)
+	if err != nil {
+		return err
+	}
+
+	vs, ok := state.Vaults[vaultPath]
+	if !ok || o.full {
+		vs = &monitorVaultState{Secrets: make(map[string]*monitorSecretState)}
+		state.Vaults[vaultPath] = vs
+	}
+
+	secrets, err := o.vault.FilterSecrets(ctx, "", "", nil)
+	if err != nil {
+		return err
+	}
+
+	changedAt, err := o.vault.SecretsChangedAt(ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(secrets))
+	for id := range secrets {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	var (
+		now      = time.Now()
+		checked  = 0
+		findings []monitorFinding
+		runErr   error
+	)
+
+	for _, id := range ids {
+		key := strconv.Itoa(id)
+
+		prev, seen := vs.Secrets[key]
+		if seen && !changedAt[id].After(prev.ChangedAt) {
+			continue
+		}
+
+		value, err := o.vault.ShowSecret(ctx, id)
+		if err != nil {
+			runErr = err
+			break
+		}
+
+		n, err := o.client.Count(ctx, value)
+		if err != nil {
+			runErr = err
+			break
+		}
+
+		checked++
+		vs.Secrets[key] = &monitorSecretState{ChangedAt: changedAt[id], CheckedAt: now, Breaches: n}
+
+		if n > 0 {
+			findings = append(findings, monitorFinding{id: id, name: secrets[id].Name, breaches: n})
+		}
+	}
+
+	// forget removed secrets, their ids may be reused.
+	for key := range vs.Secrets {
+		id, err := strconv.Atoi(key)
+		if _, ok := secrets[id]; err != nil || !ok {
+			delete(vs.Secrets, key)
+		}
+	}
+
+	if runErr == nil {
+		vs.LastRun = now
+	}
+
+	// progress is saved even if the run was interrupted,
+	// so the next run resumes where it stopped.
+	if err := saveMonitorState(o.stateFile, state); err != nil {
+		return errors.Join(runErr, err)
+	}
+
+	if runErr != nil {
+		return runErr
+	}
+
+	o.Infof("checked %d of %d secrets (%d unchanged since the last run).\n", checked, len(ids), len(ids)-checked)
+
+	if len(findings) == 0 {
+		return nil
+	}
+
+	report := &bytes.Buffer{}
+	printFindingsTable(report, findings)
+
+	o.Infof("%s", report.String())
+
+	if hook := o.hooks.postMonitor; len(hook) > 0 {
+		o.Infof("running hook: %q %q\n", hook[0], hook[1:])
+
+		if err := genericclioptions.RunCommandWithInput(ctx, o.StdioOptions, report, hook[0], hook[1:]...); err != nil {
+			o.Warnf("Post-monitor hook failed: %v\n", err)
+		}
+	}
+
+	return fmt.Errorf("%d secrets found in known data breaches", len(findings))
+}
+
+func printFindingsTable(w io.Writer, findings []monitorFinding) {
+	tw := tabwriter.NewWriter(w, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tBREACHES")
+
+	for _, f := range findings {
+		fmt.Fprintf(tw, "%d\t%s\t%d\n", f.id, f.name, f.breaches)
+	}
+
+	fmt.Fprintln(tw) // add padding
+}
+
+func loadMonitorState(path string) (*monitorState, error) {
+	state := &monitorState{}
+
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("load state: %w", err)
+	}
+
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, state); err != nil {
+			return nil, fmt.Errorf("load state: %w", err)
+		}
+	}
+
+	if state.Vaults == nil {
+		state.Vaults = make(map[string]*monitorVaultState)
+	}
+
+	return state, nil
+}
+
+func saveMonitorState(path string, state *monitorState) error {
+	raw, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+
+	return nil
+}
+
+// userStateDir returns $XDG_STATE_HOME, falling back to ~/.local/state.
+func userStateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); len(dir) > 0 {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "state"), nil
+}
+
+// NewCmdMonitor creates the monitor cobra command.
+func NewCmdMonitor(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewMonitorOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "monitor",
+		Short: "Check changed secrets against known data breaches",
+		Long: `Check secrets against the Have I Been Pwned Pwned Passwords database.
+
+Only secrets whose value changed since the last run are checked, based on a
+local state file, making the command suitable for a scheduled job (e.g., a systemd timer).
+Secret values never leave the machine, only the first 5 characters of their SHA-1 hash are sent.
+
+If newly breached secrets are found, the 'hooks.post_monitor_cmd' hook is run
+with the findings written to its stdin, and the command exits with a non-zero status.`,
+		Example: `  # Check secrets changed since the last run
+  vlt monitor
+
+  # Re-check all secrets
+  vlt monitor --full`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.full, "full", "", false, "re-check all secrets, ignoring the local state")
+	cmd.Flags().StringVarP(&o.apiURL, "api-url", "", hibp.DefaultBaseURL, "Pwned Passwords API base URL (e.g., a self-hosted mirror)")
+	cmd.Flags().StringVarP(&o.stateFile, "state-file", "", "",
+		fmt.Sprintf("path to the monitor state file (default: $XDG_STATE_HOME/vlt/%s)", defaultMonitorStateFile))
+
+	return cmd
+}
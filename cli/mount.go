@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vault"
+)
+
+// searchAll queries the vault and its mounts for secrets matching s.
+//
+// Secrets named with a mount prefix, e.g., "team/db", are looked up in that
+// mount only. Otherwise, all vaults are searched, and the matches of mounts
+// are named with their mount prefix. Ids refer to secrets of the vault itself,
+// as do searches using an access token.
+func (o *VaultOptions) searchAll(ctx context.Context, io *genericclioptions.StdioOptions, s *SearchableOptions) ([]secretWithLabels, error) {
+	if len(o.mounts) == 0 || len(o.token) > 0 || s.ID > 0 || len(s.IDs) > 0 {
+		return s.search(ctx, o.vault)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(o.mounts)) {
+		prefix := name + "/"
+		if !strings.HasPrefix(s.Name, prefix) && !strings.HasPrefix(s.Wildcard, prefix) {
+			continue
+		}
+
+		sub := *s
+		sub.Name = strings.TrimPrefix(s.Name, prefix)
+		sub.Wildcard = strings.TrimPrefix(s.Wildcard, prefix)
+
+		return o.searchMount(ctx, io, name, &sub)
+	}
+
+	secrets, err := s.search(ctx, o.vault)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(o.mounts)) {
+		mounted, err := o.searchMount(ctx, io, name, s)
+		if err != nil {
+			return nil, err
+		}
+
+		secrets = append(secrets, mounted...)
+	}
+
+	return secrets, nil
+}
+
+// searchMount queries the vault mounted under name,
+// naming the matching secrets with the mount prefix.
+func (o *VaultOptions) searchMount(ctx context.Context, io *genericclioptions.StdioOptions, name string, s *SearchableOptions) ([]secretWithLabels, error) {
+	v, err := o.mountedVault(ctx, io, name)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := s.search(ctx, v)
+	if err != nil {
+		return nil, fmt.Errorf("mount %s: %w", name, err)
+	}
+
+	for i := range secrets {
+		secrets[i].mount = name
+		secrets[i].name = name + "/" + secrets[i].name
+	}
+
+	return secrets, nil
+}
+
+// vaultOf returns the vault holding the given secret.
+func (o *VaultOptions) vaultOf(ctx context.Context, io *genericclioptions.StdioOptions, secret secretWithLabels) (*vault.Vault, error) {
+	if len(secret.mount) == 0 {
+		return o.vault, nil
+	}
+
+	return o.mountedVault(ctx, io, secret.mount)
+}
+
+// mountedVault returns the vault mounted under name, opening it on first use
+// in the same mode as the vault itself.
+func (o *VaultOptions) mountedVault(ctx context.Context, io *genericclioptions.StdioOptions, name string) (*vault.Vault, error) {
+	if m, ok := o.mounted[name]; ok {
+		return m.vault, nil
+	}
+
+	m := &VaultOptions{
+		path:          o.mounts[name],
+		readOnly:      o.readOnly,
+		acceptChanges: o.acceptChanges,
+		strict:        o.strict,
+		noPrompt:      o.noPrompt,
+		keychain:      o.keychain,
+		resolver:      o.resolver,
+		lockMode:      o.lockMode,
+		lockTimeout:   o.lockTimeout,
+	}
+
+	if err := m.Open(ctx, io, o.sessionClient, o.sessionDuration); err != nil {
+		return nil, fmt.Errorf("mount %s: %w", name, err)
+	}
+
+	if o.mounted == nil {
+		o.mounted = make(map[string]*VaultOptions)
+	}
+
+	o.mounted[name] = m
+
+	return m.vault, nil
+}
+
+// closeMounts closes the opened mounts.
+func (o *VaultOptions) closeMounts(ctx context.Context) error {
+	var errs []error
+
+	for name, m := range o.mounted {
+		if err := errors.Join(m.closeVault(ctx), m.Close()); err != nil {
+			errs = append(errs, fmt.Errorf("mount %s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
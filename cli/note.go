@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+// noteLabel marks the secrets holding notes.
+const noteLabel = "type=note"
+
+type NoteError struct {
+	Err error
+}
+
+func (e *NoteError) Error() string { return "note: " + e.Err.Error() }
+
+func (e *NoteError) Unwrap() error { return e.Err }
+
+// NewCmdNote creates the note cobra command with its sub-commands.
+func NewCmdNote(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "note",
+		Short: "Manage secure notes (subcommands available)",
+		Long: fmt.Sprintf(`Manage secure notes stored in the vault.
+
+Notes are secrets labeled %q, holding multi-line text such as license keys
+or recovery instructions, of up to %d MiB. Large notes are stored split into
+separately encrypted chunks.
+
+Notes are edited using 'vlt edit', and printed as any other secret,
+e.g., using 'vlt show' or 'vlt get'.`, noteLabel, vault.MaxSecretSize>>20),
+	}
+
+	cmd.AddCommand(NewCmdNoteAdd(defaults))
+	cmd.AddCommand(NewCmdNoteList(defaults))
+
+	return cmd
+}
+
+// NoteAddOptions holds data required to run the command.
+type NoteAddOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	name   string
+	labels []string
+}
+
+var _ genericclioptions.CmdOptions = &NoteAddOptions{}
+
+// NewNoteAddOptions initializes the options struct.
+func NewNoteAddOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *NoteAddOptions {
+	return &NoteAddOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*NoteAddOptions) Complete() error { return nil }
+
+func (o *NoteAddOptions) Validate() error {
+	if len(o.name) == 0 {
+		return &NoteError{vaulterrors.ErrEmptyName}
+	}
+
+	return nil
+}
+
+func (o *NoteAddOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &NoteError{retErr}
+			return
+		}
+	}()
+
+	note, err := o.readNote(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(strings.TrimSpace(note)) == 0 {
+		return vaulterrors.ErrEmptySecret
+	}
+
+	labels := append(slices.Clone(o.labels), noteLabel)
+
+	n, err := o.vault.InsertNewSecret(ctx, o.name, note, labels)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNoSecretInserted
+	}
+
+	o.Infof("Added note %q (%d bytes)\n", o.name, len(note))
+
+	return nil
+}
+
+// readNote reads the note as is from stdin if piped or redirected,
+// or else opens an empty note in $EDITOR.
+func (o *NoteAddOptions) readNote(ctx context.Context) (string, error) {
+	if !o.NonInteractive {
+		return editInEditor(ctx, o.StdioOptions, o.name, "")
+	}
+
+	b, err := io.ReadAll(io.LimitReader(o.In, vault.MaxSecretSize+1))
+	if err != nil {
+		return "", err
+	}
+
+	if len(b) > vault.MaxSecretSize {
+		return "", fmt.Errorf("%w (%d MiB)", vaulterrors.ErrSecretTooLarge, vault.MaxSecretSize>>20)
+	}
+
+	return string(b), nil
+}
+
+// NoteListOptions holds data required to run the command.
+type NoteListOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &NoteListOptions{}
+
+// NewNoteListOptions initializes the options struct.
+func NewNoteListOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *NoteListOptions {
+	return &NoteListOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*NoteListOptions) Complete() error { return nil }
+
+func (*NoteListOptions) Validate() error { return nil }
+
+func (o *NoteListOptions) Run(ctx context.Context, _ ...string) error {
+	secrets, err := o.searchAll(ctx, o.StdioOptions, &SearchableOptions{Labels: []string{noteLabel}})
+	if err != nil {
+		return &NoteError{err}
+	}
+
+	if len(secrets) == 0 {
+		o.Warnf("No notes found.\n")
+		return nil
+	}
+
+	for i, s := range secrets {
+		secrets[i].labels = slices.DeleteFunc(slices.Clone(s.labels), func(l string) bool { return l == noteLabel })
+	}
+
+	printTable(o.Out, secrets, o.labelColors(ctx, o.Out))
+
+	return nil
+}
+
+// NewCmdNoteAdd creates the note add cobra command.
+func NewCmdNoteAdd(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewNoteAddOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a secure note to the vault",
+		Long: `Add a secure note to the vault.
+
+The note is written in $EDITOR. If input is piped or redirected,
+the note is read from it as is instead.`,
+		Example: `  # Write a note in $EDITOR
+  vlt note add --name recovery-codes
+
+  # Add a note read from a file
+  vlt note add --name license --label work < license.txt`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.name, "name", "", "", "the note name (e.g., recovery-codes)")
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "optional label to associate with the note (comma-separated or repeated)")
+
+	return cmd
+}
+
+// NewCmdNoteList creates the note list cobra command.
+func NewCmdNoteList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewNoteListOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the secure notes",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/clipboard"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultOpenPasswordDelay is the fallback when no password delay is set.
+const defaultOpenPasswordDelay = "10s"
+
+type OpenError struct {
+	Err error
+}
+
+func (e *OpenError) Error() string { return "open: " + e.Err.Error() }
+
+func (e *OpenError) Unwrap() error { return e.Err }
+
+// OpenOptions holds data required to run the command.
+type OpenOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config *ResolvedConfig
+	delay  string // delay overrides the configured password delay.
+	wait   bool   // wait copies the password once Enter is pressed.
+
+	passwordDelay time.Duration
+}
+
+var _ genericclioptions.CmdOptions = &OpenOptions{}
+
+// NewOpenOptions initializes the options struct.
+func NewOpenOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *OpenOptions {
+	return &OpenOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+	}
+}
+
+func (o *OpenOptions) Complete() error {
+	o.passwordDelay = time.Duration(o.config.OpenPasswordDelay)
+	o.wait = o.wait || (o.config.OpenWaitForKey && len(o.delay) == 0)
+
+	if len(o.delay) > 0 {
+		d, err := cmdutil.ParseDuration(o.delay)
+		if err != nil {
+			return &OpenError{fmt.Errorf("--delay: %w", err)}
+		}
+
+		o.passwordDelay = d
+	}
+
+	return nil
+}
+
+func (o *OpenOptions) Validate() error {
+	if o.passwordDelay < 0 {
+		return &OpenError{errors.New("--delay must not be negative")}
+	}
+
+	if len(o.delay) > 0 && o.wait {
+		return &OpenError{errors.New("--delay cannot be used with --wait")}
+	}
+
+	return nil
+}
+
+func (o *OpenOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &OpenError{retErr}
+			return
+		}
+	}()
+
+	s, err := o.secretNamed(ctx, o.StdioOptions, args[0])
+	if err != nil {
+		return err
+	}
+
+	metas, err := o.secretMetas(ctx, o.StdioOptions, []secretWithLabels{s})
+	if err != nil {
+		return err
+	}
+
+	username, url := secretLogin(s, metas[s.displayID()])
+	if len(url) == 0 {
+		return fmt.Errorf("%q: no url", s.name)
+	}
+
+	v, err := o.vaultOf(ctx, o.StdioOptions, s)
+	if err != nil {
+		return err
+	}
+
+	password, err := v.ShowSecret(ctx, s.id)
+	if err != nil {
+		return err
+	}
+
+	if err := o.openBrowser(url); err != nil {
+		return err
+	}
+
+	if err := clipboard.Copy(username); err != nil {
+		return err
+	}
+
+	if err := o.waitPassword(ctx); err != nil {
+		return err
+	}
+
+	if err := clipboard.Copy(password); err != nil {
+		return err
+	}
+
+	o.Infof("Copied the password to the clipboard\n")
+
+	return nil
+}
+
+// openBrowser opens url using the configured browser command, or else the
+// platform default. The browser is started without waiting for it to exit.
+func (o *OpenOptions) openBrowser(url string) error {
+	browserCmd := o.config.OpenBrowserCmd
+
+	if len(browserCmd) == 0 {
+		switch runtime.GOOS {
+		case "darwin":
+			browserCmd = []string{"open"}
+		case "windows":
+			browserCmd = []string{"rundll32", "url.dll,FileProtocolHandler"}
+		default:
+			browserCmd = []string{"xdg-open"}
+		}
+	}
+
+	cmd := exec.Command(browserCmd[0], append(browserCmd[1:], url)...) //nolint:gosec,noctx // configured by the user, outlives the command.
+	cmd.Stderr = o.ErrOut
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("browser: %w", err)
+	}
+
+	return cmd.Process.Release()
+}
+
+// waitPassword waits until the password is due to be copied:
+// once Enter is pressed if waiting for a key, or else after the password delay.
+func (o *OpenOptions) waitPassword(ctx context.Context) error {
+	if !o.wait {
+		o.Infof("Copied the username to the clipboard, copying the password in %s\n", o.passwordDelay)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(o.passwordDelay):
+			return nil
+		}
+	}
+
+	o.Infof("Copied the username to the clipboard, press Enter to copy the password\n")
+
+	read := make(chan error, 1)
+
+	go func() {
+		_, err := bufio.NewReader(o.In).ReadString('\n')
+		read <- err
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-read:
+		return err
+	}
+}
+
+// NewCmdOpen creates the open cobra command.
+func NewCmdOpen(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewOpenOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "open NAME",
+		Short: "Open the URL of a login, then copy its username and password",
+		Long: `Open the URL of the login with exactly the given name in the browser, then
+copy its username to the clipboard, followed by its password.
+
+The URL and the username are the ones of the login metadata, set using 'vlt save'
+and 'vlt update'. Otherwise, the URL is the first label of the login holding one,
+as imported from browsers, and the username is the login name.
+The password is copied after a delay, giving
+the time to paste the username, or once Enter is pressed using --wait.
+
+The browser command and the timings are set in the 'open' config section.`,
+		Example: `  # Open a login, copying its password 10 seconds after its username
+  vlt open octocat
+
+  # Copy the password once Enter is pressed
+  vlt open octocat --wait
+
+  # Copy the password 5 seconds after the username
+  vlt open octocat --delay 5s`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(defaults),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.delay, "delay", "d", "", "how long after the username the password is copied (default: 'open.password_delay', or 10s)")
+	cmd.Flags().BoolVarP(&o.wait, "wait", "w", false, "copy the password once Enter is pressed (default: 'open.wait_for_key')")
+
+	return cmd
+}
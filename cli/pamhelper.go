@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaultdaemon"
+
+	"github.com/spf13/cobra"
+)
+
+// pamMaxAuthTok bounds the password read from pam_exec,
+// PAM_MAX_RESP_SIZE in Linux-PAM.
+const pamMaxAuthTok = 512
+
+type PAMHelperError struct {
+	Err error
+}
+
+func (e *PAMHelperError) Error() string { return "pam-helper: " + e.Err.Error() }
+
+func (e *PAMHelperError) Unwrap() error { return e.Err }
+
+// PAMHelperOptions holds data required to run the command.
+type PAMHelperOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	defaults *DefaultVltOptions
+	config   *ResolvedConfig
+}
+
+var _ genericclioptions.CmdOptions = &PAMHelperOptions{}
+
+// NewPAMHelperOptions initializes the options struct.
+func NewPAMHelperOptions(defaults *DefaultVltOptions) *PAMHelperOptions {
+	return &PAMHelperOptions{
+		StdioOptions: defaults.StdioOptions,
+		VaultOptions: defaults.vaultOptions,
+		defaults:     defaults,
+		config:       defaults.configOptions.resolved,
+	}
+}
+
+func (*PAMHelperOptions) Complete() error { return nil }
+
+func (*PAMHelperOptions) Validate() error {
+	if os.Geteuid() == 0 {
+		return &PAMHelperError{errors.New("refusing to run as root, use the 'seteuid' option of pam_exec")}
+	}
+
+	return nil
+}
+
+// Run starts a session using the login password read from stdin, if it
+// matches the vault password. A mismatch is not an error, so that logins
+// using other passwords are not reported as failures.
+func (o *PAMHelperOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &PAMHelperError{retErr}
+			return
+		}
+	}()
+
+	// only the auth stage exposes the password.
+	if t := os.Getenv("PAM_TYPE"); len(t) > 0 && t != "auth" {
+		return nil
+	}
+
+	// the environment of pam_exec is the PAM environment,
+	// which usually lacks HOME, used to locate the config and vault.
+	if len(os.Getenv("HOME")) == 0 {
+		u, err := user.Current()
+		if err != nil {
+			return err
+		}
+
+		if err := os.Setenv("HOME", u.HomeDir); err != nil {
+			return err
+		}
+	}
+
+	if err := genericclioptions.ExecuteCommand(ctx, o.defaults, "pam-helper"); err != nil {
+		return err
+	}
+
+	password, err := readAuthTok(o.In)
+	if err != nil {
+		return err
+	}
+
+	exists, err := o.vaultExists()
+	if err != nil || !exists {
+		o.Debugf("vlt: pam-helper: no vault at %s\n", o.path)
+		return err
+	}
+
+	key, nonce, err := vault.Login(ctx, o.path, password)
+	if err != nil {
+		o.Debugf("vlt: pam-helper: login password does not unlock %s: %v\n", o.path, err)
+		return nil
+	}
+
+	sessionClient, err := vaultdaemon.NewSessionClient()
+	if err != nil {
+		return fmt.Errorf("start session: %w", err)
+	}
+	defer func() { _ = sessionClient.Close() }() //nolint:wsl
+
+	if err := sessionClient.Login(ctx, o.path, key, nonce, time.Duration(o.config.SessionDuration)); err != nil {
+		return fmt.Errorf("start session: %w", err)
+	}
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postLogin); err != nil {
+		return fmt.Errorf("post login hook: %w", err)
+	}
+
+	return nil
+}
+
+// readAuthTok reads the password written to stdin by pam_exec's
+// 'expose_authtok' option, terminated by a NUL byte.
+func readAuthTok(r io.Reader) (string, error) {
+	b, err := io.ReadAll(io.LimitReader(r, pamMaxAuthTok+1))
+	if err != nil {
+		return "", err
+	}
+
+	password := strings.TrimSuffix(strings.TrimSuffix(string(b), "\x00"), "\n")
+	if len(password) == 0 {
+		return "", errors.New("no password on stdin, use the 'expose_authtok' option of pam_exec")
+	}
+
+	return password, nil
+}
+
+// NewCmdPAMHelper creates the pam-helper cobra command.
+func NewCmdPAMHelper(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewPAMHelperOptions(defaults)
+
+	cmd := &cobra.Command{
+		Use:   "pam-helper",
+		Short: "Start a session using the login password, called by pam_exec",
+		Long: `Start a session using the login password, if it matches the vault password,
+like the PAM integration of gnome-keyring.
+
+Designed to be called by pam_exec(8) during authentication, reading the
+password from stdin ('expose_authtok') and running as the user logging in
+('seteuid'). Logins using other passwords are left as is, and the module
+should be 'optional' so that vlt never fails a login.
+
+The session is kept by the 'vltd' daemon, which must be running already,
+e.g., when unlocking a screen locker, and lasts for 'vault.session_duration'.`,
+		Example: `  # /etc/pam.d/swaylock
+  auth optional pam_exec.so expose_authtok seteuid quiet /usr/bin/vlt pam-helper`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	return cmd
+}
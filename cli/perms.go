@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+const (
+	// vaultFilePerm is the expected mode of the vault file.
+	vaultFilePerm fs.FileMode = 0o600
+
+	// vaultDirPerm is the expected mode of the vault directory.
+	vaultDirPerm fs.FileMode = 0o700
+)
+
+// permIssue describes unsafe ownership or permissions of a vault path.
+type permIssue struct {
+	path   string
+	reason string
+	fix    func() error // fix corrects the issue, nil if vlt cannot fix it.
+}
+
+func (p permIssue) String() string { return p.path + ": " + p.reason }
+
+// checkVaultPermissions reports ownership and permission issues
+// of the vault file at path and its parent directory.
+//
+// The file must be owned by the current user with no group or other access.
+// The directory must be owned by the current user and, unless it is the
+// home directory, have no group or other access. The home directory is
+// the default vault location and is commonly group or world readable,
+// so only group or other write access is reported for it.
+func checkVaultPermissions(path string) ([]permIssue, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("check permissions: %w", err)
+	}
+
+	dir := filepath.Dir(abs)
+	dirMask := vaultDirPerm ^ fs.ModePerm
+
+	if home, err := os.UserHomeDir(); err == nil && filepath.Clean(home) == dir {
+		dirMask = 0o022
+	}
+
+	fileIssues, err := checkPathPermissions(abs, vaultFilePerm^fs.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	dirIssues, err := checkPathPermissions(dir, dirMask)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(fileIssues, dirIssues...), nil
+}
+
+// checkPermissions warns about unsafe vault permissions,
+// or refuses them in strict mode.
+func (o *VaultOptions) checkPermissions(io *genericclioptions.StdioOptions) error {
+	issues, err := checkVaultPermissions(o.path)
+	if err != nil {
+		return err
+	}
+
+	if len(issues) == 0 {
+		return nil
+	}
+
+	if o.strict {
+		errs := make([]error, 0, len(issues))
+		for _, issue := range issues {
+			errs = append(errs, fmt.Errorf("%w: %s", vaulterrors.ErrUnsafePermissions, issue))
+		}
+
+		return errors.Join(errs...)
+	}
+
+	for _, issue := range issues {
+		io.Warnf("vlt: unsafe permissions: %s\n", issue)
+	}
+
+	io.Warnf("Run 'vlt doctor --fix' to fix them, or use --strict to refuse unsafe vaults.\n\n")
+
+	return nil
+}
@@ -0,0 +1,44 @@
+//go:build !windows
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// checkPathPermissions reports whether path is owned by the current user
+// and has none of the permission bits in mask set.
+func checkPathPermissions(path string, mask fs.FileMode) ([]permIssue, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("check permissions: %w", err)
+	}
+
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil, errors.New("check permissions: unexpected file stat type")
+	}
+
+	var issues []permIssue
+
+	if uid := os.Getuid(); int(stat.Uid) != uid {
+		issues = append(issues, permIssue{
+			path:   path,
+			reason: fmt.Sprintf("owned by uid %d, expected %d", stat.Uid, uid),
+		})
+	}
+
+	if perm := fi.Mode().Perm(); perm&mask != 0 {
+		issues = append(issues, permIssue{
+			path:   path,
+			reason: fmt.Sprintf("mode %04o grants access to other users", perm),
+			fix:    func() error { return os.Chmod(path, perm&^mask) },
+		})
+	}
+
+	return issues, nil
+}
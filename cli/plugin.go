@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"text/tabwriter"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/clipboard"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/plugin"
+	"github.com/ladzaretti/vlt-cli/provider"
+
+	"github.com/spf13/cobra"
+)
+
+type PluginError struct {
+	Err error
+}
+
+func (e *PluginError) Error() string { return "plugin: " + e.Err.Error() }
+
+func (e *PluginError) Unwrap() error { return e.Err }
+
+// pluginProvider resolves linked secrets using a provider plugin.
+type pluginProvider struct {
+	plugin plugin.Plugin
+}
+
+var _ provider.Provider = pluginProvider{}
+
+func (p pluginProvider) Resolve(ctx context.Context, ref provider.Ref) (string, error) {
+	return p.plugin.Resolve(ctx, plugin.Ref{Scheme: ref.Scheme, Path: ref.Path, Field: ref.Field})
+}
+
+// pluginProviderFallback returns the provider plugin of the given
+// link scheme, i.e., the 'vlt-<scheme>' plugin, if found on PATH.
+//
+//nolint:ireturn
+func pluginProviderFallback(scheme string) (provider.Provider, bool) {
+	p, err := plugin.Find(scheme)
+	if err != nil {
+		return nil, false
+	}
+
+	return pluginProvider{p}, true
+}
+
+// pluginClipboard copies and pastes using a clipboard plugin,
+// looked up on PATH when first used.
+type pluginClipboard struct {
+	name string
+}
+
+var _ clipboard.Backend = pluginClipboard{}
+
+func (c pluginClipboard) Copy(s string, hints []clipboard.Hint) error {
+	p, err := plugin.Find(c.name)
+	if err != nil {
+		return &clipboard.ConfigurationError{Op: "copy-clipboard", Err: err}
+	}
+
+	pluginHints := make([]plugin.Hint, 0, len(hints))
+	for _, h := range hints {
+		pluginHints = append(pluginHints, plugin.Hint{Format: h.Format, Value: h.Value})
+	}
+
+	return p.Copy(context.Background(), s, pluginHints)
+}
+
+func (c pluginClipboard) Paste() (string, error) {
+	p, err := plugin.Find(c.name)
+	if err != nil {
+		return "", &clipboard.ConfigurationError{Op: "paste-clipboard", Err: err}
+	}
+
+	return p.Paste(context.Background())
+}
+
+// NewCmdPlugin creates the plugin cobra command with its sub-commands.
+func NewCmdPlugin(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Inspect installed plugins (subcommands available)",
+		Long: fmt.Sprintf(`Inspect the installed vlt plugins.
+
+Plugins are executables named '%[1]s<name>' found on PATH. A vlt command
+not built into vlt is passed through to the plugin of the same name, along
+with its arguments, e.g., 'vlt foo --bar' runs '%[1]sfoo --bar'.
+The plugin name must be the first argument.
+
+Plugins may also extend vlt by implementing the plugin protocol: when run
+with the single '%[2]s' argument, a plugin reads one JSON request from
+stdin, and writes one JSON response to stdout:
+
+    request:  {"version": %[3]d, "op": "<op>", ...}
+    response: {"value": "...", "secrets": [...], "error": "..."}
+
+A plugin not supporting the requested op responds with an error. Ops:
+    resolve: link provider, resolves the link '{"ref": {"scheme", "path", "field"}}'
+             to its value. Links with a scheme not built into vlt are resolved
+             by the plugin named after the scheme (see 'vlt link').
+    import:  importer, converts the '{"input"}' file contents to
+             '{"secrets": [{"name", "secret", "labels"}]}' (see 'vlt import --plugin').
+    copy:    clipboard backend, copies '{"value"}', offering the '{"hints": [{"format", "value"}]}'
+             clipboard formats alongside it (see the 'clipboard.plugin' config).
+    paste:   clipboard backend, returns the clipboard contents as its value.`, plugin.Prefix, plugin.ProtocolArg, plugin.ProtocolVersion),
+	}
+
+	cmd.AddCommand(NewCmdPluginList(defaults))
+
+	return cmd
+}
+
+// PluginListOptions holds data required to run the command.
+type PluginListOptions struct {
+	*genericclioptions.StdioOptions
+}
+
+var _ genericclioptions.CmdOptions = &PluginListOptions{}
+
+// NewPluginListOptions initializes the options struct.
+func NewPluginListOptions(stdio *genericclioptions.StdioOptions) *PluginListOptions {
+	return &PluginListOptions{
+		StdioOptions: stdio,
+	}
+}
+
+func (*PluginListOptions) Complete() error { return nil }
+
+func (*PluginListOptions) Validate() error { return nil }
+
+func (o *PluginListOptions) Run(context.Context, ...string) error {
+	plugins := plugin.List()
+	if len(plugins) == 0 {
+		o.Infof("No plugins found on PATH.\n")
+		return nil
+	}
+
+	printPluginsTable(o.Out, plugins)
+
+	return nil
+}
+
+// NewCmdPluginList creates the plugin list cobra command.
+func NewCmdPluginList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewPluginListOptions(defaults.StdioOptions)
+
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the plugins found on PATH",
+		Long: `List the plugins found on PATH.
+
+A plugin found in several PATH directories is listed once, as found first.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
+
+// newCmdPluginExec creates a cobra command passing its arguments through to the given plugin.
+func newCmdPluginExec(defaults *DefaultVltOptions, p plugin.Plugin) *cobra.Command {
+	return &cobra.Command{
+		Use:                p.Name,
+		Short:              "Run the " + p.Path + " plugin",
+		Hidden:             true,
+		DisableFlagParsing: true,
+		// plugins are run as is, the vault is neither opened nor closed for them.
+		PersistentPreRun:  func(*cobra.Command, []string) {},
+		PersistentPostRun: func(*cobra.Command, []string) {},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := p.Exec(cmd.Context(), args, defaults.In, defaults.Out, defaults.ErrOut)
+
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				clierror.Check(&clierror.ExitCodeError{Code: exitErr.ExitCode()})
+			}
+
+			if err != nil {
+				clierror.Check(&PluginError{err})
+			}
+		},
+	}
+}
+
+// addPluginCommand adds the command of the plugin named by the first
+// argument, if it is not a vlt command and the plugin is found on PATH.
+func addPluginCommand(cmd *cobra.Command, defaults *DefaultVltOptions, args []string) {
+	if len(args) == 0 {
+		return
+	}
+
+	if _, _, err := cmd.Find(args); err == nil {
+		return
+	}
+
+	p, err := plugin.Find(args[0])
+	if err != nil {
+		return
+	}
+
+	cmd.AddCommand(newCmdPluginExec(defaults, p))
+}
+
+func printPluginsTable(w io.Writer, plugins []plugin.Plugin) {
+	tw := tabwriter.NewWriter(w, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "NAME\tPATH")
+
+	for _, p := range plugins {
+		fmt.Fprintf(tw, "%s\t%s\n", p.Name, p.Path)
+	}
+
+	fmt.Fprintln(tw) // add padding
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/policy"
+	"github.com/ladzaretti/vlt-cli/randstring"
+)
+
+// passwordPolicy evaluates secrets against the configured password policy set.
+type passwordPolicy struct {
+	set policy.Set
+}
+
+func newPasswordPolicy(resolved *ResolvedConfig) passwordPolicy {
+	return passwordPolicy{set: resolved.Policy}
+}
+
+// check evaluates the secret against the policy effective for its labels.
+//
+// In enforce mode, violations are returned as a [*policy.ViolationError],
+// otherwise they are reported as warnings.
+func (p passwordPolicy) check(io *genericclioptions.StdioOptions, secret string, labels []string) error {
+	effective, mode := p.set.For(labels)
+
+	violations := effective.Check(secret)
+	if v, ok := effective.CheckLabels(labels); ok {
+		violations = append(violations, v)
+	}
+
+	return report(io, mode, violations)
+}
+
+// checkValue is like [passwordPolicy.check], but only evaluates the secret value,
+// e.g., for generated secrets that are not stored.
+func (p passwordPolicy) checkValue(io *genericclioptions.StdioOptions, secret string, labels []string) error {
+	effective, mode := p.set.For(labels)
+	return report(io, mode, effective.Check(secret))
+}
+
+// checkLabels is like [passwordPolicy.check], but only evaluates the labels,
+// e.g., for binary secrets, which are not passwords.
+func (p passwordPolicy) checkLabels(io *genericclioptions.StdioOptions, labels []string) error {
+	effective, mode := p.set.For(labels)
+
+	v, ok := effective.CheckLabels(labels)
+	if !ok {
+		return nil
+	}
+
+	return report(io, mode, []policy.Violation{v})
+}
+
+func report(io *genericclioptions.StdioOptions, mode policy.Mode, violations []policy.Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	if mode == policy.ModeEnforce {
+		return &policy.ViolationError{Violations: violations}
+	}
+
+	for _, v := range violations {
+		io.Warnf("vlt: password policy: %s\n", v)
+	}
+
+	return nil
+}
+
+// generate returns a random password satisfying the policy
+// effective for the given labels.
+func (p passwordPolicy) generate(labels []string) (string, error) {
+	effective, _ := p.set.For(labels)
+	return effective.Generate(randstring.DefaultPasswordPolicy)
+}
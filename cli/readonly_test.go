@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// fatalError is raised by [execute] in place of exiting on fatal errors.
+type fatalError string
+
+// execute runs vlt with the given arguments, returning the message
+// of the fatal error it failed with, if any.
+func execute(t *testing.T, args ...string) (msg string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("VLT_CONFIG_PATH", filepath.Join(dir, ".vlt.toml"))
+	t.Setenv(tokenEnv, "")
+
+	clierror.BehaviorOnFatal(func(msg string, _ int) { panic(fatalError(msg)) })
+	t.Cleanup(clierror.DefaultBehaviorOnFatal)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(fatalError)
+			if !ok {
+				panic(r)
+			}
+
+			msg = string(err)
+		}
+	}()
+
+	in, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = in.Close() }() //nolint:wsl
+
+	streams := &genericclioptions.IOStreams{In: in, Out: io.Discard, ErrOut: io.Discard}
+
+	args = append([]string{"--file", filepath.Join(dir, "vault.db")}, args...)
+	_ = NewDefaultVltCommand(streams, args).Execute()
+
+	return ""
+}
+
+// TestReadOnly_TokenIssue checks that issuing tokens is rejected in read-only
+// mode, as it writes them to the vault.
+func TestReadOnly_TokenIssue(t *testing.T) {
+	tests := [][]string{
+		{"--read-only", "token", "issue", "--label", "x", "--ttl", "1h"},
+		{"--read-only", "token", "issue", "--label", "x", "--ttl", "1h", "--read-only-token"},
+	}
+
+	for _, args := range tests {
+		if got := execute(t, args...); !strings.Contains(got, vaulterrors.ErrReadOnly.Error()) {
+			t.Errorf("vlt %s: got %q, want %v", strings.Join(args, " "), got, vaulterrors.ErrReadOnly)
+		}
+	}
+}
+
+// TestReadOnly_ServeTokensIssue checks that issuing API tokens is rejected
+// in read-only mode, as it writes them to the vault.
+func TestReadOnly_ServeTokensIssue(t *testing.T) {
+	args := []string{"--read-only", "serve", "tokens", "issue", "--name", "ci", "--label", "x", "--ttl", "1h", "--read-only-token"}
+
+	if got := execute(t, args...); !strings.Contains(got, vaulterrors.ErrReadOnly.Error()) {
+		t.Errorf("vlt %s: got %q, want %v", strings.Join(args, " "), got, vaulterrors.ErrReadOnly)
+	}
+}
@@ -0,0 +1,358 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/input"
+	"github.com/ladzaretti/vlt-cli/recovery"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+type RecoveryError struct {
+	Err error
+}
+
+func (e *RecoveryError) Error() string { return "recovery: " + e.Err.Error() }
+
+func (e *RecoveryError) Unwrap() error { return e.Err }
+
+// NewCmdRecovery creates the recovery cobra command with its sub-commands.
+func NewCmdRecovery(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recovery",
+		Short: "Manage one-time recovery codes (subcommands available)",
+		Long: fmt.Sprintf(`Manage one-time recovery codes stored in the vault, e.g., the backup codes
+of a two-factor account.
+
+Recovery codes are stored as secrets labeled %q, holding a JSON object
+listing the codes, along with the time each was used. Codes are revealed in
+order by 'vlt recovery use', which marks them as used.`, recovery.Label),
+	}
+
+	cmd.AddCommand(NewCmdRecoveryAdd(defaults))
+	cmd.AddCommand(NewCmdRecoveryList(defaults))
+	cmd.AddCommand(NewCmdRecoveryUse(defaults))
+
+	return cmd
+}
+
+// RecoveryAddOptions holds data required to run the command.
+type RecoveryAddOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	name   string
+	labels []string
+}
+
+var _ genericclioptions.CmdOptions = &RecoveryAddOptions{}
+
+// NewRecoveryAddOptions initializes the options struct.
+func NewRecoveryAddOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *RecoveryAddOptions {
+	return &RecoveryAddOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*RecoveryAddOptions) Complete() error { return nil }
+
+func (o *RecoveryAddOptions) Validate() error {
+	if len(o.name) == 0 {
+		return &RecoveryError{errors.New("--name is required")}
+	}
+
+	return nil
+}
+
+func (o *RecoveryAddOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &RecoveryError{retErr}
+			return
+		}
+	}()
+
+	text, err := o.readCodes()
+	if err != nil {
+		return err
+	}
+
+	codes, err := recovery.New(text)
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(codes)
+	if err != nil {
+		return err
+	}
+
+	labels := append(slices.Clone(o.labels), recovery.Label)
+
+	n, err := o.vault.InsertNewSecret(ctx, o.name, string(value), labels)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNoSecretInserted
+	}
+
+	o.Infof("Added %d recovery codes %q\n", len(codes.Codes), o.name)
+
+	return nil
+}
+
+// readCodes reads the codes from stdin if piped or redirected,
+// or else prompts for them.
+func (o *RecoveryAddOptions) readCodes() (string, error) {
+	if o.NonInteractive {
+		b, err := io.ReadAll(o.In)
+		return string(b), err
+	}
+
+	s, err := input.PromptRead(o.Out, o.In, "Enter recovery codes, separated by spaces: ")
+	if err != nil {
+		return "", fmt.Errorf("codes read interactive: %w", err)
+	}
+
+	return s, nil
+}
+
+// RecoveryListOptions holds data required to run the command.
+type RecoveryListOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &RecoveryListOptions{}
+
+// NewRecoveryListOptions initializes the options struct.
+func NewRecoveryListOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *RecoveryListOptions {
+	return &RecoveryListOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*RecoveryListOptions) Complete() error { return nil }
+
+func (*RecoveryListOptions) Validate() error { return nil }
+
+func (o *RecoveryListOptions) Run(ctx context.Context, _ ...string) error {
+	secrets, err := o.searchAll(ctx, o.StdioOptions, &SearchableOptions{Labels: []string{recovery.Label}})
+	if err != nil {
+		return &RecoveryError{err}
+	}
+
+	if len(secrets) == 0 {
+		o.Warnf("No recovery codes found.\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tREMAINING\tLAST USED")
+
+	for _, s := range secrets {
+		codes, err := o.readRecoveryCodes(ctx, o.StdioOptions, s)
+		if err != nil {
+			o.Warnf("vlt: recovery codes %q: %v\n", s.name, err)
+			continue
+		}
+
+		lastUsed := "never"
+		if t := codes.LastUsed(); !t.IsZero() {
+			lastUsed = t.Local().Format(time.DateTime)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%s\n", s.displayID(), s.name, codes.Remaining(), len(codes.Codes), lastUsed)
+	}
+
+	return nil
+}
+
+// readRecoveryCodes reads the recovery codes held by the given secret.
+func (o *VaultOptions) readRecoveryCodes(ctx context.Context, io *genericclioptions.StdioOptions, s secretWithLabels) (recovery.Codes, error) {
+	v, err := o.vaultOf(ctx, io, s)
+	if err != nil {
+		return recovery.Codes{}, err
+	}
+
+	value, err := v.ShowSecret(ctx, s.id)
+	if err != nil {
+		return recovery.Codes{}, err
+	}
+
+	return recovery.Parse(value)
+}
+
+// RecoveryUseOptions holds data required to run the command.
+type RecoveryUseOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &RecoveryUseOptions{}
+
+// NewRecoveryUseOptions initializes the options struct.
+func NewRecoveryUseOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *RecoveryUseOptions {
+	return &RecoveryUseOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*RecoveryUseOptions) Complete() error { return nil }
+
+func (*RecoveryUseOptions) Validate() error { return nil }
+
+func (o *RecoveryUseOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &RecoveryError{retErr}
+			return
+		}
+	}()
+
+	name := args[0]
+
+	secrets, err := o.searchAll(ctx, o.StdioOptions, &SearchableOptions{Name: name, Labels: []string{recovery.Label}})
+	if err != nil {
+		return err
+	}
+
+	secrets = slices.DeleteFunc(secrets, func(s secretWithLabels) bool {
+		return s.name != name || !slices.Contains(s.labels, recovery.Label)
+	})
+
+	switch {
+	case len(secrets) == 0:
+		return fmt.Errorf("%q: %w", name, vaulterrors.ErrSearchNoMatch)
+	case len(secrets) > 1:
+		return fmt.Errorf("%q: %w", name, vaulterrors.ErrAmbiguousSecretMatch)
+	}
+
+	s := secrets[0]
+
+	codes, err := o.readRecoveryCodes(ctx, o.StdioOptions, s)
+	if err != nil {
+		return err
+	}
+
+	code, err := codes.Use(time.Now())
+	if err != nil {
+		return fmt.Errorf("%q: %w", name, err)
+	}
+
+	value, err := json.Marshal(codes)
+	if err != nil {
+		return err
+	}
+
+	v, err := o.vaultOf(ctx, o.StdioOptions, s)
+	if err != nil {
+		return err
+	}
+
+	// the code is marked as used before being revealed, so that it is
+	// never revealed twice.
+	n, err := v.UpdateSecret(ctx, s.id, string(value))
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNoSecretUpdated
+	}
+
+	o.Infof("%s\n", code)
+
+	switch {
+	case codes.Remaining() == 0:
+		o.Warnf("vlt: no recovery codes left for %q, generate new ones.\n", name)
+	case codes.Low():
+		o.Warnf("vlt: %d recovery codes left for %q, consider generating new ones.\n", codes.Remaining(), name)
+	}
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		o.Warnf("Post-write hook failed: %v", err)
+	}
+
+	return nil
+}
+
+// NewCmdRecoveryAdd creates the recovery add cobra command.
+func NewCmdRecoveryAdd(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewRecoveryAddOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a list of recovery codes to the vault",
+		Long: `Add a list of one-time recovery codes to the vault.
+
+The codes are prompted for, separated by spaces. If input is piped or
+redirected, the codes are read from it instead, separated by any whitespace,
+e.g., one per line as usually downloaded.`,
+		Example: `  # Add recovery codes, prompting for them
+  vlt recovery add --name github
+
+  # Add downloaded recovery codes
+  vlt recovery add --name github --label 2fa < github-recovery-codes.txt`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.name, "name", "", "", "the recovery codes name (e.g., github)")
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "optional label to associate with the codes (comma-separated or repeated)")
+
+	return cmd
+}
+
+// NewCmdRecoveryList creates the recovery list cobra command.
+func NewCmdRecoveryList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewRecoveryListOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the recovery codes, with the number of unused codes",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
+
+// NewCmdRecoveryUse creates the recovery use cobra command.
+func NewCmdRecoveryUse(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewRecoveryUseOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "use NAME",
+		Short: "Reveal the next unused recovery code, marking it as used",
+		Long: fmt.Sprintf(`Reveal the next unused code of the recovery codes with exactly the given
+name, and mark it as used.
+
+A warning is printed once %d or fewer unused codes remain.`, recovery.LowRemaining),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
@@ -31,6 +31,7 @@ type RemoveOptions struct {
 	search    *SearchableOptions
 	assumeYes bool
 	removeAll bool
+	purge     bool // purge controls whether to permanently delete the secrets instead of trashing them.
 }
 
 var _ genericclioptions.CmdOptions = &RemoveOptions{}
@@ -70,7 +71,7 @@ func (o *RemoveOptions) Run(ctx context.Context, args ...string) (retErr error)
 	count := len(matchingSecrets)
 
 	if count > 0 && !o.assumeYes {
-		printTable(o.Out, matchingSecrets)
+		printTable(o.Out, matchingSecrets, o.labelColors(ctx, o.Out))
 	}
 
 	switch count {
@@ -102,7 +103,12 @@ func (o *RemoveOptions) Run(ctx context.Context, args ...string) (retErr error)
 
 	o.Debugf("proceeding with deleting secrets.\n")
 
-	n, err := o.vault.DeleteSecretsByIDs(ctx, extractIDs(matchingSecrets)...)
+	remove := o.vault.DeleteSecretsByIDs
+	if o.purge {
+		remove = o.vault.PurgeSecretsByIDs
+	}
+
+	n, err := remove(ctx, extractIDs(matchingSecrets)...)
 	if err != nil {
 		return err
 	}
@@ -145,6 +151,9 @@ You may optionally provide a glob pattern to match against secret names or label
 
 Use --id, --name, or --label to select which secrets to remove.
 Multiple --label flags can be applied and are logically ORed.
+
+Removed secrets are moved to the trash, see 'vlt trash', unless --purge is
+given, in which case they are permanently deleted.
 `,
 		Example: `  # Remove a secret by ID
   vlt remove --id 123
@@ -156,7 +165,10 @@ Multiple --label flags can be applied and are logically ORed.
   vlt remove --label project=legacy --label dev --all
 
   # Remove a secret by name without confirmation
-  vlt remove --name api-key --yes`,
+  vlt remove --name api-key --yes
+
+  # Permanently delete a secret, bypassing the trash
+  vlt remove --id 123 --purge`,
 		Run: func(cmd *cobra.Command, args []string) {
 			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
 		},
@@ -167,6 +179,7 @@ Multiple --label flags can be applied and are logically ORed.
 	cmd.Flags().StringSliceVarP(&o.search.Labels, "label", "", nil, FilterByName.Help())
 	cmd.Flags().BoolVarP(&o.assumeYes, "yes", "y", false, "skip confirmation prompts")
 	cmd.Flags().BoolVar(&o.removeAll, "all", false, "remove all matching secrets")
+	cmd.Flags().BoolVar(&o.purge, "purge", false, "permanently delete the secrets instead of moving them to the trash")
 
 	return cmd
 }
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/input"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaultbackup"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+type RestoreError struct {
+	Err error
+}
+
+func (e *RestoreError) Error() string { return "restore: " + e.Err.Error() }
+
+func (e *RestoreError) Unwrap() error { return e.Err }
+
+// RestoreOptions holds data required to run the command.
+type RestoreOptions struct {
+	*genericclioptions.StdioOptions
+
+	config *ResolvedConfig
+	at     string
+	output string
+}
+
+var _ genericclioptions.CmdOptions = &RestoreOptions{}
+
+// NewRestoreOptions initializes the options struct.
+func NewRestoreOptions(stdio *genericclioptions.StdioOptions, config *ResolvedConfig) *RestoreOptions {
+	return &RestoreOptions{
+		StdioOptions: stdio,
+		config:       config,
+	}
+}
+
+func (*RestoreOptions) Complete() error { return nil }
+
+func (o *RestoreOptions) Validate() error {
+	if len(o.output) == 0 {
+		return &RestoreError{errors.New("no output file; use --output")}
+	}
+
+	if _, err := os.Stat(o.output); !errors.Is(err, fs.ErrNotExist) {
+		return &RestoreError{fmt.Errorf("output file already exists: %s", o.output)}
+	}
+
+	return nil
+}
+
+func (o *RestoreOptions) Run(ctx context.Context, args ...string) error {
+	dir, err := backupDir(o.config, args)
+	if err != nil {
+		return err
+	}
+
+	d, err := vaultbackup.Open(dir)
+	if err != nil {
+		return &RestoreError{err}
+	}
+
+	at := time.Now()
+	if len(o.at) > 0 {
+		if at, err = parseSince(o.at, time.Now()); err != nil {
+			return &RestoreError{err}
+		}
+	}
+
+	e, err := d.At(at)
+	if err != nil {
+		return &RestoreError{err}
+	}
+
+	snapshot, err := d.Snapshot(e)
+	if err != nil {
+		return &RestoreError{err}
+	}
+
+	o.Debugf("vlt: restoring backup %s (%s), applying to snapshot %s\n", e.ID, e.Kind, snapshot.ID)
+
+	var opts []vault.Option
+
+	// the snapshot is only opened to apply a delta to it.
+	if e.Kind == vaultbackup.KindDelta {
+		if o.NonInteractive {
+			return vaulterrors.ErrNonInteractiveUnsupported
+		}
+
+		password, err := input.PromptReadSecure(o.Out, int(o.In.Fd()), "[vlt] Password for backup %q:", snapshot.ID)
+		if err != nil {
+			return fmt.Errorf("prompt password: %v", err)
+		}
+
+		opts = append(opts, vault.WithPassword(password))
+	}
+
+	if err := d.Restore(ctx, e, o.output, opts...); err != nil {
+		return &RestoreError{err}
+	}
+
+	o.Infof("Restored backup %s, created at %s, to %q.\n", e.ID, e.CreatedAt.Local().Format(time.DateTime), o.output)
+
+	return nil
+}
+
+// NewCmdRestore creates the restore cobra command.
+func NewCmdRestore(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewRestoreOptions(defaults.StdioOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "restore [DIR] --output FILE",
+		Short: "Restore the vault from a backup directory",
+		Long: `Restore the vault from a backup directory (see 'vlt backup').
+
+The latest backup created at or before --at is restored into a new vault file.
+Incremental backups are applied to the snapshot they were created from, which
+requires the master password the vault had at the time of the backup.
+
+The restored vault is opened using the same password, e.g., 'vlt -f FILE find'.
+
+If DIR is not given, the 'backup.dir' config is used.`,
+		Example: `  # Restore the vault as it was at a given time
+  vlt restore --at '2024-06-01 12:00' -o restored.vlt ~/backups/vlt
+
+  # Restore the vault as it was two days ago
+  vlt restore --at 2d -o restored.vlt ~/backups/vlt
+
+  # Restore the latest backup
+  vlt restore -o restored.vlt ~/backups/vlt`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.at, "at", "", "", "restore the vault as of a relative age (e.g., 12h, 30d) or a time (e.g., '2006-01-02 15:04') (default: latest)")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", "path of the restored vault file, it must not exist")
+
+	return cmd
+}
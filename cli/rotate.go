@@ -0,0 +1,368 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+type RotateError struct {
+	Err error
+}
+
+func (e *RotateError) Error() string { return "rotate: " + e.Err.Error() }
+
+func (e *RotateError) Unwrap() error { return e.Err }
+
+// rotateRequest is written as json to the stdin of rotation hooks.
+type rotateRequest struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	Labels    []string `json:"labels"`
+	Current   string   `json:"current"`
+	Generated string   `json:"generated"`
+}
+
+// rotateResult is the outcome of rotating a secret.
+type rotateResult struct {
+	secret secretWithLabels
+	hook   []string
+	value  string // value is the new secret value, set if the hook succeeded.
+	err    error
+}
+
+func (r rotateResult) status() string {
+	switch {
+	case r.hook == nil:
+		return "skipped: no rotation hook"
+	case r.err != nil:
+		return "failed: " + r.err.Error()
+	default:
+		return "rotated"
+	}
+}
+
+// RotateOptions holds data required to run the command.
+type RotateOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config   *ResolvedConfig
+	search   *SearchableOptions
+	parallel int // parallel is the number of hooks run at a time.
+	dryRun   bool
+}
+
+var _ genericclioptions.CmdOptions = &RotateOptions{}
+
+// NewRotateOptions initializes the options struct.
+func NewRotateOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *RotateOptions {
+	return &RotateOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+		search:       NewSearchableOptions(),
+		parallel:     1,
+	}
+}
+
+func (*RotateOptions) Complete() error { return nil }
+
+func (o *RotateOptions) Validate() error {
+	if err := o.search.Validate(); err != nil {
+		return &RotateError{err}
+	}
+
+	if o.parallel < 1 {
+		return &RotateError{fmt.Errorf("--parallel must be positive, got %d", o.parallel)}
+	}
+
+	if len(o.config.RotationHooks) == 0 {
+		return &RotateError{errors.New("no rotation hooks configured, see the 'rotation.hooks' config")}
+	}
+
+	return nil
+}
+
+func (o *RotateOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &RotateError{retErr}
+			return
+		}
+	}()
+
+	o.search.WildcardFrom(args)
+
+	if len(o.search.Wildcard) == 0 && o.search.ID == 0 && len(o.search.Name) == 0 && len(o.search.Labels) == 0 {
+		return errors.New("no secrets selected, use --label, --name, --id or a glob")
+	}
+
+	secrets, err := o.search.search(ctx, o.vault)
+	if err != nil {
+		return err
+	}
+
+	if len(secrets) == 0 {
+		o.Warnf("No match found.\n")
+		return vaulterrors.ErrSearchNoMatch
+	}
+
+	if o.dryRun {
+		o.printPlan(secrets)
+		return nil
+	}
+
+	results, err := o.rotate(ctx, secrets)
+	if err != nil {
+		return err
+	}
+
+	rotated, failed := printRotateReport(o.Out, results)
+
+	o.Infof("%d rotated, %d failed, %d skipped.\n", rotated, failed, len(results)-rotated-failed)
+
+	if rotated > 0 {
+		if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+			o.Warnf("Post-write hook failed: %v\n", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d secrets failed to rotate, and were left on their old value", failed, len(results))
+	}
+
+	return nil
+}
+
+// rotate runs the rotation hooks of the given secrets, at most
+// [RotateOptions.parallel] at a time, and stores the new values as the
+// hooks succeed. Secrets are left on their old value if their hook fails.
+//
+// Secret values are read, and new values stored, on the calling goroutine:
+// only the hooks run concurrently.
+func (o *RotateOptions) rotate(ctx context.Context, secrets []secretWithLabels) ([]rotateResult, error) {
+	var (
+		results = make([]rotateResult, len(secrets))
+		reqs    = make(map[int]rotateRequest, len(secrets)) // result index to hook request
+	)
+
+	for i, s := range secrets {
+		results[i] = rotateResult{secret: s, hook: o.hookFor(s.labels)}
+		if results[i].hook == nil {
+			continue
+		}
+
+		current, err := o.vault.ShowSecret(ctx, s.id)
+		if err != nil {
+			return nil, err
+		}
+
+		generated, err := o.passwordPolicy.generate(s.labels)
+		if err != nil {
+			return nil, fmt.Errorf("%s: generate: %w", s.name, err)
+		}
+
+		reqs[i] = rotateRequest{ID: s.id, Name: s.name, Labels: s.labels, Current: current, Generated: generated}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, o.parallel)
+		done = make(chan int)
+	)
+
+	for i, req := range reqs {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i].value, results[i].err = runRotateHook(ctx, results[i].hook, req)
+			done <- i
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for i := range done {
+		r := &results[i]
+		if r.err != nil {
+			continue
+		}
+
+		if err := o.store(ctx, r.secret.id, r.value); err != nil {
+			r.err = fmt.Errorf("rotated by the hook, but not stored: %w", err)
+			o.Warnf("vlt: %s: new value not stored: %s\n", r.secret.name, r.value)
+		}
+	}
+
+	return results, nil
+}
+
+// store updates the value of a rotated secret, and persists the vault right
+// away: the old value is no longer valid, and the command may yet fail.
+func (o *RotateOptions) store(ctx context.Context, id int, value string) error {
+	if _, err := o.vault.UpdateSecret(ctx, id, value); err != nil {
+		return err
+	}
+
+	return o.vault.Sync(ctx)
+}
+
+// hookFor returns the rotation hook of a secret having the given labels,
+// or nil if none. If several hooks match, the first by label glob is used.
+func (o *RotateOptions) hookFor(labels []string) []string {
+	for _, glob := range slices.Sorted(maps.Keys(o.config.RotationHooks)) {
+		matches := slices.ContainsFunc(labels, func(l string) bool {
+			ok, err := path.Match(glob, l)
+			return err == nil && ok
+		})
+
+		if matches {
+			return o.config.RotationHooks[glob]
+		}
+	}
+
+	return nil
+}
+
+// runRotateHook runs a rotation hook, and returns the new secret value: the
+// hook output if any, or else the generated value of the request.
+func runRotateHook(ctx context.Context, hook []string, req rotateRequest) (string, error) {
+	in, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, hook[0], hook[1:]...)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+
+		return "", err
+	}
+
+	if value := strings.TrimSpace(stdout.String()); len(value) > 0 {
+		return value, nil
+	}
+
+	return req.Generated, nil
+}
+
+func (o *RotateOptions) printPlan(secrets []secretWithLabels) {
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tHOOK")
+
+	for _, s := range secrets {
+		hook := "-"
+		if h := o.hookFor(s.labels); h != nil {
+			hook = strings.Join(h, " ")
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", s.id, s.name, hook)
+	}
+}
+
+// printRotateReport prints the status of each rotated secret, and returns
+// the number of secrets rotated and failed.
+func printRotateReport(w io.Writer, results []rotateResult) (rotated, failed int) {
+	tw := tabwriter.NewWriter(w, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tSTATUS")
+
+	for _, r := range results {
+		switch {
+		case r.hook == nil:
+		case r.err != nil:
+			failed++
+		default:
+			rotated++
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", r.secret.id, r.secret.name, r.status())
+	}
+
+	fmt.Fprintln(tw) // add padding
+
+	return rotated, failed
+}
+
+// NewCmdRotate creates the rotate cobra command.
+func NewCmdRotate(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewRotateOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "rotate [glob]",
+		Short: "Rotate the matching secrets using their rotation hooks",
+		Long: `Rotate the matching secrets using their rotation hooks.
+
+Rotation hooks are configured as [rotation.hooks] entries mapping label globs
+to commands. A secret is rotated by the hook of its first matching label glob,
+in lexical order; secrets without a matching hook are skipped.
+
+Each hook is given a json object on its stdin, holding the 'id', 'name',
+'labels', 'current' value and a 'generated' value satisfying the password
+policy. A hook applies the new value, e.g., to a database user, and exits
+successfully. The generated value is then stored, unless the hook printed a
+value of its own to stdout, which is stored instead, as is.
+
+Hooks run concurrently, up to --parallel at a time. The new values are stored
+as the hooks succeed. Secrets whose hook fails are left on their old value,
+and reported along with the hook error.`,
+		Example: `  # Configure a rotation hook
+  # [rotation.hooks]
+  # 'prod/db/*' = ["rotate-pg"]
+
+  # Rotate the production database secrets, 4 at a time
+  vlt rotate --label 'prod/db/*' --parallel 4
+
+  # List the secrets that would be rotated, along with their hooks
+  vlt rotate --label 'prod/db/*' --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().IntVarP(&o.search.ID, "id", "", 0, FilterByID.Help())
+	cmd.Flags().StringVarP(&o.search.Name, "name", "", "", FilterByName.Help())
+	cmd.Flags().StringSliceVarP(&o.search.Labels, "label", "", nil, FilterByLabels.Help())
+
+	cmd.Flags().IntVarP(&o.parallel, "parallel", "p", 1, "number of rotation hooks run at a time")
+	cmd.Flags().BoolVarP(&o.dryRun, "dry-run", "n", false, "list the matching secrets and their hooks, without rotating them")
+
+	return cmd
+}
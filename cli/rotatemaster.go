@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/input"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+type RotateMasterError struct {
+	Err error
+}
+
+func (e *RotateMasterError) Error() string { return "rotate master: " + e.Err.Error() }
+
+func (e *RotateMasterError) Unwrap() error { return e.Err }
+
+// RotateMasterOptions holds data required to run the command.
+type RotateMasterOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &RotateMasterOptions{}
+
+// NewRotateMasterOptions initializes the options struct.
+func NewRotateMasterOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *RotateMasterOptions {
+	return &RotateMasterOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*RotateMasterOptions) Complete() error { return nil }
+
+func (o *RotateMasterOptions) Validate() error {
+	if o.NonInteractive {
+		return &RotateMasterError{vaulterrors.ErrNonInteractiveUnsupported}
+	}
+
+	return nil
+}
+
+func (o *RotateMasterOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &RotateMasterError{retErr}
+			return
+		}
+	}()
+
+	// the vault may have been unlocked using a session key.
+	password, err := input.PromptReadSecure(o.Out, int(o.In.Fd()), "[vlt] Current password for %q:", o.path)
+	if err != nil {
+		return fmt.Errorf("prompt password: %v", err)
+	}
+
+	newPassword, err := input.PromptNewPassword(o.Out, int(o.In.Fd()), masterPasswordMinLen)
+	if err != nil {
+		return fmt.Errorf("read new master key: %w", err)
+	}
+
+	revoked, err := o.vault.RotateMasterPassword(ctx, password, newPassword)
+	if err != nil {
+		return err
+	}
+
+	o.Infof("Master password rotated, all secrets re-encrypted.\n")
+
+	if revoked > 0 {
+		o.Warnf("Revoked %d access token(s), issue new ones using 'vlt token issue'.\n", revoked)
+	}
+
+	o.refreshSession(ctx, newPassword)
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		o.Warnf("Post-write hook failed: %v", err)
+	}
+
+	return nil
+}
+
+// refreshSession replaces the vault key held by the session and cached in
+// the Keychain, if enabled, with the one derived from the new password.
+func (o *RotateMasterOptions) refreshSession(ctx context.Context, password string) {
+	key, nonce, err := vault.Login(ctx, o.path, password)
+	if err != nil {
+		o.Warnf("vlt: session not refreshed, log in again using 'vlt login': %v\n", err)
+		return
+	}
+
+	// nil-safe: sessionClient methods handle nil receivers safely.
+	_ = o.sessionClient.Login(ctx, o.path, key, nonce, o.sessionDuration)
+	o.cacheKeychainKey(ctx, o.StdioOptions, key, nonce)
+}
+
+// NewCmdRotateMaster creates the rotate-master cobra command.
+func NewCmdRotateMaster(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewRotateMasterOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "rotate-master",
+		Short: "Change the master password, re-encrypting the vault",
+		Long: `Change the master password of the vault.
+
+The current password is prompted for, followed by the new one. A new key is
+derived from the new password, and all secrets, including trashed ones, their
+previous versions and attachments are re-encrypted using it. The vault is
+updated in a single transaction, and is left unchanged on failure.
+
+Access tokens are encrypted using the previous key and are revoked. API tokens
+of 'vlt serve' hold no key and remain valid. Previous versions of the vault
+file are removed as well.
+
+Files encrypted using 'vlt encrypt' remain decryptable: the key they are
+encrypted under is kept in the vault on the first rotation. Linked secrets keep
+their cached values, which are re-encrypted along with all other secrets.
+
+Anything else encrypted using the previous key outside of the vault file keeps
+requiring the previous password: backups and vaults pushed to cloud storage,
+and keys derived for browser extensions or integrations, which should be set
+up again.`,
+		Example: `  # Change the master password
+  vlt rotate-master`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
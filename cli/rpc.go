@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vaultserver"
+
+	"github.com/spf13/cobra"
+)
+
+type RPCError struct {
+	Err error
+}
+
+func (e *RPCError) Error() string { return "rpc: " + e.Err.Error() }
+
+func (e *RPCError) Unwrap() error { return e.Err }
+
+// RPCOptions holds data required to run the command.
+type RPCOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &RPCOptions{}
+
+// NewRPCOptions initializes the options struct.
+func NewRPCOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *RPCOptions {
+	return &RPCOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*RPCOptions) Complete() error { return nil }
+
+func (*RPCOptions) Validate() error { return nil }
+
+func (o *RPCOptions) Run(ctx context.Context, _ ...string) error {
+	o.Debugf("vlt: serving JSON-RPC for vault %s on stdio\n", o.path)
+
+	if err := vaultserver.New(o.vault).ServeJSONRPC(ctx, o.In, o.Out); err != nil {
+		return &RPCError{err}
+	}
+
+	return nil
+}
+
+// NewCmdRPC creates the rpc cobra command.
+func NewCmdRPC(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewRPCOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "rpc",
+		Short: "Serve the vault over JSON-RPC on stdin and stdout",
+		Long: `Unlock the vault and serve JSON-RPC 2.0 requests read from stdin until it is closed.
+
+Intended for editor plugins and tools spawning vlt as a subprocess, instead of
+connecting to 'vlt serve'. Requests and responses are single lines of JSON.
+Notifications (requests without an id) are not answered, and batches are not supported.
+
+Since stdin carries the requests, the vault cannot be unlocked using a password
+prompt: log in beforehand ('vlt login'), or set VLT_TOKEN to an access token,
+which also limits the requests to the token scope.
+
+Methods (params are passed by name):
+	search    {"query", "name", "labels"}              list secrets without their values
+	get       {"id"}                                   show a secret, including its value
+	put       {"id", "name", "labels", "secret"}       create a secret if id is 0, otherwise update its value
+	delete    {"id"}                                   delete a secret
+
+Errors use the standard JSON-RPC codes, and -32001 (unauthorized),
+-32003 (forbidden) and -32004 (secret not found) for vault errors.
+
+The vault is persisted after every request.`,
+		Example: `  # Search secrets labeled 'ci'
+  echo '{"jsonrpc":"2.0","id":1,"method":"search","params":{"labels":["ci"]}}' | vlt rpc
+
+  # Show a secret
+  echo '{"jsonrpc":"2.0","id":1,"method":"get","params":{"id":1}}' | vlt rpc`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
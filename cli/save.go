@@ -4,14 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/ladzaretti/vlt-cli/clierror"
 	"github.com/ladzaretti/vlt-cli/clipboard"
 	"github.com/ladzaretti/vlt-cli/genericclioptions"
 	"github.com/ladzaretti/vlt-cli/input"
-	"github.com/ladzaretti/vlt-cli/randstring"
+	"github.com/ladzaretti/vlt-cli/urlmatch"
 	cmdutil "github.com/ladzaretti/vlt-cli/util"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
 	"github.com/ladzaretti/vlt-cli/vaulterrors"
 
 	"github.com/spf13/cobra"
@@ -34,10 +36,14 @@ type SaveOptions struct {
 
 	name     string   // name is the name of the secret to save in the vault.
 	labels   []string // labels to associate with the a given secret.
+	urls     []string // urls are the URL patterns of the login, saved as labels, the first one also as its metadata URL.
+	username string   // username is the login username, saved as metadata.
+	notes    string   // notes are free-form notes, saved as metadata.
 	generate bool     // generate indicates whether to auto-generate a random secret.
 	output   bool     // output controls whether to print the saved secret to stdout.
 	copy     bool     // copy controls whether to copy the saved secret to the clipboard.
-	paste    bool     // paste controls whether to read the secret to save from the clipboard.
+	paste    bool     // paste controls whether to read the secret to save from the clipboard, clearing it afterwards.
+	binary   bool     // binary controls whether to read the secret from stdin as is, as binary data.
 }
 
 var _ genericclioptions.CmdOptions = &SaveOptions{}
@@ -57,10 +63,20 @@ func (o *SaveOptions) Validate() error {
 		return fmt.Errorf("invalid --name value %q (must not start with '-')", o.name)
 	}
 
+	for _, u := range o.urls {
+		if !urlmatch.IsPattern(u) {
+			return fmt.Errorf("invalid --url value %q (must be a URL with a scheme and a host, e.g., https://example.com/login)", u)
+		}
+	}
+
+	if o.binary && !o.NonInteractive {
+		return &SaveError{errors.New("--binary reads the secret from stdin, which must be piped or redirected")}
+	}
+
 	return o.validateInputSource()
 }
 
-func (o *SaveOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+func (o *SaveOptions) Run(ctx context.Context, args ...string) (retErr error) {
 	secret := ""
 
 	// ensure error is wrapped and output is printed if everything succeeded
@@ -78,13 +94,27 @@ func (o *SaveOptions) Run(ctx context.Context, _ ...string) (retErr error) {
 		}
 	}()
 
+	if len(args) > 0 {
+		if len(o.name) > 0 {
+			return errors.New("the name can be given either as an argument or using --name, not both")
+		}
+
+		o.name = args[0]
+	}
+
+	o.labels = append(o.labels, o.urls...)
+
 	s, err := o.readSecretNonInteractive()
 	if err != nil {
 		return fmt.Errorf("read secret non-interactive: %w", err)
 	}
 
-	interactive := len(s) == 0
-	secret = strings.TrimSpace(s)
+	interactive := len(s) == 0 && !o.binary
+
+	secret = s
+	if !o.binary {
+		secret = strings.TrimSpace(s)
+	}
 
 	if interactive {
 		err := o.readInteractive(&secret)
@@ -101,6 +131,10 @@ func (o *SaveOptions) Run(ctx context.Context, _ ...string) (retErr error) {
 		return vaulterrors.ErrEmptySecret
 	}
 
+	if err := o.checkPolicy(secret); err != nil {
+		return err
+	}
+
 	if len(o.labels) == 0 && !interactive {
 		o.Warnf(`No labels were provided for the secret in non-interactive mode. 
 You may want to add labels using the '--label' flag or interactively.\n`)
@@ -119,12 +153,20 @@ You may want to add labels using the '--label' flag or interactively.\n`)
 
 func (o *SaveOptions) readSecretNonInteractive() (string, error) {
 	if o.generate {
-		return randstring.NewWithPolicy(randstring.DefaultPasswordPolicy)
+		return o.passwordPolicy.generate(o.labels)
 	}
 
 	if o.paste {
-		o.Debugf("reading secret from clipboard")
-		return clipboard.Paste()
+		o.Debugf("reading secret from clipboard\n")
+		return o.pasteAndClear()
+	}
+
+	if o.NonInteractive && o.binary {
+		o.Debugf("reading binary secret")
+
+		b, err := io.ReadAll(o.In)
+
+		return string(b), err
 	}
 
 	if o.NonInteractive {
@@ -135,6 +177,21 @@ func (o *SaveOptions) readSecretNonInteractive() (string, error) {
 	return "", nil
 }
 
+// pasteAndClear reads the secret from the clipboard and clears it,
+// so that the secret does not linger there once saved.
+func (o *SaveOptions) pasteAndClear() (string, error) {
+	s, err := clipboard.Paste()
+	if err != nil {
+		return "", err
+	}
+
+	if err := clipboard.Clear(); err != nil {
+		o.Warnf("vlt: failed to clear the clipboard: %v\n", err)
+	}
+
+	return s, nil
+}
+
 func (o *SaveOptions) readInteractive(secret *string) error {
 	if len(o.name) == 0 {
 		k, err := o.promptRead("Enter name: ")
@@ -174,8 +231,23 @@ func (o *SaveOptions) promptReadSecure(prompt string, a ...any) (string, error)
 	return input.PromptReadSecure(o.Out, int(o.In.Fd()), prompt, a...)
 }
 
+// checkPolicy checks the secret against the password policy.
+// Binary secrets are not passwords, only their labels are checked.
+func (o *SaveOptions) checkPolicy(secret string) error {
+	if o.binary {
+		return o.passwordPolicy.checkLabels(o.StdioOptions, o.labels)
+	}
+
+	return o.passwordPolicy.check(o.StdioOptions, secret, o.labels)
+}
+
 func (o *SaveOptions) insertNewSecret(ctx context.Context, s string) error {
-	n, err := o.vault.InsertNewSecret(ctx, o.name, s, o.labels)
+	meta := vaultdb.SecretMeta{Username: o.username, Notes: o.notes}
+	if len(o.urls) > 0 {
+		meta.URL = o.urls[0]
+	}
+
+	n, err := o.vault.InsertSecretWithMeta(ctx, o.name, s, o.labels, meta)
 	if err != nil {
 		return err
 	}
@@ -189,6 +261,10 @@ func (o *SaveOptions) insertNewSecret(ctx context.Context, s string) error {
 
 func (o *SaveOptions) outputSecret(s string) error {
 	if o.output {
+		if ok, err := o.confirmPrint(o.StdioOptions, secretDescription(o.name)); !ok {
+			return err
+		}
+
 		o.Infof("%s", s)
 		return nil
 	}
@@ -216,7 +292,7 @@ func (o *SaveOptions) validateInputSource() error {
 	}
 
 	if used > 1 {
-		return &SaveError{errors.New("only one of non-interactive input, --generate, or --paste-clipboard can be used at a time")}
+		return &SaveError{errors.New("only one of non-interactive input, --generate, or --from-clipboard can be used at a time")}
 	}
 
 	return nil
@@ -230,8 +306,8 @@ func NewCmdSave(defaults *DefaultVltOptions) *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:     "save",
-		Aliases: []string{"put"},
+		Use:     "save [name]",
+		Aliases: []string{"put", "add"},
 		Short:   "Save a new secret to the vault",
 		Long: `Save a new key-value pair to the vault.
 
@@ -243,19 +319,52 @@ Note 1:
 
 Note 2:
 	If data is piped or redirected into the command (i.e., stdin is not a TTY),
-	metadata must be provided as command-line arguments. Interactive prompts will be skipped in this case.`,
-		Run: func(cmd *cobra.Command, _ []string) {
-			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+	metadata must be provided as command-line arguments. Interactive prompts will be skipped in this case.
+
+Note 3:
+	Piped or redirected values are trimmed of surrounding whitespace, unless --binary
+	is set: the value is then saved exactly as read, e.g., a DER encoded key or random
+	bytes, and is not checked against the password policy. Print it using 'vlt get --raw'.
+
+Note 4:
+	With --from-clipboard, the clipboard is cleared once the secret is read from it,
+	e.g., after copying a new password from a browser reset flow.
+
+Note 5:
+	The first --url, --username and --notes are saved as metadata of the secret,
+	listed by 'vlt find -o json' and updated using 'vlt update'.
+	All --url values are also saved as labels, matched by 'vlt match'. The metadata
+	URL is the one listed and opened, 'vlt update --set-url' does not change the labels.`,
+		Example: `  # Save the password in the clipboard, clearing it
+  vlt add github --label work --from-clipboard
+
+  # Save a generated password of a login
+  vlt add github --generate --url https://github.com/login --username octocat
+
+  # Save a DER encoded key as is
+  vlt add --binary --name tls/key.der < key.der
+
+  # Save random bytes
+  head -c 32 /dev/urandom | vlt add --binary --name session-key`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
 		},
 	}
 
 	cmd.Flags().BoolVarP(&o.generate, "generate", "g", false, "generate a random secret")
 	cmd.Flags().BoolVarP(&o.output, "output", "o", false, "output the saved secret to stdout (unsafe)")
 	cmd.Flags().BoolVarP(&o.copy, "copy-clipboard", "c", false, "copy the saved secret to the clipboard")
-	cmd.Flags().BoolVarP(&o.paste, "paste-clipboard", "p", false, "read the secret from the clipboard")
+	cmd.Flags().BoolVarP(&o.paste, "from-clipboard", "p", false, "read the secret from the clipboard, clearing it afterwards")
+	cmd.Flags().BoolVarP(&o.paste, "paste-clipboard", "", false, "read the secret from the clipboard, clearing it afterwards")
+	_ = cmd.Flags().MarkDeprecated("paste-clipboard", "use --from-clipboard instead")
+	cmd.Flags().BoolVarP(&o.binary, "binary", "", false, "save the secret read from stdin as is, as binary data")
 
 	cmd.Flags().StringVarP(&o.name, "name", "", "", "the secret name (e.g., username)")
 	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "optional label to associate with the secret (comma-separated or repeated)")
+	cmd.Flags().StringSliceVarP(&o.urls, "url", "", nil, "URL pattern of the login, saved as a label matched by 'vlt match' (comma-separated or repeated)")
+	cmd.Flags().StringVarP(&o.username, "username", "", "", "the login username, saved as metadata")
+	cmd.Flags().StringVarP(&o.notes, "notes", "", "", "free-form notes, saved as metadata")
 
 	return cmd
 }
@@ -0,0 +1,547 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultRemindEvery is how long until secrets left due are reminded of, or
+// rotated, again, unless set using --remind-every.
+const defaultRemindEvery = 24 * time.Hour
+
+type SchedulerError struct {
+	Err error
+}
+
+func (e *SchedulerError) Error() string { return "scheduler: " + e.Err.Error() }
+
+func (e *SchedulerError) Unwrap() error { return e.Err }
+
+// scheduledSecret is a secret scheduled for rotation.
+type scheduledSecret struct {
+	secret secretWithLabels
+	every  time.Duration
+	label  string    // label is the glob the interval is configured for, empty if set for the secret.
+	due    time.Time // due is the time the next rotation is due.
+}
+
+// SchedulerOptions holds data required to run the command.
+type SchedulerOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config      *ResolvedConfig
+	parallel    int    // parallel is the number of rotation hooks run at a time.
+	remindEvery string // remindEvery is how long until secrets left due are handled again.
+	dryRun      bool
+
+	remindAfter time.Duration
+}
+
+var _ genericclioptions.CmdOptions = &SchedulerOptions{}
+
+// NewSchedulerOptions initializes the options struct.
+func NewSchedulerOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *SchedulerOptions {
+	return &SchedulerOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+		parallel:     1,
+	}
+}
+
+func (o *SchedulerOptions) Complete() error {
+	if len(o.remindEvery) == 0 {
+		o.remindAfter = defaultRemindEvery
+		return nil
+	}
+
+	d, err := cmdutil.ParseDuration(o.remindEvery)
+	if err != nil {
+		return &SchedulerError{fmt.Errorf("--remind-every: %w", err)}
+	}
+
+	o.remindAfter = d
+
+	return nil
+}
+
+func (o *SchedulerOptions) Validate() error {
+	if o.parallel < 1 {
+		return &SchedulerError{fmt.Errorf("--parallel must be positive, got %d", o.parallel)}
+	}
+
+	if o.remindAfter <= 0 {
+		return &SchedulerError{errors.New("--remind-every must be positive")}
+	}
+
+	return nil
+}
+
+func (o *SchedulerOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &SchedulerError{retErr}
+			return
+		}
+	}()
+
+	now := time.Now()
+
+	scheduled, err := o.rotationSchedule(ctx, o.config.RotationIntervals)
+	if err != nil {
+		return err
+	}
+
+	due := slices.DeleteFunc(scheduled, func(s scheduledSecret) bool { return s.due.After(now) })
+	if len(due) == 0 {
+		o.Infof("No secrets due for rotation.\n")
+		return nil
+	}
+
+	if o.dryRun {
+		printSchedule(o.Out, due, now)
+		return nil
+	}
+
+	rotator := &RotateOptions{
+		StdioOptions: o.StdioOptions,
+		VaultOptions: o.VaultOptions,
+		config:       o.config,
+		parallel:     o.parallel,
+	}
+
+	var (
+		hooked   []secretWithLabels
+		reminded []scheduledSecret
+		every    = make(map[int]time.Duration, len(due)) // secret id to rotation interval
+	)
+
+	for _, s := range due {
+		every[s.secret.id] = s.every
+
+		if rotator.hookFor(s.secret.labels) == nil {
+			reminded = append(reminded, s)
+			continue
+		}
+
+		hooked = append(hooked, s.secret)
+	}
+
+	results, err := rotator.rotate(ctx, hooked)
+	if err != nil {
+		return err
+	}
+
+	var rotated, failed int
+
+	if len(results) > 0 {
+		rotated, failed = printRotateReport(o.Out, results)
+		o.Infof("%d rotated, %d failed.\n", rotated, failed)
+	}
+
+	// the next rotation is due an interval after a rotation, or else once it
+	// is time to remind of it again.
+	for _, r := range results {
+		next := now.Add(o.remindAfter)
+		if r.err == nil {
+			next = now.Add(every[r.secret.id])
+		}
+
+		if err := o.vault.PostponeRotation(ctx, r.secret.id, next); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range reminded {
+		if err := o.vault.PostponeRotation(ctx, s.secret.id, now.Add(o.remindAfter)); err != nil {
+			return err
+		}
+	}
+
+	if len(reminded) > 0 {
+		o.remind(ctx, reminded, now)
+	}
+
+	if rotated > 0 {
+		if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+			o.Warnf("Post-write hook failed: %v\n", err)
+		}
+	}
+
+	if failed == 0 {
+		return nil
+	}
+
+	// the vault is only persisted by successful commands.
+	if err := o.vault.Sync(ctx); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%d of %d secrets failed to rotate, and were left on their old value", failed, len(results))
+}
+
+// remind prints the given secrets due for rotation without a rotation hook,
+// and runs the reminder hook, if configured.
+func (o *SchedulerOptions) remind(ctx context.Context, secrets []scheduledSecret, now time.Time) {
+	report := &bytes.Buffer{}
+
+	fmt.Fprintf(report, "%d secrets are due for rotation:\n\n", len(secrets))
+	printSchedule(report, secrets, now)
+
+	o.Infof("%s", report.String())
+
+	if hook := o.config.RotationReminderCmd; len(hook) > 0 {
+		o.Infof("running hook: %q %q\n", hook[0], hook[1:])
+
+		if err := genericclioptions.RunCommandWithInput(ctx, o.StdioOptions, report, hook[0], hook[1:]...); err != nil {
+			o.Warnf("Rotation reminder hook failed: %v\n", err)
+		}
+	}
+}
+
+// rotationSchedule returns the secrets of the vault scheduled for rotation,
+// ordered by the time their rotation is due.
+//
+// Secrets are scheduled using 'vlt scheduler set', or else by the shortest
+// interval configured for their labels. A rotation is due an interval after
+// the secret value was last set, unless postponed.
+func (o *VaultOptions) rotationSchedule(ctx context.Context, intervals map[string]Duration) ([]scheduledSecret, error) {
+	secrets, err := NewSearchableOptions().search(ctx, o.vault)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := o.vault.RotationSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changedAt, err := o.vault.SecretsChangedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := make([]scheduledSecret, 0, len(schedules))
+
+	for _, s := range secrets {
+		ss := scheduledSecret{secret: s, every: schedules[s.id].Every}
+		if ss.every == 0 {
+			ss.every, ss.label = labelInterval(intervals, s.labels)
+		}
+
+		if ss.every == 0 {
+			continue
+		}
+
+		ss.due = changedAt[s.id].Add(ss.every)
+		if dueAt := schedules[s.id].DueAt; dueAt.After(ss.due) {
+			ss.due = dueAt
+		}
+
+		scheduled = append(scheduled, ss)
+	}
+
+	slices.SortStableFunc(scheduled, func(a, b scheduledSecret) int { return a.due.Compare(b.due) })
+
+	return scheduled, nil
+}
+
+// labelInterval returns the shortest of the intervals configured for the
+// given labels, along with the label glob it is configured for.
+// It returns zero if none are configured.
+func labelInterval(intervals map[string]Duration, labels []string) (time.Duration, string) {
+	var (
+		every time.Duration
+		glob  string
+	)
+
+	// sorted for a deterministic glob among equal intervals.
+	for _, g := range slices.Sorted(maps.Keys(intervals)) {
+		matches := slices.ContainsFunc(labels, func(l string) bool {
+			ok, err := path.Match(g, l)
+			return err == nil && ok
+		})
+
+		if d := time.Duration(intervals[g]); matches && (every == 0 || d < every) {
+			every, glob = d, g
+		}
+	}
+
+	return every, glob
+}
+
+func printSchedule(w io.Writer, secrets []scheduledSecret, now time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tEVERY\tSCHEDULED BY\tDUE")
+
+	for _, s := range secrets {
+		by := "secret"
+		if len(s.label) > 0 {
+			by = "label " + s.label
+		}
+
+		due := s.due.Local().Format(time.DateTime)
+		if !s.due.After(now) {
+			due += " (due)"
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", s.secret.id, s.secret.name, formatInterval(s.every), by, due)
+	}
+
+	fmt.Fprintln(tw) // add padding
+}
+
+// formatInterval formats d in whole days if possible, e.g., "90d".
+func formatInterval(d time.Duration) string {
+	const day = 24 * time.Hour
+
+	if d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+
+	return d.String()
+}
+
+// SchedulerListOptions holds data required to run the command.
+type SchedulerListOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config *ResolvedConfig
+}
+
+var _ genericclioptions.CmdOptions = &SchedulerListOptions{}
+
+// NewSchedulerListOptions initializes the options struct.
+func NewSchedulerListOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *SchedulerListOptions {
+	return &SchedulerListOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+	}
+}
+
+func (*SchedulerListOptions) Complete() error { return nil }
+
+func (*SchedulerListOptions) Validate() error { return nil }
+
+func (o *SchedulerListOptions) Run(ctx context.Context, _ ...string) error {
+	scheduled, err := o.rotationSchedule(ctx, o.config.RotationIntervals)
+	if err != nil {
+		return &SchedulerError{err}
+	}
+
+	if len(scheduled) == 0 {
+		o.Warnf("No secrets scheduled for rotation.\n")
+		return nil
+	}
+
+	printSchedule(o.Out, scheduled, time.Now())
+
+	return nil
+}
+
+// SchedulerSetOptions holds data required to run the command.
+type SchedulerSetOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &SchedulerSetOptions{}
+
+// NewSchedulerSetOptions initializes the options struct.
+func NewSchedulerSetOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *SchedulerSetOptions {
+	return &SchedulerSetOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*SchedulerSetOptions) Complete() error { return nil }
+
+func (*SchedulerSetOptions) Validate() error { return nil }
+
+func (o *SchedulerSetOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &SchedulerError{retErr}
+			return
+		}
+	}()
+
+	every, err := cmdutil.ParseDuration(args[1])
+	if err != nil {
+		return err
+	}
+
+	s, err := o.secretNamed(ctx, o.StdioOptions, args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(s.mount) > 0 {
+		return fmt.Errorf("%q: secrets of mounts cannot be scheduled", s.name)
+	}
+
+	if err := o.vault.ScheduleRotation(ctx, s.id, every); err != nil {
+		return err
+	}
+
+	return genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite)
+}
+
+// SchedulerUnsetOptions holds data required to run the command.
+type SchedulerUnsetOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &SchedulerUnsetOptions{}
+
+// NewSchedulerUnsetOptions initializes the options struct.
+func NewSchedulerUnsetOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *SchedulerUnsetOptions {
+	return &SchedulerUnsetOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*SchedulerUnsetOptions) Complete() error { return nil }
+
+func (*SchedulerUnsetOptions) Validate() error { return nil }
+
+func (o *SchedulerUnsetOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &SchedulerError{retErr}
+			return
+		}
+	}()
+
+	s, err := o.secretNamed(ctx, o.StdioOptions, args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(s.mount) > 0 {
+		return fmt.Errorf("%q: secrets of mounts cannot be scheduled", s.name)
+	}
+
+	if err := o.vault.UnscheduleRotation(ctx, s.id); err != nil {
+		return err
+	}
+
+	return genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite)
+}
+
+// NewCmdScheduler creates the scheduler cobra command.
+func NewCmdScheduler(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSchedulerOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "scheduler",
+		Short: "Rotate the secrets due for rotation (subcommands available)",
+		Long: `Rotate the secrets due for rotation, or remind of them.
+
+Secrets are scheduled for rotation using 'vlt scheduler set', or by label
+using the [rotation.intervals] config, e.g., 'prod/*' = '90d'. If several
+label intervals apply, the shortest is used. A rotation is due an interval
+after the secret value was last set, by any command.
+
+Due secrets having a rotation hook are rotated as by 'vlt rotate', their next
+rotation is due an interval later. Due secrets without one are reminded of,
+using the 'rotation.reminder_cmd' hook if configured. Secrets left due, after
+a reminder or a failed rotation, are handled again after --remind-every.
+
+The next due times are kept in the vault, making the command suitable for a
+scheduled job (e.g., a systemd timer). Schedules are part of the vault, but
+are not synced between devices.`,
+		Example: `  # Rotate a secret every 30 days
+  vlt scheduler set db-admin 30d
+
+  # List the scheduled secrets
+  vlt scheduler list
+
+  # List the secrets due for rotation, without rotating them
+  vlt scheduler --dry-run
+
+  # Rotate the due secrets, 4 at a time
+  vlt scheduler --parallel 4`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().IntVarP(&o.parallel, "parallel", "p", 1, "number of rotation hooks run at a time")
+	cmd.Flags().StringVarP(&o.remindEvery, "remind-every", "", "", "how long until secrets left due are handled again (default: 1d)")
+	cmd.Flags().BoolVarP(&o.dryRun, "dry-run", "n", false, "list the secrets due for rotation, without rotating them")
+
+	cmd.AddCommand(NewCmdSchedulerList(defaults))
+	cmd.AddCommand(NewCmdSchedulerSet(defaults))
+	cmd.AddCommand(NewCmdSchedulerUnset(defaults))
+
+	return cmd
+}
+
+// NewCmdSchedulerList creates the scheduler list cobra command.
+func NewCmdSchedulerList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSchedulerListOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the secrets scheduled for rotation, by due time",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
+
+// NewCmdSchedulerSet creates the scheduler set cobra command.
+func NewCmdSchedulerSet(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSchedulerSetOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:               "set NAME INTERVAL",
+		Short:             "Schedule a secret for rotation every interval, e.g., '30d'",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeSecretNames(defaults),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
+
+// NewCmdSchedulerUnset creates the scheduler unset cobra command.
+func NewCmdSchedulerUnset(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSchedulerUnsetOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:               "unset NAME",
+		Short:             "Remove the rotation interval set for a secret, along with its postponed due time",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(defaults),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
@@ -0,0 +1,334 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/query"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+// queryHelp describes the search query syntax, see [query].
+const queryHelp = `A query is made of whitespace-separated terms, all of which a secret must
+match. Terms are negated by a leading '-', and values holding spaces are
+double-quoted, e.g., name:"my secret". The supported terms are:
+
+  label:GLOB     the secret has a label matching GLOB
+  name:GLOB      the secret name matches GLOB
+  url:GLOB       the secret has a URL label whose host, or the whole URL, matches GLOB
+  modified:>AGE  the secret value was last set more than AGE ago
+  modified:<AGE  the secret value was last set less than AGE ago
+  GLOB           the secret name or any of its labels matches GLOB
+
+Globs are matched as by 'vlt find', e.g., 'work/*' matches 'work/dev/db'.
+Ages are durations, e.g., '12h', '90d' or '2w'.`
+
+type SearchError struct {
+	Err error
+}
+
+func (e *SearchError) Error() string { return "search: " + e.Err.Error() }
+
+func (e *SearchError) Unwrap() error { return e.Err }
+
+// NewCmdSearch creates the search cobra command.
+func NewCmdSearch(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search",
+		Short: "Manage saved searches (subcommands available)",
+		Long: `Save named search queries in the vault, and run them.
+
+` + queryHelp + `
+
+Saved searches are part of the vault, but are not synced between devices.`,
+		Example: `  # Save a search of the work secrets, not archived, unchanged for 90 days
+  vlt search save work-stale 'label:work/* -label:archive modified:>90d'
+
+  # Run it
+  vlt search run work-stale`,
+	}
+
+	cmd.AddCommand(NewCmdSearchSave(defaults))
+	cmd.AddCommand(NewCmdSearchRun(defaults))
+	cmd.AddCommand(NewCmdSearchList(defaults))
+	cmd.AddCommand(NewCmdSearchRemove(defaults))
+
+	return cmd
+}
+
+// SearchSaveOptions holds data required to run the command.
+type SearchSaveOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &SearchSaveOptions{}
+
+// NewSearchSaveOptions initializes the options struct.
+func NewSearchSaveOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *SearchSaveOptions {
+	return &SearchSaveOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*SearchSaveOptions) Complete() error { return nil }
+
+func (*SearchSaveOptions) Validate() error { return nil }
+
+func (o *SearchSaveOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &SearchError{retErr}
+			return
+		}
+	}()
+
+	name, raw := args[0], args[1]
+
+	if _, err := query.Parse(raw); err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	if err := o.vault.SaveSearch(ctx, name, raw); err != nil {
+		return err
+	}
+
+	return genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite)
+}
+
+// SearchRunOptions holds data required to run the command.
+type SearchRunOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &SearchRunOptions{}
+
+// NewSearchRunOptions initializes the options struct.
+func NewSearchRunOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *SearchRunOptions {
+	return &SearchRunOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*SearchRunOptions) Complete() error { return nil }
+
+func (*SearchRunOptions) Validate() error { return nil }
+
+func (o *SearchRunOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &SearchError{retErr}
+			return
+		}
+	}()
+
+	saved, err := o.vault.SavedSearch(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	q, err := query.Parse(saved.Query)
+	if err != nil {
+		return fmt.Errorf("%q: invalid query: %w", saved.Name, err)
+	}
+
+	secrets, err := o.searchQuery(ctx, o.StdioOptions, q)
+	if err != nil {
+		return err
+	}
+
+	if len(secrets) == 0 {
+		return vaulterrors.ErrSearchNoMatch
+	}
+
+	printTable(o.Out, secrets, o.labelColors(ctx, o.Out))
+
+	return nil
+}
+
+// searchQuery returns the secrets of the vault and its mounts matching q.
+func (o *VaultOptions) searchQuery(ctx context.Context, io *genericclioptions.StdioOptions, q *query.Query) ([]secretWithLabels, error) {
+	secrets, err := o.searchAll(ctx, io, NewSearchableOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	return o.filterQuery(ctx, io, secrets, q)
+}
+
+// filterQuery returns the given secrets matching q, in order.
+// Modification times are only looked up if used by q.
+func (o *VaultOptions) filterQuery(ctx context.Context, io *genericclioptions.StdioOptions, secrets []secretWithLabels, q *query.Query) ([]secretWithLabels, error) {
+	var (
+		now       = time.Now()
+		changedAt = make(map[string]map[int]time.Time) // mount name to secret modification times
+		matching  = make([]secretWithLabels, 0, len(secrets))
+	)
+
+	for _, s := range secrets {
+		qs := query.Secret{Name: s.name, Labels: s.labels}
+
+		if q.UsesModified() {
+			if _, ok := changedAt[s.mount]; !ok {
+				v, err := o.vaultOf(ctx, io, s)
+				if err != nil {
+					return nil, err
+				}
+
+				if changedAt[s.mount], err = v.SecretsChangedAt(ctx); err != nil {
+					return nil, err
+				}
+			}
+
+			qs.ModifiedAt = changedAt[s.mount][s.id]
+		}
+
+		if q.Match(qs, now) {
+			matching = append(matching, s)
+		}
+	}
+
+	return matching, nil
+}
+
+// SearchListOptions holds data required to run the command.
+type SearchListOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &SearchListOptions{}
+
+// NewSearchListOptions initializes the options struct.
+func NewSearchListOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *SearchListOptions {
+	return &SearchListOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*SearchListOptions) Complete() error { return nil }
+
+func (*SearchListOptions) Validate() error { return nil }
+
+func (o *SearchListOptions) Run(ctx context.Context, _ ...string) error {
+	searches, err := o.vault.SavedSearches(ctx)
+	if err != nil {
+		return &SearchError{err}
+	}
+
+	if len(searches) == 0 {
+		o.Warnf("No saved searches found.\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "NAME\tQUERY\tSAVED")
+
+	for _, ss := range searches {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", ss.Name, ss.Query, ss.CreatedAt.Local().Format(time.DateTime))
+	}
+
+	return nil
+}
+
+// SearchRemoveOptions holds data required to run the command.
+type SearchRemoveOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &SearchRemoveOptions{}
+
+// NewSearchRemoveOptions initializes the options struct.
+func NewSearchRemoveOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *SearchRemoveOptions {
+	return &SearchRemoveOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*SearchRemoveOptions) Complete() error { return nil }
+
+func (*SearchRemoveOptions) Validate() error { return nil }
+
+func (o *SearchRemoveOptions) Run(ctx context.Context, args ...string) error {
+	if err := o.vault.DeleteSavedSearch(ctx, args[0]); err != nil {
+		return &SearchError{err}
+	}
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		return &SearchError{err}
+	}
+
+	return nil
+}
+
+// NewCmdSearchSave creates the search save cobra command.
+func NewCmdSearchSave(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSearchSaveOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "save NAME QUERY",
+		Short: "Save a search query under a name, replacing any saved under it",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
+
+// NewCmdSearchRun creates the search run cobra command.
+func NewCmdSearchRun(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSearchRunOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "run NAME",
+		Short: "List the secrets of the vault and its mounts matching a saved search",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
+
+// NewCmdSearchList creates the search list cobra command.
+func NewCmdSearchList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSearchListOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the saved searches",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
+
+// NewCmdSearchRemove creates the search remove cobra command.
+func NewCmdSearchRemove(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSearchRemoveOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "remove NAME",
+		Aliases: []string{"rm"},
+		Short:   "Remove a saved search",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
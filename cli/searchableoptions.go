@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"slices"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 
@@ -21,6 +22,8 @@ type SearchableOptions struct {
 	Name     string
 	Labels   []string
 	Wildcard string
+	Username string
+	URL      string
 }
 
 type Filter int
@@ -30,12 +33,16 @@ const (
 	FilterByID
 	FilterByName
 	FilterByLabels
+	FilterByUsername
+	FilterByURL
 )
 
 var help = map[Filter]string{
-	FilterByID:     "filter by id",
-	FilterByName:   "filter by name",
-	FilterByLabels: "filter by label",
+	FilterByID:       "filter by id",
+	FilterByName:     "filter by name",
+	FilterByLabels:   "filter by label",
+	FilterByUsername: "filter by the username metadata (glob)",
+	FilterByURL:      "filter by the url metadata (glob)",
 }
 
 func (u Filter) Help() string {
@@ -70,6 +77,12 @@ func (o *SearchableOptions) WildcardFrom(args []string) {
 	}
 }
 
+// empty reports whether no search criteria are set, matching all secrets.
+func (o *SearchableOptions) empty() bool {
+	return o.ID == 0 && len(o.IDs) == 0 && len(o.Name) == 0 && len(o.Labels) == 0 &&
+		len(o.Wildcard) == 0 && len(o.Username) == 0 && len(o.URL) == 0
+}
+
 // search queries the vault for secrets based on the fields
 // set in [genericclioptions.SearchOptions].
 //
@@ -89,7 +102,13 @@ func (o *SearchableOptions) search(ctx context.Context, vault *vault.Vault) ([]s
 	}
 
 	retrieveSecretsFunc := func() (map[int]vaultdb.SecretWithLabels, error) {
-		return vault.FilterSecrets(ctx, o.Wildcard, o.Name, o.Labels)
+		return vault.FilterSecretsBy(ctx, vaultdb.Filters{
+			Wildcard: o.Wildcard,
+			Name:     o.Name,
+			Labels:   o.Labels,
+			Username: o.Username,
+			URL:      o.URL,
+		})
 	}
 
 	if len(o.Labels) > 0 || len(o.Wildcard) > 0 {
@@ -103,6 +122,16 @@ type secretWithLabels struct {
 	id     int
 	name   string
 	labels []string
+	mount  string // mount is the name of the mount holding the secret, empty for the vault itself.
+}
+
+// displayID returns the secret id, prefixed by its mount name if mounted.
+func (s secretWithLabels) displayID() string {
+	if len(s.mount) > 0 {
+		return s.mount + "/" + strconv.Itoa(s.id)
+	}
+
+	return strconv.Itoa(s.id)
 }
 
 type retrieveSecretsFunc func() (map[int]vaultdb.SecretWithLabels, error)
@@ -188,14 +217,19 @@ func extractIDs(secrets []secretWithLabels) []int {
 	return ids
 }
 
-func printTable(w io.Writer, markedLabeledSecrets []secretWithLabels) {
+func printTable(w io.Writer, markedLabeledSecrets []secretWithLabels, colors map[string]string) {
 	tw := tabwriter.NewWriter(w, 0, 0, 5, ' ', 0)
 	defer func() { _ = tw.Flush() }()
 
 	fmt.Fprintln(tw, "ID\tNAME\tLABELS")
 
 	for _, marked := range markedLabeledSecrets {
-		fmt.Fprintf(tw, "%d\t%s\t%s\n", marked.id, marked.name, strings.Join(marked.labels, ","))
+		badges := make([]string, len(marked.labels))
+		for i, l := range marked.labels {
+			badges[i] = labelBadge(l, colors)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", marked.displayID(), marked.name, strings.Join(badges, ","))
 	}
 
 	fmt.Fprintln(tw) // add padding
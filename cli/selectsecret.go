@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"golang.org/x/term"
+)
+
+// canSelect reports whether a secret can be picked interactively,
+// i.e., both stdin and stderr are terminals.
+func canSelect(io *genericclioptions.StdioOptions) bool {
+	_, ok := terminalFd(io.ErrOut)
+	return ok && !io.NonInteractive && term.IsTerminal(int(io.In.Fd()))
+}
+
+// selectSecret lists the secrets of the vault and its mounts, and prompts
+// the user to pick one using a fuzzy finder over their names and labels.
+func (o *VaultOptions) selectSecret(ctx context.Context, io *genericclioptions.StdioOptions) (secretWithLabels, error) {
+	secrets, err := o.searchAll(ctx, io, NewSearchableOptions())
+	if err != nil {
+		return secretWithLabels{}, err
+	}
+
+	if len(secrets) == 0 {
+		return secretWithLabels{}, vaulterrors.ErrSearchNoMatch
+	}
+
+	width := 0
+	for _, s := range secrets {
+		width = max(width, len(s.name))
+	}
+
+	items := make([]string, len(secrets))
+	for i, s := range secrets {
+		items[i] = strings.TrimSpace(fmt.Sprintf("%-*s  %s", width, s.name, strings.Join(s.labels, ",")))
+	}
+
+	i, err := genericclioptions.Select(io, "vlt> ", items)
+	if err != nil {
+		return secretWithLabels{}, err
+	}
+
+	return secrets[i], nil
+}
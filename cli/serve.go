@@ -0,0 +1,640 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vaultbackup"
+	"github.com/ladzaretti/vlt-cli/vaultserver"
+
+	"github.com/spf13/cobra"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	defaultServeAddr     = "127.0.0.1:7711"
+	defaultServeGRPCAddr = "127.0.0.1:7712"
+
+	// serveShutdownTimeout bounds the time in-flight requests
+	// are given to complete on shutdown.
+	serveShutdownTimeout = 5 * time.Second
+)
+
+type ServeError struct {
+	Err error
+}
+
+func (e *ServeError) Error() string { return "serve: " + e.Err.Error() }
+
+func (e *ServeError) Unwrap() error { return e.Err }
+
+// ServeOptions holds data required to run the command.
+type ServeOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config   *ResolvedConfig
+	addr     string
+	grpc     bool   // grpc enables the gRPC API.
+	grpcAddr string // grpcAddr is the address the gRPC API is served on.
+
+	tlsCert  string // tlsCert is the server certificate file, TLS is enabled if set.
+	tlsKey   string // tlsKey is the server private key file.
+	clientCA string // clientCA is the CA bundle verifying client certificates, mTLS is enabled if set.
+
+	bitwarden bool // bitwarden enables the Bitwarden compatible API.
+	webui     bool // webui enables the web interface.
+
+	limits vaultserver.Limits
+}
+
+var _ genericclioptions.CmdOptions = &ServeOptions{}
+
+// NewServeOptions initializes the options struct.
+func NewServeOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *ServeOptions {
+	return &ServeOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+		addr:         defaultServeAddr,
+		grpcAddr:     defaultServeGRPCAddr,
+		limits:       vaultserver.DefaultLimits,
+	}
+}
+
+func (*ServeOptions) Complete() error { return nil }
+
+func (o *ServeOptions) Validate() error {
+	if len(o.token) > 0 {
+		return &ServeError{fmt.Errorf("cannot serve a vault opened using %s", tokenEnv)}
+	}
+
+	if _, _, err := net.SplitHostPort(o.addr); err != nil {
+		return &ServeError{fmt.Errorf("invalid --addr value %q: %w", o.addr, err)}
+	}
+
+	if o.grpc {
+		if _, _, err := net.SplitHostPort(o.grpcAddr); err != nil {
+			return &ServeError{fmt.Errorf("invalid --grpc-addr value %q: %w", o.grpcAddr, err)}
+		}
+	}
+
+	if (len(o.tlsCert) > 0) != (len(o.tlsKey) > 0) {
+		return &ServeError{errors.New("--tls-cert and --tls-key must be set together")}
+	}
+
+	if len(o.clientCA) > 0 && len(o.tlsCert) == 0 {
+		return &ServeError{errors.New("--client-ca requires --tls-cert and --tls-key")}
+	}
+
+	if o.limits.ClientRate < 0 || o.limits.TokenRate < 0 || o.limits.Burst < 0 || o.limits.MaxAuthFailures < 0 {
+		return &ServeError{errors.New("rate limits must not be negative")}
+	}
+
+	if o.limits.MaxAuthFailures > 0 && o.limits.BanDuration <= 0 {
+		return &ServeError{fmt.Errorf("invalid --ban-duration value %s (must be positive)", o.limits.BanDuration)}
+	}
+
+	return nil
+}
+
+// tlsConfig returns the server TLS configuration,
+// or nil if TLS is disabled.
+func (o *ServeOptions) tlsConfig() (*tls.Config, error) {
+	if len(o.tlsCert) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	cert, err := tls.LoadX509KeyPair(o.tlsCert, o.tlsKey)
+	if err != nil {
+		return nil, fmt.Errorf("load tls key pair: %w", err)
+	}
+
+	c := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS13,
+	}
+
+	if len(o.clientCA) == 0 {
+		return c, nil
+	}
+
+	pem, err := os.ReadFile(o.clientCA)
+	if err != nil {
+		return nil, fmt.Errorf("read client ca: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("client ca %s: no certificates found", o.clientCA)
+	}
+
+	c.ClientCAs = pool
+	c.ClientAuth = tls.RequireAndVerifyClientCert
+
+	return c, nil
+}
+
+func (o *ServeOptions) Run(ctx context.Context, _ ...string) error {
+	tlsConfig, err := o.tlsConfig()
+	if err != nil {
+		return &ServeError{err}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	l, err := (&net.ListenConfig{}).Listen(ctx, "tcp", o.addr)
+	if err != nil {
+		return &ServeError{err}
+	}
+
+	scheme := "http"
+
+	switch {
+	case tlsConfig != nil:
+		l, scheme = tls.NewListener(l, tlsConfig), "https"
+	case !isLoopback(l.Addr()):
+		o.Warnf("vlt: serving on non-loopback address %s without TLS, tokens and secrets are sent in plain text.\n", l.Addr())
+	}
+
+	if tlsConfig != nil && tlsConfig.ClientCAs == nil && !isLoopback(l.Addr()) {
+		o.Warnf("vlt: serving on non-loopback address %s without --client-ca, any client can reach the API.\n", l.Addr())
+	}
+
+	opts := []vaultserver.Option{vaultserver.WithLimits(o.limits)}
+	if len(o.config.BackupDir) > 0 {
+		opts = append(opts, vaultserver.WithLastBackup(o.lastBackup))
+	}
+
+	if o.bitwarden {
+		if _, _, err := o.vault.BitwardenAccount(ctx); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				err = errNoBitwardenAccount
+			}
+
+			_ = l.Close()
+
+			return &ServeError{err}
+		}
+
+		opts = append(opts, vaultserver.WithBitwarden())
+	}
+
+	if o.webui {
+		opts = append(opts, vaultserver.WithWebUI())
+	}
+
+	vs := vaultserver.New(o.vault, opts...)
+
+	srv := &http.Server{
+		Handler:           vs,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+		ErrorLog:          log.New(o.ErrOut, "vlt: serve: ", log.LstdFlags),
+	}
+
+	errc := make(chan error, 1)
+
+	go func() { errc <- srv.Serve(l) }()
+
+	o.Infof("Serving vault %s on %s://%s\n", o.path, scheme, l.Addr())
+
+	if o.bitwarden {
+		o.Infof("Serving the Bitwarden compatible API, set %s://%s as the client server URL\n", scheme, l.Addr())
+	}
+
+	if o.webui {
+		o.Infof("Serving the web interface at %s://%s/ui/\n", scheme, l.Addr())
+	}
+
+	var (
+		g        *grpc.Server
+		grpcErrc = make(chan error, 1)
+	)
+
+	if o.grpc {
+		g, err = o.serveGRPC(ctx, vs, tlsConfig, grpcErrc)
+		if err != nil {
+			_ = srv.Close()
+			return &ServeError{fmt.Errorf("grpc: %w", err)}
+		}
+	}
+
+	select {
+	case err := <-errc:
+		return &ServeError{err}
+	case err := <-grpcErrc:
+		_ = srv.Close()
+		return &ServeError{fmt.Errorf("grpc: %w", err)}
+	case <-ctx.Done():
+	}
+
+	o.Infof("Shutting down...\n")
+
+	if g != nil {
+		stopGRPC(g, serveShutdownTimeout)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), serveShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return &ServeError{err}
+	}
+
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return &ServeError{err}
+	}
+
+	return nil
+}
+
+// serveGRPC serves the gRPC API of the given server until stopped,
+// sending the serve error to errc.
+func (o *ServeOptions) serveGRPC(ctx context.Context, vs *vaultserver.Server, tlsConfig *tls.Config, errc chan<- error) (*grpc.Server, error) {
+	l, err := (&net.ListenConfig{}).Listen(ctx, "tcp", o.grpcAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []grpc.ServerOption
+
+	switch {
+	case tlsConfig != nil:
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	case !isLoopback(l.Addr()):
+		o.Warnf("vlt: serving gRPC on non-loopback address %s without TLS, tokens and secrets are sent in plain text.\n", l.Addr())
+	}
+
+	g := vs.GRPCServer(opts...)
+
+	go func() { errc <- g.Serve(l) }()
+
+	o.Infof("Serving gRPC API on %s\n", l.Addr())
+
+	return g, nil
+}
+
+// stopGRPC stops g gracefully, cancelling the calls still running
+// after the given timeout, e.g., watch streams.
+func stopGRPC(g *grpc.Server, timeout time.Duration) {
+	done := make(chan struct{})
+
+	go func() {
+		g.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		g.Stop()
+	}
+}
+
+// lastBackup returns the time of the latest backup in the configured backup directory.
+func (o *ServeOptions) lastBackup() (time.Time, bool) {
+	d, err := vaultbackup.Open(o.config.BackupDir)
+	if err != nil {
+		o.Debugf("vlt: serve: backup metrics: %v\n", err)
+		return time.Time{}, false
+	}
+
+	latest, ok := d.Latest()
+
+	return latest.CreatedAt, ok
+}
+
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
+
+// NewCmdServe creates the serve cobra command with its sub-commands.
+func NewCmdServe(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewServeOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the vault over a local REST API (subcommands available)",
+		Long: `Unlock the vault and serve it over a REST API until interrupted.
+
+Every request must be authenticated using an API token issued by 'vlt serve tokens issue':
+
+	Authorization: Bearer vltapi_...
+
+A token only grants access to secrets with a label matching its scope,
+and its requests are attributed to it in the audit log.
+Only token hashes are stored in the vault.
+
+Endpoints:
+	GET    /v1/health               health check, no authentication required
+	GET    /v1/secrets              list secrets, filtered by the 'q', 'name' and 'label' query parameters
+	POST   /v1/secrets              create a secret: {"name": ..., "secret": ..., "labels": [...]}
+	GET    /v1/secrets/{id}         show a secret, including its value
+	PUT    /v1/secrets/{id}         update a secret value: {"secret": ...}
+	DELETE /v1/secrets/{id}         delete a secret
+	GET    /v1/secrets/{id}/totp    current code of a TOTP secret: {"code": ..., "remaining_seconds": ...}
+	GET    /v1/watch                stream changes to secrets as server-sent events, see 'vlt watch'
+	GET    /metrics                 server and vault metrics
+
+The vault is persisted after every request.
+
+Failed requests respond with {"error": ..., "code": ...}, where code is a stable
+error code, e.g., SECRET_NOT_FOUND, shared with the JSON errors of the CLI.
+Failed gRPC calls carry the code in the 'vlt-error-code' trailer.
+
+With --grpc, the API is also served over gRPC on --grpc-addr, using the same
+API tokens, passed in the 'authorization' metadata, and the same rate limits.
+The service (vaultpb.Vault) provides Search, Get, Put, Delete and Totp calls,
+and a Watch stream of changes to secrets. Its definition is found at
+vaultserver/proto/vaultpb/vault.proto in the vlt source tree.
+
+With --bitwarden-compat, the official Bitwarden browser extensions, desktop and
+mobile apps can use the server as a self-hosted Bitwarden server, logging in to
+the account set up by 'vlt serve bitwarden setup'. Each secret is shown as a
+login item, with its value as the password and its labels in a 'labels' custom
+field. Login items can be created, edited and deleted; folders, organizations,
+attachments, sends and two-factor authentication are not supported. Clients log
+in again whenever the server restarts. Most clients require HTTPS, except on localhost.
+
+With --webui, a minimal web interface is served at /ui/, for searching, viewing,
+copying, adding and editing secrets, and showing the current codes of TOTP secrets,
+from a browser, e.g., by household members not using a terminal. It signs in using
+an API token, kept in the browser tab until it is closed or locked, and is limited
+to the scope of the token like any other API client.
+
+To expose the API beyond localhost, enable TLS using --tls-cert and --tls-key.
+Setting --client-ca additionally enables mutual TLS: only clients presenting
+a certificate signed by the given CA can connect. API tokens are required regardless.
+
+Requests are rate limited per client IP and per API token, exceeding requests
+are rejected with 429 Too Many Requests. Client IPs are banned temporarily
+after repeated authentication failures.
+
+The /metrics endpoint exposes Prometheus metrics, and accepts any valid API token:
+served requests by method and status code, rejected requests by reason
+(including authentication failures), client bans, requests unlocking the vault
+by API token, the number of stored secrets, and the seconds since the last
+backup, if 'backup.dir' is configured.`,
+		Example: `  # Issue a read-only token for CI secrets, and serve the vault
+  vlt serve tokens issue --name ci --label 'ci/*' --ttl 7d --read-only-token
+  vlt serve
+
+  # Show a secret
+  curl -H "Authorization: Bearer vltapi_..." http://127.0.0.1:7711/v1/secrets/1
+
+  # Serve the gRPC API alongside the REST API
+  vlt serve --grpc --grpc-addr 127.0.0.1:7712
+
+  # Serve Bitwarden clients
+  vlt serve bitwarden setup --email me@example.com
+  vlt serve --bitwarden-compat --addr 0.0.0.0:7711 --tls-cert server.pem --tls-key server-key.pem
+
+  # Serve the web interface to the household, with a token scoped to shared secrets
+  vlt serve tokens issue --name family --label 'shared/*' --ttl 30d
+  vlt serve --webui --addr 0.0.0.0:7711 --tls-cert server.pem --tls-key server-key.pem
+
+  # Serve trusted clients on the local network using mutual TLS
+  vlt serve --addr 0.0.0.0:7711 --tls-cert server.pem --tls-key server-key.pem --client-ca clients-ca.pem`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.addr, "addr", "", defaultServeAddr, "address to listen on")
+	cmd.Flags().BoolVarP(&o.grpc, "grpc", "", false, "also serve the gRPC API")
+	cmd.Flags().StringVarP(&o.grpcAddr, "grpc-addr", "", defaultServeGRPCAddr, "address to serve the gRPC API on")
+	cmd.Flags().BoolVarP(&o.bitwarden, "bitwarden-compat", "", false, "also serve the Bitwarden compatible API")
+	cmd.Flags().BoolVarP(&o.webui, "webui", "", false, "also serve a web interface at /ui/")
+	cmd.Flags().StringVarP(&o.tlsCert, "tls-cert", "", "", "server certificate file (PEM), enables TLS")
+	cmd.Flags().StringVarP(&o.tlsKey, "tls-key", "", "", "server private key file (PEM)")
+	cmd.Flags().StringVarP(&o.clientCA, "client-ca", "", "", "CA certificates file (PEM) verifying client certificates, enables mutual TLS")
+	cmd.Flags().Float64VarP(&o.limits.ClientRate, "rate-limit", "", o.limits.ClientRate, "requests per second allowed per client IP (0 disables)")
+	cmd.Flags().Float64VarP(&o.limits.TokenRate, "token-rate-limit", "", o.limits.TokenRate, "requests per second allowed per API token (0 disables)")
+	cmd.Flags().IntVarP(&o.limits.Burst, "burst", "", o.limits.Burst, "requests allowed to exceed the rate limits momentarily")
+	cmd.Flags().IntVarP(&o.limits.MaxAuthFailures, "ban-after", "", o.limits.MaxAuthFailures, "authentication failures after which a client IP is banned (0 disables)")
+	cmd.Flags().DurationVarP(&o.limits.BanDuration, "ban-duration", "", o.limits.BanDuration, "how long a client IP is banned for")
+
+	cmd.AddCommand(NewCmdServeTokens(defaults))
+	cmd.AddCommand(NewCmdServeBitwarden(defaults))
+
+	return cmd
+}
+
+// NewCmdServeTokens creates the serve tokens cobra command with its sub-commands.
+func NewCmdServeTokens(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tokens",
+		Short: "Manage API tokens for the REST server (subcommands available)",
+		Long: `Manage API tokens for the REST server.
+
+Unlike access tokens ('vlt token'), API tokens cannot unlock the vault,
+they only authenticate requests to a running 'vlt serve'.`,
+	}
+
+	cmd.AddCommand(NewCmdServeTokensIssue(defaults))
+	cmd.AddCommand(NewCmdServeTokensList(defaults))
+	cmd.AddCommand(NewCmdServeTokensRevoke(defaults))
+
+	return cmd
+}
+
+// ServeTokensIssueOptions holds data required to run the command.
+type ServeTokensIssueOptions struct {
+	*TokenIssueOptions
+
+	name string
+}
+
+var _ genericclioptions.CmdOptions = &ServeTokensIssueOptions{}
+
+// NewServeTokensIssueOptions initializes the options struct.
+func NewServeTokensIssueOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *ServeTokensIssueOptions {
+	return &ServeTokensIssueOptions{
+		TokenIssueOptions: NewTokenIssueOptions(stdio, vaultOptions),
+	}
+}
+
+func (o *ServeTokensIssueOptions) Validate() error {
+	if len(o.name) == 0 {
+		return &ServeError{errors.New("--name is required")}
+	}
+
+	return o.TokenIssueOptions.Validate()
+}
+
+func (o *ServeTokensIssueOptions) Run(ctx context.Context, _ ...string) error {
+	token, info, err := o.vault.IssueAPIToken(ctx, o.name, o.tokenOptions)
+	if err != nil {
+		return &ServeError{err}
+	}
+
+	o.Warnf("Issued API token %s (%s) for labels %q, expires at %s.\n", info.ID, info.Name, strings.Join(info.Scope, ","), info.ExpiresAt.Local().Format(time.DateTime))
+	o.Warnf("The token is shown only once, store it securely.\n")
+	o.Infof("%s\n", token)
+
+	return nil
+}
+
+// NewCmdServeTokensIssue creates the serve tokens issue cobra command.
+func NewCmdServeTokensIssue(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewServeTokensIssueOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:     "issue",
+		Aliases: []string{"create"},
+		Short:   "Issue a new API token",
+		Long: `Issue a new API token limited to secrets with a label matching any of the given glob patterns.
+
+The token is printed to stdout once, and cannot be retrieved again.`,
+		Example: `  # Issue a read-only API token for CI secrets, valid for a week
+  vlt serve tokens issue --name ci --label 'ci/*' --ttl 7d --read-only-token`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.name, "name", "", "", "token name, describing its use")
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "label glob pattern the token is scoped to (comma-separated or repeated)")
+	cmd.Flags().StringVarP(&o.ttl, "ttl", "", defaultTokenTTL, "token lifetime (e.g., 1h, 7d)")
+	cmd.Flags().BoolVarP(&o.readOnly, "read-only-token", "", false, "reject modifications made using the token")
+
+	return cmd
+}
+
+// ServeTokensListOptions holds data required to run the command.
+type ServeTokensListOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &ServeTokensListOptions{}
+
+// NewServeTokensListOptions initializes the options struct.
+func NewServeTokensListOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *ServeTokensListOptions {
+	return &ServeTokensListOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*ServeTokensListOptions) Complete() error { return nil }
+
+func (*ServeTokensListOptions) Validate() error { return nil }
+
+func (o *ServeTokensListOptions) Run(ctx context.Context, _ ...string) error {
+	tokens, err := o.vault.APITokens(ctx)
+	if err != nil {
+		return &ServeError{err}
+	}
+
+	if len(tokens) == 0 {
+		o.Warnf("No API tokens found.\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tLABELS\tREAD-ONLY\tEXPIRES")
+
+	now := time.Now()
+
+	for _, t := range tokens {
+		expires := t.ExpiresAt.Local().Format(time.DateTime)
+		if !now.Before(t.ExpiresAt) {
+			expires += " (expired)"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\n", t.ID, t.Name, strings.Join(t.Scope, ","), t.ReadOnly, expires)
+	}
+
+	fmt.Fprintln(tw) // add padding
+
+	return nil
+}
+
+// NewCmdServeTokensList creates the serve tokens list cobra command.
+func NewCmdServeTokensList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewServeTokensListOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List issued API tokens",
+		Long:    "List issued API tokens, including expired ones.",
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
+
+// ServeTokensRevokeOptions holds data required to run the command.
+type ServeTokensRevokeOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &ServeTokensRevokeOptions{}
+
+// NewServeTokensRevokeOptions initializes the options struct.
+func NewServeTokensRevokeOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *ServeTokensRevokeOptions {
+	return &ServeTokensRevokeOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*ServeTokensRevokeOptions) Complete() error { return nil }
+
+func (*ServeTokensRevokeOptions) Validate() error { return nil }
+
+func (o *ServeTokensRevokeOptions) Run(ctx context.Context, ids ...string) error {
+	n, err := o.vault.RevokeAPITokens(ctx, ids...)
+	if err != nil {
+		return &ServeError{err}
+	}
+
+	if int(n) != len(ids) {
+		o.Warnf("Revoked %d of %d API tokens, the rest were not found.\n", n, len(ids))
+		return nil
+	}
+
+	o.Infof("Revoked %d API tokens.\n", n)
+
+	return nil
+}
+
+// NewCmdServeTokensRevoke creates the serve tokens revoke cobra command.
+func NewCmdServeTokensRevoke(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewServeTokensRevokeOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "revoke ID...",
+		Short: "Revoke API tokens",
+		Long:  "Revoke API tokens by their ids, as listed by 'vlt serve tokens list'.",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
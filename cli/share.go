@@ -0,0 +1,294 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/share"
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+
+	"github.com/spf13/cobra"
+)
+
+// shareFilePerm is the permission bundles are written with.
+const shareFilePerm = 0o600
+
+type ShareError struct {
+	Err error
+}
+
+func (e *ShareError) Error() string { return "share: " + e.Err.Error() }
+
+func (e *ShareError) Unwrap() error { return e.Err }
+
+// ShareOptions holds data required to run the command.
+type ShareOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	recipients []string // recipients are the age recipients the bundle is encrypted for.
+	expires    string   // expires is how long until the bundle expires, empty if never.
+	output     string   // output is the path the bundle is written to, stdout if empty.
+
+	expiresIn time.Duration
+}
+
+var _ genericclioptions.CmdOptions = &ShareOptions{}
+
+// NewShareOptions initializes the options struct.
+func NewShareOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *ShareOptions {
+	return &ShareOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (o *ShareOptions) Complete() error {
+	if len(o.expires) == 0 {
+		return nil
+	}
+
+	d, err := cmdutil.ParseDuration(o.expires)
+	if err != nil {
+		return &ShareError{fmt.Errorf("--expires: %w", err)}
+	}
+
+	o.expiresIn = d
+
+	return nil
+}
+
+func (o *ShareOptions) Validate() error {
+	if len(o.recipients) == 0 {
+		return &ShareError{errors.New("no recipients, use --to")}
+	}
+
+	if len(o.expires) > 0 && o.expiresIn <= 0 {
+		return &ShareError{errors.New("--expires must be positive")}
+	}
+
+	return nil
+}
+
+func (o *ShareOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &ShareError{retErr}
+			return
+		}
+	}()
+
+	s, err := o.secretNamed(ctx, o.StdioOptions, args[0])
+	if err != nil {
+		return err
+	}
+
+	v, err := o.vaultOf(ctx, o.StdioOptions, s)
+	if err != nil {
+		return err
+	}
+
+	value, err := v.ShowSecret(ctx, s.id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	b := share.Bundle{
+		Name:      s.name,
+		Labels:    s.labels,
+		Value:     []byte(value),
+		CreatedAt: now,
+	}
+
+	if o.expiresIn > 0 {
+		b.ExpiresAt = now.Add(o.expiresIn)
+	}
+
+	sealed, err := share.Seal(ctx, b, o.recipients)
+	if err != nil {
+		return err
+	}
+
+	if len(o.output) == 0 {
+		_, err := o.Out.Write(sealed)
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Clean(o.output), sealed, shareFilePerm); err != nil {
+		return err
+	}
+
+	o.Infof("Shared %q to %s\n", s.name, o.output)
+
+	return nil
+}
+
+// ShareAcceptOptions holds data required to run the command.
+type ShareAcceptOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config *ResolvedConfig
+	key    string   // key is the name of the secret holding the age identity.
+	name   string   // name overrides the name of the shared secret.
+	labels []string // labels are added to the labels of the shared secret.
+}
+
+var _ genericclioptions.CmdOptions = &ShareAcceptOptions{}
+
+// NewShareAcceptOptions initializes the options struct.
+func NewShareAcceptOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *ShareAcceptOptions {
+	return &ShareAcceptOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+	}
+}
+
+func (o *ShareAcceptOptions) Complete() error {
+	if len(o.key) == 0 {
+		o.key = o.config.ShareKey
+	}
+
+	return nil
+}
+
+func (o *ShareAcceptOptions) Validate() error {
+	if len(o.key) == 0 {
+		return &ShareError{errors.New("no key secret; use --key or set 'share.key' in the config")}
+	}
+
+	return nil
+}
+
+func (o *ShareAcceptOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &ShareError{retErr}
+			return
+		}
+	}()
+
+	sealed, err := o.readBundle(args)
+	if err != nil {
+		return err
+	}
+
+	identity, err := o.lookupValue(ctx, o.StdioOptions, o.key)
+	if err != nil {
+		return fmt.Errorf("key secret: %w", err)
+	}
+
+	b, err := share.Open(ctx, sealed, identity, time.Now())
+	if err != nil {
+		return err
+	}
+
+	name := b.Name
+	if len(o.name) > 0 {
+		name = o.name
+	}
+
+	labels := slices.Concat(b.Labels, o.labels)
+
+	if err := o.passwordPolicy.check(o.StdioOptions, string(b.Value), labels); err != nil {
+		return err
+	}
+
+	n, err := o.vault.InsertNewSecret(ctx, name, string(b.Value), labels)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNoSecretInserted
+	}
+
+	o.Infof("Accepted %q, shared on %s\n", name, b.CreatedAt.Local().Format(time.DateTime))
+
+	return genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite)
+}
+
+// readBundle reads the sealed bundle from the file given in args,
+// or from stdin if none or '-' is given.
+func (o *ShareAcceptOptions) readBundle(args []string) ([]byte, error) {
+	if len(args) > 0 && args[0] != "-" {
+		return os.ReadFile(filepath.Clean(args[0]))
+	}
+
+	if !o.NonInteractive {
+		return nil, errors.New("no bundle given; pass a bundle file or pipe it to stdin")
+	}
+
+	return io.ReadAll(o.In)
+}
+
+// NewCmdShare creates the share cobra command.
+func NewCmdShare(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewShareOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "share NAME",
+		Short: "Share a secret as a bundle encrypted for age recipients (subcommands available)",
+		Long: `Share a secret as a bundle encrypted for age recipients.
+
+The bundle holds the secret name, labels and value, along with an optional
+expiry, encrypted using the external 'age' command for the given recipients,
+age public keys ('age1...') or SSH public keys. It is ASCII armored, so it can
+be sent over chat or email, and is accepted by the recipients using
+'vlt share accept'.
+
+Expired bundles are refused by 'vlt share accept', but expiry is not enforced
+by the encryption: recipients holding the bundle may still decrypt it using
+'age' directly.`,
+		Example: `  # Share a secret, expiring in a day
+  vlt share db-admin --to age1... --expires 1d > db-admin.age
+
+  # Accept it on the recipient side, using the stored age identity
+  age-keygen | vlt save --name age-key --label age
+  vlt share accept --key age-key db-admin.age`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(defaults),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&o.recipients, "to", "t", nil, "age or SSH public key the bundle is encrypted for (repeatable)")
+	cmd.Flags().StringVarP(&o.expires, "expires", "e", "", "how long until the bundle expires, e.g., '12h' or '7d' (default: never)")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", "write the bundle to the given file instead of stdout")
+
+	cmd.AddCommand(NewCmdShareAccept(defaults))
+
+	return cmd
+}
+
+// NewCmdShareAccept creates the share accept cobra command.
+func NewCmdShareAccept(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewShareAcceptOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "accept [BUNDLE]",
+		Short: "Save the secret of a shared bundle, read from a file or stdin",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.key, "key", "k", "", "name of the secret holding the age identity (default: 'share.key' config)")
+	cmd.Flags().StringVarP(&o.name, "name", "n", "", "save the secret under the given name instead of the shared one")
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "l", nil, "label to add to the shared labels")
+
+	return cmd
+}
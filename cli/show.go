@@ -1,17 +1,34 @@
 package cli
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/ladzaretti/vlt-cli/clierror"
 	"github.com/ladzaretti/vlt-cli/clipboard"
+	"github.com/ladzaretti/vlt-cli/fuzzy"
 	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/input"
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+	"github.com/ladzaretti/vlt-cli/vault"
 	"github.com/ladzaretti/vlt-cli/vaulterrors"
 
 	"github.com/spf13/cobra"
 )
 
+// secretMask is displayed in place of secret values,
+// its length is fixed to avoid disclosing the value length.
+const secretMask = "********"
+
 type ShowError struct {
 	Err error
 }
@@ -25,23 +42,49 @@ type ShowOptions struct {
 	*genericclioptions.StdioOptions
 	*VaultOptions
 
+	config *ResolvedConfig
+	flags  *Flags
 	search *SearchableOptions
 	output bool // output controls whether to print the secret to stdout.
 	copy   bool // copy controls whether to copy the secret to the clipboard.
+	reveal bool // reveal controls whether to display the secret value without prompting.
+	fuzzy  bool // fuzzy selects the closest secret name if nothing matches.
+
+	// timeout is how long the secret is displayed before it is cleared
+	// from the terminal, zero disables clearing.
+	timeout time.Duration
+
+	// clearAfter overrides the configured delay after which the copied
+	// secret is cleared from the clipboard, zero disables clearing.
+	clearAfter string
+	clearDelay time.Duration
 }
 
 var _ genericclioptions.CmdOptions = &ShowOptions{}
 
 // NewShowOptions initializes the options struct.
-func NewShowOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *ShowOptions {
+func NewShowOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig, flags *Flags) *ShowOptions {
 	return &ShowOptions{
 		StdioOptions: stdio,
 		VaultOptions: vaultOptions,
+		config:       config,
+		flags:        flags,
 		search:       NewSearchableOptions(),
 	}
 }
 
 func (o *ShowOptions) Complete() error {
+	o.clearDelay = time.Duration(o.config.ClipboardClear)
+
+	if len(o.clearAfter) > 0 {
+		d, err := cmdutil.ParseDuration(o.clearAfter)
+		if err != nil {
+			return &ShowError{fmt.Errorf("invalid --clear-after: %w", err)}
+		}
+
+		o.clearDelay = d
+	}
+
 	return o.search.Complete()
 }
 
@@ -58,64 +101,210 @@ func (o *ShowOptions) Validate() error {
 }
 
 func (o *ShowOptions) validateConfigOptions() error {
-	c := 0
+	if o.copy && (o.output || o.reveal || o.timeout > 0) {
+		return &ShowError{errors.New("--copy cannot be used with --output, --reveal or --timeout")}
+	}
+
+	if o.output && o.reveal {
+		return &ShowError{errors.New("--output and --reveal cannot be used together")}
+	}
 
-	if o.copy {
-		c++
+	if len(o.clearAfter) > 0 && !o.copy {
+		return &ShowError{errors.New("--clear-after requires --copy")}
 	}
 
-	if o.output {
-		c++
+	if o.clearDelay < 0 {
+		return &ShowError{fmt.Errorf("invalid --clear-after value %s (must not be negative)", o.clearDelay)}
 	}
 
-	if c != 1 {
-		return &ShowError{errors.New("either --output or --copy must be set (but not both)")}
+	if o.timeout < 0 {
+		return &ShowError{fmt.Errorf("invalid --timeout value %s (must be positive)", o.timeout)}
+	}
+
+	if _, ok := terminalFd(o.Out); !o.copy && !o.output && !ok {
+		return &ShowError{errors.New("either --output or --copy must be set when stdout is not a terminal")}
 	}
 
 	return nil
 }
 
 // Run performs a secret lookup and outputs the result based on user flags.
+//
+// Without any search criteria, the secret is picked interactively on a terminal.
 func (o *ShowOptions) Run(ctx context.Context, args ...string) error {
 	o.search.WildcardFrom(args)
 
-	matchingSecrets, err := o.search.search(ctx, o.vault)
+	if o.search.empty() && canSelect(o.StdioOptions) {
+		secret, err := o.selectSecret(ctx, o.StdioOptions)
+		if err != nil {
+			return &ShowError{err}
+		}
+
+		return o.showSecret(ctx, secret)
+	}
+
+	matchingSecrets, err := o.searchAll(ctx, o.StdioOptions, o.search)
 	if err != nil {
 		return err
 	}
 
+	var suggestions []fuzzy.Suggestion
+
+	if len(matchingSecrets) == 0 && len(o.search.Labels) == 0 {
+		suggestions = o.suggestNames(ctx, o.StdioOptions, cmp.Or(o.search.Name, o.search.Wildcard))
+	}
+
+	if best, ok := fuzzy.Best(suggestions); ok && o.fuzzy {
+		o.Warnf("No match found, using the closest match %q.\n", best.Name)
+
+		if matchingSecrets, err = o.searchAll(ctx, o.StdioOptions, &SearchableOptions{Name: best.Name}); err != nil {
+			return err
+		}
+
+		matchingSecrets = slices.DeleteFunc(matchingSecrets, func(s secretWithLabels) bool { return s.name != best.Name })
+	}
+
 	count := len(matchingSecrets)
 
 	switch count {
 	case 1:
 		o.Debugf("found one match.\n")
 
-		s, err := o.vault.ShowSecret(ctx, matchingSecrets[0].id)
-		if err != nil {
-			return err
-		}
-
-		return o.outputSecret(s)
+		return o.showSecret(ctx, matchingSecrets[0])
 	case 0:
 		o.Warnf("No match found.\n")
+
+		if len(suggestions) > 0 {
+			o.Warnf("Did you mean %s? (--fuzzy uses the closest match)\n", suggestionList(suggestions))
+		}
+
 		return &ShowError{vaulterrors.ErrSearchNoMatch}
 	default:
 		o.Warnf("Expecting exactly one match, but found %d.\n\n", count)
-		printTable(o.ErrOut, matchingSecrets)
+		printTable(o.ErrOut, matchingSecrets, o.labelColors(ctx, o.ErrOut))
 
 		return &ShowError{vaulterrors.ErrAmbiguousSecretMatch}
 	}
 }
 
-func (o *ShowOptions) outputSecret(s string) error {
-	if o.output {
-		o.Infof("%s", s)
-		return nil
+// showSecret outputs the given secret. Its value is decrypted only once
+// copied, printed or revealed, and streamed to stdout if not timed.
+func (o *ShowOptions) showSecret(ctx context.Context, secret secretWithLabels) error {
+	v, err := o.vaultOf(ctx, o.StdioOptions, secret)
+	if err != nil {
+		return err
 	}
 
-	if o.copy {
+	switch {
+	case o.copy:
 		o.Debugf("copying secret to clipboard\n")
-		return clipboard.Copy(s)
+
+		s, err := v.ShowSecret(ctx, secret.id)
+		if err != nil {
+			return err
+		}
+
+		if err := clipboard.Copy(s); err != nil {
+			return err
+		}
+
+		if o.clearDelay == 0 {
+			return nil
+		}
+
+		if err := scheduleClipboardClear(o.flags, s, o.clearDelay); err != nil {
+			o.Warnf("vlt: failed to schedule clearing the clipboard: %v\n", err)
+			return nil
+		}
+
+		o.Infof("Copied, the clipboard is cleared in %s.\n", o.clearDelay)
+
+		return nil
+	case o.output:
+		if ok, err := o.confirmPrint(o.StdioOptions, secretDescription(secret.name)); !ok {
+			return err
+		}
+
+		return o.writeSecret(ctx, v, secret, "")
+	default:
+		return o.displayMasked(ctx, v, secret)
+	}
+}
+
+// displayMasked prints the secret metadata with a masked value, and reveals
+// the value if --reveal is set or the user confirms with a keypress.
+func (o *ShowOptions) displayMasked(ctx context.Context, v *vault.Vault, secret secretWithLabels) error {
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+
+	fmt.Fprintf(tw, "ID\t%s\n", secret.displayID())
+	fmt.Fprintf(tw, "NAME\t%s\n", secret.name)
+	fmt.Fprintf(tw, "LABELS\t%s\n", strings.Join(secret.labels, ","))
+	fmt.Fprintf(tw, "SECRET\t%s\n", secretMask)
+	fmt.Fprintln(tw) // add padding
+
+	if err := tw.Flush(); err != nil {
+		return &ShowError{err}
+	}
+
+	reveal := o.reveal
+
+	if reveal {
+		ok, err := o.confirmPrint(o.StdioOptions, secretDescription(secret.name))
+		if err != nil {
+			return &ShowError{err}
+		}
+
+		reveal = ok
+	} else if !o.NonInteractive {
+		key, err := input.PromptKey(o.Out, o.In, int(o.In.Fd()), "Press 'r' to reveal the secret, or any other key to exit: ")
+		if err != nil {
+			return &ShowError{err}
+		}
+
+		reveal = key == 'r' || key == 'R'
+	}
+
+	if !reveal {
+		return nil
+	}
+
+	return o.writeSecret(ctx, v, secret, "\n")
+}
+
+// writeSecret decrypts the secret value and prints it followed by suffix,
+// streaming it to stdout unless it is displayed for --timeout.
+func (o *ShowOptions) writeSecret(ctx context.Context, v *vault.Vault, secret secretWithLabels, suffix string) error {
+	if o.timeout > 0 {
+		s, err := v.ShowSecret(ctx, secret.id)
+		if err != nil {
+			return err
+		}
+
+		return o.displayTimed(ctx, s)
+	}
+
+	if _, err := v.WriteSecret(ctx, secret.id, o.Out); err != nil {
+		return err
+	}
+
+	o.Infof("%s", suffix)
+
+	return nil
+}
+
+// displayTimed prints the secret to the terminal and clears it
+// after the timeout elapses or on interrupt.
+func (o *ShowOptions) displayTimed(ctx context.Context, s string) error {
+	fd, ok := terminalFd(o.Out)
+	if !ok {
+		return &ShowError{input.ErrNotTerminal}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := input.DisplayTimed(ctx, o.Out, fd, s, o.timeout); err != nil {
+		return &ShowError{err}
 	}
 
 	return nil
@@ -126,17 +315,45 @@ func NewCmdShow(defaults *DefaultVltOptions) *cobra.Command {
 	o := NewShowOptions(
 		defaults.StdioOptions,
 		defaults.vaultOptions,
+		defaults.configOptions.resolved,
+		defaults.configOptions.cliFlags,
 	)
 
 	cmd := &cobra.Command{
-		Use:     "show [glob]",
-		Aliases: []string{"get"},
-		Short:   "Retrieve a secret value from the vault",
+		Use:   "show [glob]",
+		Short: "Retrieve a secret value from the vault",
 		Long: `Retrieve and display a secret value from the vault.
 
 The secret value will be displayed only if there is exactly one match for the given search criteria.
-
-Use --output to print to stdout (unsafe) or --copy to copy the value to the clipboard.`,
+Without any search criteria on a terminal, the secret is picked using a fuzzy finder
+over the secret names and labels: type to filter, use Up and Down to move, Enter to pick,
+and Esc to cancel.
+Secrets of mounted vaults are matched as in 'vlt find', e.g., using --name team/db.
+If nothing matches, the secret names closest to the given one are suggested, as
+typos, e.g., "github" for "gihub". With --fuzzy, the closest one is used instead.
+
+On a terminal, the secret metadata is displayed with a masked value by default.
+The value is revealed with --reveal, or by pressing 'r' when prompted.
+It is only decrypted once revealed, printed or copied.
+
+Use --output to print to stdout (unsafe) or --copy to copy the value to the clipboard.
+The clipboard is cleared after --clear-after, or the 'clipboard.clear_after' config
+(default: 45s), unless another value was copied since.
+Use --timeout to clear the revealed value from the screen after the given duration, or on Ctrl-C.`,
+		Example: `  # Pick a secret using the fuzzy finder, and copy it to the clipboard
+  vlt show -c
+
+  # Display the secret metadata, and reveal the value on a keypress
+  vlt show --name github
+
+  # Reveal the secret and clear it after 10 seconds
+  vlt show --name github --reveal --timeout 10s
+
+  # Copy the secret with id 12, clearing the clipboard after 10 seconds
+  vlt show --id 12 --copy --clear-after 10s
+
+  # Copy the secret named "github", despite the typo
+  vlt show gihub --fuzzy -c`,
 		Run: func(cmd *cobra.Command, args []string) {
 			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
 		},
@@ -147,6 +364,11 @@ Use --output to print to stdout (unsafe) or --copy to copy the value to the clip
 	cmd.Flags().StringSliceVarP(&o.search.Labels, "label", "", nil, FilterByLabels.Help())
 	cmd.Flags().BoolVarP(&o.output, "output", "o", false, "output the secret to stdout (unsafe)")
 	cmd.Flags().BoolVarP(&o.copy, "copy-clipboard", "c", false, "copy the secret to the clipboard")
+	cmd.Flags().BoolVarP(&o.copy, "copy", "", false, "same as --copy-clipboard")
+	cmd.Flags().StringVarP(&o.clearAfter, "clear-after", "", "", "clear the copied secret from the clipboard after the given duration, 0 disables clearing (default: 'clipboard.clear_after')")
+	cmd.Flags().BoolVarP(&o.reveal, "reveal", "r", false, "display the secret value on the terminal without prompting")
+	cmd.Flags().BoolVarP(&o.fuzzy, "fuzzy", "", false, "use the closest secret name if nothing matches, e.g., \"github\" for \"gihub\"")
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 0, "clear the displayed secret from the terminal after the given duration (e.g., 10s)")
 
 	return cmd
 }
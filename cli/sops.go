@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/sops"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+type SOPSError struct {
+	Err error
+}
+
+func (e *SOPSError) Error() string { return "sops: " + e.Err.Error() }
+
+func (e *SOPSError) Unwrap() error { return e.Err }
+
+// NewCmdSOPS creates the sops cobra command with its sub-commands.
+func NewCmdSOPS(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sops",
+		Short: "Decrypt and edit SOPS files using a vault-stored key (subcommands available)",
+		Long: `Decrypt and edit SOPS encrypted files using a key stored in the vault.
+
+The key is a regular secret holding either an age identity, as written by
+'age-keygen', or an ASCII armored GPG private key, selected using --key or
+the 'sops.key' config. It is handed to the external 'sops' command for the
+duration of a single command only, so that repository-encrypted files and
+the vault share one root of trust.
+
+Every use of the key is recorded in the audit log, like any secret read.`,
+		Example: `  # Store an age identity in the vault
+  age-keygen | vlt save --name sops-key --label sops
+
+  # Decrypt a file to stdout
+  vlt sops decrypt --key sops-key secrets.enc.yaml`,
+	}
+
+	cmd.AddCommand(NewCmdSOPSEdit(defaults))
+	cmd.AddCommand(NewCmdSOPSDecrypt(defaults))
+
+	return cmd
+}
+
+// sopsKeyOptions selects the vault secret holding the sops key.
+type sopsKeyOptions struct {
+	config *ResolvedConfig
+	name   string
+}
+
+// key returns the sops key stored in the vault.
+func (o *sopsKeyOptions) key(ctx context.Context, vaultOptions *VaultOptions) (sops.Key, error) {
+	name := o.name
+	if len(name) == 0 {
+		name = o.config.SOPSKey
+	}
+
+	if len(name) == 0 {
+		return sops.Key{}, errors.New("no key secret; use --key or set 'sops.key' in the config")
+	}
+
+	secrets, err := vaultOptions.vault.FilterSecrets(ctx, "", name, nil)
+	if err != nil {
+		return sops.Key{}, err
+	}
+
+	switch {
+	case len(secrets) == 0:
+		return sops.Key{}, fmt.Errorf("key secret %q: %w", name, vaulterrors.ErrSearchNoMatch)
+	case len(secrets) > 1:
+		return sops.Key{}, fmt.Errorf("key secret %q: %w", name, vaulterrors.ErrAmbiguousSecretMatch)
+	}
+
+	var id int
+	for id = range secrets {
+		break
+	}
+
+	material, err := vaultOptions.vault.ShowSecret(ctx, id)
+	if err != nil {
+		return sops.Key{}, err
+	}
+
+	return sops.ParseKey(material)
+}
+
+// SOPSEditOptions holds data required to run the command.
+type SOPSEditOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	keyOptions sopsKeyOptions
+}
+
+var _ genericclioptions.CmdOptions = &SOPSEditOptions{}
+
+// NewSOPSEditOptions initializes the options struct.
+func NewSOPSEditOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *SOPSEditOptions {
+	return &SOPSEditOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		keyOptions:   sopsKeyOptions{config: config},
+	}
+}
+
+func (*SOPSEditOptions) Complete() error { return nil }
+
+func (o *SOPSEditOptions) Validate() error {
+	if o.NonInteractive {
+		return &SOPSError{errors.New("edit requires an interactive terminal")}
+	}
+
+	return nil
+}
+
+func (o *SOPSEditOptions) Run(ctx context.Context, args ...string) error {
+	key, err := o.keyOptions.key(ctx, o.VaultOptions)
+	if err != nil {
+		return &SOPSError{err}
+	}
+
+	o.Debugf("editing %s using %s key\n", args[0], key.Type)
+
+	if err := sops.Edit(ctx, key, args[0], o.In, o.Out, o.ErrOut, args[1:]...); err != nil {
+		return &SOPSError{err}
+	}
+
+	return nil
+}
+
+// NewCmdSOPSEdit creates the sops edit cobra command.
+func NewCmdSOPSEdit(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSOPSEditOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "edit FILE [-- SOPS_ARGS...]",
+		Short: "Edit a SOPS file using the vault-stored key",
+		Long: `Open a SOPS encrypted file decrypted in $EDITOR, re-encrypting it once saved.
+
+Arguments after '--' are passed to sops as is, e.g., the recipients
+of a new file not covered by a '.sops.yaml' creation rule.`,
+		Example: `  # Edit an encrypted file
+  vlt sops edit --key sops-key secrets.enc.yaml
+
+  # Create a new file encrypted for the given age recipient
+  vlt sops edit --key sops-key new.enc.yaml -- --age age1...`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.keyOptions.name, "key", "", "", "name of the secret holding the key (default: 'sops.key' config)")
+
+	return cmd
+}
+
+// SOPSDecryptOptions holds data required to run the command.
+type SOPSDecryptOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	keyOptions sopsKeyOptions
+}
+
+var _ genericclioptions.CmdOptions = &SOPSDecryptOptions{}
+
+// NewSOPSDecryptOptions initializes the options struct.
+func NewSOPSDecryptOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *SOPSDecryptOptions {
+	return &SOPSDecryptOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		keyOptions:   sopsKeyOptions{config: config},
+	}
+}
+
+func (*SOPSDecryptOptions) Complete() error { return nil }
+
+func (*SOPSDecryptOptions) Validate() error { return nil }
+
+func (o *SOPSDecryptOptions) Run(ctx context.Context, args ...string) error {
+	key, err := o.keyOptions.key(ctx, o.VaultOptions)
+	if err != nil {
+		return &SOPSError{err}
+	}
+
+	plaintext, err := sops.Decrypt(ctx, key, args[0], args[1:]...)
+	if err != nil {
+		return &SOPSError{err}
+	}
+
+	if ok, err := o.confirmPrint(o.StdioOptions, "decrypted "+args[0]); !ok {
+		return err
+	}
+
+	if _, err := o.Out.Write(plaintext); err != nil {
+		return &SOPSError{err}
+	}
+
+	return nil
+}
+
+// NewCmdSOPSDecrypt creates the sops decrypt cobra command.
+func NewCmdSOPSDecrypt(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSOPSDecryptOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "decrypt FILE [-- SOPS_ARGS...]",
+		Short: "Decrypt a SOPS file using the vault-stored key",
+		Long: `Decrypt a SOPS encrypted file to stdout.
+
+Arguments after '--' are passed to sops as is, e.g., '--extract'.`,
+		Example: `  # Decrypt a file
+  vlt sops decrypt --key sops-key secrets.enc.yaml
+
+  # Decrypt a single value
+  vlt sops decrypt --key sops-key secrets.enc.yaml -- --extract '["db"]["password"]'`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.keyOptions.name, "key", "", "", "name of the secret holding the key (default: 'sops.key' config)")
+
+	return cmd
+}
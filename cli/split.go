@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/input"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+type SplitError struct {
+	Err error
+}
+
+func (e *SplitError) Error() string { return "split: " + e.Err.Error() }
+
+func (e *SplitError) Unwrap() error { return e.Err }
+
+// SplitOptions holds data required to run the command.
+type SplitOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	search    *SearchableOptions
+	dest      string // dest is the path of the new vault.
+	move      bool   // move removes the split secrets from the vault.
+	assumeYes bool
+}
+
+var _ genericclioptions.CmdOptions = &SplitOptions{}
+
+// NewSplitOptions initializes the options struct.
+func NewSplitOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *SplitOptions {
+	return &SplitOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		search:       NewSearchableOptions(),
+	}
+}
+
+func (*SplitOptions) Complete() error { return nil }
+
+func (o *SplitOptions) Validate() error {
+	if len(o.search.Labels) == 0 {
+		return &SplitError{errors.New("no secrets selected, use --label")}
+	}
+
+	if _, err := os.Stat(o.dest); !errors.Is(err, fs.ErrNotExist) {
+		return &SplitError{fmt.Errorf("%s: %w", o.dest, fs.ErrExist)}
+	}
+
+	if o.NonInteractive {
+		return &SplitError{vaulterrors.ErrNonInteractiveUnsupported}
+	}
+
+	return nil
+}
+
+func (o *SplitOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &SplitError{retErr}
+			return
+		}
+	}()
+
+	secrets, err := o.search.search(ctx, o.vault)
+	if err != nil {
+		return err
+	}
+
+	if len(secrets) == 0 {
+		o.Warnf("No match found.\n")
+		return vaulterrors.ErrSearchNoMatch
+	}
+
+	printTable(o.Out, secrets, o.labelColors(ctx, o.Out))
+
+	if o.move && !o.assumeYes {
+		yes, err := confirm(o.Out, o.In, "Move %d secrets to %q? (y/N): ", len(secrets), o.dest)
+		if err != nil {
+			return err
+		}
+
+		if !yes {
+			return nil
+		}
+	}
+
+	ids := extractIDs(secrets)
+
+	if err := o.split(ctx, ids); err != nil {
+		return err
+	}
+
+	if !o.move {
+		o.Infof("Copied %d secrets to %q.\n", len(ids), o.dest)
+		return nil
+	}
+
+	if _, err := o.vault.PurgeSecretsByIDs(ctx, ids...); err != nil {
+		return fmt.Errorf("secrets copied to %q, but not removed: %w", o.dest, err)
+	}
+
+	o.Infof("Moved %d secrets to %q.\n", len(ids), o.dest)
+
+	return genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite)
+}
+
+// split copies the given secrets into a new vault, created at the destination
+// path using a new password. The new vault is removed if copying fails.
+func (o *SplitOptions) split(ctx context.Context, ids []int) (retErr error) {
+	password, err := input.PromptNewPassword(o.Out, int(o.In.Fd()), masterPasswordMinLen)
+	if err != nil {
+		return fmt.Errorf("read new master key: %w", err)
+	}
+
+	dst, err := vault.New(ctx, o.dest, password)
+	if err != nil {
+		return fmt.Errorf("create vault: %w", err)
+	}
+	defer func() { //nolint:wsl
+		if retErr != nil {
+			retErr = errors.Join(retErr, os.Remove(o.dest))
+		}
+	}()
+
+	if err := os.Chmod(o.dest, vaultFilePerm); err != nil {
+		return errors.Join(err, dst.Close(ctx))
+	}
+
+	if _, err := o.vault.CopySecrets(ctx, dst, ids...); err != nil {
+		return errors.Join(err, dst.Close(ctx))
+	}
+
+	return dst.Close(ctx)
+}
+
+// NewCmdSplit creates the split cobra command.
+func NewCmdSplit(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSplitOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "split --label GLOB --dest PATH",
+		Short: "Copy or move the secrets having matching labels into a new vault",
+		Long: `Copy the secrets having a label matching any of the --label globs into
+a new vault, created at --dest with its own master password.
+
+Secrets are copied along with their labels, attachments and links, and the
+descriptions and colors of their labels. Using --move, they are removed from
+the vault once copied. Secrets of mounted vaults are not split.`,
+		Example: `  # Copy the personal secrets into a new vault
+  vlt split --label 'personal/*' --dest ~/personal.vlt
+
+  # Move them instead
+  vlt split --label 'personal/*' --dest ~/personal.vlt --move`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&o.search.Labels, "label", "", nil, FilterByLabels.Help())
+	cmd.Flags().StringVarP(&o.dest, "dest", "d", "", "the path of the new vault")
+	cmd.Flags().BoolVarP(&o.move, "move", "", false, "remove the secrets from the vault once copied")
+	cmd.Flags().BoolVarP(&o.assumeYes, "yes", "y", false, "skip confirmation prompts")
+
+	_ = cmd.MarkFlagRequired("dest")
+
+	return cmd
+}
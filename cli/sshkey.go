@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/input"
+	"github.com/ladzaretti/vlt-cli/sshkey"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+type SSHKeyError struct {
+	Err error
+}
+
+func (e *SSHKeyError) Error() string { return "ssh-key: " + e.Err.Error() }
+
+func (e *SSHKeyError) Unwrap() error { return e.Err }
+
+// NewCmdSSHKey creates the ssh-key cobra command with its sub-commands.
+func NewCmdSSHKey(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ssh-key",
+		Short: "Manage SSH keys (subcommands available)",
+		Long: fmt.Sprintf(`Manage SSH private keys stored in the vault.
+
+Keys are stored as secrets labeled %q, holding a JSON object of their
+private_key, public_key and comment fields. Private keys are stored
+decrypted, protected by the vault encryption.
+
+Stored keys are served to SSH clients using 'vlt agent --ssh'. As with any
+other JSON secret, fields can also be printed using 'vlt get',
+e.g., 'vlt get github/public_key'.`, sshkey.Label),
+	}
+
+	cmd.AddCommand(NewCmdSSHKeyAdd(defaults))
+	cmd.AddCommand(NewCmdSSHKeyList(defaults))
+
+	return cmd
+}
+
+// SSHKeyAddOptions holds data required to run the command.
+type SSHKeyAddOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	name    string
+	comment string
+	labels  []string
+}
+
+var _ genericclioptions.CmdOptions = &SSHKeyAddOptions{}
+
+// NewSSHKeyAddOptions initializes the options struct.
+func NewSSHKeyAddOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *SSHKeyAddOptions {
+	return &SSHKeyAddOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*SSHKeyAddOptions) Complete() error { return nil }
+
+func (o *SSHKeyAddOptions) Validate() error {
+	if o.NonInteractive && len(o.name) == 0 {
+		return &SSHKeyError{errors.New("--name is required when reading the key from stdin")}
+	}
+
+	return nil
+}
+
+func (o *SSHKeyAddOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &SSHKeyError{retErr}
+			return
+		}
+	}()
+
+	var (
+		pemBytes []byte
+		err      error
+	)
+
+	switch {
+	case len(args) > 0:
+		o.name, o.comment = cmp.Or(o.name, filepath.Base(args[0])), cmp.Or(o.comment, publicKeyComment(args[0]))
+		pemBytes, err = os.ReadFile(args[0])
+	case o.NonInteractive:
+		pemBytes, err = io.ReadAll(o.In)
+	default:
+		return errors.New("no key file given, and no key piped or redirected")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	k, err := sshkey.New(pemBytes, nil, cmp.Or(o.comment, o.name))
+	if errors.Is(err, sshkey.ErrPassphraseRequired) && !o.NonInteractive {
+		passphrase, perr := input.PromptReadSecure(o.Out, int(o.In.Fd()), "Enter passphrase for %q: ", o.name)
+		if perr != nil {
+			return perr
+		}
+
+		k, err = sshkey.New(pemBytes, []byte(passphrase), cmp.Or(o.comment, o.name))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(k)
+	if err != nil {
+		return err
+	}
+
+	labels := append(slices.Clone(o.labels), sshkey.Label)
+
+	n, err := o.vault.InsertNewSecret(ctx, o.name, string(value), labels)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNoSecretInserted
+	}
+
+	pub, err := k.Public()
+	if err != nil {
+		return err
+	}
+
+	o.Infof("Added ssh key %q (%s)\n", o.name, ssh.FingerprintSHA256(pub))
+
+	return nil
+}
+
+// publicKeyComment returns the comment of the public key stored next to
+// the given private key file, e.g., id_ed25519.pub, if any.
+func publicKeyComment(path string) string {
+	b, err := os.ReadFile(path + ".pub")
+	if err != nil {
+		return ""
+	}
+
+	_, comment, _, _, err := ssh.ParseAuthorizedKey(b) //nolint:dogsled
+	if err != nil {
+		return ""
+	}
+
+	return comment
+}
+
+// SSHKeyListOptions holds data required to run the command.
+type SSHKeyListOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &SSHKeyListOptions{}
+
+// NewSSHKeyListOptions initializes the options struct.
+func NewSSHKeyListOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *SSHKeyListOptions {
+	return &SSHKeyListOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*SSHKeyListOptions) Complete() error { return nil }
+
+func (*SSHKeyListOptions) Validate() error { return nil }
+
+func (o *SSHKeyListOptions) Run(ctx context.Context, _ ...string) error {
+	secrets, err := o.searchAll(ctx, o.StdioOptions, &SearchableOptions{Labels: []string{sshkey.Label}})
+	if err != nil {
+		return &SSHKeyError{err}
+	}
+
+	if len(secrets) == 0 {
+		o.Warnf("No ssh keys found.\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tTYPE\tFINGERPRINT\tCOMMENT")
+
+	for _, s := range secrets {
+		k, err := o.readSSHKey(ctx, o.StdioOptions, s)
+		if err != nil {
+			o.Warnf("vlt: ssh key %q: %v\n", s.name, err)
+			continue
+		}
+
+		pub, err := k.Public()
+		if err != nil {
+			o.Warnf("vlt: ssh key %q: %v\n", s.name, err)
+			continue
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.displayID(), s.name, pub.Type(), ssh.FingerprintSHA256(pub), k.Comment)
+	}
+
+	return nil
+}
+
+// readSSHKey reads the ssh key held by the given secret.
+func (o *VaultOptions) readSSHKey(ctx context.Context, io *genericclioptions.StdioOptions, s secretWithLabels) (sshkey.Key, error) {
+	v, err := o.vaultOf(ctx, io, s)
+	if err != nil {
+		return sshkey.Key{}, err
+	}
+
+	value, err := v.ShowSecret(ctx, s.id)
+	if err != nil {
+		return sshkey.Key{}, err
+	}
+
+	return sshkey.Parse(value)
+}
+
+// NewCmdSSHKeyAdd creates the ssh-key add cobra command.
+func NewCmdSSHKeyAdd(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSSHKeyAddOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "add [FILE]",
+		Short: "Add an SSH private key to the vault",
+		Long: `Add an SSH private key to the vault.
+
+The key is read from FILE, or from stdin if piped or redirected, in any of
+the formats supported by OpenSSH. Encrypted keys are decrypted using a
+prompted passphrase before being stored.
+
+The key is named after FILE by default, and commented by the comment
+of its public key file (FILE.pub), if any.`,
+		Example: `  # Add a key, named id_ed25519
+  vlt ssh-key add ~/.ssh/id_ed25519
+
+  # Add a key read from stdin
+  vlt ssh-key add --name deploy --label ci < deploy_key`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.name, "name", "", "", "the key name (default: the key file name)")
+	cmd.Flags().StringVarP(&o.comment, "comment", "", "", "the key comment (default: the public key file comment, or the key name)")
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "optional label to associate with the key (comma-separated or repeated)")
+
+	return cmd
+}
+
+// NewCmdSSHKeyList creates the ssh-key list cobra command.
+func NewCmdSSHKeyList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSSHKeyListOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the SSH keys",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
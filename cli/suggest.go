@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ladzaretti/vlt-cli/fuzzy"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// maxSuggestions is the number of suggestions listed by [suggestionList].
+const maxSuggestions = 3
+
+// suggestNames returns the names of the secrets of the vault and its mounts
+// close to the given name, which was not found, closest first. Nothing is
+// suggested for globs.
+//
+// Suggestions are best effort, none are returned if the secrets cannot be listed.
+func (o *VaultOptions) suggestNames(ctx context.Context, io *genericclioptions.StdioOptions, name string) []fuzzy.Suggestion {
+	if len(name) == 0 || strings.ContainsAny(name, "*?[") {
+		return nil
+	}
+
+	secrets, err := o.searchAll(ctx, io, NewSearchableOptions())
+	if err != nil {
+		io.Debugf("vlt: suggest names: %v\n", err)
+		return nil
+	}
+
+	names := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		names = append(names, s.name)
+	}
+
+	return fuzzy.Suggest(name, names)
+}
+
+// notFound returns the [vaulterrors.ErrSearchNoMatch] error of a lookup of name,
+// suggesting the closest secret names, if any.
+func (o *VaultOptions) notFound(ctx context.Context, io *genericclioptions.StdioOptions, name string) error {
+	if suggestions := o.suggestNames(ctx, io, name); len(suggestions) > 0 {
+		return fmt.Errorf("%q: %w, did you mean %s?", name, vaulterrors.ErrSearchNoMatch, suggestionList(suggestions))
+	}
+
+	return fmt.Errorf("%q: %w", name, vaulterrors.ErrSearchNoMatch)
+}
+
+// suggestionList returns the quoted names of the first suggestions,
+// e.g., `"github", "gitlab" or "gist"`.
+func suggestionList(suggestions []fuzzy.Suggestion) string {
+	quoted := make([]string, 0, maxSuggestions)
+	for _, s := range suggestions[:min(len(suggestions), maxSuggestions)] {
+		quoted = append(quoted, strconv.Quote(s.Name))
+	}
+
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
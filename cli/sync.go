@@ -0,0 +1,461 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/input"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+// oplogFileExt is the extension of oplog files exchanged through the sync directory.
+const oplogFileExt = ".vltsync"
+
+// Conflict resolution strategies.
+const (
+	strategyNewer       = "newer"
+	strategyLocal       = "local"
+	strategyRemote      = "remote"
+	strategyInteractive = "interactive"
+)
+
+var strategies = []string{strategyNewer, strategyLocal, strategyRemote, strategyInteractive}
+
+type SyncError struct {
+	Err error
+}
+
+func (e *SyncError) Error() string { return "sync: " + e.Err.Error() }
+
+func (e *SyncError) Unwrap() error { return e.Err }
+
+// NewCmdSync creates the sync cobra command with its sub-commands.
+func NewCmdSync(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Synchronize the vault between devices (subcommands available)",
+		Long: `Synchronize the vault between devices.
+
+Once sync is initialized, every change to a secret is recorded in an encrypted,
+append-only operation log (oplog). Devices exchange their oplogs through a shared
+directory, e.g., one synced by Syncthing, rclone or a network mount, and merge them.
+Devices that merged the same changes converge to the same secrets.
+
+Each device writes its own oplog file to the directory, and never writes to the
+files of other devices. Concurrent changes of the same secret are resolved on pull,
+see 'vlt sync pull --help'.
+
+Setup:
+	1. Initialize sync on the first device: 'vlt sync init'.
+	2. Copy the vault file to the other device.
+	3. Assign the copy a device id of its own: 'vlt sync init --new-device'.
+
+The oplog is sealed using a sync key stored in the vault, so only copies
+of the same vault can exchange oplogs.`,
+		Example: `  # Exchange changes through a synced directory
+  vlt sync pull ~/Sync/vlt
+  vlt sync push ~/Sync/vlt`,
+	}
+
+	cmd.AddCommand(NewCmdSyncInit(defaults))
+	cmd.AddCommand(NewCmdSyncStatus(defaults))
+	cmd.AddCommand(NewCmdSyncPush(defaults))
+	cmd.AddCommand(NewCmdSyncPull(defaults))
+
+	return cmd
+}
+
+// SyncInitOptions holds data required to run the command.
+type SyncInitOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	newDevice bool
+}
+
+var _ genericclioptions.CmdOptions = &SyncInitOptions{}
+
+// NewSyncInitOptions initializes the options struct.
+func NewSyncInitOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *SyncInitOptions {
+	return &SyncInitOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*SyncInitOptions) Complete() error { return nil }
+
+func (*SyncInitOptions) Validate() error { return nil }
+
+func (o *SyncInitOptions) Run(ctx context.Context, _ ...string) error {
+	device, err := o.vault.InitSync(ctx, o.newDevice)
+	if err != nil {
+		return &SyncError{err}
+	}
+
+	o.Infof("Sync initialized, device id: %s\n", device)
+
+	return nil
+}
+
+// NewCmdSyncInit creates the sync init cobra command.
+func NewCmdSyncInit(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSyncInitOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Initialize sync on this device",
+		Long: `Initialize sync on this device.
+
+On the first device, the current state of every secret is recorded in the oplog.
+On other devices, which must start from a copy of the vault, use --new-device
+to assign the copy a device id of its own.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.newDevice, "new-device", "", false, "assign a new device id to a copy of a synced vault")
+
+	return cmd
+}
+
+// SyncStatusOptions holds data required to run the command.
+type SyncStatusOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &SyncStatusOptions{}
+
+// NewSyncStatusOptions initializes the options struct.
+func NewSyncStatusOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *SyncStatusOptions {
+	return &SyncStatusOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*SyncStatusOptions) Complete() error { return nil }
+
+func (*SyncStatusOptions) Validate() error { return nil }
+
+func (o *SyncStatusOptions) Run(ctx context.Context, _ ...string) error {
+	status, err := o.vault.SyncStatus(ctx)
+	if err != nil {
+		return &SyncError{err}
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "DEVICE\tENTRIES")
+
+	for _, d := range slices.Sorted(maps.Keys(status.Clock)) {
+		name := d
+		if d == status.Device {
+			name += " (this device)"
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\n", name, status.Clock[d])
+	}
+
+	fmt.Fprintln(tw) // add padding
+
+	return nil
+}
+
+// NewCmdSyncStatus creates the sync status cobra command.
+func NewCmdSyncStatus(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSyncStatusOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the sync state",
+		Long:  "Show the id of this device, and the number of oplog entries merged from each known device.",
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
+
+// SyncPushOptions holds data required to run the command.
+type SyncPushOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &SyncPushOptions{}
+
+// NewSyncPushOptions initializes the options struct.
+func NewSyncPushOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *SyncPushOptions {
+	return &SyncPushOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*SyncPushOptions) Complete() error { return nil }
+
+func (*SyncPushOptions) Validate() error { return nil }
+
+func (o *SyncPushOptions) Run(ctx context.Context, args ...string) error {
+	dir := args[0]
+
+	status, err := o.vault.SyncStatus(ctx)
+	if err != nil {
+		return &SyncError{err}
+	}
+
+	data, err := o.vault.ExportOplog(ctx)
+	if err != nil {
+		return &SyncError{err}
+	}
+
+	path := filepath.Join(dir, status.Device+oplogFileExt)
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return &SyncError{err}
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return &SyncError{err}
+	}
+
+	o.Infof("Pushed %d entries to %s\n", status.Clock[status.Device], path)
+
+	return nil
+}
+
+// NewCmdSyncPush creates the sync push cobra command.
+func NewCmdSyncPush(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSyncPushOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "push DIR",
+		Short: "Write the oplog of this device to the sync directory",
+		Long:  fmt.Sprintf("Write the oplog of this device to DIR/<device-id>%s, replacing the previous one.", oplogFileExt),
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
+
+// SyncPullOptions holds data required to run the command.
+type SyncPullOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	strategy string
+}
+
+var _ genericclioptions.CmdOptions = &SyncPullOptions{}
+
+// NewSyncPullOptions initializes the options struct.
+func NewSyncPullOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *SyncPullOptions {
+	return &SyncPullOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*SyncPullOptions) Complete() error { return nil }
+
+func (o *SyncPullOptions) Validate() error {
+	if !slices.Contains(strategies, o.strategy) {
+		return &SyncError{fmt.Errorf("invalid --strategy value %q: must be one of %s", o.strategy, strings.Join(strategies, ", "))}
+	}
+
+	if o.strategy == strategyInteractive && o.NonInteractive {
+		return &SyncError{fmt.Errorf("--strategy %s: %w", strategyInteractive, vaulterrors.ErrNonInteractiveUnsupported)}
+	}
+
+	return nil
+}
+
+func (o *SyncPullOptions) Run(ctx context.Context, args ...string) error {
+	paths, err := filepath.Glob(filepath.Join(args[0], "*"+oplogFileExt))
+	if err != nil {
+		return &SyncError{err}
+	}
+
+	if len(paths) == 0 {
+		o.Warnf("No oplog files found in %s.\n", args[0])
+		return nil
+	}
+
+	// the file of this device is merged too, detecting another
+	// device using the same device id.
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return &SyncError{err}
+		}
+
+		res, err := o.vault.ImportOplog(ctx, data, o.resolver())
+		if err != nil {
+			return &SyncError{fmt.Errorf("%s: %w", path, err)}
+		}
+
+		if res.Entries == 0 {
+			o.Debugf("%s: up to date\n", path)
+			continue
+		}
+
+		o.Infof("Merged %d entries from device %s: %d inserted, %d updated, %d deleted, %d conflicts resolved\n",
+			res.Entries, res.Device, res.Inserted, res.Updated, res.Deleted, res.Conflicts)
+	}
+
+	return nil
+}
+
+// resolver returns the conflict resolver of the selected strategy.
+//
+// Non-interactive resolutions are reported, so no change is dropped silently.
+func (o *SyncPullOptions) resolver() vault.ConflictResolver {
+	var resolve vault.ConflictResolver
+
+	switch o.strategy {
+	case strategyInteractive:
+		return o.resolveInteractive
+	case strategyLocal:
+		resolve = vault.ResolveLocal
+	case strategyRemote:
+		resolve = vault.ResolveRemote
+	default:
+		resolve = vault.ResolveNewer
+	}
+
+	return func(ctx context.Context, c vault.SyncConflict) (vault.Resolution, error) {
+		res, err := resolve(ctx, c)
+		if err != nil {
+			return res, err
+		}
+
+		kept, dropped := "local", "remote"
+		if res == vault.KeepRemote {
+			kept, dropped = dropped, kept
+		}
+
+		o.Warnf("Conflict on %s: kept the %s version, dropped the %s version (strategy %s).\n", conflictDescription(c), kept, dropped, o.strategy)
+
+		return res, nil
+	}
+}
+
+// resolveInteractive shows the metadata of the conflicting versions,
+// and asks the user which one to keep.
+func (o *SyncPullOptions) resolveInteractive(_ context.Context, c vault.SyncConflict) (vault.Resolution, error) {
+	fmt.Fprintf(o.Out, "\nConflict on %s:\n\n", conflictDescription(c))
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+
+	fmt.Fprintln(tw, "VERSION\tNAME\tLABELS\tVALUE\tDEVICE\tRECORDED")
+
+	if c.Base != nil {
+		printSyncVersion(tw, "base", *c.Base, true)
+	}
+
+	printSyncVersion(tw, "local", c.Local, false)
+	printSyncVersion(tw, "remote", c.Remote, false)
+
+	fmt.Fprintln(tw) // add padding
+
+	if err := tw.Flush(); err != nil {
+		return vault.KeepLocal, err
+	}
+
+	for {
+		answer, err := input.PromptRead(o.Out, o.In, "Keep the [l]ocal or the [r]emote version? [l/r]: ")
+		if err != nil {
+			return vault.KeepLocal, err
+		}
+
+		switch strings.ToLower(answer) {
+		case "l", "local":
+			return vault.KeepLocal, nil
+		case "r", "remote":
+			return vault.KeepRemote, nil
+		}
+	}
+}
+
+// printSyncVersion prints a table row describing v, where the value of
+// non-base versions is described relative to the base version.
+func printSyncVersion(w io.Writer, version string, v vault.SyncVersion, base bool) {
+	recorded := v.Time.Local().Format(time.DateTime)
+
+	if v.Deleted {
+		fmt.Fprintf(w, "%s\t-\t-\tdeleted\t%s\t%s\n", version, v.Device, recorded)
+		return
+	}
+
+	value := "unchanged"
+
+	switch {
+	case base:
+		value = "-"
+	case v.ValueChanged:
+		value = "changed"
+	}
+
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", version, v.Name, strings.Join(v.Labels, ","), value, v.Device, recorded)
+}
+
+// conflictDescription describes the secret of a sync conflict by its latest known name.
+func conflictDescription(c vault.SyncConflict) string {
+	for _, v := range []*vault.SyncVersion{&c.Local, &c.Remote, c.Base} {
+		if v != nil && !v.Deleted {
+			return secretDescription(v.Name)
+		}
+	}
+
+	return "secret " + c.UID
+}
+
+// NewCmdSyncPull creates the sync pull cobra command.
+func NewCmdSyncPull(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewSyncPullOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "pull DIR",
+		Short: "Merge the oplogs of other devices from the sync directory",
+		Long: fmt.Sprintf(`Merge the oplogs of other devices from the *%s files in DIR.
+
+A conflict occurs when a secret was changed on this device (local) and on another
+device (remote) since they last synced. Only one version is kept, according to --strategy:
+	newer         keep the most recently changed version
+	local         keep the local version
+	remote        keep the remote version
+	interactive   show the metadata of both versions and their common base, and ask which
+	              one to keep. Secret values are never shown, only whether they changed.
+
+Every resolved conflict is reported. The resolution is recorded in the oplog,
+so other devices keep the same version on their next pull.`, oplogFileExt),
+		Example: `  # Decide on every conflict
+  vlt sync pull ~/Sync/vlt --strategy interactive`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.strategy, "strategy", "", strategyNewer, fmt.Sprintf("conflict resolution strategy (%s)", strings.Join(strategies, "|")))
+
+	return cmd
+}
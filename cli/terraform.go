@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vault"
+
+	"github.com/spf13/cobra"
+)
+
+// terraformActor is recorded in the audit log entries of secrets read by Terraform.
+const terraformActor = "terraform"
+
+type TerraformError struct {
+	Err error
+}
+
+func (e *TerraformError) Error() string { return "terraform-external: " + e.Err.Error() }
+
+func (e *TerraformError) Unwrap() error { return e.Err }
+
+// TerraformExternalOptions holds data required to run the command.
+type TerraformExternalOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &TerraformExternalOptions{}
+
+// NewTerraformExternalOptions initializes the options struct.
+func NewTerraformExternalOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *TerraformExternalOptions {
+	return &TerraformExternalOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*TerraformExternalOptions) Complete() error { return nil }
+
+func (*TerraformExternalOptions) Validate() error { return nil }
+
+func (o *TerraformExternalOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &TerraformError{retErr}
+			return
+		}
+	}()
+
+	b, err := io.ReadAll(o.In)
+	if err != nil {
+		return fmt.Errorf("read query: %w", err)
+	}
+
+	var query map[string]string
+	if err := json.Unmarshal(b, &query); err != nil {
+		return fmt.Errorf("parse query: %w", err)
+	}
+
+	sessionClient, err := o.openNoPrompt(ctx, o.StdioOptions)
+	if err != nil {
+		return err
+	}
+	defer func() { //nolint:wsl
+		retErr = errors.Join(retErr, o.closeNoPrompt(ctx, sessionClient))
+	}()
+
+	ctx = vault.WithPrincipal(ctx, vault.Principal{Actor: terraformActor, ReadOnly: true})
+	result := make(map[string]string, len(query))
+
+	for _, key := range slices.Sorted(maps.Keys(query)) {
+		ref := cmp.Or(query[key], key)
+
+		value, err := o.lookupValue(ctx, o.StdioOptions, ref)
+		if err != nil {
+			return err
+		}
+
+		result[key] = value
+	}
+
+	return json.NewEncoder(o.Out).Encode(result)
+}
+
+// NewCmdTerraformExternal creates the terraform-external cobra command.
+func NewCmdTerraformExternal(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewTerraformExternalOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "terraform-external",
+		Short: "Serve secrets to the Terraform external data source",
+		Long: `Serve secrets to the Terraform external data source.
+
+The query is read from stdin as a JSON object, whose keys reference secrets as
+in 'vlt get' (e.g., "github" or "db/password"), and the result is written to
+stdout as a JSON object mapping the same keys to the secret values. A key may
+be given a non-empty value, referencing the secret in its place.
+
+As with 'vlt get', the vault is opened using the current session ('vlt login')
+or the VLT_TOKEN access token, without prompting. Secrets are only read, and
+every read is recorded in the audit log under the "terraform" actor.`,
+		Example: `  # Terraform configuration
+  data "external" "secrets" {
+    program = ["vlt", "terraform-external"]
+    query = {
+      "github"   = ""
+      "db_pass"  = "db/password"
+    }
+  }
+
+  # data.external.secrets.result["db_pass"] holds the "password" field of "db"`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
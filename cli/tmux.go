@@ -0,0 +1,325 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+const tmuxCmd = "tmux"
+
+// tmuxPicker is the picker run in the popup, reading the secrets from stdin.
+var tmuxPicker = []string{"fzf", "--reverse", "--prompt", "vlt> "}
+
+type TmuxError struct {
+	Err error
+}
+
+func (e *TmuxError) Error() string { return "tmux: " + e.Err.Error() }
+
+func (e *TmuxError) Unwrap() error { return e.Err }
+
+// TmuxOptions holds data required to run the command.
+type TmuxOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	flags  *Flags
+	target string        // target is the pane the secret is sent to.
+	delay  time.Duration // delay is how long to wait before sending the secret.
+	enter  bool          // enter presses Enter after sending the secret.
+	pane   bool          // pane opens the picker in a split pane instead of a popup.
+	labels []string
+}
+
+var _ genericclioptions.CmdOptions = &TmuxOptions{}
+
+// NewTmuxOptions initializes the options struct.
+func NewTmuxOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, flags *Flags) *TmuxOptions {
+	return &TmuxOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		flags:        flags,
+	}
+}
+
+func (o *TmuxOptions) Complete() error {
+	if len(o.target) == 0 {
+		o.target = os.Getenv("TMUX_PANE")
+	}
+
+	return nil
+}
+
+func (o *TmuxOptions) Validate() error {
+	if len(os.Getenv("TMUX")) == 0 {
+		return &TmuxError{errors.New("not running inside tmux")}
+	}
+
+	if len(o.target) == 0 {
+		return &TmuxError{errors.New("no target pane, use --target, e.g., --target '#{pane_id}' in tmux key bindings")}
+	}
+
+	if o.delay < 0 {
+		return &TmuxError{errors.New("--delay must not be negative")}
+	}
+
+	return nil
+}
+
+// Run opens 'vlt tmux pick' in a tmux popup, or a split pane,
+// using the current vault and configuration files.
+func (o *TmuxOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &TmuxError{retErr}
+			return
+		}
+	}()
+
+	pick, err := o.pickCommand()
+	if err != nil {
+		return err
+	}
+
+	// started in the working directory, so that relative paths, which
+	// sessions are keyed by, are resolved as in the current shell.
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"display-popup", "-E", "-c", wd, "-t", o.target, pick}
+	if o.pane {
+		args = []string{"split-window", "-v", "-l", "40%", "-c", wd, "-t", o.target, pick}
+	}
+
+	cmd := exec.CommandContext(ctx, tmuxCmd, args...)
+	cmd.Stderr = o.ErrOut
+
+	return cmd.Run()
+}
+
+// pickCommand returns the shell command running 'vlt tmux pick'
+// using the current vault and configuration files.
+func (o *TmuxOptions) pickCommand() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{exe, "--file", o.path}
+
+	if len(o.flags.configPath) > 0 {
+		args = append(args, "--config", o.flags.configPath)
+	}
+
+	args = append(args, "tmux", "pick", "--target", o.target, "--delay", o.delay.String())
+
+	if o.enter {
+		args = append(args, "--enter")
+	}
+
+	for _, l := range o.labels {
+		args = append(args, "--label", l)
+	}
+
+	quoted := make([]string, 0, len(args))
+	for _, a := range args {
+		quoted = append(quoted, shellQuote(a))
+	}
+
+	return strings.Join(quoted, " "), nil
+}
+
+// TmuxPickOptions holds data required to run the command.
+type TmuxPickOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config *ResolvedConfig
+	search *SearchableOptions
+	target string
+	delay  time.Duration
+	enter  bool
+}
+
+var _ genericclioptions.CmdOptions = &TmuxPickOptions{}
+
+// NewTmuxPickOptions initializes the options struct.
+func NewTmuxPickOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *TmuxPickOptions {
+	return &TmuxPickOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+		search:       NewSearchableOptions(),
+	}
+}
+
+func (*TmuxPickOptions) Complete() error { return nil }
+
+func (o *TmuxPickOptions) Validate() error {
+	if len(o.target) == 0 {
+		return &TmuxError{errors.New("no target pane, use --target")}
+	}
+
+	return nil
+}
+
+func (o *TmuxPickOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &TmuxError{retErr}
+
+			// the popup is closed on exit, where stderr is not seen.
+			_ = exec.CommandContext(context.WithoutCancel(ctx), tmuxCmd, "display-message", "-t", o.target, "vlt: "+retErr.Error()).Run()
+		}
+	}()
+
+	secrets, err := o.searchAll(ctx, o.StdioOptions, o.search)
+	if err != nil {
+		return err
+	}
+
+	secrets, err = o.rankByFrecency(ctx, o.StdioOptions, secrets, time.Duration(o.config.FrecencyHalfLife))
+	if err != nil {
+		return err
+	}
+
+	if len(secrets) == 0 {
+		return errors.New("no secrets found")
+	}
+
+	entries, lines := dmenuEntries(secrets)
+
+	chosen, err := o.pick(ctx, lines)
+	if err != nil || len(chosen) == 0 {
+		return err
+	}
+
+	secret, ok := entries[chosen]
+	if !ok {
+		return fmt.Errorf("%q: %w", chosen, vaulterrors.ErrSearchNoMatch)
+	}
+
+	v, err := o.vaultOf(ctx, o.StdioOptions, secret)
+	if err != nil {
+		return err
+	}
+
+	value, err := v.ShowSecret(ctx, secret.id)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(o.delay):
+	}
+
+	// sent literally, so that the value is not looked up as key names.
+	if err := exec.CommandContext(ctx, tmuxCmd, "send-keys", "-t", o.target, "-l", "--", value).Run(); err != nil {
+		return fmt.Errorf("send-keys: %w", err)
+	}
+
+	if o.enter {
+		if err := exec.CommandContext(ctx, tmuxCmd, "send-keys", "-t", o.target, "Enter").Run(); err != nil {
+			return fmt.Errorf("send-keys: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// pick runs [tmuxPicker] on the given lines, returning the chosen one.
+// Nothing is returned if cancelled.
+func (o *TmuxPickOptions) pick(ctx context.Context, lines []string) (string, error) {
+	var stdout bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, tmuxPicker[0], tmuxPicker[1:]...)
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n") + "\n")
+	cmd.Stdout = &stdout
+	cmd.Stderr = o.ErrOut
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", fmt.Errorf("picker: %w", err)
+		}
+
+		o.Debugf("vlt: picker exited with code %d, nothing chosen\n", exitErr.ExitCode())
+
+		return "", nil
+	}
+
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+// NewCmdTmux creates the tmux cobra command.
+func NewCmdTmux(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewTmuxOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.cliFlags)
+
+	cmd := &cobra.Command{
+		Use:   "tmux",
+		Short: "Pick a secret in a tmux popup and send it to the pane using send-keys",
+		Long: `Pick a secret using fzf in a tmux popup, then type it into the originating
+pane using 'tmux send-keys', e.g., over SSH, where no clipboard is available.
+
+The password is prompted for in the popup if not logged in. Use --pane to open
+the picker in a split pane instead, with tmux versions predating popups (3.2).
+
+The target pane defaults to the pane vlt runs in. In key bindings, run using
+'run-shell', pass it using --target '#{pane_id}'.`,
+		Example: `  # ~/.tmux.conf: Prefix+P types a secret, Prefix+Alt+P types it and presses Enter
+  bind-key P run-shell -b "vlt tmux --target '#{pane_id}'"
+  bind-key M-p run-shell -b "vlt tmux --target '#{pane_id}' --delay 200ms --enter"`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.target, "target", "t", "", "pane the secret is sent to (default: $TMUX_PANE)")
+	cmd.Flags().DurationVarP(&o.delay, "delay", "d", 0, "wait before sending the secret, e.g., for a password prompt to show")
+	cmd.Flags().BoolVarP(&o.enter, "enter", "", false, "press Enter after sending the secret")
+	cmd.Flags().BoolVarP(&o.pane, "pane", "", false, "open the picker in a split pane instead of a popup")
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, FilterByLabels.Help())
+
+	cmd.AddCommand(NewCmdTmuxPick(defaults))
+
+	return cmd
+}
+
+// NewCmdTmuxPick creates the tmux pick cobra command.
+func NewCmdTmuxPick(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewTmuxPickOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:    "pick",
+		Short:  "Pick a secret using fzf and send it to a tmux pane, run by 'vlt tmux'",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.target, "target", "t", "", "pane the secret is sent to")
+	cmd.Flags().DurationVarP(&o.delay, "delay", "d", 0, "wait before sending the secret")
+	cmd.Flags().BoolVarP(&o.enter, "enter", "", false, "press Enter after sending the secret")
+	cmd.Flags().StringSliceVarP(&o.search.Labels, "label", "", nil, FilterByLabels.Help())
+
+	return cmd
+}
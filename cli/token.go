@@ -0,0 +1,267 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+	"github.com/ladzaretti/vlt-cli/vault"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// tokenEnv is the environment variable holding the access token
+	// used to open the vault instead of the master password.
+	tokenEnv = "VLT_TOKEN"
+
+	defaultTokenTTL = "1h"
+)
+
+type TokenError struct {
+	Err error
+}
+
+func (e *TokenError) Error() string { return "token: " + e.Err.Error() }
+
+func (e *TokenError) Unwrap() error { return e.Err }
+
+// NewCmdToken creates the token cobra command with its sub-commands.
+func NewCmdToken(defaults *DefaultVltOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage scoped access tokens (subcommands available)",
+		Long: fmt.Sprintf(`Manage scoped access tokens.
+
+Access tokens allow scripts (e.g., CI jobs) to open the vault without the master password.
+A token only grants access to secrets with a label matching its scope,
+and cannot be used to access the audit log or manage tokens.
+
+To open the vault using a token, set the %s environment variable.
+
+Note:
+	The scope is enforced by vlt, a token can unlock the vault
+	and must be protected like the master password.`, tokenEnv),
+	}
+
+	cmd.AddCommand(NewCmdTokenIssue(defaults))
+	cmd.AddCommand(NewCmdTokenList(defaults))
+	cmd.AddCommand(NewCmdTokenRevoke(defaults))
+
+	return cmd
+}
+
+// TokenIssueOptions holds data required to run the command.
+type TokenIssueOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	labels   []string
+	ttl      string
+	readOnly bool
+
+	tokenOptions vault.TokenOptions
+}
+
+var _ genericclioptions.CmdOptions = &TokenIssueOptions{}
+
+// NewTokenIssueOptions initializes the options struct.
+func NewTokenIssueOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *TokenIssueOptions {
+	return &TokenIssueOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		ttl:          defaultTokenTTL,
+	}
+}
+
+func (o *TokenIssueOptions) Complete() error {
+	ttl, err := cmdutil.ParseDuration(o.ttl)
+	if err != nil {
+		return &TokenError{fmt.Errorf("invalid --ttl value %q: %w", o.ttl, err)}
+	}
+
+	o.tokenOptions = vault.TokenOptions{
+		Scope:    o.labels,
+		ReadOnly: o.readOnly,
+		TTL:      ttl,
+	}
+
+	return nil
+}
+
+func (o *TokenIssueOptions) Validate() error {
+	if len(o.tokenOptions.Scope) == 0 {
+		return &TokenError{errors.New("at least one --label is required")}
+	}
+
+	if o.tokenOptions.TTL <= 0 {
+		return &TokenError{fmt.Errorf("invalid --ttl value %q (must be positive)", o.ttl)}
+	}
+
+	return nil
+}
+
+func (o *TokenIssueOptions) Run(ctx context.Context, _ ...string) error {
+	token, info, err := o.vault.IssueToken(ctx, o.tokenOptions)
+	if err != nil {
+		return &TokenError{err}
+	}
+
+	o.Warnf("Issued token %s for labels %q, expires at %s.\n", info.ID, strings.Join(info.Scope, ","), info.ExpiresAt.Local().Format(time.DateTime))
+	o.Warnf("The token is shown only once, store it securely.\n")
+	o.Infof("%s\n", token)
+
+	return nil
+}
+
+// NewCmdTokenIssue creates the token issue cobra command.
+func NewCmdTokenIssue(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewTokenIssueOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:     "issue",
+		Aliases: []string{"create"},
+		Short:   "Issue a new scoped access token",
+		Long: `Issue a new access token limited to secrets with a label matching any of the given glob patterns.
+
+The token is printed to stdout once, and cannot be retrieved again.`,
+		Example: fmt.Sprintf(`  # Issue a read-only token for CI secrets, valid for one hour
+  vlt token create --label 'ci/*' --ttl 1h --read-only-token
+
+  # Use the token in a CI job
+  %s=vlt_... vlt show --name deploy-key --output`, tokenEnv),
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "label glob pattern the token is scoped to (comma-separated or repeated)")
+	cmd.Flags().StringVarP(&o.ttl, "ttl", "", defaultTokenTTL, "token lifetime (e.g., 1h, 7d)")
+	cmd.Flags().BoolVarP(&o.readOnly, "read-only-token", "", false, "reject modifications made using the token")
+
+	return cmd
+}
+
+// TokenListOptions holds data required to run the command.
+type TokenListOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &TokenListOptions{}
+
+// NewTokenListOptions initializes the options struct.
+func NewTokenListOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *TokenListOptions {
+	return &TokenListOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*TokenListOptions) Complete() error { return nil }
+
+func (*TokenListOptions) Validate() error { return nil }
+
+func (o *TokenListOptions) Run(ctx context.Context, _ ...string) error {
+	tokens, err := o.vault.Tokens(ctx)
+	if err != nil {
+		return &TokenError{err}
+	}
+
+	if len(tokens) == 0 {
+		o.Warnf("No tokens found.\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tLABELS\tREAD-ONLY\tEXPIRES")
+
+	now := time.Now()
+
+	for _, t := range tokens {
+		expires := t.ExpiresAt.Local().Format(time.DateTime)
+		if !now.Before(t.ExpiresAt) {
+			expires += " (expired)"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%t\t%s\n", t.ID, strings.Join(t.Scope, ","), t.ReadOnly, expires)
+	}
+
+	fmt.Fprintln(tw) // add padding
+
+	return nil
+}
+
+// NewCmdTokenList creates the token list cobra command.
+func NewCmdTokenList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewTokenListOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List issued access tokens",
+		Long:    "List issued access tokens, including expired ones.",
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+}
+
+// TokenRevokeOptions holds data required to run the command.
+type TokenRevokeOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &TokenRevokeOptions{}
+
+// NewTokenRevokeOptions initializes the options struct.
+func NewTokenRevokeOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *TokenRevokeOptions {
+	return &TokenRevokeOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*TokenRevokeOptions) Complete() error { return nil }
+
+func (*TokenRevokeOptions) Validate() error { return nil }
+
+func (o *TokenRevokeOptions) Run(ctx context.Context, ids ...string) error {
+	n, err := o.vault.RevokeTokens(ctx, ids...)
+	if err != nil {
+		return &TokenError{err}
+	}
+
+	if int(n) != len(ids) {
+		o.Warnf("Revoked %d of %d tokens, the rest were not found.\n", n, len(ids))
+		return nil
+	}
+
+	o.Infof("Revoked %d tokens.\n", n)
+
+	return nil
+}
+
+// NewCmdTokenRevoke creates the token revoke cobra command.
+func NewCmdTokenRevoke(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewTokenRevokeOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "revoke ID...",
+		Short: "Revoke access tokens",
+		Long:  "Revoke access tokens by their ids, as listed by 'vlt token list'.",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
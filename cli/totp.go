@@ -0,0 +1,534 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/aegis"
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/clipboard"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/input"
+	"github.com/ladzaretti/vlt-cli/qrcode"
+	"github.com/ladzaretti/vlt-cli/totp"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+
+	"github.com/spf13/cobra"
+)
+
+type TOTPError struct {
+	Err error
+}
+
+func (e *TOTPError) Error() string { return "totp: " + e.Err.Error() }
+
+func (e *TOTPError) Unwrap() error { return e.Err }
+
+// TOTPOptions holds data required to run the command.
+type TOTPOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	copy bool // copy controls whether to copy the code to the clipboard instead.
+}
+
+var _ genericclioptions.CmdOptions = &TOTPOptions{}
+
+// NewTOTPOptions initializes the options struct.
+func NewTOTPOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *TOTPOptions {
+	return &TOTPOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*TOTPOptions) Complete() error { return nil }
+
+func (*TOTPOptions) Validate() error { return nil }
+
+func (o *TOTPOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &TOTPError{retErr}
+			return
+		}
+	}()
+
+	id, err := o.resolveTOTP(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	key, err := o.vault.TOTPKey(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	code, remaining := key.Code(now), key.Remaining(now)
+
+	if o.copy {
+		o.Debugf("copying code to clipboard\n")
+
+		if err := clipboard.Copy(code); err != nil {
+			return err
+		}
+
+		o.Warnf("Copied, valid for %ds.\n", int(remaining/time.Second))
+
+		return nil
+	}
+
+	o.Infof("%s\n", code)
+	o.Warnf("Valid for %ds.\n", int(remaining/time.Second))
+
+	return nil
+}
+
+// resolveTOTP returns the id of the TOTP secret given by its id,
+// or else by its exact name.
+func (o *VaultOptions) resolveTOTP(ctx context.Context, arg string) (int, error) {
+	if id, err := strconv.Atoi(arg); err == nil && id > 0 {
+		return id, nil
+	}
+
+	secrets, err := o.vault.FilterSecretsBy(ctx, vaultdb.Filters{Name: arg, Kind: totp.Kind})
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []int
+
+	for id, s := range secrets {
+		if s.Name == arg {
+			ids = append(ids, id)
+		}
+	}
+
+	switch {
+	case len(ids) == 0:
+		return 0, fmt.Errorf("%q: %w", arg, vaulterrors.ErrSearchNoMatch)
+	case len(ids) > 1:
+		return 0, fmt.Errorf("%q: %w", arg, vaulterrors.ErrAmbiguousSecretMatch)
+	}
+
+	return ids[0], nil
+}
+
+// NewCmdTOTP creates the totp cobra command.
+func NewCmdTOTP(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewTOTPOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "totp ID|NAME",
+		Short: "Generate the current code of a TOTP secret (subcommands available)",
+		Long: `Generate the current time-based one-time password (RFC 6238) of a TOTP secret,
+given by its id or exact name.
+
+TOTP secrets hold the seed of a two-factor authentication setup, either as the
+base32 key shown by most sites, or as the otpauth:// uri encoded in their QR
+codes, and are added using 'vlt totp add'.
+
+The code is printed to stdout, and the seconds it remains valid for to stderr.`,
+		Example: `  # Print the current code of the TOTP secret named github
+  vlt totp github
+
+  # Copy the current code of the TOTP secret with id 12 to the clipboard
+  vlt totp 12 -c
+
+  # Add a TOTP secret, prompting for its seed
+  vlt totp add --name github
+
+  # Export all TOTP secrets as QR codes, to scan them using an authenticator app
+  vlt totp export --qr`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.copy, "copy-clipboard", "c", false, "copy the code to the clipboard")
+
+	cmd.AddCommand(NewCmdTOTPAdd(defaults))
+	cmd.AddCommand(NewCmdTOTPExport(defaults))
+
+	return cmd
+}
+
+// TOTPAddOptions holds data required to run the command.
+type TOTPAddOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	name     string
+	labels   []string
+	username string
+	url      string
+}
+
+var _ genericclioptions.CmdOptions = &TOTPAddOptions{}
+
+// NewTOTPAddOptions initializes the options struct.
+func NewTOTPAddOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *TOTPAddOptions {
+	return &TOTPAddOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*TOTPAddOptions) Complete() error { return nil }
+
+func (o *TOTPAddOptions) Validate() error {
+	if len(o.name) == 0 {
+		return &TOTPError{vaulterrors.ErrEmptyName}
+	}
+
+	return nil
+}
+
+func (o *TOTPAddOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &TOTPError{retErr}
+			return
+		}
+	}()
+
+	seed, err := o.readSeed()
+	if err != nil {
+		return err
+	}
+
+	key, err := totp.Parse(seed)
+	if err != nil {
+		return err
+	}
+
+	// otpauth:// uris name the account of the seed.
+	meta := vaultdb.SecretMeta{Username: o.username, URL: o.url}
+	if len(meta.Username) == 0 {
+		meta.Username = key.Account
+	}
+
+	n, err := o.vault.InsertTOTPSecret(ctx, o.name, seed, o.labels, meta)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNoSecretInserted
+	}
+
+	o.Infof("Added TOTP secret %q\n", o.name)
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		o.Warnf("Post-write hook failed: %v", err)
+	}
+
+	return nil
+}
+
+// readSeed reads the seed from stdin if piped or redirected,
+// or else prompts for it without echo.
+func (o *TOTPAddOptions) readSeed() (string, error) {
+	if o.NonInteractive {
+		return input.ReadTrim(o.In)
+	}
+
+	return input.PromptReadSecure(o.Out, int(o.In.Fd()), "Enter TOTP seed (base32 key or otpauth:// uri): ")
+}
+
+// NewCmdTOTPAdd creates the totp add cobra command.
+func NewCmdTOTPAdd(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewTOTPAddOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a TOTP secret to the vault",
+		Long: `Add a TOTP secret to the vault.
+
+The seed, either a base32 key or an otpauth:// uri, is prompted for without
+echo. If input is piped or redirected, the seed is read from it instead.
+Seeds are validated when added.`,
+		Example: `  # Add a TOTP secret, prompting for its seed
+  vlt totp add --name github --label work
+
+  # Add a TOTP secret read from an otpauth:// uri
+  vlt totp add --name github < github.uri`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.name, "name", "", "", "the secret name (e.g., github)")
+	cmd.Flags().StringSliceVarP(&o.labels, "label", "", nil, "optional label to associate with the secret (comma-separated or repeated)")
+	cmd.Flags().StringVarP(&o.username, "username", "", "", "the account username (default: the account of an otpauth:// uri)")
+	cmd.Flags().StringVarP(&o.url, "url", "", "", "the site url")
+
+	return cmd
+}
+
+const (
+	totpExportFormatOTPAuth = "otpauth"
+	totpExportFormatAegis   = "aegis-json"
+)
+
+// TOTPExportOptions holds data required to run the command.
+type TOTPExportOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	format string
+	qr     bool // qr controls whether to print the uris as QR codes instead.
+	output string
+	stdout bool
+}
+
+var _ genericclioptions.CmdOptions = &TOTPExportOptions{}
+
+// NewTOTPExportOptions initializes the options struct.
+func NewTOTPExportOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *TOTPExportOptions {
+	return &TOTPExportOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*TOTPExportOptions) Complete() error { return nil }
+
+func (o *TOTPExportOptions) Validate() error {
+	switch o.format {
+	case totpExportFormatOTPAuth:
+	case totpExportFormatAegis:
+		if o.qr {
+			return &TOTPError{fmt.Errorf("--qr is supported by the %s format only", totpExportFormatOTPAuth)}
+		}
+
+		if len(o.output) == 0 || o.stdout {
+			return &TOTPError{fmt.Errorf("%s vaults are written to an --output path only", totpExportFormatAegis)}
+		}
+
+		if o.NonInteractive {
+			return &TOTPError{vaulterrors.ErrNonInteractiveUnsupported}
+		}
+
+		return nil
+	default:
+		return &TOTPError{fmt.Errorf("unknown format %q (formats: %s, %s)", o.format, totpExportFormatOTPAuth, totpExportFormatAegis)}
+	}
+
+	if o.qr {
+		if len(o.output) > 0 || o.stdout {
+			return &TOTPError{errors.New("QR codes are printed to the terminal, --output and --stdout are not supported")}
+		}
+
+		return nil
+	}
+
+	if len(o.output) == 0 && !o.stdout {
+		return &TOTPError{errors.New("either specify an --output path, use --stdout or --qr")}
+	}
+
+	return nil
+}
+
+func (o *TOTPExportOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &TOTPError{retErr}
+			return
+		}
+	}()
+
+	keys, err := o.exportedKeys(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("no totp secrets: %w", vaulterrors.ErrSearchNoMatch)
+	}
+
+	switch {
+	case o.format == totpExportFormatAegis:
+		return o.exportAegis(keys)
+	case o.qr:
+		return o.printQR(keys)
+	}
+
+	out, closeOut, err := o.exportOutput(o.StdioOptions, o.output, o.stdout)
+	if out == nil {
+		return err
+	}
+	defer closeOut()
+
+	for _, k := range keys {
+		if _, err := fmt.Fprintln(out, k.URI()); err != nil {
+			return err
+		}
+	}
+
+	if !o.stdout {
+		o.Infof("Exported %d TOTP secrets to %s.\n", len(keys), o.output)
+	}
+
+	return nil
+}
+
+// exportedKeys returns the keys of the TOTP secrets given by their ids or
+// exact names, or else of all TOTP secrets, ordered by id.
+//
+// Keys missing an issuer or account, i.e., added as base32 keys, are labeled
+// by the secret name and username, so that authenticator apps can tell them apart.
+func (o *TOTPExportOptions) exportedKeys(ctx context.Context, args []string) ([]totp.Key, error) {
+	secrets, err := o.vault.FilterSecretsBy(ctx, vaultdb.Filters{Kind: totp.Kind})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := slices.Sorted(maps.Keys(secrets))
+
+	if len(args) > 0 {
+		ids = ids[:0]
+
+		for _, arg := range args {
+			id, err := o.resolveTOTP(ctx, arg)
+			if err != nil {
+				return nil, err
+			}
+
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	metas, err := o.vault.SecretMetas(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]totp.Key, 0, len(ids))
+
+	for _, id := range ids {
+		key, err := o.vault.TOTPKey(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		name := secrets[id].Name
+		key.Issuer = cmp.Or(key.Issuer, name)
+		key.Account = cmp.Or(key.Account, metas[id].Username, name)
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// printQR prints the otpauth:// uri of each key as a QR code, if confirmed.
+func (o *TOTPExportOptions) printQR(keys []totp.Key) error {
+	if ok, err := o.confirmPrint(o.StdioOptions, "TOTP seeds"); !ok {
+		return err
+	}
+
+	for _, k := range keys {
+		code, err := qrcode.Encode([]byte(k.URI()))
+		if err != nil {
+			return fmt.Errorf("%s:%s: %w", k.Issuer, k.Account, err)
+		}
+
+		o.Infof("%s (%s)\n%s\n", k.Issuer, k.Account, code.Terminal())
+	}
+
+	return nil
+}
+
+// exportAegis writes the keys to an encrypted Aegis vault,
+// prompting for its password.
+func (o *TOTPExportOptions) exportAegis(keys []totp.Key) error {
+	entries := make([]aegis.Entry, 0, len(keys))
+
+	for _, k := range keys {
+		entries = append(entries, aegis.Entry{
+			Name:      k.Account,
+			Issuer:    k.Issuer,
+			Secret:    k.Secret,
+			Algorithm: k.Algorithm,
+			Digits:    k.Digits,
+			Period:    int(k.Period / time.Second),
+		})
+	}
+
+	o.Infof("Choose a password for the Aegis vault, it is required to import it.\n")
+
+	password, err := input.PromptNewPassword(o.Out, int(o.In.Fd()), masterPasswordMinLen)
+	if err != nil {
+		return err
+	}
+
+	data, err := aegis.Encrypt(password, entries)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(o.output, data, 0o600); err != nil {
+		return err
+	}
+
+	o.Infof("Exported %d TOTP secrets to %s.\n", len(keys), o.output)
+
+	return nil
+}
+
+// NewCmdTOTPExport creates the totp export cobra command.
+func NewCmdTOTPExport(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewTOTPExportOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "export [ID|NAME...]",
+		Short: "Export TOTP secrets to authenticator apps",
+		Long: `Export TOTP secrets, given by their ids or exact names, or else all of them,
+to be moved to authenticator apps.
+
+Formats:
+  otpauth      one otpauth:// uri per line, as encoded in the QR codes of sites
+  aegis-json   an encrypted Aegis Authenticator vault, protected by a password
+               prompted for, written to an --output path only
+
+Using --qr, the otpauth:// uris are printed to the terminal as QR codes instead,
+to be scanned by an authenticator app. Seeds added as base32 keys are labeled
+by the secret name and username.
+
+Use --output to specify a file path or --stdout to print to standard output (unsafe).`,
+		Example: `  # Print the TOTP secret named github as a QR code
+  vlt totp export github --qr
+
+  # Export all TOTP secrets as otpauth:// uris
+  vlt totp export --output totp.txt
+
+  # Export all TOTP secrets to an Aegis vault, to import it in the app
+  vlt totp export --format aegis-json --output aegis.json`,
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.format, "format", "", totpExportFormatOTPAuth, fmt.Sprintf("export format (one of: %s, %s)", totpExportFormatOTPAuth, totpExportFormatAegis))
+	cmd.Flags().BoolVarP(&o.qr, "qr", "", false, "print the otpauth:// uris as QR codes to the terminal (unsafe)")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", "export the TOTP secrets to the specified file path")
+	cmd.Flags().BoolVarP(&o.stdout, "stdout", "", false, "print the exported TOTP secrets to standard output (unsafe)")
+
+	return cmd
+}
@@ -0,0 +1,293 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+
+	"github.com/spf13/cobra"
+)
+
+type TrashError struct {
+	Err error
+}
+
+func (e *TrashError) Error() string { return "trash: " + e.Err.Error() }
+
+func (e *TrashError) Unwrap() error { return e.Err }
+
+// TrashOptions holds data required to run the command.
+type TrashOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &TrashOptions{}
+
+// NewTrashOptions initializes the options struct.
+func NewTrashOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *TrashOptions {
+	return &TrashOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*TrashOptions) Complete() error { return nil }
+
+func (*TrashOptions) Validate() error { return nil }
+
+func (o *TrashOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &TrashError{retErr}
+			return
+		}
+	}()
+
+	trashed, err := o.vault.TrashedSecrets(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(trashed) == 0 {
+		o.Warnf("The trash is empty.\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 5, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	fmt.Fprintln(tw, "ID\tNAME\tLABELS\tDELETED")
+
+	for _, id := range slices.Sorted(maps.Keys(trashed)) {
+		t := trashed[id]
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", id, t.Name, strings.Join(t.Labels, ","), t.DeletedAt.Local().Format(time.DateTime))
+	}
+
+	return nil
+}
+
+// NewCmdTrash creates the trash cobra command.
+func NewCmdTrash(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewTrashOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "List removed secrets (subcommands available)",
+		Long: `List the secrets moved to the trash by 'vlt remove'.
+
+Trashed secrets are hidden from all other commands, but are kept in the vault,
+covered by its integrity check, until purged. Use 'vlt trash restore' to bring
+a secret back, and 'vlt trash purge' to permanently delete trashed secrets.
+
+Removals and restores are synced between devices; purges are not.`,
+		Example: `  # List the trashed secrets
+  vlt trash
+
+  # Restore the trashed secret with id 12
+  vlt trash restore 12
+
+  # Permanently delete the secrets trashed more than 30 days ago
+  vlt trash purge --older-than 30d`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.AddCommand(NewCmdTrashList(defaults))
+	cmd.AddCommand(NewCmdTrashRestore(defaults))
+	cmd.AddCommand(NewCmdTrashPurge(defaults))
+
+	return cmd
+}
+
+// NewCmdTrashList creates the trash list cobra command.
+func NewCmdTrashList(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewTrashOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the trashed secrets",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
+
+// TrashRestoreOptions holds data required to run the command.
+type TrashRestoreOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+}
+
+var _ genericclioptions.CmdOptions = &TrashRestoreOptions{}
+
+// NewTrashRestoreOptions initializes the options struct.
+func NewTrashRestoreOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *TrashRestoreOptions {
+	return &TrashRestoreOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (*TrashRestoreOptions) Complete() error { return nil }
+
+func (*TrashRestoreOptions) Validate() error { return nil }
+
+func (o *TrashRestoreOptions) Run(ctx context.Context, args ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &TrashError{retErr}
+			return
+		}
+	}()
+
+	ids := make([]int, 0, len(args))
+
+	for _, arg := range args {
+		id, err := parseSecretID(arg)
+		if err != nil {
+			return err
+		}
+
+		ids = append(ids, id)
+	}
+
+	for _, id := range ids {
+		if err := o.vault.RestoreTrashedSecret(ctx, id); err != nil {
+			return err
+		}
+
+		o.Infof("Restored secret %d.\n", id)
+	}
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		o.Warnf("Post-write hook failed: %v", err)
+	}
+
+	return nil
+}
+
+// NewCmdTrashRestore creates the trash restore cobra command.
+func NewCmdTrashRestore(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewTrashRestoreOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	return &cobra.Command{
+		Use:   "restore ID...",
+		Short: "Move secrets out of the trash",
+		Long:  `Move the given secrets out of the trash, as listed by 'vlt trash'.`,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+}
+
+// TrashPurgeOptions holds data required to run the command.
+type TrashPurgeOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	olderThan string        // olderThan is the minimum age of the purged secrets, as given.
+	age       time.Duration // age is the parsed olderThan, zero purges the whole trash.
+	assumeYes bool
+}
+
+var _ genericclioptions.CmdOptions = &TrashPurgeOptions{}
+
+// NewTrashPurgeOptions initializes the options struct.
+func NewTrashPurgeOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions) *TrashPurgeOptions {
+	return &TrashPurgeOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+	}
+}
+
+func (o *TrashPurgeOptions) Complete() error {
+	if o.olderThan == "" {
+		return nil
+	}
+
+	d, err := cmdutil.ParseDuration(o.olderThan)
+	if err != nil {
+		return &TrashError{fmt.Errorf("--older-than: %w", err)}
+	}
+
+	o.age = d
+
+	return nil
+}
+
+func (o *TrashPurgeOptions) Validate() error {
+	if o.age < 0 {
+		return &TrashError{errors.New("--older-than must not be negative")}
+	}
+
+	return nil
+}
+
+func (o *TrashPurgeOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &TrashError{retErr}
+			return
+		}
+	}()
+
+	if !o.assumeYes {
+		yes, err := confirm(o.Out, o.In, "Permanently delete the trashed secrets? (y/N): ")
+		if err != nil {
+			return err
+		}
+
+		if !yes {
+			return nil
+		}
+	}
+
+	n, err := o.vault.PurgeTrash(ctx, time.Now().Add(-o.age))
+	if err != nil {
+		return err
+	}
+
+	o.Infof("Purged %d secrets.\n", n)
+
+	if err := genericclioptions.RunHook(ctx, o.StdioOptions, o.hooks.postWrite); err != nil {
+		o.Warnf("Post-write hook failed: %v", err)
+	}
+
+	return nil
+}
+
+// NewCmdTrashPurge creates the trash purge cobra command.
+func NewCmdTrashPurge(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewTrashPurgeOptions(defaults.StdioOptions, defaults.vaultOptions)
+
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Permanently delete trashed secrets",
+		Long: `Permanently delete the secrets in the trash, or only those trashed more than
+--older-than ago. Purged secret values are overwritten before deletion.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.olderThan, "older-than", "", "", "only purge secrets trashed more than the given duration ago (e.g., 30d)")
+	cmd.Flags().BoolVarP(&o.assumeYes, "yes", "y", false, "skip confirmation prompts")
+
+	return cmd
+}
@@ -10,14 +10,13 @@ import (
 	"github.com/ladzaretti/vlt-cli/clipboard"
 	"github.com/ladzaretti/vlt-cli/genericclioptions"
 	"github.com/ladzaretti/vlt-cli/input"
-	"github.com/ladzaretti/vlt-cli/randstring"
 	"github.com/ladzaretti/vlt-cli/vaulterrors"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	ErrNoUpdateArgs    = errors.New("no update arguments provided; specify at least one of --set-name, --add-label, or --remove-label")
+	ErrNoUpdateArgs    = errors.New("no update arguments provided; specify at least one of --set-name, --add-label, --remove-label, --set-url, --set-username, or --set-notes")
 	ErrNoSecretUpdated = errors.New("no secret was updated")
 )
 
@@ -37,6 +36,11 @@ type UpdateOptions struct {
 	newName      string
 	addLabels    []string
 	removeLabels []string
+
+	// url, username and notes are the metadata to set, nil if not given.
+	url      *string
+	username *string
+	notes    *string
 }
 
 var _ genericclioptions.CmdOptions = &UpdateOptions{}
@@ -75,6 +79,10 @@ func (o *UpdateOptions) validateUpdateArgs() error {
 		args++
 	}
 
+	if o.url != nil || o.username != nil || o.notes != nil {
+		args++
+	}
+
 	if args == 0 {
 		return &UpdateError{ErrNoUpdateArgs}
 	}
@@ -107,24 +115,73 @@ func (o *UpdateOptions) Run(ctx context.Context, args ...string) (retErr error)
 		return vaulterrors.ErrSearchNoMatch
 	default:
 		o.Warnf("Expecting exactly one match, but found %d.\n\n", count)
-		printTable(o.ErrOut, matchingSecrets)
+		printTable(o.ErrOut, matchingSecrets, o.labelColors(ctx, o.ErrOut))
 
 		return vaulterrors.ErrAmbiguousSecretMatch
 	}
 
-	return o.vault.UpdateSecretMetadata(ctx, matchingSecrets[0].id, o.newName, o.removeLabels, o.addLabels)
+	id := matchingSecrets[0].id
+
+	if len(o.newName) > 0 || len(o.removeLabels) > 0 || len(o.addLabels) > 0 {
+		if err := o.vault.UpdateSecretMetadata(ctx, id, o.newName, o.removeLabels, o.addLabels); err != nil {
+			return err
+		}
+	}
+
+	if o.url == nil && o.username == nil && o.notes == nil {
+		return nil
+	}
+
+	return o.updateSecretMeta(ctx, id)
+}
+
+// updateSecretMeta sets the given url, username and notes of the secret,
+// keeping the ones not given.
+func (o *UpdateOptions) updateSecretMeta(ctx context.Context, id int) error {
+	metas, err := o.vault.SecretMetas(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	meta := metas[id]
+
+	if o.url != nil {
+		meta.URL = *o.url
+	}
+
+	if o.username != nil {
+		meta.Username = *o.username
+	}
+
+	if o.notes != nil {
+		meta.Notes = *o.notes
+	}
+
+	n, err := o.vault.UpdateSecretMeta(ctx, id, meta)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNoSecretUpdated
+	}
+
+	return nil
 }
 
 // NewCmdUpdate creates the update cobra command.
 func NewCmdUpdate(defaults *DefaultVltOptions) *cobra.Command {
 	o := NewUpdateOptions(defaults.StdioOptions, defaults.vaultOptions)
 
+	var url, username, notes string
+
 	cmd := &cobra.Command{
 		Use:   "update [glob]",
 		Short: "Update secret data or metadata (subcommands available)",
 		Long: `Update metadata for an existing secret.
 
-This command updates metadata such as the name or labels of a secret.
+This command updates metadata such as the name, labels, url, username or notes
+of a secret. An empty --set-url, --set-username or --set-notes value clears it.
 The update will proceed only if exactly one secret matches the given search criteria.
 
 To update the secret value, use the 'vlt update secret' subcommand.`,
@@ -138,8 +195,23 @@ To update the secret value, use the 'vlt update secret' subcommand.`,
   vlt update --name github --add-label dev
 
   # Remove a label from a secret
-  vlt update --id 456 --remove-label old-label`,
+  vlt update --id 456 --remove-label old-label
+
+  # Set the username and notes of a secret
+  vlt update --name github --set-username octocat --set-notes "recovery codes in the safe"`,
 		Run: func(cmd *cobra.Command, args []string) {
+			if cmd.Flags().Changed("set-url") {
+				o.url = &url
+			}
+
+			if cmd.Flags().Changed("set-username") {
+				o.username = &username
+			}
+
+			if cmd.Flags().Changed("set-notes") {
+				o.notes = &notes
+			}
+
 			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
 		},
 	}
@@ -151,6 +223,9 @@ To update the secret value, use the 'vlt update secret' subcommand.`,
 	cmd.Flags().StringVarP(&o.newName, "set-name", "", "", "new name for the secret")
 	cmd.Flags().StringSliceVarP(&o.addLabels, "add-label", "", nil, "label to add to the secret")
 	cmd.Flags().StringSliceVarP(&o.removeLabels, "remove-label", "", nil, "label to remove from the secret")
+	cmd.Flags().StringVarP(&url, "set-url", "", "", "the url metadata of the secret")
+	cmd.Flags().StringVarP(&username, "set-username", "", "", "the username metadata of the secret")
+	cmd.Flags().StringVarP(&notes, "set-notes", "", "", "the notes metadata of the secret")
 
 	cmd.AddCommand(NewCmdUpdateSecretValue(defaults))
 
@@ -231,12 +306,12 @@ func (o *UpdateSecretValueOptions) Run(ctx context.Context, args ...string) (ret
 		return &UpdateError{vaulterrors.ErrSearchNoMatch}
 	default:
 		o.Warnf("Expecting exactly one match, but found %d.\n\n", count)
-		printTable(o.ErrOut, matchingSecrets)
+		printTable(o.ErrOut, matchingSecrets, o.labelColors(ctx, o.ErrOut))
 
 		return &UpdateError{vaulterrors.ErrAmbiguousSecretMatch}
 	}
 
-	id, secret := matchingSecrets[0].id, ""
+	id, name, labels, secret := matchingSecrets[0].id, matchingSecrets[0].name, matchingSecrets[0].labels, ""
 
 	// ensure error is wrapped and output is printed if everything succeeded
 	defer func() {
@@ -246,14 +321,14 @@ func (o *UpdateSecretValueOptions) Run(ctx context.Context, args ...string) (ret
 		}
 
 		if len(secret) > 0 {
-			if err := o.outputSecret(secret); err != nil {
+			if err := o.outputSecret(name, secret); err != nil {
 				retErr = &UpdateError{err}
 				return
 			}
 		}
 	}()
 
-	s, err := o.readSecretNonInteractive()
+	s, err := o.readSecretNonInteractive(labels)
 	if err != nil {
 		return fmt.Errorf("read secret non-interactive: %w", err)
 	}
@@ -274,6 +349,10 @@ func (o *UpdateSecretValueOptions) Run(ctx context.Context, args ...string) (ret
 		return vaulterrors.ErrEmptySecret
 	}
 
+	if err := o.passwordPolicy.check(o.StdioOptions, secret, labels); err != nil {
+		return err
+	}
+
 	if err := o.UpdateSecretValue(ctx, id, secret); err != nil {
 		return err
 	}
@@ -285,9 +364,9 @@ func (o *UpdateSecretValueOptions) Run(ctx context.Context, args ...string) (ret
 	return nil
 }
 
-func (o *UpdateSecretValueOptions) readSecretNonInteractive() (string, error) {
+func (o *UpdateSecretValueOptions) readSecretNonInteractive(labels []string) (string, error) {
 	if o.generate {
-		return randstring.NewWithPolicy(randstring.DefaultPasswordPolicy)
+		return o.passwordPolicy.generate(labels)
 	}
 
 	if o.paste {
@@ -303,8 +382,12 @@ func (o *UpdateSecretValueOptions) readSecretNonInteractive() (string, error) {
 	return "", nil
 }
 
-func (o *UpdateSecretValueOptions) outputSecret(s string) error {
+func (o *UpdateSecretValueOptions) outputSecret(name, s string) error {
 	if o.output {
+		if ok, err := o.confirmPrint(o.StdioOptions, secretDescription(name)); !ok {
+			return err
+		}
+
 		o.Infof("%s", s)
 		return nil
 	}
@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaultdaemon"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+	"github.com/ladzaretti/vlt-cli/webhook"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// watchFormatJSON prints one [webhook.Payload] per line.
+	watchFormatJSON = "json"
+
+	// defaultWatchInterval is the interval the vault file is checked for changes at.
+	defaultWatchInterval = time.Second
+)
+
+type WatchError struct {
+	Err error
+}
+
+func (e *WatchError) Error() string { return "watch: " + e.Err.Error() }
+
+func (e *WatchError) Unwrap() error { return e.Err }
+
+// WatchOptions holds data required to run the command.
+type WatchOptions struct {
+	*genericclioptions.StdioOptions
+	*VaultOptions
+
+	config   *ResolvedConfig
+	output   string
+	interval time.Duration
+}
+
+var _ genericclioptions.CmdOptions = &WatchOptions{}
+
+// NewWatchOptions initializes the options struct.
+func NewWatchOptions(stdio *genericclioptions.StdioOptions, vaultOptions *VaultOptions, config *ResolvedConfig) *WatchOptions {
+	return &WatchOptions{
+		StdioOptions: stdio,
+		VaultOptions: vaultOptions,
+		config:       config,
+	}
+}
+
+func (*WatchOptions) Complete() error { return nil }
+
+func (o *WatchOptions) Validate() error {
+	if len(o.output) > 0 && o.output != watchFormatJSON {
+		return &WatchError{fmt.Errorf("unsupported output format %q (supported: %s)", o.output, watchFormatJSON)}
+	}
+
+	if o.interval <= 0 {
+		return &WatchError{errors.New("--interval must be positive")}
+	}
+
+	return nil
+}
+
+// Run prints the changes recorded in the audit log after it started.
+//
+// The vault is loaded into memory only while reading the audit log, once
+// the vault file changed, so that other commands are free to modify it.
+func (o *WatchOptions) Run(ctx context.Context, _ ...string) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			retErr = &WatchError{retErr}
+			return
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	exists, err := o.vaultExists()
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: %s", vaulterrors.ErrVaultFileNotFound, o.path)
+	}
+
+	if err := o.checkPermissions(o.StdioOptions); err != nil {
+		return err
+	}
+
+	opts, err := o.unlockOptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	fi, err := os.Stat(o.path)
+	if err != nil {
+		return err
+	}
+
+	_, afterID, err := o.changes(ctx, opts, 0)
+	if err != nil {
+		return err
+	}
+
+	o.Debugf("vlt: watching %s for changes after audit log entry %d\n", o.path, afterID)
+
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		curr, err := os.Stat(o.path)
+		if err != nil {
+			return err
+		}
+
+		if curr.ModTime().Equal(fi.ModTime()) && curr.Size() == fi.Size() {
+			continue
+		}
+
+		entries, lastID, err := o.changes(ctx, opts, afterID)
+		if errors.Is(err, vaulterrors.ErrVaultBusy) {
+			o.Debugf("vlt: %v, retrying\n", err)
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		fi, afterID = curr, lastID
+
+		for _, e := range entries {
+			if err := o.print(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// unlockOptions returns the options the vault is opened with on every change,
+// unlocking it using the access token, the session key, the key cached in the
+// Keychain or the password, in this order.
+func (o *WatchOptions) unlockOptions(ctx context.Context) ([]vault.Option, error) {
+	opts := []vault.Option{vault.WithReadOnly()}
+
+	if o.acceptChanges {
+		opts = append(opts, vault.WithAcceptChanges())
+	}
+
+	if len(o.token) > 0 {
+		return append(opts, vault.WithToken(o.token)), nil
+	}
+
+	sessionClient, err := vaultdaemon.NewSessionClient()
+	if err != nil {
+		o.Debugf("vlt: daemon unavailable: %v\n", err)
+	}
+	defer func() { _ = sessionClient.Close() }() //nolint:wsl
+
+	// nil-safe: sessionClient methods handle nil receivers safely.
+	key, nonce, err := sessionClient.GetSessionKey(ctx, o.path)
+	if err != nil {
+		o.Debugf("vlt: no session found, falling back to password: %v\n", err)
+	}
+
+	if key == nil || nonce == nil {
+		key, nonce = o.keychainKey(ctx, o.StdioOptions)
+	}
+
+	if key == nil || nonce == nil {
+		password, err := o.login(ctx, o.StdioOptions, sessionClient, time.Duration(o.config.SessionDuration))
+		if err != nil {
+			return nil, err
+		}
+
+		if key, nonce, err = vault.Login(ctx, o.path, password); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(opts, vault.WithSessionKey(key, nonce)), nil
+}
+
+// changes opens the vault and returns the changes recorded in the audit log
+// after the entry with the given id, along with the id of the last entry.
+func (o *WatchOptions) changes(ctx context.Context, opts []vault.Option, afterID int) (_ []vaultdb.AuditEntry, lastID int, retErr error) {
+	if err := o.open(ctx, o.StdioOptions, opts...); err != nil {
+		return nil, 0, err
+	}
+	defer func() { //nolint:wsl
+		retErr = errors.Join(retErr, o.closeVault(ctx), o.Close())
+	}()
+
+	var entries []vaultdb.AuditEntry
+
+	lastID = afterID
+
+	for entry, err := range o.vault.AuditLog(ctx, vaultdb.AuditFilters{AfterID: afterID}) {
+		if err != nil {
+			return nil, 0, err
+		}
+
+		lastID = entry.ID
+
+		if _, ok := webhook.EventOf(entry.Operation); ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, lastID, nil
+}
+
+func (o *WatchOptions) print(entry vaultdb.AuditEntry) error {
+	event, _ := webhook.EventOf(entry.Operation)
+
+	if o.output == watchFormatJSON {
+		return json.NewEncoder(o.Out).Encode(webhook.Payload{
+			Event:      event,
+			Vault:      o.path,
+			Time:       entry.CreatedAt,
+			AuditID:    entry.ID,
+			SecretID:   entry.SecretID,
+			SecretName: entry.SecretName,
+			Actor:      entry.Actor,
+		})
+	}
+
+	by := ""
+	if len(entry.Actor) > 0 {
+		by = " (by " + entry.Actor + ")"
+	}
+
+	_, err := fmt.Fprintf(o.Out, "%s  %-6s  %d  %s%s\n", entry.CreatedAt.Local().Format(time.DateTime), event, entry.SecretID, entry.SecretName, by)
+
+	return err
+}
+
+// NewCmdWatch creates the watch cobra command.
+func NewCmdWatch(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewWatchOptions(defaults.StdioOptions, defaults.vaultOptions, defaults.configOptions.resolved)
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Stream changes to secrets as they are made",
+		Long: `Stream the secrets added, updated, rotated and deleted after the command
+started, as recorded in the audit log, until interrupted.
+
+Intended for tooling reacting to changes, e.g., status bars or sync daemons,
+without polling the vault. Secret values are never printed.
+
+The vault file is checked for changes every --interval, and read without
+being kept open, so other commands are free to modify the vault meanwhile.
+
+With -o json, every change is printed as a JSON object on its own line,
+in the format of webhook payloads. Served vaults stream changes at the
+'/v1/watch' endpoint of 'vlt serve', as server-sent events.`,
+		Example: `  # Print changes as they are made
+  vlt watch
+
+  # Refresh a status bar on every change
+  vlt watch -o json | while read -r event; do pkill -RTMIN+8 waybar; done`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", "output format (json)")
+	cmd.Flags().DurationVarP(&o.interval, "interval", "", defaultWatchInterval, "interval the vault file is checked for changes at")
+
+	return cmd
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/webhook"
+)
+
+// newWebhookEndpoints returns the webhook endpoints of the given config,
+// reading signing keys from the environment where configured.
+func newWebhookEndpoints(config *ResolvedConfig) ([]webhook.Endpoint, error) {
+	endpoints := make([]webhook.Endpoint, 0, len(config.Webhooks))
+
+	for _, name := range slices.Sorted(maps.Keys(config.Webhooks)) {
+		w := config.Webhooks[name]
+
+		key := w.Secret
+		if len(w.SecretEnv) > 0 {
+			key = os.Getenv(w.SecretEnv)
+			if len(key) == 0 {
+				return nil, fmt.Errorf("webhook %s: signing key environment variable %s is not set", name, w.SecretEnv)
+			}
+		}
+
+		events := make([]webhook.Event, 0, len(w.Events))
+		for _, e := range w.Events {
+			events = append(events, webhook.Event(e))
+		}
+
+		endpoints = append(endpoints, webhook.Endpoint{
+			Name:   name,
+			URL:    w.URL,
+			Events: events,
+			Key:    []byte(key),
+		})
+	}
+
+	return endpoints, nil
+}
+
+// webhookSink notifies webhooks of audited vault events.
+// Delivery errors are reported as warnings, as the
+// operations they notify of have already been committed.
+type webhookSink struct {
+	notifier *webhook.Notifier
+	io       *genericclioptions.StdioOptions
+}
+
+var _ vault.AuditSink = webhookSink{}
+
+func (s webhookSink) Write(vaultPath string, entry vaultdb.AuditEntry) error {
+	if err := s.notifier.Write(vaultPath, entry); err != nil {
+		s.io.Warnf("vlt: %v\n", err)
+	}
+
+	return nil
+}
+
+// notifyUnlock notifies webhooks that the given vault was unlocked.
+func (s webhookSink) notifyUnlock(ctx context.Context, v *vault.Vault) {
+	if err := s.notifier.Notify(ctx, webhook.Payload{Event: webhook.EventUnlock, Vault: v.Path, Actor: v.Actor()}); err != nil {
+		s.io.Warnf("vlt: %v\n", err)
+	}
+}
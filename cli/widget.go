@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/ladzaretti/vlt-cli/clierror"
+	"github.com/ladzaretti/vlt-cli/genericclioptions"
+
+	"github.com/spf13/cobra"
+)
+
+// widgetPicker lists the secrets and prints the id of the one picked.
+const widgetPicker = `vlt find | fzf --height=40% --reverse --header-lines=1 --nth=2.. --prompt='vlt> ' | awk '{print $1}'`
+
+// widgetScripts maps the supported shells to their widget code.
+var widgetScripts = map[string]string{
+	"zsh": `# vlt-widget inserts the value of a secret picked using fzf at the cursor,
+# keeping the command line holding it out of the history.
+vlt-widget() {
+  local id value
+  id="$(` + widgetPicker + `)"
+  if [[ -n "$id" ]] && value="$(vlt show --id "$id" --output)"; then
+    LBUFFER+="$value"
+    _vlt_widget_values+=("$value")
+  fi
+  zle reset-prompt
+}
+
+_vlt_widget_addhistory() {
+  local value
+  for value in "${_vlt_widget_values[@]}"; do
+    if [[ "$1" == *"$value"* ]]; then
+      _vlt_widget_values=()
+      return 1
+    fi
+  done
+  _vlt_widget_values=()
+}
+
+typeset -ga _vlt_widget_values
+autoload -Uz add-zsh-hook
+add-zsh-hook zshaddhistory _vlt_widget_addhistory
+zle -N vlt-widget
+bindkey '{{.Key}}' vlt-widget
+`,
+	"bash": `# __vlt_widget inserts the value of a secret picked using fzf at the cursor,
+# prefixing the command line with a space, which keeps it out of the history
+# given HISTCONTROL=ignorespace, added below if missing.
+__vlt_widget() {
+  local id value
+  id="$(` + widgetPicker + `)"
+  [[ -n "$id" ]] || return
+  value="$(vlt show --id "$id" --output)" || return
+  READLINE_LINE="${READLINE_LINE:0:READLINE_POINT}${value}${READLINE_LINE:READLINE_POINT}"
+  READLINE_POINT=$((READLINE_POINT + ${#value}))
+  if [[ "$READLINE_LINE" != " "* ]]; then
+    READLINE_LINE=" $READLINE_LINE"
+    READLINE_POINT=$((READLINE_POINT + 1))
+  fi
+}
+
+case ":$HISTCONTROL:" in
+  *:ignorespace:* | *:ignoreboth:*) ;;
+  *) HISTCONTROL="${HISTCONTROL:+$HISTCONTROL:}ignorespace" ;;
+esac
+
+bind -x '"{{.Key}}": __vlt_widget'
+`,
+	"fish": `# vlt-widget inserts the value of a secret picked using fzf at the cursor,
+# prefixing the command line with a space, which keeps it out of the history.
+function vlt-widget
+    set -l id (` + widgetPicker + `)
+    if test -n "$id"; and set -l value (vlt show --id $id --output | string collect)
+        commandline -i -- $value
+        if not string match -q -- ' *' (commandline | string collect)
+            set -l cursor (commandline -C)
+            commandline -r -- " "(commandline | string collect)
+            commandline -C (math $cursor + 1)
+        end
+    end
+    commandline -f repaint
+end
+
+bind {{.Key}} vlt-widget
+`,
+}
+
+// widgetDefaultKeys maps the supported shells to the default key binding
+// of the widget, Ctrl-P, in their own syntax.
+var widgetDefaultKeys = map[string]string{
+	"zsh":  "^P",
+	"bash": `\C-p`,
+	"fish": `\cp`,
+}
+
+type WidgetError struct {
+	Err error
+}
+
+func (e *WidgetError) Error() string { return "widget: " + e.Err.Error() }
+
+func (e *WidgetError) Unwrap() error { return e.Err }
+
+// WidgetOptions holds data required to run the command.
+type WidgetOptions struct {
+	*genericclioptions.StdioOptions
+
+	key string // key is the key binding of the widget, in the syntax of the shell.
+}
+
+var _ genericclioptions.CmdOptions = &WidgetOptions{}
+
+// NewWidgetOptions initializes the options struct.
+func NewWidgetOptions(stdio *genericclioptions.StdioOptions) *WidgetOptions {
+	return &WidgetOptions{
+		StdioOptions: stdio,
+	}
+}
+
+func (*WidgetOptions) Complete() error { return nil }
+
+func (*WidgetOptions) Validate() error { return nil }
+
+func (o *WidgetOptions) Run(_ context.Context, args ...string) error {
+	shell := args[0]
+
+	script, ok := widgetScripts[shell]
+	if !ok {
+		return &WidgetError{fmt.Errorf("unsupported shell %q (supported: %s)", shell, strings.Join(widgetShells(), ", "))}
+	}
+
+	key := o.key
+	if len(key) == 0 {
+		key = widgetDefaultKeys[shell]
+	}
+
+	t, err := template.New(shell).Parse(script)
+	if err != nil {
+		return &WidgetError{err}
+	}
+
+	if err := t.Execute(o.Out, struct{ Key string }{key}); err != nil {
+		return &WidgetError{err}
+	}
+
+	return nil
+}
+
+func widgetShells() []string {
+	shells := make([]string, 0, len(widgetScripts))
+	for shell := range widgetScripts {
+		shells = append(shells, shell)
+	}
+
+	slices.Sort(shells)
+
+	return shells
+}
+
+// NewCmdWidget creates the widget cobra command.
+func NewCmdWidget(defaults *DefaultVltOptions) *cobra.Command {
+	o := NewWidgetOptions(defaults.StdioOptions)
+
+	cmd := &cobra.Command{
+		Use:   "widget SHELL",
+		Short: "Print a shell key binding inserting a secret picked using fzf at the cursor",
+		Long: `Print shell code binding a key, Ctrl-P by default, to a widget picking a
+secret using fzf and inserting its value at the cursor, without it entering
+the shell history.
+
+The secrets are listed using 'vlt find' and their values read using
+'vlt show --output', both prompting for the password if not logged in.
+
+Supported shells: zsh, bash and fish. In bash and fish, the command line is
+kept out of the history by prefixing it with a space, in bash only given
+HISTCONTROL=ignorespace, which the widget adds if missing.`,
+		Example: `  # ~/.zshrc
+  eval "$(vlt widget zsh)"
+
+  # ~/.bashrc, binding Alt-P instead
+  eval "$(vlt widget bash --key '\ep')"
+
+  # ~/.config/fish/config.fish
+  vlt widget fish | source`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: widgetShells(),
+		Run: func(cmd *cobra.Command, args []string) {
+			clierror.Check(genericclioptions.ExecuteCommand(cmd.Context(), o, args...))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.key, "key", "k", "", "key binding of the widget, in the syntax of the shell (default: Ctrl-P)")
+
+	return cmd
+}
@@ -1,6 +1,7 @@
 package clierror
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -25,6 +26,9 @@ var (
 
 	// debugMode enables always printing raw error values.
 	debugMode bool
+
+	// jsonMode enables printing errors as JSON objects.
+	jsonMode bool
 )
 
 // BehaviorOnFatal allows overriding the default behavior when a fatal
@@ -53,6 +57,16 @@ func DebugMode(enabled bool) {
 	debugMode = enabled
 }
 
+// JSONMode sets whether errors are printed as JSON objects,
+// for commands run with '-o json':
+//
+//	{"error": {"code": "SECRET_NOT_FOUND", "message": "show: no match found"}}
+//
+// The code is one of [vaulterrors.Code], the message is not meant to be parsed.
+func JSONMode(enabled bool) {
+	jsonMode = enabled
+}
+
 // fatal prints the message provided and then exits with the given code.
 func fatal(msg string, code int) {
 	if len(msg) > 0 {
@@ -80,6 +94,24 @@ func debugPrint(err error) {
 // status code 1.
 var ErrExit = errors.New("exit")
 
+// ExitCodeError may be passed to Check to instruct it to output nothing but exit
+// with the given status code, e.g., that of a plugin run as a sub-command.
+// If Err is set, it is printed as any other error.
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitCodeError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func (e *ExitCodeError) Unwrap() error { return e.Err }
+
 // Check prints a user friendly error and exits with a non-zero
 // exit code. Unrecognized errors will be printed with an "error: " prefix.
 func Check(err error) {
@@ -94,9 +126,26 @@ func check(err error, handleErr func(string, int)) {
 
 	debugPrint(err)
 
+	var exitCodeErr *ExitCodeError
+
+	if jsonMode && !errors.Is(err, ErrExit) && (!errors.As(err, &exitCodeErr) || exitCodeErr.Err != nil) {
+		code := DefaultErrorExitCode
+		if exitCodeErr != nil {
+			code, err = exitCodeErr.Code, exitCodeErr.Err
+		}
+
+		handleErr(jsonMessage(err), code)
+
+		return
+	}
+
 	switch {
 	case errors.Is(err, ErrExit):
 		handleErr("", DefaultErrorExitCode)
+	case errors.As(err, &exitCodeErr) && exitCodeErr.Err != nil:
+		handleErr("vlt: "+exitCodeErr.Err.Error(), exitCodeErr.Code)
+	case errors.As(err, &exitCodeErr):
+		handleErr("", exitCodeErr.Code)
 	case errors.Is(err, vaulterrors.ErrVaultFileExists):
 		handleErr("vlt: vault file already exists\nConsider deleting the file first before running 'create' to create a new vault at the specified path.", DefaultErrorExitCode)
 	case errors.Is(err, vaulterrors.ErrVaultFileNotFound):
@@ -105,6 +154,22 @@ func check(err error, handleErr func(string, int)) {
 		handleErr("vlt: incorrect password\nPlease check your password and try again.", DefaultErrorExitCode)
 	case errors.Is(err, vaulterrors.ErrNonInteractiveUnsupported):
 		handleErr("vlt: this command supports interactive input only.", DefaultErrorExitCode)
+	case errors.Is(err, vaulterrors.ErrVaultModified):
+		handleErr("vlt: "+err.Error()+"\nIf the changes are expected, rerun with --accept-changes to trust the current vault contents.", DefaultErrorExitCode)
+	case errors.Is(err, vaulterrors.ErrReadOnly):
+		handleErr("vlt: "+err.Error()+"\nMutating commands are disabled by --read-only or the 'vault.read_only' config option.", DefaultErrorExitCode)
+	case errors.Is(err, vaulterrors.ErrUnsafePermissions):
+		handleErr("vlt: "+err.Error()+"\nRun 'vlt doctor --fix' to restrict access to the vault file and its directory.", DefaultErrorExitCode)
+	case errors.Is(err, vaulterrors.ErrInvalidToken), errors.Is(err, vaulterrors.ErrTokenExpired):
+		handleErr("vlt: "+err.Error()+"\nCheck the VLT_TOKEN environment variable, or issue a new token using 'vlt token create'.", DefaultErrorExitCode)
+	case errors.Is(err, vaulterrors.ErrTokenScope):
+		handleErr("vlt: "+err.Error()+"\nThe access token only grants access to secrets with a label matching its scope.", DefaultErrorExitCode)
+	case errors.Is(err, vaulterrors.ErrSyncNotInitialized):
+		handleErr("vlt: "+err.Error()+"\nRun 'vlt sync init' to start recording changes for sync.", DefaultErrorExitCode)
+	case errors.Is(err, vaulterrors.ErrSyncDeviceConflict):
+		handleErr("vlt: "+err.Error()+"\nA copied vault must run 'vlt sync init --new-device' before its first push.", DefaultErrorExitCode)
+	case errors.Is(err, vaulterrors.ErrSyncKeyMismatch):
+		handleErr("vlt: "+err.Error()+"\nOnly copies of the same synced vault can exchange oplogs.", DefaultErrorExitCode)
 	case errors.Is(err, vaultdaemon.ErrSocketUnavailable):
 		handleErr("vlt: vault daemon is not running\nStart `vltd` to enable session support", DefaultErrorExitCode)
 	default:
@@ -120,6 +185,39 @@ func check(err error, handleErr func(string, int)) {
 	}
 }
 
+// errorObject is the JSON representation of an error, see [JSONMode].
+type errorObject struct {
+	Error struct {
+		Code    vaulterrors.Code `json:"code"`
+		Message string           `json:"message"`
+	} `json:"error"`
+}
+
+// jsonMessage returns the JSON representation of err.
+func jsonMessage(err error) string {
+	var obj errorObject
+
+	obj.Error.Code = CodeOf(err)
+	obj.Error.Message = strings.TrimPrefix(err.Error(), "vlt: ")
+
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return "vlt: " + obj.Error.Message
+	}
+
+	return string(b)
+}
+
+// CodeOf returns the code of err, see [vaulterrors.CodeOf].
+// It also covers errors of the vlt packages vaulterrors cannot depend on.
+func CodeOf(err error) vaulterrors.Code {
+	if errors.Is(err, vaultdaemon.ErrSocketUnavailable) {
+		return vaulterrors.CodeDaemonUnavailable
+	}
+
+	return vaulterrors.CodeOf(err)
+}
+
 func StandardErrorMessage(_ error) (string, bool) {
 	return "", false
 }
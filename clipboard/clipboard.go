@@ -1,12 +1,22 @@
 // Package clipboard provides utilities to interact with the system clipboard
-// using external commands, with `xsel` as the default.
+// using external commands, detected per platform by default: `pbcopy` on macOS,
+// `wl-copy` on Wayland, and `xsel`, or else `xclip`, on X11. On Windows, values
+// are copied using the clipboard API.
 //
 // It supports copying to and pasting from the clipboard,
 // and allows customization of the commands used.
+//
+// Copied values are marked with [SensitiveHints] where possible,
+// so clipboard managers do not record them in their history.
 package clipboard
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
 	"os/exec"
+	"runtime"
+	"slices"
 	"strings"
 )
 
@@ -15,6 +25,20 @@ const (
 	defaultPaste = "xsel -ob"
 )
 
+// platformCommands are the copy and paste commands of each platform,
+// in order of preference.
+var platformCommands = map[string][][2]string{
+	"darwin":  {{"pbcopy", "pbpaste"}},
+	"windows": {{defaultCopy, "powershell -NoProfile -Command Get-Clipboard"}},
+}
+
+// waylandCommands and x11Commands are the copy and paste commands
+// of other platforms, depending on the display server.
+var (
+	waylandCommands = [][2]string{{"wl-copy", "wl-paste -n"}}
+	x11Commands     = [][2]string{{defaultCopy, defaultPaste}, {"xclip -selection clipboard", "xclip -selection clipboard -o"}}
+)
+
 // ConfigurationError indicates that a clipboard command is not available
 // or misconfigured on the host system.
 type ConfigurationError struct {
@@ -32,6 +56,10 @@ func (ce *ConfigurationError) Unwrap() error {
 
 var clipboard = New()
 
+// nativeCopy copies using the platform clipboard API, setting the given hints.
+// It is nil on platforms where the copy command is always used.
+var nativeCopy func(s string, hints []Hint) error
+
 // SetDefault replaces the global clipboard instance.
 // Intended custom configurations or testing.
 func SetDefault(c *Clipboard) {
@@ -54,6 +82,23 @@ func Paste() (string, error) {
 	return clipboard.Paste()
 }
 
+// Clear empties the system clipboard using the default command.
+func Clear() error {
+	return clipboard.Clear()
+}
+
+// ClearIf empties the system clipboard using the default command,
+// if it still holds the value of the given digest, see [Clipboard.ClearIf].
+func ClearIf(digest string) (bool, error) {
+	return clipboard.ClearIf(digest)
+}
+
+// Check reports whether the default clipboard is usable,
+// see [Clipboard.Check].
+func Check() error {
+	return clipboard.Check()
+}
+
 type cmd struct {
 	cmd  string
 	args []string
@@ -72,19 +117,32 @@ func newCmd(str string) cmd {
 	}
 }
 
+// Backend copies and pastes in place of the copy and paste commands,
+// e.g., a clipboard plugin.
+type Backend interface {
+	Copy(s string, hints []Hint) error
+	Paste() (string, error)
+}
+
 type Clipboard struct {
-	copy  cmd
-	paste cmd
+	copy    cmd
+	paste   cmd
+	native  bool    // native copies using [nativeCopy] instead of the copy command.
+	backend Backend // backend is used instead of the commands, if set.
 }
 
 type Opt func(*Clipboard)
 
 // New returns a new [Clipboard] instance.
-// By default, it uses xsel for both copy and paste.
+// By default, it uses the first installed commands of the platform,
+// see [DefaultCommands], or the platform clipboard API for copy where available.
 func New(opts ...Opt) *Clipboard {
+	copyCmd, pasteCmd := DefaultCommands()
+
 	c := &Clipboard{
-		copy:  newCmd(defaultCopy),
-		paste: newCmd(defaultPaste),
+		copy:   newCmd(copyCmd),
+		paste:  newCmd(pasteCmd),
+		native: nativeCopy != nil,
 	}
 
 	for _, opt := range opts {
@@ -94,10 +152,32 @@ func New(opts ...Opt) *Clipboard {
 	return c
 }
 
+// DefaultCommands returns the default copy and paste commands of the platform:
+// the first installed ones, Wayland commands taking precedence if a Wayland
+// display is set, falling back to xsel.
+func DefaultCommands() (copyCmd string, pasteCmd string) {
+	candidates, ok := platformCommands[runtime.GOOS]
+	if !ok {
+		candidates = x11Commands
+		if len(os.Getenv("WAYLAND_DISPLAY")) > 0 {
+			candidates = append(slices.Clone(waylandCommands), x11Commands...)
+		}
+	}
+
+	for _, c := range candidates {
+		if _, err := exec.LookPath(newCmd(c[0]).cmd); err == nil {
+			return c[0], c[1]
+		}
+	}
+
+	return candidates[0][0], candidates[0][1]
+}
+
 // WithCopyCmd sets a custom copy command.
 func WithCopyCmd(copyCmd string) Opt {
 	return func(c *Clipboard) {
 		c.copy = newCmd(copyCmd)
+		c.native = false
 	}
 }
 
@@ -108,14 +188,54 @@ func WithPasteCmd(pasteCmd string) Opt {
 	}
 }
 
+// WithBackend sets a backend used instead of the copy and paste commands.
+func WithBackend(b Backend) Opt {
+	return func(c *Clipboard) {
+		c.backend = b
+		c.native = false
+	}
+}
+
+// Check returns a [ConfigurationError] if the copy or paste command is not
+// found. The platform API and backends are assumed to be usable.
+func (c *Clipboard) Check() error {
+	if c.backend != nil {
+		return nil
+	}
+
+	if !c.native {
+		if _, err := exec.LookPath(c.copy.cmd); err != nil {
+			return &ConfigurationError{"copy-clipboard", err}
+		}
+	}
+
+	if _, err := exec.LookPath(c.paste.cmd); err != nil {
+		return &ConfigurationError{"paste-clipboard", err}
+	}
+
+	return nil
+}
+
 // Copy writes the provided string to the clipboard.
+//
+// The copy command receives [SensitiveHints] via the [HintsEnv]
+// environment variable, the platform API and backends set them directly.
 func (c *Clipboard) Copy(s string) error {
+	if c.backend != nil {
+		return c.backend.Copy(s, SensitiveHints)
+	}
+
+	if c.native {
+		return nativeCopy(s, SensitiveHints)
+	}
+
 	if _, err := exec.LookPath(c.copy.cmd); err != nil {
 		return &ConfigurationError{"copy-clipboard", err}
 	}
 
 	//nolint:gosec // G204: safe, user config on local CLI tool
 	cmd := exec.Command(c.copy.cmd, c.copy.args...)
+	cmd.Env = append(os.Environ(), hintsEnv(SensitiveHints))
 
 	in, err := cmd.StdinPipe()
 	if err != nil {
@@ -139,6 +259,10 @@ func (c *Clipboard) Copy(s string) error {
 
 // Paste reads and returns the current contents of the system clipboard.
 func (c *Clipboard) Paste() (string, error) {
+	if c.backend != nil {
+		return c.backend.Paste()
+	}
+
 	if _, err := exec.LookPath(c.paste.cmd); err != nil {
 		return "", &ConfigurationError{"paste-clipboard", err}
 	}
@@ -153,3 +277,32 @@ func (c *Clipboard) Paste() (string, error) {
 
 	return string(out), err
 }
+
+// Clear empties the system clipboard, by copying an empty string.
+func (c *Clipboard) Clear() error {
+	return c.Copy("")
+}
+
+// ClearIf empties the clipboard if it still holds the value of the given
+// [Digest], so that values copied since are kept. It reports whether the
+// clipboard was cleared.
+func (c *Clipboard) ClearIf(digest string) (bool, error) {
+	s, err := c.Paste()
+	if err != nil {
+		return false, err
+	}
+
+	// paste commands may append a line break, e.g., on Windows.
+	if Digest(s) != digest && Digest(strings.TrimSuffix(strings.TrimSuffix(s, "\n"), "\r")) != digest {
+		return false, nil
+	}
+
+	return true, c.Clear()
+}
+
+// Digest returns the hex encoded SHA-256 digest of a copied value,
+// identifying it without holding it, see [Clipboard.ClearIf].
+func Digest(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
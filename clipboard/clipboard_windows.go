@@ -0,0 +1,131 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+
+	openRetries = 10
+)
+
+var (
+	user32   = syscall.NewLazyDLL("user32.dll")
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+
+	procOpenClipboard           = user32.NewProc("OpenClipboard")
+	procCloseClipboard          = user32.NewProc("CloseClipboard")
+	procEmptyClipboard          = user32.NewProc("EmptyClipboard")
+	procSetClipboardData        = user32.NewProc("SetClipboardData")
+	procRegisterClipboardFormat = user32.NewProc("RegisterClipboardFormatW")
+
+	procGlobalAlloc   = kernel32.NewProc("GlobalAlloc")
+	procGlobalFree    = kernel32.NewProc("GlobalFree")
+	procGlobalLock    = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock  = kernel32.NewProc("GlobalUnlock")
+	procRtlMoveMemory = kernel32.NewProc("RtlMoveMemory")
+)
+
+func init() {
+	nativeCopy = copyWindows
+}
+
+// copyWindows places s on the clipboard as CF_UNICODETEXT,
+// registering and setting the given hints as additional formats.
+func copyWindows(s string, hints []Hint) error {
+	text, err := syscall.UTF16FromString(s)
+	if err != nil {
+		return err
+	}
+
+	// the clipboard is owned by the calling thread.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := openClipboard(); err != nil {
+		return err
+	}
+
+	defer func() { _, _, _ = procCloseClipboard.Call() }() //nolint:wsl
+
+	if r, _, err := procEmptyClipboard.Call(); r == 0 {
+		return fmt.Errorf("empty clipboard: %w", err)
+	}
+
+	//nolint:gosec // G103: memory is copied into a global allocation owned by the clipboard.
+	if err := setClipboardData(cfUnicodeText, unsafe.Pointer(&text[0]), len(text)*2); err != nil {
+		return err
+	}
+
+	for _, h := range hints {
+		name, err := syscall.UTF16PtrFromString(h.Format)
+		if err != nil {
+			return err
+		}
+
+		//nolint:gosec // G103: syscall argument.
+		format, _, err := procRegisterClipboardFormat.Call(uintptr(unsafe.Pointer(name)))
+		if format == 0 {
+			return fmt.Errorf("register clipboard format %q: %w", h.Format, err)
+		}
+
+		// the hint formats are checked for presence, a zero DWORD is the customary value.
+		var data uint32
+
+		//nolint:gosec // G103: memory is copied into a global allocation owned by the clipboard.
+		if err := setClipboardData(format, unsafe.Pointer(&data), int(unsafe.Sizeof(data))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func openClipboard() error {
+	var err error
+
+	// another process may hold the clipboard open briefly.
+	for range openRetries {
+		var r uintptr
+
+		if r, _, err = procOpenClipboard.Call(0); r != 0 {
+			return nil
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return fmt.Errorf("open clipboard: %w", err)
+}
+
+// setClipboardData copies size bytes at src into a movable global
+// allocation and hands it to the clipboard under the given format.
+func setClipboardData(format uintptr, src unsafe.Pointer, size int) error {
+	h, _, err := procGlobalAlloc.Call(gmemMoveable, uintptr(size))
+	if h == 0 {
+		return fmt.Errorf("global alloc: %w", err)
+	}
+
+	p, _, err := procGlobalLock.Call(h)
+	if p == 0 {
+		_, _, _ = procGlobalFree.Call(h)
+		return fmt.Errorf("global lock: %w", err)
+	}
+
+	_, _, _ = procRtlMoveMemory.Call(p, uintptr(src), uintptr(size))
+	_, _, _ = procGlobalUnlock.Call(h)
+
+	if r, _, err := procSetClipboardData.Call(format, h); r == 0 {
+		_, _, _ = procGlobalFree.Call(h)
+		return errors.Join(errors.New("set clipboard data"), err)
+	}
+
+	return nil
+}
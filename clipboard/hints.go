@@ -0,0 +1,34 @@
+package clipboard
+
+import "strings"
+
+// HintsEnv is the environment variable holding the clipboard hints
+// passed to the copy command, as space-separated 'format=value' pairs.
+//
+// Copy commands able to offer additional clipboard formats
+// (e.g., a wrapper script) can advertise them alongside the text.
+const HintsEnv = "VLT_CLIPBOARD_HINTS"
+
+// Hint is a clipboard format offered alongside the copied text,
+// asking clipboard managers not to record the value in their history.
+type Hint struct {
+	Format string
+	Value  string
+}
+
+// SensitiveHints are the hints set when copying secrets.
+var SensitiveHints = []Hint{
+	{Format: "x-kde-passwordManagerHint", Value: "secret"},   // honored by KDE Klipper.
+	{Format: "CLIPBOARD_STATE", Value: "sensitive"},          // honored by GNOME clipboard managers.
+	{Format: "ExcludeClipboardContentFromMonitorProcessing"}, // honored by Windows clipboard history and monitors.
+}
+
+// hintsEnv returns the [HintsEnv] environment entry for the given hints.
+func hintsEnv(hints []Hint) string {
+	pairs := make([]string, 0, len(hints))
+	for _, h := range hints {
+		pairs = append(pairs, h.Format+"="+h.Value)
+	}
+
+	return HintsEnv + "=" + strings.Join(pairs, " ")
+}
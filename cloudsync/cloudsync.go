@@ -0,0 +1,362 @@
+// Package cloudsync pushes vault secrets to, and pulls them from, cloud secret
+// managers, detecting drift between the vault and the cloud.
+//
+// Each synced secret is mapped to its remote secret along with a keyed digest of
+// the value and labels it was last synced with, so that changes made on either
+// side since can be told apart.
+package cloudsync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/provider"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+// DefaultPrefix is the default prefix of remote secret names.
+const DefaultPrefix = "vlt/"
+
+// Status describes how a vault secret and its remote secret relate.
+type Status string
+
+const (
+	StatusInSync        Status = "in-sync"
+	StatusLocalChanged  Status = "local-changed"  // changed in the vault since last synced.
+	StatusRemoteChanged Status = "remote-changed" // changed in the cloud since last synced.
+	StatusDiverged      Status = "diverged"       // changed on both sides, or never synced and different.
+	StatusLocalOnly     Status = "local-only"
+	StatusRemoteOnly    Status = "remote-only"
+)
+
+// Drifted reports whether the remote secret changed in a way a push would overwrite.
+func (s Status) Drifted() bool {
+	return s == StatusRemoteChanged || s == StatusDiverged
+}
+
+// Entry pairs a vault secret with its remote secret.
+type Entry struct {
+	SecretID   int    // SecretID is the vault secret id, zero for remote-only entries.
+	Name       string // Name is the vault secret name.
+	RemoteName string
+	Status     Status
+
+	local  *provider.RemoteSecret // local is the vault secret in its remote form, nil for remote-only entries.
+	remote *provider.RemoteSecret // remote is nil for local-only entries.
+
+	localDigest, remoteDigest []byte
+	mapping                   *vaultdb.CloudSecret // mapping is the recorded mapping, if any.
+}
+
+// Syncer syncs vault secrets with a cloud secret manager.
+type Syncer struct {
+	Vault    *vault.Vault
+	Store    provider.Store
+	Provider string // Provider names the store, mappings are recorded per provider.
+	Prefix   string // Prefix is prepended to vault secret names to form remote names.
+}
+
+// Result lists the entries a push or pull applied, and the
+// drifted entries it skipped to avoid overwriting changes.
+type Result struct {
+	Applied []Entry
+	Skipped []Entry
+}
+
+// Plan pairs vault secrets with their remote secrets and determines their status.
+//
+// Only vault secrets with a label matching any of the given glob patterns,
+// and remote secrets whose labels match, are included. All secrets are
+// included if no patterns are given.
+func (s *Syncer) Plan(ctx context.Context, labels []string) ([]Entry, error) {
+	mappings, err := s.Vault.CloudSecrets(ctx, s.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("plan: %w", err)
+	}
+
+	bySecret := make(map[int]*vaultdb.CloudSecret, len(mappings))
+	byRemote := make(map[string]*vaultdb.CloudSecret, len(mappings))
+
+	for i := range mappings {
+		bySecret[mappings[i].SecretID] = &mappings[i]
+		byRemote[mappings[i].RemoteName] = &mappings[i]
+	}
+
+	remotes, err := s.Store.List(ctx, s.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("plan: %w", err)
+	}
+
+	matched, err := s.Vault.FilterSecrets(ctx, "", "", labels)
+	if err != nil {
+		return nil, fmt.Errorf("plan: %w", err)
+	}
+
+	ids := slices.Collect(maps.Keys(matched))
+
+	remoteByName := make(map[string]*provider.RemoteSecret, len(remotes))
+
+	for i, r := range remotes {
+		if !matchAny(labels, r.Labels) {
+			continue
+		}
+
+		remoteByName[r.Name] = &remotes[i]
+
+		// secrets synced with matching remote secrets are included,
+		// even if their labels no longer match.
+		if m, ok := byRemote[r.Name]; ok && !slices.Contains(ids, m.SecretID) {
+			ids = append(ids, m.SecretID)
+		}
+	}
+
+	var entries []Entry
+
+	if len(ids) > 0 {
+		if entries, err = s.localEntries(ctx, ids, bySecret, remoteByName); err != nil {
+			return nil, fmt.Errorf("plan: %w", err)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(remoteByName)) {
+		r := remoteByName[name]
+
+		digest, err := s.Vault.SecretDigest(r.Value, r.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("plan: %w", err)
+		}
+
+		entries = append(entries, Entry{
+			Name:         strings.TrimPrefix(name, s.Prefix),
+			RemoteName:   name,
+			Status:       StatusRemoteOnly,
+			remote:       r,
+			remoteDigest: digest,
+		})
+	}
+
+	return entries, nil
+}
+
+// localEntries returns the entries of the given vault secrets, removing
+// the remote secrets they are paired with from remotes.
+func (s *Syncer) localEntries(ctx context.Context, ids []int, mappings map[int]*vaultdb.CloudSecret, remotes map[string]*provider.RemoteSecret) ([]Entry, error) {
+	secrets, err := s.Vault.SecretsByIDs(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(ids)
+
+	entries := make([]Entry, 0, len(ids))
+	paired := make(map[string]int, len(ids))
+
+	for _, id := range ids {
+		secret := secrets[id]
+
+		e := Entry{SecretID: id, Name: secret.Name, RemoteName: s.Prefix + secret.Name, mapping: mappings[id]}
+		if e.mapping != nil {
+			e.RemoteName = e.mapping.RemoteName
+		}
+
+		if other, ok := paired[e.RemoteName]; ok {
+			return nil, fmt.Errorf("secrets %d and %d map to the same remote secret %q, rename either", other, id, e.RemoteName)
+		}
+
+		paired[e.RemoteName] = id
+
+		value, err := s.Vault.ShowSecret(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		e.local = &provider.RemoteSecret{Name: e.RemoteName, Value: value, Labels: secret.Labels}
+
+		if e.localDigest, err = s.Vault.SecretDigest(value, secret.Labels); err != nil {
+			return nil, err
+		}
+
+		if e.remote = remotes[e.RemoteName]; e.remote != nil {
+			delete(remotes, e.RemoteName)
+
+			if e.remoteDigest, err = s.Vault.SecretDigest(e.remote.Value, e.remote.Labels); err != nil {
+				return nil, err
+			}
+		}
+
+		e.Status = e.status()
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+func (e *Entry) status() Status {
+	switch {
+	case e.remote == nil:
+		return StatusLocalOnly
+	case bytes.Equal(e.localDigest, e.remoteDigest):
+		return StatusInSync
+	case e.mapping == nil:
+		return StatusDiverged
+	}
+
+	localChanged := !bytes.Equal(e.localDigest, e.mapping.Digest)
+	remoteChanged := !bytes.Equal(e.remoteDigest, e.mapping.Digest)
+
+	switch {
+	case localChanged && remoteChanged:
+		return StatusDiverged
+	case remoteChanged:
+		return StatusRemoteChanged
+	default:
+		return StatusLocalChanged
+	}
+}
+
+// Push pushes the vault secrets of the given entries to the cloud.
+//
+// Drifted entries are skipped, unless force is set.
+func (s *Syncer) Push(ctx context.Context, entries []Entry, force bool) (*Result, error) {
+	res := &Result{}
+
+	for _, e := range entries {
+		switch {
+		case e.Status == StatusRemoteOnly:
+			continue
+		case e.Status == StatusInSync:
+			if err := s.record(ctx, e, e.localDigest); err != nil {
+				return res, fmt.Errorf("push: %w", err)
+			}
+
+			continue
+		case e.Status.Drifted() && !force:
+			res.Skipped = append(res.Skipped, e)
+			continue
+		}
+
+		if err := s.Store.Put(ctx, *e.local, e.remote); err != nil {
+			return res, fmt.Errorf("push: %s: %w", e.RemoteName, err)
+		}
+
+		if err := s.record(ctx, e, e.localDigest); err != nil {
+			return res, fmt.Errorf("push: %w", err)
+		}
+
+		res.Applied = append(res.Applied, e)
+	}
+
+	return res, nil
+}
+
+// Pull pulls the remote secrets of the given entries into the vault.
+//
+// Entries changed in the vault are skipped, unless force is set.
+func (s *Syncer) Pull(ctx context.Context, entries []Entry, force bool) (*Result, error) {
+	res := &Result{}
+
+	for _, e := range entries {
+		switch {
+		case e.Status == StatusLocalOnly:
+			continue
+		case e.Status == StatusInSync:
+			if err := s.record(ctx, e, e.remoteDigest); err != nil {
+				return res, fmt.Errorf("pull: %w", err)
+			}
+
+			continue
+		case (e.Status == StatusLocalChanged || e.Status == StatusDiverged) && !force:
+			res.Skipped = append(res.Skipped, e)
+			continue
+		}
+
+		var err error
+
+		if e.Status == StatusRemoteOnly {
+			e.SecretID, err = s.Vault.InsertNewSecret(ctx, e.Name, e.remote.Value, e.remote.Labels)
+		} else {
+			err = s.update(ctx, e)
+		}
+
+		if err != nil {
+			return res, fmt.Errorf("pull: %s: %w", e.RemoteName, err)
+		}
+
+		if err := s.record(ctx, e, e.remoteDigest); err != nil {
+			return res, fmt.Errorf("pull: %w", err)
+		}
+
+		res.Applied = append(res.Applied, e)
+	}
+
+	return res, nil
+}
+
+// update updates the vault secret of the given entry to match its remote secret.
+func (s *Syncer) update(ctx context.Context, e Entry) error {
+	if e.local.Value != e.remote.Value {
+		if _, err := s.Vault.UpdateSecret(ctx, e.SecretID, e.remote.Value); err != nil {
+			return err
+		}
+	}
+
+	var removed, added []string
+
+	for _, l := range e.local.Labels {
+		if !slices.Contains(e.remote.Labels, l) {
+			removed = append(removed, l)
+		}
+	}
+
+	for _, l := range e.remote.Labels {
+		if !slices.Contains(e.local.Labels, l) {
+			added = append(added, l)
+		}
+	}
+
+	if len(removed) == 0 && len(added) == 0 {
+		return nil
+	}
+
+	return s.Vault.UpdateSecretMetadata(ctx, e.SecretID, "", removed, added)
+}
+
+// record records the mapping of the given entry, synced with the given digest.
+func (s *Syncer) record(ctx context.Context, e Entry, digest []byte) error {
+	if e.mapping != nil && e.mapping.RemoteName == e.RemoteName && bytes.Equal(e.mapping.Digest, digest) {
+		return nil
+	}
+
+	return s.Vault.SetCloudSecret(ctx, vaultdb.CloudSecret{
+		SecretID:   e.SecretID,
+		Provider:   s.Provider,
+		RemoteName: e.RemoteName,
+		Digest:     digest,
+		SyncedAt:   time.Now(),
+	})
+}
+
+// matchAny reports whether any of the labels matches any of the glob patterns,
+// or true if no patterns are given.
+func matchAny(patterns []string, labels []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, p := range patterns {
+		for _, l := range labels {
+			if ok, _ := path.Match(p, l); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
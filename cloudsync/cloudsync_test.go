@@ -0,0 +1,193 @@
+package cloudsync_test
+
+import (
+	"context"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/cloudsync"
+	"github.com/ladzaretti/vlt-cli/provider"
+	"github.com/ladzaretti/vlt-cli/vault"
+)
+
+// memStore is an in-memory [provider.Store].
+type memStore struct {
+	secrets map[string]provider.RemoteSecret
+}
+
+func (m *memStore) List(_ context.Context, prefix string) ([]provider.RemoteSecret, error) {
+	var secrets []provider.RemoteSecret
+
+	for _, name := range slices.Sorted(maps.Keys(m.secrets)) {
+		if strings.HasPrefix(name, prefix) {
+			secrets = append(secrets, m.secrets[name])
+		}
+	}
+
+	return secrets, nil
+}
+
+func (m *memStore) Put(_ context.Context, s provider.RemoteSecret, _ *provider.RemoteSecret) error {
+	m.secrets[s.Name] = s
+	return nil
+}
+
+func statuses(entries []cloudsync.Entry) map[string]cloudsync.Status {
+	m := make(map[string]cloudsync.Status, len(entries))
+	for _, e := range entries {
+		m[e.Name] = e.Status
+	}
+
+	return m
+}
+
+func TestSyncer(t *testing.T) {
+	ctx := t.Context()
+
+	v, err := vault.New(ctx, filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(ctx) }() //nolint:wsl
+
+	dbID, err := v.InsertNewSecret(ctx, "db", "v1", []string{"prod/db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiID, err := v.InsertNewSecret(ctx, "api", "key", []string{"prod/api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.InsertNewSecret(ctx, "dev", "dev", []string{"dev"}); err != nil {
+		t.Fatal(err)
+	}
+
+	store := &memStore{secrets: map[string]provider.RemoteSecret{
+		"vlt/remote": {Name: "vlt/remote", Value: "r", Labels: []string{"prod/remote"}},
+		"vlt/other":  {Name: "vlt/other", Value: "o", Labels: []string{"staging"}},
+	}}
+
+	s := &cloudsync.Syncer{Vault: v, Store: store, Provider: "mem", Prefix: cloudsync.DefaultPrefix}
+
+	plan := func() map[string]cloudsync.Status {
+		t.Helper()
+
+		entries, err := s.Plan(ctx, []string{"prod/*"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return statuses(entries)
+	}
+
+	want := map[string]cloudsync.Status{
+		"db":     cloudsync.StatusLocalOnly,
+		"api":    cloudsync.StatusLocalOnly,
+		"remote": cloudsync.StatusRemoteOnly,
+	}
+	if got := plan(); !maps.Equal(got, want) {
+		t.Errorf("initial plan: got %v, want %v", got, want)
+	}
+
+	entries, err := s.Plan(ctx, []string{"prod/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Push(ctx, entries, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Pull(ctx, entries, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := store.secrets["vlt/db"]; got.Value != "v1" || !slices.Equal(got.Labels, []string{"prod/db"}) {
+		t.Errorf("pushed secret: got %+v", got)
+	}
+
+	if ids, err := v.FilterSecrets(ctx, "", "remote", nil); err != nil || len(ids) != 1 {
+		t.Errorf("pulled secret: got %v, %v", ids, err)
+	}
+
+	want = map[string]cloudsync.Status{
+		"db":     cloudsync.StatusInSync,
+		"api":    cloudsync.StatusInSync,
+		"remote": cloudsync.StatusInSync,
+	}
+	if got := plan(); !maps.Equal(got, want) {
+		t.Errorf("plan after sync: got %v, want %v", got, want)
+	}
+
+	// drift the secrets on either or both sides.
+	if _, err := v.UpdateSecret(ctx, dbID, "v2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.UpdateSecretMetadata(ctx, apiID, "", nil, []string{"rotated"}); err != nil {
+		t.Fatal(err)
+	}
+
+	store.secrets["vlt/api"] = provider.RemoteSecret{Name: "vlt/api", Value: "changed", Labels: []string{"prod/api"}}
+	store.secrets["vlt/remote"] = provider.RemoteSecret{Name: "vlt/remote", Value: "r2", Labels: []string{"prod/remote"}}
+
+	want = map[string]cloudsync.Status{
+		"db":     cloudsync.StatusLocalChanged,
+		"api":    cloudsync.StatusDiverged,
+		"remote": cloudsync.StatusRemoteChanged,
+	}
+	if got := plan(); !maps.Equal(got, want) {
+		t.Errorf("plan after drift: got %v, want %v", got, want)
+	}
+
+	entries, err = s.Plan(ctx, []string{"prod/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := s.Push(ctx, entries, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Applied) != 1 || len(res.Skipped) != 2 {
+		t.Errorf("push: got %d applied, %d skipped, want 1, 2", len(res.Applied), len(res.Skipped))
+	}
+
+	if got := store.secrets["vlt/api"].Value; got != "changed" {
+		t.Errorf("drifted secret was overwritten: got %q", got)
+	}
+
+	entries, err = s.Plan(ctx, []string{"prod/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Pull(ctx, entries, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := v.ShowSecret(ctx, apiID); err != nil || got != "changed" {
+		t.Errorf("force pulled secret: got %q, %v, want %q", got, err, "changed")
+	}
+
+	secrets, err := v.SecretsByIDs(ctx, apiID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := secrets[apiID].Labels; !slices.Equal(got, []string{"prod/api"}) {
+		t.Errorf("force pulled labels: got %v, want [prod/api]", got)
+	}
+
+	for name, status := range plan() {
+		if status != cloudsync.StatusInSync {
+			t.Errorf("plan after force pull: %s is %s", name, status)
+		}
+	}
+}
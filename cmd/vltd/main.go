@@ -42,7 +42,8 @@ func main() {
 Usage: vltd [options]
 
 Manages user sessions for the 'vlt' cli.
-Runs over a UNIX socket at /run/user/$UID/vlt.sock and takes no arguments.
+Runs over a UNIX socket at /run/user/$UID/vlt.sock, or $TMPDIR/vlt.sock on macOS,
+and takes no arguments.
 
 Options:
 `)
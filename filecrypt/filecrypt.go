@@ -0,0 +1,260 @@
+// Package filecrypt encrypts standalone files, outside of the vault.
+//
+// Files are encrypted in chunks using AES-256-GCM, under a key derived from
+// a key material, e.g., the vault file key or a stored secret, and a random
+// salt. The chunks are authenticated along with the file header, their
+// position, and whether they are the last one, so that they cannot be
+// reordered or dropped unnoticed.
+//
+// An encrypted file is made of a header followed by the sealed chunks,
+// each prefixed by its 4-byte big-endian length:
+//
+//	magic "vltfile1" | key type (1 byte) | salt (32 bytes) | chunks...
+package filecrypt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Ext is the extension of encrypted files.
+const Ext = ".vlt"
+
+const (
+	magic     = "vltfile1"
+	keyInfo   = "vlt-file-v1"
+	saltSize  = 32
+	chunkSize = 64 << 10
+
+	headerSize = len(magic) + 1 + saltSize
+)
+
+// KeyType tells which key material a file is encrypted under.
+type KeyType byte
+
+const (
+	KeyVault  KeyType = iota // KeyVault is the file key of the vault.
+	KeyStored                // KeyStored is the value of a stored secret.
+)
+
+func (t KeyType) String() string {
+	switch t {
+	case KeyVault:
+		return "vault key"
+	case KeyStored:
+		return "stored key"
+	default:
+		return fmt.Sprintf("unknown key type %d", byte(t))
+	}
+}
+
+var (
+	// ErrFormat indicates the input is not a file encrypted by this package.
+	ErrFormat = errors.New("not a vlt encrypted file")
+
+	// ErrDecrypt indicates the file was encrypted using another key, or was modified.
+	ErrDecrypt = errors.New("decryption failed: wrong key, or the file was modified")
+)
+
+// Header is the header of an encrypted file.
+type Header struct {
+	KeyType KeyType
+	salt    []byte
+	raw     []byte // raw is the encoded header, authenticated along with each chunk.
+}
+
+// Encrypt encrypts r under the given key material to w.
+func Encrypt(w io.Writer, r io.Reader, keyType KeyType, ikm []byte) error {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	h := &Header{KeyType: keyType, salt: salt}
+	h.raw = append(append([]byte(magic), byte(keyType)), salt...)
+
+	aead, err := h.aead(ikm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(h.raw); err != nil {
+		return err
+	}
+
+	cur, next := make([]byte, chunkSize), make([]byte, chunkSize)
+
+	n, err := readChunk(r, cur)
+	if err != nil {
+		return err
+	}
+
+	for seq := uint64(0); ; seq++ {
+		m := 0
+		if n == chunkSize {
+			if m, err = readChunk(r, next); err != nil {
+				return err
+			}
+		}
+
+		last := m == 0
+
+		sealed := aead.Seal(nil, nonce(seq, last), cur[:n], h.raw)
+
+		if err := binary.Write(w, binary.BigEndian, uint32(len(sealed))); err != nil { //nolint:gosec // bounded by the chunk size.
+			return err
+		}
+
+		if _, err := w.Write(sealed); err != nil {
+			return err
+		}
+
+		if last {
+			return nil
+		}
+
+		cur, next, n = next, cur, m
+	}
+}
+
+// ReadHeader reads the header of an encrypted file from r.
+func ReadHeader(r io.Reader) (*Header, error) {
+	raw := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, raw); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrFormat
+		}
+
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(raw, []byte(magic)) {
+		return nil, ErrFormat
+	}
+
+	h := &Header{
+		KeyType: KeyType(raw[len(magic)]),
+		salt:    raw[len(magic)+1:],
+		raw:     raw,
+	}
+
+	if h.KeyType != KeyVault && h.KeyType != KeyStored {
+		return nil, fmt.Errorf("%w: %s", ErrFormat, h.KeyType)
+	}
+
+	return h, nil
+}
+
+// Decrypt decrypts the chunks following the header h read from r to w,
+// under the given key material.
+//
+// Chunks are written as they are authenticated, on failure w holds
+// the chunks preceding the failing one.
+func (h *Header) Decrypt(w io.Writer, r io.Reader, ikm []byte) error {
+	aead, err := h.aead(ikm)
+	if err != nil {
+		return err
+	}
+
+	br := bufio.NewReader(r)
+	buf := make([]byte, chunkSize+aead.Overhead())
+
+	for seq := uint64(0); ; seq++ {
+		var size uint32
+		if err := binary.Read(br, binary.BigEndian, &size); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return fmt.Errorf("%w: truncated", ErrDecrypt)
+			}
+
+			return err
+		}
+
+		if int(size) > len(buf) {
+			return fmt.Errorf("%w: invalid chunk size", ErrFormat)
+		}
+
+		sealed := buf[:size]
+		if _, err := io.ReadFull(br, sealed); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return fmt.Errorf("%w: truncated", ErrDecrypt)
+			}
+
+			return err
+		}
+
+		last := false
+
+		plaintext, err := aead.Open(nil, nonce(seq, false), sealed, h.raw)
+		if err != nil {
+			if plaintext, err = aead.Open(nil, nonce(seq, true), sealed, h.raw); err != nil {
+				return ErrDecrypt
+			}
+
+			last = true
+		}
+
+		if _, err := w.Write(plaintext); err != nil {
+			return err
+		}
+
+		if last {
+			if _, err := br.ReadByte(); !errors.Is(err, io.EOF) {
+				return fmt.Errorf("%w: trailing data", ErrDecrypt)
+			}
+
+			return nil
+		}
+	}
+}
+
+// aead returns the cipher of the file key derived from ikm and the header salt.
+func (h *Header) aead(ikm []byte) (cipher.AEAD, error) {
+	if len(ikm) == 0 {
+		return nil, errors.New("empty key")
+	}
+
+	key, err := hkdf.Key(sha256.New, ikm, h.salt, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// nonce returns the nonce of the chunk at the given position, the last chunk
+// is flagged. Nonces are unique per file, as keys are derived using a random salt.
+func nonce(seq uint64, last bool) []byte {
+	n := make([]byte, 12)
+	binary.BigEndian.PutUint64(n[3:11], seq)
+
+	if last {
+		n[11] = 1
+	}
+
+	return n
+}
+
+// readChunk reads up to len(buf) bytes from r, returning
+// the number of bytes read, short only at the end of r.
+func readChunk(r io.Reader, buf []byte) (int, error) {
+	n, err := io.ReadFull(r, buf)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return n, nil
+	}
+
+	return n, err
+}
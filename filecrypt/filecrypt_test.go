@@ -0,0 +1,150 @@
+package filecrypt_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ladzaretti/vlt-cli/filecrypt"
+)
+
+func encrypt(t *testing.T, plaintext []byte, keyType filecrypt.KeyType, key []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := filecrypt.Encrypt(&buf, bytes.NewReader(plaintext), keyType, key); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func decrypt(encrypted []byte, key []byte) ([]byte, *filecrypt.Header, error) {
+	r := bytes.NewReader(encrypted)
+
+	h, err := filecrypt.ReadHeader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	err = h.Decrypt(&buf, r, key)
+
+	return buf.Bytes(), h, err
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := []byte("key material")
+
+	large := make([]byte, 3*64<<10)
+	if _, err := rand.Read(large); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		plaintext []byte
+	}{
+		{name: "empty", plaintext: nil},
+		{name: "short", plaintext: []byte("hello")},
+		{name: "chunk multiple", plaintext: large},
+		{name: "partial chunk", plaintext: large[:len(large)-100]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted := encrypt(t, tt.plaintext, filecrypt.KeyStored, key)
+
+			got, h, err := decrypt(encrypted, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if h.KeyType != filecrypt.KeyStored {
+				t.Errorf("KeyType = %v, want %v", h.KeyType, filecrypt.KeyStored)
+			}
+
+			if !bytes.Equal(got, tt.plaintext) {
+				t.Errorf("decrypted %d bytes, want %d bytes", len(got), len(tt.plaintext))
+			}
+		})
+	}
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	key := []byte("key material")
+
+	plaintext := make([]byte, 2*64<<10+10)
+	encrypted := encrypt(t, plaintext, filecrypt.KeyVault, key)
+
+	if _, _, err := decrypt(encrypted, []byte("other key")); !errors.Is(err, filecrypt.ErrDecrypt) {
+		t.Errorf("wrong key: got err %v, want %v", err, filecrypt.ErrDecrypt)
+	}
+
+	if _, _, err := decrypt([]byte("plain text file, long enough to hold a header"), key); !errors.Is(err, filecrypt.ErrFormat) {
+		t.Errorf("unencrypted file: got err %v, want %v", err, filecrypt.ErrFormat)
+	}
+
+	// the header is authenticated.
+	modified := bytes.Clone(encrypted)
+	modified[8] = byte(filecrypt.KeyStored)
+
+	if _, _, err := decrypt(modified, key); !errors.Is(err, filecrypt.ErrDecrypt) {
+		t.Errorf("modified header: got err %v, want %v", err, filecrypt.ErrDecrypt)
+	}
+
+	// the last chunk holds 10 bytes, sealed into 4+10+16 bytes.
+	if _, _, err := decrypt(encrypted[:len(encrypted)-30], key); !errors.Is(err, filecrypt.ErrDecrypt) {
+		t.Errorf("dropped last chunk: got err %v, want %v", err, filecrypt.ErrDecrypt)
+	}
+
+	if _, _, err := decrypt(append(bytes.Clone(encrypted), 0), key); !errors.Is(err, filecrypt.ErrDecrypt) {
+		t.Errorf("trailing data: got err %v, want %v", err, filecrypt.ErrDecrypt)
+	}
+}
+
+func TestEncryptDecrypt_Stream(t *testing.T) {
+	key := []byte("key material")
+
+	const size = 1<<20 + 123
+
+	// the plaintext is streamed through pipes, read in short reads.
+	pr, pw := io.Pipe()
+
+	go func() {
+		_, err := io.CopyN(pw, rand.Reader, size)
+		_ = pw.CloseWithError(err)
+	}()
+
+	er, ew := io.Pipe()
+
+	go func() {
+		_ = ew.CloseWithError(filecrypt.Encrypt(ew, iotest.HalfReader(pr), filecrypt.KeyVault, key))
+	}()
+
+	r := iotest.HalfReader(er)
+
+	h, err := filecrypt.ReadHeader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := &countingWriter{}
+	if err := h.Decrypt(n, r, key); err != nil {
+		t.Fatal(err)
+	}
+
+	if n.n != size {
+		t.Errorf("decrypted %d bytes, want %d", n.n, size)
+	}
+}
+
+type countingWriter struct{ n int }
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return len(p), nil
+}
@@ -0,0 +1,184 @@
+// Package fuzzy suggests names close to mistyped ones, e.g., "github"
+// for "gihub", based on their edit distance, and filters names matching
+// an abbreviated query, e.g., "gh" for "github", as done by fuzzy finders.
+//
+// The edit distance is the number of single character insertions, deletions,
+// substitutions and transpositions of adjacent characters needed to turn one
+// name into the other, ignoring case.
+package fuzzy
+
+import (
+	"cmp"
+	"slices"
+	"unicode"
+)
+
+// maxDistance is the highest edit distance of suggestions,
+// lowered for short names, see [threshold].
+const maxDistance = 3
+
+// Suggestion is a candidate name close to a looked up one.
+type Suggestion struct {
+	Name     string
+	Distance int
+}
+
+// Suggest returns the distinct candidates close enough to name to be
+// suggested in its place, closest first, then by name.
+//
+// The tolerated distance grows with the length of name, by one edit per three
+// characters, at least one and up to [maxDistance]. A candidate equal to name
+// is not suggested.
+func Suggest(name string, candidates []string) []Suggestion {
+	limit := threshold(name)
+
+	var suggestions []Suggestion
+
+	for _, c := range candidates {
+		if c == name || slices.ContainsFunc(suggestions, func(s Suggestion) bool { return s.Name == c }) {
+			continue
+		}
+
+		if d := Distance(name, c); d <= limit {
+			suggestions = append(suggestions, Suggestion{Name: c, Distance: d})
+		}
+	}
+
+	slices.SortFunc(suggestions, func(a, b Suggestion) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.Name, b.Name))
+	})
+
+	return suggestions
+}
+
+// Best returns the single suggestion closest to name, if any is strictly
+// closer than all the others.
+func Best(suggestions []Suggestion) (Suggestion, bool) {
+	switch {
+	case len(suggestions) == 0:
+		return Suggestion{}, false
+	case len(suggestions) > 1 && suggestions[1].Distance == suggestions[0].Distance:
+		return Suggestion{}, false
+	}
+
+	return suggestions[0], true
+}
+
+func threshold(name string) int {
+	return min(max(len([]rune(name))/3, 1), maxDistance)
+}
+
+// Distance returns the edit distance between a and b, ignoring case.
+//
+// It is the optimal string alignment distance: transposed adjacent characters
+// count as a single edit, but are not edited any further.
+func Distance(a, b string) int {
+	ra, rb := fold(a), fold(b)
+
+	// d[i][j] is the distance between the first i runes of a and the first j runes of b.
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min(
+				d[i-1][j]+1,      // deletion
+				d[i][j-1]+1,      // insertion
+				d[i-1][j-1]+cost, // substitution
+			)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1) // transposition
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+// Match reports whether the runes of query appear in s in order, ignoring
+// case, e.g., "gh" in "github", and returns the score of the match.
+//
+// Higher scores are better: matched runes following each other, or starting
+// a word of s, score higher than scattered ones.
+func Match(query, s string) (score int, ok bool) {
+	rq, rs := fold(query), fold(s)
+
+	prev := -1 // prev is the index of the previously matched rune of s.
+
+	for _, r := range rq {
+		i := prev + 1
+		for i < len(rs) && rs[i] != r {
+			i++
+		}
+
+		if i == len(rs) {
+			return 0, false
+		}
+
+		score++
+
+		if prev >= 0 && i == prev+1 {
+			score += 2
+		}
+
+		if i == 0 || !unicode.IsLetter(rs[i-1]) && !unicode.IsDigit(rs[i-1]) {
+			score += 2
+		}
+
+		prev = i
+	}
+
+	return score, true
+}
+
+// Filter returns the indices of the items matching query, see [Match],
+// best match first, then shortest, then in the given order.
+// An empty query matches all items, in the given order.
+func Filter(query string, items []string) []int {
+	type match struct{ i, score int }
+
+	var matches []match
+
+	for i, item := range items {
+		if score, ok := Match(query, item); ok {
+			matches = append(matches, match{i: i, score: score})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		if len(query) == 0 {
+			return 0
+		}
+
+		return cmp.Or(cmp.Compare(b.score, a.score), cmp.Compare(len(items[a.i]), len(items[b.i])))
+	})
+
+	indices := make([]int, len(matches))
+	for i, m := range matches {
+		indices[i] = m.i
+	}
+
+	return indices
+}
+
+func fold(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+
+	return runes
+}
@@ -0,0 +1,118 @@
+package fuzzy_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/fuzzy"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "github", b: "github", want: 0},
+		{a: "GitHub", b: "github", want: 0},
+		{a: "gihub", b: "github", want: 1},
+		{a: "githbu", b: "github", want: 1},
+		{a: "gitlab", b: "github", want: 2},
+		{a: "", b: "abc", want: 3},
+		{a: "work-vpn", b: "wrok-vnp", want: 2},
+		{a: "ca", b: "abc", want: 3},
+		{a: "héllo", b: "hello", want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := fuzzy.Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+
+		if got := fuzzy.Distance(tt.b, tt.a); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"github", "gitlab", "gist", "github", "team/github", "db"}
+
+	tests := []struct {
+		name     string
+		want     []string
+		wantBest string // wantBest is the best suggestion, empty for none.
+	}{
+		{name: "gihub", want: []string{"github"}, wantBest: "github"},
+		{name: "gitub", want: []string{"github"}, wantBest: "github"},
+		{name: "gitlub", want: []string{"github", "gitlab"}},
+		{name: "GitHub", want: []string{"github", "gitlab"}, wantBest: "github"},
+		{name: "github", want: []string{"gitlab"}, wantBest: "gitlab"},
+		{name: "d", want: []string{"db"}, wantBest: "db"},
+		{name: "aws", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		suggestions := fuzzy.Suggest(tt.name, candidates)
+
+		got := make([]string, 0, len(suggestions))
+		for _, s := range suggestions {
+			got = append(got, s.Name)
+		}
+
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Suggest(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+
+		best, ok := fuzzy.Best(suggestions)
+		if ok != (len(tt.wantBest) > 0) || best.Name != tt.wantBest {
+			t.Errorf("Best(%q) = %q, %v, want %q", tt.name, best.Name, ok, tt.wantBest)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query, s string
+		wantOK   bool
+	}{
+		{query: "", s: "github", wantOK: true},
+		{query: "gh", s: "github", wantOK: true},
+		{query: "GH", s: "github", wantOK: true},
+		{query: "hg", s: "github", wantOK: false},
+		{query: "tgh", s: "team/github", wantOK: true},
+		{query: "githubs", s: "github", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := fuzzy.Match(tt.query, tt.s); ok != tt.wantOK {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.query, tt.s, ok, tt.wantOK)
+		}
+	}
+
+	consecutive, _ := fuzzy.Match("git", "github")
+	scattered, _ := fuzzy.Match("git", "gadget")
+
+	if consecutive <= scattered {
+		t.Errorf("Match(%q): got score %d for %q, want more than %d for %q", "git", consecutive, "github", scattered, "gadget")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	items := []string{"gadget", "team/github", "db", "github", "gist"}
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{query: "", want: []int{0, 1, 2, 3, 4}},
+		{query: "git", want: []int{3, 1, 4}},
+		{query: "gh", want: []int{3, 1}},
+		{query: "aws", want: []int{}},
+	}
+
+	for _, tt := range tests {
+		if got := fuzzy.Filter(tt.query, items); !slices.Equal(got, tt.want) {
+			t.Errorf("Filter(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
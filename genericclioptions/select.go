@@ -0,0 +1,244 @@
+package genericclioptions
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ladzaretti/vlt-cli/fuzzy"
+	"github.com/ladzaretti/vlt-cli/input"
+
+	"golang.org/x/term"
+)
+
+// selectRows is the maximum number of items displayed below the query.
+const selectRows = 10
+
+var (
+	// ErrSelectAborted indicates that the selection was canceled,
+	// using Esc, Ctrl-C or Ctrl-D.
+	ErrSelectAborted = errors.New("selection aborted")
+
+	// ErrSelectNoItems indicates that there is nothing to select from.
+	ErrSelectNoItems = errors.New("no items to select from")
+)
+
+const (
+	keyCtrlC     = 0x03
+	keyCtrlD     = 0x04
+	keyBackspace = 0x08
+	keyTab       = 0x09
+	keyCtrlJ     = 0x0a
+	keyCtrlK     = 0x0b
+	keyEnter     = 0x0d
+	keyCtrlN     = 0x0e
+	keyCtrlP     = 0x10
+	keyCtrlU     = 0x15
+	keyCtrlW     = 0x17
+	keyEsc       = 0x1b
+	keyDelete    = 0x7f
+
+	seqUp   = "\x1b[A"
+	seqDown = "\x1b[B"
+)
+
+// Select prompts the user to pick one of the items using an interactive
+// fuzzy finder, see [fuzzy.Filter], and returns the index of the picked item.
+//
+// The query is read from the terminal of the input stream, in raw mode, and
+// the matching items are displayed on the error stream, leaving the output
+// stream to the command. Up and Down, or Ctrl-P and Ctrl-N, move the cursor,
+// Enter picks the item under it, and Esc, Ctrl-C or Ctrl-D abort the selection.
+//
+// It returns [input.ErrNotTerminal] if the input is not a terminal.
+func Select(o *StdioOptions, prompt string, items []string) (int, error) {
+	if len(items) == 0 {
+		return 0, ErrSelectNoItems
+	}
+
+	fd := int(o.In.Fd())
+	if o.NonInteractive || !term.IsTerminal(fd) {
+		return 0, input.ErrNotTerminal
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return 0, fmt.Errorf("select: %w", err)
+	}
+
+	defer func() { _ = term.Restore(fd, state) }() //nolint:wsl
+
+	s := newSelector(prompt, items)
+
+	if width, _, err := term.GetSize(fd); err == nil {
+		s.width = width
+	}
+
+	return s.run(o.In, o.ErrOut)
+}
+
+// selector holds the state of an interactive selection.
+type selector struct {
+	prompt  string
+	items   []string
+	query   []rune
+	matches []int // matches are the indices of the items matching the query, best first.
+	cursor  int   // cursor is the index of the highlighted match.
+	offset  int   // offset is the index of the first displayed match.
+	width   int   // width is the terminal width items are truncated to, zero for none.
+}
+
+func newSelector(prompt string, items []string) *selector {
+	s := &selector{prompt: prompt, items: items}
+	s.filter()
+
+	return s
+}
+
+// run reads keys from r and draws the selection on w, until an item
+// is picked or the selection is aborted.
+func (s *selector) run(r io.Reader, w io.Writer) (int, error) {
+	// reserve the rows of the items first, so the drawing does not scroll.
+	rows := min(len(s.items), selectRows)
+	fmt.Fprintf(w, "%s\x1b[%dA", strings.Repeat("\r\n", rows), rows)
+
+	defer fmt.Fprint(w, "\r\x1b[J")
+
+	buf := make([]byte, 64)
+
+	for {
+		s.draw(w)
+
+		n, err := r.Read(buf)
+		if err != nil {
+			return 0, fmt.Errorf("select: %w", err)
+		}
+
+		picked, done, err := s.handle(buf[:n])
+		if done || err != nil {
+			return picked, err
+		}
+	}
+}
+
+// handle applies the keys read at once, typically a single key or escape
+// sequence, reporting whether the selection is done.
+func (s *selector) handle(keys []byte) (picked int, done bool, err error) {
+	switch k := string(keys); k {
+	case seqUp:
+		s.move(-1)
+		return 0, false, nil
+	case seqDown:
+		s.move(1)
+		return 0, false, nil
+	case string(rune(keyEsc)):
+		return 0, true, ErrSelectAborted
+	}
+
+	for len(keys) > 0 {
+		r, size := utf8.DecodeRune(keys)
+		keys = keys[size:]
+
+		switch r {
+		case keyCtrlC, keyCtrlD:
+			return 0, true, ErrSelectAborted
+		case keyEnter, keyCtrlJ:
+			if len(s.matches) == 0 {
+				continue
+			}
+
+			return s.matches[s.cursor], true, nil
+		case keyCtrlP, keyCtrlK:
+			s.move(-1)
+		case keyCtrlN, keyTab:
+			s.move(1)
+		case keyBackspace, keyDelete:
+			if len(s.query) > 0 {
+				s.query = s.query[:len(s.query)-1]
+				s.filter()
+			}
+		case keyCtrlU:
+			s.query = nil
+			s.filter()
+		case keyCtrlW:
+			q := strings.TrimRightFunc(string(s.query), unicode.IsSpace)
+			s.query = []rune(strings.TrimRightFunc(q, func(r rune) bool { return !unicode.IsSpace(r) }))
+			s.filter()
+		case keyEsc:
+			// an unknown escape sequence, dropped as a whole.
+			return 0, false, nil
+		default:
+			if unicode.IsPrint(r) {
+				s.query = append(s.query, r)
+				s.filter()
+			}
+		}
+	}
+
+	return 0, false, nil
+}
+
+// filter matches the items against the query, resetting the cursor.
+func (s *selector) filter() {
+	s.matches = fuzzy.Filter(string(s.query), s.items)
+	s.cursor, s.offset = 0, 0
+}
+
+// move moves the cursor by delta matches, scrolling the displayed ones.
+func (s *selector) move(delta int) {
+	if len(s.matches) == 0 {
+		return
+	}
+
+	s.cursor = min(max(s.cursor+delta, 0), len(s.matches)-1)
+
+	switch {
+	case s.cursor < s.offset:
+		s.offset = s.cursor
+	case s.cursor >= s.offset+selectRows:
+		s.offset = s.cursor - selectRows + 1
+	}
+}
+
+// draw redraws the query line and the displayed matches below it,
+// leaving the cursor at the end of the query.
+func (s *selector) draw(w io.Writer) {
+	var sb strings.Builder
+
+	sb.WriteString("\r\x1b[J")
+
+	end := min(s.offset+selectRows, len(s.matches))
+
+	for i := s.offset; i < end; i++ {
+		marker := "  "
+		if i == s.cursor {
+			marker = "> "
+		}
+
+		sb.WriteString("\r\n" + s.truncate(marker+s.items[s.matches[i]]))
+	}
+
+	if rows := end - s.offset; rows > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dA", rows)
+	}
+
+	fmt.Fprintf(&sb, "\r%s", s.truncate(fmt.Sprintf("%s%s (%d/%d)", s.prompt, string(s.query), len(s.matches), len(s.items))))
+
+	if col := utf8.RuneCountInString(s.prompt) + len(s.query); col > 0 {
+		fmt.Fprintf(&sb, "\r\x1b[%dC", col)
+	}
+
+	_, _ = io.WriteString(w, sb.String())
+}
+
+// truncate truncates line to the terminal width, so that it does not wrap.
+func (s *selector) truncate(line string) string {
+	if r := []rune(line); s.width > 1 && len(r) >= s.width {
+		return string(r[:s.width-1])
+	}
+
+	return line
+}
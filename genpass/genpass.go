@@ -0,0 +1,232 @@
+// Package genpass generates passwords: random strings of the given character
+// classes, pronounceable strings, and diceware passphrases.
+//
+// Random values are read from crypto/rand. Passwords of all modes are
+// generated using [Generate], e.g., by 'vlt generate'.
+package genpass
+
+import (
+	"bufio"
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/ladzaretti/vlt-cli/randstring"
+)
+
+// Mode is a password generation mode.
+type Mode string
+
+const (
+	ModeRandom        Mode = "random"        // ModeRandom generates random strings of the given character classes.
+	ModePronounceable Mode = "pronounceable" // ModePronounceable generates alternating consonants and vowels.
+	ModePassphrase    Mode = "passphrase"    // ModePassphrase generates diceware passphrases.
+)
+
+const (
+	// DefaultLength is the default length of pronounceable passwords.
+	DefaultLength = 16
+
+	// DefaultWords is the default number of passphrase words,
+	// about 62 bits of entropy using [DefaultWordlist].
+	DefaultWords = 6
+
+	// DefaultSeparator is the default separator of passphrase words.
+	DefaultSeparator = "-"
+
+	// MinWordlistLen is the minimum number of distinct words of a
+	// wordlist, at least 10 bits of entropy per word.
+	MinWordlistLen = 1024
+)
+
+var (
+	ErrInvalidLength    = errors.New("length must be greater than 0")
+	ErrInvalidWords     = errors.New("number of words must be greater than 0")
+	ErrWordlistTooShort = fmt.Errorf("wordlist must hold at least %d distinct words", MinWordlistLen)
+	ErrInvalidWordlist  = errors.New("invalid wordlist")
+)
+
+const (
+	consonants = "bcdfghjklmnprstvz"
+	vowels     = "aeiou"
+)
+
+//go:embed wordlist.txt
+var wordlistFile string
+
+// DefaultWordlist holds 1296 short common English words, numbered by the
+// rolls of four dice in wordlist.txt, so that passphrases can also be
+// generated by hand.
+var DefaultWordlist = mustParseWordlist(wordlistFile)
+
+// Options configures the generated password.
+type Options struct {
+	Mode Mode // Mode defaults to [ModeRandom].
+
+	// Policy is the minimum number of characters of each class,
+	// and the minimum length, of random passwords.
+	Policy randstring.PasswordPolicy
+
+	// Length is the length of pronounceable passwords.
+	Length int
+
+	// Words, Separator and Capitalize shape passphrases of words drawn
+	// from Wordlist, defaulting to [DefaultWordlist].
+	Words      int
+	Separator  string
+	Capitalize bool
+	Wordlist   []string
+}
+
+// Generate returns a password generated as configured by the given options.
+func Generate(o Options) (string, error) {
+	switch o.Mode {
+	case ModeRandom, "":
+		return randstring.NewWithPolicy(o.Policy)
+	case ModePronounceable:
+		return Pronounceable(o.Length)
+	case ModePassphrase:
+		return Passphrase(o.Words, o.Separator, o.Capitalize, o.Wordlist)
+	default:
+		return "", fmt.Errorf("unknown mode %q", o.Mode)
+	}
+}
+
+// Pronounceable returns a lowercase password of the given length, of
+// alternating consonants and vowels, starting with either one. It is easier
+// to read and type than a random string of the same length, but weaker,
+// about 3.2 bits of entropy per character.
+func Pronounceable(n int) (string, error) {
+	if n <= 0 {
+		return "", ErrInvalidLength
+	}
+
+	first, err := randomInt(2)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+
+	for i := range n {
+		alphabet := consonants
+		if (i+first)%2 == 1 {
+			alphabet = vowels
+		}
+
+		c, err := randomInt(len(alphabet))
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteByte(alphabet[c])
+	}
+
+	return sb.String(), nil
+}
+
+// Passphrase returns a passphrase of the given number of words drawn
+// uniformly from the wordlist, or from [DefaultWordlist] if empty,
+// joined by the separator. Capitalize capitalizes the first letter
+// of each word.
+func Passphrase(words int, separator string, capitalize bool, wordlist []string) (string, error) {
+	if words <= 0 {
+		return "", ErrInvalidWords
+	}
+
+	if len(wordlist) == 0 {
+		wordlist = DefaultWordlist
+	}
+
+	picked := make([]string, words)
+
+	for i := range picked {
+		n, err := randomInt(len(wordlist))
+		if err != nil {
+			return "", err
+		}
+
+		w := wordlist[n]
+		if capitalize {
+			r := []rune(w)
+			r[0] = unicode.ToUpper(r[0])
+			w = string(r)
+		}
+
+		picked[i] = w
+	}
+
+	return strings.Join(picked, separator), nil
+}
+
+// ParseWordlist parses a wordlist of one word per line, optionally numbered
+// by dice rolls as diceware wordlists are, e.g., "11111	abacus". Empty lines
+// and repeated words are skipped.
+//
+// It returns [ErrWordlistTooShort] if the wordlist holds less than
+// [MinWordlistLen] distinct words.
+func ParseWordlist(r io.Reader) ([]string, error) {
+	var (
+		words []string
+		seen  = make(map[string]bool)
+	)
+
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) == 2 && isDiceRoll(fields[0]):
+			fields = fields[1:]
+		case len(fields) > 1:
+			return nil, fmt.Errorf("%w: line %d: expecting a word, optionally numbered", ErrInvalidWordlist, line)
+		}
+
+		if w := fields[0]; !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(words) < MinWordlistLen {
+		return nil, fmt.Errorf("%w, got %d", ErrWordlistTooShort, len(words))
+	}
+
+	return words, nil
+}
+
+func mustParseWordlist(s string) []string {
+	words, err := ParseWordlist(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+
+	return words
+}
+
+// isDiceRoll reports whether s is a sequence of die faces, e.g., "16253".
+func isDiceRoll(s string) bool {
+	return strings.Trim(s, "123456") == ""
+}
+
+// randomInt returns a uniform random integer in [0, n).
+func randomInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(v.Int64()), nil
+}
@@ -0,0 +1,122 @@
+package genpass_test
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/genpass"
+	"github.com/ladzaretti/vlt-cli/randstring"
+)
+
+func TestGenerate_Random(t *testing.T) {
+	policy := randstring.PasswordPolicy{MinUppercase: 3, MinNumeric: 4, MinLength: 20}
+
+	s, err := genpass.Generate(genpass.Options{Policy: policy})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(set string) (n int) {
+		for _, r := range s {
+			if strings.ContainsRune(set, r) {
+				n++
+			}
+		}
+
+		return n
+	}
+
+	if len(s) != 20 || count("ABCDEFGHIJKLMNOPQRSTUVWXYZ") < 3 || count("0123456789") < 4 {
+		t.Errorf("Generate(%+v): got %q", policy, s)
+	}
+}
+
+func TestPronounceable(t *testing.T) {
+	const vowels = "aeiou"
+
+	for range 20 {
+		s, err := genpass.Pronounceable(11)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(s) != 11 {
+			t.Fatalf("Pronounceable(11): got %q, want 11 characters", s)
+		}
+
+		for i := 1; i < len(s); i++ {
+			if strings.ContainsRune(vowels, rune(s[i])) == strings.ContainsRune(vowels, rune(s[i-1])) {
+				t.Fatalf("Pronounceable(11): got %q, want alternating consonants and vowels", s)
+			}
+		}
+	}
+
+	if _, err := genpass.Pronounceable(0); !errors.Is(err, genpass.ErrInvalidLength) {
+		t.Errorf("Pronounceable(0): got err %v, want %v", err, genpass.ErrInvalidLength)
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	s, err := genpass.Generate(genpass.Options{Mode: genpass.ModePassphrase, Words: 5, Separator: " ", Capitalize: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	words := strings.Split(s, " ")
+	if len(words) != 5 {
+		t.Fatalf("Passphrase(): got %q, want 5 words", s)
+	}
+
+	for _, w := range words {
+		if !slices.Contains(genpass.DefaultWordlist, strings.ToLower(w[:1])+w[1:]) || w[:1] != strings.ToUpper(w[:1]) {
+			t.Errorf("Passphrase(): got word %q, want a capitalized word of the default wordlist", w)
+		}
+	}
+
+	if _, err := genpass.Passphrase(0, "-", false, nil); !errors.Is(err, genpass.ErrInvalidWords) {
+		t.Errorf("Passphrase(0): got err %v, want %v", err, genpass.ErrInvalidWords)
+	}
+
+	if len(genpass.DefaultWordlist) != 1296 {
+		t.Errorf("DefaultWordlist: got %d words, want 1296", len(genpass.DefaultWordlist))
+	}
+}
+
+func TestParseWordlist(t *testing.T) {
+	var numbered, plain strings.Builder
+
+	for i := range genpass.MinWordlistLen {
+		fmt.Fprintf(&numbered, "%05d\tword%d\n", 11111+i%6, i)
+		fmt.Fprintf(&plain, "word%d\n\n", i)
+	}
+
+	for name, input := range map[string]string{"numbered": numbered.String(), "plain": plain.String()} {
+		got, err := genpass.ParseWordlist(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if len(got) != genpass.MinWordlistLen || got[0] != "word0" {
+			t.Errorf("%s: got %d words starting with %q", name, len(got), got[0])
+		}
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{name: "too short", input: "a\nb\nc\n", want: genpass.ErrWordlistTooShort},
+		{name: "repeated words", input: strings.Repeat("a\n", genpass.MinWordlistLen), want: genpass.ErrWordlistTooShort},
+		{name: "invalid line", input: "11111 two words\n", want: genpass.ErrInvalidWordlist},
+	}
+
+	for _, tt := range tests {
+		if _, err := genpass.ParseWordlist(strings.NewReader(tt.input)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got err %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Package gpg provides utilities to sign, verify and encrypt data
+// using the external `gpg` command.
+package gpg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const defaultCmd = "gpg"
+
+var (
+	// ErrBadSignature indicates that a signature failed verification.
+	ErrBadSignature = errors.New("gpg: bad signature")
+
+	// ErrAmbiguousKey indicates that a key specification matches more than one key.
+	ErrAmbiguousKey = errors.New("gpg: ambiguous key")
+)
+
+// CommandError wraps a failed gpg invocation along with its stderr output.
+type CommandError struct {
+	Op     string
+	Stderr string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	msg := "gpg: " + e.Op + ": " + e.Err.Error()
+	if s := strings.TrimSpace(e.Stderr); len(s) > 0 {
+		msg += ": " + s
+	}
+
+	return msg
+}
+
+func (e *CommandError) Unwrap() error { return e.Err }
+
+// Sign returns an ASCII armored detached signature over data
+// created with the given key.
+func Sign(ctx context.Context, key string, data []byte) ([]byte, error) {
+	return run(ctx, "sign", data, "--batch", "--yes", "--armor", "--local-user", key, "--detach-sign")
+}
+
+// Encrypt returns data encrypted to the given recipients, in binary form,
+// as done by pass(1).
+func Encrypt(ctx context.Context, recipients []string, data []byte) ([]byte, error) {
+	args := []string{"--batch", "--yes", "--quiet", "--compress-algo=none", "--no-encrypt-to", "--encrypt"}
+	for _, r := range recipients {
+		args = append(args, "--recipient", r)
+	}
+
+	return run(ctx, "encrypt", data, args...)
+}
+
+// Decrypt returns the decrypted data, e.g., of a pass(1) entry. Passphrases
+// of secret keys are prompted for by the gpg agent.
+func Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	return run(ctx, "decrypt", data, "--batch", "--quiet", "--yes", "--decrypt")
+}
+
+// Verify checks the detached signature over data, and returns the
+// fingerprint of the primary key of the signer.
+//
+// It returns [ErrBadSignature] if the signature does not match
+// or was not made by a trusted key in the local keyring. Any trusted
+// key is accepted, callers are expected to compare the returned
+// fingerprint against the expected signer, see [Fingerprint].
+func Verify(ctx context.Context, signature []byte, data []byte) (string, error) {
+	f, err := os.CreateTemp("", "vlt-*.sig")
+	if err != nil {
+		return "", fmt.Errorf("gpg: verify: %w", err)
+	}
+	defer func() { //nolint:wsl
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	if _, err := f.Write(signature); err != nil {
+		return "", fmt.Errorf("gpg: verify: %w", err)
+	}
+
+	status, err := run(ctx, "verify", data, "--batch", "--status-fd", "1", "--verify", f.Name(), "-")
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("%w: %w", ErrBadSignature, err)
+		}
+
+		return "", err
+	}
+
+	// [GNUPG:] VALIDSIG <fpr> <date> <timestamp> <expire> <version> <reserved>
+	// <pubkey-algo> <hash-algo> <sig-class> <primary-fpr>
+	for line := range strings.Lines(string(status)) {
+		fields := strings.Fields(line)
+		if len(fields) >= 12 && fields[0] == "[GNUPG:]" && fields[1] == "VALIDSIG" {
+			return fields[11], nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: gpg: verify: no valid signature reported", ErrBadSignature)
+}
+
+// Fingerprint returns the fingerprint of the primary key matching the given
+// key id, fingerprint or user id in the local keyring.
+//
+// It returns [ErrAmbiguousKey] if more than one key matches.
+func Fingerprint(ctx context.Context, key string) (string, error) {
+	out, err := run(ctx, "fingerprint", nil, "--batch", "--with-colons", "--fingerprint", "--", key)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		fingerprints []string
+		primary      bool // primary reports whether the last key record is a primary key.
+	)
+
+	for line := range strings.Lines(string(out)) {
+		fields := strings.Split(strings.TrimSpace(line), ":")
+
+		switch fields[0] {
+		case "pub":
+			primary = true
+		case "sub":
+			primary = false
+		case "fpr":
+			if primary && len(fields) > 9 {
+				fingerprints = append(fingerprints, fields[9])
+				primary = false
+			}
+		}
+	}
+
+	switch len(fingerprints) {
+	case 0:
+		return "", fmt.Errorf("gpg: fingerprint: no key matches %q", key)
+	case 1:
+		return fingerprints[0], nil
+	default:
+		return "", fmt.Errorf("%w: %q matches %d keys", ErrAmbiguousKey, key, len(fingerprints))
+	}
+}
+
+func run(ctx context.Context, op string, stdin []byte, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(defaultCmd); err != nil {
+		return nil, &CommandError{Op: op, Err: err}
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, defaultCmd, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, &CommandError{Op: op, Stderr: stderr.String(), Err: err}
+	}
+
+	return stdout.Bytes(), nil
+}
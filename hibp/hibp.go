@@ -0,0 +1,120 @@
+// Package hibp implements a client for the Have I Been Pwned
+// Pwned Passwords range API.
+//
+// Passwords are never sent, only the first 5 characters of their SHA-1 hash
+// are, following the k-anonymity model described at
+// https://haveibeenpwned.com/API/v3#PwnedPasswords.
+package hibp
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1" //nolint:gosec // required by the range API, not used for security.
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the base URL of the Pwned Passwords API.
+	DefaultBaseURL = "https://api.pwnedpasswords.com"
+
+	// defaultTimeout bounds a single range request.
+	defaultTimeout = 30 * time.Second
+
+	userAgent = "vlt-cli"
+)
+
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("hibp: unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Client queries the Pwned Passwords range API.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+type Opt func(*Client)
+
+// WithBaseURL overrides [DefaultBaseURL].
+func WithBaseURL(u string) Opt {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(u, "/")
+	}
+}
+
+// WithHTTPClient sets the http client used for requests.
+func WithHTTPClient(h *http.Client) Opt {
+	return func(c *Client) {
+		c.http = h
+	}
+}
+
+// New returns a new [Client] configured with the given options.
+func New(opts ...Opt) *Client {
+	c := &Client{
+		baseURL: DefaultBaseURL,
+		http:    &http.Client{Timeout: defaultTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Count returns the number of times the password appears in known breaches.
+// A zero count means the password was not found.
+func (c *Client) Count(ctx context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/range/"+prefix, http.NoBody)
+	if err != nil {
+		return 0, fmt.Errorf("hibp: %w", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Add-Padding", "true") // hides the real response size.
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("hibp: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:wsl
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		s, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(s, suffix) {
+			continue
+		}
+
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return 0, fmt.Errorf("hibp: parse count: %w", err)
+		}
+
+		return n, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("hibp: read response: %w", err)
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,45 @@
+package hibp_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/hibp"
+)
+
+func TestClient_Count(t *testing.T) {
+	// SHA-1("password") = 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/range/5BAA6" {
+			fmt.Fprint(w, "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:0\r\n")
+			return
+		}
+
+		fmt.Fprint(w, "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n")
+		fmt.Fprint(w, "1E4C9B93F3F0682250B6CF8331B7EE68FD8:10434004\r\n")
+		fmt.Fprint(w, "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:0\r\n")
+	}))
+	t.Cleanup(srv.Close)
+
+	c := hibp.New(hibp.WithBaseURL(srv.URL), hibp.WithHTTPClient(srv.Client()))
+
+	n, err := c.Count(t.Context(), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 10434004 {
+		t.Errorf("Count(password) = %d, want %d", n, 10434004)
+	}
+
+	n, err = c.Count(t.Context(), "not-breached")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 0 {
+		t.Errorf("Count(not-breached) = %d, want 0", n)
+	}
+}
@@ -0,0 +1,104 @@
+// Package identity defines the personal identities stored as secrets.
+//
+// Identities are stored as flat JSON objects holding the fields of [Identity],
+// so that their fields can be selected as the ones of any other JSON secret.
+package identity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Label marks the secrets holding identities.
+const Label = "type=identity"
+
+// DateLayout is the layout of the identity dates.
+const DateLayout = time.DateOnly
+
+// ErrInvalidDate indicates a date not formatted as [DateLayout].
+var ErrInvalidDate = errors.New("invalid date (expected YYYY-MM-DD)")
+
+// Identity holds the details of a person.
+type Identity struct {
+	Title      string `json:"title,omitempty"`
+	FirstName  string `json:"first_name,omitempty"`
+	MiddleName string `json:"middle_name,omitempty"`
+	LastName   string `json:"last_name,omitempty"`
+	BirthDate  string `json:"birth_date,omitempty"`
+
+	Email   string `json:"email,omitempty"`
+	Phone   string `json:"phone,omitempty"`
+	Company string `json:"company,omitempty"`
+
+	// Address holds the street lines of the address, separated by newlines.
+	Address    string `json:"address,omitempty"`
+	City       string `json:"city,omitempty"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+
+	NationalID     string `json:"national_id,omitempty"`
+	PassportNumber string `json:"passport_number,omitempty"`
+	PassportExpiry string `json:"passport_expiry,omitempty"`
+	LicenseNumber  string `json:"license_number,omitempty"`
+}
+
+// Fields lists the JSON fields of an identity, in display order.
+var Fields = []string{
+	"title", "first_name", "middle_name", "last_name", "birth_date",
+	"email", "phone", "company",
+	"address", "city", "state", "postal_code", "country",
+	"national_id", "passport_number", "passport_expiry", "license_number",
+}
+
+// Parse parses an identity stored as a JSON object.
+func Parse(value string) (Identity, error) {
+	var id Identity
+	if err := json.Unmarshal([]byte(value), &id); err != nil {
+		return Identity{}, fmt.Errorf("parse identity: %w", err)
+	}
+
+	return id, nil
+}
+
+// Validate reports whether the identity has a name, and valid dates.
+func (id *Identity) Validate() error {
+	if len(id.FullName()) == 0 {
+		return errors.New("identity has no name")
+	}
+
+	for _, d := range []string{id.BirthDate, id.PassportExpiry} {
+		if _, err := time.Parse(DateLayout, d); len(d) > 0 && err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidDate, d)
+		}
+	}
+
+	return nil
+}
+
+// FullName returns the title and names of the identity, separated by spaces.
+func (id *Identity) FullName() string {
+	return join(" ", id.Title, id.FirstName, id.MiddleName, id.LastName)
+}
+
+// Locality returns the city, state and postal code of the address,
+// e.g., "Springfield, IL 62701".
+func (id *Identity) Locality() string {
+	return join(", ", id.City, join(" ", id.State, id.PostalCode))
+}
+
+// join joins the non-empty elements using sep.
+func join(sep string, elems ...string) string {
+	nonEmpty := make([]string, 0, len(elems))
+
+	for _, e := range elems {
+		if e = strings.TrimSpace(e); len(e) > 0 {
+			nonEmpty = append(nonEmpty, e)
+		}
+	}
+
+	return strings.Join(nonEmpty, sep)
+}
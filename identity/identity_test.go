@@ -0,0 +1,44 @@
+package identity_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/identity"
+)
+
+func TestIdentity_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity identity.Identity
+		wantErr  error
+	}{
+		{name: "valid", identity: identity.Identity{FirstName: "Jane", BirthDate: "1990-01-31"}},
+		{name: "invalid birth date", identity: identity.Identity{FirstName: "Jane", BirthDate: "31/01/1990"}, wantErr: identity.ErrInvalidDate},
+		{name: "invalid passport expiry", identity: identity.Identity{LastName: "Doe", PassportExpiry: "2030-13-01"}, wantErr: identity.ErrInvalidDate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.identity.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := (&identity.Identity{Email: "jane@example.com"}).Validate(); err == nil {
+		t.Error("Validate() of an identity without name: got nil err")
+	}
+}
+
+func TestIdentity_Format(t *testing.T) {
+	id := identity.Identity{Title: "Dr.", FirstName: "Jane", LastName: "Doe", City: "Springfield", State: "IL", PostalCode: "62701"}
+
+	if got, want := id.FullName(), "Dr. Jane Doe"; got != want {
+		t.Errorf("FullName() = %q, want %q", got, want)
+	}
+
+	if got, want := id.Locality(), "Springfield, IL 62701"; got != want {
+		t.Errorf("Locality() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,291 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ladzaretti/vlt-cli/card"
+	"github.com/ladzaretti/vlt-cli/identity"
+)
+
+// Bitwarden item types.
+const (
+	bitwardenLogin    = 1
+	bitwardenNote     = 2
+	bitwardenCard     = 3
+	bitwardenIdentity = 4
+)
+
+// bitwardenCSVColumns are the columns of Bitwarden CSV exports, other than
+// the leading folder and favorite, or collections for organization exports.
+const bitwardenCSVColumns = "type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp"
+
+// Bitwarden is the format of unencrypted Bitwarden JSON exports.
+//
+// Logins are imported as secrets named by their username, or else by the
+// item name, labeled by the item name and URIs. Their username, first URI
+// and notes are kept as the secret metadata. Cards and identities are
+// imported as JSON secrets labeled [card.Label] and [identity.Label], and
+// secure notes as secrets holding the note. Items are labeled by their folder.
+var Bitwarden = Format{
+	Name:        "bitwarden",
+	Description: "Bitwarden unencrypted JSON export",
+	Sniff: func(head []byte) bool {
+		return bytes.HasPrefix(bytes.TrimSpace(head), []byte("{")) && bytes.Contains(head, []byte(`"encrypted"`))
+	},
+	Parse: parseBitwarden,
+}
+
+type bitwardenExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items []bitwardenItem `json:"items"`
+}
+
+type bitwardenItem struct {
+	Type     int    `json:"type"`
+	Name     string `json:"name"`
+	Notes    string `json:"notes"`
+	FolderID string `json:"folderId"`
+	Login    *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	Card *struct {
+		CardholderName string `json:"cardholderName"`
+		Number         string `json:"number"`
+		ExpMonth       string `json:"expMonth"`
+		ExpYear        string `json:"expYear"`
+		Code           string `json:"code"`
+	} `json:"card"`
+	Identity *struct {
+		Title          string `json:"title"`
+		FirstName      string `json:"firstName"`
+		MiddleName     string `json:"middleName"`
+		LastName       string `json:"lastName"`
+		Address1       string `json:"address1"`
+		Address2       string `json:"address2"`
+		Address3       string `json:"address3"`
+		City           string `json:"city"`
+		State          string `json:"state"`
+		PostalCode     string `json:"postalCode"`
+		Country        string `json:"country"`
+		Company        string `json:"company"`
+		Email          string `json:"email"`
+		Phone          string `json:"phone"`
+		SSN            string `json:"ssn"`
+		PassportNumber string `json:"passportNumber"`
+		LicenseNumber  string `json:"licenseNumber"`
+	} `json:"identity"`
+}
+
+func parseBitwarden(in io.Reader) ([]Record, error) {
+	var export bitwardenExport
+	if err := json.NewDecoder(in).Decode(&export); err != nil {
+		return nil, fmt.Errorf("parse bitwarden export: %w", err)
+	}
+
+	if export.Encrypted {
+		return nil, errors.New("encrypted bitwarden exports are not supported, export as unencrypted JSON")
+	}
+
+	folders := make(map[string]string, len(export.Folders))
+	for _, f := range export.Folders {
+		folders[f.ID] = f.Name
+	}
+
+	var records []Record
+
+	for _, item := range export.Items {
+		r, ok, err := item.record()
+		if err != nil {
+			return nil, fmt.Errorf("item %q: %w", item.Name, err)
+		}
+
+		if !ok {
+			continue
+		}
+
+		r.Labels = nonEmpty(append(r.Labels, folders[item.FolderID]))
+		records = append(records, r)
+	}
+
+	return records, nil
+}
+
+// record returns the record of the item,
+// or false if the item holds no secret.
+func (item bitwardenItem) record() (Record, bool, error) {
+	switch {
+	case item.Type == bitwardenLogin && item.Login != nil && len(item.Login.Password) > 0:
+		uris := make([]string, 0, len(item.Login.URIs))
+		for _, u := range item.Login.URIs {
+			uris = append(uris, u.URI)
+		}
+
+		return bitwardenLoginRecord(item.Name, item.Login.Username, item.Login.Password, uris, item.Notes), true, nil
+	case item.Type == bitwardenNote && len(item.Notes) > 0:
+		return Record{Name: item.Name, Secret: item.Notes}, true, nil
+	case item.Type == bitwardenCard && item.Card != nil:
+		c := card.Card{
+			Number: item.Card.Number,
+			Expiry: item.Card.ExpMonth + "/" + item.Card.ExpYear,
+			CVV:    item.Card.Code,
+			Holder: item.Card.CardholderName,
+		}
+
+		return jsonRecord(item.Name, normalizeCard(c), card.Label)
+	case item.Type == bitwardenIdentity && item.Identity != nil:
+		id := item.Identity
+
+		return jsonRecord(item.Name, identity.Identity{
+			Title:          id.Title,
+			FirstName:      id.FirstName,
+			MiddleName:     id.MiddleName,
+			LastName:       id.LastName,
+			Email:          id.Email,
+			Phone:          id.Phone,
+			Company:        id.Company,
+			Address:        strings.Join(nonEmpty([]string{id.Address1, id.Address2, id.Address3}), "\n"),
+			City:           id.City,
+			State:          id.State,
+			PostalCode:     id.PostalCode,
+			Country:        id.Country,
+			NationalID:     id.SSN,
+			PassportNumber: id.PassportNumber,
+			LicenseNumber:  id.LicenseNumber,
+		}, identity.Label)
+	default:
+		return Record{}, false, nil
+	}
+}
+
+// bitwardenLoginRecord returns the record of a Bitwarden login item.
+func bitwardenLoginRecord(name, username, password string, uris []string, notes string) Record {
+	uris = nonEmpty(uris)
+
+	r := Record{Name: name, Secret: password, Username: username, Notes: notes}
+	if len(username) > 0 {
+		r.Name, r.Labels = username, []string{name}
+	}
+
+	r.Labels = append(r.Labels, uris...)
+
+	if len(uris) > 0 {
+		r.URL = uris[0]
+	}
+
+	return r
+}
+
+// BitwardenCSV is the format of Bitwarden CSV exports, of personal vaults or
+// of organizations. Logins and secure notes are imported like [Bitwarden]
+// ones, labeled by their folder or collections. Other items are not part of
+// CSV exports.
+var BitwardenCSV = Format{
+	Name:        "bitwarden-csv",
+	Description: "Bitwarden CSV export",
+	Sniff: func(head []byte) bool {
+		head = bytes.TrimPrefix(head, []byte("\ufeff"))
+
+		line, _, _ := bytes.Cut(head, []byte("\n"))
+		line = bytes.TrimSuffix(line, []byte("\r"))
+
+		return bytes.HasSuffix(line, []byte(","+bitwardenCSVColumns))
+	},
+	Parse: parseBitwardenCSV,
+}
+
+func parseBitwardenCSV(in io.Reader) ([]Record, error) {
+	r := csv.NewReader(in)
+	r.FieldsPerRecord = -1 // fields are looked up by name, missing ones are empty.
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("parse bitwarden csv export: %w", err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.TrimPrefix(h, "\ufeff")] = i
+	}
+
+	field := func(row []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(row) {
+			return row[i]
+		}
+
+		return ""
+	}
+
+	var records []Record
+
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("parse bitwarden csv export: %w", err)
+		}
+
+		name, notes := field(row, "name"), field(row, "notes")
+
+		var rec Record
+
+		switch field(row, "type") {
+		case "login":
+			password := field(row, "login_password")
+			if len(password) == 0 {
+				continue
+			}
+
+			rec = bitwardenLoginRecord(name, field(row, "login_username"), password, strings.Split(field(row, "login_uri"), ","), notes)
+		case "note":
+			if len(notes) == 0 {
+				continue
+			}
+
+			rec = Record{Name: name, Secret: notes}
+		default:
+			continue
+		}
+
+		// organization exports list the collections of items, comma separated.
+		rec.Labels = nonEmpty(append(append(rec.Labels, field(row, "folder")), strings.Split(field(row, "collections"), ",")...))
+		records = append(records, rec)
+	}
+}
+
+// jsonRecord returns the record of a secret holding v as a JSON object.
+func jsonRecord(name string, v any, labels ...string) (Record, bool, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	return Record{Name: name, Secret: string(b), Labels: labels}, true, nil
+}
+
+// normalizeCard returns the normalized card, or the card as is
+// if it is invalid, not to lose the exported data.
+func normalizeCard(c card.Card) card.Card {
+	normalized := c
+	if err := normalized.Normalize(); err != nil {
+		return c
+	}
+
+	return normalized
+}
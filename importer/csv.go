@@ -0,0 +1,148 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// firefoxHeader is the CSV header of exported Firefox passwords.
+	firefoxHeader = "url,username,password,httpRealm,formActionOrigin,guid,timeCreated,timeLastUsed,timePasswordChanged"
+
+	// chromiumHeader is the CSV header of exported Chromium passwords.
+	chromiumHeader = "name,url,username,password,note"
+)
+
+var (
+	// Firefox is the format of exported Firefox passwords.
+	Firefox = CSVFormat("firefox", "Firefox passwords CSV export", firefoxHeader, CSVColumns{
+		Name:   1,
+		Secret: 2,
+		Labels: []int{0, 3, 4},
+	})
+
+	// Chromium is the format of exported Chromium passwords.
+	Chromium = CSVFormat("chromium", "Chromium-based browsers passwords CSV export", chromiumHeader, CSVColumns{
+		Name:   2,
+		Secret: 3,
+		Labels: []int{0, 1, 4},
+	})
+)
+
+// NewDefaultRegistry returns a [Registry] holding the built-in formats.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	r.Register(Firefox)
+	r.Register(Chromium)
+	r.Register(Bitwarden)
+	r.Register(BitwardenCSV)
+	r.Register(OnePassword)
+
+	return r
+}
+
+// CSVColumns are the zero-based indexes of the columns
+// the fields of a record are read from.
+type CSVColumns struct {
+	Name     int
+	Secret   int
+	Labels   []int  // Labels are the label columns, empty labels are skipped.
+	LabelSep string // LabelSep splits label columns holding several labels, if set.
+}
+
+func (c CSVColumns) validate(header []string) error {
+	if c.Name < 0 || c.Name >= len(header) {
+		return fmt.Errorf("name index %d is out of range (record has %d columns)", c.Name, len(header))
+	}
+
+	if c.Secret < 0 || c.Secret >= len(header) {
+		return fmt.Errorf("secret index %d is out of range (record has %d columns)", c.Secret, len(header))
+	}
+
+	for _, i := range c.Labels {
+		if i < 0 || i >= len(header) {
+			return fmt.Errorf("label index %d is out of range (record has %d columns)", i, len(header))
+		}
+	}
+
+	return nil
+}
+
+func (c CSVColumns) record(row []string) Record {
+	r := Record{
+		Name:   row[c.Name],
+		Secret: row[c.Secret],
+		Labels: make([]string, 0, len(c.Labels)),
+	}
+
+	for _, i := range c.Labels {
+		if len(c.LabelSep) > 0 {
+			r.Labels = append(r.Labels, strings.Split(row[i], c.LabelSep)...)
+			continue
+		}
+
+		r.Labels = append(r.Labels, row[i])
+	}
+
+	r.Labels = nonEmpty(r.Labels)
+
+	return r
+}
+
+// ParseCSV returns the records of the given CSV input, read from the given columns.
+// The first row is a header, its number of columns is expected of every row.
+func ParseCSV(in io.Reader, cols CSVColumns) ([]Record, error) {
+	r := csv.NewReader(in)
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("empty input, expected a CSV header")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cols.validate(header); err != nil {
+		return nil, err
+	}
+
+	var records []Record
+
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, cols.record(row))
+	}
+}
+
+// CSVFormat returns the format of CSV files starting with the given header row,
+// whose records are read from the given columns.
+func CSVFormat(name string, description string, header string, cols CSVColumns) Format {
+	return Format{
+		Name:        name,
+		Description: description,
+		Sniff: func(head []byte) bool {
+			head = bytes.TrimPrefix(head, []byte("\ufeff")) // byte order mark, as written by some spreadsheets.
+
+			line, _, _ := bytes.Cut(head, []byte("\n"))
+
+			return string(bytes.TrimSuffix(line, []byte("\r"))) == header
+		},
+		Parse: func(r io.Reader) ([]Record, error) {
+			return ParseCSV(r, cols)
+		},
+	}
+}
@@ -0,0 +1,163 @@
+// Package importer converts password data exported by other password
+// managers to vault secrets.
+//
+// Import formats are registered with a [Registry], each with a sniff function
+// detecting its input and a parser converting it to normalized [Record]s, so
+// new formats are added without changes to the import command.
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// sniffLen is the length of the input head passed to sniff functions.
+const sniffLen = 4096
+
+// ErrUnknownFormat indicates input not detected as any registered format.
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// Record is a normalized imported secret.
+type Record struct {
+	Name   string
+	Secret string
+	Labels []string
+
+	// URL, Username, Notes and Kind are the optional metadata of the secret,
+	// set by formats carrying them.
+	URL      string
+	Username string
+	Notes    string
+	Kind     string
+}
+
+// Prefixed returns a copy of the record, its name prefixed by namePrefix,
+// and its labels by labelPrefix. Unlabeled records are labeled by labelPrefix
+// itself, so that all prefixed records have a label matching 'labelPrefix*'.
+func (r Record) Prefixed(namePrefix, labelPrefix string) Record {
+	r.Name = namePrefix + r.Name
+
+	if len(labelPrefix) == 0 {
+		return r
+	}
+
+	if len(r.Labels) == 0 {
+		r.Labels = []string{labelPrefix}
+		return r
+	}
+
+	labels := make([]string, len(r.Labels))
+	for i, l := range r.Labels {
+		labels[i] = labelPrefix + l
+	}
+
+	r.Labels = labels
+
+	return r
+}
+
+// Format is an import format.
+type Format struct {
+	// Name identifies the format, e.g., for explicit selection.
+	Name string
+
+	// Description is a short human readable description of the format.
+	Description string
+
+	// Sniff reports whether the input starting with head, up to
+	// 4096 bytes long, is in the format. Formats with no sniff
+	// function are never detected and must be selected by name.
+	Sniff func(head []byte) bool
+
+	// Parse returns the records of the input.
+	Parse func(r io.Reader) ([]Record, error)
+}
+
+// Registry holds import formats, in registration order.
+type Registry struct {
+	formats []Format
+}
+
+// NewRegistry returns an empty [Registry].
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register registers f, replacing any format of the same name.
+func (r *Registry) Register(f Format) {
+	if i := slices.IndexFunc(r.formats, func(g Format) bool { return g.Name == f.Name }); i >= 0 {
+		r.formats[i] = f
+		return
+	}
+
+	r.formats = append(r.formats, f)
+}
+
+// Formats returns the registered formats, in registration order.
+func (r *Registry) Formats() []Format {
+	return slices.Clone(r.formats)
+}
+
+// Names returns the names of the registered formats, in registration order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.formats))
+	for _, f := range r.formats {
+		names = append(names, f.Name)
+	}
+
+	return names
+}
+
+// Lookup returns the format of the given name, if registered.
+func (r *Registry) Lookup(name string) (Format, bool) {
+	i := slices.IndexFunc(r.formats, func(f Format) bool { return f.Name == name })
+	if i < 0 {
+		return Format{}, false
+	}
+
+	return r.formats[i], true
+}
+
+// Provider returns a registry of the formats of the given provider, in
+// registration order: the format of the given name, and those named by it
+// followed by a dash, e.g., "bitwarden" and "bitwarden-csv".
+func (r *Registry) Provider(name string) *Registry {
+	p := NewRegistry()
+
+	for _, f := range r.formats {
+		if f.Name == name || strings.HasPrefix(f.Name, name+"-") {
+			p.formats = append(p.formats, f)
+		}
+	}
+
+	return p
+}
+
+// Detect returns the first registered format whose sniff function matches
+// the head of the given input, along with a reader of the whole input.
+// It returns [ErrUnknownFormat] if no format matches.
+func (r *Registry) Detect(in io.Reader) (Format, io.Reader, error) {
+	br := bufio.NewReaderSize(in, sniffLen)
+
+	head, err := br.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return Format{}, nil, err
+	}
+
+	for _, f := range r.formats {
+		if f.Sniff != nil && f.Sniff(head) {
+			return f, br, nil
+		}
+	}
+
+	return Format{}, br, fmt.Errorf("%w (formats: %s)", ErrUnknownFormat, strings.Join(r.Names(), ", "))
+}
+
+// nonEmpty returns the non-empty labels.
+func nonEmpty(labels []string) []string {
+	return slices.DeleteFunc(labels, func(l string) bool { return len(l) == 0 })
+}
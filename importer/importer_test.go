@@ -0,0 +1,315 @@
+package importer_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/importer"
+)
+
+func TestDetect(t *testing.T) {
+	r := importer.NewDefaultRegistry()
+	r.Register(importer.Format{
+		Name:  "lines",
+		Sniff: func(head []byte) bool { return strings.HasPrefix(string(head), "#lines") },
+		Parse: func(in io.Reader) ([]importer.Record, error) {
+			b, err := io.ReadAll(in)
+			return []importer.Record{{Name: "all", Secret: string(b)}}, err
+		},
+	})
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "firefox", input: "url,username,password,httpRealm,formActionOrigin,guid,timeCreated,timeLastUsed,timePasswordChanged\r\n", want: "firefox"},
+		{name: "chromium with bom", input: "\ufeffname,url,username,password,note\n", want: "chromium"},
+		{name: "bitwarden", input: "{\n  \"encrypted\": false,\n  \"items\": []\n}", want: "bitwarden"},
+		{name: "bitwarden csv", input: "folder,favorite,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp\n", want: "bitwarden-csv"},
+		{name: "bitwarden organization csv", input: "collections,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp\r\n", want: "bitwarden-csv"},
+		{name: "registered", input: "#lines\nfoo", want: "lines"},
+		{name: "long input", input: "#lines\n" + strings.Repeat("x", 10000), want: "lines"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, in, err := r.Detect(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if f.Name != tt.want {
+				t.Errorf("Detect() = %s, want %s", f.Name, tt.want)
+			}
+
+			// the sniffed head is replayed.
+			if b, _ := io.ReadAll(in); string(b) != tt.input {
+				t.Errorf("Detect() reader returned %d bytes, want %d", len(b), len(tt.input))
+			}
+		})
+	}
+
+	if _, _, err := r.Detect(strings.NewReader("a,b\n1,2\n")); !errors.Is(err, importer.ErrUnknownFormat) {
+		t.Errorf("Detect(unknown): got err %v, want %v", err, importer.ErrUnknownFormat)
+	}
+
+	if got, want := r.Names(), []string{"firefox", "chromium", "bitwarden", "bitwarden-csv", "1password", "lines"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistry_Provider(t *testing.T) {
+	r := importer.NewDefaultRegistry()
+
+	if got, want := r.Provider("bitwarden").Names(), []string{"bitwarden", "bitwarden-csv"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Provider(bitwarden).Names() = %v, want %v", got, want)
+	}
+
+	if got, want := r.Provider("bitwarden-csv").Names(), []string{"bitwarden-csv"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Provider(bitwarden-csv).Names() = %v, want %v", got, want)
+	}
+
+	if got := r.Provider("bit").Names(); len(got) != 0 {
+		t.Errorf("Provider(bit).Names() = %v, want none", got)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	input := "url,name,password,tags\nhttps://a,alice,pw,\"x;y\"\nhttps://b,bob,pw2,\n"
+
+	got, err := importer.ParseCSV(strings.NewReader(input), importer.CSVColumns{Name: 1, Secret: 2, Labels: []int{0, 3}, LabelSep: ";"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []importer.Record{
+		{Name: "alice", Secret: "pw", Labels: []string{"https://a", "x", "y"}},
+		{Name: "bob", Secret: "pw2", Labels: []string{"https://b"}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCSV() = %+v, want %+v", got, want)
+	}
+
+	if _, err := importer.ParseCSV(strings.NewReader(input), importer.CSVColumns{Name: 1, Secret: 4}); err == nil {
+		t.Error("ParseCSV(secret index out of range): got nil err")
+	}
+
+	if _, err := importer.ParseCSV(strings.NewReader(""), importer.CSVColumns{}); err == nil {
+		t.Error("ParseCSV(empty): got nil err")
+	}
+}
+
+func TestBitwarden(t *testing.T) {
+	input := `{
+  "encrypted": false,
+  "folders": [{"id": "f1", "name": "work"}],
+  "items": [
+    {"type": 1, "name": "GitHub", "folderId": "f1", "notes": "2fa on phone", "login": {"username": "jane", "password": "pw", "uris": [{"uri": "https://github.com"}]}},
+    {"type": 1, "name": "empty", "login": {"username": "x", "password": ""}},
+    {"type": 2, "name": "note", "notes": "remember"},
+    {"type": 3, "name": "visa", "card": {"cardholderName": "Jane Doe", "number": "4242 4242 4242 4242", "expMonth": "3", "expYear": "2031", "code": "123"}},
+    {"type": 4, "name": "me", "identity": {"firstName": "Jane", "lastName": "Doe", "address1": "1 Main St", "address2": "Apt 2", "ssn": "123-45-6789", "passportNumber": "X1"}}
+  ]
+}`
+
+	got, err := importer.Bitwarden.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []importer.Record{
+		{Name: "jane", Secret: "pw", Labels: []string{"GitHub", "https://github.com", "work"}, URL: "https://github.com", Username: "jane", Notes: "2fa on phone"},
+		{Name: "note", Secret: "remember", Labels: []string{}},
+		{Name: "visa", Secret: `{"number":"4242424242424242","expiry":"03/31","cvv":"123","holder":"Jane Doe"}`, Labels: []string{"type=card"}},
+		{Name: "me", Secret: `{"first_name":"Jane","last_name":"Doe","address":"1 Main St\nApt 2","national_id":"123-45-6789","passport_number":"X1"}`, Labels: []string{"type=identity"}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+
+	if _, err := importer.Bitwarden.Parse(strings.NewReader(`{"encrypted": true}`)); err == nil {
+		t.Error("Parse(encrypted): got nil err")
+	}
+}
+
+func TestBitwardenCSV(t *testing.T) {
+	input := `folder,favorite,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp
+work,1,login,GitHub,2fa on phone,,0,"https://github.com,https://gist.github.com",jane,pw,
+,,login,router,,,0,,,admin,
+,,login,empty,,,0,,x,,
+,,note,note,remember,,0,,,,
+`
+
+	got, err := importer.BitwardenCSV.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []importer.Record{
+		{Name: "jane", Secret: "pw", Labels: []string{"GitHub", "https://github.com", "https://gist.github.com", "work"}, URL: "https://github.com", Username: "jane", Notes: "2fa on phone"},
+		{Name: "router", Secret: "admin", Labels: []string{}},
+		{Name: "note", Secret: "remember", Labels: []string{}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+
+	org := "collections,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp\n\"infra,ops\",login,db,,,0,,root,pw,\n"
+
+	got, err = importer.BitwardenCSV.Parse(strings.NewReader(org))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want = []importer.Record{{Name: "root", Secret: "pw", Labels: []string{"db", "infra", "ops"}, Username: "root"}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() of an organization export = %+v, want %+v", got, want)
+	}
+}
+
+func TestOnePassword(t *testing.T) {
+	data := `{"accounts": [{"vaults": [{"attrs": {"name": "Personal"}, "items": [
+  {"categoryUuid": "001", "overview": {"title": "GitHub", "url": "https://github.com", "tags": ["dev"]},
+   "details": {"loginFields": [{"value": "jane", "designation": "username"}, {"value": "pw", "designation": "password"}]}},
+  {"categoryUuid": "002", "overview": {"title": "visa"}, "details": {"sections": [{"fields": [
+    {"id": "cardholder", "value": {"string": "Jane Doe"}},
+    {"id": "ccnum", "value": {"creditCardNumber": "4242424242424242"}},
+    {"id": "cvv", "value": {"concealed": "123"}},
+    {"id": "expiry", "value": {"monthYear": 203103}}]}]}},
+  {"categoryUuid": "004", "overview": {"title": "me"}, "details": {"sections": [{"fields": [
+    {"id": "firstname", "value": {"string": "Jane"}},
+    {"id": "lastname", "value": {"string": "Doe"}},
+    {"id": "birthdate", "value": {"date": 633744000}},
+    {"id": "address", "value": {"address": {"street": "1 Main St", "city": "Springfield", "state": "IL", "zip": "62701", "country": "us"}}},
+    {"id": "defphone", "value": {"phone": ""}},
+    {"id": "cellphone", "value": {"phone": "555-0100"}},
+    {"id": "photo", "value": {"file": {"name": "me.png"}}}]}]}}
+]}]}]}`
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+
+	w, err := zw.Create("export.data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := io.WriteString(w, data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, in, err := importer.NewDefaultRegistry().Detect(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Name != importer.OnePassword.Name {
+		t.Fatalf("Detect() = %s, want %s", f.Name, importer.OnePassword.Name)
+	}
+
+	got, err := f.Parse(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []importer.Record{
+		{Name: "jane", Secret: "pw", Labels: []string{"GitHub", "https://github.com", "Personal", "dev"}},
+		{Name: "visa", Secret: `{"number":"4242424242424242","expiry":"03/31","cvv":"123","holder":"Jane Doe"}`, Labels: []string{"type=card", "Personal"}},
+		{Name: "me", Secret: `{"first_name":"Jane","last_name":"Doe","birth_date":"1990-01-31","phone":"555-0100","address":"1 Main St","city":"Springfield","state":"IL","postal_code":"62701","country":"us"}`, Labels: []string{"type=identity", "Personal"}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPassRecord(t *testing.T) {
+	tests := []struct {
+		path, content string
+		want          importer.Record
+		ok            bool
+	}{
+		{
+			path:    "work/aws/root.gpg",
+			content: "hunter2\nlogin: admin\nURL: https://aws.amazon.com\nmfa on phone\n",
+			want: importer.Record{
+				Name: "root", Secret: "hunter2", Labels: []string{"work/aws"},
+				Username: "admin", URL: "https://aws.amazon.com", Notes: "login: admin\nURL: https://aws.amazon.com\nmfa on phone",
+			},
+			ok: true,
+		},
+		{
+			path:    "github.gpg",
+			content: "s3cret",
+			want:    importer.Record{Name: "github", Secret: "s3cret"},
+			ok:      true,
+		},
+		{
+			path:    "notes/wifi.gpg",
+			content: "\nssid: home\r\nkey: 1234\r\n",
+			want:    importer.Record{Name: "wifi", Secret: "ssid: home\nkey: 1234", Labels: []string{"notes"}},
+			ok:      true,
+		},
+		{path: "empty.gpg", content: "\n"},
+	}
+
+	for _, tt := range tests {
+		got, ok := importer.PassRecord(tt.path, tt.content)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PassRecord(%q): got %+v, %v, want %+v, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRecord_Prefixed(t *testing.T) {
+	tests := []struct {
+		record      importer.Record
+		name, label string
+		want        importer.Record
+	}{
+		{
+			record: importer.Record{Name: "gh", Secret: "s", Labels: []string{"work", "dev"}},
+			name:   "lastpass:",
+			label:  "imported/",
+			want:   importer.Record{Name: "lastpass:gh", Secret: "s", Labels: []string{"imported/work", "imported/dev"}},
+		},
+		{
+			record: importer.Record{Name: "gh", Secret: "s"},
+			label:  "imported/",
+			want:   importer.Record{Name: "gh", Secret: "s", Labels: []string{"imported/"}},
+		},
+		{
+			record: importer.Record{Name: "gh", Secret: "s", Labels: []string{"work"}},
+			name:   "lp:",
+			want:   importer.Record{Name: "lp:gh", Secret: "s", Labels: []string{"work"}},
+		},
+	}
+
+	for _, tt := range tests {
+		labels := slices.Clone(tt.record.Labels)
+
+		if got := tt.record.Prefixed(tt.name, tt.label); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Prefixed(%q, %q): got %+v, want %+v", tt.name, tt.label, got, tt.want)
+		}
+
+		if !slices.Equal(tt.record.Labels, labels) {
+			t.Errorf("Prefixed(%q, %q): modified the record labels %v", tt.name, tt.label, tt.record.Labels)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/card"
+	"github.com/ladzaretti/vlt-cli/identity"
+)
+
+// 1Password item categories.
+const (
+	onePasswordLogin    = "001"
+	onePasswordCard     = "002"
+	onePasswordNote     = "003"
+	onePasswordIdentity = "004"
+)
+
+// onePasswordData is the name of the 1PUX archive member holding the items.
+const onePasswordData = "export.data"
+
+// OnePassword is the format of 1Password 1PUX exports.
+//
+// Logins are imported as secrets named by their username, or else by the item
+// title, labeled by the item title and URLs. Cards and identities are imported
+// as JSON secrets labeled [card.Label] and [identity.Label], and secure notes
+// as secrets holding the note. Items are labeled by their vault and tags.
+var OnePassword = Format{
+	Name:        "1password",
+	Description: "1Password 1PUX export",
+	Sniff: func(head []byte) bool {
+		return bytes.HasPrefix(head, []byte("PK\x03\x04")) && bytes.Contains(head, []byte("export."))
+	},
+	Parse: parseOnePassword,
+}
+
+type onePasswordExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Attrs struct {
+				Name string `json:"name"`
+			} `json:"attrs"`
+			Items []onePasswordItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+type onePasswordItem struct {
+	CategoryUUID string `json:"categoryUuid"`
+	Overview     struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+		URLs  []struct {
+			URL string `json:"url"`
+		} `json:"urls"`
+		Tags []string `json:"tags"`
+	} `json:"overview"`
+	Details struct {
+		LoginFields []struct {
+			Value       string `json:"value"`
+			Designation string `json:"designation"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		Sections   []struct {
+			Fields []struct {
+				ID    string                     `json:"id"`
+				Value map[string]json.RawMessage `json:"value"`
+			} `json:"fields"`
+		} `json:"sections"`
+	} `json:"details"`
+}
+
+type onePasswordAddress struct {
+	Street  string `json:"street"`
+	City    string `json:"city"`
+	State   string `json:"state"`
+	Zip     string `json:"zip"`
+	Country string `json:"country"`
+}
+
+func parseOnePassword(in io.Reader) ([]Record, error) {
+	b, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, fmt.Errorf("parse 1password export: %w", err)
+	}
+
+	f, err := archive.Open(onePasswordData)
+	if err != nil {
+		return nil, fmt.Errorf("parse 1password export: %w", err)
+	}
+	defer func() { _ = f.Close() }() //nolint:wsl
+
+	var export onePasswordExport
+	if err := json.NewDecoder(f).Decode(&export); err != nil {
+		return nil, fmt.Errorf("parse 1password export: %w", err)
+	}
+
+	var records []Record
+
+	for _, account := range export.Accounts {
+		for _, v := range account.Vaults {
+			for _, item := range v.Items {
+				r, ok, err := item.record()
+				if err != nil {
+					return nil, fmt.Errorf("item %q: %w", item.Overview.Title, err)
+				}
+
+				if !ok {
+					continue
+				}
+
+				r.Labels = nonEmpty(append(append(r.Labels, v.Attrs.Name), item.Overview.Tags...))
+				records = append(records, r)
+			}
+		}
+	}
+
+	return records, nil
+}
+
+// record returns the record of the item,
+// or false if the item holds no secret.
+func (item onePasswordItem) record() (Record, bool, error) {
+	title := item.Overview.Title
+
+	switch item.CategoryUUID {
+	case onePasswordLogin:
+		var username, password string
+
+		for _, f := range item.Details.LoginFields {
+			switch f.Designation {
+			case "username":
+				username = f.Value
+			case "password":
+				password = f.Value
+			}
+		}
+
+		if len(password) == 0 {
+			return Record{}, false, nil
+		}
+
+		r := Record{Name: title, Secret: password}
+		if len(username) > 0 {
+			r.Name, r.Labels = username, []string{title}
+		}
+
+		r.Labels = append(r.Labels, item.Overview.URL)
+		for _, u := range item.Overview.URLs {
+			if u.URL != item.Overview.URL {
+				r.Labels = append(r.Labels, u.URL)
+			}
+		}
+
+		return r, true, nil
+	case onePasswordNote:
+		if len(item.Details.NotesPlain) == 0 {
+			return Record{}, false, nil
+		}
+
+		return Record{Name: title, Secret: item.Details.NotesPlain}, true, nil
+	case onePasswordCard:
+		fields, err := item.fields()
+		if err != nil {
+			return Record{}, false, err
+		}
+
+		c := card.Card{Number: fields["ccnum"], Expiry: fields["expiry"], CVV: fields["cvv"], Holder: fields["cardholder"]}
+
+		return jsonRecord(title, normalizeCard(c), card.Label)
+	case onePasswordIdentity:
+		fields, err := item.fields()
+		if err != nil {
+			return Record{}, false, err
+		}
+
+		var addr onePasswordAddress
+		if a := fields["address"]; len(a) > 0 {
+			if err := json.Unmarshal([]byte(a), &addr); err != nil {
+				return Record{}, false, fmt.Errorf("address: %w", err)
+			}
+		}
+
+		phone := fields["defphone"]
+		for _, k := range []string{"cellphone", "homephone", "busphone"} {
+			if len(phone) == 0 {
+				phone = fields[k]
+			}
+		}
+
+		return jsonRecord(title, identity.Identity{
+			FirstName:  fields["firstname"],
+			MiddleName: fields["initial"],
+			LastName:   fields["lastname"],
+			BirthDate:  fields["birthdate"],
+			Email:      fields["email"],
+			Phone:      phone,
+			Company:    fields["company"],
+			Address:    addr.Street,
+			City:       addr.City,
+			State:      addr.State,
+			PostalCode: addr.Zip,
+			Country:    addr.Country,
+		}, identity.Label)
+	default:
+		return Record{}, false, nil
+	}
+}
+
+// fields returns the section fields of the item by id, formatted as strings:
+// dates as [identity.DateLayout], month-years as MM/YY, and addresses
+// as their JSON objects. Values of other non-scalar kinds are empty.
+func (item onePasswordItem) fields() (map[string]string, error) {
+	fields := make(map[string]string)
+
+	for _, s := range item.Details.Sections {
+		for _, f := range s.Fields {
+			for kind, raw := range f.Value {
+				v, err := onePasswordValue(kind, raw)
+				if err != nil {
+					return nil, fmt.Errorf("field %q: %w", f.ID, err)
+				}
+
+				if len(v) > 0 {
+					fields[f.ID] = v
+				}
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+func onePasswordValue(kind string, raw json.RawMessage) (string, error) {
+	switch kind {
+	case "address":
+		return string(raw), nil
+	case "date":
+		var unix int64
+		if err := json.Unmarshal(raw, &unix); err != nil {
+			return "", err
+		}
+
+		return time.Unix(unix, 0).UTC().Format(identity.DateLayout), nil
+	case "monthYear":
+		var yyyymm int
+		if err := json.Unmarshal(raw, &yyyymm); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("%02d/%02d", yyyymm%100, yyyymm/100%100), nil
+	case "email":
+		var email struct {
+			Address string `json:"email_address"`
+		}
+		if err := json.Unmarshal(raw, &email); err != nil {
+			return "", err
+		}
+
+		return email.Address, nil
+	default:
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return s, nil
+		}
+
+		var n json.Number
+		if err := json.Unmarshal(raw, &n); err == nil {
+			return n.String(), nil
+		}
+
+		return "", nil // values of other kinds, e.g., files, are not imported.
+	}
+}
@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"path"
+	"strings"
+)
+
+// PassExt is the extension of pass(1) password store entries.
+const PassExt = ".gpg"
+
+// passMetaKeys maps the keys of the conventional "key: value" lines
+// following the password of pass entries to the fields they set.
+var passMetaKeys = map[string]string{
+	"login":    "username",
+	"username": "username",
+	"user":     "username",
+	"url":      "url",
+}
+
+// PassRecord returns the record of a pass(1) password store entry, given its
+// slash separated path relative to the store, e.g., "work/aws/root.gpg", and
+// its decrypted content.
+//
+// The directory of the entry is mapped to a label, e.g., "work/aws". Entries
+// follow the conventional layout: the password on the first line, the rest
+// being notes. "login:", "username:", "user:" and "url:" lines of the notes
+// set the username and the URL, as used by browserpass.
+//
+// Entries holding no password are imported as notes, entries holding neither
+// are skipped.
+func PassRecord(entryPath string, content string) (Record, bool) {
+	dir, file := path.Split(entryPath)
+
+	name := strings.TrimSuffix(file, PassExt)
+	if len(name) == 0 {
+		return Record{}, false
+	}
+
+	password, notes, _ := strings.Cut(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	notes = strings.TrimRight(notes, "\n")
+
+	r := Record{Name: name, Secret: password, Notes: notes}
+
+	if dir = strings.Trim(dir, "/"); len(dir) > 0 {
+		r.Labels = []string{dir}
+	}
+
+	for line := range strings.SplitSeq(notes, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+
+		switch passMetaKeys[strings.ToLower(strings.TrimSpace(key))] {
+		case "username":
+			if len(r.Username) == 0 {
+				r.Username = value
+			}
+		case "url":
+			if len(r.URL) == 0 {
+				r.URL = value
+			}
+		}
+	}
+
+	switch {
+	case len(password) > 0:
+		return r, true
+	case len(notes) > 0:
+		r.Secret, r.Notes = notes, ""
+		return r, true
+	default:
+		return Record{}, false
+	}
+}
@@ -0,0 +1,66 @@
+package input
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ErrNotTerminal indicates that the output is not a terminal.
+var ErrNotTerminal = errors.New("output is not a terminal")
+
+const (
+	escSaveCursor    = "\x1b7"
+	escRestoreCursor = "\x1b8"
+	escEraseBelow    = "\x1b[J"
+)
+
+// DisplayTimed writes s to the terminal w with file descriptor fd,
+// and erases it once d elapses or ctx is done, whichever comes first.
+//
+// Enough lines are reserved before saving the cursor position,
+// so the saved position stays valid even if the output would scroll.
+func DisplayTimed(ctx context.Context, w io.Writer, fd int, s string, d time.Duration) error {
+	if !term.IsTerminal(fd) {
+		return ErrNotTerminal
+	}
+
+	rows := displayRows(fd, s)
+
+	if _, err := fmt.Fprintf(w, "%s\x1b[%dA\r%s%s\n", strings.Repeat("\n", rows), rows, escSaveCursor, s); err != nil {
+		return err
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+
+	_, err := fmt.Fprint(w, escRestoreCursor+escEraseBelow)
+
+	return err
+}
+
+// displayRows returns the number of terminal rows s occupies on fd.
+func displayRows(fd int, s string) int {
+	width, _, err := term.GetSize(fd)
+	if err != nil || width <= 0 {
+		width = 80
+	}
+
+	rows := 0
+	for line := range strings.SplitSeq(s, "\n") {
+		rows += max(1, (utf8.RuneCountInString(line)+width-1)/width)
+	}
+
+	return rows
+}
@@ -17,6 +17,12 @@ func IsPipedOrRedirected(fi os.FileInfo) bool {
 	return (fi.Mode() & os.ModeCharDevice) == 0
 }
 
+// OpenTTY opens the controlling terminal of the process, for prompting
+// while stdin or stdout are piped or redirected.
+func OpenTTY() (*os.File, error) {
+	return os.OpenFile("/dev/tty", os.O_RDWR, 0)
+}
+
 // ReadTrim reads and trims input from r.
 func ReadTrim(r io.Reader) (string, error) {
 	bs, err := io.ReadAll(r)
@@ -45,7 +51,7 @@ func PromptRead(w io.Writer, r io.Reader, prompt string, a ...any) (string, erro
 // (hiding the input) from the given file descriptor.
 func PromptReadSecure(w io.Writer, fd int, prompt string, a ...any) (string, error) {
 	fmt.Fprintf(w, prompt, a...)
-	defer fmt.Println()
+	defer fmt.Fprintln(w)
 
 	bs, err := term.ReadPassword(fd)
 	if err != nil {
@@ -92,3 +98,26 @@ func PromptNewPassword(w io.Writer, fd int, length int) (string, error) {
 
 	return pass, nil
 }
+
+// PromptKey prompts the user via w and reads a single keypress from r,
+// putting the terminal with file descriptor fd into raw mode
+// so no newline is required.
+func PromptKey(w io.Writer, r io.Reader, fd int, prompt string, a ...any) (byte, error) {
+	fmt.Fprintf(w, prompt, a...)
+	defer fmt.Fprintln(w)
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return 0, fmt.Errorf("prompt key: %w", err)
+	}
+
+	defer func() { _ = term.Restore(fd, state) }() //nolint:wsl
+
+	var b [1]byte
+
+	if _, err := r.Read(b[:]); err != nil {
+		return 0, fmt.Errorf("prompt key: %w", err)
+	}
+
+	return b[0], nil
+}
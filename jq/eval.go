@@ -0,0 +1,379 @@
+package jq
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func literal(x any) filter {
+	return func(any) ([]any, error) { return []any{x}, nil }
+}
+
+// flatMap returns the concatenated outputs of g applied to each output of f.
+func flatMap(f filter, v any, g func(any) ([]any, error)) ([]any, error) {
+	in, err := f(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []any
+
+	for _, x := range in {
+		ys, err := g(x)
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, ys...)
+	}
+
+	return out, nil
+}
+
+func pipe(l, r filter) filter {
+	return func(v any) ([]any, error) { return flatMap(l, v, r) }
+}
+
+func comma(l, r filter) filter {
+	return func(v any) ([]any, error) {
+		a, err := l(v)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := r(v)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(a, b...), nil
+	}
+}
+
+func withSuffix(f filter, s suffix) filter {
+	return func(v any) ([]any, error) {
+		return flatMap(f, v, func(base any) ([]any, error) { return s(base, v) })
+	}
+}
+
+func collect(f filter) filter {
+	return func(v any) ([]any, error) {
+		out, err := f(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if out == nil {
+			out = []any{}
+		}
+
+		return []any{out}, nil
+	}
+}
+
+// index returns the element of base at the given array index or object key.
+func index(base, key any) ([]any, error) {
+	switch b := base.(type) {
+	case nil:
+		return []any{nil}, nil
+	case map[string]any:
+		if k, ok := key.(string); ok {
+			return []any{b[k]}, nil
+		}
+	case []any:
+		if n, ok := key.(float64); ok {
+			i := int(math.Floor(n))
+			if i < 0 {
+				i += len(b)
+			}
+
+			if i < 0 || i >= len(b) {
+				return []any{nil}, nil
+			}
+
+			return []any{b[i]}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot index %s with %s", typeName(base), describe(key))
+}
+
+// iterate returns the elements of an array, or the values of an object,
+// ordered by key.
+func iterate(base any) ([]any, error) {
+	switch b := base.(type) {
+	case []any:
+		return b, nil
+	case map[string]any:
+		out := make([]any, 0, len(b))
+		for _, k := range slices.Sorted(maps.Keys(b)) {
+			out = append(out, b[k])
+		}
+
+		return out, nil
+	}
+
+	return nil, fmt.Errorf("cannot iterate over %s", typeName(base))
+}
+
+func comparison(l, r filter, op string) filter {
+	return func(v any) ([]any, error) {
+		return flatMap(r, v, func(b any) ([]any, error) {
+			return flatMap(l, v, func(a any) ([]any, error) {
+				c := compare(a, b)
+
+				var result bool
+
+				switch op {
+				case "==":
+					result = c == 0
+				case "!=":
+					result = c != 0
+				case "<":
+					result = c < 0
+				case "<=":
+					result = c <= 0
+				case ">":
+					result = c > 0
+				default:
+					result = c >= 0
+				}
+
+				return []any{result}, nil
+			})
+		})
+	}
+}
+
+// logical returns l or r if or is set, or else l and r, short-circuiting.
+func logical(l, r filter, or bool) filter {
+	return func(v any) ([]any, error) {
+		return flatMap(l, v, func(a any) ([]any, error) {
+			if truthy(a) == or {
+				return []any{or}, nil
+			}
+
+			return flatMap(r, v, func(b any) ([]any, error) { return []any{truthy(b)}, nil })
+		})
+	}
+}
+
+func selectf(cond filter) filter {
+	return func(v any) ([]any, error) {
+		return flatMap(cond, v, func(c any) ([]any, error) {
+			if truthy(c) {
+				return []any{v}, nil
+			}
+
+			return nil, nil
+		})
+	}
+}
+
+func join(sep filter) filter {
+	return func(v any) ([]any, error) {
+		elems, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot join %s", typeName(v))
+		}
+
+		return flatMap(sep, v, func(s any) ([]any, error) {
+			sepStr, ok := s.(string)
+			if !ok {
+				return nil, fmt.Errorf("join: separator must be a string, got %s", typeName(s))
+			}
+
+			parts := make([]string, len(elems))
+
+			for i, e := range elems {
+				switch e := e.(type) {
+				case nil:
+				case string:
+					parts[i] = e
+				case float64, bool:
+					parts[i] = describe(e)
+				default:
+					return nil, fmt.Errorf("cannot join %s", typeName(e))
+				}
+			}
+
+			return []any{strings.Join(parts, sepStr)}, nil
+		})
+	}
+}
+
+func length(v any) ([]any, error) {
+	switch x := v.(type) {
+	case nil:
+		return []any{0.0}, nil
+	case float64:
+		return []any{math.Abs(x)}, nil
+	case string:
+		return []any{float64(utf8.RuneCountInString(x))}, nil
+	case []any:
+		return []any{float64(len(x))}, nil
+	case map[string]any:
+		return []any{float64(len(x))}, nil
+	}
+
+	return nil, fmt.Errorf("%s has no length", typeName(v))
+}
+
+func keys(v any) ([]any, error) {
+	switch x := v.(type) {
+	case map[string]any:
+		out := make([]any, 0, len(x))
+		for _, k := range slices.Sorted(maps.Keys(x)) {
+			out = append(out, k)
+		}
+
+		return []any{out}, nil
+	case []any:
+		out := make([]any, len(x))
+		for i := range x {
+			out[i] = float64(i)
+		}
+
+		return []any{out}, nil
+	}
+
+	return nil, fmt.Errorf("%s has no keys", typeName(v))
+}
+
+// object returns a filter constructing an object out of the given entries,
+// one for each combination of the entry outputs.
+func object(entries []objectEntry) filter {
+	return func(v any) ([]any, error) {
+		objects := []map[string]any{{}}
+
+		for _, e := range entries {
+			values, err := e.value(v)
+			if err != nil {
+				return nil, err
+			}
+
+			next := make([]map[string]any, 0, len(objects)*len(values))
+
+			for _, o := range objects {
+				for _, x := range values {
+					c := maps.Clone(o)
+					c[e.key] = x
+					next = append(next, c)
+				}
+			}
+
+			objects = next
+		}
+
+		out := make([]any, len(objects))
+		for i, o := range objects {
+			out[i] = o
+		}
+
+		return out, nil
+	}
+}
+
+// truthy reports whether v is neither false nor null.
+func truthy(v any) bool {
+	b, ok := v.(bool)
+	return v != nil && (!ok || b)
+}
+
+// rank orders the json types as jq does:
+// null, false, true, numbers, strings, arrays, then objects.
+func rank(v any) int {
+	switch x := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if x {
+			return 2
+		}
+
+		return 1
+	case float64:
+		return 3
+	case string:
+		return 4
+	case []any:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// compare returns an integer comparing a and b in jq order.
+func compare(a, b any) int {
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra - rb
+	}
+
+	switch x := a.(type) {
+	case float64:
+		y := b.(float64) //nolint:forcetypeassert // of the same rank.
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+
+		return 0
+	case string:
+		return strings.Compare(x, b.(string)) //nolint:forcetypeassert // of the same rank.
+	case []any:
+		return slices.CompareFunc(x, b.([]any), compare) //nolint:forcetypeassert // of the same rank.
+	case map[string]any:
+		y := b.(map[string]any) //nolint:forcetypeassert // of the same rank.
+
+		xk, yk := slices.Sorted(maps.Keys(x)), slices.Sorted(maps.Keys(y))
+		if c := slices.Compare(xk, yk); c != 0 {
+			return c
+		}
+
+		for _, k := range xk {
+			if c := compare(x[k], y[k]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return 0
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// describe returns a short representation of v, used in errors and joins.
+func describe(v any) string {
+	switch x := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+
+	return typeName(v)
+}
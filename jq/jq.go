@@ -0,0 +1,539 @@
+// Package jq evaluates a subset of the jq language over json values,
+// extracting values out of json output without requiring jq to be installed,
+// e.g., '.[] | select(.url != "") | {name, url}'.
+//
+// The supported filters are:
+//
+//	.                 the input, unchanged
+//	.foo, ."foo"      the value of an object key, null if missing
+//	.[N], .["foo"]    an array element, counting back from the end if negative, or an object key
+//	.[]               each element of an array, or value of an object
+//	f | g             the outputs of g applied to each output of f
+//	f, g              the outputs of f, followed by those of g
+//	[f]               an array of the outputs of f
+//	{a, b: f}         an object, holding the key a of the input, and the outputs of f as b
+//	f == g            comparisons, using ==, !=, <, <=, > and >=
+//	f and g, f or g   boolean operators, false and null being false
+//	"str", 1, true    literals, as well as null
+//	(f)               grouping
+//
+// along with the functions select(f), map(f), join(f), length, keys and not.
+package jq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Query is a parsed jq filter.
+type Query struct {
+	raw string
+	f   filter
+}
+
+// filter maps an input value to its outputs.
+type filter func(v any) ([]any, error)
+
+// Parse parses the given jq filter.
+func Parse(s string) (*Query, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	if p.peek().kind == tokEOF {
+		return nil, errors.New("empty filter")
+	}
+
+	f, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s", t)
+	}
+
+	return &Query{raw: s, f: f}, nil
+}
+
+// String returns the filter as given to [Parse].
+func (q *Query) String() string { return q.raw }
+
+// Run applies the filter to v, returning its outputs.
+// v is any value encodable as json, evaluated as encoded.
+func (q *Query) Run(v any) ([]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded any
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return nil, err
+	}
+
+	return q.f(decoded)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokIdent
+	tokField // tokField is a '.' immediately followed by an identifier, e.g., .foo.
+	tokString
+	tokNumber
+)
+
+type token struct {
+	kind tokenKind
+	text string // text is the punctuation or identifier, or the decoded string.
+	num  float64
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of filter"
+	case tokString:
+		return strconv.Quote(t.text)
+	case tokNumber:
+		return strconv.FormatFloat(t.num, 'g', -1, 64)
+	case tokField:
+		return fmt.Sprintf("%q", "."+t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// lex splits s into tokens, ending with a [tokEOF] token.
+func lex(s string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			end, err := stringEnd(s, i)
+			if err != nil {
+				return nil, err
+			}
+
+			var text string
+			if err := json.Unmarshal([]byte(s[i:end]), &text); err != nil {
+				return nil, fmt.Errorf("invalid string %s: %w", s[i:end], err)
+			}
+
+			tokens = append(tokens, token{kind: tokString, text: text})
+			i = end
+		case isDigit(c) || (c == '-' && i+1 < len(s) && isDigit(s[i+1]) && !endsOperand(tokens)):
+			end := i + 1
+			for end < len(s) && (isDigit(s[end]) || s[end] == '.' || s[end] == 'e' || s[end] == 'E') {
+				end++
+			}
+
+			n, err := strconv.ParseFloat(s[i:end], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", s[i:end])
+			}
+
+			tokens = append(tokens, token{kind: tokNumber, num: n})
+			i = end
+		case isIdentStart(c), c == '.' && i+1 < len(s) && isIdentStart(s[i+1]):
+			kind, start := tokIdent, i
+			if c == '.' {
+				kind, start = tokField, i+1
+			}
+
+			end := start + 1
+			for end < len(s) && (isIdentStart(s[end]) || isDigit(s[end])) {
+				end++
+			}
+
+			tokens = append(tokens, token{kind: kind, text: s[start:end]})
+			i = end
+		default:
+			if i+1 < len(s) && slices.Contains([]string{"==", "!=", "<=", ">="}, s[i:i+2]) {
+				tokens = append(tokens, token{kind: tokPunct, text: s[i : i+2]})
+				i += 2
+
+				continue
+			}
+
+			if !strings.ContainsRune(".|,()[]{}:<>", rune(c)) {
+				r, _ := utf8.DecodeRuneInString(s[i:])
+				return nil, fmt.Errorf("unexpected character %q", r)
+			}
+
+			tokens = append(tokens, token{kind: tokPunct, text: string(c)})
+			i++
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF}), nil
+}
+
+// stringEnd returns the index following the closing quote of the string
+// starting at s[start].
+func stringEnd(s string, start int) (int, error) {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+
+	return 0, errors.New("unterminated string")
+}
+
+// endsOperand reports whether the last token ends an operand,
+// in which case a following '-' is not a sign.
+func endsOperand(tokens []token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+
+	t := tokens[len(tokens)-1]
+
+	return t.kind == tokNumber || t.kind == tokString || t.kind == tokIdent || t.kind == tokField ||
+		(t.kind == tokPunct && strings.Contains(".)]}", t.text))
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isIdentStart(c byte) bool { return c == '_' || (c|0x20 >= 'a' && c|0x20 <= 'z') }
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+// accept consumes the next token if it is the given punctuation.
+func (p *parser) accept(punct string) bool {
+	if t := p.peek(); t.kind == tokPunct && t.text == punct {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.accept(punct) {
+		return fmt.Errorf("expected %q, got %s", punct, p.peek())
+	}
+
+	return nil
+}
+
+func (p *parser) parsePipe() (filter, error) {
+	l, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("|") {
+		r, err := p.parseComma()
+		if err != nil {
+			return nil, err
+		}
+
+		l = pipe(l, r)
+	}
+
+	return l, nil
+}
+
+func (p *parser) parseComma() (filter, error) {
+	l, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept(",") {
+		r, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		l = comma(l, r)
+	}
+
+	return l, nil
+}
+
+func (p *parser) parseOr() (filter, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptIdent("or") {
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		l = logical(l, r, true)
+	}
+
+	return l, nil
+}
+
+func (p *parser) parseAnd() (filter, error) {
+	l, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptIdent("and") {
+		r, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+
+		l = logical(l, r, false)
+	}
+
+	return l, nil
+}
+
+func (p *parser) acceptIdent(name string) bool {
+	if t := p.peek(); t.kind == tokIdent && t.text == name {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) parseComparison() (filter, error) {
+	l, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	if t.kind != tokPunct || !slices.Contains([]string{"==", "!=", "<", "<=", ">", ">="}, t.text) {
+		return l, nil
+	}
+
+	p.pos++
+
+	r, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+
+	return comparison(l, r, t.text), nil
+}
+
+// suffix maps a base value to its outputs, given the input of the expression.
+type suffix func(base, in any) ([]any, error)
+
+func (p *parser) parsePostfix() (filter, error) {
+	f, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var s suffix
+
+		switch t := p.peek(); {
+		case t.kind == tokField:
+			p.pos++
+
+			s = func(base, _ any) ([]any, error) { return index(base, t.text) }
+		case p.accept("."):
+			if p.peek().kind == tokPunct && p.peek().text == "[" {
+				continue
+			}
+
+			if p.peek().kind != tokString {
+				return nil, fmt.Errorf("expected a key after '.', got %s", p.peek())
+			}
+
+			key := p.next().text
+
+			s = func(base, _ any) ([]any, error) { return index(base, key) }
+		case p.accept("["):
+			if p.accept("]") {
+				s = func(base, _ any) ([]any, error) { return iterate(base) }
+				break
+			}
+
+			idx, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+
+			s = func(base, in any) ([]any, error) {
+				return flatMap(idx, in, func(k any) ([]any, error) { return index(base, k) })
+			}
+		default:
+			return f, nil
+		}
+
+		f = withSuffix(f, s)
+	}
+}
+
+func (p *parser) parsePrimary() (filter, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokEOF:
+		return nil, errors.New("unexpected end of filter")
+	case tokString:
+		return literal(t.text), nil
+	case tokNumber:
+		return literal(t.num), nil
+	case tokIdent:
+		return p.parseFunction(t.text)
+	case tokField:
+		return func(v any) ([]any, error) { return index(v, t.text) }, nil
+	}
+
+	switch t.text {
+	case ".":
+		if p.peek().kind == tokString {
+			key := p.next().text
+			return func(v any) ([]any, error) { return index(v, key) }, nil
+		}
+
+		return func(v any) ([]any, error) { return []any{v}, nil }, nil
+	case "(":
+		f, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+
+		return f, p.expect(")")
+	case "[":
+		if p.accept("]") {
+			return literal([]any{}), nil
+		}
+
+		f, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+
+		return collect(f), p.expect("]")
+	case "{":
+		return p.parseObject()
+	}
+
+	return nil, fmt.Errorf("unexpected %s", t)
+}
+
+func (p *parser) parseFunction(name string) (filter, error) {
+	switch name {
+	case "null":
+		return literal(nil), nil
+	case "true":
+		return literal(true), nil
+	case "false":
+		return literal(false), nil
+	case "length":
+		return length, nil
+	case "keys":
+		return keys, nil
+	case "not":
+		return func(v any) ([]any, error) { return []any{!truthy(v)}, nil }, nil
+	case "select", "map", "join":
+		if err := p.expect("("); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		arg, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(")"); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		switch name {
+		case "select":
+			return selectf(arg), nil
+		case "map":
+			return collect(pipe(iterate, arg)), nil
+		default:
+			return join(arg), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported function %q (supported: select, map, join, length, keys, not)", name)
+}
+
+// objectEntry is an entry of an object construction.
+type objectEntry struct {
+	key   string
+	value filter
+}
+
+func (p *parser) parseObject() (filter, error) {
+	var entries []objectEntry
+
+	for !p.accept("}") {
+		if len(entries) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+
+		t := p.next()
+		if t.kind != tokIdent && t.kind != tokString {
+			return nil, fmt.Errorf("expected an object key, got %s", t)
+		}
+
+		e := objectEntry{key: t.text}
+
+		if p.accept(":") {
+			v, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+
+			e.value = v
+		} else {
+			e.value = func(v any) ([]any, error) { return index(v, t.text) }
+		}
+
+		entries = append(entries, e)
+	}
+
+	return object(entries), nil
+}
@@ -0,0 +1,149 @@
+package jq_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/jq"
+)
+
+const secrets = `[
+  {"id": 1, "name": "gh", "labels": ["personal", "https://github.com/login"], "url": "https://github.com/login"},
+  {"id": 7, "name": "z", "labels": [], "url": ""},
+  {"id": 9, "name": "team/db", "labels": ["prod"], "url": ""}
+]`
+
+func TestQuery_Run(t *testing.T) {
+	var input any
+	if err := json.Unmarshal([]byte(secrets), &input); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		filter string
+		want   string // want is the json array of the filter outputs.
+	}{
+		{filter: ".", want: "[" + secrets + "]"},
+		{filter: ".[0].name", want: `["gh"]`},
+		{filter: `.[-1]["name"]`, want: `["team/db"]`},
+		{filter: `.[1]."name"`, want: `["z"]`},
+		{filter: ".[5]", want: `[null]`},
+		{filter: ".[0].missing.deeper", want: `[null]`},
+		{filter: ".[].id", want: `[1, 7, 9]`},
+		{filter: ".[0].labels[]", want: `["personal", "https://github.com/login"]`},
+		{filter: "[.[].name]", want: `[["gh", "z", "team/db"]]`},
+		{filter: "map(.id)", want: `[[1, 7, 9]]`},
+		{filter: `.[] | select(.url != "") | .name`, want: `["gh"]`},
+		{filter: `.[] | select(.id > 1 and .id < 9) | .name`, want: `["z"]`},
+		{filter: `.[] | select(.name == "z" or .id >= 9) | .id`, want: `[7, 9]`},
+		{filter: `.[] | select(.labels | length == 0 | not) | .name`, want: `["gh", "team/db"]`},
+		{filter: ".[0] | {name, id: .id, first: .labels[0]}", want: `[{"name": "gh", "id": 1, "first": "personal"}]`},
+		{filter: `.[0] | {name: (.name, "other")}`, want: `[{"name": "gh"}, {"name": "other"}]`},
+		{filter: ".[0].name, .[1].name", want: `["gh", "z"]`},
+		{filter: `.[0].labels | join(",")`, want: `["personal,https://github.com/login"]`},
+		{filter: "length", want: `[3]`},
+		{filter: ".[0] | keys", want: `[["id", "labels", "name", "url"]]`},
+		{filter: ".[0] | .[]", want: `[1, ["personal", "https://github.com/login"], "gh", "https://github.com/login"]`},
+		{filter: `[.[] | select(.id == 100)]`, want: `[[]]`},
+		{filter: `null, true, false, "s", -1.5`, want: `[null, true, false, "s", -1.5]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			q, err := jq.Parse(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := q.Run(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if g, w := canonical(t, got), canonical(t, json.RawMessage(tt.want)); g != w {
+				t.Errorf("got %s, want %s", g, w)
+			}
+		})
+	}
+}
+
+func TestQuery_RunTyped(t *testing.T) {
+	q, err := jq.Parse(".[0].id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := q.Run([]map[string]any{{"id": 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0] != 3.0 {
+		t.Errorf("got %v, want [3]", got)
+	}
+}
+
+func TestQuery_RunErrors(t *testing.T) {
+	tests := []struct {
+		filter string
+		input  any
+	}{
+		{filter: ".name", input: []any{}},
+		{filter: ".[0]", input: map[string]any{}},
+		{filter: ".[]", input: nil},
+		{filter: "keys", input: "s"},
+		{filter: "length", input: true},
+		{filter: `join(",")`, input: []any{[]any{}}},
+	}
+
+	for _, tt := range tests {
+		q, err := jq.Parse(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := q.Run(tt.input); err == nil {
+			t.Errorf("%s: expected an error", tt.filter)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, filter := range []string{
+		"",
+		".[",
+		".foo |",
+		`"unterminated`,
+		"{a: }",
+		"select(.a",
+		"first",
+		".a $",
+		". .",
+	} {
+		if _, err := jq.Parse(filter); err == nil {
+			t.Errorf("%q: expected an error", filter)
+		}
+	}
+}
+
+// canonical returns v encoded as json, with object keys sorted.
+func canonical(t *testing.T, v any) string {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded any
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,530 @@
+// Package kdbx reads the entries of KeePass databases, in the KDBX 3.1 and
+// KDBX 4 formats, e.g., to import them into a vault.
+//
+// Only databases protected by a password alone are supported, key files and
+// challenge-response keys are not. Keys are derived using AES-KDF, Argon2d or
+// Argon2id, and databases encrypted using AES-256 or ChaCha20.
+//
+// KDBX 4 headers and payload blocks are authenticated using HMAC-SHA-256,
+// and KDBX 3.1 payload blocks using SHA-256 hashes, so that a wrong password
+// is told apart from a corrupted database.
+package kdbx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20"
+)
+
+const (
+	// maxSize bounds the size of databases and of their decompressed payload.
+	maxSize = 256 << 20
+
+	// maxArgon2Memory bounds the Argon2 memory cost read from headers, in KiB.
+	maxArgon2Memory = 4 << 20
+)
+
+var (
+	// ErrFormat indicates input that is not a KeePass database.
+	ErrFormat = errors.New("not a KeePass KDBX database")
+
+	// ErrUnsupported indicates a database using unsupported features.
+	ErrUnsupported = errors.New("unsupported KeePass database")
+
+	// ErrCredentials indicates a wrong password, or a database protected by a key file.
+	ErrCredentials = errors.New("wrong password, or the database requires a key file")
+
+	// ErrCorrupted indicates a database modified or truncated.
+	ErrCorrupted = errors.New("corrupted KeePass database")
+)
+
+// signature is the leading two signatures of KDBX files, little-endian.
+var signature = []byte{0x03, 0xd9, 0xa2, 0x9a, 0x67, 0xfb, 0x4b, 0xb5}
+
+// Outer header field ids.
+const (
+	fieldEnd                 = 0
+	fieldCipherID            = 2
+	fieldCompressionFlags    = 3
+	fieldMasterSeed          = 4
+	fieldTransformSeed       = 5 // KDBX 3.1 only.
+	fieldTransformRounds     = 6 // KDBX 3.1 only.
+	fieldEncryptionIV        = 7
+	fieldProtectedStreamKey  = 8  // KDBX 3.1 only.
+	fieldStreamStartBytes    = 9  // KDBX 3.1 only.
+	fieldInnerRandomStreamID = 10 // KDBX 3.1 only.
+	fieldKDFParameters       = 11 // KDBX 4 only.
+)
+
+// Inner header field ids, KDBX 4 only.
+const (
+	innerFieldEnd      = 0
+	innerFieldStreamID = 1
+	innerFieldKey      = 2
+)
+
+// Cipher, key derivation and inner stream identifiers.
+const (
+	cipherAES256   = "31c1f2e6bf714350be5805216afc5aff"
+	cipherChaCha20 = "d6038a2b8b6f4cb5a524339a31dbb59a"
+
+	kdfAES    = "c9d9f39a628a4460bf740d08c18a4fea"
+	kdfAES4   = "7c02bb8279a74ac0927d114a00648238"
+	kdfArgonD = "ef636ddf8c29444b91f7a9a403e30a0c" // kdfArgonD is Argon2d.
+	kdfArgonI = "9e298b1956db4773b23dfc3ec6f0a1e6" // kdfArgonI is Argon2id.
+
+	streamSalsa20  = 2
+	streamChaCha20 = 3
+)
+
+// Sniff reports whether the input starting with head is a KeePass database.
+func Sniff(head []byte) bool {
+	return bytes.HasPrefix(head, signature)
+}
+
+// Read returns the entries of the KeePass database read from r,
+// opened using password.
+//
+// It returns [ErrFormat] if r is not a KeePass database, [ErrCredentials] if
+// the password is wrong, and [ErrCorrupted] if the database was modified.
+func Read(r io.Reader, password []byte) ([]Entry, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 12 || !Sniff(data) {
+		return nil, ErrFormat
+	}
+
+	composite := compositeKey(password)
+
+	switch major := binary.LittleEndian.Uint16(data[10:12]); major {
+	case 3:
+		return readV3(data, composite)
+	case 4:
+		return readV4(data, composite)
+	default:
+		return nil, fmt.Errorf("%w: KDBX version %d", ErrUnsupported, major)
+	}
+}
+
+// compositeKey returns the composite key of a database protected by password alone.
+func compositeKey(password []byte) []byte {
+	h := sha256.Sum256(password)
+	k := sha256.Sum256(h[:])
+
+	return k[:]
+}
+
+// readV4 reads a KDBX 4 database.
+func readV4(data []byte, composite []byte) ([]Entry, error) {
+	fields, end, err := parseHeader(data, 12, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < end+64 {
+		return nil, ErrCorrupted
+	}
+
+	header, rest := data[:end], data[end:]
+
+	if sum := sha256.Sum256(header); !bytes.Equal(sum[:], rest[:32]) {
+		return nil, fmt.Errorf("%w: header checksum mismatch", ErrCorrupted)
+	}
+
+	params, err := parseVariantDict(fields[fieldKDFParameters])
+	if err != nil {
+		return nil, err
+	}
+
+	transformed, err := deriveKeyV4(params, composite)
+	if err != nil {
+		return nil, err
+	}
+
+	masterSeed := fields[fieldMasterSeed]
+	hmacKey := sha512.Sum512(bytes.Join([][]byte{masterSeed, transformed, {1}}, nil))
+
+	if !hmac.Equal(blockHMAC(hmacKey[:], math.MaxUint64, header), rest[32:64]) {
+		return nil, ErrCredentials
+	}
+
+	encrypted, err := readHMACBlocks(rest[64:], hmacKey[:])
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := decrypt(fields, masterSeed, transformed, encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+
+	if plaintext, err = decompress(fields, plaintext); err != nil {
+		return nil, err
+	}
+
+	streamID, streamKey, xml, err := parseInnerHeader(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := newInnerStream(streamID, streamKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseXML(bytes.NewReader(xml), stream)
+}
+
+// readV3 reads a KDBX 3.1 database.
+func readV3(data []byte, composite []byte) ([]Entry, error) {
+	fields, end, err := parseHeader(data, 12, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	rounds := fields[fieldTransformRounds]
+	if len(rounds) != 8 {
+		return nil, fmt.Errorf("%w: invalid transform rounds", ErrFormat)
+	}
+
+	transformed, err := aesKDF(composite, fields[fieldTransformSeed], binary.LittleEndian.Uint64(rounds))
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := decrypt(fields, fields[fieldMasterSeed], transformed, data[end:])
+	if err != nil {
+		return nil, ErrCredentials
+	}
+
+	// the leading bytes are checked to tell a wrong password apart.
+	start := fields[fieldStreamStartBytes]
+	if len(start) == 0 || !bytes.HasPrefix(plaintext, start) {
+		return nil, ErrCredentials
+	}
+
+	payload, err := readHashedBlocks(plaintext[len(start):])
+	if err != nil {
+		return nil, err
+	}
+
+	if payload, err = decompress(fields, payload); err != nil {
+		return nil, err
+	}
+
+	streamID := fields[fieldInnerRandomStreamID]
+	if len(streamID) != 4 {
+		return nil, fmt.Errorf("%w: invalid inner stream", ErrFormat)
+	}
+
+	stream, err := newInnerStream(binary.LittleEndian.Uint32(streamID), fields[fieldProtectedStreamKey])
+	if err != nil {
+		return nil, err
+	}
+
+	return parseXML(bytes.NewReader(payload), stream)
+}
+
+// parseHeader returns the header fields starting at off, whose sizes are
+// sizeLen bytes long, and the offset following the end of header field.
+func parseHeader(data []byte, off int, sizeLen int) (map[byte][]byte, int, error) {
+	fields := make(map[byte][]byte)
+
+	for {
+		if len(data) < off+1+sizeLen {
+			return nil, 0, fmt.Errorf("%w: truncated header", ErrFormat)
+		}
+
+		id := data[off]
+		off++
+
+		var size int
+		if sizeLen == 2 {
+			size = int(binary.LittleEndian.Uint16(data[off:]))
+		} else {
+			size = int(binary.LittleEndian.Uint32(data[off:]))
+		}
+
+		off += sizeLen
+
+		if size < 0 || len(data) < off+size {
+			return nil, 0, fmt.Errorf("%w: truncated header", ErrFormat)
+		}
+
+		fields[id] = data[off : off+size]
+		off += size
+
+		if id == fieldEnd {
+			return fields, off, nil
+		}
+	}
+}
+
+// deriveKeyV4 derives the transformed key using the KDF parameters of a KDBX 4 header.
+func deriveKeyV4(params map[string]any, composite []byte) ([]byte, error) {
+	uuid, _ := params["$UUID"].([]byte)
+	salt, _ := params["S"].([]byte)
+
+	switch hex.EncodeToString(uuid) {
+	case kdfAES, kdfAES4:
+		rounds, _ := params["R"].(uint64)
+		return aesKDF(composite, salt, rounds)
+	case kdfArgonD, kdfArgonI:
+		iterations, _ := params["I"].(uint64)
+		memory, _ := params["M"].(uint64)
+		parallelism, _ := params["P"].(uint32)
+		version, _ := params["V"].(uint32)
+
+		if secret, _ := params["K"].([]byte); len(secret) > 0 {
+			return nil, fmt.Errorf("%w: argon2 secret key", ErrUnsupported)
+		}
+
+		if data, _ := params["A"].([]byte); len(data) > 0 {
+			return nil, fmt.Errorf("%w: argon2 associated data", ErrUnsupported)
+		}
+
+		if version != argon2.Version {
+			return nil, fmt.Errorf("%w: argon2 version %#x", ErrUnsupported, version)
+		}
+
+		// bounded, not to exhaust memory deriving the key of a crafted database.
+		memory /= 1024
+		if iterations == 0 || iterations > math.MaxUint32 || memory == 0 || memory > maxArgon2Memory || parallelism == 0 || parallelism > math.MaxUint8 {
+			return nil, fmt.Errorf("%w: invalid argon2 parameters", ErrFormat)
+		}
+
+		derive := argon2.IDKey
+		if hex.EncodeToString(uuid) == kdfArgonD {
+			derive = argon2.DKey
+		}
+
+		return derive(composite, salt, uint32(iterations), uint32(memory), uint8(parallelism), 32), nil
+	default:
+		return nil, fmt.Errorf("%w: key derivation function %x", ErrUnsupported, uuid)
+	}
+}
+
+// aesKDF derives the transformed key by encrypting the composite key
+// rounds times using AES-256 in ECB mode, keyed by seed.
+func aesKDF(composite []byte, seed []byte, rounds uint64) ([]byte, error) {
+	if len(seed) != 32 {
+		return nil, fmt.Errorf("%w: invalid transform seed", ErrFormat)
+	}
+
+	block, err := aes.NewCipher(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	key := bytes.Clone(composite)
+	for range rounds {
+		block.Encrypt(key[:16], key[:16])
+		block.Encrypt(key[16:], key[16:])
+	}
+
+	sum := sha256.Sum256(key)
+
+	return sum[:], nil
+}
+
+// blockHMAC returns the HMAC of the payload block at the given index,
+// or of the header at index [math.MaxUint64].
+func blockHMAC(hmacKey []byte, index uint64, data []byte) []byte {
+	prefix := binary.LittleEndian.AppendUint64(nil, index)
+	key := sha512.Sum512(append(prefix, hmacKey...))
+
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write(prefix)
+
+	if index != math.MaxUint64 {
+		mac.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(data)))) //nolint:gosec // bounded by maxSize.
+	}
+
+	mac.Write(data)
+
+	return mac.Sum(nil)
+}
+
+// readHMACBlocks returns the concatenated data of the HMAC-SHA-256
+// authenticated blocks of a KDBX 4 payload.
+func readHMACBlocks(data []byte, hmacKey []byte) ([]byte, error) {
+	var out []byte
+
+	for index := uint64(0); ; index++ {
+		if len(data) < 36 {
+			return nil, fmt.Errorf("%w: truncated payload", ErrCorrupted)
+		}
+
+		mac, size := data[:32], int(binary.LittleEndian.Uint32(data[32:36]))
+		data = data[36:]
+
+		if size < 0 || len(data) < size {
+			return nil, fmt.Errorf("%w: truncated payload", ErrCorrupted)
+		}
+
+		block := data[:size]
+		data = data[size:]
+
+		if !hmac.Equal(blockHMAC(hmacKey, index, block), mac) {
+			return nil, fmt.Errorf("%w: block %d authentication failed", ErrCorrupted, index)
+		}
+
+		if size == 0 {
+			return out, nil
+		}
+
+		out = append(out, block...)
+	}
+}
+
+// readHashedBlocks returns the concatenated data of the SHA-256
+// hashed blocks of a KDBX 3.1 payload.
+func readHashedBlocks(data []byte) ([]byte, error) {
+	var out []byte
+
+	for {
+		if len(data) < 40 {
+			return nil, fmt.Errorf("%w: truncated payload", ErrCorrupted)
+		}
+
+		hash, size := data[4:36], int(binary.LittleEndian.Uint32(data[36:40]))
+		data = data[40:]
+
+		if size == 0 {
+			return out, nil
+		}
+
+		if size < 0 || len(data) < size {
+			return nil, fmt.Errorf("%w: truncated payload", ErrCorrupted)
+		}
+
+		block := data[:size]
+		data = data[size:]
+
+		if sum := sha256.Sum256(block); !bytes.Equal(sum[:], hash) {
+			return nil, fmt.Errorf("%w: block checksum mismatch", ErrCorrupted)
+		}
+
+		out = append(out, block...)
+	}
+}
+
+// decrypt decrypts the payload using the cipher of the header.
+func decrypt(fields map[byte][]byte, masterSeed []byte, transformed []byte, payload []byte) ([]byte, error) {
+	key := sha256.Sum256(append(bytes.Clone(masterSeed), transformed...))
+	iv := fields[fieldEncryptionIV]
+
+	switch id := hex.EncodeToString(fields[fieldCipherID]); id {
+	case cipherAES256:
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			return nil, err
+		}
+
+		if len(iv) != aes.BlockSize || len(payload) == 0 || len(payload)%aes.BlockSize != 0 {
+			return nil, errors.New("invalid aes payload")
+		}
+
+		plaintext := make([]byte, len(payload))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, payload)
+
+		return unpad(plaintext)
+	case cipherChaCha20:
+		c, err := chacha20.NewUnauthenticatedCipher(key[:], iv)
+		if err != nil {
+			return nil, err
+		}
+
+		plaintext := make([]byte, len(payload))
+		c.XORKeyStream(plaintext, payload)
+
+		return plaintext, nil
+	default:
+		return nil, fmt.Errorf("%w: cipher %s", ErrUnsupported, id)
+	}
+}
+
+// unpad removes the PKCS #7 padding of b.
+func unpad(b []byte) ([]byte, error) {
+	n := int(b[len(b)-1])
+	if n == 0 || n > aes.BlockSize || n > len(b) {
+		return nil, errors.New("invalid padding")
+	}
+
+	for _, p := range b[len(b)-n:] {
+		if int(p) != n {
+			return nil, errors.New("invalid padding")
+		}
+	}
+
+	return b[:len(b)-n], nil
+}
+
+// decompress decompresses the payload, if compressed according to the header.
+func decompress(fields map[byte][]byte, payload []byte) ([]byte, error) {
+	flags := fields[fieldCompressionFlags]
+	if len(flags) != 4 || binary.LittleEndian.Uint32(flags) == 0 {
+		return payload, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+
+	out, err := io.ReadAll(io.LimitReader(zr, maxSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+
+	return out, nil
+}
+
+// parseInnerHeader returns the inner random stream of a KDBX 4 payload,
+// and the XML document following its inner header.
+func parseInnerHeader(payload []byte) (streamID uint32, key []byte, xml []byte, _ error) {
+	for {
+		if len(payload) < 5 {
+			return 0, nil, nil, fmt.Errorf("%w: truncated inner header", ErrCorrupted)
+		}
+
+		id, size := payload[0], int(binary.LittleEndian.Uint32(payload[1:5]))
+		payload = payload[5:]
+
+		if size < 0 || len(payload) < size {
+			return 0, nil, nil, fmt.Errorf("%w: truncated inner header", ErrCorrupted)
+		}
+
+		data := payload[:size]
+		payload = payload[size:]
+
+		switch id {
+		case innerFieldEnd:
+			return streamID, key, payload, nil
+		case innerFieldStreamID:
+			if len(data) != 4 {
+				return 0, nil, nil, fmt.Errorf("%w: invalid inner stream", ErrFormat)
+			}
+
+			streamID = binary.LittleEndian.Uint32(data)
+		case innerFieldKey:
+			key = data
+		}
+	}
+}
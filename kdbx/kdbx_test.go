@@ -0,0 +1,462 @@
+package kdbx_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/kdbx"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/salsa20/salsa"
+)
+
+const testPassword = "correct horse"
+
+var wantEntries = []kdbx.Entry{
+	{Title: "root entry", Password: "root-pw"},
+	{Title: "GitHub", UserName: "jane", Password: "pw", URL: "https://github.com", Notes: "2fa on phone", Groups: []string{"Internet"}, Tags: []string{"work", "dev"}},
+	{Title: "mail", Password: "mail-pw", Groups: []string{"Internet", "Email"}},
+	{Title: "note", Notes: "remember", Groups: []string{"Notes"}},
+}
+
+// testXML returns the XML document of the test database, protecting values using protect.
+func testXML(protect func(string) string) []byte {
+	return fmt.Appendf(nil, `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta>
+		<Generator>test</Generator>
+		<RecycleBinEnabled>True</RecycleBinEnabled>
+		<RecycleBinUUID>cmVjeWNsZQ==</RecycleBinUUID>
+	</Meta>
+	<Root>
+		<Group>
+			<UUID>cm9vdA==</UUID>
+			<Name>Database</Name>
+			<Entry>
+				<String><Key>Title</Key><Value>root entry</Value></String>
+				<String><Key>Password</Key><Value Protected="True">%s</Value></String>
+			</Entry>
+			<Group>
+				<UUID>aW50ZXJuZXQ=</UUID>
+				<Name>Internet</Name>
+				<Entry>
+					<Tags>work; dev</Tags>
+					<String><Key>Notes</Key><Value>2fa on phone</Value></String>
+					<String><Key>Password</Key><Value Protected="True">%s</Value></String>
+					<String><Key>Title</Key><Value>GitHub</Value></String>
+					<String><Key>URL</Key><Value>https://github.com</Value></String>
+					<String><Key>UserName</Key><Value>jane</Value></String>
+					<History>
+						<Entry>
+							<String><Key>Password</Key><Value Protected="True">%s</Value></String>
+							<String><Key>Title</Key><Value>GitHub (old)</Value></String>
+						</Entry>
+					</History>
+				</Entry>
+				<Group>
+					<UUID>ZW1haWw=</UUID>
+					<Name>Email</Name>
+					<Entry>
+						<String><Key>Title</Key><Value>mail</Value></String>
+						<String><Key>Password</Key><Value Protected="True">%s</Value></String>
+					</Entry>
+				</Group>
+			</Group>
+			<Group>
+				<UUID>cmVjeWNsZQ==</UUID>
+				<Name>Recycle Bin</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>deleted</Value></String>
+					<String><Key>Password</Key><Value Protected="True">%s</Value></String>
+				</Entry>
+			</Group>
+			<Group>
+				<UUID>bm90ZXM=</UUID>
+				<Name>Notes</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>note</Value></String>
+					<String><Key>Notes</Key><Value>remember</Value></String>
+					<String><Key>Password</Key><Value Protected="True">%s</Value></String>
+				</Entry>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>`, protect("root-pw"), protect("pw"), protect("old-pw"), protect("mail-pw"), protect("gone"), protect(""))
+}
+
+// protector returns a function protecting values using the given key stream.
+func protector(stream func(dst, src []byte)) func(string) string {
+	return func(s string) string {
+		b := []byte(s)
+		stream(b, b)
+
+		return base64.StdEncoding.EncodeToString(b)
+	}
+}
+
+func randBytes(t *testing.T, n int) []byte {
+	t.Helper()
+
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+func gzipped(t *testing.T, b []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func aesCBC(t *testing.T, key, iv, plaintext []byte) []byte {
+	t.Helper()
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := append(bytes.Clone(plaintext), bytes.Repeat([]byte{byte(n)}, n)...)
+
+	out := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, padded)
+
+	return out
+}
+
+func aesKDF(t *testing.T, composite, seed []byte, rounds uint64) []byte {
+	t.Helper()
+
+	block, err := aes.NewCipher(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := bytes.Clone(composite)
+	for range rounds {
+		block.Encrypt(key[:16], key[:16])
+		block.Encrypt(key[16:], key[16:])
+	}
+
+	sum := sha256.Sum256(key)
+
+	return sum[:]
+}
+
+func compositeKey(password string) []byte {
+	h := sha256.Sum256([]byte(password))
+	k := sha256.Sum256(h[:])
+
+	return k[:]
+}
+
+func field(buf *bytes.Buffer, id byte, data []byte, wide bool) {
+	buf.WriteByte(id)
+
+	if wide {
+		_ = binary.Write(buf, binary.LittleEndian, uint32(len(data))) //nolint:gosec // test data.
+	} else {
+		_ = binary.Write(buf, binary.LittleEndian, uint16(len(data))) //nolint:gosec // test data.
+	}
+
+	buf.Write(data)
+}
+
+func variant(buf *bytes.Buffer, typ byte, name string, value []byte) {
+	buf.WriteByte(typ)
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(name))) //nolint:gosec // test data.
+	buf.WriteString(name)
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(value))) //nolint:gosec // test data.
+	buf.Write(value)
+}
+
+// kdfV4 is the key derivation of a KDBX 4 test database.
+type kdfV4 struct {
+	params func(buf *bytes.Buffer)
+	derive func(composite []byte) []byte
+}
+
+func aesKDFV4(t *testing.T) kdfV4 {
+	t.Helper()
+
+	seed := randBytes(t, 32)
+
+	return kdfV4{
+		params: func(buf *bytes.Buffer) {
+			variant(buf, 0x42, "$UUID", mustHex("c9d9f39a628a4460bf740d08c18a4fea"))
+			variant(buf, 0x05, "R", binary.LittleEndian.AppendUint64(nil, 100))
+			variant(buf, 0x42, "S", seed)
+		},
+		derive: func(composite []byte) []byte { return aesKDF(t, composite, seed, 100) },
+	}
+}
+
+func argon2KDFV4(t *testing.T, id bool) kdfV4 {
+	t.Helper()
+
+	salt := randBytes(t, 32)
+
+	uuid, derive := "ef636ddf8c29444b91f7a9a403e30a0c", argon2.DKey
+	if id {
+		uuid, derive = "9e298b1956db4773b23dfc3ec6f0a1e6", argon2.IDKey
+	}
+
+	return kdfV4{
+		params: func(buf *bytes.Buffer) {
+			variant(buf, 0x42, "$UUID", mustHex(uuid))
+			variant(buf, 0x05, "I", binary.LittleEndian.AppendUint64(nil, 2))
+			variant(buf, 0x05, "M", binary.LittleEndian.AppendUint64(nil, 1<<20))
+			variant(buf, 0x04, "P", binary.LittleEndian.AppendUint32(nil, 2))
+			variant(buf, 0x04, "V", binary.LittleEndian.AppendUint32(nil, 0x13))
+			variant(buf, 0x42, "S", salt)
+		},
+		derive: func(composite []byte) []byte { return derive(composite, salt, 2, 1024, 2, 32) },
+	}
+}
+
+func blockHMAC(hmacKey []byte, index uint64, data []byte, header bool) []byte {
+	prefix := binary.LittleEndian.AppendUint64(nil, index)
+	key := sha512.Sum512(append(prefix, hmacKey...))
+
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write(prefix)
+
+	if !header {
+		mac.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(data)))) //nolint:gosec // test data.
+	}
+
+	mac.Write(data)
+
+	return mac.Sum(nil)
+}
+
+// writeV4 returns a KDBX 4 test database, encrypted using AES-256, or else ChaCha20.
+func writeV4(t *testing.T, kdf kdfV4, useAES bool) []byte {
+	t.Helper()
+
+	masterSeed := randBytes(t, 32)
+	innerKey := randBytes(t, 64)
+
+	cipherID, iv := "d6038a2b8b6f4cb5a524339a31dbb59a", randBytes(t, 12)
+	if useAES {
+		cipherID, iv = "31c1f2e6bf714350be5805216afc5aff", randBytes(t, 16)
+	}
+
+	var params bytes.Buffer
+
+	params.Write([]byte{0x00, 0x01})
+	kdf.params(&params)
+	params.WriteByte(0)
+
+	var header bytes.Buffer
+
+	header.Write([]byte{0x03, 0xd9, 0xa2, 0x9a, 0x67, 0xfb, 0x4b, 0xb5, 0x01, 0x00, 0x04, 0x00})
+	field(&header, 2, mustHex(cipherID), true)
+	field(&header, 3, []byte{1, 0, 0, 0}, true)
+	field(&header, 4, masterSeed, true)
+	field(&header, 7, iv, true)
+	field(&header, 11, params.Bytes(), true)
+	field(&header, 0, []byte("\r\n\r\n"), true)
+
+	h := sha512.Sum512(innerKey)
+
+	stream, err := chacha20.NewUnauthenticatedCipher(h[:32], h[32:44])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var inner bytes.Buffer
+
+	field(&inner, 1, []byte{3, 0, 0, 0}, true)
+	field(&inner, 2, innerKey, true)
+	field(&inner, 3, []byte("\x01attachment"), true)
+	field(&inner, 0, nil, true)
+	inner.Write(testXML(protector(stream.XORKeyStream)))
+
+	transformed := kdf.derive(compositeKey(testPassword))
+	key := sha256.Sum256(append(bytes.Clone(masterSeed), transformed...))
+
+	var encrypted []byte
+
+	if useAES {
+		encrypted = aesCBC(t, key[:], iv, gzipped(t, inner.Bytes()))
+	} else {
+		c, err := chacha20.NewUnauthenticatedCipher(key[:], iv)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		plaintext := gzipped(t, inner.Bytes())
+		encrypted = make([]byte, len(plaintext))
+		c.XORKeyStream(encrypted, plaintext)
+	}
+
+	hmacKey := sha512.Sum512(bytes.Join([][]byte{masterSeed, transformed, {1}}, nil))
+
+	out := bytes.Clone(header.Bytes())
+	headerHash := sha256.Sum256(header.Bytes())
+	out = append(out, headerHash[:]...)
+	out = append(out, blockHMAC(hmacKey[:], math.MaxUint64, header.Bytes(), true)...)
+
+	half := len(encrypted) / 2
+	for i, block := range [][]byte{encrypted[:half], encrypted[half:], nil} {
+		out = append(out, blockHMAC(hmacKey[:], uint64(i), block, false)...) //nolint:gosec // small index.
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(block)))      //nolint:gosec // test data.
+		out = append(out, block...)
+	}
+
+	return out
+}
+
+// writeV3 returns a KDBX 3.1 test database, encrypted using AES-256,
+// its key derived using AES-KDF, and its values protected using Salsa20.
+func writeV3(t *testing.T) []byte {
+	t.Helper()
+
+	masterSeed, transformSeed := randBytes(t, 32), randBytes(t, 32)
+	iv, streamKey, startBytes := randBytes(t, 16), randBytes(t, 32), randBytes(t, 32)
+
+	var header bytes.Buffer
+
+	header.Write([]byte{0x03, 0xd9, 0xa2, 0x9a, 0x67, 0xfb, 0x4b, 0xb5, 0x01, 0x00, 0x03, 0x00})
+	field(&header, 2, mustHex("31c1f2e6bf714350be5805216afc5aff"), false)
+	field(&header, 3, []byte{1, 0, 0, 0}, false)
+	field(&header, 4, masterSeed, false)
+	field(&header, 5, transformSeed, false)
+	field(&header, 6, binary.LittleEndian.AppendUint64(nil, 100), false)
+	field(&header, 7, iv, false)
+	field(&header, 8, streamKey, false)
+	field(&header, 9, startBytes, false)
+	field(&header, 10, []byte{2, 0, 0, 0}, false)
+	field(&header, 0, []byte("\r\n\r\n"), false)
+
+	// the key stream of all protected values, generated at once.
+	keyStream := make([]byte, 1024)
+	counter := [16]byte{0xe8, 0x30, 0x09, 0x4b, 0x97, 0x20, 0x5d, 0x2a}
+	salsaKey := sha256.Sum256(streamKey)
+	salsa.XORKeyStream(keyStream, make([]byte, len(keyStream)), &counter, &salsaKey)
+
+	used := 0
+	xml := testXML(protector(func(dst, src []byte) {
+		for i := range src {
+			dst[i] = src[i] ^ keyStream[used]
+			used++
+		}
+	}))
+
+	compressed := gzipped(t, xml)
+	hash := sha256.Sum256(compressed)
+
+	payload := bytes.Clone(startBytes)
+	payload = binary.LittleEndian.AppendUint32(payload, 0)
+	payload = append(payload, hash[:]...)
+	payload = binary.LittleEndian.AppendUint32(payload, uint32(len(compressed))) //nolint:gosec // test data.
+	payload = append(payload, compressed...)
+	payload = binary.LittleEndian.AppendUint32(payload, 1)
+	payload = append(payload, make([]byte, 32)...)
+	payload = binary.LittleEndian.AppendUint32(payload, 0)
+
+	transformed := aesKDF(t, compositeKey(testPassword), transformSeed, 100)
+	key := sha256.Sum256(append(bytes.Clone(masterSeed), transformed...))
+
+	return append(bytes.Clone(header.Bytes()), aesCBC(t, key[:], iv, payload)...)
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name string
+		db   []byte
+	}{
+		{name: "kdbx 3.1", db: writeV3(t)},
+		{name: "kdbx 4 aes-kdf aes", db: writeV4(t, aesKDFV4(t), true)},
+		{name: "kdbx 4 argon2d chacha20", db: writeV4(t, argon2KDFV4(t, false), false)},
+		{name: "kdbx 4 argon2id aes", db: writeV4(t, argon2KDFV4(t, true), true)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !kdbx.Sniff(tt.db) {
+				t.Error("Sniff(): got false, want true")
+			}
+
+			got, err := kdbx.Read(bytes.NewReader(tt.db), []byte(testPassword))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, wantEntries) {
+				t.Errorf("Read():\ngot  %+v\nwant %+v", got, wantEntries)
+			}
+
+			if _, err := kdbx.Read(bytes.NewReader(tt.db), []byte("wrong")); !errors.Is(err, kdbx.ErrCredentials) {
+				t.Errorf("Read() using a wrong password: got err %v, want %v", err, kdbx.ErrCredentials)
+			}
+		})
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	db := writeV4(t, aesKDFV4(t), true)
+
+	tampered := bytes.Clone(db)
+	tampered[len(tampered)-50] ^= 1
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  error
+	}{
+		{name: "not a database", input: []byte("name,secret,labels\n"), want: kdbx.ErrFormat},
+		{name: "truncated header", input: db[:20], want: kdbx.ErrFormat},
+		{name: "modified payload", input: tampered, want: kdbx.ErrCorrupted},
+		{name: "truncated payload", input: db[:len(db)-40], want: kdbx.ErrCorrupted},
+		{name: "kdbx 2", input: append(bytes.Clone(db[:10]), 0x02, 0x00), want: kdbx.ErrUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := kdbx.Read(bytes.NewReader(tt.input), []byte(testPassword)); !errors.Is(err, tt.want) {
+				t.Errorf("Read(): got err %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/salsa20/salsa"
+)
+
+// salsa20Nonce is the fixed nonce of the Salsa20 inner stream.
+var salsa20Nonce = []byte{0xe8, 0x30, 0x09, 0x4b, 0x97, 0x20, 0x5d, 0x2a}
+
+// keyStream is the inner random stream protected values are XORed with,
+// in document order.
+type keyStream interface {
+	XORKeyStream(dst, src []byte)
+}
+
+// newInnerStream returns the inner random stream of the given id and key.
+func newInnerStream(id uint32, key []byte) (keyStream, error) {
+	switch id {
+	case streamSalsa20:
+		s := &salsa20Stream{key: sha256.Sum256(key), used: len(salsa20Block{})}
+		copy(s.counter[:8], salsa20Nonce)
+
+		return s, nil
+	case streamChaCha20:
+		h := sha512.Sum512(key)
+		return chacha20.NewUnauthenticatedCipher(h[:32], h[32:44])
+	default:
+		return nil, fmt.Errorf("%w: inner stream %d", ErrUnsupported, id)
+	}
+}
+
+type salsa20Block = [64]byte
+
+// salsa20Stream is a Salsa20 key stream, kept across calls.
+type salsa20Stream struct {
+	key     [32]byte
+	counter [16]byte // counter holds the nonce, followed by the block counter.
+	block   salsa20Block
+	used    int // used is the number of key stream bytes of block used.
+}
+
+func (s *salsa20Stream) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if s.used == len(s.block) {
+			var zero salsa20Block
+
+			salsa.XORKeyStream(s.block[:], zero[:], &s.counter, &s.key)
+			binary.LittleEndian.PutUint64(s.counter[8:], binary.LittleEndian.Uint64(s.counter[8:])+1)
+
+			s.used = 0
+		}
+
+		dst[i] = src[i] ^ s.block[s.used]
+		s.used++
+	}
+}
+
+// VariantDictionary value types.
+const (
+	variantEnd       = 0x00
+	variantUInt32    = 0x04
+	variantUInt64    = 0x05
+	variantBool      = 0x08
+	variantInt32     = 0x0c
+	variantInt64     = 0x0d
+	variantString    = 0x18
+	variantByteArray = 0x42
+)
+
+// parseVariantDict parses the KDF parameters of a KDBX 4 header,
+// encoded as a VariantDictionary.
+func parseVariantDict(data []byte) (map[string]any, error) {
+	errInvalid := fmt.Errorf("%w: invalid key derivation parameters", ErrFormat)
+
+	if len(data) < 2 || data[1] != 0x01 {
+		return nil, errInvalid
+	}
+
+	data = data[2:]
+	dict := make(map[string]any)
+
+	for {
+		if len(data) < 1 {
+			return nil, errInvalid
+		}
+
+		typ := data[0]
+		if typ == variantEnd {
+			return dict, nil
+		}
+
+		name, rest, ok := readSized(data[1:])
+		if !ok {
+			return nil, errInvalid
+		}
+
+		value, rest, ok := readSized(rest)
+		if !ok {
+			return nil, errInvalid
+		}
+
+		data = rest
+
+		switch {
+		case typ == variantUInt32 && len(value) == 4:
+			dict[string(name)] = binary.LittleEndian.Uint32(value)
+		case typ == variantUInt64 && len(value) == 8:
+			dict[string(name)] = binary.LittleEndian.Uint64(value)
+		case typ == variantBool && len(value) == 1:
+			dict[string(name)] = value[0] != 0
+		case typ == variantInt32 && len(value) == 4:
+			dict[string(name)] = int32(binary.LittleEndian.Uint32(value)) //nolint:gosec // reinterpreted.
+		case typ == variantInt64 && len(value) == 8:
+			dict[string(name)] = int64(binary.LittleEndian.Uint64(value)) //nolint:gosec // reinterpreted.
+		case typ == variantString:
+			dict[string(name)] = string(value)
+		case typ == variantByteArray:
+			dict[string(name)] = bytes.Clone(value)
+		default:
+			return nil, errInvalid
+		}
+	}
+}
+
+// readSized reads a value prefixed by its 4-byte little-endian size.
+func readSized(data []byte) (value []byte, rest []byte, ok bool) {
+	if len(data) < 4 {
+		return nil, nil, false
+	}
+
+	size := binary.LittleEndian.Uint32(data)
+	if uint64(len(data)-4) < uint64(size) {
+		return nil, nil, false
+	}
+
+	return data[4 : 4+size], data[4+size:], true
+}
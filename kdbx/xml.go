@@ -0,0 +1,196 @@
+package kdbx
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Entry is a KeePass database entry.
+type Entry struct {
+	Title    string
+	UserName string
+	Password string
+	URL      string
+	Notes    string
+
+	// Groups is the path of the group holding the entry,
+	// below the root group, e.g., ["Internet", "Email"].
+	Groups []string
+	Tags   []string
+}
+
+// emptyUUID is the base64 encoded UUID of unset references, e.g., of
+// the recycle bin of databases that have none.
+const emptyUUID = "AAAAAAAAAAAAAAAAAAAAAA=="
+
+// group is a group being parsed.
+type group struct {
+	uuid string
+	name string
+}
+
+// parsedEntry is an entry being parsed, along with the UUIDs of its groups.
+type parsedEntry struct {
+	Entry
+
+	groupUUIDs []string
+}
+
+// parseXML returns the entries of the XML document of a database, excluding
+// previous versions of entries and recycled ones. Protected values are
+// decrypted using the inner stream, in document order.
+func parseXML(r io.Reader, stream keyStream) ([]Entry, error) {
+	var (
+		d          = xml.NewDecoder(r)
+		path       []string // path holds the names of the open elements.
+		groups     []group
+		entries    []parsedEntry
+		entry      *parsedEntry
+		history    int // history is the depth of the previous versions of entry being parsed.
+		recycleBin string
+
+		text       strings.Builder
+		protected  bool
+		key, value string
+	)
+
+	parent := func() string {
+		if len(path) < 2 {
+			return ""
+		}
+
+		return path[len(path)-2]
+	}
+
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			text.Reset()
+
+			protected = slices.ContainsFunc(t.Attr, func(a xml.Attr) bool {
+				return a.Name.Local == "Protected" && strings.EqualFold(a.Value, "true")
+			})
+
+			switch t.Name.Local {
+			case "Group":
+				groups = append(groups, group{})
+			case "Entry":
+				if entry != nil {
+					history++
+					continue
+				}
+
+				entry = &parsedEntry{}
+				for i, g := range groups {
+					entry.groupUUIDs = append(entry.groupUUIDs, g.uuid)
+
+					// the root group is not part of the path.
+					if i > 0 {
+						entry.Groups = append(entry.Groups, g.name)
+					}
+				}
+			case "String":
+				key, value = "", ""
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			s := text.String()
+			text.Reset()
+
+			// protected values are decrypted even if unused, to keep the stream in sync.
+			if protected {
+				protected = false
+
+				raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+				if err != nil {
+					return nil, fmt.Errorf("%w: protected value: %v", ErrCorrupted, err)
+				}
+
+				stream.XORKeyStream(raw, raw)
+				s = string(raw)
+			}
+
+			switch p := parent(); {
+			case t.Name.Local == "RecycleBinUUID" && p == "Meta":
+				recycleBin = s
+			case t.Name.Local == "UUID" && p == "Group" && len(groups) > 0:
+				groups[len(groups)-1].uuid = s
+			case t.Name.Local == "Name" && p == "Group" && len(groups) > 0:
+				groups[len(groups)-1].name = s
+			case t.Name.Local == "Key" && p == "String":
+				key = s
+			case t.Name.Local == "Value" && p == "String":
+				value = s
+			case t.Name.Local == "String" && entry != nil && history == 0:
+				entry.set(key, value)
+			case t.Name.Local == "Tags" && p == "Entry" && entry != nil && history == 0:
+				entry.Tags = splitTags(s)
+			case t.Name.Local == "Entry" && history > 0:
+				history--
+			case t.Name.Local == "Entry" && entry != nil:
+				entries = append(entries, *entry)
+				entry = nil
+			case t.Name.Local == "Group" && len(groups) > 0:
+				groups = groups[:len(groups)-1]
+			}
+
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+		}
+	}
+
+	out := make([]Entry, 0, len(entries))
+
+	for _, e := range entries {
+		if len(recycleBin) > 0 && recycleBin != emptyUUID && slices.Contains(e.groupUUIDs, recycleBin) {
+			continue
+		}
+
+		out = append(out, e.Entry)
+	}
+
+	return out, nil
+}
+
+// set sets the standard field of the given key.
+func (e *parsedEntry) set(key string, value string) {
+	switch key {
+	case "Title":
+		e.Title = value
+	case "UserName":
+		e.UserName = value
+	case "Password":
+		e.Password = value
+	case "URL":
+		e.URL = value
+	case "Notes":
+		e.Notes = value
+	}
+}
+
+// splitTags splits the tags of an entry, separated by commas or semicolons.
+func splitTags(s string) []string {
+	tags := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' })
+	for i, t := range tags {
+		tags[i] = strings.TrimSpace(t)
+	}
+
+	return slices.DeleteFunc(tags, func(t string) bool { return len(t) == 0 })
+}
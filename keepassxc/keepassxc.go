@@ -0,0 +1,222 @@
+// Package keepassxc implements the transport of the KeePassXC-Browser protocol,
+// allowing the KeePassXC browser extensions to use vlt as their password database.
+//
+// The extensions talk to a native messaging host, exchanging JSON messages
+// prefixed by their length as a 32-bit native-endian integer. Once the public
+// keys are exchanged, message payloads are encrypted using NaCl boxes
+// (X25519, XSalsa20-Poly1305), and every response uses the request nonce,
+// incremented by one.
+package keepassxc
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+const (
+	// Version is the KeePassXC version reported to the extensions.
+	Version = "2.7.10"
+
+	// HostName is the name of the native messaging host the extensions connect to.
+	HostName = "org.keepassxc.keepassxc_browser"
+
+	// MaxMessageSize is the maximum size of a message sent to the browser.
+	MaxMessageSize = 1 << 20
+
+	// NonceSize is the size of a message nonce.
+	NonceSize = 24
+
+	// KeySize is the size of a public key.
+	KeySize = 32
+)
+
+// ErrorCode identifies protocol errors, as defined by KeePassXC.
+type ErrorCode int
+
+const (
+	ErrorDatabaseNotOpened          ErrorCode = 1
+	ErrorDatabaseHashNotReceived    ErrorCode = 2
+	ErrorClientPublicKeyNotReceived ErrorCode = 3
+	ErrorCannotDecryptMessage       ErrorCode = 4
+	ErrorActionCancelledOrDenied    ErrorCode = 6
+	ErrorCannotEncryptMessage       ErrorCode = 7
+	ErrorAssociationFailed          ErrorCode = 8
+	ErrorEncryptionKeyUnrecognized  ErrorCode = 10
+	ErrorIncorrectAction            ErrorCode = 12
+	ErrorEmptyMessageReceived       ErrorCode = 13
+	ErrorNoURLProvided              ErrorCode = 14
+	ErrorNoLoginsFound              ErrorCode = 15
+	ErrorNoValidUUIDProvided        ErrorCode = 18
+	ErrorAccessToAllEntriesIsDenied ErrorCode = 19
+)
+
+var errorMessages = map[ErrorCode]string{
+	ErrorDatabaseNotOpened:          "Database not opened",
+	ErrorDatabaseHashNotReceived:    "Database hash not available",
+	ErrorClientPublicKeyNotReceived: "Client public key not received",
+	ErrorCannotDecryptMessage:       "Cannot decrypt message",
+	ErrorActionCancelledOrDenied:    "Action cancelled or denied",
+	ErrorCannotEncryptMessage:       "Message encryption failed.",
+	ErrorAssociationFailed:          "KeePassXC association failed, try again",
+	ErrorEncryptionKeyUnrecognized:  "Encryption key is not recognized",
+	ErrorIncorrectAction:            "Incorrect action",
+	ErrorEmptyMessageReceived:       "Empty message received",
+	ErrorNoURLProvided:              "No URL provided",
+	ErrorNoLoginsFound:              "No logins found",
+	ErrorNoValidUUIDProvided:        "No valid UUID provided",
+	ErrorAccessToAllEntriesIsDenied: "Access to all entries is denied",
+}
+
+// String returns the message KeePassXC reports for the error code.
+func (c ErrorCode) String() string {
+	if msg, ok := errorMessages[c]; ok {
+		return msg
+	}
+
+	return "Unknown error"
+}
+
+var (
+	ErrMessageTooLarge = errors.New("message too large")
+	ErrInvalidKey      = errors.New("invalid public key")
+	ErrInvalidNonce    = errors.New("invalid nonce")
+	ErrDecrypt         = errors.New("cannot decrypt message")
+)
+
+// ReadMessage reads a single length-prefixed message from r,
+// rejecting messages larger than maxSize.
+func ReadMessage(r io.Reader, maxSize int) ([]byte, error) {
+	var n uint32
+	if err := binary.Read(r, binary.NativeEndian, &n); err != nil {
+		return nil, err
+	}
+
+	if int64(n) > int64(maxSize) {
+		return nil, fmt.Errorf("%w: %d bytes", ErrMessageTooLarge, n)
+	}
+
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// WriteMessage writes msg to w, prefixed by its length.
+func WriteMessage(w io.Writer, msg []byte) error {
+	if len(msg) > MaxMessageSize {
+		return fmt.Errorf("%w: %d bytes", ErrMessageTooLarge, len(msg))
+	}
+
+	b := binary.NativeEndian.AppendUint32(make([]byte, 0, 4+len(msg)), uint32(len(msg))) //nolint:gosec // bounded above.
+
+	_, err := w.Write(append(b, msg...))
+
+	return err
+}
+
+// Session holds the keys encrypting the messages exchanged with a client.
+type Session struct {
+	clientKey  *[KeySize]byte
+	publicKey  *[KeySize]byte
+	privateKey *[KeySize]byte
+}
+
+// NewSession returns a new session with the client having the given
+// base64 encoded public key, generating a new server key pair.
+func NewSession(clientPublicKey string) (*Session, error) {
+	clientKey, err := decodeKey(clientPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{clientKey: clientKey, publicKey: publicKey, privateKey: privateKey}, nil
+}
+
+// PublicKey returns the base64 encoded server public key.
+func (s *Session) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.publicKey[:])
+}
+
+// ClientKey returns the base64 encoded client public key.
+func (s *Session) ClientKey() string {
+	return base64.StdEncoding.EncodeToString(s.clientKey[:])
+}
+
+// Open decrypts the given base64 encoded message sent by the client.
+func (s *Session) Open(message string, nonce string) ([]byte, error) {
+	n, err := decodeNonce(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := base64.StdEncoding.DecodeString(message)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	plaintext, ok := box.Open(nil, b, n, s.clientKey, s.privateKey)
+	if !ok {
+		return nil, ErrDecrypt
+	}
+
+	return plaintext, nil
+}
+
+// Seal encrypts plaintext for the client, returning the base64 encoded message.
+func (s *Session) Seal(plaintext []byte, nonce string) (string, error) {
+	n, err := decodeNonce(nonce)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(box.Seal(nil, plaintext, n, s.clientKey, s.privateKey)), nil
+}
+
+// IncrementNonce returns the given base64 encoded nonce incremented by one,
+// as a little-endian number.
+func IncrementNonce(nonce string) (string, error) {
+	n, err := decodeNonce(nonce)
+	if err != nil {
+		return "", err
+	}
+
+	for i := range n {
+		n[i]++
+		if n[i] != 0 {
+			break
+		}
+	}
+
+	return base64.StdEncoding.EncodeToString(n[:]), nil
+}
+
+func decodeKey(s string) (*[KeySize]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(b) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	return (*[KeySize]byte)(b), nil
+}
+
+func decodeNonce(s string) (*[NonceSize]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(b) != NonceSize {
+		return nil, ErrInvalidNonce
+	}
+
+	return (*[NonceSize]byte)(b), nil
+}
@@ -0,0 +1,126 @@
+package keepassxc_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/keepassxc"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+func TestMessage(t *testing.T) {
+	var buf bytes.Buffer
+
+	for _, msg := range []string{`{"action":"get-databasehash"}`, ""} {
+		if err := keepassxc.WriteMessage(&buf, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, want := range []string{`{"action":"get-databasehash"}`, ""} {
+		got, err := keepassxc.ReadMessage(&buf, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != want {
+			t.Errorf("read message: got %q, want %q", got, want)
+		}
+	}
+
+	if err := keepassxc.WriteMessage(&buf, bytes.Repeat([]byte("x"), 65)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := keepassxc.ReadMessage(&buf, 64); !errors.Is(err, keepassxc.ErrMessageTooLarge) {
+		t.Errorf("read large message: got err %v, want %v", err, keepassxc.ErrMessageTooLarge)
+	}
+}
+
+func TestIncrementNonce(t *testing.T) {
+	b64 := base64.StdEncoding.EncodeToString
+
+	tests := []struct {
+		name  string
+		nonce []byte
+		want  []byte
+	}{
+		{"zero", make([]byte, 24), append([]byte{1}, make([]byte, 23)...)},
+		{"carry", append([]byte{0xff, 0xff, 7}, make([]byte, 21)...), append([]byte{0, 0, 8}, make([]byte, 21)...)},
+		{"overflow", bytes.Repeat([]byte{0xff}, 24), make([]byte, 24)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := keepassxc.IncrementNonce(b64(tt.nonce))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != b64(tt.want) {
+				t.Errorf("got %s, want %s", got, b64(tt.want))
+			}
+		})
+	}
+
+	if _, err := keepassxc.IncrementNonce(b64(make([]byte, 12))); !errors.Is(err, keepassxc.ErrInvalidNonce) {
+		t.Errorf("short nonce: got err %v, want %v", err, keepassxc.ErrInvalidNonce)
+	}
+}
+
+func TestSession(t *testing.T) {
+	clientPublic, clientPrivate, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := keepassxc.NewSession(base64.StdEncoding.EncodeToString(clientPublic[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverPublic, err := base64.StdEncoding.DecodeString(s.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var nonce [keepassxc.NonceSize]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		t.Fatal(err)
+	}
+
+	b64Nonce := base64.StdEncoding.EncodeToString(nonce[:])
+	sealed := box.Seal(nil, []byte("request"), &nonce, (*[keepassxc.KeySize]byte)(serverPublic), clientPrivate)
+
+	got, err := s.Open(base64.StdEncoding.EncodeToString(sealed), b64Nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != "request" {
+		t.Errorf("open: got %q, want %q", got, "request")
+	}
+
+	res, err := s.Seal([]byte("response"), b64Nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opened, ok := box.Open(nil, b, &nonce, (*[keepassxc.KeySize]byte)(serverPublic), clientPrivate)
+	if !ok || string(opened) != "response" {
+		t.Errorf("seal: got (%q, %t), want (%q, true)", opened, ok, "response")
+	}
+
+	if _, err := s.Open(base64.StdEncoding.EncodeToString(sealed[1:]), b64Nonce); !errors.Is(err, keepassxc.ErrDecrypt) {
+		t.Errorf("open tampered message: got err %v, want %v", err, keepassxc.ErrDecrypt)
+	}
+}
@@ -0,0 +1,154 @@
+// Package keychain stores secrets in the macOS login Keychain using the
+// external `security` command, e.g., to cache the vault key between sessions.
+//
+// Items are created without trusted applications, so that macOS asks the user
+// to allow each read, including reads by `security` itself. Values are passed
+// to `security` on stdin, never as command line arguments.
+//
+// Touch ID is not supported: requiring biometry to read an item takes access
+// control flags that only the Security framework sets, which needs CGo and a
+// signed binary entitled to the data protection Keychain, while vlt is a
+// CGo-free, unsigned binary. Reads are allowed in the password prompt of macOS.
+package keychain
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	securityCmd = "security"
+
+	// service is the Keychain service items are stored under.
+	service = "vlt"
+
+	// errSecItemNotFound is the exit status of `security` for missing items.
+	errSecItemNotFound = 44
+)
+
+var (
+	// ErrUnsupported indicates a platform without a Keychain.
+	ErrUnsupported = errors.New("keychain: not supported on " + runtime.GOOS)
+
+	// ErrNotFound indicates a missing Keychain item.
+	ErrNotFound = errors.New("keychain: item not found")
+
+	// ErrAccount indicates an account that cannot be passed to `security`.
+	ErrAccount = errors.New("keychain: account must not contain quotes, backslashes or newlines")
+)
+
+// CommandError wraps a failed security invocation along with its stderr output.
+type CommandError struct {
+	Op     string
+	Stderr string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	msg := "keychain: " + e.Op + ": " + e.Err.Error()
+	if s := strings.TrimSpace(e.Stderr); len(s) > 0 {
+		msg += ": " + s
+	}
+
+	return msg
+}
+
+func (e *CommandError) Unwrap() error { return e.Err }
+
+// Store stores value under the given account, replacing any existing item.
+func Store(ctx context.Context, account string, value []byte) error {
+	cmd, err := addCommand(account, value)
+	if err != nil {
+		return err
+	}
+
+	_, err = run(ctx, "store", []byte(cmd), "-i")
+
+	return err
+}
+
+// Load returns the value stored under the given account,
+// or [ErrNotFound] if there is none.
+//
+// macOS asks the user to allow the read, Load fails if denied.
+func Load(ctx context.Context, account string) ([]byte, error) {
+	out, err := run(ctx, "load", nil, "find-generic-password", "-s", service, "-a", account, "-w")
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := hex.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, &CommandError{Op: "load", Err: err}
+	}
+
+	return value, nil
+}
+
+// Delete removes the item stored under the given account,
+// or returns [ErrNotFound] if there is none.
+func Delete(ctx context.Context, account string) error {
+	_, err := run(ctx, "delete", nil, "delete-generic-password", "-s", service, "-a", account)
+	return err
+}
+
+// addCommand returns the `security -i` command storing value under account.
+//
+// The value is hex encoded, keeping it printable, and the empty trusted
+// application list ('-T ""') makes macOS confirm every read.
+func addCommand(account string, value []byte) (string, error) {
+	if strings.ContainsAny(account, "\"\\\n") {
+		return "", ErrAccount
+	}
+
+	quoted := `"` + account + `"`
+
+	args := []string{
+		"add-generic-password", "-U",
+		"-s", service,
+		"-a", quoted,
+		"-l", `"vlt: ` + account + `"`,
+		"-T", `""`,
+		"-w", hex.EncodeToString(value),
+	}
+
+	return strings.Join(args, " ") + "\n", nil
+}
+
+func run(ctx context.Context, op string, stdin []byte, args ...string) ([]byte, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, ErrUnsupported
+	}
+
+	if _, err := exec.LookPath(securityCmd); err != nil {
+		return nil, &CommandError{Op: op, Err: err}
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, securityCmd, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == errSecItemNotFound {
+			return nil, ErrNotFound
+		}
+
+		return nil, &CommandError{Op: op, Stderr: stderr.String(), Err: err}
+	}
+
+	// in interactive mode, failed commands are reported on stderr only.
+	if len(stdin) > 0 && len(bytes.TrimSpace(stderr.Bytes())) > 0 {
+		return nil, &CommandError{Op: op, Stderr: stderr.String(), Err: errors.New("command failed")}
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,56 @@
+package keychain
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestAddCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		account string
+		value   []byte
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "path",
+			account: "/Users/me/.vlt",
+			value:   []byte{0x00, 0xff, 'k'},
+			want:    `add-generic-password -U -s vlt -a "/Users/me/.vlt" -l "vlt: /Users/me/.vlt" -T "" -w 00ff6b` + "\n",
+		},
+		{
+			name:    "spaces",
+			account: "/Users/me/My Vaults/work.vlt",
+			value:   []byte("k"),
+			want:    `add-generic-password -U -s vlt -a "/Users/me/My Vaults/work.vlt" -l "vlt: /Users/me/My Vaults/work.vlt" -T "" -w 6b` + "\n",
+		},
+		{name: "quote", account: `/tmp/"vlt`, wantErr: ErrAccount},
+		{name: "backslash", account: `/tmp/\vlt`, wantErr: ErrAccount},
+		{name: "newline", account: "/tmp/\nvlt", wantErr: ErrAccount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := addCommand(tt.account, tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("addCommand() error: got %v, want %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("addCommand():\ngot  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("the Keychain is supported on macOS")
+	}
+
+	if _, err := Load(t.Context(), "/tmp/vlt"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Load() error: got %v, want %v", err, ErrUnsupported)
+	}
+}
@@ -0,0 +1,81 @@
+// Package notify shows desktop notifications using the external
+// `notify-send` command, or `osascript` on macOS.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	notifySendCmd = "notify-send"
+	osascriptCmd  = "osascript"
+
+	appName = "vlt"
+)
+
+// ErrUnsupported indicates a platform without a supported notification command.
+var ErrUnsupported = errors.New("notify: desktop notifications are not supported on " + runtime.GOOS)
+
+// CommandError wraps a failed notification command along with its stderr output.
+type CommandError struct {
+	Cmd    string
+	Stderr string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	msg := "notify: " + e.Cmd + ": " + e.Err.Error()
+	if s := strings.TrimSpace(e.Stderr); len(s) > 0 {
+		msg += ": " + s
+	}
+
+	return msg
+}
+
+func (e *CommandError) Unwrap() error { return e.Err }
+
+// Send shows a desktop notification with the given title and body.
+func Send(ctx context.Context, title string, body string) error {
+	name, args, err := command(title, body)
+	if err != nil {
+		return err
+	}
+
+	if _, err := exec.LookPath(name); err != nil {
+		return &CommandError{Cmd: name, Err: err}
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return &CommandError{Cmd: name, Stderr: stderr.String(), Err: err}
+	}
+
+	return nil
+}
+
+// command returns the command showing the notification on the current platform.
+func command(title string, body string) (string, []string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		script := "display notification " + appleScriptQuote(body) + " with title " + appleScriptQuote(title)
+		return osascriptCmd, []string{"-e", script}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return notifySendCmd, []string{"--app-name", appName, title, body}, nil
+	default:
+		return "", nil, ErrUnsupported
+	}
+}
+
+// appleScriptQuote returns s as an AppleScript string literal.
+func appleScriptQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
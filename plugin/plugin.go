@@ -0,0 +1,272 @@
+// Package plugin discovers and runs exec-based vlt plugins.
+//
+// Plugins are executables named 'vlt-<name>' found on PATH. Unknown vlt
+// sub-commands are passed through to them, e.g., 'vlt foo --bar' runs
+// 'vlt-foo --bar', so plugins can add commands of their own.
+//
+// Plugins may also extend vlt itself as link providers, importers and
+// clipboard backends by implementing the plugin protocol: when run with
+// the single [ProtocolArg] argument, a plugin reads one JSON [Request]
+// from stdin and writes one JSON [Response] to stdout. A plugin not
+// supporting the requested operation responds with an error.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// Prefix is the executable name prefix of plugins.
+	Prefix = "vlt-"
+
+	// ProtocolArg is the argument plugins are run with to serve a protocol request.
+	ProtocolArg = "--vlt-plugin"
+
+	// ProtocolVersion is the version of the plugin protocol, sent with each request.
+	ProtocolVersion = 1
+
+	// defaultTimeout bounds a single protocol request.
+	defaultTimeout = 30 * time.Second
+)
+
+// Op is a plugin protocol operation.
+type Op string
+
+const (
+	// OpResolve resolves a linked secret, see [Plugin.Resolve].
+	OpResolve Op = "resolve"
+
+	// OpImport converts an input file to secrets, see [Plugin.Import].
+	OpImport Op = "import"
+
+	// OpCopy writes a value to the clipboard, see [Plugin.Copy].
+	OpCopy Op = "copy"
+
+	// OpPaste reads the clipboard, see [Plugin.Paste].
+	OpPaste Op = "paste"
+)
+
+// Request is a plugin protocol request, read by the plugin from stdin.
+type Request struct {
+	Version int    `json:"version"`
+	Op      Op     `json:"op"`
+	Ref     *Ref   `json:"ref,omitempty"`   // Ref is the link to resolve, set for [OpResolve].
+	Input   string `json:"input,omitempty"` // Input is the content of the file to import, set for [OpImport].
+	Value   string `json:"value,omitempty"` // Value is the value to copy, set for [OpCopy].
+	Hints   []Hint `json:"hints,omitempty"` // Hints are the clipboard formats to offer alongside the value, set for [OpCopy].
+}
+
+// Ref is a parsed link URI, '<scheme>://<path>#<field>'.
+type Ref struct {
+	Scheme string `json:"scheme"`
+	Path   string `json:"path"`
+	Field  string `json:"field,omitempty"`
+}
+
+// Hint is a clipboard format offered alongside a copied value.
+type Hint struct {
+	Format string `json:"format"`
+	Value  string `json:"value,omitempty"`
+}
+
+// Secret is a secret converted by an importer plugin.
+type Secret struct {
+	Name   string   `json:"name"`
+	Secret string   `json:"secret"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+// Response is a plugin protocol response, written by the plugin to stdout.
+type Response struct {
+	Value   string   `json:"value,omitempty"`   // Value is the resolved or pasted value.
+	Secrets []Secret `json:"secrets,omitempty"` // Secrets are the imported secrets.
+	Error   string   `json:"error,omitempty"`   // Error reports a failed request, if set.
+}
+
+// Error wraps a failed plugin invocation along with its stderr output, if captured.
+type Error struct {
+	Plugin string
+	Op     Op
+	Stderr string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	msg := "plugin " + e.Plugin + ": " + string(e.Op) + ": " + e.Err.Error()
+	if s := strings.TrimSpace(e.Stderr); len(s) > 0 {
+		msg += ": " + s
+	}
+
+	return msg
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Plugin is a plugin executable.
+type Plugin struct {
+	Name string // Name is the plugin name, without the [Prefix].
+	Path string // Path is the path of the executable.
+}
+
+// Find returns the plugin of the given name, looked up on PATH.
+func Find(name string) (Plugin, error) {
+	if len(name) == 0 || strings.ContainsAny(name, `/\`) {
+		return Plugin{}, fmt.Errorf("plugin: invalid name %q", name)
+	}
+
+	path, err := exec.LookPath(Prefix + name)
+	if err != nil {
+		return Plugin{}, fmt.Errorf("plugin: %w", err)
+	}
+
+	return Plugin{Name: name, Path: path}, nil
+}
+
+// List returns the plugins found on PATH, sorted by name.
+// A plugin found in several directories is returned once,
+// as found first, like [exec.LookPath].
+func List() []Plugin {
+	plugins := map[string]Plugin{}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue // missing or unreadable directories are skipped, like exec.LookPath.
+		}
+
+		for _, e := range entries {
+			name, ok := pluginName(e.Name())
+			if !ok || e.IsDir() {
+				continue
+			}
+
+			if _, ok := plugins[name]; ok {
+				continue
+			}
+
+			path := filepath.Join(dir, e.Name())
+			if _, err := exec.LookPath(path); err != nil {
+				continue // not executable.
+			}
+
+			plugins[name] = Plugin{Name: name, Path: path}
+		}
+	}
+
+	sorted := make([]Plugin, 0, len(plugins))
+	for _, name := range slices.Sorted(maps.Keys(plugins)) {
+		sorted = append(sorted, plugins[name])
+	}
+
+	return sorted
+}
+
+// pluginName returns the plugin name of the given executable file name.
+func pluginName(file string) (string, bool) {
+	name, ok := strings.CutPrefix(file, Prefix)
+	if !ok {
+		return "", false
+	}
+
+	if ext := filepath.Ext(name); len(ext) > 0 && strings.EqualFold(ext, ".exe") {
+		name = strings.TrimSuffix(name, ext)
+	}
+
+	return name, len(name) > 0
+}
+
+// Exec runs the plugin as a vlt sub-command with the given arguments and stdio.
+func (p Plugin) Exec(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	//nolint:gosec // G204: plugins are executables installed by the user.
+	cmd := exec.CommandContext(ctx, p.Path, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	return cmd.Run()
+}
+
+// Call sends the given request to the plugin and returns its response.
+// A response reporting an error is returned as an [*Error].
+func (p Plugin) Call(ctx context.Context, req Request) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	req.Version = ProtocolVersion
+
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, &Error{Plugin: p.Name, Op: req.Op, Err: err}
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	//nolint:gosec // G204: plugins are executables installed by the user.
+	cmd := exec.CommandContext(ctx, p.Path, ProtocolArg)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, &Error{Plugin: p.Name, Op: req.Op, Stderr: stderr.String(), Err: err}
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, &Error{Plugin: p.Name, Op: req.Op, Stderr: stderr.String(), Err: fmt.Errorf("invalid response: %w", err)}
+	}
+
+	if len(resp.Error) > 0 {
+		return nil, &Error{Plugin: p.Name, Op: req.Op, Err: errors.New(resp.Error)}
+	}
+
+	return &resp, nil
+}
+
+// Import returns the secrets the plugin converts the given input to.
+func (p Plugin) Import(ctx context.Context, input []byte) ([]Secret, error) {
+	resp, err := p.Call(ctx, Request{Op: OpImport, Input: string(input)})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Secrets, nil
+}
+
+// Resolve returns the value of the linked secret referenced by ref.
+func (p Plugin) Resolve(ctx context.Context, ref Ref) (string, error) {
+	resp, err := p.Call(ctx, Request{Op: OpResolve, Ref: &ref})
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Value, nil
+}
+
+// Copy writes the given value to the clipboard, offering the given hints alongside it.
+func (p Plugin) Copy(ctx context.Context, value string, hints []Hint) error {
+	_, err := p.Call(ctx, Request{Op: OpCopy, Value: value, Hints: hints})
+	return err
+}
+
+// Paste returns the contents of the clipboard.
+func (p Plugin) Paste(ctx context.Context) (string, error) {
+	resp, err := p.Call(ctx, Request{Op: OpPaste})
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Value, nil
+}
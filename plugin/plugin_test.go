@@ -0,0 +1,95 @@
+package plugin_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/plugin"
+)
+
+// echoPlugin responds to protocol requests with the request it read,
+// as the value of a resolve response, and fails on other ops.
+const echoPlugin = `#!/bin/sh
+[ "$1" = "--vlt-plugin" ] || { echo "args: $*"; exit 3; }
+req=$(cat)
+case "$req" in
+*'"op":"resolve"'*) printf '{"value":"%s"}' "$(printf '%s' "$req" | sed 's/"/\\"/g')" ;;
+*'"op":"import"'*) echo '{"secrets":[{"name":"db","secret":"pw","labels":["prod"]}]}' ;;
+*) echo '{"error":"unsupported op"}' ;;
+esac
+`
+
+func writePlugin(t *testing.T, dir string, name string, content string, mode os.FileMode) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not executable on windows")
+	}
+
+	first, second := t.TempDir(), t.TempDir()
+
+	writePlugin(t, first, "vlt-echo", echoPlugin, 0o755)
+	writePlugin(t, second, "vlt-echo", echoPlugin, 0o755)
+	writePlugin(t, second, "vlt-noexec", echoPlugin, 0o644)
+	writePlugin(t, second, "other", echoPlugin, 0o755)
+
+	t.Setenv("PATH", strings.Join([]string{first, second, os.Getenv("PATH")}, string(filepath.ListSeparator)))
+
+	// ignore plugins installed on the host.
+	plugins := slices.DeleteFunc(plugin.List(), func(p plugin.Plugin) bool {
+		dir := filepath.Dir(p.Path)
+		return dir != first && dir != second
+	})
+
+	if want := []plugin.Plugin{{Name: "echo", Path: filepath.Join(first, "vlt-echo")}}; !slices.Equal(plugins, want) {
+		t.Fatalf("List() = %v, want %v", plugins, want)
+	}
+
+	if _, err := plugin.Find("../echo"); err == nil {
+		t.Error("Find(../echo): got nil err")
+	}
+
+	p, err := plugin.Find("echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := p.Resolve(t.Context(), plugin.Ref{Scheme: "echo", Path: "db", Field: "password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"version":1,"op":"resolve","ref":{"scheme":"echo","path":"db","field":"password"}}`; got != want {
+		t.Errorf("Resolve() = %s, want %s", got, want)
+	}
+
+	secrets, err := p.Import(t.Context(), []byte("input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secrets) != 1 || secrets[0].Name != "db" || secrets[0].Secret != "pw" || !slices.Equal(secrets[0].Labels, []string{"prod"}) {
+		t.Errorf("Import() = %+v", secrets)
+	}
+
+	var pluginErr *plugin.Error
+	if _, err := p.Paste(t.Context()); !errors.As(err, &pluginErr) || pluginErr.Err.Error() != "unsupported op" {
+		t.Errorf("Paste(): got err %v, want unsupported op", err)
+	}
+
+	var exitErr interface{ ExitCode() int }
+	if err := p.Exec(t.Context(), []string{"a"}, nil, nil, nil); !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("Exec(): got err %v, want exit status 3", err)
+	}
+}
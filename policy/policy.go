@@ -0,0 +1,281 @@
+// Package policy implements password policies evaluated
+// when storing or generating secrets.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ladzaretti/vlt-cli/randstring"
+)
+
+// maxGenerateAttempts bounds the number of attempts made by [Policy.Generate]
+// to produce a password that does not contain a banned word.
+const maxGenerateAttempts = 100
+
+var ErrGenerate = errors.New("could not generate a password satisfying the policy")
+
+// Mode controls how policy violations are handled.
+type Mode string
+
+const (
+	ModeWarn    Mode = "warn"    // ModeWarn reports violations but allows the operation.
+	ModeEnforce Mode = "enforce" // ModeEnforce rejects operations that violate the policy.
+)
+
+// Modes lists the supported policy modes.
+var Modes = []Mode{ModeWarn, ModeEnforce}
+
+// IsValidMode reports whether m is a supported policy mode.
+func IsValidMode(m string) bool {
+	return slices.Contains(Modes, Mode(m))
+}
+
+// Class is a character class a password may be required to contain.
+type Class string
+
+const (
+	ClassUpper   Class = "upper"
+	ClassLower   Class = "lower"
+	ClassDigit   Class = "digit"
+	ClassSpecial Class = "special"
+)
+
+// Classes lists the supported character classes.
+var Classes = []Class{ClassUpper, ClassLower, ClassDigit, ClassSpecial}
+
+// IsValidClass reports whether c is a supported character class.
+func IsValidClass(c string) bool {
+	return slices.Contains(Classes, Class(c))
+}
+
+func (c Class) matches(r rune) bool {
+	switch c {
+	case ClassUpper:
+		return unicode.IsUpper(r)
+	case ClassLower:
+		return unicode.IsLower(r)
+	case ClassDigit:
+		return unicode.IsDigit(r)
+	case ClassSpecial:
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+	default:
+		return false
+	}
+}
+
+// Rule identifies the policy rule a [Violation] refers to.
+type Rule string
+
+const (
+	RuleMinLength       Rule = "min_length"
+	RuleRequiredClasses Rule = "required_classes"
+	RuleBannedWords     Rule = "banned_words"
+	RuleMaxAge          Rule = "max_age"
+	RuleRequire2FA      Rule = "require_2fa"
+)
+
+// TwoFactorLabel is the label marking secrets of accounts
+// protected by a second factor, see [Policy.Require2FA].
+const TwoFactorLabel = "2fa"
+
+// Violation describes a single policy rule a secret fails to satisfy.
+type Violation struct {
+	Rule    Rule
+	Message string
+}
+
+func (v Violation) String() string { return string(v.Rule) + ": " + v.Message }
+
+// ViolationError is returned when a secret violates an enforced policy.
+type ViolationError struct {
+	Violations []Violation
+}
+
+func (e *ViolationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+
+	return "password policy violation: " + strings.Join(msgs, "; ")
+}
+
+// Policy defines the requirements secrets are evaluated against.
+// The zero value imposes no requirements.
+type Policy struct {
+	MinLength       int           // MinLength is the minimum number of characters.
+	RequiredClasses []Class       // RequiredClasses must each appear at least once.
+	BannedWords     []string      // BannedWords must not appear, matched case-insensitively.
+	MaxAge          time.Duration // MaxAge is the maximum time since the secret was last changed, zero disables the check.
+	Require2FA      bool          // Require2FA requires secrets to be labeled with [TwoFactorLabel].
+}
+
+// IsZero reports whether the policy imposes no requirements.
+func (p Policy) IsZero() bool {
+	return p.MinLength == 0 && len(p.RequiredClasses) == 0 && len(p.BannedWords) == 0 && p.MaxAge == 0 && !p.Require2FA
+}
+
+// Merge returns the stricter combination of p and other.
+//
+// Length requirements are maximized, the shorter non-zero maximum age wins,
+// and classes and banned words are combined.
+func (p Policy) Merge(other Policy) Policy {
+	merged := Policy{
+		MinLength:       max(p.MinLength, other.MinLength),
+		RequiredClasses: slices.Clone(p.RequiredClasses),
+		BannedWords:     slices.Clone(p.BannedWords),
+		MaxAge:          p.MaxAge,
+		Require2FA:      p.Require2FA || other.Require2FA,
+	}
+
+	for _, c := range other.RequiredClasses {
+		if !slices.Contains(merged.RequiredClasses, c) {
+			merged.RequiredClasses = append(merged.RequiredClasses, c)
+		}
+	}
+
+	for _, w := range other.BannedWords {
+		if !slices.Contains(merged.BannedWords, w) {
+			merged.BannedWords = append(merged.BannedWords, w)
+		}
+	}
+
+	if other.MaxAge > 0 && (merged.MaxAge == 0 || other.MaxAge < merged.MaxAge) {
+		merged.MaxAge = other.MaxAge
+	}
+
+	return merged
+}
+
+// Check evaluates the given secret value against the policy.
+// [Policy.MaxAge] is not considered, see [Policy.CheckAge].
+func (p Policy) Check(secret string) []Violation {
+	var violations []Violation
+
+	if n := len([]rune(secret)); n < p.MinLength {
+		violations = append(violations, Violation{
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("length %d is below the minimum of %d", n, p.MinLength),
+		})
+	}
+
+	var missing []string
+
+	for _, c := range p.RequiredClasses {
+		if !strings.ContainsFunc(secret, c.matches) {
+			missing = append(missing, string(c))
+		}
+	}
+
+	if len(missing) > 0 {
+		violations = append(violations, Violation{
+			Rule:    RuleRequiredClasses,
+			Message: "missing " + strings.Join(missing, ", ") + " characters",
+		})
+	}
+
+	var banned []string
+
+	lower := strings.ToLower(secret)
+	for _, w := range p.BannedWords {
+		if len(w) > 0 && strings.Contains(lower, strings.ToLower(w)) {
+			banned = append(banned, w)
+		}
+	}
+
+	if len(banned) > 0 {
+		violations = append(violations, Violation{
+			Rule:    RuleBannedWords,
+			Message: "contains banned words: " + strings.Join(banned, ", "),
+		})
+	}
+
+	return violations
+}
+
+// CheckAge evaluates the time a secret was last changed against [Policy.MaxAge].
+// The second return value is false if the secret is within the allowed age.
+func (p Policy) CheckAge(changedAt time.Time, now time.Time) (Violation, bool) {
+	if p.MaxAge <= 0 || changedAt.IsZero() {
+		return Violation{}, false
+	}
+
+	age := now.Sub(changedAt)
+	if age <= p.MaxAge {
+		return Violation{}, false
+	}
+
+	return Violation{
+		Rule:    RuleMaxAge,
+		Message: fmt.Sprintf("last changed %s ago, exceeds the maximum age of %s", age.Round(time.Hour), p.MaxAge),
+	}, true
+}
+
+// ExpiresAt returns the time a secret last changed at changedAt exceeds [Policy.MaxAge].
+// The second return value is false if the policy sets no maximum age.
+func (p Policy) ExpiresAt(changedAt time.Time) (time.Time, bool) {
+	if p.MaxAge <= 0 || changedAt.IsZero() {
+		return time.Time{}, false
+	}
+
+	return changedAt.Add(p.MaxAge), true
+}
+
+// CheckLabels evaluates the labels of a secret against [Policy.Require2FA].
+// The second return value is false if the labels satisfy the policy.
+func (p Policy) CheckLabels(labels []string) (Violation, bool) {
+	if !p.Require2FA || slices.Contains(labels, TwoFactorLabel) {
+		return Violation{}, false
+	}
+
+	return Violation{
+		Rule:    RuleRequire2FA,
+		Message: fmt.Sprintf("missing the %q label marking the account as protected by a second factor", TwoFactorLabel),
+	}, true
+}
+
+// GeneratorPolicy returns base adjusted so that generated passwords
+// satisfy the policy length and character class requirements.
+func (p Policy) GeneratorPolicy(base randstring.PasswordPolicy) randstring.PasswordPolicy {
+	base.MinLength = max(base.MinLength, p.MinLength)
+
+	for _, c := range p.RequiredClasses {
+		switch c {
+		case ClassUpper:
+			base.MinUppercase = max(base.MinUppercase, 1)
+		case ClassLower:
+			base.MinLowercase = max(base.MinLowercase, 1)
+		case ClassDigit:
+			base.MinNumeric = max(base.MinNumeric, 1)
+		case ClassSpecial:
+			base.MinSpecial = max(base.MinSpecial, 1)
+		}
+	}
+
+	return base
+}
+
+// Generate returns a random password generated using the given base
+// generator policy, adjusted by [Policy.GeneratorPolicy],
+// that satisfies the policy.
+func (p Policy) Generate(base randstring.PasswordPolicy) (string, error) {
+	gen := p.GeneratorPolicy(base)
+
+	for range maxGenerateAttempts {
+		s, err := randstring.NewWithPolicy(gen)
+		if err != nil {
+			return "", err
+		}
+
+		if len(p.Check(s)) == 0 {
+			return s, nil
+		}
+	}
+
+	return "", ErrGenerate
+}
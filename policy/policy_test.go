@@ -0,0 +1,126 @@
+package policy_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/policy"
+	"github.com/ladzaretti/vlt-cli/randstring"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	p := policy.Policy{
+		MinLength:       10,
+		RequiredClasses: []policy.Class{policy.ClassUpper, policy.ClassDigit, policy.ClassSpecial},
+		BannedWords:     []string{"password"},
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		want   []policy.Rule
+	}{
+		{name: "compliant", secret: "Tr0ub4dor&3x"},
+		{name: "too short", secret: "Tr0ub4&", want: []policy.Rule{policy.RuleMinLength}},
+		{name: "missing classes", secret: "troubadorxx", want: []policy.Rule{policy.RuleRequiredClasses}},
+		{name: "banned word", secret: "MyPassWord1!", want: []policy.Rule{policy.RuleBannedWords}},
+		{
+			name:   "multiple violations",
+			secret: "password",
+			want:   []policy.Rule{policy.RuleMinLength, policy.RuleRequiredClasses, policy.RuleBannedWords},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []policy.Rule
+			for _, v := range p.Check(tt.secret) {
+				got = append(got, v.Rule)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Check(%q) rules = %v, want %v", tt.secret, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy_CheckAge(t *testing.T) {
+	p := policy.Policy{MaxAge: 24 * time.Hour}
+	now := time.Now()
+
+	if _, ok := p.CheckAge(now.Add(-time.Hour), now); ok {
+		t.Error("unexpected violation for a recently changed secret")
+	}
+
+	if _, ok := p.CheckAge(now.Add(-48*time.Hour), now); !ok {
+		t.Error("expected a violation for an expired secret")
+	}
+}
+
+func TestPolicy_ExpiresAt(t *testing.T) {
+	changedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	got, ok := policy.Policy{MaxAge: 24 * time.Hour}.ExpiresAt(changedAt)
+	if want := changedAt.Add(24 * time.Hour); !ok || !got.Equal(want) {
+		t.Errorf("ExpiresAt() = %v, %t, want %v, true", got, ok, want)
+	}
+
+	if _, ok := (policy.Policy{}).ExpiresAt(changedAt); ok {
+		t.Error("unexpected expiry without a maximum age")
+	}
+}
+
+func TestPolicy_Generate(t *testing.T) {
+	p := policy.Policy{
+		MinLength:       32,
+		RequiredClasses: policy.Classes,
+		BannedWords:     []string{"a", "b"},
+	}
+
+	s, err := p.Generate(randstring.DefaultPasswordPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := p.Check(s); len(v) > 0 {
+		t.Errorf("generated password %q violates the policy: %v", s, v)
+	}
+}
+
+func TestSet_For(t *testing.T) {
+	s := policy.Set{
+		Default: policy.Policy{MinLength: 12, MaxAge: 90 * 24 * time.Hour},
+		Mode:    policy.ModeWarn,
+		Overrides: []policy.Override{
+			{
+				Label:  "prod*",
+				Policy: policy.Policy{MinLength: 24, MaxAge: 30 * 24 * time.Hour, Require2FA: true},
+				Mode:   policy.ModeEnforce,
+			},
+			{
+				Label:  "personal",
+				Policy: policy.Policy{MinLength: 8, RequiredClasses: []policy.Class{policy.ClassDigit}},
+			},
+		},
+	}
+
+	p, mode := s.For([]string{"personal"})
+	if p.MinLength != 12 || mode != policy.ModeWarn || len(p.RequiredClasses) != 1 {
+		t.Errorf("personal: got %+v %s, want the stricter default length in warn mode with the override classes", p, mode)
+	}
+
+	p, mode = s.For([]string{"personal", "prod-db"})
+	if p.MinLength != 24 || p.MaxAge != 30*24*time.Hour || !p.Require2FA || mode != policy.ModeEnforce {
+		t.Errorf("prod: got %+v %s, want the prod override enforced", p, mode)
+	}
+
+	if _, ok := p.CheckLabels([]string{"prod-db", policy.TwoFactorLabel}); ok {
+		t.Error("unexpected 2fa violation for a labeled secret")
+	}
+
+	if _, ok := p.CheckLabels([]string{"prod-db"}); !ok {
+		t.Error("expected a 2fa violation for an unlabeled secret")
+	}
+}
@@ -0,0 +1,66 @@
+package policy
+
+import (
+	"path"
+	"slices"
+)
+
+// Override scopes a policy to secrets with a matching label.
+type Override struct {
+	// Label is a glob pattern matched against secret labels,
+	// using [path.Match] syntax.
+	Label  string
+	Policy Policy
+	Mode   Mode // Mode overrides the set mode if stricter, empty to inherit.
+}
+
+// Set is a default policy along with its label-scoped overrides.
+type Set struct {
+	Default   Policy
+	Mode      Mode
+	Overrides []Override
+}
+
+// IsZero reports whether the set imposes no requirements at all.
+func (s Set) IsZero() bool {
+	if !s.Default.IsZero() {
+		return false
+	}
+
+	for _, o := range s.Overrides {
+		if !o.Policy.IsZero() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// For returns the effective policy and mode for a secret with the given labels.
+//
+// The default policy is merged with every override matching any of the labels,
+// see [Policy.Merge]. Enforcing overrides take precedence over warning ones.
+func (s Set) For(labels []string) (Policy, Mode) {
+	p, mode := s.Default, s.Mode
+
+	for _, o := range s.Overrides {
+		if !o.matches(labels) {
+			continue
+		}
+
+		p = p.Merge(o.Policy)
+
+		if o.Mode == ModeEnforce {
+			mode = ModeEnforce
+		}
+	}
+
+	return p, mode
+}
+
+func (o Override) matches(labels []string) bool {
+	return slices.ContainsFunc(labels, func(l string) bool {
+		ok, err := path.Match(o.Label, l)
+		return err == nil && ok
+	})
+}
@@ -0,0 +1,426 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	envAWSRegion          = "AWS_REGION"
+	envAWSDefaultRegion   = "AWS_DEFAULT_REGION"
+	envAWSProfile         = "AWS_PROFILE"
+	envAWSAccessKeyID     = "AWS_ACCESS_KEY_ID"
+	envAWSSecretAccessKey = "AWS_SECRET_ACCESS_KEY" //nolint:gosec // environment variable name.
+	envAWSSessionToken    = "AWS_SESSION_TOKEN"
+	envAWSCredentialsFile = "AWS_SHARED_CREDENTIALS_FILE"
+
+	awsService        = "secretsmanager"
+	awsDefaultProfile = "default"
+
+	// awsLabelTagPrefix prefixes the keys of the tags vault labels are mapped to.
+	awsLabelTagPrefix = "vlt:label:"
+)
+
+// AWS stores and resolves secrets in AWS Secrets Manager, using its JSON API.
+//
+// Link paths are secret names or ARNs, e.g., 'aws://prod/db#password'.
+// Without a field, the secret string is returned as is, otherwise
+// it is parsed as a JSON object and the field is selected.
+//
+// Vault labels are mapped to tags with empty values, keyed by the label
+// prefixed by 'vlt:label:'.
+type AWS struct {
+	region   string
+	profile  string
+	endpoint string
+	creds    *awsCredentials
+	http     *http.Client
+}
+
+type AWSOpt func(*AWS)
+
+// WithAWSRegion overrides the region set by the AWS_REGION environment variable.
+func WithAWSRegion(region string) AWSOpt {
+	return func(a *AWS) {
+		if len(region) > 0 {
+			a.region = region
+		}
+	}
+}
+
+// WithAWSProfile overrides the shared credentials profile set by the AWS_PROFILE environment variable.
+func WithAWSProfile(profile string) AWSOpt {
+	return func(a *AWS) {
+		if len(profile) > 0 {
+			a.profile = profile
+		}
+	}
+}
+
+// WithAWSEndpoint overrides the regional Secrets Manager endpoint.
+func WithAWSEndpoint(endpoint string) AWSOpt {
+	return func(a *AWS) {
+		if len(endpoint) > 0 {
+			a.endpoint = strings.TrimSuffix(endpoint, "/")
+		}
+	}
+}
+
+// WithAWSCredentials sets the credentials requests are signed with,
+// instead of looking them up on each request.
+func WithAWSCredentials(accessKeyID, secretAccessKey, sessionToken string) AWSOpt {
+	return func(a *AWS) {
+		a.creds = &awsCredentials{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, SessionToken: sessionToken}
+	}
+}
+
+// WithAWSHTTPClient sets the http client used for requests.
+func WithAWSHTTPClient(c *http.Client) AWSOpt {
+	return func(a *AWS) {
+		a.http = c
+	}
+}
+
+// NewAWS returns a new [AWS] provider configured by the
+// standard AWS environment variables and the given options.
+func NewAWS(opts ...AWSOpt) *AWS {
+	a := &AWS{
+		region:  cmp.Or(os.Getenv(envAWSRegion), os.Getenv(envAWSDefaultRegion)),
+		profile: cmp.Or(os.Getenv(envAWSProfile), awsDefaultProfile),
+		http:    &http.Client{Timeout: defaultTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Resolve returns the value of the secret referenced by ref.
+func (a *AWS) Resolve(ctx context.Context, ref Ref) (string, error) {
+	value, err := a.secretValue(ctx, ref.Path)
+	if err != nil {
+		return "", err
+	}
+
+	if len(ref.Field) == 0 {
+		return value, nil
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return "", fmt.Errorf("aws: %s: secret is not a JSON object, it has no fields", ref.Path)
+	}
+
+	v, err := selectField(values, ref.Field)
+	if err != nil {
+		return "", fmt.Errorf("aws: %s: %w", ref.Path, err)
+	}
+
+	return v, nil
+}
+
+//nolint:tagliatelle
+type awsTag struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+// List returns the secrets whose names start with prefix, along with their values.
+func (a *AWS) List(ctx context.Context, prefix string) ([]RemoteSecret, error) {
+	var (
+		secrets   []RemoteSecret
+		nextToken string
+	)
+
+	for {
+		in := map[string]any{
+			"Filters":    []map[string]any{{"Key": "name", "Values": []string{prefix}}},
+			"MaxResults": 100,
+		}
+
+		if len(nextToken) > 0 {
+			in["NextToken"] = nextToken
+		}
+
+		var out struct {
+			SecretList []struct {
+				Name string   `json:"Name"`
+				Tags []awsTag `json:"Tags"`
+			} `json:"SecretList"`
+			NextToken string `json:"NextToken"`
+		}
+
+		if err := a.call(ctx, "ListSecrets", in, &out); err != nil {
+			return nil, err
+		}
+
+		for _, s := range out.SecretList {
+			// the name filter matches prefixes of any word in the name.
+			if !strings.HasPrefix(s.Name, prefix) {
+				continue
+			}
+
+			value, err := a.secretValue(ctx, s.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			secrets = append(secrets, RemoteSecret{Name: s.Name, Value: value, Labels: awsLabels(s.Tags)})
+		}
+
+		if nextToken = out.NextToken; len(nextToken) == 0 {
+			return secrets, nil
+		}
+	}
+}
+
+// Put creates or updates the given secret, see [Store].
+func (a *AWS) Put(ctx context.Context, s RemoteSecret, prev *RemoteSecret) error {
+	if prev == nil {
+		return a.call(ctx, "CreateSecret", map[string]any{
+			"Name":               s.Name,
+			"SecretString":       s.Value,
+			"Tags":               awsTags(s.Labels),
+			"ClientRequestToken": newRequestToken(),
+		}, nil)
+	}
+
+	if s.Value != prev.Value {
+		err := a.call(ctx, "PutSecretValue", map[string]any{
+			"SecretId":           s.Name,
+			"SecretString":       s.Value,
+			"ClientRequestToken": newRequestToken(),
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	var removed []string
+
+	for _, l := range prev.Labels {
+		if !slices.Contains(s.Labels, l) {
+			removed = append(removed, awsLabelTagPrefix+l)
+		}
+	}
+
+	if len(removed) > 0 {
+		if err := a.call(ctx, "UntagResource", map[string]any{"SecretId": s.Name, "TagKeys": removed}, nil); err != nil {
+			return err
+		}
+	}
+
+	if len(s.Labels) > 0 && !slices.Equal(slices.Sorted(slices.Values(s.Labels)), slices.Sorted(slices.Values(prev.Labels))) {
+		return a.call(ctx, "TagResource", map[string]any{"SecretId": s.Name, "Tags": awsTags(s.Labels)}, nil)
+	}
+
+	return nil
+}
+
+func (a *AWS) secretValue(ctx context.Context, name string) (string, error) {
+	var out struct {
+		SecretString *string `json:"SecretString"`
+	}
+
+	if err := a.call(ctx, "GetSecretValue", map[string]any{"SecretId": name}, &out); err != nil {
+		return "", err
+	}
+
+	if out.SecretString == nil {
+		return "", fmt.Errorf("aws: %s: binary secrets are not supported", name)
+	}
+
+	return *out.SecretString, nil
+}
+
+// call invokes the given Secrets Manager action.
+func (a *AWS) call(ctx context.Context, action string, in any, out any) error {
+	if len(a.region) == 0 {
+		return fmt.Errorf("aws: no region, set %s", envAWSRegion)
+	}
+
+	creds, err := a.credentials()
+	if err != nil {
+		return fmt.Errorf("aws: %w", err)
+	}
+
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("aws: %s: %w", action, err)
+	}
+
+	endpoint := cmp.Or(a.endpoint, fmt.Sprintf("https://%s.%s.amazonaws.com", awsService, a.region))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("aws: %s: %w", action, err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager."+action)
+
+	if err := signV4(req, creds, a.region, awsService, time.Now()); err != nil {
+		return fmt.Errorf("aws: %s: %w", action, err)
+	}
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("aws: %s: %w", action, err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:wsl
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Type    string `json:"__type"` //nolint:tagliatelle
+			Message string `json:"message"`
+			Msg     string `json:"Message"`
+		}
+
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+
+		// the type may be qualified by a namespace, e.g., 'namespace#Code'.
+		code := e.Type[strings.LastIndex(e.Type, "#")+1:]
+
+		return &StatusError{Provider: "aws", StatusCode: resp.StatusCode, Code: code, Message: cmp.Or(e.Message, e.Msg)}
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("aws: %s: decode response: %w", action, err)
+	}
+
+	return nil
+}
+
+// credentials returns the configured credentials, falling back to the
+// standard environment variables and the shared credentials file.
+func (a *AWS) credentials() (awsCredentials, error) {
+	if a.creds != nil {
+		return *a.creds, nil
+	}
+
+	if id, secret := os.Getenv(envAWSAccessKeyID), os.Getenv(envAWSSecretAccessKey); len(id) > 0 && len(secret) > 0 {
+		return awsCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv(envAWSSessionToken)}, nil
+	}
+
+	path := os.Getenv(envAWSCredentialsFile)
+	if len(path) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return awsCredentials{}, err
+		}
+
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+
+	creds, err := readAWSCredentials(path, a.profile)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("no credentials, set %s and %s: %w", envAWSAccessKeyID, envAWSSecretAccessKey, err)
+	}
+
+	return creds, nil
+}
+
+// readAWSCredentials reads the credentials of the given profile from the shared credentials file.
+func readAWSCredentials(path, profile string) (awsCredentials, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	defer func() { _ = f.Close() }() //nolint:wsl
+
+	var (
+		creds   awsCredentials
+		section string
+	)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		case section != profile:
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.TrimSpace(k) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(v)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(v)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(v)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return awsCredentials{}, err
+	}
+
+	if len(creds.AccessKeyID) == 0 || len(creds.SecretAccessKey) == 0 {
+		return awsCredentials{}, fmt.Errorf("profile %q not found in %s", profile, path)
+	}
+
+	return creds, nil
+}
+
+// awsTags returns the tags the given vault labels are mapped to.
+func awsTags(labels []string) []awsTag {
+	tags := make([]awsTag, 0, len(labels))
+	for _, l := range labels {
+		tags = append(tags, awsTag{Key: awsLabelTagPrefix + l})
+	}
+
+	return tags
+}
+
+// awsLabels returns the vault labels the given tags are mapped from, sorted.
+func awsLabels(tags []awsTag) []string {
+	var labels []string
+
+	for _, t := range tags {
+		if l, ok := strings.CutPrefix(t.Key, awsLabelTagPrefix); ok {
+			labels = append(labels, l)
+		}
+	}
+
+	slices.Sort(labels)
+
+	return labels
+}
+
+// newRequestToken returns a random idempotency token.
+func newRequestToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // never returns an error.
+
+	return hex.EncodeToString(b)
+}
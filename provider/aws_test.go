@@ -0,0 +1,164 @@
+package provider_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/provider"
+)
+
+// fakeSecretsManager serves a subset of the AWS Secrets Manager JSON API.
+type fakeSecretsManager struct {
+	mu      sync.Mutex
+	values  map[string]string
+	tags    map[string]map[string]string
+	actions []string
+}
+
+//nolint:tagliatelle
+type fakeRequest struct {
+	SecretID     string `json:"SecretId"`
+	Name         string
+	SecretString string
+	Tags         []struct{ Key, Value string }
+	TagKeys      []string
+}
+
+func (f *fakeSecretsManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"__type":"UnrecognizedClientException","message":"bad credentials"}`)
+
+		return
+	}
+
+	var in fakeRequest
+	_ = json.NewDecoder(r.Body).Decode(&in)
+
+	action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "secretsmanager.")
+	f.actions = append(f.actions, action)
+
+	id := in.SecretID
+	if _, ok := f.values[id]; !ok && action != "CreateSecret" && action != "ListSecrets" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"__type":"com.amazonaws#ResourceNotFoundException","Message":"not found"}`)
+
+		return
+	}
+
+	switch action {
+	case "ListSecrets":
+		var list []map[string]any
+
+		for _, name := range slices.Sorted(maps.Keys(f.values)) {
+			var tags []map[string]string
+			for k, v := range f.tags[name] {
+				tags = append(tags, map[string]string{"Key": k, "Value": v})
+			}
+
+			list = append(list, map[string]any{"Name": name, "Tags": tags})
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"SecretList": list})
+	case "GetSecretValue":
+		_ = json.NewEncoder(w).Encode(map[string]string{"SecretString": f.values[id]})
+	case "CreateSecret":
+		f.values[in.Name] = in.SecretString
+		f.tags[in.Name] = map[string]string{}
+
+		for _, t := range in.Tags {
+			f.tags[in.Name][t.Key] = t.Value
+		}
+
+		fmt.Fprint(w, `{}`)
+	case "PutSecretValue":
+		f.values[id] = in.SecretString
+		fmt.Fprint(w, `{}`)
+	case "TagResource":
+		for _, t := range in.Tags {
+			f.tags[id][t.Key] = t.Value
+		}
+
+		fmt.Fprint(w, `{}`)
+	case "UntagResource":
+		for _, k := range in.TagKeys {
+			delete(f.tags[id], k)
+		}
+
+		fmt.Fprint(w, `{}`)
+	}
+}
+
+func TestAWS(t *testing.T) {
+	f := &fakeSecretsManager{
+		values: map[string]string{
+			"prod/db": `{"user":"admin","password":"s3cret"}`,
+			"other":   "x",
+		},
+		tags: map[string]map[string]string{"prod/db": {}, "other": {}},
+	}
+
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	aws := provider.NewAWS(
+		provider.WithAWSRegion("us-east-1"),
+		provider.WithAWSEndpoint(srv.URL),
+		provider.WithAWSCredentials("AKID", "secret", ""),
+		provider.WithAWSHTTPClient(srv.Client()),
+	)
+
+	r := provider.NewRegistry()
+	r.Register(provider.SchemeAWS, aws)
+
+	if got, err := r.Resolve(t.Context(), "aws://prod/db#password"); err != nil || got != "s3cret" {
+		t.Errorf("Resolve(aws://prod/db#password) = %q, %v, want %q", got, err, "s3cret")
+	}
+
+	if got, err := r.Resolve(t.Context(), "aws://other"); err != nil || got != "x" {
+		t.Errorf("Resolve(aws://other) = %q, %v, want %q", got, err, "x")
+	}
+
+	var statusErr *provider.StatusError
+	if _, err := r.Resolve(t.Context(), "aws://missing"); !errors.As(err, &statusErr) || statusErr.Code != "ResourceNotFoundException" {
+		t.Errorf("Resolve(aws://missing): got err %v, want ResourceNotFoundException", err)
+	}
+
+	s := provider.RemoteSecret{Name: "vlt/api", Value: "key", Labels: []string{"prod/api", "old"}}
+	if err := aws.Put(t.Context(), s, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := provider.RemoteSecret{Name: "vlt/api", Value: "key", Labels: []string{"prod/api", "new"}}
+	if err := aws.Put(t.Context(), updated, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	if slices.Contains(f.actions, "PutSecretValue") {
+		t.Error("unchanged value was put")
+	}
+
+	secrets, err := aws.List(t.Context(), "vlt/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secrets) != 1 {
+		t.Fatalf("List(vlt/): got %d secrets, want 1", len(secrets))
+	}
+
+	if got := secrets[0]; got.Name != updated.Name || got.Value != updated.Value || !slices.Equal(got.Labels, []string{"new", "prod/api"}) {
+		t.Errorf("List(vlt/) = %+v, want %+v", got, updated)
+	}
+}
@@ -0,0 +1,354 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	envAzureIdentityEndpoint = "IDENTITY_ENDPOINT"
+	envAzureIdentityHeader   = "IDENTITY_HEADER"
+	envAzureClientID         = "AZURE_CLIENT_ID"
+
+	azureAPIVersion       = "7.4"
+	azureDefaultDNSSuffix = "vault.azure.net"
+	azureIMDSEndpoint     = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+	// azureLabelsTag is the secret tag vault labels are stored in.
+	azureLabelsTag = "vlt-labels"
+
+	// azureMaxTagValue is the maximum length of a tag value.
+	azureMaxTagValue = 256
+)
+
+// azureSecretName matches valid Key Vault secret names.
+var azureSecretName = regexp.MustCompile(`^[0-9A-Za-z-]{1,127}$`)
+
+// Azure stores and resolves secrets in Azure Key Vault, using its secrets REST API.
+//
+// Link paths are secret names in the configured key vault, e.g., 'azure://db#password',
+// or key vault names followed by a secret name and an optional version, e.g.,
+// 'azure://my-vault/db/<version>#password'. The latest version is used if none is given.
+// Without a field, the secret value is returned as is, otherwise it is parsed as
+// a JSON object and the field is selected.
+//
+// Pushed values are set as new secret versions. Vault labels are stored
+// as a JSON array in the 'vlt-labels' secret tag.
+type Azure struct {
+	vault     string
+	dnsSuffix string
+	http      *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+type AzureOpt func(*Azure)
+
+// WithAzureVault sets the name of the key vault secrets are stored in.
+func WithAzureVault(name string) AzureOpt {
+	return func(a *Azure) {
+		a.vault = name
+	}
+}
+
+// WithAzureDNSSuffix overrides the key vault DNS suffix, e.g., of a sovereign cloud.
+func WithAzureDNSSuffix(suffix string) AzureOpt {
+	return func(a *Azure) {
+		if len(suffix) > 0 {
+			a.dnsSuffix = suffix
+		}
+	}
+}
+
+// WithAzureToken sets the access token used to authenticate,
+// instead of obtaining one from the azure cli or a managed identity.
+func WithAzureToken(token string) AzureOpt {
+	return func(a *Azure) {
+		a.token = token
+	}
+}
+
+// WithAzureHTTPClient sets the http client used for requests.
+func WithAzureHTTPClient(c *http.Client) AzureOpt {
+	return func(a *Azure) {
+		a.http = c
+	}
+}
+
+// NewAzure returns a new [Azure] provider configured by the given options.
+func NewAzure(opts ...AzureOpt) *Azure {
+	a := &Azure{
+		dnsSuffix: azureDefaultDNSSuffix,
+		http:      &http.Client{Timeout: defaultTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Vault returns the name of the key vault secrets are stored in.
+func (a *Azure) Vault() string { return a.vault }
+
+// Resolve returns the value of the secret referenced by ref.
+func (a *Azure) Resolve(ctx context.Context, ref Ref) (string, error) {
+	vault, name, version := a.vault, ref.Path, ""
+
+	if parts := strings.Split(ref.Path, "/"); len(parts) > 1 {
+		vault, name = parts[0], parts[1]
+		if len(parts) > 2 {
+			version = strings.Join(parts[2:], "/")
+		}
+	}
+
+	var out azureSecret
+	if err := a.call(ctx, http.MethodGet, vault, "secrets/"+name+"/"+version, nil, &out); err != nil {
+		return "", err
+	}
+
+	if len(ref.Field) == 0 {
+		return out.Value, nil
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal([]byte(out.Value), &values); err != nil {
+		return "", fmt.Errorf("azure: %s: secret is not a JSON object, it has no fields", ref.Path)
+	}
+
+	v, err := selectField(values, ref.Field)
+	if err != nil {
+		return "", fmt.Errorf("azure: %s: %w", ref.Path, err)
+	}
+
+	return v, nil
+}
+
+type azureSecret struct {
+	ID         string            `json:"id,omitempty"`
+	Value      string            `json:"value"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Managed    bool              `json:"managed,omitempty"`
+	Attributes *struct {
+		Enabled bool `json:"enabled"`
+	} `json:"attributes,omitempty"`
+}
+
+// List returns the secrets whose names start with prefix, along with their latest values.
+// Disabled secrets, and secrets managed by key vault certificates, are skipped.
+func (a *Azure) List(ctx context.Context, prefix string) ([]RemoteSecret, error) {
+	var secrets []RemoteSecret
+
+	for next := "secrets?maxresults=25"; len(next) > 0; {
+		var out struct {
+			Value    []azureSecret `json:"value"`
+			NextLink string        `json:"nextLink"`
+		}
+
+		if err := a.call(ctx, http.MethodGet, a.vault, next, nil, &out); err != nil {
+			return nil, err
+		}
+
+		for _, s := range out.Value {
+			name := s.ID[strings.LastIndex(s.ID, "/")+1:]
+			if !strings.HasPrefix(name, prefix) || s.Managed || (s.Attributes != nil && !s.Attributes.Enabled) {
+				continue
+			}
+
+			var secret azureSecret
+			if err := a.call(ctx, http.MethodGet, a.vault, "secrets/"+name+"/", nil, &secret); err != nil {
+				return nil, err
+			}
+
+			secrets = append(secrets, RemoteSecret{Name: name, Value: secret.Value, Labels: azureLabels(secret.Tags)})
+		}
+
+		next = ""
+
+		if len(out.NextLink) > 0 {
+			u, err := url.Parse(out.NextLink)
+			if err != nil {
+				return nil, fmt.Errorf("azure: invalid next link: %w", err)
+			}
+
+			next = strings.TrimPrefix(u.Path, "/") + "?" + u.RawQuery
+		}
+	}
+
+	return secrets, nil
+}
+
+// Put sets the value and labels of the given secret as a new version, see [Store].
+func (a *Azure) Put(ctx context.Context, s RemoteSecret, prev *RemoteSecret) error {
+	if !azureSecretName.MatchString(s.Name) {
+		return fmt.Errorf("azure: invalid secret name %q (letters, digits and '-' only)", s.Name)
+	}
+
+	labels, err := json.Marshal(slices.Sorted(slices.Values(s.Labels)))
+	if err != nil {
+		return fmt.Errorf("azure: %w", err)
+	}
+
+	if len(labels) > azureMaxTagValue {
+		return fmt.Errorf("azure: %s: labels exceed the %d characters of a tag value", s.Name, azureMaxTagValue)
+	}
+
+	tags := map[string]string{}
+
+	if prev != nil {
+		if s.Value == prev.Value && slices.Equal(slices.Sorted(slices.Values(s.Labels)), slices.Sorted(slices.Values(prev.Labels))) {
+			return nil
+		}
+
+		// tags are set per version, keep those not set by vlt.
+		var current azureSecret
+		if err := a.call(ctx, http.MethodGet, a.vault, "secrets/"+s.Name+"/", nil, &current); err != nil {
+			return err
+		}
+
+		maps.Copy(tags, current.Tags)
+	}
+
+	tags[azureLabelsTag] = string(labels)
+
+	return a.call(ctx, http.MethodPut, a.vault, "secrets/"+s.Name, azureSecret{Value: s.Value, Tags: tags}, nil)
+}
+
+// call sends a request to the given resource of the key vault secrets API.
+func (a *Azure) call(ctx context.Context, method string, vault string, resource string, in any, out any) error {
+	if len(vault) == 0 {
+		return errors.New("azure: no key vault configured")
+	}
+
+	token, err := a.lookupToken(ctx)
+	if err != nil {
+		return fmt.Errorf("azure: %w", err)
+	}
+
+	var body bytes.Buffer
+
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return fmt.Errorf("azure: %w", err)
+		}
+	}
+
+	u, err := url.Parse(fmt.Sprintf("https://%s.%s/%s", vault, a.dnsSuffix, resource))
+	if err != nil {
+		return fmt.Errorf("azure: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("api-version", azureAPIVersion)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), &body)
+	if err != nil {
+		return fmt.Errorf("azure: %w", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("azure: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:wsl
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+
+		return &StatusError{Provider: "azure", StatusCode: resp.StatusCode, Code: e.Error.Code, Message: e.Error.Message}
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("azure: decode response: %w", err)
+	}
+
+	return nil
+}
+
+// lookupToken returns the configured access token, falling back to the azure cli,
+// and the managed identity of the Azure resource vlt runs on.
+// The token is reused afterwards.
+func (a *Azure) lookupToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.token) > 0 {
+		return a.token, nil
+	}
+
+	resource := "https://" + a.dnsSuffix
+
+	token, cliErr := commandToken(ctx, "az", "account", "get-access-token", "--resource", resource, "--query", "accessToken", "--output", "tsv")
+	if cliErr != nil {
+		var identityErr error
+
+		if token, identityErr = a.managedIdentityToken(ctx, resource); identityErr != nil {
+			return "", fmt.Errorf("no access token, run 'az login' (azure cli: %v, managed identity: %v)", cliErr, identityErr)
+		}
+	}
+
+	a.token = token
+
+	return token, nil
+}
+
+// managedIdentityToken returns an access token of the managed identity of the
+// Azure resource vlt runs on, using the App Service identity endpoint if set,
+// or the instance metadata service otherwise.
+func (a *Azure) managedIdentityToken(ctx context.Context, resource string) (string, error) {
+	q := url.Values{"resource": {resource}}
+	if id := os.Getenv(envAzureClientID); len(id) > 0 {
+		q.Set("client_id", id)
+	}
+
+	if endpoint := os.Getenv(envAzureIdentityEndpoint); len(endpoint) > 0 {
+		q.Set("api-version", "2019-08-01")
+
+		return metadataToken(ctx, a.http, endpoint+"?"+q.Encode(), http.Header{"X-Identity-Header": {os.Getenv(envAzureIdentityHeader)}})
+	}
+
+	q.Set("api-version", "2018-02-01")
+
+	return metadataToken(ctx, a.http, azureIMDSEndpoint+"?"+q.Encode(), http.Header{"Metadata": {"true"}})
+}
+
+// azureLabels returns the vault labels stored in the given secret tags.
+func azureLabels(tags map[string]string) []string {
+	var labels []string
+
+	_ = json.Unmarshal([]byte(tags[azureLabelsTag]), &labels) // not set by vlt.
+
+	return labels
+}
@@ -0,0 +1,176 @@
+package provider_test
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/provider"
+)
+
+// fakeKeyVault serves a subset of the Azure Key Vault secrets REST API.
+type fakeKeyVault struct {
+	mu       sync.Mutex
+	versions map[string][]fakeKeyVaultVersion
+	hosts    []string
+}
+
+type fakeKeyVaultVersion struct {
+	Value string            `json:"value"`
+	Tags  map[string]string `json:"tags"`
+}
+
+func (f *fakeKeyVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.hosts = append(f.hosts, r.Host)
+
+	if r.Header.Get("Authorization") != "Bearer token" || r.URL.Query().Get("api-version") == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"code":"Unauthorized","message":"bad token"}}`)
+
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/secrets"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 1:
+		var secrets []map[string]any
+		for _, name := range slices.Sorted(maps.Keys(f.versions)) {
+			secrets = append(secrets, map[string]any{"id": "https://" + r.Host + "/secrets/" + name, "attributes": map[string]bool{"enabled": true}})
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"value": secrets})
+	case r.Method == http.MethodGet:
+		versions := f.versions[parts[1]]
+
+		i := len(versions) - 1
+		if len(parts) > 2 && len(parts[2]) > 0 {
+			_, _ = fmt.Sscanf(parts[2], "v%d", &i)
+		}
+
+		if i < 0 || i >= len(versions) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"code":"SecretNotFound","message":"not found"}}`)
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(versions[i])
+	case r.Method == http.MethodPut:
+		var in fakeKeyVaultVersion
+		_ = json.NewDecoder(r.Body).Decode(&in)
+
+		f.versions[parts[1]] = append(f.versions[parts[1]], in)
+
+		fmt.Fprint(w, `{}`)
+	}
+}
+
+func TestAzure(t *testing.T) {
+	f := &fakeKeyVault{versions: map[string][]fakeKeyVaultVersion{
+		"db": {{Value: `{"password":"v0"}`}, {Value: `{"password":"v1"}`, Tags: map[string]string{"owner": "team"}}},
+	}}
+
+	srv := httptest.NewTLSServer(f)
+	t.Cleanup(srv.Close)
+
+	// key vaults are addressed by host name, route them all to the server.
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // test server.
+	}}
+
+	azure := provider.NewAzure(
+		provider.WithAzureVault("kv"),
+		provider.WithAzureDNSSuffix("vault.test"),
+		provider.WithAzureToken("token"),
+		provider.WithAzureHTTPClient(client),
+	)
+
+	r := provider.NewRegistry()
+	r.Register(provider.SchemeAzure, azure)
+
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{uri: "azure://db#password", want: "v1"},
+		{uri: "azure://other/db/v0#password", want: "v0"},
+		{uri: "azure://kv/db", want: `{"password":"v1"}`},
+	}
+
+	for _, tt := range tests {
+		got, err := r.Resolve(t.Context(), tt.uri)
+		if err != nil {
+			t.Errorf("Resolve(%s): %v", tt.uri, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("Resolve(%s) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+
+	if !slices.Contains(f.hosts, "other.vault.test") {
+		t.Errorf("got hosts %v, want other.vault.test", f.hosts)
+	}
+
+	var statusErr *provider.StatusError
+	if _, err := r.Resolve(t.Context(), "azure://missing"); !errors.As(err, &statusErr) || statusErr.Code != "SecretNotFound" {
+		t.Errorf("Resolve(azure://missing): got err %v, want SecretNotFound", err)
+	}
+
+	if err := azure.Put(t.Context(), provider.RemoteSecret{Name: "vlt_api"}, nil); err == nil {
+		t.Error("put invalid secret name: got nil err")
+	}
+
+	s := provider.RemoteSecret{Name: "vlt-api", Value: "key", Labels: []string{"prod/api"}}
+	if err := azure.Put(t.Context(), s, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := azure.Put(t.Context(), s, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(f.versions["vlt-api"]); n != 1 {
+		t.Errorf("unchanged secret was set as a new version, got %d versions", n)
+	}
+
+	relabeled := provider.RemoteSecret{Name: "db", Value: `{"password":"v1"}`, Labels: []string{"prod/db"}}
+	if err := azure.Put(t.Context(), relabeled, &provider.RemoteSecret{Name: "db", Value: `{"password":"v1"}`}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := f.versions["db"][2].Tags["owner"]; got != "team" {
+		t.Errorf("foreign tag was dropped, got %q", got)
+	}
+
+	secrets, err := azure.List(t.Context(), "vlt-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secrets) != 1 {
+		t.Fatalf("List(vlt-): got %d secrets, want 1", len(secrets))
+	}
+
+	if got := secrets[0]; got.Name != s.Name || got.Value != s.Value || !slices.Equal(got.Labels, s.Labels) {
+		t.Errorf("List(vlt-) = %+v, want %+v", got, s)
+	}
+}
@@ -0,0 +1,431 @@
+package provider
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	envGCPProject      = "GOOGLE_CLOUD_PROJECT"
+	envGCPSDKProject   = "CLOUDSDK_CORE_PROJECT"
+	envGCPAccessToken  = "GOOGLE_OAUTH_ACCESS_TOKEN" //nolint:gosec // environment variable name.
+	envGCPMetadataHost = "GCE_METADATA_HOST"
+
+	gcpDefaultEndpoint     = "https://secretmanager.googleapis.com"
+	gcpDefaultMetadataHost = "metadata.google.internal"
+
+	// gcpLabelsAnnotation is the secret annotation vault labels are stored in,
+	// GCP labels are too restricted to hold them.
+	gcpLabelsAnnotation = "vlt-labels"
+)
+
+// gcpSecretName matches valid Secret Manager secret ids.
+var gcpSecretName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,255}$`)
+
+// GCP stores and resolves secrets in Google Cloud Secret Manager, using its REST API.
+//
+// Link paths are full secret or version resource names, e.g.,
+// 'gcp://projects/my-project/secrets/db/versions/3#password', or secret ids
+// in the configured project, optionally followed by a version, e.g., 'gcp://db#password'.
+// The latest version is used if none is given. Without a field, the secret
+// payload is returned as is, otherwise it is parsed as a JSON object and the
+// field is selected.
+//
+// Pushed values are added as new secret versions. Vault labels are stored
+// as a JSON array in the 'vlt-labels' secret annotation.
+type GCP struct {
+	project   string
+	locations []string
+	endpoint  string
+	http      *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+type GCPOpt func(*GCP)
+
+// WithGCPProject overrides the project set by the GOOGLE_CLOUD_PROJECT environment variable.
+func WithGCPProject(project string) GCPOpt {
+	return func(g *GCP) {
+		if len(project) > 0 {
+			g.project = project
+		}
+	}
+}
+
+// WithGCPLocations sets the locations created secrets are replicated to,
+// instead of the automatic replication policy.
+func WithGCPLocations(locations ...string) GCPOpt {
+	return func(g *GCP) {
+		g.locations = locations
+	}
+}
+
+// WithGCPEndpoint overrides the Secret Manager endpoint.
+func WithGCPEndpoint(endpoint string) GCPOpt {
+	return func(g *GCP) {
+		if len(endpoint) > 0 {
+			g.endpoint = strings.TrimSuffix(endpoint, "/")
+		}
+	}
+}
+
+// WithGCPToken sets the access token used to authenticate,
+// instead of obtaining one from gcloud or the metadata server.
+func WithGCPToken(token string) GCPOpt {
+	return func(g *GCP) {
+		g.token = token
+	}
+}
+
+// WithGCPHTTPClient sets the http client used for requests.
+func WithGCPHTTPClient(c *http.Client) GCPOpt {
+	return func(g *GCP) {
+		g.http = c
+	}
+}
+
+// NewGCP returns a new [GCP] provider configured by the
+// standard Google Cloud environment variables and the given options.
+func NewGCP(opts ...GCPOpt) *GCP {
+	g := &GCP{
+		project:  cmp.Or(os.Getenv(envGCPProject), os.Getenv(envGCPSDKProject)),
+		endpoint: gcpDefaultEndpoint,
+		http:     &http.Client{Timeout: defaultTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// Project returns the project secrets are stored in.
+func (g *GCP) Project() string { return g.project }
+
+// Resolve returns the value of the secret referenced by ref.
+func (g *GCP) Resolve(ctx context.Context, ref Ref) (string, error) {
+	name := ref.Path
+	if !strings.HasPrefix(name, "projects/") {
+		parent, err := g.secretPath("")
+		if err != nil {
+			return "", err
+		}
+
+		name = parent + name
+	}
+
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+
+	value, err := g.access(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	if len(ref.Field) == 0 {
+		return value, nil
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return "", fmt.Errorf("gcp: %s: secret is not a JSON object, it has no fields", ref.Path)
+	}
+
+	v, err := selectField(values, ref.Field)
+	if err != nil {
+		return "", fmt.Errorf("gcp: %s: %w", ref.Path, err)
+	}
+
+	return v, nil
+}
+
+type gcpSecret struct {
+	Name        string            `json:"name,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Replication *gcpReplication   `json:"replication,omitempty"`
+}
+
+type gcpReplication struct {
+	Automatic   *struct{} `json:"automatic,omitempty"`
+	UserManaged *struct {
+		Replicas []gcpReplica `json:"replicas"`
+	} `json:"userManaged,omitempty"`
+}
+
+type gcpReplica struct {
+	Location string `json:"location"`
+}
+
+// List returns the secrets whose ids start with prefix, along with their latest values.
+func (g *GCP) List(ctx context.Context, prefix string) ([]RemoteSecret, error) {
+	parent, err := g.secretPath("")
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		secrets   []RemoteSecret
+		pageToken string
+	)
+
+	for {
+		q := url.Values{"pageSize": {"250"}}
+		if len(pageToken) > 0 {
+			q.Set("pageToken", pageToken)
+		}
+
+		var out struct {
+			Secrets       []gcpSecret `json:"secrets"`
+			NextPageToken string      `json:"nextPageToken"`
+		}
+
+		if err := g.call(ctx, http.MethodGet, strings.TrimSuffix(parent, "/")+"?"+q.Encode(), nil, &out); err != nil {
+			return nil, err
+		}
+
+		for _, s := range out.Secrets {
+			id := s.Name[strings.LastIndex(s.Name, "/")+1:]
+			if !strings.HasPrefix(id, prefix) {
+				continue
+			}
+
+			value, err := g.access(ctx, s.Name+"/versions/latest")
+			if err != nil {
+				var statusErr *StatusError
+				if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+					continue // no enabled versions.
+				}
+
+				return nil, err
+			}
+
+			secrets = append(secrets, RemoteSecret{Name: id, Value: value, Labels: gcpLabels(s.Annotations)})
+		}
+
+		if pageToken = out.NextPageToken; len(pageToken) == 0 {
+			return secrets, nil
+		}
+	}
+}
+
+// Put creates the given secret if needed, and adds its value as a new version, see [Store].
+func (g *GCP) Put(ctx context.Context, s RemoteSecret, prev *RemoteSecret) error {
+	if !gcpSecretName.MatchString(s.Name) {
+		return fmt.Errorf("gcp: invalid secret id %q (letters, digits, '-' and '_' only)", s.Name)
+	}
+
+	name, err := g.secretPath(s.Name)
+	if err != nil {
+		return err
+	}
+
+	labels, err := json.Marshal(slices.Sorted(slices.Values(s.Labels)))
+	if err != nil {
+		return fmt.Errorf("gcp: %w", err)
+	}
+
+	switch {
+	case prev == nil:
+		secret := gcpSecret{
+			Annotations: map[string]string{gcpLabelsAnnotation: string(labels)},
+			Replication: g.replication(),
+		}
+
+		parent, _, _ := strings.Cut(name, "/secrets/")
+
+		if err := g.call(ctx, http.MethodPost, parent+"/secrets?secretId="+url.QueryEscape(s.Name), secret, nil); err != nil {
+			return err
+		}
+	case !slices.Equal(slices.Sorted(slices.Values(s.Labels)), slices.Sorted(slices.Values(prev.Labels))):
+		var secret gcpSecret
+		if err := g.call(ctx, http.MethodGet, name, nil, &secret); err != nil {
+			return err
+		}
+
+		// annotations are replaced as a whole, keep those not set by vlt.
+		annotations := secret.Annotations
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+
+		annotations[gcpLabelsAnnotation] = string(labels)
+
+		err := g.call(ctx, http.MethodPatch, name+"?updateMask=annotations", gcpSecret{Annotations: annotations}, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if prev != nil && s.Value == prev.Value {
+		return nil
+	}
+
+	payload := map[string]any{"payload": map[string]string{"data": base64.StdEncoding.EncodeToString([]byte(s.Value))}}
+
+	return g.call(ctx, http.MethodPost, name+":addVersion", payload, nil)
+}
+
+func (g *GCP) replication() *gcpReplication {
+	if len(g.locations) == 0 {
+		return &gcpReplication{Automatic: &struct{}{}}
+	}
+
+	r := &gcpReplication{UserManaged: &struct {
+		Replicas []gcpReplica `json:"replicas"`
+	}{}}
+
+	for _, l := range g.locations {
+		r.UserManaged.Replicas = append(r.UserManaged.Replicas, gcpReplica{Location: l})
+	}
+
+	return r
+}
+
+// secretPath returns the resource name of the given secret id in the configured project.
+func (g *GCP) secretPath(id string) (string, error) {
+	if len(g.project) == 0 {
+		return "", fmt.Errorf("gcp: no project, set %s", envGCPProject)
+	}
+
+	return "projects/" + g.project + "/secrets/" + id, nil
+}
+
+// access returns the payload of the given secret version.
+func (g *GCP) access(ctx context.Context, version string) (string, error) {
+	var out struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+
+	if err := g.call(ctx, http.MethodGet, version+":access", nil, &out); err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(out.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("gcp: %s: decode payload: %w", version, err)
+	}
+
+	return string(data), nil
+}
+
+// call sends a request to the given resource of the Secret Manager API.
+func (g *GCP) call(ctx context.Context, method string, resource string, in any, out any) error {
+	token, err := g.lookupToken(ctx)
+	if err != nil {
+		return fmt.Errorf("gcp: %w", err)
+	}
+
+	var body bytes.Buffer
+
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return fmt.Errorf("gcp: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.endpoint+"/v1/"+resource, &body)
+	if err != nil {
+		return fmt.Errorf("gcp: %w", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("gcp: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:wsl
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+				Status  string `json:"status"`
+			} `json:"error"`
+		}
+
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+
+		return &StatusError{Provider: "gcp", StatusCode: resp.StatusCode, Code: e.Error.Status, Message: e.Error.Message}
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("gcp: decode response: %w", err)
+	}
+
+	return nil
+}
+
+// lookupToken returns the configured access token, falling back to the
+// GOOGLE_OAUTH_ACCESS_TOKEN environment variable, the gcloud cli, and the
+// metadata server of Google Cloud instances. The token is reused afterwards.
+func (g *GCP) lookupToken(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.token) > 0 {
+		return g.token, nil
+	}
+
+	if token := os.Getenv(envGCPAccessToken); len(token) > 0 {
+		g.token = token
+		return token, nil
+	}
+
+	token, gcloudErr := commandToken(ctx, "gcloud", "auth", "print-access-token")
+	if gcloudErr != nil {
+		var metadataErr error
+
+		if token, metadataErr = g.metadataToken(ctx); metadataErr != nil {
+			return "", fmt.Errorf("no access token, run 'gcloud auth login' or set %s (gcloud: %v, metadata server: %v)", envGCPAccessToken, gcloudErr, metadataErr)
+		}
+	}
+
+	g.token = token
+
+	return token, nil
+}
+
+// metadataToken returns an access token of the default service account
+// of the Google Cloud instance vlt runs on.
+func (g *GCP) metadataToken(ctx context.Context) (string, error) {
+	host := cmp.Or(os.Getenv(envGCPMetadataHost), gcpDefaultMetadataHost)
+
+	return metadataToken(ctx, g.http, "http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", http.Header{"Metadata-Flavor": {"Google"}})
+}
+
+// gcpLabels returns the vault labels stored in the given secret annotations.
+func gcpLabels(annotations map[string]string) []string {
+	var labels []string
+
+	_ = json.Unmarshal([]byte(annotations[gcpLabelsAnnotation]), &labels) // not set by vlt.
+
+	return labels
+}
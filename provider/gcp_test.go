@@ -0,0 +1,203 @@
+package provider_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/provider"
+)
+
+// fakeSecretManager serves a subset of the Google Cloud Secret Manager REST API
+// for the 'p' project.
+type fakeSecretManager struct {
+	mu          sync.Mutex
+	versions    map[string][]string // versions maps secret ids to their version payloads.
+	annotations map[string]map[string]string
+	replication map[string]json.RawMessage
+}
+
+func (f *fakeSecretManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"code":401,"message":"bad token","status":"UNAUTHENTICATED"}}`)
+
+		return
+	}
+
+	var in struct {
+		Annotations map[string]string `json:"annotations"`
+		Replication json.RawMessage   `json:"replication"`
+		Payload     struct {
+			Data []byte `json:"data"` // base64 decoded by encoding/json.
+		} `json:"payload"`
+	}
+
+	_ = json.NewDecoder(r.Body).Decode(&in)
+
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":404,"message":"not found","status":"NOT_FOUND"}}`)
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/projects/p/secrets")
+	id, rest, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+
+	if (len(id) > 0 && r.Method != http.MethodPost) || strings.HasSuffix(id, ":addVersion") {
+		if _, ok := f.versions[strings.TrimSuffix(id, ":addVersion")]; !ok {
+			notFound()
+			return
+		}
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(id) == 0:
+		var secrets []map[string]any
+		for _, id := range slices.Sorted(maps.Keys(f.versions)) {
+			secrets = append(secrets, map[string]any{"name": "projects/p/secrets/" + id, "annotations": f.annotations[id]})
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"secrets": secrets})
+	case r.Method == http.MethodGet && strings.HasSuffix(rest, ":access"):
+		versions := f.versions[id]
+		version := strings.TrimSuffix(strings.TrimPrefix(rest, "versions/"), ":access")
+
+		i := len(versions)
+		if version != "latest" {
+			i, _ = strconv.Atoi(version)
+		}
+
+		if i < 1 || i > len(versions) {
+			notFound()
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"payload": map[string][]byte{"data": []byte(versions[i-1])}})
+	case r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "projects/p/secrets/" + id, "annotations": f.annotations[id]})
+	case r.Method == http.MethodPost && len(id) == 0:
+		id := r.URL.Query().Get("secretId")
+		f.versions[id] = []string{}
+		f.annotations[id] = in.Annotations
+		f.replication[id] = in.Replication
+
+		fmt.Fprint(w, `{}`)
+	case r.Method == http.MethodPost:
+		id := strings.TrimSuffix(id, ":addVersion")
+		f.versions[id] = append(f.versions[id], string(in.Payload.Data))
+
+		fmt.Fprint(w, `{}`)
+	case r.Method == http.MethodPatch:
+		f.annotations[id] = in.Annotations
+		fmt.Fprint(w, `{}`)
+	}
+}
+
+func TestGCP(t *testing.T) {
+	f := &fakeSecretManager{
+		versions: map[string][]string{
+			"db": {`{"password":"v1"}`, `{"password":"v2"}`},
+		},
+		annotations: map[string]map[string]string{"db": {"owner": "team"}},
+		replication: map[string]json.RawMessage{},
+	}
+
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	gcp := provider.NewGCP(
+		provider.WithGCPProject("p"),
+		provider.WithGCPLocations("us-east1"),
+		provider.WithGCPEndpoint(srv.URL),
+		provider.WithGCPToken("token"),
+		provider.WithGCPHTTPClient(srv.Client()),
+	)
+
+	r := provider.NewRegistry()
+	r.Register(provider.SchemeGCP, gcp)
+
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{uri: "gcp://db#password", want: "v2"},
+		{uri: "gcp://db/versions/1#password", want: "v1"},
+		{uri: "gcp://projects/p/secrets/db", want: `{"password":"v2"}`},
+	}
+
+	for _, tt := range tests {
+		got, err := r.Resolve(t.Context(), tt.uri)
+		if err != nil {
+			t.Errorf("Resolve(%s): %v", tt.uri, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("Resolve(%s) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+
+	var statusErr *provider.StatusError
+	if _, err := r.Resolve(t.Context(), "gcp://missing"); !errors.As(err, &statusErr) || statusErr.Code != "NOT_FOUND" {
+		t.Errorf("Resolve(gcp://missing): got err %v, want NOT_FOUND", err)
+	}
+
+	if err := gcp.Put(t.Context(), provider.RemoteSecret{Name: "prod/api"}, nil); err == nil {
+		t.Error("put invalid secret id: got nil err")
+	}
+
+	s := provider.RemoteSecret{Name: "vlt-api", Value: "key", Labels: []string{"prod/api"}}
+	if err := gcp.Put(t.Context(), s, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := string(f.replication["vlt-api"]), `{"userManaged":{"replicas":[{"location":"us-east1"}]}}`; got != want {
+		t.Errorf("replication = %s, want %s", got, want)
+	}
+
+	rotated := provider.RemoteSecret{Name: "vlt-api", Value: "rotated", Labels: []string{"prod/api"}}
+	if err := gcp.Put(t.Context(), rotated, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := f.versions["vlt-api"]; !slices.Equal(got, []string{"key", "rotated"}) {
+		t.Errorf("versions = %q, want [key rotated]", got)
+	}
+
+	relabeled := provider.RemoteSecret{Name: "db", Value: `{"password":"v2"}`, Labels: []string{"prod/db"}}
+	if err := gcp.Put(t.Context(), relabeled, &provider.RemoteSecret{Name: "db", Value: `{"password":"v2"}`}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := f.annotations["db"]["owner"]; got != "team" {
+		t.Errorf("foreign annotation was dropped, got %q", got)
+	}
+
+	if n := len(f.versions["db"]); n != 2 {
+		t.Errorf("unchanged value was added as a new version, got %d versions", n)
+	}
+
+	secrets, err := gcp.List(t.Context(), "vlt-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secrets) != 1 {
+		t.Fatalf("List(vlt-): got %d secrets, want 1", len(secrets))
+	}
+
+	if got := secrets[0]; got.Name != rotated.Name || got.Value != rotated.Value || !slices.Equal(got.Labels, rotated.Labels) {
+		t.Errorf("List(vlt-) = %+v, want %+v", got, rotated)
+	}
+}
@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	envVaultAddr      = "VAULT_ADDR"
+	envVaultToken     = "VAULT_TOKEN"
+	envVaultNamespace = "VAULT_NAMESPACE"
+
+	// vaultTokenFile is the file under the user's home directory
+	// the vault cli stores the token of the last login in.
+	vaultTokenFile = ".vault-token"
+)
+
+// HashiCorp resolves secrets stored in HashiCorp Vault, using its HTTP API.
+//
+// Link paths are API paths without the '/v1/' prefix, e.g.,
+// 'hv://secret/data/db#password' for the 'password' field of the
+// 'db' secret of a KV version 2 engine mounted at 'secret'.
+// Both KV engine versions are supported.
+type HashiCorp struct {
+	address   string
+	namespace string
+	token     string
+	http      *http.Client
+}
+
+type HashiCorpOpt func(*HashiCorp)
+
+// WithHashiCorpAddress overrides the address set by the VAULT_ADDR environment variable.
+func WithHashiCorpAddress(addr string) HashiCorpOpt {
+	return func(h *HashiCorp) {
+		if len(addr) > 0 {
+			h.address = strings.TrimSuffix(addr, "/")
+		}
+	}
+}
+
+// WithHashiCorpNamespace overrides the namespace set by the VAULT_NAMESPACE environment variable.
+func WithHashiCorpNamespace(ns string) HashiCorpOpt {
+	return func(h *HashiCorp) {
+		if len(ns) > 0 {
+			h.namespace = ns
+		}
+	}
+}
+
+// WithHashiCorpToken sets the token used to authenticate,
+// instead of looking it up on each request.
+func WithHashiCorpToken(token string) HashiCorpOpt {
+	return func(h *HashiCorp) {
+		h.token = token
+	}
+}
+
+// WithHashiCorpHTTPClient sets the http client used for requests.
+func WithHashiCorpHTTPClient(c *http.Client) HashiCorpOpt {
+	return func(h *HashiCorp) {
+		h.http = c
+	}
+}
+
+// NewHashiCorp returns a new [HashiCorp] provider configured
+// by the standard vault environment variables and the given options.
+func NewHashiCorp(opts ...HashiCorpOpt) *HashiCorp {
+	h := &HashiCorp{
+		address:   strings.TrimSuffix(os.Getenv(envVaultAddr), "/"),
+		namespace: os.Getenv(envVaultNamespace),
+		http:      &http.Client{Timeout: defaultTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Resolve returns the value of the secret field referenced by ref.
+func (h *HashiCorp) Resolve(ctx context.Context, ref Ref) (string, error) {
+	if len(h.address) == 0 {
+		return "", fmt.Errorf("hashicorp: no vault address, set %s", envVaultAddr)
+	}
+
+	token, err := h.lookupToken()
+	if err != nil {
+		return "", fmt.Errorf("hashicorp: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.address+"/v1/"+ref.Path, http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("hashicorp: %w", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Vault-Token", token)
+
+	if len(h.namespace) > 0 {
+		req.Header.Set("X-Vault-Namespace", h.namespace)
+	}
+
+	resp, err := h.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("hashicorp: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:wsl
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Errors []string `json:"errors"`
+		}
+
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+
+		return "", &StatusError{Provider: "hashicorp", StatusCode: resp.StatusCode, Message: strings.Join(body.Errors, "; ")}
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("hashicorp: decode response: %w", err)
+	}
+
+	values := body.Data
+
+	// KV version 2 nests the secret data alongside its metadata.
+	if data, ok := values["data"].(map[string]any); ok {
+		if _, ok := values["metadata"]; ok {
+			values = data
+		}
+	}
+
+	v, err := selectField(values, ref.Field)
+	if err != nil {
+		return "", fmt.Errorf("hashicorp: %s: %w", ref.Path, err)
+	}
+
+	return v, nil
+}
+
+// lookupToken returns the configured token, falling back to the VAULT_TOKEN
+// environment variable and the token stored by 'vault login'.
+func (h *HashiCorp) lookupToken() (string, error) {
+	if len(h.token) > 0 {
+		return h.token, nil
+	}
+
+	if token := os.Getenv(envVaultToken); len(token) > 0 {
+		return token, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	token, err := os.ReadFile(filepath.Join(home, vaultTokenFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("no vault token, set %s or run 'vault login'", envVaultToken)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(token)), nil
+}
@@ -0,0 +1,84 @@
+package provider_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/provider"
+)
+
+func TestHashiCorp_Resolve(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors":["permission denied"]}`)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/db":
+			fmt.Fprint(w, `{"data":{"data":{"user":"admin","password":"s3cret","port":5432},"metadata":{"version":3}}}`)
+		case "/v1/kv/api":
+			fmt.Fprint(w, `{"data":{"key":"abc"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[]}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	r := provider.NewRegistry()
+	r.Register(provider.SchemeHashiCorp, provider.NewHashiCorp(
+		provider.WithHashiCorpAddress(srv.URL),
+		provider.WithHashiCorpToken("token"),
+		provider.WithHashiCorpHTTPClient(srv.Client()),
+	))
+
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{uri: "hv://secret/data/db#password", want: "s3cret"},
+		{uri: "hv://secret/data/db#port", want: "5432"},
+		{uri: "hv://kv/api", want: "abc"},
+	}
+
+	for _, tt := range tests {
+		got, err := r.Resolve(t.Context(), tt.uri)
+		if err != nil {
+			t.Errorf("Resolve(%s): %v", tt.uri, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("Resolve(%s) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+
+	if _, err := r.Resolve(t.Context(), "hv://secret/data/db"); !errors.Is(err, provider.ErrNoField) {
+		t.Errorf("Resolve without a field: got err %v, want %v", err, provider.ErrNoField)
+	}
+
+	for _, uri := range []string{"hv://secret/data/db#missing", "hv://secret/data/missing#password", "aws://db#password", "secret/data/db"} {
+		if _, err := r.Resolve(t.Context(), uri); err == nil {
+			t.Errorf("Resolve(%s): got nil err", uri)
+		}
+	}
+
+	unauthorized := provider.NewHashiCorp(
+		provider.WithHashiCorpAddress(srv.URL),
+		provider.WithHashiCorpToken("wrong"),
+		provider.WithHashiCorpHTTPClient(srv.Client()),
+	)
+
+	var statusErr *provider.StatusError
+
+	_, err := unauthorized.Resolve(t.Context(), provider.Ref{Scheme: provider.SchemeHashiCorp, Path: "secret/data/db"})
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusForbidden || statusErr.Message != "permission denied" {
+		t.Errorf("Resolve using a wrong token: got err %v, want a %d status error", err, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,263 @@
+// Package provider resolves secrets stored in external secret managers.
+//
+// Secrets are referenced by link URIs of the form '<scheme>://<path>#<field>',
+// where the scheme selects the provider, the path locates the secret within it,
+// and the optional field selects a single value of a secret holding several.
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// SchemeHashiCorp is the link scheme of HashiCorp Vault secrets.
+	SchemeHashiCorp = "hv"
+
+	// SchemeAWS is the link scheme of AWS Secrets Manager secrets.
+	SchemeAWS = "aws"
+
+	// SchemeGCP is the link scheme of Google Cloud Secret Manager secrets.
+	SchemeGCP = "gcp"
+
+	// SchemeAzure is the link scheme of Azure Key Vault secrets.
+	SchemeAzure = "azure"
+
+	// defaultTimeout bounds a single provider request.
+	defaultTimeout = 30 * time.Second
+
+	// metadataTimeout bounds instance metadata token requests,
+	// which hang outside of the cloud.
+	metadataTimeout = 3 * time.Second
+
+	userAgent = "vlt-cli"
+)
+
+var ErrNoField = errors.New("secret has several fields, select one using '#<field>'")
+
+// StatusError is returned when a provider responds with an unexpected status.
+type StatusError struct {
+	Provider   string
+	StatusCode int
+	Code       string // Code is the error code reported by the provider, if any.
+	Message    string // Message is the error reported by the provider, if any.
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%s: unexpected status %d", e.Provider, e.StatusCode)
+	if len(e.Code) > 0 {
+		msg += ": " + e.Code
+	}
+
+	if len(e.Message) > 0 {
+		msg += ": " + e.Message
+	}
+
+	return msg
+}
+
+// Ref is a parsed link URI.
+type Ref struct {
+	Scheme string
+	Path   string // Path locates the secret within the provider.
+	Field  string // Field selects a single value of the secret, optional.
+}
+
+// ParseRef parses the given link URI.
+func ParseRef(uri string) (Ref, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return Ref{}, fmt.Errorf("invalid link %q: %w", uri, err)
+	}
+
+	ref := Ref{
+		Scheme: u.Scheme,
+		Path:   strings.Trim(u.Host+u.Path, "/"),
+		Field:  u.Fragment,
+	}
+
+	if len(ref.Scheme) == 0 || len(ref.Path) == 0 {
+		return Ref{}, fmt.Errorf("invalid link %q: expected '<scheme>://<path>[#<field>]'", uri)
+	}
+
+	return ref, nil
+}
+
+func (r Ref) String() string {
+	s := r.Scheme + "://" + r.Path
+	if len(r.Field) > 0 {
+		s += "#" + r.Field
+	}
+
+	return s
+}
+
+// Provider resolves secrets stored in an external secret manager.
+type Provider interface {
+	Resolve(ctx context.Context, ref Ref) (string, error)
+}
+
+// RemoteSecret is a vault secret stored in a cloud secret manager.
+type RemoteSecret struct {
+	Name   string // Name is the name of the secret in the secret manager.
+	Value  string
+	Labels []string // Labels are the vault secret labels, stored as tags of the remote secret.
+}
+
+// Store is a cloud secret manager vault secrets are pushed to and pulled from.
+type Store interface {
+	// List returns the secrets whose names start with prefix.
+	List(ctx context.Context, prefix string) ([]RemoteSecret, error)
+
+	// Put stores s, which was prev when last listed, or creates it if prev is nil.
+	// Only the changed value or labels are updated.
+	Put(ctx context.Context, s RemoteSecret, prev *RemoteSecret) error
+}
+
+// Registry resolves link URIs using the provider registered for their scheme.
+type Registry struct {
+	providers map[string]Provider
+
+	// fallback looks up the provider of schemes with no registered provider, if set.
+	fallback func(scheme string) (Provider, bool)
+}
+
+// NewRegistry returns an empty [Registry].
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]Provider)}
+}
+
+// Register registers p as the provider of the given scheme.
+func (r *Registry) Register(scheme string, p Provider) {
+	r.providers[scheme] = p
+}
+
+// RegisterFallback registers f to look up the provider of
+// schemes with no registered provider, e.g., plugins.
+func (r *Registry) RegisterFallback(f func(scheme string) (Provider, bool)) {
+	r.fallback = f
+}
+
+// Schemes returns the registered schemes, sorted.
+func (r *Registry) Schemes() []string {
+	return slices.Sorted(maps.Keys(r.providers))
+}
+
+// Provider returns the provider of the given scheme, if registered.
+//
+//nolint:ireturn
+func (r *Registry) Provider(scheme string) (Provider, bool) {
+	if p, ok := r.providers[scheme]; ok {
+		return p, true
+	}
+
+	if r.fallback != nil {
+		return r.fallback(scheme)
+	}
+
+	return nil, false
+}
+
+// Resolve returns the current value of the secret referenced by the given link URI.
+func (r *Registry) Resolve(ctx context.Context, uri string) (string, error) {
+	ref, err := ParseRef(uri)
+	if err != nil {
+		return "", err
+	}
+
+	p, ok := r.Provider(ref.Scheme)
+	if !ok {
+		return "", fmt.Errorf("unsupported link scheme %q (supported: %v)", ref.Scheme, r.Schemes())
+	}
+
+	return p.Resolve(ctx, ref)
+}
+
+// selectField returns the given field of a secret holding several values,
+// or its only value if no field is given.
+// Non-string values are returned in their JSON encoding.
+func selectField(values map[string]any, field string) (string, error) {
+	if len(field) == 0 {
+		if len(values) != 1 {
+			return "", fmt.Errorf("%w (fields: %v)", ErrNoField, slices.Sorted(maps.Keys(values)))
+		}
+
+		for k := range values {
+			field = k
+		}
+	}
+
+	v, ok := values[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found (fields: %v)", field, slices.Sorted(maps.Keys(values)))
+	}
+
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("field %q: %w", field, err)
+	}
+
+	return string(encoded), nil
+}
+
+// commandToken returns the access token printed by the given cli command.
+func commandToken(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+		}
+
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// metadataToken returns the access token served by the given
+// instance metadata endpoint of a cloud provider.
+func metadataToken(ctx context.Context, c *http.Client, endpoint string, header http.Header) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+
+	maps.Copy(req.Header, header)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:wsl
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var out struct {
+		AccessToken string `json:"access_token"` //nolint:tagliatelle
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+
+	return out.AccessToken, nil
+}
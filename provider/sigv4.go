@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat = "20060102T150405Z"
+	sigV4DateFormat = "20060102"
+)
+
+// awsCredentials are the credentials requests to AWS are signed with.
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// signV4 signs the given request in place using AWS Signature Version 4,
+// see https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html.
+func signV4(req *http.Request, creds awsCredentials, region, service string, now time.Time) error {
+	var body []byte
+
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return fmt.Errorf("sign request: %w", err)
+		}
+
+		body = b
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	amzDate := now.UTC().Format(sigV4TimeFormat)
+	scope := strings.Join([]string{now.UTC().Format(sigV4DateFormat), region, service, "aws4_request"}, "/")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	if len(creds.SessionToken) > 0 {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers, signedHeaders := canonicalHeaders(req)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		canonicalQuery(req.URL),
+		headers,
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n")
+
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, hexSHA256([]byte(canonicalRequest))}, "\n")
+
+	key := []byte("AWS4" + creds.SecretAccessKey)
+	for _, part := range strings.Split(scope, "/") {
+		key = hmacSHA256(key, part)
+	}
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, creds.AccessKeyID, scope, signedHeaders, signature))
+
+	return nil
+}
+
+// canonicalHeaders returns the canonical headers of the request,
+// and the list of signed header names.
+func canonicalHeaders(req *http.Request) (headers string, signed string) {
+	values := map[string]string{"host": req.Host}
+	if len(req.Host) == 0 {
+		values["host"] = req.URL.Host
+	}
+
+	for k, v := range req.Header {
+		k = strings.ToLower(k)
+		if k == "user-agent" || k == "authorization" {
+			continue
+		}
+
+		values[k] = strings.Join(v, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+
+	slices.Sort(names)
+
+	var sb strings.Builder
+	for _, k := range names {
+		sb.WriteString(k + ":" + strings.Join(strings.Fields(values[k]), " ") + "\n")
+	}
+
+	return sb.String(), strings.Join(names, ";")
+}
+
+func canonicalPath(u *url.URL) string {
+	p := u.EscapedPath()
+	if len(p) == 0 {
+		return "/"
+	}
+
+	return p
+}
+
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	var pairs []string
+
+	for _, k := range keys {
+		vs := slices.Sorted(slices.Values(query[k]))
+		for _, v := range vs {
+			pairs = append(pairs, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// awsEscape percent-encodes s as required by SigV4, escaping all but unreserved characters.
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hexSHA256(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,543 @@
+// Package qrcode encodes data as QR codes (ISO/IEC 18004), and renders them
+// to terminals, e.g., to transfer TOTP seeds to authenticator apps.
+//
+// Only the byte mode and the medium (M) error correction level are supported,
+// up to version 20, holding up to 666 bytes, which fits any otpauth:// uri.
+package qrcode
+
+import (
+	"errors"
+	"strings"
+)
+
+// maxVersion is the largest supported version.
+const maxVersion = 20
+
+// ErrTooLong indicates that the data does not fit the largest supported version.
+var ErrTooLong = errors.New("qrcode: data too long")
+
+// blocks describes the error correction blocks of a version, at level M.
+type blocks struct {
+	ecc   int // ecc is the number of error correction codewords per block.
+	short int // short is the number of blocks in the first group.
+	data  int // data is the number of data codewords of the first group blocks.
+	long  int // long is the number of blocks in the second group, of data+1 codewords.
+}
+
+// versions holds the error correction blocks of versions 1 through 20, at level M.
+var versions = [maxVersion + 1]blocks{
+	1:  {ecc: 10, short: 1, data: 16},
+	2:  {ecc: 16, short: 1, data: 28},
+	3:  {ecc: 26, short: 1, data: 44},
+	4:  {ecc: 18, short: 2, data: 32},
+	5:  {ecc: 24, short: 2, data: 43},
+	6:  {ecc: 16, short: 4, data: 27},
+	7:  {ecc: 18, short: 4, data: 31},
+	8:  {ecc: 22, short: 2, data: 38, long: 2},
+	9:  {ecc: 22, short: 3, data: 36, long: 2},
+	10: {ecc: 26, short: 4, data: 43, long: 1},
+	11: {ecc: 30, short: 1, data: 50, long: 4},
+	12: {ecc: 22, short: 6, data: 36, long: 2},
+	13: {ecc: 22, short: 8, data: 37, long: 1},
+	14: {ecc: 24, short: 4, data: 40, long: 5},
+	15: {ecc: 24, short: 5, data: 41, long: 5},
+	16: {ecc: 28, short: 7, data: 45, long: 3},
+	17: {ecc: 28, short: 10, data: 46, long: 1},
+	18: {ecc: 26, short: 9, data: 43, long: 4},
+	19: {ecc: 26, short: 3, data: 44, long: 11},
+	20: {ecc: 26, short: 3, data: 41, long: 13},
+}
+
+func (b blocks) dataCodewords() int { return b.short*b.data + b.long*(b.data+1) }
+
+func (b blocks) totalCodewords() int { return b.dataCodewords() + (b.short+b.long)*b.ecc }
+
+// Code is a QR code, a square of dark and light modules.
+type Code struct {
+	Size int // Size is the number of modules per side.
+
+	modules  []bool // modules are the dark modules, in row-major order.
+	function []bool // function marks the modules of function patterns.
+}
+
+// Dark reports whether the module at column x and row y is dark.
+func (c *Code) Dark(x, y int) bool { return c.modules[y*c.Size+x] }
+
+// Encode encodes data in byte mode, using the smallest version it fits.
+func Encode(data []byte) (*Code, error) {
+	version := 0
+
+	for v := 1; v <= maxVersion; v++ {
+		if 4+countBits(v)+8*len(data) <= 8*versions[v].dataCodewords() {
+			version = v
+			break
+		}
+	}
+
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := interleave(versions[version], encodeData(version, data))
+
+	c := newCode(version)
+	c.drawFunctionPatterns(version)
+	c.drawCodewords(codewords)
+
+	best, bestPenalty := 0, -1
+
+	for mask := range 8 {
+		c.applyMask(mask)
+		c.drawFormat(mask)
+
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+
+		c.applyMask(mask) // masks are undone by applying them again.
+	}
+
+	c.applyMask(best)
+	c.drawFormat(best)
+
+	return c, nil
+}
+
+// countBits returns the size of the character count indicator of a version, in byte mode.
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+
+	return 16
+}
+
+// encodeData returns the data codewords of data, padded to the capacity of version.
+func encodeData(version int, data []byte) []byte {
+	var bb bitBuffer
+
+	bb.append(0b0100, 4) // byte mode
+	bb.append(len(data), countBits(version))
+
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+
+	capacity := 8 * versions[version].dataCodewords()
+
+	bb.append(0, min(4, capacity-bb.len())) // terminator
+	bb.append(0, (8-bb.len()%8)%8)
+
+	for pad := 0xec; bb.len() < capacity; pad ^= 0xec ^ 0x11 {
+		bb.append(pad, 8)
+	}
+
+	return bb.bytes
+}
+
+// interleave splits the data codewords into blocks, computes their error
+// correction codewords, and returns the codewords in placement order.
+func interleave(b blocks, data []byte) []byte {
+	var dataBlocks, eccBlocks [][]byte
+
+	divisor := rsDivisor(b.ecc)
+
+	for i := range b.short + b.long {
+		n := b.data
+		if i >= b.short {
+			n++
+		}
+
+		block := data[:n]
+		data = data[n:]
+
+		dataBlocks = append(dataBlocks, block)
+		eccBlocks = append(eccBlocks, rsRemainder(block, divisor))
+	}
+
+	result := make([]byte, 0, b.totalCodewords())
+
+	for i := range b.data + 1 {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+
+	for i := range b.ecc {
+		for _, block := range eccBlocks {
+			result = append(result, block[i])
+		}
+	}
+
+	return result
+}
+
+func newCode(version int) *Code {
+	size := 4*version + 17
+
+	return &Code{
+		Size:     size,
+		modules:  make([]bool, size*size),
+		function: make([]bool, size*size),
+	}
+}
+
+// setFunction sets the module at column x and row y as part of a function pattern.
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y*c.Size+x] = dark
+	c.function[y*c.Size+x] = true
+}
+
+func (c *Code) drawFunctionPatterns(version int) {
+	for i := range c.Size {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	pos := alignmentPositions(version)
+	last := len(pos) - 1
+
+	for i, x := range pos {
+		for j, y := range pos {
+			// skip the ones overlapping the finder patterns.
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+
+			c.drawAlignment(x, y)
+		}
+	}
+
+	// reserve the format areas, drawn for each mask.
+	c.drawFormat(0)
+	c.drawVersion(version)
+}
+
+// drawFinder draws a finder pattern, along with its separator, centered at x, y.
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= c.Size || yy < 0 || yy >= c.Size {
+				continue
+			}
+
+			d := max(abs(dx), abs(dy))
+			c.setFunction(xx, yy, d != 2 && d != 4)
+		}
+	}
+}
+
+// drawAlignment draws an alignment pattern centered at x, y.
+func (c *Code) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// alignmentPositions returns the coordinates of the alignment pattern centers, in both axes.
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+
+	n := version/7 + 2
+	step := (version*4 + n*2 + 1) / (n*2 - 2) * 2
+
+	pos := make([]int, n)
+	pos[0] = 6
+
+	for i, p := n-1, 4*version+10; i > 0; i, p = i-1, p-step {
+		pos[i] = p
+	}
+
+	return pos
+}
+
+// drawFormat draws both copies of the format information of mask, along with the dark module.
+func (c *Code) drawFormat(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := range 6 {
+		c.setFunction(8, i, bit(i))
+	}
+
+	c.setFunction(8, 7, bit(6))
+	c.setFunction(8, 8, bit(7))
+	c.setFunction(7, 8, bit(8))
+
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := range 8 {
+		c.setFunction(c.Size-1-i, 8, bit(i))
+	}
+
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(i))
+	}
+
+	c.setFunction(8, c.Size-8, true)
+}
+
+// formatBits returns the 15 format information bits of mask, at level M.
+func formatBits(mask int) int {
+	data := 0b00<<3 | mask // level M is indicated by 00.
+
+	rem := data
+	for range 10 {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// drawVersion draws both copies of the version information, of versions 7 and up.
+func (c *Code) drawVersion(version int) {
+	if version < 7 {
+		return
+	}
+
+	bits := versionBits(version)
+
+	for i := range 18 {
+		dark := bits>>i&1 == 1
+		a, b := c.Size-11+i%3, i/3
+
+		c.setFunction(a, b, dark)
+		c.setFunction(b, a, dark)
+	}
+}
+
+// versionBits returns the 18 version information bits of version.
+func versionBits(version int) int {
+	rem := version
+	for range 12 {
+		rem = rem<<1 ^ (rem>>11)*0x1f25
+	}
+
+	return version<<12 | rem
+}
+
+// drawCodewords places the codewords in the non-function modules, in the
+// zigzag order of two module wide columns, from the bottom right corner.
+// The remainder bits are left light.
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // skip the vertical timing pattern.
+		}
+
+		upward := (right+1)&2 == 0
+
+		for vert := range c.Size {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+
+			for j := range 2 {
+				x := right - j
+				if c.function[y*c.Size+x] || i >= 8*len(codewords) {
+					continue
+				}
+
+				c.modules[y*c.Size+x] = codewords[i/8]>>(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// applyMask flips the non-function modules selected by mask.
+func (c *Code) applyMask(mask int) {
+	for y := range c.Size {
+		for x := range c.Size {
+			if c.function[y*c.Size+x] || !masked(mask, x, y) {
+				continue
+			}
+
+			c.modules[y*c.Size+x] = !c.modules[y*c.Size+x]
+		}
+	}
+}
+
+func masked(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// penalty scores the readability of the code, lower being better,
+// using the four rules of the standard.
+func (c *Code) penalty() int {
+	p := 0
+
+	for i := range c.Size {
+		p += c.linePenalty(func(j int) bool { return c.Dark(j, i) })
+		p += c.linePenalty(func(j int) bool { return c.Dark(i, j) })
+	}
+
+	dark := 0
+
+	for y := range c.Size {
+		for x := range c.Size {
+			if c.Dark(x, y) {
+				dark++
+			}
+
+			if x+1 < c.Size && y+1 < c.Size {
+				d := c.Dark(x, y)
+				if d == c.Dark(x+1, y) && d == c.Dark(x, y+1) && d == c.Dark(x+1, y+1) {
+					p += 3
+				}
+			}
+		}
+	}
+
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+
+	return p + k*10
+}
+
+// finderLike is the 1:1:3:1:1 finder pattern ratio preceded by four light modules.
+var finderLike = []bool{false, false, false, false, true, false, true, true, true, false, true}
+
+// linePenalty scores the runs of same colored modules,
+// and the finder like patterns of a row or column.
+func (c *Code) linePenalty(dark func(int) bool) int {
+	p, run := 0, 1
+
+	for j := 1; j <= c.Size; j++ {
+		if j < c.Size && dark(j) == dark(j-1) {
+			run++
+			continue
+		}
+
+		if run >= 5 {
+			p += run - 2
+		}
+
+		run = 1
+	}
+
+	for j := 0; j+len(finderLike) <= c.Size; j++ {
+		forward, backward := true, true
+
+		for k, want := range finderLike {
+			forward = forward && dark(j+k) == want
+			backward = backward && dark(j+len(finderLike)-1-k) == want
+		}
+
+		if forward {
+			p += 40
+		}
+
+		if backward {
+			p += 40
+		}
+	}
+
+	return p
+}
+
+// Terminal renders the code using half block characters, two rows per line,
+// with explicit colors, so that it scans on dark and light terminals alike.
+func (c *Code) Terminal() string {
+	const (
+		quiet = 2 // quiet is the light margin around the code, in modules.
+
+		colors = "\x1b[97;40m" // white foreground, black background.
+		reset  = "\x1b[0m"
+	)
+
+	dark := func(x, y int) bool {
+		x, y = x-quiet, y-quiet
+		return x >= 0 && x < c.Size && y >= 0 && y < c.Size && c.Dark(x, y)
+	}
+
+	var sb strings.Builder
+
+	size := c.Size + 2*quiet
+
+	for y := 0; y < size; y += 2 {
+		sb.WriteString(colors)
+
+		for x := range size {
+			// the foreground draws the light upper module, the background the light lower one.
+			switch top, bottom := !dark(x, y), !dark(x, y+1); {
+			case top && bottom:
+				sb.WriteString("█")
+			case top:
+				sb.WriteString("▀")
+			case bottom:
+				sb.WriteString("▄")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+
+		sb.WriteString(reset + "\n")
+	}
+
+	return sb.String()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// bitBuffer is a sequence of bits, appended most significant first.
+type bitBuffer struct {
+	bytes []byte
+	n     int
+}
+
+func (bb *bitBuffer) len() int { return bb.n }
+
+// append appends the n low bits of v.
+func (bb *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if bb.n%8 == 0 {
+			bb.bytes = append(bb.bytes, 0)
+		}
+
+		if v>>i&1 == 1 {
+			bb.bytes[bb.n/8] |= 0x80 >> (bb.n % 8)
+		}
+
+		bb.n++
+	}
+}
@@ -0,0 +1,226 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestRSRemainder checks the error correction codewords of the "HELLO WORLD"
+// example of the standard, encoded as version 1-M.
+func TestRSRemainder(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	if got, want := fmt.Sprintf("%015b", formatBits(0)), "101010000010010"; got != want {
+		t.Errorf("M mask 0: got %s, want %s", got, want)
+	}
+
+	if got, want := fmt.Sprintf("%018b", versionBits(7)), "000111110010010100"; got != want {
+		t.Errorf("version 7: got %s, want %s", got, want)
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	tests := map[int][]int{
+		1:  nil,
+		2:  {6, 18},
+		6:  {6, 34},
+		7:  {6, 22, 38},
+		13: {6, 34, 62},
+		14: {6, 26, 46, 66},
+		16: {6, 26, 50, 74},
+		20: {6, 34, 62, 90},
+	}
+
+	for version, want := range tests {
+		if got := alignmentPositions(version); !slices.Equal(got, want) {
+			t.Errorf("version %d: got %v, want %v", version, got, want)
+		}
+	}
+}
+
+// TestCapacity checks that the modules left for data hold exactly the total
+// codewords of each version, along with its remainder bits.
+func TestCapacity(t *testing.T) {
+	totals := []int{26, 44, 70, 100, 134, 172, 196, 242, 292, 346, 404, 466, 532, 581, 655, 733, 815, 901, 991, 1085}
+
+	for version := 1; version <= maxVersion; version++ {
+		remainder := 0
+
+		switch {
+		case version >= 2 && version <= 6:
+			remainder = 7
+		case version >= 14:
+			remainder = 3
+		}
+
+		if got := versions[version].totalCodewords(); got != totals[version-1] {
+			t.Errorf("version %d: got %d total codewords, want %d", version, got, totals[version-1])
+		}
+
+		c := newCode(version)
+		c.drawFunctionPatterns(version)
+
+		free := 0
+
+		for _, f := range c.function {
+			if !f {
+				free++
+			}
+		}
+
+		if want := 8*totals[version-1] + remainder; free != want {
+			t.Errorf("version %d: got %d data modules, want %d", version, free, want)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	for _, n := range []int{0, 14, 15, 100, 300, 666} {
+		data := []byte(strings.Repeat("otpauth://totp/", n/15+1))[:n]
+
+		c, err := Encode(data)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+
+		if got := decode(t, c); !bytes.Equal(got, data) {
+			t.Errorf("%d bytes: decoded %q", n, got)
+		}
+	}
+
+	if _, err := Encode(make([]byte, 667)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("got %v, want %v", err, ErrTooLong)
+	}
+}
+
+// decode reads the data of c back, through its format information.
+func decode(t *testing.T, c *Code) []byte {
+	t.Helper()
+
+	version := (c.Size - 17) / 4
+
+	format := 0
+	for i := range 8 {
+		if c.Dark(c.Size-1-i, 8) {
+			format |= 1 << i
+		}
+	}
+
+	for i := 8; i < 15; i++ {
+		if c.Dark(8, c.Size-15+i) {
+			format |= 1 << i
+		}
+	}
+
+	mask := slices.IndexFunc([]int{0, 1, 2, 3, 4, 5, 6, 7}, func(m int) bool { return formatBits(m) == format })
+	if mask < 0 {
+		t.Fatalf("invalid format information %015b", format)
+	}
+
+	c.applyMask(mask)
+	defer c.applyMask(mask)
+
+	b := versions[version]
+
+	var bb bitBuffer
+
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+
+		for vert := range c.Size {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = c.Size - 1 - vert
+			}
+
+			for j := range 2 {
+				if x := right - j; !c.function[y*c.Size+x] && bb.len() < 8*b.totalCodewords() {
+					bb.append(boolToInt(c.Dark(x, y)), 1)
+				}
+			}
+		}
+	}
+
+	// deinterleave the data codewords.
+	blockCount := b.short + b.long
+	dataBlocks := make([][]byte, blockCount)
+	codewords := bb.bytes
+
+	for i := range b.data + 1 {
+		for k := range blockCount {
+			if i == b.data && k < b.short {
+				continue
+			}
+
+			dataBlocks[k] = append(dataBlocks[k], codewords[0])
+			codewords = codewords[1:]
+		}
+	}
+
+	divisor := rsDivisor(b.ecc)
+
+	for i := range b.ecc {
+		for k := range blockCount {
+			if want := rsRemainder(dataBlocks[k], divisor)[i]; codewords[0] != want {
+				t.Fatalf("block %d: ecc codeword %d mismatch", k, i)
+			}
+
+			codewords = codewords[1:]
+		}
+	}
+
+	data := slices.Concat(dataBlocks...)
+
+	if mode := data[0] >> 4; mode != 0b0100 {
+		t.Fatalf("got mode %04b, want byte mode", mode)
+	}
+
+	// read past the mode indicator.
+	r := bitReader{data: data, pos: 4}
+
+	n := r.read(countBits(version))
+	out := make([]byte, n)
+
+	for i := range out {
+		out[i] = byte(r.read(8))
+	}
+
+	return out
+}
+
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) read(n int) int {
+	v := 0
+
+	for range n {
+		v = v<<1 | int(r.data[r.pos/8]>>(7-r.pos%8)&1)
+		r.pos++
+	}
+
+	return v
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
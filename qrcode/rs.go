@@ -0,0 +1,53 @@
+package qrcode
+
+// rsDivisor returns the coefficients of the Reed-Solomon generator polynomial
+// of the given degree, highest first, omitting the leading one.
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+
+	for range degree {
+		for j := range result {
+			result[j] = gfMul(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+
+		root = gfMul(root, 0x02)
+	}
+
+	return result
+}
+
+// rsRemainder returns the Reed-Solomon error correction codewords of data.
+func rsRemainder(data []byte, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+
+		for i, d := range divisor {
+			result[i] ^= gfMul(d, factor)
+		}
+	}
+
+	return result
+}
+
+// gfMul multiplies x and y in GF(2^8), modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMul(x, y byte) byte {
+	var z byte
+
+	for i := 7; i >= 0; i-- {
+		// multiply z by two, reducing modulo the polynomial.
+		z = z<<1 ^ (z>>7)*0x1d
+		z ^= (y >> i & 1) * x
+	}
+
+	return z
+}
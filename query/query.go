@@ -0,0 +1,267 @@
+// Package query parses and evaluates search queries over secrets,
+// e.g., "name:github label:work url:*.example.com modified:<30d".
+//
+// A query is made of whitespace-separated terms, all of which a secret must
+// match. Terms are negated by a leading '-', and values holding spaces are
+// double-quoted, e.g., name:"my secret". The supported terms are:
+//
+//	label:GLOB     the secret has a label matching GLOB
+//	name:GLOB      the secret name matches GLOB
+//	url:GLOB       the secret has a URL label whose host, or the whole URL, matches GLOB
+//	modified:>AGE  the secret value was last set more than AGE ago
+//	modified:<AGE  the secret value was last set less than AGE ago
+//	GLOB           the secret name or any of its labels matches GLOB
+//
+// Globs are matched as by SQLite GLOB, as used by 'vlt find': '*' matches any
+// sequence of characters, including '/', '?' any single character, and
+// '[...]' any of the bracketed characters. Ages accept any
+// [time.ParseDuration] value, as well as whole days (e.g., 90d) and weeks
+// (e.g., 2w).
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+)
+
+// Secret holds the secret attributes queries are evaluated against.
+type Secret struct {
+	Name       string
+	Labels     []string
+	ModifiedAt time.Time // ModifiedAt is the time the secret value was last set.
+}
+
+// Query is a parsed search query.
+type Query struct {
+	raw   string
+	terms []term
+}
+
+type term struct {
+	negated bool
+	match   func(s Secret, now time.Time) bool
+	usesAge bool
+}
+
+// Parse parses the given query.
+func Parse(s string) (*Query, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, errors.New("empty query")
+	}
+
+	q := &Query{raw: s, terms: make([]term, 0, len(tokens))}
+
+	for _, tok := range tokens {
+		t, err := parseTerm(tok)
+		if err != nil {
+			return nil, err
+		}
+
+		q.terms = append(q.terms, t)
+	}
+
+	return q, nil
+}
+
+// String returns the query as given to [Parse].
+func (q *Query) String() string { return q.raw }
+
+// UsesModified reports whether the query has modified terms, evaluated
+// against [Secret.ModifiedAt]. Modification times need not be set otherwise.
+func (q *Query) UsesModified() bool {
+	return slices.ContainsFunc(q.terms, func(t term) bool { return t.usesAge })
+}
+
+// Match reports whether the secret matches all the query terms,
+// ages being counted back from now.
+func (q *Query) Match(s Secret, now time.Time) bool {
+	for _, t := range q.terms {
+		if t.match(s, now) == t.negated {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseTerm(tok string) (term, error) {
+	t := term{}
+
+	if strings.HasPrefix(tok, "-") && len(tok) > 1 {
+		t.negated, tok = true, tok[1:]
+	}
+
+	key, value, ok := strings.Cut(tok, ":")
+	if !ok {
+		re, err := compileGlob(tok)
+		if err != nil {
+			return term{}, err
+		}
+
+		t.match = func(s Secret, _ time.Time) bool {
+			return re.MatchString(s.Name) || slices.ContainsFunc(s.Labels, re.MatchString)
+		}
+
+		return t, nil
+	}
+
+	if len(value) == 0 {
+		return term{}, fmt.Errorf("%s: missing value", key)
+	}
+
+	switch key {
+	case "label":
+		re, err := compileGlob(value)
+		if err != nil {
+			return term{}, err
+		}
+
+		t.match = func(s Secret, _ time.Time) bool { return slices.ContainsFunc(s.Labels, re.MatchString) }
+	case "name":
+		re, err := compileGlob(value)
+		if err != nil {
+			return term{}, err
+		}
+
+		t.match = func(s Secret, _ time.Time) bool { return re.MatchString(s.Name) }
+	case "url":
+		re, err := compileGlob(value)
+		if err != nil {
+			return term{}, err
+		}
+
+		t.match = func(s Secret, _ time.Time) bool {
+			return slices.ContainsFunc(s.Labels, func(l string) bool { return matchURL(re, l) })
+		}
+	case "modified":
+		op, age := value[0], value[1:]
+		if op != '>' && op != '<' {
+			return term{}, fmt.Errorf("modified: %q: expected '>' or '<' followed by an age, e.g., >90d", value)
+		}
+
+		d, err := cmdutil.ParseDuration(age)
+		if err != nil {
+			return term{}, fmt.Errorf("modified: %w", err)
+		}
+
+		t.usesAge = true
+		t.match = func(s Secret, now time.Time) bool {
+			if op == '>' {
+				return now.Sub(s.ModifiedAt) > d
+			}
+
+			return now.Sub(s.ModifiedAt) < d
+		}
+	default:
+		return term{}, fmt.Errorf("unsupported term %q (supported: label, name, url, modified)", key)
+	}
+
+	return t, nil
+}
+
+// matchURL reports whether label is a URL whose host, or the whole URL, matches re.
+func matchURL(re *regexp.Regexp, label string) bool {
+	if !strings.Contains(label, "://") {
+		return false
+	}
+
+	if re.MatchString(label) {
+		return true
+	}
+
+	u, err := url.Parse(label)
+
+	return err == nil && re.MatchString(u.Hostname())
+}
+
+// tokenize splits s on whitespace outside of double quotes, removing the quotes.
+func tokenize(s string) ([]string, error) {
+	var (
+		tokens []string
+		tok    strings.Builder
+		quoted bool
+		inTok  bool
+	)
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted, inTok = !quoted, true
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if inTok {
+				tokens = append(tokens, tok.String())
+				tok.Reset()
+			}
+
+			inTok = false
+		default:
+			tok.WriteRune(r)
+
+			inTok = true
+		}
+	}
+
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+
+	if inTok {
+		tokens = append(tokens, tok.String())
+	}
+
+	return tokens, nil
+}
+
+// compileGlob compiles the given SQLite GLOB pattern into an anchored regexp.
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+
+	b.WriteString("^")
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			b.WriteString("(?s:.*)")
+		case '?':
+			b.WriteString("(?s:.)")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == 0 { // a leading ']' is part of the set.
+				end = strings.IndexByte(glob[i+2:], ']') + 1
+			}
+
+			if end <= 0 {
+				return nil, fmt.Errorf("invalid glob %q: unterminated '['", glob)
+			}
+
+			set := glob[i+1 : i+1+end]
+			if strings.HasPrefix(set, "^") {
+				set = "^" + regexp.QuoteMeta(set[1:])
+			} else {
+				set = regexp.QuoteMeta(set)
+			}
+
+			b.WriteString("[" + set + "]")
+
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,95 @@
+package query_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/query"
+)
+
+func TestQuery_Match(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	secret := query.Secret{
+		Name:       "github/octocat",
+		Labels:     []string{"work/dev", "2fa", "https://login.example.com/sso"},
+		ModifiedAt: now.Add(-100 * 24 * time.Hour),
+	}
+
+	tests := []struct {
+		query string
+		match bool
+	}{
+		{"label:work/*", true},
+		{"label:work", false},
+		{"label:work/* -label:archive", true},
+		{"label:work/* -label:2fa", false},
+		{"name:github/*", true},
+		{"name:gitlab/*", false},
+		{"github", false},
+		{"github*", true},
+		{"2fa", true},
+		{"label:[0-9]fa", true},
+		{"label:[^0-9]fa", false},
+		{"url:*.example.com", true},
+		{"url:example.com", false},
+		{"url:https://login.example.com/*", true},
+		{"url:work/*", false},
+		{"name:github/* url:*.example.com -label:archive", true},
+		{"modified:>90d", true},
+		{"modified:<90d", false},
+		{"-modified:>90d", false},
+		{"label:work/* modified:>90d modified:<15w", true},
+		{`name:"github/octocat"`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := query.Parse(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := q.Match(secret, now); got != tt.match {
+				t.Errorf("Match() = %v, want %v", got, tt.match)
+			}
+		})
+	}
+}
+
+func TestQuery_QuotedValues(t *testing.T) {
+	q, err := query.Parse(`name:"my secret" -label:"old stuff"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !q.Match(query.Secret{Name: "my secret", Labels: []string{"stuff"}}, time.Now()) {
+		t.Error("quoted name: got no match")
+	}
+
+	if q.Match(query.Secret{Name: "my secret", Labels: []string{"old stuff"}}, time.Now()) {
+		t.Error("quoted negated label: got match")
+	}
+
+	if q.UsesModified() {
+		t.Error("UsesModified() = true, want false")
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"   ",
+		"owner:me",
+		"url:",
+		"label:",
+		"modified:90d",
+		"modified:>soon",
+		`name:"unterminated`,
+		"label:[abc",
+	} {
+		if _, err := query.Parse(s); err == nil {
+			t.Errorf("Parse(%q): got nil err", s)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package recovery tracks the one-time recovery codes stored as secrets.
+//
+// Recovery codes, e.g., the backup codes of a two-factor account, are stored
+// as JSON objects holding the fields of [Codes], recording which of the codes
+// were used, and when.
+package recovery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Label marks the secrets holding recovery codes.
+const Label = "type=recovery-codes"
+
+// LowRemaining is the number of unused codes, at or below which
+// the codes are considered running low.
+const LowRemaining = 2
+
+// ErrExhausted indicates all recovery codes were used.
+var ErrExhausted = errors.New("all recovery codes were used")
+
+// Code is a single one-time recovery code.
+type Code struct {
+	Code   string `json:"code"`
+	UsedAt string `json:"used_at,omitempty"` // UsedAt is the time the code was used, formatted as RFC 3339.
+}
+
+// Used reports whether the code was used.
+func (c *Code) Used() bool { return len(c.UsedAt) > 0 }
+
+// Codes holds a list of one-time recovery codes, used in order.
+type Codes struct {
+	Codes []Code `json:"codes"`
+}
+
+// New returns the unused codes listed in text, separated by whitespace.
+func New(text string) (Codes, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return Codes{}, errors.New("no recovery codes given")
+	}
+
+	codes := Codes{Codes: make([]Code, 0, len(fields))}
+	for _, f := range fields {
+		codes.Codes = append(codes.Codes, Code{Code: f})
+	}
+
+	return codes, nil
+}
+
+// Parse parses recovery codes stored as a JSON object.
+func Parse(value string) (Codes, error) {
+	var c Codes
+	if err := json.Unmarshal([]byte(value), &c); err != nil {
+		return Codes{}, fmt.Errorf("parse recovery codes: %w", err)
+	}
+
+	return c, nil
+}
+
+// Remaining returns the number of unused codes.
+func (c *Codes) Remaining() int {
+	n := 0
+
+	for _, code := range c.Codes {
+		if !code.Used() {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Low reports whether the unused codes are running low, see [LowRemaining].
+func (c *Codes) Low() bool { return c.Remaining() <= LowRemaining }
+
+// Use marks the next unused code as used at the given time, and returns it.
+// It returns [ErrExhausted] if all codes were used.
+func (c *Codes) Use(now time.Time) (string, error) {
+	for i := range c.Codes {
+		if c.Codes[i].Used() {
+			continue
+		}
+
+		c.Codes[i].UsedAt = now.UTC().Format(time.RFC3339)
+
+		return c.Codes[i].Code, nil
+	}
+
+	return "", ErrExhausted
+}
+
+// LastUsed returns the time the last code was used, or the zero time if none was.
+func (c *Codes) LastUsed() time.Time {
+	var last time.Time
+
+	for _, code := range c.Codes {
+		if t, err := time.Parse(time.RFC3339, code.UsedAt); err == nil && t.After(last) {
+			last = t
+		}
+	}
+
+	return last
+}
@@ -0,0 +1,66 @@
+package recovery_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/recovery"
+)
+
+func TestCodes_Use(t *testing.T) {
+	codes, err := recovery.New("aaaa-1111 bbbb-2222\ncccc-3333\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := codes.Remaining(); got != 3 {
+		t.Fatalf("Remaining() = %d, want 3", got)
+	}
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, want := range []string{"aaaa-1111", "bbbb-2222"} {
+		got, err := codes.Use(now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != want {
+			t.Errorf("Use() = %q, want %q", got, want)
+		}
+	}
+
+	if !codes.Low() || codes.Remaining() != 1 {
+		t.Errorf("after 2 uses: Remaining() = %d, Low() = %v", codes.Remaining(), codes.Low())
+	}
+
+	b, err := json.Marshal(codes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := recovery.Parse(string(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !parsed.LastUsed().Equal(now) {
+		t.Errorf("LastUsed() = %v, want %v", parsed.LastUsed(), now)
+	}
+
+	if got, err := parsed.Use(now); err != nil || got != "cccc-3333" {
+		t.Errorf("Use() of parsed codes = %q, %v, want %q", got, err, "cccc-3333")
+	}
+
+	if _, err := parsed.Use(now); !errors.Is(err, recovery.ErrExhausted) {
+		t.Errorf("Use() of exhausted codes: got err %v, want %v", err, recovery.ErrExhausted)
+	}
+}
+
+func TestNew_Empty(t *testing.T) {
+	if _, err := recovery.New(" \n "); err == nil {
+		t.Error("New() of no codes: got nil err")
+	}
+}
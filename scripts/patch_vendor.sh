@@ -16,3 +16,22 @@ type Conn = conn
 EOF
 
 echo "[vendor-patch] Applied: $PATCH_FILE"
+
+ARGON2_PATCH_FILE="vendor/golang.org/x/crypto/argon2/argon2_patch.go"
+
+cat >"$ARGON2_PATCH_FILE" <<EOF
+// Patch: expose Argon2d key derivation for reading KeePass databases
+// NOTE: This file is auto-generated by patch_vendor.sh
+package argon2
+
+// DKey derives a key from the password, salt, and cost parameters using
+// Argon2d, like [IDKey] does using Argon2id.
+//
+// It is exposed to read KeePass KDBX 4 databases,
+// whose keys are derived using Argon2d by default.
+func DKey(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	return deriveKey(argon2d, password, salt, nil, nil, time, memory, threads, keyLen)
+}
+EOF
+
+echo "[vendor-patch] Applied: $ARGON2_PATCH_FILE"
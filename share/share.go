@@ -0,0 +1,168 @@
+// Package share seals single secrets into bundles encrypted for age
+// recipients, using the external `age` command, e.g., to hand a credential
+// to a colleague instead of pasting it into a chat.
+//
+// A bundle is an ASCII armored age file holding the json encoded secret,
+// along with optional expiry metadata. Expiry is enforced on opening, it does
+// not prevent the recipient from decrypting the bundle using age directly.
+package share
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCmd = "age"
+
+	// version is the version of the bundle encoding.
+	version = 1
+
+	ageKeyPrefix = "AGE-SECRET-KEY-"
+)
+
+var (
+	// ErrExpired indicates a bundle opened past its expiry.
+	ErrExpired = errors.New("share: bundle expired")
+
+	// ErrFormat indicates decrypted content that is not a bundle.
+	ErrFormat = errors.New("share: not a vlt share bundle")
+
+	// ErrIdentity indicates key material that is not an age identity.
+	ErrIdentity = errors.New("share: key is not an age identity ('AGE-SECRET-KEY-...')")
+)
+
+// Bundle is a shared secret.
+type Bundle struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	Labels    []string  `json:"labels,omitempty"`
+	Value     []byte    `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"` // ExpiresAt is zero if the bundle never expires.
+}
+
+// Expired reports whether the bundle expired at now.
+func (b Bundle) Expired(now time.Time) bool {
+	return !b.ExpiresAt.IsZero() && !now.Before(b.ExpiresAt)
+}
+
+// CommandError wraps a failed age invocation along with its stderr output.
+type CommandError struct {
+	Op     string
+	Stderr string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	msg := "share: " + e.Op + ": " + e.Err.Error()
+	if s := strings.TrimSpace(e.Stderr); len(s) > 0 {
+		msg += ": " + s
+	}
+
+	return msg
+}
+
+func (e *CommandError) Unwrap() error { return e.Err }
+
+// Seal returns the bundle encrypted for the given recipients, age public keys
+// ('age1...') or SSH public keys, as accepted by 'age --recipient'.
+func Seal(ctx context.Context, b Bundle, recipients []string) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("share: no recipients")
+	}
+
+	raw, err := encode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"--encrypt", "--armor"}
+	for _, r := range recipients {
+		args = append(args, "--recipient", r)
+	}
+
+	return run(ctx, "seal", raw, args...)
+}
+
+// Open decrypts a sealed bundle using the given age identity, as written by
+// 'age-keygen', rejecting it if expired at now.
+func Open(ctx context.Context, sealed []byte, identity string, now time.Time) (Bundle, error) {
+	if !strings.Contains(identity, ageKeyPrefix) {
+		return Bundle{}, ErrIdentity
+	}
+
+	// the identity is only written to a private directory for the duration of the command.
+	dir, err := os.MkdirTemp("", "vlt-share-*")
+	if err != nil {
+		return Bundle{}, &CommandError{Op: "open", Err: err}
+	}
+	defer func() { _ = os.RemoveAll(dir) }() //nolint:wsl
+
+	path := filepath.Join(dir, "identity.txt")
+	if err := os.WriteFile(path, []byte(identity), 0o600); err != nil {
+		return Bundle{}, &CommandError{Op: "open", Err: err}
+	}
+
+	raw, err := run(ctx, "open", sealed, "--decrypt", "--identity", path)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	return decode(raw, now)
+}
+
+func run(ctx context.Context, op string, stdin []byte, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(defaultCmd); err != nil {
+		return nil, &CommandError{Op: op, Err: err}
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, defaultCmd, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, &CommandError{Op: op, Stderr: stderr.String(), Err: err}
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func encode(b Bundle) ([]byte, error) {
+	if len(b.Name) == 0 {
+		return nil, errors.New("share: bundle has no name")
+	}
+
+	b.Version = version
+
+	return json.Marshal(b)
+}
+
+func decode(raw []byte, now time.Time) (Bundle, error) {
+	var b Bundle
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return Bundle{}, ErrFormat
+	}
+
+	switch {
+	case b.Version == 0 || len(b.Name) == 0:
+		return Bundle{}, ErrFormat
+	case b.Version > version:
+		return Bundle{}, fmt.Errorf("share: unsupported bundle version %d, upgrade vlt", b.Version)
+	case b.Expired(now):
+		return Bundle{}, fmt.Errorf("%w at %s", ErrExpired, b.ExpiresAt.Local().Format(time.DateTime))
+	}
+
+	return b, nil
+}
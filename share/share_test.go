@@ -0,0 +1,79 @@
+package share
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	b := Bundle{
+		Name:      "db",
+		Labels:    []string{"prod"},
+		Value:     []byte{0xff, 's', 'e', 'c'},
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	}
+
+	raw, err := encode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := decode(raw, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Version != version || got.Name != b.Name || !slices.Equal(got.Labels, b.Labels) ||
+		!bytes.Equal(got.Value, b.Value) || !got.CreatedAt.Equal(b.CreatedAt) || !got.ExpiresAt.Equal(b.ExpiresAt) {
+		t.Errorf("got %+v, want %+v", got, b)
+	}
+
+	if _, err := decode(raw, now.Add(time.Hour)); !errors.Is(err, ErrExpired) {
+		t.Errorf("decode expired: got %v, want %v", err, ErrExpired)
+	}
+
+	// bundles without an expiry never expire.
+	b.ExpiresAt = time.Time{}
+
+	raw, err = encode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := decode(raw, now.AddDate(100, 0, 0)); err != nil {
+		t.Errorf("decode: %v", err)
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"not json",
+		`{"name": "db"}`,
+		`{"version": 1}`,
+	} {
+		if _, err := decode([]byte(raw), time.Now()); !errors.Is(err, ErrFormat) {
+			t.Errorf("%q: got %v, want %v", raw, err, ErrFormat)
+		}
+	}
+
+	if _, err := decode([]byte(`{"version": 2, "name": "db"}`), time.Now()); err == nil {
+		t.Error("expected an error on a newer version")
+	}
+
+	if _, err := encode(Bundle{}); err == nil {
+		t.Error("expected an error on a bundle without a name")
+	}
+}
+
+func TestOpen_Identity(t *testing.T) {
+	if _, err := Open(t.Context(), nil, "not an identity", time.Now()); !errors.Is(err, ErrIdentity) {
+		t.Errorf("got %v, want %v", err, ErrIdentity)
+	}
+}
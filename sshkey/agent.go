@@ -0,0 +1,163 @@
+package sshkey
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// ErrReadOnlyAgent is returned to clients trying to change the keys of an [Agent].
+var ErrReadOnlyAgent = errors.New("agent keys are managed in the vault, use 'vlt ssh-key add'")
+
+// ConfirmFunc is called before each signature using the key of the given
+// name, the signature is denied if it fails.
+type ConfirmFunc func(name string, key ssh.PublicKey) error
+
+// Agent serves stored keys using the ssh-agent protocol.
+//
+// Keys are held in memory, and cannot be added or removed by clients.
+// The agent may be locked and unlocked using a passphrase, as any other agent.
+type Agent struct {
+	keyring agent.ExtendedAgent
+	names   map[string]string // names holds the key names by their wire encoded public keys.
+
+	confirmMu sync.Mutex // confirmMu serializes confirmations, not to prompt for several at once.
+	confirm   ConfirmFunc
+}
+
+var _ agent.ExtendedAgent = &Agent{}
+
+// NewAgent returns an agent serving the given keys, by name. If confirm is
+// not nil, it is called to confirm each use of a key.
+func NewAgent(keys map[string]Key, confirm ConfirmFunc) (*Agent, error) {
+	a := &Agent{
+		keyring: agent.NewKeyring().(agent.ExtendedAgent), //nolint:forcetypeassert // the keyring implements the extended agent.
+		names:   make(map[string]string, len(keys)),
+		confirm: confirm,
+	}
+
+	for name, k := range keys {
+		raw, err := k.Raw()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		if err := a.keyring.Add(agent.AddedKey{PrivateKey: raw, Comment: name}); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		pub, err := k.Public()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		a.names[string(pub.Marshal())] = name
+	}
+
+	return a, nil
+}
+
+// Serve accepts ssh-agent connections on l until ctx is done.
+func (a *Agent) Serve(ctx context.Context, l net.Listener) error {
+	stop := context.AfterFunc(ctx, func() { _ = l.Close() })
+	defer stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { _ = conn.Close() }()
+
+			stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+			defer stop()
+
+			_ = agent.ServeAgent(a, conn)
+		}()
+	}
+}
+
+// List returns the identities of the served keys, commented by their names.
+func (a *Agent) List() ([]*agent.Key, error) {
+	return a.keyring.List()
+}
+
+// Sign signs data using the given key, once confirmed.
+func (a *Agent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+// SignWithFlags signs data using the given key and flags, once confirmed.
+func (a *Agent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	name, err := a.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.confirm != nil {
+		a.confirmMu.Lock()
+		err := a.confirm(name, key)
+		a.confirmMu.Unlock()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return a.keyring.SignWithFlags(key, data, flags)
+}
+
+// lookup returns the name of the given key, if served and the agent is unlocked.
+func (a *Agent) lookup(key ssh.PublicKey) (string, error) {
+	keys, err := a.keyring.List()
+	if err != nil {
+		return "", err
+	}
+
+	wire := key.Marshal()
+
+	for _, k := range keys {
+		if bytes.Equal(k.Blob, wire) {
+			return a.names[string(wire)], nil
+		}
+	}
+
+	return "", errors.New("agent: key not found")
+}
+
+// Signers is not supported, as signers would bypass the use confirmation.
+func (*Agent) Signers() ([]ssh.Signer, error) {
+	return nil, errors.New("agent: signers are not exposed")
+}
+
+func (*Agent) Add(agent.AddedKey) error { return ErrReadOnlyAgent }
+
+func (*Agent) Remove(ssh.PublicKey) error { return ErrReadOnlyAgent }
+
+func (*Agent) RemoveAll() error { return ErrReadOnlyAgent }
+
+func (a *Agent) Lock(passphrase []byte) error { return a.keyring.Lock(passphrase) }
+
+func (a *Agent) Unlock(passphrase []byte) error { return a.keyring.Unlock(passphrase) }
+
+func (*Agent) Extension(string, []byte) ([]byte, error) {
+	return nil, agent.ErrExtensionUnsupported
+}
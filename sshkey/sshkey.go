@@ -0,0 +1,123 @@
+// Package sshkey stores SSH private keys as secrets, and serves them
+// using the ssh-agent protocol.
+//
+// Keys are stored as JSON objects holding the fields of [Key], so that
+// their fields can be selected as the ones of any other JSON secret.
+// Private keys are stored unencrypted, protected by the vault encryption.
+package sshkey
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Label marks the secrets holding SSH keys.
+const Label = "type=ssh-key"
+
+// Fields lists the JSON fields of a key.
+var Fields = []string{"private_key", "public_key", "comment"}
+
+// ErrPassphraseRequired indicates the parsed private key is encrypted,
+// and no passphrase was given.
+var ErrPassphraseRequired = errors.New("ssh key is encrypted, passphrase required")
+
+// Key holds an SSH private key.
+type Key struct {
+	PrivateKey string `json:"private_key"` // PrivateKey is the unencrypted private key, in the OpenSSH PEM format.
+	PublicKey  string `json:"public_key"`  // PublicKey is the public key, in the authorized_keys format.
+	Comment    string `json:"comment,omitempty"`
+}
+
+// New returns the key of the given PEM encoded private key, in any of the
+// formats supported by OpenSSH. Encrypted keys are decrypted using passphrase.
+func New(pemBytes []byte, passphrase []byte, comment string) (Key, error) {
+	raw, err := ssh.ParseRawPrivateKey(pemBytes)
+
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if len(passphrase) == 0 {
+			return Key{}, ErrPassphraseRequired
+		}
+
+		raw, err = ssh.ParseRawPrivateKeyWithPassphrase(pemBytes, passphrase)
+	}
+
+	if err != nil {
+		return Key{}, fmt.Errorf("parse ssh key: %w", err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(raw, comment)
+	if err != nil {
+		return Key{}, fmt.Errorf("marshal ssh key: %w", err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(raw)
+	if err != nil {
+		return Key{}, fmt.Errorf("parse ssh key: %w", err)
+	}
+
+	return Key{
+		PrivateKey: string(pem.EncodeToMemory(block)),
+		PublicKey:  authorizedKey(signer.PublicKey(), comment),
+		Comment:    comment,
+	}, nil
+}
+
+// Parse parses a key stored as a JSON object.
+func Parse(value string) (Key, error) {
+	var k Key
+	if err := json.Unmarshal([]byte(value), &k); err != nil {
+		return Key{}, fmt.Errorf("parse ssh key: %w", err)
+	}
+
+	return k, nil
+}
+
+// Raw returns the parsed private key, as accepted by [ssh.NewSignerFromKey].
+func (k *Key) Raw() (any, error) {
+	raw, err := ssh.ParseRawPrivateKey([]byte(k.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("parse ssh key: %w", err)
+	}
+
+	return raw, nil
+}
+
+// Public returns the parsed public key.
+func (k *Key) Public() (ssh.PublicKey, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(k.PublicKey)) //nolint:dogsled
+	if err != nil {
+		return nil, fmt.Errorf("parse ssh public key: %w", err)
+	}
+
+	return pub, nil
+}
+
+// Field returns the value of the given field, see [Fields].
+func (k *Key) Field(name string) (string, bool) {
+	switch name {
+	case "private_key":
+		return k.PrivateKey, true
+	case "public_key":
+		return k.PublicKey, true
+	case "comment":
+		return k.Comment, true
+	default:
+		return "", false
+	}
+}
+
+// authorizedKey formats pub in the authorized_keys format, followed by comment.
+func authorizedKey(pub ssh.PublicKey, comment string) string {
+	s := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub)))
+	if len(comment) > 0 {
+		s += " " + comment
+	}
+
+	return s
+}
@@ -0,0 +1,175 @@
+package sshkey_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/sshkey"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func newTestKey(t *testing.T, passphrase string) (ed25519.PrivateKey, []byte) {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var block *pem.Block
+	if len(passphrase) > 0 {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase))
+	} else {
+		block, err = ssh.MarshalPrivateKey(priv, "")
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return priv, pem.EncodeToMemory(block)
+}
+
+func TestNew(t *testing.T) {
+	priv, encrypted := newTestKey(t, "secret")
+
+	if _, err := sshkey.New(encrypted, nil, ""); !errors.Is(err, sshkey.ErrPassphraseRequired) {
+		t.Fatalf("New() of encrypted key without passphrase: got err %v, want %v", err, sshkey.ErrPassphraseRequired)
+	}
+
+	if _, err := sshkey.New(encrypted, []byte("wrong"), ""); err == nil {
+		t.Fatal("New() of encrypted key with wrong passphrase: got nil err")
+	}
+
+	k, err := sshkey.New(encrypted, []byte("secret"), "me@host")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(k.PrivateKey, "ENCRYPTED") {
+		t.Error("stored private key is encrypted")
+	}
+
+	want, err := ssh.NewPublicKey(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, wantStr := k.PublicKey, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(want)))+" me@host"; got != wantStr {
+		t.Errorf("PublicKey = %q, want %q", got, wantStr)
+	}
+
+	pub, err := k.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ssh.FingerprintSHA256(pub) != ssh.FingerprintSHA256(want) {
+		t.Error("Public() fingerprint mismatch")
+	}
+}
+
+func TestAgent(t *testing.T) {
+	_, pemBytes := newTestKey(t, "")
+
+	k, err := sshkey.New(pemBytes, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		confirmed []string
+		deny      bool
+	)
+
+	a, err := sshkey.NewAgent(map[string]sshkey.Key{"github": k}, func(name string, _ ssh.PublicKey) error {
+		confirmed = append(confirmed, name)
+		if deny {
+			return errors.New("denied")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("unix", t.TempDir()+"/agent.sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() { _ = a.Serve(t.Context(), l) }()
+
+	conn, err := net.Dial("unix", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	client := agent.NewClient(conn)
+
+	keys, err := client.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 1 || keys[0].Comment != "github" {
+		t.Fatalf("List() = %v, want the github key", keys)
+	}
+
+	pub, err := k.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := client.Sign(pub, []byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pub.Verify([]byte("data"), sig); err != nil {
+		t.Errorf("verify signature: %v", err)
+	}
+
+	deny = true
+
+	if _, err := client.Sign(pub, []byte("data")); err == nil {
+		t.Error("Sign() denied by confirmation: got nil err")
+	}
+
+	if want := []string{"github", "github"}; strings.Join(confirmed, ",") != strings.Join(want, ",") {
+		t.Errorf("confirmed uses: got %v, want %v", confirmed, want)
+	}
+
+	_, other := newTestKey(t, "")
+
+	raw, err := ssh.ParseRawPrivateKey(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Add(agent.AddedKey{PrivateKey: raw}); err == nil {
+		t.Error("Add(): got nil err")
+	}
+
+	if err := client.Lock([]byte("pass")); err != nil {
+		t.Fatal(err)
+	}
+
+	if keys, err := client.List(); err != nil || len(keys) != 0 {
+		t.Errorf("List() of locked agent: got %d keys, err %v", len(keys), err)
+	}
+
+	if err := client.Unlock([]byte("pass")); err != nil {
+		t.Fatal(err)
+	}
+}
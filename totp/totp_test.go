@@ -0,0 +1,139 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/totp"
+)
+
+// TestKey_Code checks the test vectors of RFC 6238 appendix B.
+func TestKey_Code(t *testing.T) {
+	seeds := map[string]string{
+		"SHA1":   "12345678901234567890",
+		"SHA256": "12345678901234567890123456789012",
+		"SHA512": "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+
+	tests := []struct {
+		unix int64
+		want map[string]string
+	}{
+		{59, map[string]string{"SHA1": "94287082", "SHA256": "46119246", "SHA512": "90693936"}},
+		{1111111109, map[string]string{"SHA1": "07081804", "SHA256": "68084774", "SHA512": "25091201"}},
+		{1111111111, map[string]string{"SHA1": "14050471", "SHA256": "67062674", "SHA512": "99943326"}},
+		{1234567890, map[string]string{"SHA1": "89005924", "SHA256": "91819424", "SHA512": "93441116"}},
+		{2000000000, map[string]string{"SHA1": "69279037", "SHA256": "90698825", "SHA512": "38618901"}},
+		{20000000000, map[string]string{"SHA1": "65353130", "SHA256": "77737706", "SHA512": "47863826"}},
+	}
+
+	for algorithm, seed := range seeds {
+		secret := base32.StdEncoding.EncodeToString([]byte(seed))
+
+		k, err := totp.Parse("otpauth://totp/vlt?digits=8&algorithm=" + algorithm + "&secret=" + secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tt := range tests {
+			if got := k.Code(time.Unix(tt.unix, 0)); got != tt.want[algorithm] {
+				t.Errorf("%s: Code(%d) = %q, want %q", algorithm, tt.unix, got, tt.want[algorithm])
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		seed    string
+		want    totp.Key
+		wantErr error
+	}{
+		{
+			name: "base32 key",
+			seed: " jbsw y3dp ehpk 3pxp ",
+			want: totp.Key{Algorithm: "SHA1", Digits: 6, Period: 30 * time.Second},
+		},
+		{
+			name: "uri",
+			seed: "otpauth://totp/ACME%20Co:jane@example.com?secret=JBSWY3DPEHPK3PXP&period=60&digits=8",
+			want: totp.Key{Algorithm: "SHA1", Digits: 8, Period: time.Minute, Issuer: "ACME Co", Account: "jane@example.com"},
+		},
+		{
+			name: "uri issuer parameter",
+			seed: "otpauth://totp/jane?secret=JBSWY3DPEHPK3PXP&issuer=ACME&algorithm=sha256",
+			want: totp.Key{Algorithm: "SHA256", Digits: 6, Period: 30 * time.Second, Issuer: "ACME", Account: "jane"},
+		},
+		{name: "not base32", seed: "not a seed!", wantErr: totp.ErrInvalidSeed},
+		{name: "empty", seed: "", wantErr: totp.ErrInvalidSeed},
+		{name: "too short", seed: "NOPE", wantErr: totp.ErrInvalidSeed},
+		{name: "hotp", seed: "otpauth://hotp/jane?secret=JBSWY3DPEHPK3PXP", wantErr: totp.ErrInvalidURI},
+		{name: "algorithm", seed: "otpauth://totp/jane?secret=JBSWY3DPEHPK3PXP&algorithm=MD5", wantErr: totp.ErrInvalidURI},
+		{name: "digits", seed: "otpauth://totp/jane?secret=JBSWY3DPEHPK3PXP&digits=4", wantErr: totp.ErrInvalidURI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := totp.Parse(tt.seed)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if string(got.Secret) != "Hello!\xde\xad\xbe\xef" {
+				t.Errorf("Parse() secret = %q", got.Secret)
+			}
+
+			got.Secret = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKey_URI(t *testing.T) {
+	keys := []totp.Key{
+		{Secret: []byte("Hello!\xde\xad\xbe\xef"), Algorithm: "SHA256", Digits: 8, Period: time.Minute, Issuer: "ACME Co", Account: "jane@example.com"},
+		{Secret: []byte("Hello!\xde\xad\xbe\xef"), Algorithm: "SHA1", Digits: 6, Period: 30 * time.Second, Account: "jane"},
+	}
+
+	for _, k := range keys {
+		uri := k.URI()
+
+		got, err := totp.Parse(uri)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", uri, err)
+		}
+
+		if !reflect.DeepEqual(got, k) {
+			t.Errorf("Parse(%q) = %+v, want %+v", uri, got, k)
+		}
+	}
+
+	want := "otpauth://totp/ACME%20Co:jane@example.com?algorithm=SHA256&digits=8&issuer=ACME+Co&period=60&secret=JBSWY3DPEHPK3PXP"
+	if got := keys[0].URI(); got != want {
+		t.Errorf("URI() = %q, want %q", got, want)
+	}
+}
+func TestKey_Remaining(t *testing.T) {
+	k, err := totp.Parse("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := k.Remaining(time.Unix(59, 0)); got != time.Second {
+		t.Errorf("Remaining(59) = %v, want %v", got, time.Second)
+	}
+
+	if got := k.Remaining(time.Unix(60, 0)); got != 30*time.Second {
+		t.Errorf("Remaining(60) = %v, want %v", got, 30*time.Second)
+	}
+}
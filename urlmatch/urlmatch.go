@@ -0,0 +1,124 @@
+// Package urlmatch ranks the URL patterns of logins against page URLs.
+//
+// Logins store their URL patterns as secret labels, such as the ones imported
+// from browsers, e.g., "https://github.com/login". A pattern matches pages of
+// its host and of the subdomains of its host, on any path. Matches are ranked
+// by their quality: pages of the pattern host rank above the ones of its
+// subdomains, then the longer the path shared by the pattern and the page,
+// the better the match.
+package urlmatch
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// subdomainRank is the rank of a match on a subdomain of the pattern host,
+// and hostRank the one on the host itself, both added to the matching path
+// segments count, capped below subdomainRank.
+const (
+	subdomainRank = 1 << 16
+	hostRank      = 2 * subdomainRank
+)
+
+// IsPattern reports whether s is a URL pattern, i.e., a URL with a scheme and a host.
+func IsPattern(s string) bool {
+	if !strings.Contains(s, "://") {
+		return false
+	}
+
+	u, err := url.Parse(s)
+
+	return err == nil && len(u.Scheme) > 0 && len(u.Hostname()) > 0
+}
+
+// ParsePage parses the URL of a page, defaulting its scheme to https,
+// e.g., "github.com/login" is parsed as "https://github.com/login".
+func ParsePage(rawURL string) (*url.URL, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(u.Hostname()) == 0 {
+		return nil, fmt.Errorf("parse %q: missing host", rawURL)
+	}
+
+	return u, nil
+}
+
+// Rank returns the quality of the match of the given pattern and page,
+// the higher the better, or 0 if they do not match.
+//
+// Patterns of https URLs do not match http pages, and patterns holding
+// a port only match pages of the same port.
+func Rank(pattern string, page *url.URL) int {
+	if !IsPattern(pattern) {
+		return 0
+	}
+
+	p, err := url.Parse(pattern)
+	if err != nil {
+		return 0
+	}
+
+	if strings.EqualFold(p.Scheme, "https") && strings.EqualFold(page.Scheme, "http") {
+		return 0
+	}
+
+	if len(p.Port()) > 0 && p.Port() != page.Port() {
+		return 0
+	}
+
+	host, pageHost := strings.ToLower(p.Hostname()), strings.ToLower(page.Hostname())
+
+	var rank int
+
+	switch {
+	case host == pageHost:
+		rank = hostRank
+	case strings.HasSuffix(pageHost, "."+host):
+		rank = subdomainRank
+	default:
+		return 0
+	}
+
+	return rank + min(sharedSegments(p.Path, page.Path), subdomainRank-1)
+}
+
+// RankAny returns the best rank of the given patterns, see [Rank].
+// Labels that are not URL patterns are ignored.
+func RankAny(patterns []string, page *url.URL) int {
+	best := 0
+	for _, p := range patterns {
+		best = max(best, Rank(p, page))
+	}
+
+	return best
+}
+
+// sharedSegments returns the number of leading path segments shared by a and b.
+func sharedSegments(a, b string) int {
+	as, bs := segments(a), segments(b)
+
+	n := 0
+	for n < len(as) && n < len(bs) && as[n] == bs[n] {
+		n++
+	}
+
+	return n
+}
+
+func segments(path string) []string {
+	path = strings.Trim(path, "/")
+	if len(path) == 0 {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
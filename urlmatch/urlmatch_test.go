@@ -0,0 +1,83 @@
+package urlmatch_test
+
+import (
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/urlmatch"
+)
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		page    string
+		match   bool
+	}{
+		{"same host", "https://github.com/login", "https://github.com/", true},
+		{"subdomain", "https://github.com/login", "https://gist.github.com/", true},
+		{"case insensitive host", "https://GitHub.com", "github.com", true},
+		{"scheme upgrade", "http://example.com", "https://example.com", true},
+		{"scheme downgrade", "https://example.com", "http://example.com", false},
+		{"other host", "https://github.com", "https://gitlab.com", false},
+		{"suffix host", "https://hub.com", "https://github.com", false},
+		{"parent domain", "https://gist.github.com", "https://github.com", false},
+		{"same port", "https://example.com:8443", "https://example.com:8443/app", true},
+		{"other port", "https://example.com:8443", "https://example.com/app", false},
+		{"not a pattern", "personal", "https://personal", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := urlmatch.ParsePage(tt.page)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := urlmatch.Rank(tt.pattern, page) > 0; got != tt.match {
+				t.Errorf("Rank(%q, %q) > 0 = %v, want %v", tt.pattern, tt.page, got, tt.match)
+			}
+		})
+	}
+}
+
+func TestRank_Order(t *testing.T) {
+	page, err := urlmatch.ParsePage("https://app.example.com/admin/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// from the best match to the worst.
+	patterns := []string{
+		"https://app.example.com/admin/users",
+		"https://app.example.com/admin",
+		"https://app.example.com/",
+		"https://example.com/admin/users",
+		"https://example.com/login",
+	}
+
+	for i := 1; i < len(patterns); i++ {
+		better, worse := urlmatch.Rank(patterns[i-1], page), urlmatch.Rank(patterns[i], page)
+		if better <= worse || worse == 0 {
+			t.Errorf("Rank(%q) = %d, Rank(%q) = %d: want a decreasing positive rank", patterns[i-1], better, patterns[i], worse)
+		}
+	}
+
+	if got, want := urlmatch.RankAny(patterns[2:], page), urlmatch.Rank(patterns[2], page); got != want {
+		t.Errorf("RankAny() = %d, want %d", got, want)
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	u, err := urlmatch.ParsePage("github.com/login")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := u.String(), "https://github.com/login"; got != want {
+		t.Errorf("ParsePage() = %q, want %q", got, want)
+	}
+
+	if _, err := urlmatch.ParsePage("https://"); err == nil {
+		t.Error("ParsePage(): expected an error for a URL without host")
+	}
+}
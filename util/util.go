@@ -1,7 +1,10 @@
 package util
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func ParseCommaSeparated(raw string) []string {
@@ -17,6 +20,29 @@ func ParseCommaSeparated(raw string) []string {
 	return res
 }
 
+// ParseDuration parses a duration string as accepted by [time.ParseDuration],
+// additionally supporting whole days ("30d") and weeks ("2w").
+func ParseDuration(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+
+	units := map[byte]time.Duration{
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}
+
+	if len(s) > 1 {
+		if unit, ok := units[s[len(s)-1]]; ok {
+			if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n >= 0 {
+				return time.Duration(n) * unit, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("invalid duration %q", s)
+}
+
 func ToAnySlice[T any](ts []T) []any {
 	args := make([]any, len(ts))
 
@@ -0,0 +1,138 @@
+package vault
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaultcrypto"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// apiTokenPrefix prefixes API token strings, which have the form 'vltapi_<id>_<secret>'.
+const apiTokenPrefix = "vltapi_"
+
+// IssueAPIToken creates a new API token for the vault server,
+// scoped by the given options.
+//
+// Unlike access tokens issued by [Vault.IssueToken], API tokens cannot
+// unlock the vault, they authenticate requests to a server holding
+// the unlocked vault. Only a hash of the token secret is stored.
+//
+// The returned token string is the only copy of the token secret.
+func (vlt *Vault) IssueAPIToken(ctx context.Context, name string, opts TokenOptions) (string, vaultdb.APIToken, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return "", vaultdb.APIToken{}, errf("issue api token: %w", err)
+	}
+
+	if err := vlt.checkWritable(ctx); err != nil {
+		return "", vaultdb.APIToken{}, errf("issue api token: %w", err)
+	}
+
+	if len(opts.Scope) == 0 {
+		return "", vaultdb.APIToken{}, errf("issue api token: empty scope")
+	}
+
+	if opts.TTL <= 0 {
+		return "", vaultdb.APIToken{}, errf("issue api token: non-positive ttl")
+	}
+
+	id, err := vaultcrypto.RandBytes(tokenIDLen)
+	if err != nil {
+		return "", vaultdb.APIToken{}, errf("issue api token: %w", err)
+	}
+
+	secret, err := vaultcrypto.RandBytes(tokenSecretLen)
+	if err != nil {
+		return "", vaultdb.APIToken{}, errf("issue api token: %w", err)
+	}
+
+	hash := sha256.Sum256(secret)
+
+	t := vaultdb.APIToken{
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Hash:      hash[:],
+		Scope:     opts.Scope,
+		ReadOnly:  opts.ReadOnly,
+		ExpiresAt: time.Now().UTC().Add(opts.TTL).Truncate(time.Second),
+	}
+
+	if t.CreatedAt, err = vlt.db.InsertAPIToken(ctx, t); err != nil {
+		return "", vaultdb.APIToken{}, errf("issue api token: %w", err)
+	}
+
+	token := apiTokenPrefix + t.ID + "_" + base64.RawURLEncoding.EncodeToString(secret)
+
+	return token, t, nil
+}
+
+// APITokens returns all issued API tokens, including expired ones.
+func (vlt *Vault) APITokens(ctx context.Context) ([]vaultdb.APIToken, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return nil, errf("api tokens: %w", err)
+	}
+
+	return vlt.db.APITokens(ctx)
+}
+
+// RevokeAPITokens deletes the API tokens with the given ids,
+// returning the number of revoked tokens.
+func (vlt *Vault) RevokeAPITokens(ctx context.Context, ids ...string) (int64, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return 0, errf("revoke api tokens: %w", err)
+	}
+
+	if err := vlt.checkWritable(ctx); err != nil {
+		return 0, errf("revoke api tokens: %w", err)
+	}
+
+	return vlt.db.DeleteAPITokens(ctx, ids)
+}
+
+// AuthenticateAPIToken verifies the given API token string at time now,
+// and returns the principal to perform the request on behalf of,
+// see [WithPrincipal].
+func (vlt *Vault) AuthenticateAPIToken(ctx context.Context, token string, now time.Time) (Principal, error) {
+	id, secret, err := parseToken(apiTokenPrefix, token)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	t, err := vlt.db.APIToken(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Principal{}, vaulterrors.ErrInvalidToken
+	}
+
+	if err != nil {
+		return Principal{}, errf("authenticate api token: %w", err)
+	}
+
+	hash := sha256.Sum256(secret)
+	if subtle.ConstantTimeCompare(hash[:], t.Hash) != 1 {
+		return Principal{}, vaulterrors.ErrInvalidToken
+	}
+
+	if !now.Before(t.ExpiresAt) {
+		return Principal{}, fmt.Errorf("%w: %s", vaulterrors.ErrTokenExpired, t.ExpiresAt.Local().Format(time.DateTime))
+	}
+
+	if len(t.Scope) == 0 {
+		return Principal{}, vaulterrors.ErrInvalidToken
+	}
+
+	p := Principal{
+		Actor:    "api:" + t.ID,
+		Scope:    t.Scope,
+		ReadOnly: t.ReadOnly,
+	}
+
+	return p, nil
+}
@@ -0,0 +1,82 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_APIToken(t *testing.T) {
+	v, err := New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	ciID, err := v.InsertNewSecret(t.Context(), "deploy", "ci-secret", []string{"ci/deploy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	personalID, err := v.InsertNewSecret(t.Context(), "email", "personal-secret", []string{"personal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, info, err := v.IssueAPIToken(t.Context(), "ci", TokenOptions{Scope: []string{"ci/*"}, ReadOnly: true, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.AuthenticateAPIToken(t.Context(), token[:len(token)-1]+"A", time.Now()); !errors.Is(err, vaulterrors.ErrInvalidToken) {
+		t.Errorf("authenticate tampered token: got err %v, want %v", err, vaulterrors.ErrInvalidToken)
+	}
+
+	if _, err := v.AuthenticateAPIToken(t.Context(), token, time.Now().Add(2*time.Hour)); !errors.Is(err, vaulterrors.ErrTokenExpired) {
+		t.Errorf("authenticate expired token: got err %v, want %v", err, vaulterrors.ErrTokenExpired)
+	}
+
+	p, err := v.AuthenticateAPIToken(t.Context(), token, time.Now())
+	if err != nil {
+		t.Fatalf("authenticate token: %v", err)
+	}
+
+	ctx := WithPrincipal(t.Context(), p)
+
+	if s, err := v.ShowSecret(ctx, ciID); err != nil || s != "ci-secret" {
+		t.Errorf("show scoped secret: got %q, %v", s, err)
+	}
+
+	if _, err := v.ShowSecret(ctx, personalID); !errors.Is(err, vaulterrors.ErrTokenScope) {
+		t.Errorf("show secret out of scope: got err %v, want %v", err, vaulterrors.ErrTokenScope)
+	}
+
+	if _, err := v.UpdateSecret(ctx, ciID, "new"); !errors.Is(err, vaulterrors.ErrReadOnly) {
+		t.Errorf("update with read-only token: got err %v, want %v", err, vaulterrors.ErrReadOnly)
+	}
+
+	head, _, err := v.AuditHead(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "api:" + info.ID; head.Operation != vaultdb.OpShow || head.Actor != want {
+		t.Errorf("audit head: got %s by %q, want %s by %q", head.Operation, head.Actor, vaultdb.OpShow, want)
+	}
+
+	if _, err := v.VerifyAuditLog(t.Context()); err != nil {
+		t.Errorf("verify audit log: %v", err)
+	}
+
+	if n, err := v.RevokeAPITokens(t.Context(), info.ID); err != nil || n != 1 {
+		t.Fatalf("revoke token: got %d, %v", n, err)
+	}
+
+	if _, err := v.AuthenticateAPIToken(t.Context(), token, time.Now()); !errors.Is(err, vaulterrors.ErrInvalidToken) {
+		t.Errorf("authenticate revoked token: got err %v, want %v", err, vaulterrors.ErrInvalidToken)
+	}
+}
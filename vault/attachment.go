@@ -0,0 +1,350 @@
+package vault
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultcontainer"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaultcrypto"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+const (
+	// attachmentChunkSize is the size of the separately encrypted
+	// chunks attachments are stored in.
+	attachmentChunkSize = 64 << 10
+
+	// attachmentAADPrefix binds the encrypted attachment data to its purpose.
+	attachmentAADPrefix = "vlt-attachment-v1"
+)
+
+// Attachment describes a file attached to a secret.
+type Attachment struct {
+	ID        int
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Attach stores the file read from r as an attachment of the given secret,
+// replacing any attachment of the same name.
+//
+// Attachments are stored in the vault container, outside of the in-memory
+// vault, in separately encrypted chunks, so that r is never read fully into
+// memory. As such, they are not part of vault backups, exports, or sync.
+func (vlt *Vault) Attach(ctx context.Context, secretID int, name string, r io.Reader) (_ Attachment, retErr error) {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return Attachment{}, errf("attach: %w", err)
+	}
+
+	if len(name) == 0 {
+		return Attachment{}, errf("attach: %w", vaulterrors.ErrEmptyName)
+	}
+
+	existing, err := vlt.attachments(ctx, secretID)
+	if err != nil {
+		return Attachment{}, errf("attach: %w", err)
+	}
+
+	nameNonce, nameCiphertext, err := vlt.sealAttachmentName(secretID, name)
+	if err != nil {
+		return Attachment{}, errf("attach: %w", err)
+	}
+
+	tx, err := vlt.vaultContainerHandle.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return Attachment{}, errf("attach: %w", err)
+	}
+
+	defer func() {
+		if retErr != nil {
+			retErr = errors.Join(retErr, tx.Rollback())
+		}
+	}()
+
+	store := vlt.vaultContainerHandle.db.WithTx(tx)
+
+	var replaced []int
+
+	for _, a := range existing {
+		if a.Name == name {
+			replaced = append(replaced, a.ID)
+		}
+	}
+
+	if _, err := store.DeleteAttachments(ctx, replaced); err != nil {
+		return Attachment{}, errf("attach: %w", err)
+	}
+
+	id, err := store.InsertAttachment(ctx, secretID, nameNonce, nameCiphertext)
+	if err != nil {
+		return Attachment{}, errf("attach: %w", err)
+	}
+
+	size, err := vlt.storeAttachmentChunks(ctx, store, id, r)
+	if err != nil {
+		return Attachment{}, errf("attach: %w", err)
+	}
+
+	if err := store.SetAttachmentSize(ctx, id, size); err != nil {
+		return Attachment{}, errf("attach: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Attachment{}, errf("attach: %w", err)
+	}
+
+	entry, err := vlt.audit(ctx, vlt.db, vaultdb.OpUpdate, secretID)
+	if err != nil {
+		return Attachment{}, errf("attach: audit: %w", err)
+	}
+
+	vlt.emit(entry)
+
+	return Attachment{ID: id, Name: name, Size: size, CreatedAt: time.Now().UTC()}, nil
+}
+
+// storeAttachmentChunks reads r in chunks, storing each encrypted,
+// and returns the number of bytes read.
+//
+// Chunks are read one ahead, so that the last chunk is known when sealed.
+// Empty files are stored as a single empty chunk.
+func (vlt *Vault) storeAttachmentChunks(ctx context.Context, store *vaultcontainer.VaultContainer, id int, r io.Reader) (int64, error) {
+	cur, next := make([]byte, attachmentChunkSize), make([]byte, attachmentChunkSize)
+
+	n, err := readChunk(r, cur)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+
+	for seq := 0; ; seq++ {
+		m := 0
+		if n == attachmentChunkSize {
+			if m, err = readChunk(r, next); err != nil {
+				return 0, err
+			}
+		}
+
+		last := m == 0
+
+		nonce, err := vaultcrypto.RandBytes(12)
+		if err != nil {
+			return 0, err
+		}
+
+		ciphertext := vlt.aesgcm.AEAD().Seal(nil, nonce, cur[:n], attachmentAAD(id, seq, last))
+
+		if err := store.InsertAttachmentChunk(ctx, id, vaultcontainer.AttachmentChunk{Seq: seq, Nonce: nonce, Ciphertext: ciphertext}); err != nil {
+			return 0, err
+		}
+
+		size += int64(n)
+
+		if last {
+			return size, nil
+		}
+
+		cur, next, n = next, cur, m
+	}
+}
+
+// readChunk reads up to len(buf) bytes from r, returning
+// the number of bytes read, short only at the end of r.
+func readChunk(r io.Reader, buf []byte) (int, error) {
+	n, err := io.ReadFull(r, buf)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return n, nil
+	}
+
+	return n, err
+}
+
+// Attachments returns the attachments of the given secret, ordered by creation.
+func (vlt *Vault) Attachments(ctx context.Context, secretID int) ([]Attachment, error) {
+	attachments, err := vlt.attachments(ctx, secretID)
+	if err != nil {
+		return nil, errf("attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+func (vlt *Vault) attachments(ctx context.Context, secretID int) ([]Attachment, error) {
+	if err := vlt.checkSecret(ctx, secretID); err != nil {
+		return nil, err
+	}
+
+	stored, err := vlt.vaultContainerHandle.db.Attachments(ctx, secretID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]Attachment, 0, len(stored))
+
+	for _, a := range stored {
+		name, err := vlt.aesgcm.AEAD().Open(nil, a.NameNonce, a.NameCiphertext, attachmentNameAAD(secretID))
+		if err != nil {
+			return nil, fmt.Errorf("attachment %d: %w", a.ID, err)
+		}
+
+		attachments = append(attachments, Attachment{ID: a.ID, Name: string(name), Size: a.Size, CreatedAt: a.CreatedAt})
+	}
+
+	return attachments, nil
+}
+
+// WriteAttachment writes the decrypted attachment of the given secret and
+// name to w, one chunk at a time, returning the number of bytes written.
+//
+// Chunks are authenticated as they are written, a missing or reordered
+// chunk fails the write, leaving w with the chunks preceding it.
+func (vlt *Vault) WriteAttachment(ctx context.Context, secretID int, name string, w io.Writer) (int64, error) {
+	a, err := vlt.attachment(ctx, secretID, name)
+	if err != nil {
+		return 0, errf("write attachment: %w", err)
+	}
+
+	var (
+		written int64
+		seq     int
+		last    bool
+	)
+
+	err = vlt.vaultContainerHandle.db.EachAttachmentChunk(ctx, a.ID, func(c vaultcontainer.AttachmentChunk) error {
+		if last || c.Seq != seq {
+			return fmt.Errorf("%q: unexpected chunk %d", name, c.Seq)
+		}
+
+		plaintext, err := vlt.aesgcm.AEAD().Open(nil, c.Nonce, c.Ciphertext, attachmentAAD(a.ID, seq, false))
+		if err != nil {
+			// the last chunk is authenticated as such.
+			plaintext, err = vlt.aesgcm.AEAD().Open(nil, c.Nonce, c.Ciphertext, attachmentAAD(a.ID, seq, true))
+			if err != nil {
+				return fmt.Errorf("%q: chunk %d: %w", name, c.Seq, err)
+			}
+
+			last = true
+		}
+
+		n, err := w.Write(plaintext)
+		written += int64(n)
+		seq++
+
+		return err
+	})
+	if err != nil {
+		return written, errf("write attachment: %w", err)
+	}
+
+	if !last {
+		return written, errf("write attachment: %q: truncated", name)
+	}
+
+	entry, err := vlt.auditRead(ctx, vaultdb.OpShow, secretID, false)
+	if err != nil {
+		return written, errf("write attachment: audit: %w", err)
+	}
+
+	vlt.emit(entry)
+
+	return written, nil
+}
+
+// RemoveAttachment removes the attachment of the given secret and name.
+func (vlt *Vault) RemoveAttachment(ctx context.Context, secretID int, name string) error {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("remove attachment: %w", err)
+	}
+
+	a, err := vlt.attachment(ctx, secretID, name)
+	if err != nil {
+		return errf("remove attachment: %w", err)
+	}
+
+	if _, err := vlt.vaultContainerHandle.db.DeleteAttachments(ctx, []int{a.ID}); err != nil {
+		return errf("remove attachment: %w", err)
+	}
+
+	entry, err := vlt.audit(ctx, vlt.db, vaultdb.OpUpdate, secretID)
+	if err != nil {
+		return errf("remove attachment: audit: %w", err)
+	}
+
+	vlt.emit(entry)
+
+	return nil
+}
+
+// attachment returns the attachment of the given secret and name.
+func (vlt *Vault) attachment(ctx context.Context, secretID int, name string) (Attachment, error) {
+	attachments, err := vlt.attachments(ctx, secretID)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	for _, a := range attachments {
+		if a.Name == name {
+			return a, nil
+		}
+	}
+
+	return Attachment{}, fmt.Errorf("%q: %w", name, vaulterrors.ErrAttachmentNotFound)
+}
+
+// checkSecret fails if the given secret does not exist, or is out of scope.
+func (vlt *Vault) checkSecret(ctx context.Context, secretID int) error {
+	if err := vlt.checkScope(ctx, vlt.db, secretID); err != nil {
+		return err
+	}
+
+	secrets, err := vlt.db.SecretsByIDs(ctx, []int{secretID})
+	if err != nil {
+		return err
+	}
+
+	if len(secrets) == 0 {
+		return fmt.Errorf("secret %d: %w", secretID, vaulterrors.ErrSearchNoMatch)
+	}
+
+	return nil
+}
+
+func (vlt *Vault) sealAttachmentName(secretID int, name string) (nonce []byte, ciphertext []byte, _ error) {
+	nonce, err := vaultcrypto.RandBytes(12)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nonce, vlt.aesgcm.AEAD().Seal(nil, nonce, []byte(name), attachmentNameAAD(secretID)), nil
+}
+
+// attachmentNameAAD binds an attachment name to its secret.
+func attachmentNameAAD(secretID int) []byte {
+	//nolint:gosec // ids are non-negative.
+	return binary.BigEndian.AppendUint64([]byte(attachmentAADPrefix+"/name"), uint64(secretID))
+}
+
+// attachmentAAD binds an attachment chunk to its attachment, its position,
+// and whether it is the last chunk, so that chunks cannot be moved,
+// reordered, or dropped unnoticed.
+func attachmentAAD(id int, seq int, last bool) []byte {
+	b := []byte(attachmentAADPrefix + "/chunk")
+
+	//nolint:gosec // ids and positions are non-negative.
+	b = binary.BigEndian.AppendUint64(b, uint64(id))
+	b = binary.BigEndian.AppendUint64(b, uint64(seq)) //nolint:gosec
+
+	if last {
+		return append(b, 1)
+	}
+
+	return append(b, 0)
+}
@@ -0,0 +1,121 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vaultcrypto"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_Attachments(t *testing.T) {
+	ctx := t.Context()
+
+	v, err := New(ctx, filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = v.Close(ctx) })
+
+	id, err := v.InsertNewSecret(ctx, "cluster", "token", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := vaultcrypto.RandBytes(3*attachmentChunkSize + 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.Attach(ctx, id, "kubeconfig", bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.Attach(ctx, id, "empty", bytes.NewReader(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) ([]byte, error) {
+		t.Helper()
+
+		var buf bytes.Buffer
+		_, err := v.WriteAttachment(ctx, id, name, &buf)
+
+		return buf.Bytes(), err
+	}
+
+	if got, err := read("kubeconfig"); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("WriteAttachment(): got %d bytes, err %v, want %d bytes", len(got), err, len(data))
+	}
+
+	if got, err := read("empty"); err != nil || len(got) != 0 {
+		t.Errorf("WriteAttachment() of empty file: got %d bytes, err %v", len(got), err)
+	}
+
+	if _, err := v.Attach(ctx, id, "kubeconfig", bytes.NewReader([]byte("replaced"))); err != nil {
+		t.Fatal(err)
+	}
+
+	attachments, err := v.Attachments(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(attachments) != 2 || attachments[0].Name != "empty" || attachments[1].Name != "kubeconfig" || attachments[1].Size != 8 {
+		t.Errorf("Attachments() after replace = %+v", attachments)
+	}
+
+	if _, err := read("missing"); !errors.Is(err, vaulterrors.ErrAttachmentNotFound) {
+		t.Errorf("WriteAttachment() of missing attachment: got err %v, want %v", err, vaulterrors.ErrAttachmentNotFound)
+	}
+
+	large, err := v.Attach(ctx, id, "large", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exec := func(query string, args ...any) {
+		t.Helper()
+
+		if _, err := v.vaultContainerHandle.conn.ExecContext(ctx, query, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// chunks swapped in place must fail authentication.
+	exec("UPDATE attachment_chunks SET seq = -1 WHERE attachment_id = ? AND seq = 0", large.ID)
+	exec("UPDATE attachment_chunks SET seq = 0 WHERE attachment_id = ? AND seq = 1", large.ID)
+	exec("UPDATE attachment_chunks SET seq = 1 WHERE attachment_id = ? AND seq = -1", large.ID)
+
+	if _, err := read("large"); err == nil {
+		t.Error("WriteAttachment() of reordered chunks: got nil err")
+	}
+
+	truncated, err := v.Attach(ctx, id, "truncated", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a dropped last chunk must be detected.
+	exec("DELETE FROM attachment_chunks WHERE attachment_id = ? AND seq = 3", truncated.ID)
+
+	if got, err := read("truncated"); err == nil || len(got) != 3*attachmentChunkSize {
+		t.Errorf("WriteAttachment() of truncated attachment: got %d bytes, err %v", len(got), err)
+	}
+
+	if _, err := v.PurgeSecretsByIDs(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	if err := v.vaultContainerHandle.conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM attachment_chunks").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 0 {
+		t.Errorf("chunks left after deleting the secret: %d", n)
+	}
+}
@@ -0,0 +1,178 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+// ErrAuditLogTampered indicates that the audit log hash chain
+// or one of its checkpoints failed verification.
+var ErrAuditLogTampered = errors.New("audit log tampered")
+
+// auditCheckpointVersion prefixes signed checkpoint payloads.
+const auditCheckpointVersion = "vlt-audit-checkpoint-v1"
+
+// audit records the given operation in the audit log using store,
+// chaining the new entry to the previous one.
+//
+// Operations on secrets that do not exist are not recorded,
+// the zero entry is returned instead, see [Vault.emit].
+func (vlt *Vault) audit(ctx context.Context, store *vaultdb.VaultDB, op vaultdb.Operation, secretID int) (vaultdb.AuditEntry, error) {
+	prev, err := store.LastAuditHash(ctx)
+	if err != nil {
+		return vaultdb.AuditEntry{}, err
+	}
+
+	entry, err := store.InsertAuditEntry(ctx, op, secretID, vlt.principal(ctx).Actor)
+	if err != nil {
+		return vaultdb.AuditEntry{}, err
+	}
+
+	if entry.ID == 0 {
+		return entry, nil
+	}
+
+	entry.PrevHash, entry.Hash = prev, auditEntryHash(prev, entry)
+
+	if err := store.SetAuditEntryHash(ctx, entry.ID, entry.PrevHash, entry.Hash); err != nil {
+		return vaultdb.AuditEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// auditEntryHash returns SHA-256 over the previous chain hash
+// and the length-prefixed entry fields.
+func auditEntryHash(prev []byte, e vaultdb.AuditEntry) []byte {
+	h := sha256.New()
+
+	//nolint:gosec // ids are non-negative.
+	id, secretID := uint64(e.ID), uint64(e.SecretID)
+
+	writeField(h, prev)
+	writeField(h, binary.BigEndian.AppendUint64(nil, id))
+	writeField(h, []byte(e.Operation))
+	writeField(h, binary.BigEndian.AppendUint64(nil, secretID))
+	writeField(h, []byte(e.SecretName))
+	writeField(h, []byte(e.CreatedAt.UTC().Format(time.RFC3339)))
+
+	// the actor is omitted for owner entries, keeping earlier chains valid.
+	if len(e.Actor) > 0 {
+		writeField(h, []byte(e.Actor))
+	}
+
+	return h.Sum(nil)
+}
+
+// AuditCheckpointPayload returns the payload to be signed
+// when checkpointing the audit log at the given entry.
+func AuditCheckpointPayload(entryID int, hash []byte) []byte {
+	return fmt.Appendf(nil, "%s\nentry=%d\nhash=%s\n", auditCheckpointVersion, entryID, hex.EncodeToString(hash))
+}
+
+// AuditHead returns the most recent audit log entry.
+// The second return value is false if the log is empty.
+func (vlt *Vault) AuditHead(ctx context.Context) (head vaultdb.AuditEntry, ok bool, _ error) {
+	for entry, err := range vlt.AuditLog(ctx, vaultdb.AuditFilters{}) {
+		if err != nil {
+			return vaultdb.AuditEntry{}, false, err
+		}
+
+		head, ok = entry, true
+	}
+
+	return head, ok, nil
+}
+
+// InsertAuditCheckpoint stores a signed checkpoint of the audit log chain.
+func (vlt *Vault) InsertAuditCheckpoint(ctx context.Context, entryID int, hash []byte, signer string, signature []byte) error {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("insert audit checkpoint: %w", err)
+	}
+
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return errf("insert audit checkpoint: %w", err)
+	}
+
+	return vlt.db.InsertAuditCheckpoint(ctx, entryID, hash, signer, signature)
+}
+
+// AuditVerification summarizes a successful audit log verification.
+type AuditVerification struct {
+	Entries     int                       // Entries is the number of chained entries verified.
+	Unchained   int                       // Unchained is the number of leading entries recorded before chaining was introduced.
+	Head        vaultdb.AuditEntry        // Head is the most recent entry.
+	Checkpoints []vaultdb.AuditCheckpoint // Checkpoints are the checkpoints matching the chain, signatures are not verified.
+}
+
+// VerifyAuditLog walks the audit log hash chain and checks that every entry
+// links to its predecessor, and that every checkpoint matches the chain.
+//
+// Checkpoint signatures are not verified, callers are expected to verify
+// them against [AuditCheckpointPayload].
+//
+// It returns an error wrapping [ErrAuditLogTampered] on the first mismatch.
+func (vlt *Vault) VerifyAuditLog(ctx context.Context) (*AuditVerification, error) {
+	var (
+		v      = &AuditVerification{}
+		prev   []byte
+		hashes = make(map[int][]byte)
+	)
+
+	for entry, err := range vlt.AuditLog(ctx, vaultdb.AuditFilters{}) {
+		if err != nil {
+			return nil, errf("verify audit log: %w", err)
+		}
+
+		if entry.Hash == nil {
+			if v.Entries > 0 {
+				return nil, fmt.Errorf("%w: entry %d: missing chain hash", ErrAuditLogTampered, entry.ID)
+			}
+
+			v.Unchained++
+
+			continue
+		}
+
+		if !bytes.Equal(entry.PrevHash, prev) {
+			return nil, fmt.Errorf("%w: entry %d: broken link to the previous entry", ErrAuditLogTampered, entry.ID)
+		}
+
+		if !bytes.Equal(entry.Hash, auditEntryHash(prev, entry)) {
+			return nil, fmt.Errorf("%w: entry %d: hash mismatch", ErrAuditLogTampered, entry.ID)
+		}
+
+		prev = entry.Hash
+		hashes[entry.ID] = entry.Hash
+		v.Head = entry
+		v.Entries++
+	}
+
+	checkpoints, err := vlt.db.AuditCheckpoints(ctx)
+	if err != nil {
+		return nil, errf("verify audit log: %w", err)
+	}
+
+	for _, c := range checkpoints {
+		hash, ok := hashes[c.EntryID]
+		if !ok {
+			return nil, fmt.Errorf("%w: checkpoint %d: entry %d is missing", ErrAuditLogTampered, c.ID, c.EntryID)
+		}
+
+		if !bytes.Equal(hash, c.Hash) {
+			return nil, fmt.Errorf("%w: checkpoint %d: hash mismatch at entry %d", ErrAuditLogTampered, c.ID, c.EntryID)
+		}
+	}
+
+	v.Checkpoints = checkpoints
+
+	return v, nil
+}
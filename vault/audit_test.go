@@ -0,0 +1,99 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestVault_VerifyAuditLog(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper string
+	}{
+		{
+			name:   "modified entry",
+			tamper: `UPDATE audit_log SET secret_name = 'other' WHERE id = 1`,
+		},
+		{
+			name:   "removed entry",
+			tamper: `DELETE FROM audit_log WHERE id = 2`,
+		},
+		{
+			name:   "reordered entries",
+			tamper: `UPDATE audit_log SET operation = CASE id WHEN 1 THEN 'show' ELSE 'insert' END WHERE id IN (1, 3)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Cleanup(func() { _ = v.Close(t.Context()) })
+
+			id, err := v.InsertNewSecret(t.Context(), "name", "secret", []string{"label"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := v.ShowSecret(t.Context(), id); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := v.DeleteSecretsByIDs(t.Context(), id); err != nil {
+				t.Fatal(err)
+			}
+
+			verification, err := v.VerifyAuditLog(t.Context())
+			if err != nil {
+				t.Fatalf("verify untampered log: %v", err)
+			}
+
+			if got, want := verification.Entries, 3; got != want {
+				t.Fatalf("verified entries: got %d, want %d", got, want)
+			}
+
+			if _, err := v.conn.ExecContext(t.Context(), tt.tamper); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := v.VerifyAuditLog(t.Context()); !errors.Is(err, ErrAuditLogTampered) {
+				t.Errorf("verify tampered log: got err %v, want %v", err, ErrAuditLogTampered)
+			}
+		})
+	}
+}
+
+func TestVault_AuditMissingSecret(t *testing.T) {
+	v, err := New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = v.Close(t.Context()) })
+
+	id, err := v.InsertNewSecret(t.Context(), "name", "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.UpdateSecret(t.Context(), id, "updated"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := v.DeleteSecretsByIDs(t.Context(), id+1); err != nil || n != 0 {
+		t.Fatalf("delete missing secret: got %d, %v, want 0, nil", n, err)
+	}
+
+	verification, err := v.VerifyAuditLog(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := verification.Entries, 2; got != want {
+		t.Errorf("verified entries: got %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,551 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaultcrypto"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+const (
+	// backupDeltaMagic prefixes incremental backups, and binds their ciphertext to the format version.
+	backupDeltaMagic = "vlt-backup-delta-v1"
+
+	// backupKeyInfo binds the backup key derived from the vault key.
+	backupKeyInfo = "vlt-backup"
+)
+
+// BackupState identifies the vault state captured by a backup.
+//
+//nolint:tagliatelle
+type BackupState struct {
+	// KeyID fingerprints the vault key. Deltas are sealed using a key
+	// derived from the vault key, so they only apply to snapshots of the same key.
+	KeyID         string `json:"key_id"`
+	SchemaVersion int    `json:"schema_version"`
+
+	// AuditID is the id of the last audit log entry captured,
+	// and AuditHash its chain hash, identifying the vault history.
+	AuditID   int    `json:"audit_id"`
+	AuditHash []byte `json:"audit_hash,omitempty"`
+
+	// OplogClock is the latest sequence number of each device in the captured oplog.
+	OplogClock map[string]int `json:"oplog_clock,omitempty"`
+
+	// Rows is the number of rows of each captured vault table.
+	Rows map[string]int `json:"rows,omitempty"`
+}
+
+// Compare returns an error describing the first difference between the
+// captured state s and the given state of a restored vault, or nil if they match.
+//
+// Schema versions are not compared, as restored vaults may have been migrated since.
+// Row counts are only compared if captured by both states.
+func (s *BackupState) Compare(got *BackupState) error {
+	switch {
+	case s.KeyID != got.KeyID:
+		return fmt.Errorf("key id: got %s, want %s", got.KeyID, s.KeyID)
+	case s.AuditID != got.AuditID:
+		return fmt.Errorf("audit log head: got entry %d, want %d", got.AuditID, s.AuditID)
+	case !bytes.Equal(s.AuditHash, got.AuditHash):
+		return fmt.Errorf("audit log head: hash mismatch at entry %d", s.AuditID)
+	case !maps.Equal(s.OplogClock, got.OplogClock):
+		return fmt.Errorf("oplog clock: got %v, want %v", got.OplogClock, s.OplogClock)
+	}
+
+	if s.Rows == nil || got.Rows == nil {
+		return nil
+	}
+
+	for _, table := range slices.Sorted(maps.Keys(s.Rows)) {
+		if want, n := s.Rows[table], got.Rows[table]; n != want {
+			return fmt.Errorf("%s: got %d rows, want %d", table, n, want)
+		}
+	}
+
+	return nil
+}
+
+// backupDelta holds the changes made to a vault since a snapshot.
+type backupDelta struct {
+	Base  BackupState
+	State BackupState
+
+	// Secrets are the secrets inserted or updated since the base snapshot,
+	// and Deleted the ids of secrets deleted since.
+	Secrets []vaultdb.BackupSecret
+	Deleted []int
+
+	// AuditLog and Oplog hold the entries appended since the base snapshot.
+	AuditLog []vaultdb.AuditEntry
+	Oplog    []vaultdb.OplogEntry
+
+	// tables that are small, or rarely appended to, are captured in full.
+	AuditCheckpoints      []vaultdb.AuditCheckpoint
+	APITokens             []vaultdb.APIToken
+	Links                 []vaultdb.Link
+	CloudSecrets          []vaultdb.CloudSecret
+	SyncState             *vaultdb.SyncState
+	BitwardenAccount      *vaultdb.BitwardenAccount
+	KeePassXCAssociations []vaultdb.KeePassXCAssociation
+}
+
+// Snapshot returns a full backup of the vault along with the state it captured.
+//
+// The snapshot is a copy of the sealed vault container, without its history,
+// so it can be opened as a vault file using the master password.
+func (vlt *Vault) Snapshot(ctx context.Context) ([]byte, *BackupState, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return nil, nil, errf("snapshot: %w", err)
+	}
+
+	// in read-only mode, the vault cannot diverge from its container.
+	if !vlt.readOnly {
+		if err := vlt.seal(ctx); err != nil {
+			return nil, nil, errf("snapshot: %w", err)
+		}
+	}
+
+	state, err := vlt.backupState(ctx)
+	if err != nil {
+		return nil, nil, errf("snapshot: %w", err)
+	}
+
+	serialized, err := Serialize(vlt.vaultContainerHandle.conn)
+	if err != nil {
+		return nil, nil, errf("snapshot: %w", err)
+	}
+
+	compacted, err := compactSnapshot(ctx, serialized)
+	if err != nil {
+		return nil, nil, errf("snapshot: %w", err)
+	}
+
+	return compacted, state, nil
+}
+
+// compactSnapshot drops the vault history from the given serialized vault container.
+func compactSnapshot(ctx context.Context, serialized []byte) (_ []byte, retErr error) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, errors.Join(err, db.Close())
+	}
+	// conn.Close only, to avoid double-closing the shared driver connection.
+	defer func() { retErr = errors.Join(retErr, conn.Close()) }() //nolint:wsl
+
+	buf := slices.Clone(serialized) // copied to avoid side effects from the underlying sqlite3 driver.
+
+	if err := Deserialize(conn, buf); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, "DELETE FROM vault_history"); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, "VACUUM"); err != nil {
+		return nil, err
+	}
+
+	return Serialize(conn)
+}
+
+// Delta returns an incremental backup of the changes made since the snapshot
+// that captured base, along with the state it captured.
+//
+// Changed secrets are identified using the audit log. The delta is sealed using
+// a key derived from the vault key. If the vault does not descend from the base
+// snapshot, e.g., the master key or schema changed since, it fails with
+// [vaulterrors.ErrBackupBaseMismatch], and a new snapshot is required.
+func (vlt *Vault) Delta(ctx context.Context, base *BackupState) ([]byte, *BackupState, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	state, err := vlt.backupState(ctx)
+	if err != nil {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	if err := vlt.checkBackupBase(ctx, base, state); err != nil {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	d := backupDelta{Base: *base, State: *state}
+
+	ids, err := vlt.db.ChangedSecretIDs(ctx, base.AuditID)
+	if err != nil {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	if d.Secrets, err = vlt.db.BackupSecrets(ctx, ids); err != nil {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	for _, id := range ids {
+		if !slices.ContainsFunc(d.Secrets, func(s vaultdb.BackupSecret) bool { return s.ID == id }) {
+			d.Deleted = append(d.Deleted, id)
+		}
+	}
+
+	for e, err := range vlt.db.AuditLog(ctx, vaultdb.AuditFilters{AfterID: base.AuditID}) {
+		if err != nil {
+			return nil, nil, errf("delta: %w", err)
+		}
+
+		d.AuditLog = append(d.AuditLog, e)
+	}
+
+	oplog, err := vlt.db.OplogEntries(ctx)
+	if err != nil {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	d.Oplog = slices.DeleteFunc(oplog, func(e vaultdb.OplogEntry) bool { return e.Seq <= base.OplogClock[e.Device] })
+
+	if d.AuditCheckpoints, err = vlt.db.AuditCheckpoints(ctx); err != nil {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	if d.APITokens, err = vlt.db.APITokens(ctx); err != nil {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	if d.Links, err = vlt.db.Links(ctx); err != nil {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	if d.CloudSecrets, err = vlt.db.CloudSecrets(ctx, ""); err != nil {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	syncState, err := vlt.db.SyncState(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	if err == nil {
+		d.SyncState = &syncState
+	}
+
+	bitwardenAccount, err := vlt.db.BitwardenAccount(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	if err == nil {
+		d.BitwardenAccount = &bitwardenAccount
+	}
+
+	if d.KeePassXCAssociations, err = vlt.db.KeePassXCAssociations(ctx); err != nil {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	sealed, err := vlt.sealBackupDelta(&d)
+	if err != nil {
+		return nil, nil, errf("delta: %w", err)
+	}
+
+	return sealed, state, nil
+}
+
+// ApplyDelta applies an incremental backup created by [Vault.Delta] to the vault,
+// which must be a restored copy of the snapshot the delta applies to.
+//
+// If the vault is not in the state the delta applies to, it fails with
+// [vaulterrors.ErrBackupBaseMismatch].
+func (vlt *Vault) ApplyDelta(ctx context.Context, data []byte) (retErr error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return errf("apply delta: %w", err)
+	}
+
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("apply delta: %w", err)
+	}
+
+	d, err := vlt.openBackupDelta(data)
+	if err != nil {
+		return errf("apply delta: %w", err)
+	}
+
+	state, err := vlt.backupState(ctx)
+	if err != nil {
+		return errf("apply delta: %w", err)
+	}
+
+	if d.State.SchemaVersion > state.SchemaVersion {
+		return errf("apply delta: created by a newer vlt version (schema version %d)", d.State.SchemaVersion)
+	}
+
+	// the restored snapshot may have been migrated since, the captured rows apply to later schemas as well.
+	base := d.Base
+	base.SchemaVersion = state.SchemaVersion
+
+	if state.AuditID != base.AuditID {
+		return errf("apply delta: %w: vault is not at the base snapshot", vaulterrors.ErrBackupBaseMismatch)
+	}
+
+	if err := vlt.checkBackupBase(ctx, &base, state); err != nil {
+		return errf("apply delta: %w", err)
+	}
+
+	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errf("apply delta: %w", err)
+	}
+	defer func() { //nolint:wsl
+		if retErr != nil {
+			if err := tx.Rollback(); err != nil {
+				retErr = errors.Join(retErr, errf("apply delta: rollback: %w", err))
+			}
+		}
+	}()
+
+	storeTx := vlt.db.WithTx(tx)
+
+	// changed secrets are replaced as a whole, along with their labels.
+	replaced := slices.Clone(d.Deleted)
+	for _, s := range d.Secrets {
+		replaced = append(replaced, s.ID)
+	}
+
+	if len(replaced) > 0 {
+		if _, err := storeTx.DeleteSecretsByIDs(ctx, replaced); err != nil {
+			return errf("apply delta: %w", err)
+		}
+	}
+
+	for _, s := range d.Secrets {
+		if err := storeTx.RestoreSecret(ctx, s); err != nil {
+			return errf("apply delta: secret %d: %w", s.ID, err)
+		}
+	}
+
+	for _, e := range d.AuditLog {
+		if err := storeTx.RestoreAuditEntry(ctx, e); err != nil {
+			return errf("apply delta: audit entry %d: %w", e.ID, err)
+		}
+	}
+
+	for _, e := range d.Oplog {
+		if err := storeTx.InsertOplogEntry(ctx, e); err != nil {
+			return errf("apply delta: oplog entry %s/%d: %w", e.Device, e.Seq, err)
+		}
+	}
+
+	if err := storeTx.RestoreAuditCheckpoints(ctx, d.AuditCheckpoints); err != nil {
+		return errf("apply delta: %w", err)
+	}
+
+	if err := storeTx.RestoreAPITokens(ctx, d.APITokens); err != nil {
+		return errf("apply delta: %w", err)
+	}
+
+	if err := storeTx.RestoreLinks(ctx, d.Links); err != nil {
+		return errf("apply delta: %w", err)
+	}
+
+	if err := storeTx.RestoreCloudSecrets(ctx, d.CloudSecrets); err != nil {
+		return errf("apply delta: %w", err)
+	}
+
+	if d.SyncState != nil {
+		if err := storeTx.SetSyncState(ctx, *d.SyncState); err != nil {
+			return errf("apply delta: %w", err)
+		}
+	}
+
+	if err := storeTx.RestoreBitwardenAccount(ctx, d.BitwardenAccount); err != nil {
+		return errf("apply delta: %w", err)
+	}
+
+	if err := storeTx.RestoreKeePassXCAssociations(ctx, d.KeePassXCAssociations); err != nil {
+		return errf("apply delta: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errf("apply delta: tx commit: %w", err)
+	}
+
+	return nil
+}
+
+// ChangesSince returns the number of secret changes made since the backup that captured state.
+func (vlt *Vault) ChangesSince(ctx context.Context, state *BackupState) (int, error) {
+	n, err := vlt.db.CountSecretChanges(ctx, state.AuditID)
+	if err != nil {
+		return 0, errf("changes since backup: %w", err)
+	}
+
+	return n, nil
+}
+
+// checkBackupBase verifies that the vault in the given state
+// descends from the snapshot that captured base.
+func (vlt *Vault) checkBackupBase(ctx context.Context, base *BackupState, state *BackupState) error {
+	if base.KeyID != state.KeyID {
+		return fmt.Errorf("%w: master key changed", vaulterrors.ErrBackupBaseMismatch)
+	}
+
+	if base.SchemaVersion != state.SchemaVersion {
+		return fmt.Errorf("%w: schema version changed", vaulterrors.ErrBackupBaseMismatch)
+	}
+
+	if base.AuditID == 0 {
+		return nil
+	}
+
+	hash, err := vlt.db.AuditEntryHash(ctx, base.AuditID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !bytes.Equal(hash, base.AuditHash)) {
+		return fmt.Errorf("%w: audit log diverged", vaulterrors.ErrBackupBaseMismatch)
+	}
+
+	return err
+}
+
+// BackupState returns the current state of the vault, as captured by backups.
+func (vlt *Vault) BackupState(ctx context.Context) (*BackupState, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return nil, errf("backup state: %w", err)
+	}
+
+	state, err := vlt.backupState(ctx)
+	if err != nil {
+		return nil, errf("backup state: %w", err)
+	}
+
+	return state, nil
+}
+
+// VerifyDecryption test-decrypts up to n randomly sampled secrets,
+// or all secrets if n is not positive, returning the number of secrets decrypted.
+func (vlt *Vault) VerifyDecryption(ctx context.Context, n int) (int, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return 0, errf("verify decryption: %w", err)
+	}
+
+	secrets, err := vlt.db.SampleSecrets(ctx, n)
+	if err != nil {
+		return 0, errf("verify decryption: %w", err)
+	}
+
+	for _, s := range secrets {
+		if _, err := vlt.aesgcm.Open(s.Nonce, s.Ciphertext); err != nil {
+			return 0, errf("verify decryption: secret %d: %w", s.ID, err)
+		}
+	}
+
+	return len(secrets), nil
+}
+
+func (vlt *Vault) backupState(ctx context.Context) (*BackupState, error) {
+	key, err := vlt.backupKey()
+	if err != nil {
+		return nil, err
+	}
+
+	keyID := sha256.Sum256(key)
+
+	state := &BackupState{KeyID: hex.EncodeToString(keyID[:8])}
+
+	if state.SchemaVersion, err = vlt.db.SchemaVersion(ctx); err != nil {
+		return nil, err
+	}
+
+	if state.AuditID, err = vlt.db.MaxAuditID(ctx); err != nil {
+		return nil, err
+	}
+
+	if state.AuditID > 0 {
+		if state.AuditHash, err = vlt.db.AuditEntryHash(ctx, state.AuditID); err != nil {
+			return nil, err
+		}
+	}
+
+	if state.OplogClock, err = vlt.db.OplogClock(ctx); err != nil {
+		return nil, err
+	}
+
+	if state.Rows, err = vlt.db.RowCounts(ctx); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (vlt *Vault) backupKey() ([]byte, error) {
+	return vlt.aesgcm.DeriveKey(backupKeyInfo, 32)
+}
+
+func (vlt *Vault) sealBackupDelta(d *backupDelta) ([]byte, error) {
+	plaintext, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := vlt.backupKey()
+	if err != nil {
+		return nil, err
+	}
+
+	aes, err := vaultcrypto.NewAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := vaultcrypto.RandBytes(aes.AEAD().NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	out := append([]byte(backupDeltaMagic), nonce...)
+
+	return aes.AEAD().Seal(out, nonce, plaintext, []byte(backupDeltaMagic)), nil
+}
+
+// openBackupDelta decrypts a delta sealed by [Vault.sealBackupDelta].
+func (vlt *Vault) openBackupDelta(data []byte) (*backupDelta, error) {
+	key, err := vlt.backupKey()
+	if err != nil {
+		return nil, err
+	}
+
+	aes, err := vaultcrypto.NewAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	rest, ok := bytes.CutPrefix(data, []byte(backupDeltaMagic))
+	if !ok || len(rest) < aes.AEAD().NonceSize() {
+		return nil, errors.New("not a backup delta")
+	}
+
+	nonce, ciphertext := rest[:aes.AEAD().NonceSize()], rest[aes.AEAD().NonceSize():]
+
+	plaintext, err := aes.AEAD().Open(nil, nonce, ciphertext, []byte(backupDeltaMagic))
+	if err != nil {
+		return nil, errors.New("delta is corrupted or was sealed using another key")
+	}
+
+	var d backupDelta
+	if err := json.Unmarshal(plaintext, &d); err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
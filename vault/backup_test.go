@@ -0,0 +1,134 @@
+package vault
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_Backup(t *testing.T) {
+	dir := t.TempDir()
+
+	v, err := New(t.Context(), filepath.Join(dir, "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = v.Close(t.Context()) })
+
+	var ids []int
+
+	for _, name := range []string{"unchanged", "updated", "deleted"} {
+		id, err := v.InsertNewSecret(t.Context(), name, "v1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	snapshot, base, err := v.Snapshot(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the snapshot is a vault file on its own.
+	path := filepath.Join(dir, "snapshot.db")
+	if err := os.WriteFile(path, snapshot, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := Open(t.Context(), path, WithPassword("password"), WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := secretsByName(t, restored); len(got) != 3 {
+		t.Errorf("snapshot: got %v, want 3 secrets", got)
+	}
+
+	_ = restored.Close(t.Context())
+
+	if _, err := v.UpdateSecret(t.Context(), ids[1], "v2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// chunked secrets are restored along with their chunks.
+	inserted, err := v.InsertNewSecret(t.Context(), "inserted", strings.Repeat("v1", secretChunkSize), []string{"x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.DeleteSecretsByIDs(t.Context(), ids[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	data, state, err := v.Delta(t.Context(), base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.AuditID <= base.AuditID {
+		t.Errorf("audit id: got %d, want more than %d", state.AuditID, base.AuditID)
+	}
+
+	d, err := v.openBackupDelta(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var changed []int
+	for _, s := range d.Secrets {
+		changed = append(changed, s.ID)
+	}
+
+	if want := []int{ids[1], inserted}; !slices.Equal(changed, want) {
+		t.Errorf("changed secrets: got %v, want %v", changed, want)
+	}
+
+	if want := []int{ids[2]}; !slices.Equal(d.Deleted, want) {
+		t.Errorf("deleted secrets: got %v, want %v", d.Deleted, want)
+	}
+
+	// applying the delta to the snapshot restores the current vault.
+	restored, err = Open(t.Context(), path, WithPassword("password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = restored.Close(t.Context()) })
+
+	if err := restored.ApplyDelta(t.Context(), data); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := secretsByName(t, restored), secretsByName(t, v); !maps.Equal(got, want) {
+		t.Errorf("restored secrets: got %v, want %v", got, want)
+	}
+
+	if _, err := restored.VerifyAuditLog(t.Context()); err != nil {
+		t.Errorf("restored audit log: %v", err)
+	}
+
+	if err := restored.ApplyDelta(t.Context(), data); !errors.Is(err, vaulterrors.ErrBackupBaseMismatch) {
+		t.Errorf("reapplied delta: got err %v, want %v", err, vaulterrors.ErrBackupBaseMismatch)
+	}
+
+	// a vault of another key does not descend from the snapshot.
+	other, err := New(t.Context(), filepath.Join(dir, "other.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = other.Close(t.Context()) })
+
+	if _, _, err := other.Delta(t.Context(), base); !errors.Is(err, vaulterrors.ErrBackupBaseMismatch) {
+		t.Errorf("delta of another vault: got err %v, want %v", err, vaulterrors.ErrBackupBaseMismatch)
+	}
+}
@@ -0,0 +1,111 @@
+package vault
+
+import (
+	"context"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/bitwarden"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+// bitwardenKeyInfo binds the Bitwarden user key derived from the vault key.
+const bitwardenKeyInfo = "vlt-bitwarden-user-key"
+
+// SetBitwardenAccount sets up the account Bitwarden clients log in to using the
+// given email and password, replacing the existing one, if any.
+//
+// The account user key is derived from the vault key, so that the server can
+// encrypt secrets for clients without storing it.
+func (vlt *Vault) SetBitwardenAccount(ctx context.Context, email string, password string, iterations int) error {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return errf("set bitwarden account: %w", err)
+	}
+
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("set bitwarden account: %w", err)
+	}
+
+	userKey, err := vlt.bitwardenUserKey()
+	if err != nil {
+		return errf("set bitwarden account: %w", err)
+	}
+
+	a, err := bitwarden.NewAccount(email, password, iterations, userKey)
+	if err != nil {
+		return errf("set bitwarden account: %w", err)
+	}
+
+	err = vlt.db.SetBitwardenAccount(ctx, vaultdb.BitwardenAccount{
+		Email:               a.Email,
+		KDFIterations:       a.KDFIterations,
+		PasswordSalt:        a.PasswordSalt,
+		PasswordHash:        a.PasswordHash,
+		ProtectedKey:        a.ProtectedKey,
+		PublicKey:           a.PublicKey,
+		ProtectedPrivateKey: a.ProtectedPrivateKey,
+		CreatedAt:           time.Now().UTC(),
+	})
+	if err != nil {
+		return errf("set bitwarden account: %w", err)
+	}
+
+	return nil
+}
+
+// BitwardenAccount returns the Bitwarden account and its user key,
+// or [sql.ErrNoRows] if no account was set up.
+func (vlt *Vault) BitwardenAccount(ctx context.Context) (bitwarden.Account, bitwarden.Key, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return bitwarden.Account{}, bitwarden.Key{}, errf("bitwarden account: %w", err)
+	}
+
+	a, err := vlt.db.BitwardenAccount(ctx)
+	if err != nil {
+		return bitwarden.Account{}, bitwarden.Key{}, errf("bitwarden account: %w", err)
+	}
+
+	userKey, err := vlt.bitwardenUserKey()
+	if err != nil {
+		return bitwarden.Account{}, bitwarden.Key{}, errf("bitwarden account: %w", err)
+	}
+
+	account := bitwarden.Account{
+		Email:               a.Email,
+		KDFIterations:       a.KDFIterations,
+		PasswordSalt:        a.PasswordSalt,
+		PasswordHash:        a.PasswordHash,
+		ProtectedKey:        a.ProtectedKey,
+		PublicKey:           a.PublicKey,
+		ProtectedPrivateKey: a.ProtectedPrivateKey,
+	}
+
+	return account, userKey, nil
+}
+
+// RemoveBitwardenAccount removes the Bitwarden account,
+// reporting whether one was set up.
+func (vlt *Vault) RemoveBitwardenAccount(ctx context.Context) (bool, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return false, errf("remove bitwarden account: %w", err)
+	}
+
+	if err := vlt.checkWritable(ctx); err != nil {
+		return false, errf("remove bitwarden account: %w", err)
+	}
+
+	n, err := vlt.db.DeleteBitwardenAccount(ctx)
+	if err != nil {
+		return false, errf("remove bitwarden account: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+func (vlt *Vault) bitwardenUserKey() (bitwarden.Key, error) {
+	b, err := vlt.aesgcm.DeriveKey(bitwardenKeyInfo, bitwarden.KeySize)
+	if err != nil {
+		return bitwarden.Key{}, err
+	}
+
+	return bitwarden.NewKey(b)
+}
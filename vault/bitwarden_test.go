@@ -0,0 +1,82 @@
+package vault
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/bitwarden"
+)
+
+func TestVault_BitwardenAccount(t *testing.T) {
+	v, err := New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	if _, _, err := v.BitwardenAccount(t.Context()); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("account before setup: got err %v, want %v", err, sql.ErrNoRows)
+	}
+
+	if err := v.SetBitwardenAccount(t.Context(), "me@example.com", "client-password", 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	a, userKey, err := v.BitwardenAccount(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	masterKey, err := bitwarden.MasterKey("client-password", "me@example.com", a.KDFIterations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := bitwarden.MasterPasswordHash(masterKey, "client-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !a.VerifyPassword(hash) {
+		t.Error("verify client password: got false, want true")
+	}
+
+	stretched, err := bitwarden.StretchMasterKey(masterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	protected, err := stretched.Decrypt(a.ProtectedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(protected, userKey.Bytes()) {
+		t.Error("protected key does not match the user key")
+	}
+
+	// the user key is derived from the vault key, it survives account resets.
+	if err := v.SetBitwardenAccount(t.Context(), "me@example.com", "other-password", 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	_, userKey2, err := v.BitwardenAccount(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(userKey.Bytes(), userKey2.Bytes()) {
+		t.Error("user key changed on account reset")
+	}
+
+	if ok, err := v.RemoveBitwardenAccount(t.Context()); err != nil || !ok {
+		t.Errorf("remove account: got (%t, %v), want (true, nil)", ok, err)
+	}
+
+	if _, _, err := v.BitwardenAccount(t.Context()); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("account after removal: got err %v, want %v", err, sql.ErrNoRows)
+	}
+}
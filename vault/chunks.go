@@ -0,0 +1,92 @@
+package vault
+
+import (
+	"context"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+const (
+	// MaxSecretSize is the size limit of secret values, in bytes.
+	MaxSecretSize = 16 << 20
+
+	// secretChunkSize is the size of the chunks of values too large to
+	// be stored inline, so that queries over the secrets table, e.g.,
+	// joined with their labels, do not hold multi-megabyte values.
+	secretChunkSize = 64 << 10
+)
+
+// checkSecretSize fails with [vaulterrors.ErrSecretTooLarge]
+// if the given value exceeds [MaxSecretSize].
+func checkSecretSize(value string) error {
+	if len(value) > MaxSecretSize {
+		return vaulterrors.ErrSecretTooLarge
+	}
+
+	return nil
+}
+
+// sealValue encrypts the given secret value. Values larger than a single
+// chunk are split into separately encrypted chunks, and the returned
+// ciphertext holds an empty value.
+func (vlt *Vault) sealValue(value string) (nonce []byte, ciphertext []byte, chunks []vaultdb.SecretChunk, _ error) {
+	if len(value) <= secretChunkSize {
+		nonce, ciphertext, err := vlt.sealSecret(value)
+		return nonce, ciphertext, nil, err
+	}
+
+	for seq := 0; len(value) > 0; seq++ {
+		n := min(len(value), secretChunkSize)
+
+		nonce, ciphertext, err := vlt.sealSecret(value[:n])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		chunks = append(chunks, vaultdb.SecretChunk{Seq: seq, Nonce: nonce, Ciphertext: ciphertext})
+		value = value[n:]
+	}
+
+	nonce, ciphertext, err := vlt.sealSecret("")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return nonce, ciphertext, chunks, nil
+}
+
+// openValue returns the decrypted value of the secret with the given id,
+// joining its chunks if chunked.
+func (vlt *Vault) openValue(ctx context.Context, store *vaultdb.VaultDB, id int) ([]byte, error) {
+	nonce, ciphertext, err := store.ShowSecret(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := store.SecretChunks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return vlt.openChunks(nonce, ciphertext, chunks)
+}
+
+// openChunks decrypts the given sealed value, joining its chunks if any.
+func (vlt *Vault) openChunks(nonce []byte, ciphertext []byte, chunks []vaultdb.SecretChunk) ([]byte, error) {
+	value, err := vlt.aesgcm.Open(nonce, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range chunks {
+		plaintext, err := vlt.aesgcm.Open(c.Nonce, c.Ciphertext)
+		if err != nil {
+			return nil, err
+		}
+
+		value = append(value, plaintext...)
+	}
+
+	return value, nil
+}
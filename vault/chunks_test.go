@@ -0,0 +1,147 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_ChunkedSecrets(t *testing.T) {
+	ctx := t.Context()
+
+	v, err := New(ctx, filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = v.Close(ctx) })
+
+	countChunks := func() (n int) {
+		t.Helper()
+
+		if err := v.conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM secret_chunks").Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+
+		return n
+	}
+
+	note := strings.Repeat("recovery code\n", 3*secretChunkSize/14+1)
+
+	id, err := v.InsertNewSecret(ctx, "note", note, []string{"type=note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := countChunks(), 4; got != want {
+		t.Errorf("chunks: got %d, want %d", got, want)
+	}
+
+	if got, err := v.ShowSecret(ctx, id); err != nil || got != note {
+		t.Errorf("ShowSecret() of chunked secret: got %d bytes, err %v, want %d bytes", len(got), err, len(note))
+	}
+
+	exported, err := v.ExportSecrets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := exported[id].Value; got != note {
+		t.Errorf("ExportSecrets() of chunked secret: got %d bytes, want %d bytes", len(got), len(note))
+	}
+
+	if err := v.updateIntegrity(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := v.verifyIntegrity(ctx); err != nil || !ok {
+		t.Errorf("verifyIntegrity() of chunked secret: got %v, %v, want true", ok, err)
+	}
+
+	if _, err := v.conn.ExecContext(ctx, "UPDATE secret_chunks SET ciphertext = randomblob(length(ciphertext)) WHERE seq = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := v.verifyIntegrity(ctx); err != nil || ok {
+		t.Errorf("verifyIntegrity() of tampered chunk: got %v, %v, want false", ok, err)
+	}
+
+	if _, err := v.UpdateSecret(ctx, id, "short"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := countChunks(); got != 0 {
+		t.Errorf("chunks after updating to an inline value: got %d, want 0", got)
+	}
+
+	if got, err := v.ShowSecret(ctx, id); err != nil || got != "short" {
+		t.Errorf("ShowSecret() after update: got %q, err %v", got, err)
+	}
+
+	if _, err := v.UpdateSecret(ctx, id, note); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.PurgeSecretsByIDs(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := countChunks(); got != 0 {
+		t.Errorf("chunks after delete: got %d, want 0", got)
+	}
+
+	large := strings.Repeat("x", MaxSecretSize+1)
+	if _, err := v.InsertNewSecret(ctx, "large", large, nil); !errors.Is(err, vaulterrors.ErrSecretTooLarge) {
+		t.Errorf("InsertNewSecret() exceeding the size limit: got err %v, want %v", err, vaulterrors.ErrSecretTooLarge)
+	}
+}
+
+func TestVault_WriteSecret(t *testing.T) {
+	ctx := t.Context()
+
+	v, err := New(ctx, filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = v.Close(ctx) })
+
+	for _, value := range []string{"secret", strings.Repeat("recovery code\n", 3*secretChunkSize/14+1)} {
+		id, err := v.InsertNewSecret(ctx, "name", value, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+
+		n, err := v.WriteSecret(ctx, id, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != int64(len(value)) || buf.String() != value {
+			t.Errorf("WriteSecret(): got %d bytes, wrote %d, want %d bytes", buf.Len(), n, len(value))
+		}
+
+		shown := 0
+
+		for e, err := range v.AuditLog(ctx, vaultdb.AuditFilters{Operations: []vaultdb.Operation{vaultdb.OpShow}}) {
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if e.SecretID == id {
+				shown++
+			}
+		}
+
+		if shown != 1 {
+			t.Errorf("got %d show audit entries, want 1", shown)
+		}
+	}
+}
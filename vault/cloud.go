@@ -0,0 +1,74 @@
+package vault
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"slices"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+// cloudKeyInfo binds the key of cloud secret digests derived from the vault key.
+const cloudKeyInfo = "vlt-cloud"
+
+// CloudSecrets returns the mappings of the accessible secrets to the
+// remote secrets of the given cloud provider, ordered by secret id.
+func (vlt *Vault) CloudSecrets(ctx context.Context, provider string) ([]vaultdb.CloudSecret, error) {
+	secrets, err := vlt.db.CloudSecrets(ctx, provider)
+	if err != nil {
+		return nil, errf("cloud secrets: %w", err)
+	}
+
+	allowed, err := vlt.allowed(ctx, vlt.db)
+	if err != nil {
+		return nil, errf("cloud secrets: %w", err)
+	}
+
+	if allowed != nil {
+		secrets = slices.DeleteFunc(secrets, func(c vaultdb.CloudSecret) bool {
+			_, ok := allowed[c.SecretID]
+			return !ok
+		})
+	}
+
+	return secrets, nil
+}
+
+// SetCloudSecret records that the given secret was synced with a remote secret.
+//
+// Mappings are bookkeeping, not part of the secret history, they are
+// recorded without audit log or oplog entries.
+func (vlt *Vault) SetCloudSecret(ctx context.Context, c vaultdb.CloudSecret) error {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("set cloud secret: %w", err)
+	}
+
+	if err := vlt.checkScope(ctx, vlt.db, c.SecretID); err != nil {
+		return errf("set cloud secret: %w", err)
+	}
+
+	if err := vlt.db.SetCloudSecret(ctx, c); err != nil {
+		return errf("set cloud secret: %w", err)
+	}
+
+	return nil
+}
+
+// SecretDigest returns a digest of the given secret value and labels, keyed by
+// the vault key, used to detect changes to synced secrets without storing their values.
+func (vlt *Vault) SecretDigest(value string, labels []string) ([]byte, error) {
+	key, err := vlt.aesgcm.DeriveKey(cloudKeyInfo, sha256.Size)
+	if err != nil {
+		return nil, errf("secret digest: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	writeField(mac, []byte(value))
+
+	for _, l := range slices.Compact(slices.Sorted(slices.Values(labels))) {
+		writeField(mac, []byte(l))
+	}
+
+	return mac.Sum(nil), nil
+}
@@ -0,0 +1,13 @@
+CREATE TABLE
+    IF NOT EXISTS audit_log (
+        id INTEGER PRIMARY KEY,
+        -- The vault operation performed, e.g. 'insert', 'show', 'delete'.
+        operation TEXT NOT NULL,
+        -- The affected secret, if any.
+        -- Not a foreign key, entries must outlive the secrets they refer to.
+        secret_id INTEGER DEFAULT NULL,
+        secret_name TEXT DEFAULT NULL,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at);
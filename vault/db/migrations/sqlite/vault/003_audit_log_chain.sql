@@ -0,0 +1,19 @@
+-- Tamper-evident hash chain over the audit log.
+-- Each entry hash covers the previous entry hash and the entry fields.
+ALTER TABLE audit_log ADD COLUMN prev_hash BLOB DEFAULT NULL;
+
+ALTER TABLE audit_log ADD COLUMN hash BLOB DEFAULT NULL;
+
+CREATE TABLE
+    IF NOT EXISTS audit_checkpoints (
+        id INTEGER PRIMARY KEY,
+        -- The audit log entry the checkpoint was taken at.
+        entry_id INTEGER NOT NULL,
+        -- The chain hash of the entry at the time of the checkpoint.
+        hash BLOB NOT NULL,
+        -- The key identifier used to sign the checkpoint.
+        signer TEXT NOT NULL,
+        -- Detached signature over the checkpoint payload.
+        signature BLOB NOT NULL,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
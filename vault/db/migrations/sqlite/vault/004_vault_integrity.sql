@@ -0,0 +1,8 @@
+-- vault_integrity holds a single MAC over the logical vault contents,
+-- refreshed whenever the vault is sealed and verified on open.
+CREATE TABLE
+    IF NOT EXISTS vault_integrity (
+        id INTEGER PRIMARY KEY CHECK (id = 0),
+        mac BLOB NOT NULL,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
@@ -0,0 +1,16 @@
+-- The actor that performed the operation, e.g. an access token.
+-- NULL for operations performed by the vault owner.
+ALTER TABLE audit_log ADD COLUMN actor TEXT DEFAULT NULL;
+
+CREATE TABLE
+    IF NOT EXISTS api_tokens (
+        id TEXT PRIMARY KEY,
+        name TEXT NOT NULL,
+        -- SHA-256 of the token secret, the secret itself is never stored.
+        hash BLOB NOT NULL,
+        -- JSON array of label globs the token is scoped to.
+        scope TEXT NOT NULL,
+        read_only INTEGER NOT NULL,
+        expires_at TIMESTAMP NOT NULL,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
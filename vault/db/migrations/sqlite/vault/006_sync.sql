@@ -0,0 +1,64 @@
+-- uid identifies a secret across synced devices.
+ALTER TABLE secrets ADD COLUMN uid TEXT DEFAULT NULL;
+
+-- the update timestamp tracks secret changes only,
+-- so that assigning uids does not affect it.
+DROP TRIGGER IF EXISTS update_secrets_updated_at;
+
+CREATE TRIGGER IF NOT EXISTS update_secrets_updated_at AFTER
+UPDATE OF name, nonce, ciphertext ON secrets FOR EACH ROW BEGIN
+UPDATE secrets
+SET
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    id = OLD.id;
+
+END;
+
+UPDATE secrets
+SET
+    uid = lower(hex(randomblob(16)))
+WHERE
+    uid IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS secrets_uid ON secrets (uid);
+
+CREATE TRIGGER IF NOT EXISTS insert_secrets_uid AFTER INSERT ON secrets FOR EACH ROW
+WHEN NEW.uid IS NULL BEGIN
+UPDATE secrets
+SET
+    uid = lower(hex(randomblob(16)))
+WHERE
+    id = NEW.id;
+
+END;
+
+-- sync_state holds the sync configuration of this device.
+-- It has at most one row, sync is disabled if it is empty.
+CREATE TABLE
+    IF NOT EXISTS sync_state (
+        id INTEGER PRIMARY KEY CHECK (id = 0),
+        -- device identifies this device in the oplog.
+        device TEXT NOT NULL,
+        -- key seals oplog payloads, it is shared by all synced devices.
+        key BLOB NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+-- oplog is the append-only log of secret mutations of all synced devices.
+CREATE TABLE
+    IF NOT EXISTS oplog (
+        device TEXT NOT NULL,
+        seq INTEGER NOT NULL,
+        -- JSON object mapping device ids to sequence numbers.
+        clock TEXT NOT NULL,
+        secret_uid TEXT NOT NULL,
+        operation TEXT NOT NULL,
+        nonce BLOB NOT NULL,
+        -- sealed using the sync key.
+        payload BLOB NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        PRIMARY KEY (device, seq)
+    );
+
+CREATE INDEX IF NOT EXISTS oplog_secret_uid ON oplog (secret_uid);
@@ -0,0 +1,12 @@
+-- links marks secrets whose values are resolved from an external provider,
+-- the secret value caches the latest resolved value.
+CREATE TABLE
+    IF NOT EXISTS links (
+        secret_id INTEGER PRIMARY KEY REFERENCES secrets (id) ON DELETE CASCADE,
+        -- the provider reference, e.g., 'hv://secret/data/db#password'.
+        uri TEXT NOT NULL,
+        -- how long the cached value is used before resolving the link again, in seconds.
+        ttl INTEGER NOT NULL,
+        -- the time the link was last resolved, NULL if never.
+        resolved_at TIMESTAMP DEFAULT NULL
+    );
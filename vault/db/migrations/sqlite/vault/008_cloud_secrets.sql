@@ -0,0 +1,14 @@
+-- cloud_secrets maps secrets to the cloud secret manager secrets they were
+-- pushed to or pulled from, to detect drift since they were last synced.
+CREATE TABLE
+    IF NOT EXISTS cloud_secrets (
+        secret_id INTEGER NOT NULL REFERENCES secrets (id) ON DELETE CASCADE,
+        -- the cloud provider, e.g., 'aws'.
+        provider TEXT NOT NULL,
+        remote_name TEXT NOT NULL,
+        -- keyed digest of the secret value and labels last synced.
+        digest BLOB NOT NULL,
+        synced_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (secret_id, provider),
+        UNIQUE (provider, remote_name)
+    );
@@ -0,0 +1,17 @@
+-- bitwarden_account holds the single account Bitwarden clients log in to
+-- when the vault is served in Bitwarden compatibility mode.
+CREATE TABLE
+    IF NOT EXISTS bitwarden_account (
+        id INTEGER PRIMARY KEY CHECK (id = 0),
+        email TEXT NOT NULL,
+        kdf_iterations INTEGER NOT NULL,
+        -- salted hash of the master password hash sent by clients.
+        password_salt BLOB NOT NULL,
+        password_hash BLOB NOT NULL,
+        -- the user key, encrypted by the client master key.
+        protected_key TEXT NOT NULL,
+        public_key TEXT NOT NULL,
+        -- the private key, encrypted by the user key.
+        protected_private_key TEXT NOT NULL,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
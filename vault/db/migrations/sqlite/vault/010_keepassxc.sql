@@ -0,0 +1,12 @@
+-- keepassxc_associations holds the KeePassXC-Browser extensions
+-- associated with the vault.
+CREATE TABLE
+    IF NOT EXISTS keepassxc_associations (
+        id TEXT PRIMARY KEY,
+        -- the extension identity public key, NULL while pairing.
+        id_key TEXT DEFAULT NULL,
+        -- the time pairing expires at, an extension associating before then
+        -- takes over the association.
+        pairing_expires_at TIMESTAMP DEFAULT NULL,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
@@ -0,0 +1,13 @@
+-- secret_chunks holds the values of secrets too large to be stored inline,
+-- e.g., notes, split into separately encrypted chunks.
+-- The ciphertext of chunked secrets holds an empty value.
+CREATE TABLE
+    IF NOT EXISTS secret_chunks (
+        secret_id INTEGER NOT NULL REFERENCES secrets (id) ON DELETE CASCADE,
+        -- the position of the chunk within the value, starting at 0.
+        seq INTEGER NOT NULL,
+        ciphertext BLOB NOT NULL,
+        -- 96-bit (12-byte) nonce used for AES-GCM encryption, generated randomly per chunk.
+        nonce BLOB NOT NULL,
+        PRIMARY KEY (secret_id, seq)
+    );
@@ -0,0 +1,10 @@
+-- label_meta holds the optional metadata of labels, shared by all the secrets
+-- having the label. Labels are stored per secret in the labels table, and are
+-- described here by name, whether or not any secret has them.
+CREATE TABLE
+    IF NOT EXISTS label_meta (
+        name TEXT PRIMARY KEY,
+        description TEXT NOT NULL DEFAULT '',
+        -- the color label badges are shown in, empty for none.
+        color TEXT NOT NULL DEFAULT ''
+    );
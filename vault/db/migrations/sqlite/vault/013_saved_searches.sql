@@ -0,0 +1,8 @@
+-- saved_searches holds named search queries, e.g.,
+-- 'label:work/* -label:archive modified:>90d', run by 'vlt search run'.
+CREATE TABLE
+    IF NOT EXISTS saved_searches (
+        name TEXT PRIMARY KEY,
+        query TEXT NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
@@ -0,0 +1,10 @@
+-- secret_access holds the times secret values were last accessed, ranking
+-- secrets in pickers by frecency. Only the most recent accesses of each secret
+-- are kept, older ones are pruned as new ones are recorded.
+CREATE TABLE
+    IF NOT EXISTS secret_access (
+        secret_id INTEGER NOT NULL REFERENCES secrets (id) ON DELETE CASCADE,
+        accessed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+CREATE INDEX IF NOT EXISTS secret_access_secret_id ON secret_access (secret_id);
@@ -0,0 +1,10 @@
+-- rotation_schedule holds the rotation schedules of secrets, run by
+-- 'vlt scheduler run'. Secrets are also scheduled by label in the config.
+CREATE TABLE
+    IF NOT EXISTS rotation_schedule (
+        secret_id INTEGER PRIMARY KEY REFERENCES secrets (id) ON DELETE CASCADE,
+        -- how often the secret is rotated, in seconds, 0 if scheduled by label.
+        every INTEGER NOT NULL DEFAULT 0,
+        -- the time the next rotation is due, NULL if due by the interval only.
+        due_at TIMESTAMP DEFAULT NULL
+    );
@@ -0,0 +1,6 @@
+-- labels.secret_id is declared TEXT, so joins compare it against the text
+-- form of the secret id to look labels up by this index, see 'joinLabels'.
+-- Label names are already indexed by the UNIQUE (name, secret_id) constraint.
+CREATE INDEX IF NOT EXISTS labels_secret_id ON labels (secret_id);
+
+CREATE INDEX IF NOT EXISTS secrets_name ON secrets (name);
@@ -0,0 +1,20 @@
+-- structured metadata of secrets, alongside their names and labels.
+-- Empty strings stand for unset fields.
+ALTER TABLE secrets ADD COLUMN url TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE secrets ADD COLUMN username TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE secrets ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+
+-- metadata changes are secret changes, see '006_sync'.
+DROP TRIGGER IF EXISTS update_secrets_updated_at;
+
+CREATE TRIGGER IF NOT EXISTS update_secrets_updated_at AFTER
+UPDATE OF name, nonce, ciphertext, url, username, notes ON secrets FOR EACH ROW BEGIN
+UPDATE secrets
+SET
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    id = OLD.id;
+
+END;
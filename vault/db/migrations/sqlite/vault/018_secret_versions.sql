@@ -0,0 +1,15 @@
+-- secret_versions holds the previous values of secrets, one row per value
+-- replaced, listed and restored using 'vlt history'.
+-- Values are sealed inline, even if the secret value was chunked.
+CREATE TABLE
+    IF NOT EXISTS secret_versions (
+        secret_id INTEGER NOT NULL REFERENCES secrets (id) ON DELETE CASCADE,
+        -- the version number of the value, starting at 1 for the first value replaced.
+        version INTEGER NOT NULL,
+        ciphertext BLOB NOT NULL,
+        -- 96-bit (12-byte) nonce used for AES-GCM encryption.
+        nonce BLOB NOT NULL,
+        -- the time the value was replaced.
+        replaced_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (secret_id, version)
+    );
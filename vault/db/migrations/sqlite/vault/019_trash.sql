@@ -0,0 +1,4 @@
+-- deleted secrets are kept in the trash until purged, see 'vlt trash'.
+-- Trashed secrets are hidden from all queries but those of the trash,
+-- and the vault integrity check.
+ALTER TABLE secrets ADD COLUMN deleted_at TIMESTAMP DEFAULT NULL;
@@ -0,0 +1,16 @@
+-- the kind of secrets with a dedicated format, e.g., 'totp' seeds.
+-- Empty strings stand for plain secrets.
+ALTER TABLE secrets ADD COLUMN kind TEXT NOT NULL DEFAULT '';
+
+-- kind changes are secret changes, see '017_secret_meta'.
+DROP TRIGGER IF EXISTS update_secrets_updated_at;
+
+CREATE TRIGGER IF NOT EXISTS update_secrets_updated_at AFTER
+UPDATE OF name, nonce, ciphertext, url, username, notes, kind ON secrets FOR EACH ROW BEGIN
+UPDATE secrets
+SET
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    id = OLD.id;
+
+END;
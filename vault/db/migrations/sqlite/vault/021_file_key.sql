@@ -0,0 +1,9 @@
+-- file_key holds the key material of standalone files encrypted using 'vlt encrypt'.
+-- It has at most one row: the key is derived from the vault key until the master
+-- password is rotated, when the derived key is kept here, so that files encrypted
+-- before the rotation can still be decrypted.
+CREATE TABLE
+    IF NOT EXISTS file_key (
+        id INTEGER PRIMARY KEY CHECK (id = 0),
+        key BLOB NOT NULL
+    );
@@ -0,0 +1,15 @@
+CREATE TABLE
+    IF NOT EXISTS vault_tokens (
+        -- public token identifier, embedded in the token string.
+        id TEXT PRIMARY KEY,
+        -- JSON array of label globs, informational.
+        -- the authoritative scope is part of the sealed payload.
+        scope TEXT NOT NULL,
+        read_only INTEGER NOT NULL,
+        expires_at TEXT NOT NULL,
+        -- the vault key and nonce along with the token scope,
+        -- sealed using a key derived from the token secret.
+        nonce BLOB NOT NULL,
+        sealed BLOB NOT NULL,
+        created_at TEXT NOT NULL DEFAULT (datetime ('now'))
+    );
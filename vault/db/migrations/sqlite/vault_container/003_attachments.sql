@@ -0,0 +1,29 @@
+-- attachments holds the files attached to secrets. They are stored outside
+-- of the encrypted vault, which is loaded entirely into memory, so that they
+-- can be streamed in separately encrypted chunks.
+CREATE TABLE
+    IF NOT EXISTS attachments (
+        id INTEGER PRIMARY KEY,
+        -- the id of the secret within the vault.
+        secret_id INTEGER NOT NULL,
+        -- the attachment name, encrypted using AES-GCM.
+        name_ciphertext BLOB NOT NULL,
+        name_nonce BLOB NOT NULL,
+        -- the plaintext size in bytes, set once all chunks are stored.
+        size INTEGER NOT NULL DEFAULT 0,
+        created_at TEXT NOT NULL DEFAULT (datetime ('now'))
+    );
+
+CREATE INDEX IF NOT EXISTS attachments_secret_id_idx ON attachments (secret_id);
+
+CREATE TABLE
+    IF NOT EXISTS attachment_chunks (
+        attachment_id INTEGER NOT NULL REFERENCES attachments (id) ON DELETE CASCADE,
+        -- the position of the chunk within the file, starting at 0.
+        seq INTEGER NOT NULL,
+        -- encrypted using AES-GCM, authenticating the attachment id, the
+        -- position of the chunk, and whether it is the last one.
+        ciphertext BLOB NOT NULL,
+        nonce BLOB NOT NULL,
+        PRIMARY KEY (attachment_id, seq)
+    );
@@ -0,0 +1,177 @@
+package vault
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVault_PurgeSecretsByIDs_Shred(t *testing.T) {
+	v, err := New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = v.Close(t.Context()) })
+
+	id, err := v.InsertNewSecret(t.Context(), "name", "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, ciphertext, err := v.db.ShowSecret(t.Context(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := Serialize(v.conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(before, ciphertext) {
+		t.Fatal("ciphertext not found in the serialized vault before deletion")
+	}
+
+	if _, err := v.PurgeSecretsByIDs(t.Context(), id); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := Serialize(v.conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(after, ciphertext) {
+		t.Error("ciphertext found in the serialized vault after deletion")
+	}
+}
+
+// TestVault_Open_SecureDelete checks that deleted content is zeroed out in
+// vaults that are opened, as deserializing the vault resets the pragma.
+func TestVault_Open_SecureDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err = Open(t.Context(), path, WithPassword("password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = v.Close(t.Context()) })
+
+	var secureDelete int
+	if err := v.conn.QueryRowContext(t.Context(), "PRAGMA secure_delete").Scan(&secureDelete); err != nil {
+		t.Fatal(err)
+	}
+
+	if secureDelete != 1 {
+		t.Errorf("secure_delete: got %d, want 1", secureDelete)
+	}
+}
+
+func TestVault_PurgeSecretsByIDs_ShredHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := v.InsertNewSecret(t.Context(), "name", "secret", nil)
+	if err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	_, ciphertext, err := v.db.ShowSecret(t.Context(), id)
+	if err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	if err := v.Sync(t.Context()); err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	sealed, err := v.vaultContainerHandle.db.SelectVault(t.Context())
+	if err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	if _, err := v.PurgeSecretsByIDs(t.Context(), id); err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	// the sealed vault holding the purged secret is the previous version once closed.
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	var current []byte
+	if err := db.QueryRowContext(t.Context(), "SELECT vault_encrypted FROM vault_container").Scan(&current); err != nil {
+		t.Fatal(err)
+	}
+
+	// chunks of the vault sealed before the purge, that are not shared with the current one,
+	// must not be left anywhere in the file, e.g., in free pages.
+	const chunk = 256
+
+	for i := 0; i+chunk <= len(sealed.Vault); i += chunk {
+		if c := sealed.Vault[i : i+chunk]; !bytes.Contains(current, c) && bytes.Contains(raw, c) {
+			t.Fatalf("vault sealed before the purge found in the vault container file at offset %d", i)
+		}
+	}
+
+	rows, err := db.QueryContext(t.Context(), "SELECT snapshot FROM vault_history UNION ALL SELECT vault_encrypted FROM vault_container")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = rows.Close() })
+
+	for rows.Next() {
+		var snapshot []byte
+		if err := rows.Scan(&snapshot); err != nil {
+			t.Fatal(err)
+		}
+
+		plaintext, err := v.aesgcm.Open(v.nonce, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if bytes.Contains(plaintext, ciphertext) {
+			t.Error("purged secret found in a vault kept by the vault container")
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
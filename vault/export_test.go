@@ -0,0 +1,88 @@
+package vault
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+func TestVault_StreamSecrets(t *testing.T) {
+	ctx := t.Context()
+
+	v, err := New(ctx, filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = v.Close(ctx) })
+
+	note := strings.Repeat("recovery code\n", 2*secretChunkSize/14+1)
+
+	inserted := []ExportedSecret{
+		{Name: "db", Value: "hunter2", Labels: []string{"prod", "db"}},
+		{Name: "note", Value: note, Labels: []string{"type=note"}},
+		{Name: "bare", Value: "secret"},
+	}
+
+	for i, s := range inserted {
+		id, err := v.InsertNewSecret(ctx, s.Name, s.Value, s.Labels)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		inserted[i].ID = id
+		slices.Sort(inserted[i].Labels)
+	}
+
+	exports := func() (n int) {
+		t.Helper()
+
+		filters := vaultdb.AuditFilters{Operations: []vaultdb.Operation{vaultdb.OpExport}}
+
+		for _, err := range v.AuditLog(ctx, filters) {
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			n++
+		}
+
+		return n
+	}
+
+	var got []ExportedSecret
+
+	for s, err := range v.StreamSecrets(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got = append(got, s)
+	}
+
+	if !slices.EqualFunc(got, inserted, func(a, b ExportedSecret) bool {
+		return a.ID == b.ID && a.Name == b.Name && a.Value == b.Value && slices.Equal(a.Labels, b.Labels)
+	}) {
+		t.Errorf("StreamSecrets(): got %d secrets, want %d secrets in id order", len(got), len(inserted))
+	}
+
+	if got, want := exports(), 1; got != want {
+		t.Errorf("export audit entries: got %d, want %d", got, want)
+	}
+
+	// an export stopped early is not audited.
+	for _, err := range v.StreamSecrets(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		break
+	}
+
+	if got, want := exports(), 1; got != want {
+		t.Errorf("export audit entries after early stop: got %d, want %d", got, want)
+	}
+}
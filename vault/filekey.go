@@ -0,0 +1,45 @@
+package vault
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+// fileKeyInfo binds the key material of standalone encrypted files derived from the vault key.
+const fileKeyInfo = "vlt-file"
+
+// FileKey returns the key material of standalone files encrypted using the
+// vault key, e.g., by 'vlt encrypt'. Files encrypted using it can be decrypted
+// as long as the vault exists, and only using it.
+//
+// The key material is derived from the vault key, until the master password
+// is rotated, see [Vault.RotateMasterPassword], which keeps it in the vault.
+func (vlt *Vault) FileKey(ctx context.Context) ([]byte, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return nil, errf("file key: %w", err)
+	}
+
+	key, err := vlt.fileKey(ctx, vlt.db)
+	if err != nil {
+		return nil, errf("file key: %w", err)
+	}
+
+	return key, nil
+}
+
+// fileKey returns the stored file key, or else the one derived from the vault key.
+func (vlt *Vault) fileKey(ctx context.Context, store *vaultdb.VaultDB) ([]byte, error) {
+	key, err := store.FileKey(ctx)
+	if err == nil {
+		return key, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return vlt.aesgcm.DeriveKey(fileKeyInfo, 32)
+}
@@ -0,0 +1,128 @@
+package vault
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+func TestVault_FilterSecrets(t *testing.T) {
+	v := newFilterTestVault(t)
+
+	tests := []struct {
+		name    string
+		filters vaultdb.Filters
+		want    map[string][]string // secret name to returned labels
+	}{
+		{
+			name:    "label prefix",
+			filters: vaultdb.Filters{Labels: []string{"prod/db/*"}},
+			want:    map[string][]string{"pg-1": {"prod/db/1"}, "pg-2": {"prod/db/2"}},
+		},
+		{
+			name:    "name prefix",
+			filters: vaultdb.Filters{Name: "pg-1*"},
+			want:    map[string][]string{"pg-1": {"prod", "prod/db/1"}},
+		},
+		{
+			name:    "wildcard matching a name or a label",
+			filters: vaultdb.Filters{Wildcard: "dev*"},
+			want:    map[string][]string{"dev-key": {"ssh"}, "pg-3": {"dev/db/3"}},
+		},
+		{
+			name:    "unlabeled",
+			filters: vaultdb.Filters{Name: "bare"},
+			want:    map[string][]string{"bare": nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secrets, err := v.db.FilterSecrets(t.Context(), tt.filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string][]string, len(secrets))
+			for _, s := range secrets {
+				got[s.Name] = slices.Sorted(slices.Values(s.Labels))
+			}
+
+			if !maps.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestVault_FilterSecretsPlan guards against listing regressing into full
+// scans: labels are looked up by secret, and glob patterns having a literal
+// prefix search by range.
+func TestVault_FilterSecretsPlan(t *testing.T) {
+	v := newFilterTestVault(t)
+
+	tests := []struct {
+		name    string
+		filters vaultdb.Filters
+		scans   bool // scans reports whether secrets are expected to be scanned.
+	}{
+		{name: "all", filters: vaultdb.Filters{}, scans: true},
+		{name: "label prefix", filters: vaultdb.Filters{Labels: []string{"prod/*"}}},
+		{name: "labels prefix", filters: vaultdb.Filters{Labels: []string{"prod/*", "dev/*"}}},
+		{name: "name prefix", filters: vaultdb.Filters{Name: "pg-*"}},
+		{name: "wildcard prefix", filters: vaultdb.Filters{Wildcard: "pg-*"}},
+		{name: "name and label prefix", filters: vaultdb.Filters{Name: "pg-*", Labels: []string{"prod/*"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := v.db.FilterSecretsPlan(t.Context(), tt.filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, step := range plan {
+				if strings.HasPrefix(step, "SCAN l") || strings.HasPrefix(step, "SCAN labels") {
+					t.Errorf("labels scanned: %q", plan)
+				}
+
+				if !tt.scans && (strings.HasPrefix(step, "SCAN s") || strings.HasPrefix(step, "SCAN secrets")) {
+					t.Errorf("secrets scanned: %q", plan)
+				}
+			}
+		})
+	}
+}
+
+func newFilterTestVault(t *testing.T) *Vault {
+	t.Helper()
+
+	v, err := New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = v.Close(t.Context()) })
+
+	secrets := []struct {
+		name   string
+		labels []string
+	}{
+		{"pg-1", []string{"prod", "prod/db/1"}},
+		{"pg-2", []string{"prod/db/2"}},
+		{"pg-3", []string{"dev/db/3"}},
+		{"dev-key", []string{"ssh"}},
+		{"bare", nil},
+	}
+
+	for _, s := range secrets {
+		if _, err := v.InsertNewSecret(t.Context(), s.name, "secret", s.labels); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,65 @@
+package vault
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// frecencyAccesses is the number of most recent accesses of each secret
+// kept for scoring.
+const frecencyAccesses = 10
+
+// Frecency returns the frecency scores of the accessed secrets, keyed by
+// secret id, as of now. Each recorded access of a secret value adds up to its
+// score, decaying by half each halfLife, so secrets accessed both often and
+// recently rank first.
+//
+// Scores are only ever used for ranking, their scale is not meaningful.
+func (vlt *Vault) Frecency(ctx context.Context, now time.Time, halfLife time.Duration) (map[int]float64, error) {
+	accesses, err := vlt.db.SecretAccesses(ctx)
+	if err != nil {
+		return nil, errf("frecency: %w", err)
+	}
+
+	accesses, err = scoped(ctx, vlt, accesses)
+	if err != nil {
+		return nil, errf("frecency: %w", err)
+	}
+
+	scores := make(map[int]float64, len(accesses))
+
+	for id, times := range accesses {
+		for _, t := range times {
+			age := max(now.Sub(t), 0)
+			scores[id] += math.Exp2(-float64(age) / float64(halfLife))
+		}
+	}
+
+	return scores, nil
+}
+
+// ResetFrecency forgets the recorded accesses of the given secrets,
+// or of all secrets if none are given.
+//
+// Accesses are not part of the oplog, and as such are not synced.
+func (vlt *Vault) ResetFrecency(ctx context.Context, ids ...int) error {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("reset frecency: %w", err)
+	}
+
+	check := vlt.checkUnscoped(ctx)
+	if len(ids) > 0 {
+		check = vlt.checkScope(ctx, vlt.db, ids...)
+	}
+
+	if check != nil {
+		return errf("reset frecency: %w", check)
+	}
+
+	if _, err := vlt.db.DeleteSecretAccesses(ctx, ids); err != nil {
+		return errf("reset frecency: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_Frecency(t *testing.T) {
+	v, err := New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	ids := make(map[string]int)
+
+	for _, name := range []string{"vpn", "db", "idle"} {
+		id, err := v.InsertNewSecret(t.Context(), name, "value", []string{"work/" + name})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids[name] = id
+	}
+
+	show := func(name string, times int) {
+		t.Helper()
+
+		for range times {
+			if _, err := v.ShowSecret(t.Context(), ids[name]); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	show("vpn", 3)
+	show("db", 1)
+
+	halfLife := 24 * time.Hour
+	now := time.Now()
+
+	scores, err := v.Frecency(t.Context(), now, halfLife)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := scores[ids["idle"]]; ok || scores[ids["vpn"]] <= scores[ids["db"]] {
+		t.Fatalf("scores: got %v, want vpn above db, idle unscored", scores)
+	}
+
+	// scores halve every half-life.
+	later, err := v.Frecency(t.Context(), now.Add(halfLife), halfLife)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := later[ids["vpn"]], scores[ids["vpn"]]/2; got < want*0.99 || got > want*1.01 {
+		t.Errorf("decayed vpn score: got %v, want %v", got, want)
+	}
+
+	// only the most recent accesses are kept.
+	show("db", frecencyAccesses+5)
+
+	accesses, err := v.db.SecretAccesses(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(accesses[ids["db"]]); n != frecencyAccesses {
+		t.Errorf("kept db accesses: got %d, want %d", n, frecencyAccesses)
+	}
+
+	scoped := WithPrincipal(t.Context(), Principal{Scope: []string{"work/db"}})
+
+	scores, err = v.Frecency(scoped, now, halfLife)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := scores[ids["vpn"]]; ok || len(scores) != 1 {
+		t.Errorf("scoped scores: got %v, want db only", scores)
+	}
+
+	if err := v.ResetFrecency(scoped); !errors.Is(err, vaulterrors.ErrTokenScope) {
+		t.Errorf("scoped reset all: got err %v, want %v", err, vaulterrors.ErrTokenScope)
+	}
+
+	if err := v.ResetFrecency(scoped, ids["vpn"]); !errors.Is(err, vaulterrors.ErrTokenScope) {
+		t.Errorf("scoped reset of an out of scope secret: got err %v, want %v", err, vaulterrors.ErrTokenScope)
+	}
+
+	if err := v.ResetFrecency(t.Context(), ids["vpn"]); err != nil {
+		t.Fatal(err)
+	}
+
+	scores, err = v.Frecency(t.Context(), now, halfLife)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := scores[ids["vpn"]]; ok || len(scores) != 1 {
+		t.Errorf("scores after resetting vpn: got %v, want db only", scores)
+	}
+
+	if err := v.ResetFrecency(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if scores, err := v.Frecency(t.Context(), now, halfLife); err != nil || len(scores) != 0 {
+		t.Errorf("scores after reset: got %v, %v, want none", scores, err)
+	}
+
+	// accesses of purged secrets are deleted along with them.
+	show("db", 1)
+
+	if _, err := v.PurgeSecretsByIDs(t.Context(), ids["db"]); err != nil {
+		t.Fatal(err)
+	}
+
+	if accesses, err := v.db.SecretAccesses(t.Context()); err != nil || len(accesses) != 0 {
+		t.Errorf("accesses after delete: got %v, %v, want none", accesses, err)
+	}
+}
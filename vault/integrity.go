@@ -0,0 +1,224 @@
+package vault
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+const (
+	// integrityMACVersion prefixes the vault integrity MAC input.
+	integrityMACVersion = "vlt-integrity-v1"
+
+	// integrityKeyInfo binds the integrity MAC key derived from the vault key.
+	integrityKeyInfo = "vlt-integrity-mac"
+
+	// integrityMetaField precedes the metadata fields of a secret in its MAC input.
+	integrityMetaField = "meta"
+
+	// integrityVersionsField precedes the previous values of a secret in its MAC input.
+	integrityVersionsField = "versions"
+
+	// integrityTrashField precedes the time a secret was moved to the trash in its MAC input.
+	integrityTrashField = "trash"
+
+	// integrityKindField precedes the kind of a secret in its MAC input.
+	integrityKindField = "kind"
+)
+
+// integrityMAC computes a MAC over the logical vault contents:
+// the schema version, the number of secrets, and a per-secret MAC
+// covering its name, labels, nonce and ciphertext, its chunks if chunked,
+// its metadata if set, its previous values if any, and the time it was
+// moved to the trash if trashed, and its kind if not a plain secret.
+func (vlt *Vault) integrityMAC(ctx context.Context) ([]byte, error) {
+	key, err := vlt.aesgcm.DeriveKey(integrityKeyInfo, sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := vlt.db.SchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	chunked, err := vlt.db.ChunkedSecretIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	metas, err := vlt.db.SecretsWithMeta(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds, err := vlt.db.SecretKinds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versioned, err := vlt.db.VersionedSecretIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trashed, err := vlt.db.TrashedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		count   uint64
+		rowMACs [][]byte
+	)
+
+	for row, err := range vlt.db.IntegrityRows(ctx) {
+		if err != nil {
+			return nil, err
+		}
+
+		m := hmac.New(sha256.New, key)
+
+		//nolint:gosec // ids are non-negative.
+		writeField(m, binary.BigEndian.AppendUint64(nil, uint64(row.ID)))
+		writeField(m, []byte(row.Name))
+		writeField(m, row.Nonce)
+		writeField(m, row.Ciphertext)
+
+		for _, l := range row.Labels {
+			writeField(m, []byte(l))
+		}
+
+		// chunks are only covered if present, keeping the MAC of vaults without any.
+		if chunked[row.ID] {
+			if err := writeChunks(ctx, m, vlt.db, row.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		// as are metadata fields, see [vaultdb.SecretMeta].
+		if meta, ok := metas[row.ID]; ok {
+			writeField(m, []byte(integrityMetaField))
+			writeField(m, []byte(meta.URL))
+			writeField(m, []byte(meta.Username))
+			writeField(m, []byte(meta.Notes))
+		}
+
+		// and previous values, see [Vault.SecretHistory].
+		if versioned[row.ID] {
+			writeField(m, []byte(integrityVersionsField))
+
+			if err := writeVersions(ctx, m, vlt.db, row.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		// and the trash, see [Vault.TrashedSecrets].
+		if deletedAt, ok := trashed[row.ID]; ok {
+			writeField(m, []byte(integrityTrashField))
+			writeField(m, []byte(deletedAt.UTC().Format(time.RFC3339)))
+		}
+
+		// and kinds, see [vaultdb.SecretMeta.Kind].
+		if kind, ok := kinds[row.ID]; ok {
+			writeField(m, []byte(integrityKindField))
+			writeField(m, []byte(kind))
+		}
+
+		rowMACs = append(rowMACs, m.Sum(nil))
+		count++
+	}
+
+	m := hmac.New(sha256.New, key)
+
+	writeField(m, []byte(integrityMACVersion))
+	writeField(m, binary.BigEndian.AppendUint64(nil, uint64(version))) //nolint:gosec // versions are non-negative.
+	writeField(m, binary.BigEndian.AppendUint64(nil, count))
+
+	for _, rm := range rowMACs {
+		writeField(m, rm)
+	}
+
+	return m.Sum(nil), nil
+}
+
+// writeChunks writes the sequence, nonce and ciphertext of each chunk of the given secret to h.
+func writeChunks(ctx context.Context, h hash.Hash, store *vaultdb.VaultDB, id int) error {
+	chunks, err := store.SecretChunks(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range chunks {
+		writeField(h, binary.BigEndian.AppendUint64(nil, uint64(c.Seq))) //nolint:gosec // sequences are non-negative.
+		writeField(h, c.Nonce)
+		writeField(h, c.Ciphertext)
+	}
+
+	return nil
+}
+
+// writeVersions writes the version, nonce and ciphertext of each previous value of the given secret to h.
+func writeVersions(ctx context.Context, h hash.Hash, store *vaultdb.VaultDB, id int) error {
+	versions, err := store.SealedSecretVersions(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		writeField(h, binary.BigEndian.AppendUint64(nil, uint64(v.Version))) //nolint:gosec // versions are non-negative.
+		writeField(h, v.Nonce)
+		writeField(h, v.Ciphertext)
+	}
+
+	return nil
+}
+
+// verifyIntegrity compares the stored integrity MAC against the vault contents.
+//
+// Vaults without a stored MAC are trusted as is, the MAC is
+// stored the next time the vault is sealed.
+func (vlt *Vault) verifyIntegrity(ctx context.Context) (ok bool, _ error) {
+	stored, err := vlt.db.IntegrityMAC(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if stored == nil {
+		return true, nil
+	}
+
+	mac, err := vlt.integrityMAC(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return hmac.Equal(stored, mac), nil
+}
+
+// updateIntegrity stores the integrity MAC of the current vault contents.
+func (vlt *Vault) updateIntegrity(ctx context.Context) error {
+	mac, err := vlt.integrityMAC(ctx)
+	if err != nil {
+		return err
+	}
+
+	return vlt.db.SetIntegrityMAC(ctx, mac)
+}
+
+// ChangesAccepted reports whether the vault failed the integrity check
+// on open, and the changes were accepted using [WithAcceptChanges].
+func (vlt *Vault) ChangesAccepted() bool {
+	return vlt.changesAccepted
+}
+
+// writeField writes b to h, prefixed by its length.
+func writeField(h hash.Hash, b []byte) {
+	_ = binary.Write(h, binary.BigEndian, uint64(len(b)))
+	_, _ = h.Write(b)
+}
@@ -0,0 +1,82 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_Open_Integrity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.InsertNewSecret(t.Context(), "name", "secret", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err = Open(t.Context(), path, WithPassword("password"))
+	if err != nil {
+		t.Fatalf("open untampered vault: %v", err)
+	}
+
+	// modify the vault contents, persisting them without refreshing the MAC.
+	if _, err := v.conn.ExecContext(t.Context(), `UPDATE secrets SET name = 'other'`); err != nil {
+		t.Fatal(err)
+	}
+
+	serialized, err := Serialize(v.conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphervault, err := v.aesgcm.Seal(v.nonce, serialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.vaultContainerHandle.db.UpdateVault(t.Context(), ciphervault); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.cleanup(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(t.Context(), path, WithPassword("password")); !errors.Is(err, vaulterrors.ErrVaultModified) {
+		t.Fatalf("open tampered vault: got err %v, want %v", err, vaulterrors.ErrVaultModified)
+	}
+
+	v, err = Open(t.Context(), path, WithPassword("password"), WithAcceptChanges())
+	if err != nil {
+		t.Fatalf("open tampered vault accepting changes: %v", err)
+	}
+
+	if !v.ChangesAccepted() {
+		t.Error("expected accepted changes to be reported")
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err = Open(t.Context(), path, WithPassword("password"))
+	if err != nil {
+		t.Fatalf("open vault after accepting changes: %v", err)
+	}
+
+	if v.ChangesAccepted() {
+		t.Error("unexpected accepted changes after the MAC was refreshed")
+	}
+
+	_ = v.Close(t.Context())
+}
@@ -0,0 +1,96 @@
+package vault
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// readRecord is an audit log entry recorded by a read operation,
+// along with the access of the secret read, if any.
+type readRecord struct {
+	op       vaultdb.Operation
+	secretID int
+	actor    string
+	access   bool // access is set if the secret access was recorded, see [Vault.Frecency].
+}
+
+// auditRead audits the read operation, recording the access of the secret
+// if access is set, and journals both, see [Vault.merge].
+func (vlt *Vault) auditRead(ctx context.Context, op vaultdb.Operation, secretID int, access bool) (vaultdb.AuditEntry, error) {
+	entry, err := vlt.audit(ctx, vlt.db, op, secretID)
+	if err != nil {
+		return vaultdb.AuditEntry{}, err
+	}
+
+	if access && entry.ID != 0 {
+		if err := vlt.db.RecordSecretAccess(ctx, secretID, frecencyAccesses); err != nil {
+			return vaultdb.AuditEntry{}, fmt.Errorf("record access: %w", err)
+		}
+	}
+
+	vlt.journal = append(vlt.journal, readRecord{
+		op:       op,
+		secretID: secretID,
+		actor:    vlt.principal(ctx).Actor,
+		access:   access,
+	})
+
+	return entry, nil
+}
+
+// merge reloads the vault if it was written by another process since it was
+// last loaded or stored, and replays the journaled read records onto it.
+//
+// Processes only reading from the vault share its lock, see [Lock], so the
+// last one to close would otherwise drop the audit log entries of the others.
+// Cached link values are not replayed, they are resolved again once expired.
+//
+// It fails with [vaulterrors.ErrVaultChanged] if the vault was written to
+// as well, as these changes cannot be merged.
+func (vlt *Vault) merge(ctx context.Context) error {
+	cipherdata, err := vlt.vaultContainerHandle.db.SelectVault(ctx)
+	if err != nil {
+		return fmt.Errorf("merge: %w", err)
+	}
+
+	if sha256.Sum256(cipherdata.Vault) == vlt.sealed {
+		return nil
+	}
+
+	if vlt.written {
+		return vaulterrors.ErrVaultChanged
+	}
+
+	if err := vlt.connect(ctx); err != nil {
+		return fmt.Errorf("merge: %w", err)
+	}
+
+	// fails if the master password was rotated meanwhile.
+	if err := vlt.load(ctx, cipherdata.Vault); err != nil {
+		return fmt.Errorf("merge: %w: %w", vaulterrors.ErrVaultChanged, err)
+	}
+
+	for _, r := range vlt.journal {
+		ctx := WithPrincipal(ctx, Principal{Actor: r.actor})
+
+		entry, err := vlt.audit(ctx, vlt.db, r.op, r.secretID)
+		if err != nil {
+			return fmt.Errorf("merge: audit: %w", err)
+		}
+
+		// the secret may have been deleted meanwhile.
+		if !r.access || entry.ID == 0 {
+			continue
+		}
+
+		if err := vlt.db.RecordSecretAccess(ctx, r.secretID, frecencyAccesses); err != nil {
+			return fmt.Errorf("merge: record access: %w", err)
+		}
+	}
+
+	return nil
+}
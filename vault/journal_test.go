@@ -0,0 +1,131 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// TestVault_Close_OverlappingReaders checks that vaults read from while
+// opened by several processes keep the audit log entries of all of them.
+func TestVault_Close_OverlappingReaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := v.InsertNewSecret(t.Context(), "name", "secret", nil)
+	if err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	readers := make([]*Vault, 3)
+
+	for i := range readers {
+		r, err := Open(t.Context(), path, WithPassword("password"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := r.ShowSecret(t.Context(), id); err != nil {
+			_ = r.Close(t.Context())
+			t.Fatal(err)
+		}
+
+		readers[i] = r
+	}
+
+	for _, r := range readers {
+		if err := r.Close(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v, err = Open(t.Context(), path, WithPassword("password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	shown := 0
+
+	for e, err := range v.AuditLog(t.Context(), vaultdb.AuditFilters{Operations: []vaultdb.Operation{vaultdb.OpShow}}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if e.SecretID == id {
+			shown++
+		}
+	}
+
+	if shown != len(readers) {
+		t.Errorf("got %d show audit entries, want %d", shown, len(readers))
+	}
+
+	scores, err := v.Frecency(t.Context(), time.Now(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := scores[id]; !ok {
+		t.Error("secret access not recorded")
+	}
+
+	if _, err := v.VerifyAuditLog(t.Context()); err != nil {
+		t.Errorf("verify audit log: %v", err)
+	}
+}
+
+// TestVault_Close_Changed checks that changes of a vault that was written by
+// another process while open are rejected instead of overwriting the others.
+func TestVault_Close_Changed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	open := func() *Vault {
+		t.Helper()
+
+		v, err := Open(t.Context(), path, WithPassword("password"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := v.InsertNewSecret(t.Context(), "name", "secret", nil); err != nil {
+			_ = v.Close(t.Context())
+			t.Fatal(err)
+		}
+
+		return v
+	}
+
+	first, second := open(), open()
+
+	if err := first.Close(t.Context()); err != nil {
+		_ = second.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	if err := second.Close(t.Context()); !errors.Is(err, vaulterrors.ErrVaultChanged) {
+		t.Errorf("got %v, want %v", err, vaulterrors.ErrVaultChanged)
+	}
+}
@@ -0,0 +1,135 @@
+package vault
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// keepassxcHashInfo binds the database hash reported to KeePassXC-Browser extensions.
+const keepassxcHashInfo = "vlt-keepassxc-database-hash"
+
+// PairKeePassXC starts pairing the KeePassXC-Browser association with the given id,
+// replacing the existing one, if any.
+//
+// The first extension associating before the pairing expires after ttl takes
+// over the association, see [Vault.AssociateKeePassXC].
+func (vlt *Vault) PairKeePassXC(ctx context.Context, id string, ttl time.Duration) (vaultdb.KeePassXCAssociation, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return vaultdb.KeePassXCAssociation{}, errf("pair keepassxc: %w", err)
+	}
+
+	if err := vlt.checkWritable(ctx); err != nil {
+		return vaultdb.KeePassXCAssociation{}, errf("pair keepassxc: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	a := vaultdb.KeePassXCAssociation{
+		ID:               id,
+		PairingExpiresAt: now.Add(ttl).Truncate(time.Second),
+		CreatedAt:        now,
+	}
+
+	if err := vlt.db.SetKeePassXCAssociation(ctx, a); err != nil {
+		return vaultdb.KeePassXCAssociation{}, errf("pair keepassxc: %w", err)
+	}
+
+	return a, nil
+}
+
+// AssociateKeePassXC completes the latest pairing that did not expire at now,
+// associating the extension with the given identity public key.
+// It returns the association id, or [vaulterrors.ErrNotPairing]
+// if no pairing is in progress.
+func (vlt *Vault) AssociateKeePassXC(ctx context.Context, idKey string, now time.Time) (string, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return "", errf("associate keepassxc: %w", err)
+	}
+
+	if err := vlt.checkWritable(ctx); err != nil {
+		return "", errf("associate keepassxc: %w", err)
+	}
+
+	associations, err := vlt.db.KeePassXCAssociations(ctx)
+	if err != nil {
+		return "", errf("associate keepassxc: %w", err)
+	}
+
+	// associations are ordered by creation time, the latest pairing wins.
+	for i := len(associations) - 1; i >= 0; i-- {
+		a := associations[i]
+		if !a.Pending() || !now.Before(a.PairingExpiresAt) {
+			continue
+		}
+
+		a.IDKey, a.PairingExpiresAt = idKey, time.Time{}
+
+		if err := vlt.db.SetKeePassXCAssociation(ctx, a); err != nil {
+			return "", errf("associate keepassxc: %w", err)
+		}
+
+		return a.ID, nil
+	}
+
+	return "", vaulterrors.ErrNotPairing
+}
+
+// VerifyKeePassXCAssociation reports whether the association with the given id
+// exists and belongs to the extension with the given identity public key.
+func (vlt *Vault) VerifyKeePassXCAssociation(ctx context.Context, id string, idKey string) (bool, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return false, errf("verify keepassxc association: %w", err)
+	}
+
+	a, err := vlt.db.KeePassXCAssociation(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, errf("verify keepassxc association: %w", err)
+	}
+
+	return !a.Pending() && a.IDKey == idKey, nil
+}
+
+// KeePassXCAssociations returns all KeePassXC-Browser associations,
+// including pending ones.
+func (vlt *Vault) KeePassXCAssociations(ctx context.Context) ([]vaultdb.KeePassXCAssociation, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return nil, errf("keepassxc associations: %w", err)
+	}
+
+	return vlt.db.KeePassXCAssociations(ctx)
+}
+
+// RevokeKeePassXCAssociations deletes the associations with the given ids,
+// returning the number of revoked associations.
+func (vlt *Vault) RevokeKeePassXCAssociations(ctx context.Context, ids ...string) (int64, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return 0, errf("revoke keepassxc associations: %w", err)
+	}
+
+	if err := vlt.checkWritable(ctx); err != nil {
+		return 0, errf("revoke keepassxc associations: %w", err)
+	}
+
+	return vlt.db.DeleteKeePassXCAssociations(ctx, ids)
+}
+
+// KeePassXCDatabaseHash returns the hash identifying the vault to
+// KeePassXC-Browser extensions, derived from the vault key.
+func (vlt *Vault) KeePassXCDatabaseHash() (string, error) {
+	b, err := vlt.aesgcm.DeriveKey(keepassxcHashInfo, 32)
+	if err != nil {
+		return "", errf("keepassxc database hash: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,75 @@
+package vault
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// SetLabelMeta sets the description and color of the label named m.Name,
+// whether or not any secret has it. Setting both to empty removes them.
+//
+// Label metadata is vault-wide, it cannot be set if access is restricted
+// by a scope. It is not part of the oplog, and as such is not synced.
+func (vlt *Vault) SetLabelMeta(ctx context.Context, m vaultdb.LabelMeta) error {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("set label meta: %w", err)
+	}
+
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return errf("set label meta: %w", err)
+	}
+
+	if len(m.Name) == 0 {
+		return errf("set label meta: %w", vaulterrors.ErrEmptyName)
+	}
+
+	if len(m.Description) == 0 && len(m.Color) == 0 {
+		if _, err := vlt.db.DeleteLabelMeta(ctx, m.Name); err != nil {
+			return errf("set label meta: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := vlt.db.SetLabelMeta(ctx, m); err != nil {
+		return errf("set label meta: %w", err)
+	}
+
+	return nil
+}
+
+// LabelMetas returns the metadata of the described labels, ordered by name.
+//
+// If access is restricted by a scope, only the labels of
+// accessible secrets are returned.
+func (vlt *Vault) LabelMetas(ctx context.Context) ([]vaultdb.LabelMeta, error) {
+	metas, err := vlt.db.LabelMetas(ctx)
+	if err != nil {
+		return nil, errf("label metas: %w", err)
+	}
+
+	if len(vlt.scopes(ctx)) == 0 {
+		return metas, nil
+	}
+
+	secrets, err := vlt.FilterSecrets(ctx, "", "", nil)
+	if err != nil {
+		return nil, errf("label metas: %w", err)
+	}
+
+	labels := make(map[string]struct{})
+
+	for _, s := range secrets {
+		for _, l := range s.Labels {
+			labels[l] = struct{}{}
+		}
+	}
+
+	return slices.DeleteFunc(metas, func(m vaultdb.LabelMeta) bool {
+		_, ok := labels[m.Name]
+		return !ok
+	}), nil
+}
@@ -0,0 +1,76 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_LabelMetas(t *testing.T) {
+	v, err := New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	if _, err := v.InsertNewSecret(t.Context(), "deploy", "secret", []string{"ci/deploy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range []vaultdb.LabelMeta{
+		{Name: "ci/deploy", Description: "deployment keys", Color: "green"},
+		{Name: "q", Description: "quarantined, pending rotation"},
+		{Name: "x1", Color: "red"},
+	} {
+		if err := v.SetLabelMeta(t.Context(), m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// updates replace the metadata, clearing both removes it.
+	if err := v.SetLabelMeta(t.Context(), vaultdb.LabelMeta{Name: "q", Description: "quarantined"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.SetLabelMeta(t.Context(), vaultdb.LabelMeta{Name: "x1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	metas, err := v.LabelMetas(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []vaultdb.LabelMeta{
+		{Name: "ci/deploy", Description: "deployment keys", Color: "green"},
+		{Name: "q", Description: "quarantined"},
+	}
+
+	if !slices.Equal(metas, want) {
+		t.Errorf("label metas: got %v, want %v", metas, want)
+	}
+
+	// scoped principals see the labels of accessible secrets only.
+	ctx := WithPrincipal(t.Context(), Principal{Scope: []string{"ci/*"}})
+
+	metas, err = v.LabelMetas(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(metas, want[:1]) {
+		t.Errorf("scoped label metas: got %v, want %v", metas, want[:1])
+	}
+
+	if err := v.SetLabelMeta(ctx, vaultdb.LabelMeta{Name: "q"}); !errors.Is(err, vaulterrors.ErrTokenScope) {
+		t.Errorf("scoped set label meta: got err %v, want %v", err, vaulterrors.ErrTokenScope)
+	}
+
+	if err := v.SetLabelMeta(t.Context(), vaultdb.LabelMeta{Description: "d"}); !errors.Is(err, vaulterrors.ErrEmptyName) {
+		t.Errorf("set unnamed label meta: got err %v, want %v", err, vaulterrors.ErrEmptyName)
+	}
+}
@@ -0,0 +1,252 @@
+package vault
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// Resolver resolves links to the current value of the secret they reference.
+type Resolver interface {
+	Resolve(ctx context.Context, uri string) (string, error)
+}
+
+// WithResolver sets the resolver used to resolve linked secrets.
+//
+// Without a resolver, linked secrets evaluate to their cached value.
+func WithResolver(r Resolver) Option {
+	return func(c *config) {
+		c.resolver = r
+	}
+}
+
+// InsertLink inserts a new secret linked to the given uri, whose value is
+// resolved using the [Resolver] and cached for ttl.
+//
+// Returns the ID of the inserted secret or an error if the link cannot be resolved.
+func (vlt *Vault) InsertLink(ctx context.Context, name string, uri string, ttl time.Duration, labels []string) (id int, retErr error) {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return 0, errf("insert link: %w", err)
+	}
+
+	if vlt.resolver == nil {
+		return 0, errf("insert link: %w", vaulterrors.ErrNoLinkResolver)
+	}
+
+	value, err := vlt.resolver.Resolve(ctx, uri)
+	if err != nil {
+		return 0, errf("insert link: %w", err)
+	}
+
+	nonce, ciphertext, err := vlt.sealSecret(value)
+	if err != nil {
+		return 0, errf("insert link: %w", err)
+	}
+
+	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, errf("insert link: %w", err)
+	}
+	defer func() { //nolint:wsl
+		if retErr != nil {
+			if err := tx.Rollback(); err != nil {
+				retErr = errors.Join(retErr, errf("insert link: rollback: %w", err))
+			}
+		}
+	}()
+
+	storeTx := vlt.db.WithTx(tx)
+
+	if id, err = storeTx.InsertNewSecret(ctx, name, nonce, ciphertext); err != nil {
+		return 0, errf("insert link: %w", err)
+	}
+
+	for _, l := range labels {
+		if _, err := storeTx.InsertLabel(ctx, l, id); err != nil {
+			return 0, errf("insert link: insert label: %w", err)
+		}
+	}
+
+	link := vaultdb.Link{SecretID: id, URI: uri, TTL: ttl, ResolvedAt: time.Now()}
+	if err := storeTx.InsertLink(ctx, link); err != nil {
+		return 0, errf("insert link: %w", err)
+	}
+
+	if err := vlt.checkScope(ctx, storeTx, id); err != nil {
+		return 0, errf("insert link: %w", err)
+	}
+
+	if err := vlt.record(ctx, storeTx, oplogPut, id); err != nil {
+		return 0, errf("insert link: %w", err)
+	}
+
+	entry, err := vlt.audit(ctx, storeTx, vaultdb.OpInsert, id)
+	if err != nil {
+		return 0, errf("insert link: audit: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errf("insert link: tx commit: %w", err)
+	}
+
+	vlt.emit(entry)
+
+	return id, nil
+}
+
+// Links returns the links of all accessible linked secrets, ordered by secret id.
+func (vlt *Vault) Links(ctx context.Context) ([]vaultdb.Link, error) {
+	links, err := vlt.db.Links(ctx)
+	if err != nil {
+		return nil, errf("links: %w", err)
+	}
+
+	trashed, err := vlt.db.TrashedAt(ctx)
+	if err != nil {
+		return nil, errf("links: %w", err)
+	}
+
+	links = slices.DeleteFunc(links, func(l vaultdb.Link) bool {
+		_, ok := trashed[l.SecretID]
+		return ok
+	})
+
+	allowed, err := vlt.allowed(ctx, vlt.db)
+	if err != nil {
+		return nil, errf("links: %w", err)
+	}
+
+	if allowed != nil {
+		links = slices.DeleteFunc(links, func(l vaultdb.Link) bool {
+			_, ok := allowed[l.SecretID]
+			return !ok
+		})
+	}
+
+	return links, nil
+}
+
+// RefreshLinks resolves the links of the given secrets, or of all linked
+// secrets if no ids are given, regardless of their cache expiry.
+//
+// Returns the number of secrets whose cached value changed.
+func (vlt *Vault) RefreshLinks(ctx context.Context, ids ...int) (int, error) {
+	if vlt.resolver == nil {
+		return 0, errf("refresh links: %w", vaulterrors.ErrNoLinkResolver)
+	}
+
+	links, err := vlt.Links(ctx)
+	if err != nil {
+		return 0, errf("refresh links: %w", err)
+	}
+
+	if len(ids) > 0 {
+		if err := vlt.checkScope(ctx, vlt.db, ids...); err != nil {
+			return 0, errf("refresh links: %w", err)
+		}
+
+		links = slices.DeleteFunc(links, func(l vaultdb.Link) bool { return !slices.Contains(ids, l.SecretID) })
+	}
+
+	changed := 0
+
+	for _, l := range links {
+		ok, err := vlt.resolveLink(ctx, l)
+		if err != nil {
+			return changed, errf("refresh links: secret %d: %w", l.SecretID, err)
+		}
+
+		if ok {
+			changed++
+		}
+	}
+
+	return changed, nil
+}
+
+// refreshLink resolves the link of the given secret if its cached value expired.
+// It does nothing for secrets that are not linked, or if no [Resolver] is set.
+func (vlt *Vault) refreshLink(ctx context.Context, id int) error {
+	if vlt.resolver == nil {
+		return nil
+	}
+
+	l, err := vlt.db.Link(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !l.Expired(time.Now()) {
+		return nil
+	}
+
+	_, err = vlt.resolveLink(ctx, l)
+
+	return err
+}
+
+// resolveLink resolves the given link and caches the resolved value,
+// reporting whether it differs from the previously cached value.
+//
+// The cache is not part of the secret history, it is updated
+// without recording audit log or oplog entries.
+func (vlt *Vault) resolveLink(ctx context.Context, l vaultdb.Link) (changed bool, retErr error) {
+	value, err := vlt.resolver.Resolve(ctx, l.URI)
+	if err != nil {
+		return false, err
+	}
+
+	nonce, ciphertext, err := vlt.db.ShowSecret(ctx, l.SecretID)
+	if err != nil {
+		return false, err
+	}
+
+	cached, err := vlt.aesgcm.Open(nonce, ciphertext)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return false, err
+	}
+	defer func() { //nolint:wsl
+		if retErr != nil {
+			if err := tx.Rollback(); err != nil {
+				retErr = errors.Join(retErr, errf("rollback: %w", err))
+			}
+		}
+	}()
+
+	storeTx := vlt.db.WithTx(tx)
+
+	// rewritten only if changed, to keep the secret update time meaningful.
+	if changed = string(cached) != value; changed {
+		if nonce, ciphertext, err = vlt.sealSecret(value); err != nil {
+			return false, err
+		}
+
+		if _, err := storeTx.UpdateSecret(ctx, l.SecretID, nonce, ciphertext); err != nil {
+			return false, err
+		}
+	}
+
+	if err := storeTx.SetLinkResolved(ctx, l.SecretID, time.Now()); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,103 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// mapResolver resolves links to their values in the map, counting resolutions.
+type mapResolver struct {
+	values   map[string]string
+	resolved int
+}
+
+func (r *mapResolver) Resolve(_ context.Context, uri string) (string, error) {
+	v, ok := r.values[uri]
+	if !ok {
+		return "", errors.New("not found")
+	}
+
+	r.resolved++
+
+	return v, nil
+}
+
+func TestVault_Links(t *testing.T) {
+	r := &mapResolver{values: map[string]string{"hv://secret/data/db#password": "v1", "hv://kv/api": "key"}}
+
+	v, err := New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password", WithResolver(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	if _, err := v.InsertLink(t.Context(), "missing", "hv://missing", time.Hour, nil); err == nil {
+		t.Error("insert unresolvable link: got nil err")
+	}
+
+	cachedID, err := v.InsertLink(t.Context(), "db", "hv://secret/data/db#password", time.Hour, []string{"prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uncachedID, err := v.InsertLink(t.Context(), "api", "hv://kv/api", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.values["hv://secret/data/db#password"] = "v2"
+	r.values["hv://kv/api"] = "rotated"
+	r.resolved = 0
+
+	// the cached value is used until it expires.
+	if s, err := v.ShowSecret(t.Context(), cachedID); err != nil || s != "v1" {
+		t.Errorf("show cached link: got %q, %v, want %q", s, err, "v1")
+	}
+
+	if s, err := v.ShowSecret(t.Context(), uncachedID); err != nil || s != "rotated" {
+		t.Errorf("show uncached link: got %q, %v, want %q", s, err, "rotated")
+	}
+
+	if r.resolved != 1 {
+		t.Errorf("got %d resolutions, want 1", r.resolved)
+	}
+
+	if _, err := v.UpdateSecret(t.Context(), cachedID, "manual"); !errors.Is(err, vaulterrors.ErrLinkedSecret) {
+		t.Errorf("update linked secret: got err %v, want %v", err, vaulterrors.ErrLinkedSecret)
+	}
+
+	changed, err := v.RefreshLinks(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changed != 1 {
+		t.Errorf("refresh links: got %d changed, want 1", changed)
+	}
+
+	if s, err := v.ShowSecret(t.Context(), cachedID); err != nil || s != "v2" {
+		t.Errorf("show refreshed link: got %q, %v, want %q", s, err, "v2")
+	}
+
+	links, err := v.Links(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(links) != 2 || links[0].SecretID != cachedID || links[0].TTL != time.Hour {
+		t.Errorf("links: got %+v", links)
+	}
+
+	if _, err := v.DeleteSecretsByIDs(t.Context(), cachedID); err != nil {
+		t.Fatal(err)
+	}
+
+	if links, err := v.Links(t.Context()); err != nil || len(links) != 1 {
+		t.Errorf("links after delete: got %d, %v, want 1", len(links), err)
+	}
+}
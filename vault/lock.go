@@ -0,0 +1,144 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+const (
+	// lockFilePerm is the permission the lock file is created with.
+	lockFilePerm = 0o600
+
+	// lockMinBackoff and lockMaxBackoff bound the wait between lock attempts.
+	lockMinBackoff = 10 * time.Millisecond
+	lockMaxBackoff = 250 * time.Millisecond
+)
+
+// LockMode is the mode a vault is locked in.
+type LockMode int
+
+const (
+	// LockExclusive is held by processes writing to the vault,
+	// excluding all others.
+	LockExclusive LockMode = iota
+
+	// LockShared is held by processes reading from the vault,
+	// excluding writers only.
+	LockShared
+)
+
+// Lock is an advisory lock coordinating vlt processes using the same vault.
+//
+// The vault is loaded into memory when opened, and written back as a whole
+// when closed. A process writing to the vault holds it exclusively, from
+// before it is opened until after it is closed, so that no other process
+// overwrites its changes using an outdated copy.
+//
+// Processes only reading from the vault share it, yet they too write back
+// the audit log entries and accesses they record. They upgrade the lock
+// using [Lock.Upgrade] before closing the vault, which merges the records
+// into the latest copy if it was written meanwhile, see [Vault.Close].
+//
+// The lock is a flock(2), or LockFileEx on Windows, on a file next to the
+// vault, released by the kernel if the process exits without unlocking it.
+type Lock struct {
+	f *os.File
+}
+
+// LockPath returns the path of the lock file of the vault at the given path.
+func LockPath(path string) string { return path + ".lock" }
+
+// TryLock locks the vault at the given path in the given mode, reporting
+// false if it is held by another process in a conflicting mode.
+func TryLock(path string, mode LockMode) (*Lock, bool, error) {
+	f, err := os.OpenFile(LockPath(path), os.O_RDWR|os.O_CREATE, lockFilePerm)
+	if err != nil {
+		return nil, false, errf("lock: %w", err)
+	}
+
+	ok, err := lockFile(f, mode)
+	if err != nil || !ok {
+		_ = f.Close()
+
+		if err != nil {
+			return nil, false, errf("lock: %w", err)
+		}
+
+		return nil, false, nil
+	}
+
+	return &Lock{f: f}, true, nil
+}
+
+// AcquireLock locks the vault at the given path in the given mode, retrying
+// with an increasing backoff while it is held by another process in a
+// conflicting mode. It fails with [vaulterrors.ErrVaultBusy] once the context
+// is done.
+func AcquireLock(ctx context.Context, path string, mode LockMode) (*Lock, error) {
+	var l *Lock
+
+	err := retryLock(ctx, func() (ok bool, err error) {
+		l, ok, err = TryLock(path, mode)
+		return ok, err
+	})
+
+	return l, err
+}
+
+// Upgrade converts the lock into an exclusive one, retrying like
+// [AcquireLock] while it is held by another process.
+//
+// The lock is released first, as converting it is not atomic on all
+// platforms, so the vault may be written by another process meanwhile.
+func (l *Lock) Upgrade(ctx context.Context) error {
+	if err := unlockFile(l.f); err != nil {
+		return errf("lock: upgrade: %w", err)
+	}
+
+	err := retryLock(ctx, func() (bool, error) {
+		return lockFile(l.f, LockExclusive)
+	})
+	if err != nil && !errors.Is(err, vaulterrors.ErrVaultBusy) {
+		return errf("lock: upgrade: %w", err)
+	}
+
+	return err
+}
+
+// retryLock calls try with an increasing backoff until it locks, fails, or
+// the context is done, failing with [vaulterrors.ErrVaultBusy].
+func retryLock(ctx context.Context, try func() (bool, error)) error {
+	backoff := lockMinBackoff
+
+	for {
+		ok, err := try()
+		if err != nil || ok {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", vaulterrors.ErrVaultBusy, context.Cause(ctx))
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, lockMaxBackoff)
+	}
+}
+
+// Unlock releases the lock. It is safe to call on a nil lock.
+func (l *Lock) Unlock() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+
+	err := errors.Join(unlockFile(l.f), l.f.Close())
+	l.f = nil
+
+	return err
+}
@@ -0,0 +1,108 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestTryLock(t *testing.T) {
+	tests := []struct {
+		name       string
+		held, want vault.LockMode
+		ok         bool
+	}{
+		{name: "shared with shared", held: vault.LockShared, want: vault.LockShared, ok: true},
+		{name: "exclusive with shared", held: vault.LockShared, want: vault.LockExclusive, ok: false},
+		{name: "shared with exclusive", held: vault.LockExclusive, want: vault.LockShared, ok: false},
+		{name: "exclusive with exclusive", held: vault.LockExclusive, want: vault.LockExclusive, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "vault.db")
+
+			held, ok, err := vault.TryLock(path, tt.held)
+			if err != nil || !ok {
+				t.Fatalf("lock: got %v, %v", ok, err)
+			}
+			defer func() { _ = held.Unlock() }() //nolint:wsl
+
+			l, ok, err := vault.TryLock(path, tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = l.Unlock() }() //nolint:wsl
+
+			if ok != tt.ok {
+				t.Errorf("try lock: got %v, want %v", ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAcquireLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	held, err := vault.AcquireLock(t.Context(), path, vault.LockExclusive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := vault.AcquireLock(ctx, path, vault.LockShared); !errors.Is(err, vaulterrors.ErrVaultBusy) {
+		t.Fatalf("acquire held lock: got %v, want %v", err, vaulterrors.ErrVaultBusy)
+	}
+
+	// the lock is acquired once released while waiting for it.
+	time.AfterFunc(50*time.Millisecond, func() { _ = held.Unlock() })
+
+	l, err := vault.AcquireLock(t.Context(), path, vault.LockShared)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLock_Upgrade(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	l, err := vault.AcquireLock(t.Context(), path, vault.LockShared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Unlock() }() //nolint:wsl
+
+	other, err := vault.AcquireLock(t.Context(), path, vault.LockShared)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := l.Upgrade(ctx); !errors.Is(err, vaulterrors.ErrVaultBusy) {
+		t.Fatalf("upgrade shared lock: got %v, want %v", err, vaulterrors.ErrVaultBusy)
+	}
+
+	// the lock is upgraded once the other reader releases it.
+	time.AfterFunc(50*time.Millisecond, func() { _ = other.Unlock() })
+
+	if err := l.Upgrade(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := vault.TryLock(path, vault.LockShared); err != nil || ok {
+		t.Errorf("shared lock of upgraded lock: got %v, %v", ok, err)
+	}
+}
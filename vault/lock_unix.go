@@ -0,0 +1,30 @@
+//go:build !windows
+
+package vault
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockFile locks f in the given mode without blocking,
+// reporting false if it is held in a conflicting mode.
+func lockFile(f *os.File, mode LockMode) (bool, error) {
+	how := unix.LOCK_EX
+	if mode == LockShared {
+		how = unix.LOCK_SH
+	}
+
+	err := unix.Flock(int(f.Fd()), how|unix.LOCK_NB)
+	if errors.Is(err, unix.EWOULDBLOCK) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func unlockFile(f *os.File) error {
+	return unix.Flock(int(f.Fd()), unix.LOCK_UN)
+}
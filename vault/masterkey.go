@@ -0,0 +1,267 @@
+package vault
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultcontainer"
+	"github.com/ladzaretti/vlt-cli/vaultcrypto"
+)
+
+// RotateMasterPassword replaces the vault password, re-encrypting the vault
+// under a key derived from newPassword.
+//
+// The values of all secrets, including trashed ones, their chunks, previous
+// versions and attachments are decrypted and sealed using the new key. The file
+// key derived from the previous key is kept in the vault, see [Vault.FileKey],
+// so that files encrypted before the rotation can still be decrypted. The
+// vault is then stored along with the new key derivation parameters and the
+// attachments in a single transaction of the vault container. Access tokens,
+// see [Vault.IssueToken], hold the previous key and are revoked, returning
+// their number. API tokens, see [Vault.IssueAPIToken], hold no key and are
+// kept. Previous versions of the vault kept by the container are deleted as well.
+//
+// If the vault cannot be stored, the container is left unchanged, and the
+// vault is made read-only, not to store values sealed using the new key
+// along with the previous key derivation parameters.
+func (vlt *Vault) RotateMasterPassword(ctx context.Context, password string, newPassword string) (revoked int, retErr error) {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return 0, errf("rotate master password: %w", err)
+	}
+
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return 0, errf("rotate master password: %w", err)
+	}
+
+	cipherdata, err := vlt.vaultContainerHandle.db.SelectVault(ctx)
+	if err != nil {
+		return 0, errf("rotate master password: %w", err)
+	}
+
+	if err := verifyPassword([]byte(password), cipherdata.AuthPHC); err != nil {
+		return 0, errf("rotate master password: %w", err)
+	}
+
+	next, err := vaultCipherData([]byte(newPassword))
+	if err != nil {
+		return 0, errf("rotate master password: %w", err)
+	}
+
+	phc, err := vaultcrypto.DecodeAragon2idPHC(next.KDFPHC)
+	if err != nil {
+		return 0, errf("rotate master password: %w", err)
+	}
+
+	aes, err := deriveAESGCM(phc, []byte(newPassword))
+	if err != nil {
+		return 0, errf("rotate master password: %w", err)
+	}
+
+	if err := vlt.resealValues(ctx, aes); err != nil {
+		return 0, errf("rotate master password: %w", err)
+	}
+
+	// the values are sealed using the new key from here on.
+	prev := vlt.aesgcm
+	vlt.aesgcm, vlt.nonce = aes, next.Nonce
+
+	revoked, err = vlt.storeRotated(ctx, prev, next)
+	if err != nil {
+		vlt.readOnly = true
+		return 0, errf("rotate master password: vault left unchanged: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// resealValues re-encrypts the values sealed using the vault key, see
+// [vaultdb.VaultDB.AllSealedValues], using aes within a single transaction,
+// in which the file key is kept in the vault, unless it is already.
+func (vlt *Vault) resealValues(ctx context.Context, aes *vaultcrypto.AESGCM) (retErr error) {
+	values, err := vlt.db.AllSealedValues(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i, v := range values {
+		plaintext, err := vlt.aesgcm.Open(v.Nonce, v.Ciphertext)
+		if err != nil {
+			return errf("%s %d: %w", v.Table, v.RowID, err)
+		}
+
+		if values[i].Nonce, err = vaultcrypto.RandBytes(12); err != nil {
+			return err
+		}
+
+		if values[i].Ciphertext, err = aes.Seal(values[i].Nonce, plaintext); err != nil {
+			return err
+		}
+	}
+
+	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { //nolint:wsl
+		if retErr != nil {
+			if err := tx.Rollback(); err != nil {
+				retErr = errors.Join(retErr, errf("rollback: %w", err))
+			}
+		}
+	}()
+
+	storeTx := vlt.db.WithTx(tx)
+
+	if err := storeTx.UpdateSealedValues(ctx, values); err != nil {
+		return err
+	}
+
+	fileKey, err := vlt.fileKey(ctx, storeTx)
+	if err != nil {
+		return errf("file key: %w", err)
+	}
+
+	if err := storeTx.SetFileKey(ctx, fileKey); err != nil {
+		return errf("file key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errf("tx commit: %w", err)
+	}
+
+	return nil
+}
+
+// storeRotated re-encrypts the attachments sealed using prev, revokes all
+// API tokens, and stores the vault along with the key derivation parameters
+// of next in place of its previous versions, within a single transaction of
+// the vault container.
+func (vlt *Vault) storeRotated(ctx context.Context, prev *vaultcrypto.AESGCM, next *vaultcontainer.CipherData) (_ int, retErr error) {
+	if err := vlt.updateIntegrity(ctx); err != nil {
+		return 0, errf("integrity: %w", err)
+	}
+
+	serialized, err := Serialize(vlt.conn)
+	if err != nil {
+		return 0, err
+	}
+
+	ciphervault, err := vlt.aesgcm.Seal(next.Nonce, serialized)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := vlt.vaultContainerHandle.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { //nolint:wsl
+		if retErr != nil {
+			if err := tx.Rollback(); err != nil {
+				retErr = errors.Join(retErr, errf("rollback: %w", err))
+			}
+		}
+	}()
+
+	container := vlt.vaultContainerHandle.db.WithTx(tx)
+
+	if err := vlt.resealAttachments(ctx, container, prev); err != nil {
+		return 0, errf("attachments: %w", err)
+	}
+
+	tokens, err := container.Tokens(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		ids = append(ids, t.ID)
+	}
+
+	if _, err := container.DeleteTokens(ctx, ids); err != nil {
+		return 0, errf("revoke tokens: %w", err)
+	}
+
+	if err := container.InsertNewVault(ctx, next.AuthPHC, next.KDFPHC, next.Nonce, ciphervault); err != nil {
+		return 0, err
+	}
+
+	// previous versions of the vault are encrypted using the previous key.
+	if err := container.DeleteVaultHistory(ctx); err != nil {
+		return 0, errf("vault history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errf("tx commit: %w", err)
+	}
+
+	vlt.sealed, vlt.journal = sha256.Sum256(ciphervault), nil
+
+	return len(ids), nil
+}
+
+// resealAttachments re-encrypts the names and chunks of all attachments,
+// sealed using prev, using the vault key, keeping their associated data.
+func (vlt *Vault) resealAttachments(ctx context.Context, container *vaultcontainer.VaultContainer, prev *vaultcrypto.AESGCM) error {
+	attachments, err := container.AllAttachments(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range attachments {
+		aad := attachmentNameAAD(a.SecretID)
+
+		name, err := prev.AEAD().Open(nil, a.NameNonce, a.NameCiphertext, aad)
+		if err != nil {
+			return errf("attachment %d: %w", a.ID, err)
+		}
+
+		nonce, err := vaultcrypto.RandBytes(12)
+		if err != nil {
+			return err
+		}
+
+		if err := container.UpdateAttachmentName(ctx, a.ID, nonce, vlt.aesgcm.AEAD().Seal(nil, nonce, name, aad)); err != nil {
+			return err
+		}
+
+		// chunks are collected first, not to update them while being read.
+		var chunks []vaultcontainer.AttachmentChunk
+
+		err = container.EachAttachmentChunk(ctx, a.ID, func(c vaultcontainer.AttachmentChunk) error {
+			chunks = append(chunks, c)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, c := range chunks {
+			aad := attachmentAAD(a.ID, c.Seq, false)
+
+			plaintext, err := prev.AEAD().Open(nil, c.Nonce, c.Ciphertext, aad)
+			if err != nil {
+				// the last chunk is authenticated as such.
+				aad = attachmentAAD(a.ID, c.Seq, true)
+				if plaintext, err = prev.AEAD().Open(nil, c.Nonce, c.Ciphertext, aad); err != nil {
+					return errf("attachment %d: chunk %d: %w", a.ID, c.Seq, err)
+				}
+			}
+
+			if c.Nonce, err = vaultcrypto.RandBytes(12); err != nil {
+				return err
+			}
+
+			c.Ciphertext = vlt.aesgcm.AEAD().Seal(nil, c.Nonce, plaintext, aad)
+
+			if err := container.UpdateAttachmentChunk(ctx, a.ID, c); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
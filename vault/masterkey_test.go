@@ -0,0 +1,208 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/filecrypt"
+)
+
+func TestVault_RotateMasterPassword(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(ctx, path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inline, err := v.InsertNewSecret(ctx, "inline", "first", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.UpdateSecret(ctx, inline, "second"); err != nil {
+		t.Fatal(err)
+	}
+
+	note := strings.Repeat("recovery code\n", 3*secretChunkSize/14+1)
+
+	chunked, err := v.InsertNewSecret(ctx, "note", note, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trashed, err := v.InsertNewSecret(ctx, "trashed", "gone", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.DeleteSecretsByIDs(ctx, trashed); err != nil {
+		t.Fatal(err)
+	}
+
+	data := bytes.Repeat([]byte("kubeconfig\n"), attachmentChunkSize/5)
+
+	if _, err := v.Attach(ctx, inline, "kubeconfig", bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := v.IssueToken(ctx, TokenOptions{Scope: []string{"*"}, TTL: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+
+	apiToken, _, err := v.IssueAPIToken(ctx, "ci", TokenOptions{Scope: []string{"*"}, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.RotateMasterPassword(ctx, "wrong", "new-password"); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Errorf("RotateMasterPassword() with a wrong password: got err %v, want %v", err, ErrAuthenticationFailed)
+	}
+
+	revoked, err := v.RotateMasterPassword(ctx, "password", "new-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked != 1 {
+		t.Errorf("RotateMasterPassword() revoked tokens: got %d, want 1", revoked)
+	}
+
+	if err := v.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(ctx, path, WithPassword("password")); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Errorf("open with the previous password: got err %v, want %v", err, ErrAuthenticationFailed)
+	}
+
+	v, err = Open(ctx, path, WithPassword("new-password"))
+	if err != nil {
+		t.Fatalf("open with the new password: %v", err)
+	}
+	t.Cleanup(func() { _ = v.Close(ctx) })
+
+	if got, err := v.ShowSecret(ctx, inline); err != nil || got != "second" {
+		t.Errorf("ShowSecret(): got %q, err %v, want %q", got, err, "second")
+	}
+
+	if got, err := v.SecretAtVersion(ctx, inline, 1); err != nil || got != "first" {
+		t.Errorf("SecretAtVersion(): got %q, err %v, want %q", got, err, "first")
+	}
+
+	if got, err := v.ShowSecret(ctx, chunked); err != nil || got != note {
+		t.Errorf("ShowSecret() of chunked secret: got %d bytes, err %v, want %d bytes", len(got), err, len(note))
+	}
+
+	if err := v.RestoreTrashedSecret(ctx, trashed); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := v.ShowSecret(ctx, trashed); err != nil || got != "gone" {
+		t.Errorf("ShowSecret() of restored secret: got %q, err %v, want %q", got, err, "gone")
+	}
+
+	var buf bytes.Buffer
+	if _, err := v.WriteAttachment(ctx, inline, "kubeconfig", &buf); err != nil || !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("WriteAttachment(): got %d bytes, err %v, want %d bytes", buf.Len(), err, len(data))
+	}
+
+	tokens, err := v.Tokens(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tokens) != 0 {
+		t.Errorf("tokens after rotation: got %d, want 0", len(tokens))
+	}
+
+	// api tokens hold no key, these are kept.
+	if _, err := v.AuthenticateAPIToken(ctx, apiToken, time.Now()); err != nil {
+		t.Errorf("AuthenticateAPIToken() after rotation: %v", err)
+	}
+}
+
+// TestVault_RotateMasterPassword_FileKey checks that files encrypted using the
+// file key, and the cached values of links, survive rotations.
+func TestVault_RotateMasterPassword_FileKey(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), "vault.db")
+	r := &mapResolver{values: map[string]string{"hv://kv/db": "cached", "hv://kv/api": "v1"}}
+
+	v, err := New(ctx, path, "password", WithResolver(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cachedID, err := v.InsertLink(ctx, "db", "hv://kv/db", time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshedID, err := v.InsertLink(ctx, "api", "hv://kv/api", time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := v.FileKey(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var encrypted bytes.Buffer
+	if err := filecrypt.Encrypt(&encrypted, strings.NewReader("plaintext"), filecrypt.KeyVault, key); err != nil {
+		t.Fatal(err)
+	}
+
+	// rotated twice, the file key is kept by the first rotation.
+	if _, err := v.RotateMasterPassword(ctx, "password", "new-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.RotateMasterPassword(ctx, "new-password", "newer-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err = Open(ctx, path, WithPassword("newer-password"), WithResolver(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = v.Close(ctx) })
+
+	if key, err = v.FileKey(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := filecrypt.ReadHeader(&encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decrypted bytes.Buffer
+	if err := h.Decrypt(&decrypted, &encrypted, key); err != nil || decrypted.String() != "plaintext" {
+		t.Errorf("decrypt after rotation: got %q, err %v, want %q", decrypted.String(), err, "plaintext")
+	}
+
+	if s, err := v.ShowSecret(ctx, cachedID); err != nil || s != "cached" {
+		t.Errorf("show cached link after rotation: got %q, err %v, want %q", s, err, "cached")
+	}
+
+	r.values["hv://kv/api"] = "v2"
+
+	if n, err := v.RefreshLinks(ctx, refreshedID); err != nil || n != 1 {
+		t.Errorf("refresh link after rotation: got %d changed, err %v, want 1", n, err)
+	}
+
+	if s, err := v.ShowSecret(ctx, refreshedID); err != nil || s != "v2" {
+		t.Errorf("show refreshed link after rotation: got %q, err %v, want %q", s, err, "v2")
+	}
+}
@@ -0,0 +1,65 @@
+package vault
+
+import (
+	"context"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+// UpdateSecretMeta replaces the url, username, notes and kind of the secret
+// identified by id. The timestamps of meta are ignored.
+func (vlt *Vault) UpdateSecretMeta(ctx context.Context, id int, meta vaultdb.SecretMeta) (int64, error) {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return 0, errf("update secret meta: %w", err)
+	}
+
+	if err := vlt.checkScope(ctx, vlt.db, id); err != nil {
+		return 0, errf("update secret meta: %w", err)
+	}
+
+	n, err := vlt.db.UpdateSecretMeta(ctx, id, meta)
+	if err != nil {
+		return 0, errf("update secret meta: %w", err)
+	}
+
+	if n == 0 {
+		return 0, nil
+	}
+
+	if err := vlt.record(ctx, vlt.db, oplogPut, id); err != nil {
+		return n, errf("update secret meta: %w", err)
+	}
+
+	entry, err := vlt.audit(ctx, vlt.db, vaultdb.OpUpdateMetadata, id)
+	if err != nil {
+		return n, errf("update secret meta: audit: %w", err)
+	}
+
+	vlt.emit(entry)
+
+	return n, nil
+}
+
+// SecretMetas returns the metadata of the secrets with the given ids,
+// keyed by secret id.
+//
+// If the IDs slice is empty, the function returns [vaultdb.ErrNoIDsProvided].
+func (vlt *Vault) SecretMetas(ctx context.Context, ids ...int) (map[int]vaultdb.SecretMeta, error) {
+	metas, err := vlt.db.SecretMetas(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return scoped(ctx, vlt, metas)
+}
+
+// FilterSecretsBy is like [Vault.FilterSecrets], also filtering secrets
+// by their metadata.
+func (vlt *Vault) FilterSecretsBy(ctx context.Context, filters vaultdb.Filters) (map[int]vaultdb.SecretWithLabels, error) {
+	secrets, err := vlt.db.FilterSecrets(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return scoped(ctx, vlt, secrets)
+}
@@ -0,0 +1,160 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_SecretMeta(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta := vaultdb.SecretMeta{URL: "https://example.com", Username: "alice", Notes: "first"}
+
+	id, err := v.InsertSecretWithMeta(t.Context(), "example", "secret", []string{"web"}, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.InsertNewSecret(t.Context(), "bare", "secret", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := vaultdb.SecretMeta{URL: "https://example.com/login", Username: "bob", Notes: "second"}
+
+	if n, err := v.UpdateSecretMeta(t.Context(), id, updated); err != nil || n != 1 {
+		t.Fatalf("update secret meta: got (%d, %v), want (1, nil)", n, err)
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the metadata is covered by the integrity MAC.
+	v, err = Open(t.Context(), path, WithPassword("password"))
+	if err != nil {
+		t.Fatalf("reopen vault: %v", err)
+	}
+	t.Cleanup(func() { _ = v.Close(t.Context()) })
+
+	metas, err := v.SecretMetas(t.Context(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := metas[id]
+	if got.URL != updated.URL || got.Username != updated.Username || got.Notes != updated.Notes {
+		t.Errorf("secret meta: got %+v, want %+v", got, updated)
+	}
+
+	if got.UpdatedAt.IsZero() {
+		t.Error("secret meta: updated_at is not set after an update")
+	}
+
+	secrets, err := v.FilterSecretsBy(t.Context(), vaultdb.Filters{Username: "b*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := secrets[id]; !ok || len(secrets) != 1 {
+		t.Errorf("filter by username: got %v, want only secret %d", secrets, id)
+	}
+
+	secrets, err = v.FilterSecretsBy(t.Context(), vaultdb.Filters{URL: "https://other.*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secrets) != 0 {
+		t.Errorf("filter by url: got %v, want none", secrets)
+	}
+}
+
+func TestVault_SecretMeta_Integrity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.InsertSecretWithMeta(t.Context(), "name", "secret", nil, vaultdb.SecretMeta{Username: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err = Open(t.Context(), path, WithPassword("password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// modify the metadata, persisting it without refreshing the MAC.
+	if _, err := v.conn.ExecContext(t.Context(), `UPDATE secrets SET username = 'mallory'`); err != nil {
+		t.Fatal(err)
+	}
+
+	serialized, err := Serialize(v.conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphervault, err := v.aesgcm.Seal(v.nonce, serialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.vaultContainerHandle.db.UpdateVault(t.Context(), ciphervault); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.cleanup(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(t.Context(), path, WithPassword("password")); !errors.Is(err, vaulterrors.ErrVaultModified) {
+		t.Fatalf("open tampered vault: got err %v, want %v", err, vaulterrors.ErrVaultModified)
+	}
+}
+
+func TestVault_Sync_SecretMeta(t *testing.T) {
+	a, b := syncedVaults(t)
+
+	meta := vaultdb.SecretMeta{URL: "https://example.com", Username: "alice", Notes: "synced"}
+
+	if _, err := a.InsertSecretWithMeta(t.Context(), "new", "v", nil, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	exchange(t, a, b)
+
+	secrets, err := b.FilterSecretsBy(t.Context(), vaultdb.Filters{Name: "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secrets) != 1 {
+		t.Fatalf("synced secrets: got %v, want one", secrets)
+	}
+
+	for id := range secrets {
+		metas, err := b.SecretMetas(t.Context(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := metas[id]; got.URL != meta.URL || got.Username != meta.Username || got.Notes != meta.Notes {
+			t.Errorf("synced secret meta: got %+v, want %+v", got, meta)
+		}
+	}
+}
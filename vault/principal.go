@@ -0,0 +1,148 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// Principal describes on whose behalf vault operations are performed,
+// and restricts what they can access.
+type Principal struct {
+	Actor    string   // Actor is recorded in the audit log entries of the operations.
+	Scope    []string // Scope are label globs, secrets with a matching label are accessible. nil grants access to all secrets.
+	ReadOnly bool     // ReadOnly rejects mutations.
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx under which vault operations
+// are performed on behalf of p.
+//
+// The principal restrictions are applied in addition to the restrictions
+// the vault was opened with, e.g., [WithReadOnly] or [WithToken].
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// Actor returns the actor recorded in the audit log entries of operations
+// performed using the vault, e.g., of the token it was opened with.
+// It is empty for the vault owner.
+func (vlt *Vault) Actor() string {
+	return vlt.actor
+}
+
+// principal returns the effective principal of ctx.
+func (vlt *Vault) principal(ctx context.Context) Principal {
+	p := Principal{
+		Actor:    vlt.actor,
+		Scope:    vlt.scope,
+		ReadOnly: vlt.readOnly,
+	}
+
+	if cp, ok := ctx.Value(principalKey{}).(Principal); ok {
+		p.Actor = cp.Actor
+		p.ReadOnly = p.ReadOnly || cp.ReadOnly
+	}
+
+	return p
+}
+
+// scopes returns the scopes restricting access in ctx, the vault scope first.
+func (vlt *Vault) scopes(ctx context.Context) [][]string {
+	var scopes [][]string
+
+	if vlt.scope != nil {
+		scopes = append(scopes, vlt.scope)
+	}
+
+	if cp, ok := ctx.Value(principalKey{}).(Principal); ok && cp.Scope != nil {
+		scopes = append(scopes, cp.Scope)
+	}
+
+	return scopes
+}
+
+// checkWritable rejects mutations if the vault or the principal of ctx is read-only.
+// Otherwise, the vault is marked as written, see [Vault.merge].
+func (vlt *Vault) checkWritable(ctx context.Context) error {
+	if vlt.principal(ctx).ReadOnly {
+		return vaulterrors.ErrReadOnly
+	}
+
+	vlt.written = true
+
+	return nil
+}
+
+// checkUnscoped rejects vault-wide operations if access is restricted by a scope.
+func (vlt *Vault) checkUnscoped(ctx context.Context) error {
+	if len(vlt.scopes(ctx)) > 0 {
+		return vaulterrors.ErrTokenScope
+	}
+
+	return nil
+}
+
+// allowed returns the ids of the secrets accessible in ctx,
+// or nil if access is not restricted by a scope.
+func (vlt *Vault) allowed(ctx context.Context, store *vaultdb.VaultDB) (map[int]struct{}, error) {
+	var allowed map[int]struct{}
+
+	for _, scope := range vlt.scopes(ctx) {
+		ids, err := store.SecretIDsByLabelGlobs(ctx, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		if allowed == nil {
+			allowed = ids
+			continue
+		}
+
+		maps.DeleteFunc(allowed, func(id int, _ struct{}) bool {
+			_, ok := ids[id]
+			return !ok
+		})
+	}
+
+	return allowed, nil
+}
+
+// checkScope rejects access to secrets outside of the scopes of ctx, if any.
+func (vlt *Vault) checkScope(ctx context.Context, store *vaultdb.VaultDB, ids ...int) error {
+	allowed, err := vlt.allowed(ctx, store)
+	if err != nil || allowed == nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if _, ok := allowed[id]; !ok {
+			return fmt.Errorf("%w: secret %d", vaulterrors.ErrTokenScope, id)
+		}
+	}
+
+	return nil
+}
+
+// scoped removes the secrets outside of the scopes of ctx, if any, from m.
+func scoped[V any](ctx context.Context, vlt *Vault, m map[int]V) (map[int]V, error) {
+	allowed, err := vlt.allowed(ctx, vlt.db)
+	if err != nil {
+		return nil, err
+	}
+
+	if allowed == nil {
+		return m, nil
+	}
+
+	maps.DeleteFunc(m, func(id int, _ V) bool {
+		_, ok := allowed[id]
+		return !ok
+	})
+
+	return m, nil
+}
@@ -0,0 +1,101 @@
+package vault
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+// LabelChange removes and adds labels of the secret identified by ID.
+type LabelChange struct {
+	ID     int
+	Remove []string
+	Add    []string
+}
+
+// RelabelSecrets applies the given label changes in a single transaction,
+// so that either all of them are applied, or none are.
+//
+// Labels are removed before being added, adding labels a secret already has
+// is a no-op.
+func (vlt *Vault) RelabelSecrets(ctx context.Context, changes []LabelChange) error {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("relabel secrets: %w", err)
+	}
+
+	ids := make([]int, 0, len(changes))
+	for _, c := range changes {
+		ids = append(ids, c.ID)
+	}
+
+	if err := vlt.checkScope(ctx, vlt.db, ids...); err != nil {
+		return errf("relabel secrets: %w", err)
+	}
+
+	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	relabelTx := vlt.db.WithTx(tx)
+	entries := make([]vaultdb.AuditEntry, 0, len(changes))
+
+	for _, c := range changes {
+		entry, err := vlt.relabel(ctx, relabelTx, c)
+		if err != nil {
+			if err2 := tx.Rollback(); err2 != nil {
+				return errf("relabel secrets: rollback: %w", errors.Join(err2, err))
+			}
+
+			return errf("relabel secrets: secret %d: %w", c.ID, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	// relabeled secrets must remain within the scope.
+	if err := vlt.checkScope(ctx, relabelTx, ids...); err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return errf("relabel secrets: rollback: %w", errors.Join(err2, err))
+		}
+
+		return errf("relabel secrets: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errf("relabel secrets: tx commit: %w", err)
+	}
+
+	vlt.emit(entries...)
+
+	return nil
+}
+
+// relabel applies the label change using the given store,
+// returning the audit entry recording it.
+func (vlt *Vault) relabel(ctx context.Context, store *vaultdb.VaultDB, c LabelChange) (vaultdb.AuditEntry, error) {
+	for _, l := range c.Remove {
+		if _, err := store.DeleteLabel(ctx, l, int64(c.ID)); err != nil {
+			return vaultdb.AuditEntry{}, errf("remove label: %w", err)
+		}
+	}
+
+	for _, l := range c.Add {
+		if _, err := store.InsertLabel(ctx, l, c.ID); err != nil {
+			return vaultdb.AuditEntry{}, errf("insert label: %w", err)
+		}
+	}
+
+	if err := vlt.record(ctx, store, oplogPut, c.ID); err != nil {
+		return vaultdb.AuditEntry{}, err
+	}
+
+	entry, err := vlt.audit(ctx, store, vaultdb.OpUpdateMetadata, c.ID)
+	if err != nil {
+		return vaultdb.AuditEntry{}, errf("audit: %w", err)
+	}
+
+	return entry, nil
+}
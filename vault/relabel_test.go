@@ -0,0 +1,85 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_RelabelSecrets(t *testing.T) {
+	v, err := New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	ids := make(map[string]int)
+
+	for name, labels := range map[string][]string{
+		"vpn": {"old/vpn", "keep"},
+		"db":  {"old/db", "new/db"},
+	} {
+		id, err := v.InsertNewSecret(t.Context(), name, "value", labels)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids[name] = id
+	}
+
+	labelsOf := func(name string) []string {
+		t.Helper()
+
+		secrets, err := v.FilterSecrets(t.Context(), "", name, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		labels := slices.Clone(secrets[ids[name]].Labels)
+		slices.Sort(labels)
+
+		return labels
+	}
+
+	if err := v.RelabelSecrets(t.Context(), []LabelChange{
+		{ID: ids["vpn"], Remove: []string{"old/vpn"}, Add: []string{"new/vpn"}},
+		{ID: ids["db"], Remove: []string{"old/db"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := labelsOf("vpn"), []string{"keep", "new/vpn"}; !slices.Equal(got, want) {
+		t.Errorf("vpn labels: got %v, want %v", got, want)
+	}
+
+	if got, want := labelsOf("db"), []string{"new/db"}; !slices.Equal(got, want) {
+		t.Errorf("db labels: got %v, want %v", got, want)
+	}
+
+	// changes are applied all or nothing.
+	if err := v.RelabelSecrets(t.Context(), []LabelChange{
+		{ID: ids["vpn"], Remove: []string{"keep"}, Add: []string{"kept"}},
+		{ID: ids["db"] + 100, Add: []string{"new/db"}},
+	}); err == nil {
+		t.Error("relabel of a missing secret: expected an error")
+	}
+
+	if got, want := labelsOf("vpn"), []string{"keep", "new/vpn"}; !slices.Equal(got, want) {
+		t.Errorf("vpn labels after failure: got %v, want %v", got, want)
+	}
+
+	// relabeled secrets must remain within the scope.
+	scoped := WithPrincipal(t.Context(), Principal{Scope: []string{"new/*"}})
+
+	err = v.RelabelSecrets(scoped, []LabelChange{{ID: ids["db"], Remove: []string{"new/db"}, Add: []string{"other/db"}}})
+	if !errors.Is(err, vaulterrors.ErrTokenScope) {
+		t.Errorf("relabel out of scope: got err %v, want %v", err, vaulterrors.ErrTokenScope)
+	}
+
+	if got, want := labelsOf("db"), []string{"new/db"}; !slices.Equal(got, want) {
+		t.Errorf("db labels after out of scope relabel: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+package vault
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// ScheduleRotation schedules the given secret to be rotated every given
+// interval, overriding the intervals of its labels.
+//
+// Rotation schedules are not part of the oplog, and as such are not synced.
+func (vlt *Vault) ScheduleRotation(ctx context.Context, id int, every time.Duration) error {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("schedule rotation: %w", err)
+	}
+
+	if err := vlt.checkScope(ctx, vlt.db, id); err != nil {
+		return errf("schedule rotation: %w", err)
+	}
+
+	if every < time.Second {
+		return errf("schedule rotation: invalid interval %s", every)
+	}
+
+	if err := vlt.db.SetRotationEvery(ctx, id, every); err != nil {
+		return errf("schedule rotation: %w", err)
+	}
+
+	return nil
+}
+
+// UnscheduleRotation deletes the rotation schedule of the given secret,
+// including the time its next rotation is due.
+func (vlt *Vault) UnscheduleRotation(ctx context.Context, id int) error {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("unschedule rotation: %w", err)
+	}
+
+	if err := vlt.checkScope(ctx, vlt.db, id); err != nil {
+		return errf("unschedule rotation: %w", err)
+	}
+
+	n, err := vlt.db.DeleteRotationSchedule(ctx, id)
+	if err != nil {
+		return errf("unschedule rotation: %w", err)
+	}
+
+	if n == 0 {
+		return errf("unschedule rotation: %w", vaulterrors.ErrRotationNotScheduled)
+	}
+
+	return nil
+}
+
+// PostponeRotation sets the time the next rotation of the given secret is
+// due, e.g., once rotated, or reminded of.
+func (vlt *Vault) PostponeRotation(ctx context.Context, id int, until time.Time) error {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("postpone rotation: %w", err)
+	}
+
+	if err := vlt.checkScope(ctx, vlt.db, id); err != nil {
+		return errf("postpone rotation: %w", err)
+	}
+
+	if err := vlt.db.SetRotationDue(ctx, id, until); err != nil {
+		return errf("postpone rotation: %w", err)
+	}
+
+	return nil
+}
+
+// RotationSchedules returns the rotation schedules of the secrets
+// not in the trash, keyed by secret id.
+func (vlt *Vault) RotationSchedules(ctx context.Context) (map[int]vaultdb.RotationSchedule, error) {
+	schedules, err := vlt.db.RotationSchedules(ctx)
+	if err != nil {
+		return nil, errf("rotation schedules: %w", err)
+	}
+
+	schedules, err = scoped(ctx, vlt, schedules)
+	if err != nil {
+		return nil, errf("rotation schedules: %w", err)
+	}
+
+	// secrets in the trash are not rotated.
+	trashed, err := vlt.db.TrashedAt(ctx)
+	if err != nil {
+		return nil, errf("rotation schedules: %w", err)
+	}
+
+	maps.DeleteFunc(schedules, func(id int, _ vaultdb.RotationSchedule) bool {
+		_, ok := trashed[id]
+		return ok
+	})
+
+	return schedules, nil
+}
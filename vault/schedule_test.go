@@ -0,0 +1,98 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_RotationSchedules(t *testing.T) {
+	v, err := New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	db, err := v.InsertNewSecret(t.Context(), "db", "value", []string{"prod/db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api, err := v.InsertNewSecret(t.Context(), "api", "value", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.ScheduleRotation(t.Context(), db, 30*24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	due := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := v.PostponeRotation(t.Context(), api, due); err != nil {
+		t.Fatal(err)
+	}
+
+	schedules, err := v.RotationSchedules(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := schedules[db]; got.Every != 30*24*time.Hour || !got.DueAt.IsZero() {
+		t.Errorf("db schedule: got %+v, want every 720h, not postponed", got)
+	}
+
+	if got := schedules[api]; got.Every != 0 || !got.DueAt.Equal(due) {
+		t.Errorf("api schedule: got %+v, want due at %s", got, due)
+	}
+
+	// postponing keeps the interval, and rescheduling keeps the due time.
+	if err := v.PostponeRotation(t.Context(), db, due); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.ScheduleRotation(t.Context(), api, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	schedules, err = v.RotationSchedules(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := schedules[db]; got.Every != 30*24*time.Hour || !got.DueAt.Equal(due) {
+		t.Errorf("db schedule: got %+v, want every 720h, due at %s", got, due)
+	}
+
+	if got := schedules[api]; got.Every != time.Hour || !got.DueAt.Equal(due) {
+		t.Errorf("api schedule: got %+v, want every 1h, due at %s", got, due)
+	}
+
+	if err := v.ScheduleRotation(t.Context(), db, 0); err == nil {
+		t.Error("schedule rotation: expected an error on a zero interval")
+	}
+
+	if err := v.UnscheduleRotation(t.Context(), api); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.UnscheduleRotation(t.Context(), api); !errors.Is(err, vaulterrors.ErrRotationNotScheduled) {
+		t.Errorf("unschedule rotation: got %v, want %v", err, vaulterrors.ErrRotationNotScheduled)
+	}
+
+	// schedules are deleted along with their secrets.
+	if _, err := v.DeleteSecretsByIDs(t.Context(), db); err != nil {
+		t.Fatal(err)
+	}
+
+	schedules, err = v.RotationSchedules(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(schedules) != 0 {
+		t.Errorf("schedules: got %v, want none", schedules)
+	}
+}
@@ -0,0 +1,83 @@
+package vault
+
+import (
+	"context"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// SaveSearch saves the search query under the given name,
+// replacing any query saved under it.
+//
+// Queries are stored as given, they are parsed by their users.
+// Saved searches are vault-wide, they cannot be saved if access is
+// restricted by a scope. They are not part of the oplog, and as such
+// are not synced.
+func (vlt *Vault) SaveSearch(ctx context.Context, name string, query string) error {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("save search: %w", err)
+	}
+
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return errf("save search: %w", err)
+	}
+
+	if len(name) == 0 {
+		return errf("save search: %w", vaulterrors.ErrEmptyName)
+	}
+
+	if err := vlt.db.SetSavedSearch(ctx, name, query); err != nil {
+		return errf("save search: %w", err)
+	}
+
+	return nil
+}
+
+// SavedSearches returns the saved searches, ordered by name.
+func (vlt *Vault) SavedSearches(ctx context.Context) ([]vaultdb.SavedSearch, error) {
+	searches, err := vlt.db.SavedSearches(ctx)
+	if err != nil {
+		return nil, errf("saved searches: %w", err)
+	}
+
+	return searches, nil
+}
+
+// SavedSearch returns the search saved under the given name.
+func (vlt *Vault) SavedSearch(ctx context.Context, name string) (vaultdb.SavedSearch, error) {
+	searches, err := vlt.db.SavedSearches(ctx)
+	if err != nil {
+		return vaultdb.SavedSearch{}, errf("saved search: %w", err)
+	}
+
+	for _, ss := range searches {
+		if ss.Name == name {
+			return ss, nil
+		}
+	}
+
+	return vaultdb.SavedSearch{}, errf("saved search: %q: %w", name, vaulterrors.ErrSavedSearchNotFound)
+}
+
+// DeleteSavedSearch deletes the search saved under the given name.
+func (vlt *Vault) DeleteSavedSearch(ctx context.Context, name string) error {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("delete saved search: %w", err)
+	}
+
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return errf("delete saved search: %w", err)
+	}
+
+	n, err := vlt.db.DeleteSavedSearch(ctx, name)
+	if err != nil {
+		return errf("delete saved search: %w", err)
+	}
+
+	if n == 0 {
+		return errf("delete saved search: %q: %w", name, vaulterrors.ErrSavedSearchNotFound)
+	}
+
+	return nil
+}
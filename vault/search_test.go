@@ -0,0 +1,61 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_SavedSearches(t *testing.T) {
+	v, err := New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	if err := v.SaveSearch(t.Context(), "work-active", "label:work/*"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.SaveSearch(t.Context(), "stale", "modified:>90d"); err != nil {
+		t.Fatal(err)
+	}
+
+	// saving under the same name replaces the query.
+	if err := v.SaveSearch(t.Context(), "work-active", "label:work/* -label:archive"); err != nil {
+		t.Fatal(err)
+	}
+
+	searches, err := v.SavedSearches(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(searches) != 2 || searches[0].Name != "stale" || searches[1].Name != "work-active" {
+		t.Fatalf("saved searches: got %v, want stale and work-active", searches)
+	}
+
+	ss, err := v.SavedSearch(t.Context(), "work-active")
+	if err != nil || ss.Query != "label:work/* -label:archive" {
+		t.Errorf("saved search: got %q, %v", ss.Query, err)
+	}
+
+	if err := v.DeleteSavedSearch(t.Context(), "stale"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.SavedSearch(t.Context(), "stale"); !errors.Is(err, vaulterrors.ErrSavedSearchNotFound) {
+		t.Errorf("deleted saved search: got err %v, want %v", err, vaulterrors.ErrSavedSearchNotFound)
+	}
+
+	if err := v.DeleteSavedSearch(t.Context(), "stale"); !errors.Is(err, vaulterrors.ErrSavedSearchNotFound) {
+		t.Errorf("delete missing saved search: got err %v, want %v", err, vaulterrors.ErrSavedSearchNotFound)
+	}
+
+	ctx := WithPrincipal(t.Context(), Principal{Scope: []string{"ci/*"}})
+	if err := v.SaveSearch(ctx, "ci", "label:ci/*"); !errors.Is(err, vaulterrors.ErrTokenScope) {
+		t.Errorf("scoped save search: got err %v, want %v", err, vaulterrors.ErrTokenScope)
+	}
+}
@@ -0,0 +1,133 @@
+package vault
+
+import (
+	"context"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+// CopySecrets copies the secrets identified by ids into dst, along with
+// their labels, links, attachments, and the metadata of their labels.
+//
+// Links are copied along with their cached values, without being resolved.
+// Copying is recorded as an export in the audit log of the vault.
+//
+// Returns the IDs of the copies, mapped by the IDs of the copied secrets.
+func (vlt *Vault) CopySecrets(ctx context.Context, dst *Vault, ids ...int) (map[int]int, error) {
+	if err := vlt.checkScope(ctx, vlt.db, ids...); err != nil {
+		return nil, errf("copy secrets: %w", err)
+	}
+
+	secrets, err := vlt.db.SecretsByIDs(ctx, ids)
+	if err != nil {
+		return nil, errf("copy secrets: %w", err)
+	}
+
+	links, err := vlt.db.Links(ctx)
+	if err != nil {
+		return nil, errf("copy secrets: %w", err)
+	}
+
+	metas, err := vlt.db.LabelMetas(ctx)
+	if err != nil {
+		return nil, errf("copy secrets: %w", err)
+	}
+
+	copies := make(map[int]int, len(secrets))
+	labels := make(map[string]struct{})
+
+	for _, id := range slices.Sorted(maps.Keys(secrets)) {
+		s := secrets[id]
+
+		if copies[id], err = vlt.copySecret(ctx, dst, id, s); err != nil {
+			return nil, errf("copy secrets: %q: %w", s.Name, err)
+		}
+
+		for _, l := range s.Labels {
+			labels[l] = struct{}{}
+		}
+	}
+
+	for _, l := range links {
+		if c, ok := copies[l.SecretID]; ok {
+			l.SecretID = c
+			if err := dst.db.InsertLink(ctx, l); err != nil {
+				return nil, errf("copy secrets: link: %w", err)
+			}
+		}
+	}
+
+	for _, m := range metas {
+		if _, ok := labels[m.Name]; ok {
+			if err := dst.SetLabelMeta(ctx, m); err != nil {
+				return nil, errf("copy secrets: %w", err)
+			}
+		}
+	}
+
+	entry, err := vlt.auditRead(ctx, vaultdb.OpExport, 0, false)
+	if err != nil {
+		return nil, errf("copy secrets: audit: %w", err)
+	}
+
+	vlt.emit(entry)
+
+	return copies, nil
+}
+
+// copySecret copies the secret, its labels, metadata and attachments into dst,
+// returning the ID of the copy.
+func (vlt *Vault) copySecret(ctx context.Context, dst *Vault, id int, s vaultdb.SecretWithLabels) (int, error) {
+	value, err := vlt.openValue(ctx, vlt.db, id)
+	if err != nil {
+		return 0, err
+	}
+
+	metas, err := vlt.db.SecretMetas(ctx, []int{id})
+	if err != nil {
+		return 0, err
+	}
+
+	copied, err := dst.InsertSecretWithMeta(ctx, s.Name, string(value), s.Labels, metas[id])
+	if err != nil {
+		return 0, err
+	}
+
+	attachments, err := vlt.attachments(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, a := range attachments {
+		if err := vlt.copyAttachment(ctx, dst, id, copied, a.Name); err != nil {
+			return 0, err
+		}
+	}
+
+	return copied, nil
+}
+
+// copyAttachment streams the named attachment of the secret into an
+// attachment of the copy in dst.
+func (vlt *Vault) copyAttachment(ctx context.Context, dst *Vault, id int, copied int, name string) error {
+	pr, pw := io.Pipe()
+	written := make(chan error, 1)
+
+	go func() {
+		_, err := vlt.WriteAttachment(ctx, id, name, pw)
+		_ = pw.CloseWithError(err)
+		written <- err
+	}()
+
+	_, err := dst.Attach(ctx, copied, name, pr)
+	_ = pr.Close() // unblocks the writer if the attachment was not read fully.
+
+	if errw := <-written; err == nil {
+		err = errw
+	}
+
+	return err
+}
@@ -0,0 +1,108 @@
+package vault
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaultcrypto"
+)
+
+func TestVault_CopySecrets(t *testing.T) {
+	ctx := t.Context()
+	r := &mapResolver{values: map[string]string{"hv://kv/api": "key"}}
+
+	src, err := New(ctx, filepath.Join(t.TempDir(), "src.db"), "password", WithResolver(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = src.Close(ctx) }() //nolint:wsl
+
+	dst, err := New(ctx, filepath.Join(t.TempDir(), "dst.db"), "other password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = dst.Close(ctx) }() //nolint:wsl
+
+	vpn, err := src.InsertNewSecret(ctx, "vpn", "v", []string{"personal/vpn", "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := src.InsertNewSecret(ctx, "work", "w", []string{"work"}); err != nil {
+		t.Fatal(err)
+	}
+
+	api, err := src.InsertLink(ctx, "api", "hv://kv/api", time.Hour, []string{"personal/api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := vaultcrypto.RandBytes(2*attachmentChunkSize + 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := src.Attach(ctx, vpn, "config", bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range []vaultdb.LabelMeta{{Name: "other", Color: "red"}, {Name: "work", Color: "blue"}} {
+		if err := src.SetLabelMeta(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	copies, err := src.CopySecrets(ctx, dst, vpn, api)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets, err := dst.ExportSecrets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secrets) != 2 {
+		t.Fatalf("copied secrets: got %v, want vpn and api", secrets)
+	}
+
+	got := secrets[copies[vpn]]
+	slices.Sort(got.Labels)
+
+	if got.Name != "vpn" || got.Value != "v" || !slices.Equal(got.Labels, []string{"other", "personal/vpn"}) {
+		t.Errorf("copied vpn: got %+v", got)
+	}
+
+	var buf bytes.Buffer
+	if _, err := dst.WriteAttachment(ctx, copies[vpn], "config", &buf); err != nil || !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("copied attachment: got %d bytes, err %v, want %d bytes", buf.Len(), err, len(data))
+	}
+
+	// links are copied along with their cached values.
+	links, err := dst.Links(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(links) != 1 || links[0].SecretID != copies[api] || links[0].URI != "hv://kv/api" || links[0].TTL != time.Hour {
+		t.Errorf("copied links: got %v", links)
+	}
+
+	if secrets[copies[api]].Value != "key" {
+		t.Errorf("copied link value: got %q, want %q", secrets[copies[api]].Value, "key")
+	}
+
+	// only the metadata of the copied labels is copied.
+	metas, err := dst.LabelMetas(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []vaultdb.LabelMeta{{Name: "other", Color: "red"}}; !slices.Equal(metas, want) {
+		t.Errorf("copied label metas: got %v, want %v", metas, want)
+	}
+}
@@ -0,0 +1,224 @@
+package vaultcontainer
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+)
+
+// Attachment is a stored attachment record, without its chunks.
+type Attachment struct {
+	ID             int
+	SecretID       int
+	NameNonce      []byte
+	NameCiphertext []byte
+	Size           int64
+	CreatedAt      time.Time
+}
+
+// AttachmentChunk is an encrypted chunk of an attachment.
+type AttachmentChunk struct {
+	Seq        int
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+const insertAttachment = `
+	INSERT INTO
+		attachments (secret_id, name_nonce, name_ciphertext)
+	VALUES
+		(?, ?, ?)
+`
+
+// InsertAttachment inserts an empty attachment of the given secret,
+// returning its id.
+func (vc *VaultContainer) InsertAttachment(ctx context.Context, secretID int, nameNonce []byte, nameCiphertext []byte) (int, error) {
+	res, err := vc.db.ExecContext(ctx, insertAttachment, secretID, nameNonce, nameCiphertext)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+
+	return int(id), err
+}
+
+const insertAttachmentChunk = `
+	INSERT INTO
+		attachment_chunks (attachment_id, seq, nonce, ciphertext)
+	VALUES
+		(?, ?, ?, ?)
+`
+
+func (vc *VaultContainer) InsertAttachmentChunk(ctx context.Context, attachmentID int, c AttachmentChunk) error {
+	_, err := vc.db.ExecContext(ctx, insertAttachmentChunk, attachmentID, c.Seq, c.Nonce, c.Ciphertext)
+	return err
+}
+
+// SetAttachmentSize sets the size of the given attachment, once its chunks are stored.
+func (vc *VaultContainer) SetAttachmentSize(ctx context.Context, id int, size int64) error {
+	_, err := vc.db.ExecContext(ctx, "UPDATE attachments SET size = ? WHERE id = ?", size, id)
+	return err
+}
+
+const selectAttachments = `
+	SELECT
+		id, secret_id, name_nonce, name_ciphertext, size, created_at
+	FROM
+		attachments
+	WHERE
+		secret_id = ?
+	ORDER BY
+		id
+`
+
+// Attachments returns the attachments of the given secret, ordered by id.
+func (vc *VaultContainer) Attachments(ctx context.Context, secretID int) ([]Attachment, error) {
+	rows, err := vc.db.QueryContext(ctx, selectAttachments, secretID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var attachments []Attachment
+
+	for rows.Next() {
+		var (
+			a         Attachment
+			createdAt string
+		)
+
+		if err := rows.Scan(&a.ID, &a.SecretID, &a.NameNonce, &a.NameCiphertext, &a.Size, &createdAt); err != nil {
+			return nil, err
+		}
+
+		if a.CreatedAt, err = time.Parse(time.DateTime, createdAt); err != nil {
+			return nil, err
+		}
+
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+const selectAttachmentChunks = `
+	SELECT
+		seq, nonce, ciphertext
+	FROM
+		attachment_chunks
+	WHERE
+		attachment_id = ?
+	ORDER BY
+		seq
+`
+
+// EachAttachmentChunk calls fn with the chunks of the given attachment
+// ordered by sequence, one at a time, stopping at the first error.
+func (vc *VaultContainer) EachAttachmentChunk(ctx context.Context, attachmentID int, fn func(AttachmentChunk) error) error {
+	rows, err := vc.db.QueryContext(ctx, selectAttachmentChunks, attachmentID)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	for rows.Next() {
+		var c AttachmentChunk
+		if err := rows.Scan(&c.Seq, &c.Nonce, &c.Ciphertext); err != nil {
+			return err
+		}
+
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// DeleteAttachments deletes the attachments with the given ids along with
+// their chunks, returning the number of deleted attachments.
+func (vc *VaultContainer) DeleteAttachments(ctx context.Context, ids []int) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+
+	if _, err := vc.db.ExecContext(ctx, "DELETE FROM attachment_chunks WHERE attachment_id IN ("+placeholders+")", cmdutil.ToAnySlice(ids)...); err != nil {
+		return 0, err
+	}
+
+	res, err := vc.db.ExecContext(ctx, "DELETE FROM attachments WHERE id IN ("+placeholders+")", cmdutil.ToAnySlice(ids)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// DeleteSecretsAttachments deletes the attachments of the given secrets
+// along with their chunks.
+func (vc *VaultContainer) DeleteSecretsAttachments(ctx context.Context, secretIDs []int) error {
+	if len(secretIDs) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(secretIDs)), ",")
+
+	if _, err := vc.db.ExecContext(ctx, `DELETE FROM attachment_chunks WHERE attachment_id IN (
+		SELECT id FROM attachments WHERE secret_id IN (`+placeholders+`))`, cmdutil.ToAnySlice(secretIDs)...); err != nil {
+		return err
+	}
+
+	_, err := vc.db.ExecContext(ctx, "DELETE FROM attachments WHERE secret_id IN ("+placeholders+")", cmdutil.ToAnySlice(secretIDs)...)
+
+	return err
+}
+
+// DeleteAllAttachments deletes all attachments along with their chunks.
+func (vc *VaultContainer) DeleteAllAttachments(ctx context.Context) error {
+	if _, err := vc.db.ExecContext(ctx, "DELETE FROM attachment_chunks"); err != nil {
+		return err
+	}
+
+	_, err := vc.db.ExecContext(ctx, "DELETE FROM attachments")
+
+	return err
+}
+
+// AllAttachments returns the attachments of all secrets, ordered by id.
+func (vc *VaultContainer) AllAttachments(ctx context.Context) ([]Attachment, error) {
+	rows, err := vc.db.QueryContext(ctx, "SELECT id, secret_id, name_nonce, name_ciphertext FROM attachments ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var attachments []Attachment
+
+	for rows.Next() {
+		var a Attachment
+		if err := rows.Scan(&a.ID, &a.SecretID, &a.NameNonce, &a.NameCiphertext); err != nil {
+			return nil, err
+		}
+
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+// UpdateAttachmentName replaces the encrypted name of the given attachment.
+func (vc *VaultContainer) UpdateAttachmentName(ctx context.Context, id int, nameNonce []byte, nameCiphertext []byte) error {
+	_, err := vc.db.ExecContext(ctx, "UPDATE attachments SET name_nonce = ?, name_ciphertext = ? WHERE id = ?", nameNonce, nameCiphertext, id)
+	return err
+}
+
+// UpdateAttachmentChunk replaces the nonce and ciphertext of the given chunk.
+func (vc *VaultContainer) UpdateAttachmentChunk(ctx context.Context, attachmentID int, c AttachmentChunk) error {
+	_, err := vc.db.ExecContext(ctx, "UPDATE attachment_chunks SET nonce = ?, ciphertext = ? WHERE attachment_id = ? AND seq = ?", c.Nonce, c.Ciphertext, attachmentID, c.Seq)
+	return err
+}
@@ -0,0 +1,133 @@
+package vaultcontainer
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+)
+
+// Token is a stored access token record.
+//
+// The token secret itself is never stored, only the payload sealed with it.
+type Token struct {
+	ID        string
+	Scope     []string
+	ReadOnly  bool
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	Nonce     []byte
+	Sealed    []byte
+}
+
+const insertToken = `
+	INSERT INTO
+		vault_tokens (id, scope, read_only, expires_at, nonce, sealed)
+	VALUES
+		(?, ?, ?, ?, ?, ?)
+`
+
+func (vc *VaultContainer) InsertToken(ctx context.Context, t Token) error {
+	scope, err := json.Marshal(t.Scope)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := t.ExpiresAt.UTC().Format(time.DateTime)
+
+	_, err = vc.db.ExecContext(ctx, insertToken, t.ID, string(scope), t.ReadOnly, expiresAt, t.Nonce, t.Sealed)
+
+	return err
+}
+
+const selectTokens = `
+	SELECT
+		id, scope, read_only, expires_at, created_at, nonce, sealed
+	FROM
+		vault_tokens
+`
+
+// Token returns the token with the given id, or [sql.ErrNoRows] if it does not exist.
+func (vc *VaultContainer) Token(ctx context.Context, id string) (*Token, error) {
+	row := vc.db.QueryRowContext(ctx, selectTokens+" WHERE id = ?", id)
+
+	t, err := scanToken(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Tokens returns all stored tokens, ordered by creation time.
+func (vc *VaultContainer) Tokens(ctx context.Context) ([]Token, error) {
+	rows, err := vc.db.QueryContext(ctx, selectTokens+" ORDER BY created_at, id")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var tokens []Token
+
+	for rows.Next() {
+		t, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, *t)
+	}
+
+	return tokens, rows.Err()
+}
+
+// DeleteTokens deletes the tokens with the given ids,
+// returning the number of deleted tokens.
+func (vc *VaultContainer) DeleteTokens(ctx context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+
+	res, err := vc.db.ExecContext(ctx, "DELETE FROM vault_tokens WHERE id IN ("+placeholders+")", cmdutil.ToAnySlice(ids)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanToken(row scanner) (*Token, error) {
+	var (
+		t                    Token
+		scope                string
+		expiresAt, createdAt string
+	)
+
+	if err := row.Scan(&t.ID, &scope, &t.ReadOnly, &expiresAt, &createdAt, &t.Nonce, &t.Sealed); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(scope), &t.Scope); err != nil {
+		return nil, err
+	}
+
+	var err error
+
+	if t.ExpiresAt, err = time.Parse(time.DateTime, expiresAt); err != nil {
+		return nil, err
+	}
+
+	if t.CreatedAt, err = time.Parse(time.DateTime, createdAt); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
@@ -137,3 +137,23 @@ func (vc *VaultContainer) pruneVaultHistory(ctx context.Context) error {
 	_, err := vc.db.ExecContext(ctx, pruneHistory)
 	return err
 }
+
+// DeleteVaultHistory deletes the previous versions of the encrypted vault.
+func (vc *VaultContainer) DeleteVaultHistory(ctx context.Context) error {
+	_, err := vc.db.ExecContext(ctx, "DELETE FROM vault_history")
+	return err
+}
+
+// ShredVaultHistory deletes the previous versions of the encrypted vault,
+// and rebuilds the database file, so that they do not linger in free pages.
+//
+// It must not be called within a transaction.
+func (vc *VaultContainer) ShredVaultHistory(ctx context.Context) error {
+	if err := vc.DeleteVaultHistory(ctx); err != nil {
+		return err
+	}
+
+	_, err := vc.db.ExecContext(ctx, "VACUUM")
+
+	return err
+}
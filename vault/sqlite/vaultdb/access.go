@@ -0,0 +1,104 @@
+package vaultdb
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+)
+
+const insertSecretAccess = `
+	INSERT INTO
+		secret_access (secret_id)
+	VALUES
+		(?)
+`
+
+const pruneSecretAccess = `
+	DELETE FROM secret_access
+	WHERE
+		secret_id = ?
+		AND rowid NOT IN (
+			SELECT
+				rowid
+			FROM
+				secret_access
+			WHERE
+				secret_id = ?
+			ORDER BY
+				rowid DESC
+			LIMIT
+				?
+		)
+`
+
+// RecordSecretAccess records an access to the secret value now,
+// keeping the given number of most recent accesses of the secret.
+func (s *VaultDB) RecordSecretAccess(ctx context.Context, secretID int, keep int) error {
+	if _, err := s.db.ExecContext(ctx, insertSecretAccess, secretID); err != nil {
+		return err
+	}
+
+	_, err := s.db.ExecContext(ctx, pruneSecretAccess, secretID, secretID, keep)
+
+	return err
+}
+
+const selectSecretAccesses = `
+	SELECT
+		secret_id, accessed_at
+	FROM
+		secret_access
+`
+
+// SecretAccesses returns the recorded access times of the secret values,
+// keyed by secret id.
+func (s *VaultDB) SecretAccesses(ctx context.Context) (map[int][]time.Time, error) {
+	rows, err := s.db.QueryContext(ctx, selectSecretAccesses)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	accesses := make(map[int][]time.Time)
+
+	for rows.Next() {
+		var (
+			id         int
+			accessedAt time.Time
+		)
+
+		if err := rows.Scan(&id, &accessedAt); err != nil {
+			return nil, err
+		}
+
+		accesses[id] = append(accesses[id], accessedAt)
+	}
+
+	return accesses, rows.Err()
+}
+
+// DeleteSecretAccesses deletes the recorded accesses of the given secrets,
+// or of all secrets if none are given, returning the number of deleted rows.
+func (s *VaultDB) DeleteSecretAccesses(ctx context.Context, ids []int) (int64, error) {
+	query := `DELETE FROM secret_access`
+
+	if len(ids) > 0 {
+		placeholders := make([]string, len(ids))
+		for i := range ids {
+			placeholders[i] = "?"
+		}
+
+		query += `
+	WHERE
+		secret_id IN (` + strings.Join(placeholders, ",") + ")"
+	}
+
+	res, err := s.db.ExecContext(ctx, query, cmdutil.ToAnySlice(ids)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,114 @@
+package vaultdb
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+)
+
+// APIToken represents a stored API token record.
+//
+// Only the SHA-256 hash of the token secret is stored.
+type APIToken struct {
+	ID        string
+	Name      string
+	Hash      []byte
+	Scope     []string
+	ReadOnly  bool
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+const insertAPIToken = `
+	INSERT INTO
+		api_tokens (id, name, hash, scope, read_only, expires_at)
+	VALUES
+		(?, ?, ?, ?, ?, ?)
+	RETURNING
+		created_at
+`
+
+// InsertAPIToken stores the given token, returning its creation time.
+func (s *VaultDB) InsertAPIToken(ctx context.Context, t APIToken) (time.Time, error) {
+	scope, err := json.Marshal(t.Scope)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var createdAt time.Time
+
+	err = s.db.QueryRowContext(ctx, insertAPIToken, t.ID, t.Name, t.Hash, string(scope), t.ReadOnly, t.ExpiresAt.UTC()).Scan(&createdAt)
+
+	return createdAt, err
+}
+
+const selectAPITokens = `
+	SELECT
+		id, name, hash, scope, read_only, expires_at, created_at
+	FROM
+		api_tokens
+`
+
+// APIToken returns the token with the given id, or [sql.ErrNoRows] if it does not exist.
+func (s *VaultDB) APIToken(ctx context.Context, id string) (APIToken, error) {
+	return scanAPIToken(s.db.QueryRowContext(ctx, selectAPITokens+" WHERE id = ?", id))
+}
+
+// APITokens returns all stored tokens, ordered by creation time.
+func (s *VaultDB) APITokens(ctx context.Context) ([]APIToken, error) {
+	rows, err := s.db.QueryContext(ctx, selectAPITokens+" ORDER BY created_at, id")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var tokens []APIToken
+
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
+// DeleteAPITokens deletes the tokens with the given ids,
+// returning the number of deleted tokens.
+func (s *VaultDB) DeleteAPITokens(ctx context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM api_tokens WHERE id IN ("+placeholders+")", cmdutil.ToAnySlice(ids)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func scanAPIToken(row scanner) (APIToken, error) {
+	var (
+		t     APIToken
+		scope string
+	)
+
+	if err := row.Scan(&t.ID, &t.Name, &t.Hash, &scope, &t.ReadOnly, &t.ExpiresAt, &t.CreatedAt); err != nil {
+		return APIToken{}, err
+	}
+
+	if err := json.Unmarshal([]byte(scope), &t.Scope); err != nil {
+		return APIToken{}, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,303 @@
+package vaultdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"iter"
+	"strings"
+	"time"
+)
+
+// Operation identifies a vault operation recorded in the audit log.
+type Operation string
+
+const (
+	OpInsert         Operation = "insert"
+	OpShow           Operation = "show"
+	OpUpdate         Operation = "update"
+	OpUpdateMetadata Operation = "update_metadata"
+	OpDelete         Operation = "delete"
+	OpExport         Operation = "export"
+)
+
+const insertSecretAuditEntry = `
+	INSERT INTO
+		audit_log (operation, secret_id, secret_name, actor)
+	SELECT
+		?, id, name, NULLIF(?, '')
+	FROM
+		secrets
+	WHERE
+		id = ?
+	RETURNING
+		id, operation, secret_id, secret_name, actor, created_at, prev_hash, hash
+`
+
+const insertAuditEntry = `
+	INSERT INTO
+		audit_log (operation, actor)
+	VALUES
+		(?, NULLIF(?, ''))
+	RETURNING
+		id, operation, secret_id, secret_name, actor, created_at, prev_hash, hash
+`
+
+// InsertAuditEntry records the given operation in the audit log
+// and returns the inserted entry.
+//
+// If secretID is positive, the entry references the secret
+// and captures its current name. If no such secret exists, there is
+// nothing to audit, and the zero entry is returned.
+// The actor is empty for operations performed by the vault owner.
+// Secret values are never recorded.
+func (s *VaultDB) InsertAuditEntry(ctx context.Context, op Operation, secretID int, actor string) (AuditEntry, error) {
+	if secretID > 0 {
+		entry, err := scanAuditEntry(s.db.QueryRowContext(ctx, insertSecretAuditEntry, op, actor, secretID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return AuditEntry{}, nil
+		}
+
+		return entry, err
+	}
+
+	return scanAuditEntry(s.db.QueryRowContext(ctx, insertAuditEntry, op, actor))
+}
+
+const selectLastAuditHash = `
+	SELECT
+		hash
+	FROM
+		audit_log
+	ORDER BY
+		id DESC
+	LIMIT
+		1
+`
+
+// LastAuditHash returns the chain hash of the most recent audit log entry.
+//
+// It returns nil if the log is empty or the last entry is unchained.
+func (s *VaultDB) LastAuditHash(ctx context.Context) ([]byte, error) {
+	var hash []byte
+
+	err := s.db.QueryRowContext(ctx, selectLastAuditHash).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return hash, err
+}
+
+// AuditEntryHash returns the chain hash of the audit log entry with the given id,
+// or [sql.ErrNoRows] if it does not exist.
+func (s *VaultDB) AuditEntryHash(ctx context.Context, id int) ([]byte, error) {
+	var hash []byte
+	err := s.db.QueryRowContext(ctx, "SELECT hash FROM audit_log WHERE id = ?", id).Scan(&hash)
+
+	return hash, err
+}
+
+const updateAuditEntryHash = `
+	UPDATE audit_log
+	SET
+		prev_hash = ?,
+		hash = ?
+	WHERE
+		id = ?
+`
+
+// SetAuditEntryHash sets the chain hashes of the given audit log entry.
+func (s *VaultDB) SetAuditEntryHash(ctx context.Context, id int, prevHash []byte, hash []byte) error {
+	_, err := s.db.ExecContext(ctx, updateAuditEntryHash, prevHash, hash, id)
+	return err
+}
+
+// AuditEntry represents a single audit log record.
+type AuditEntry struct {
+	ID         int
+	Operation  Operation
+	SecretID   int    // SecretID is zero if the operation is not secret specific.
+	SecretName string // SecretName is the name of the secret at the time of the operation.
+	Actor      string // Actor identifies who performed the operation, empty for the vault owner.
+	CreatedAt  time.Time
+	PrevHash   []byte // PrevHash is the chain hash of the preceding entry.
+	Hash       []byte // Hash is the chain hash of this entry, nil for unchained entries.
+}
+
+// AuditFilters defines criteria for querying the audit log.
+type AuditFilters struct {
+	// Since filters out entries created before the given time.
+	// The zero value matches all entries.
+	Since time.Time
+
+	// Operations filters entries by operation type.
+	// Multiple operations are ORed.
+	Operations []Operation
+
+	// Secret filters entries by secret name.
+	// Supports UNIX glob-style wildcard matching.
+	Secret string
+
+	// AfterID filters out entries with an id up to the given one.
+	AfterID int
+}
+
+// AuditLog returns an iterator over the audit log entries matching the
+// given filters, in chain (insertion) order.
+//
+// Rows are read lazily, iteration stops at the first error.
+func (s *VaultDB) AuditLog(ctx context.Context, f AuditFilters) iter.Seq2[AuditEntry, error] {
+	query := `
+	SELECT
+		id,
+		operation,
+		secret_id,
+		secret_name,
+		actor,
+		created_at,
+		prev_hash,
+		hash
+	FROM
+		audit_log
+	`
+
+	var (
+		args         []any
+		whereClauses []string
+	)
+
+	if !f.Since.IsZero() {
+		whereClauses = append(whereClauses, "created_at >= ?")
+		args = append(args, f.Since.UTC().Format(time.DateTime))
+	}
+
+	if len(f.Operations) > 0 {
+		placeholders := make([]string, len(f.Operations))
+		for i, op := range f.Operations {
+			placeholders[i] = "?"
+			args = append(args, op) //nolint:wsl
+		}
+
+		whereClauses = append(whereClauses, "operation IN ("+strings.Join(placeholders, ",")+")")
+	}
+
+	if len(f.Secret) > 0 {
+		whereClauses = append(whereClauses, "secret_name GLOB ?")
+		args = append(args, f.Secret)
+	}
+
+	if f.AfterID > 0 {
+		whereClauses = append(whereClauses, "id > ?")
+		args = append(args, f.AfterID)
+	}
+
+	if len(whereClauses) > 0 {
+		query += " WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	query += " ORDER BY id"
+
+	return func(yield func(AuditEntry, error) bool) {
+		rows, err := s.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			yield(AuditEntry{}, err)
+			return
+		}
+		defer func() { _ = rows.Close() }() //nolint:wsl
+
+		for rows.Next() {
+			entry, err := scanAuditEntry(rows)
+			if err != nil {
+				yield(AuditEntry{}, err)
+				return
+			}
+
+			if !yield(entry, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(AuditEntry{}, err)
+		}
+	}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAuditEntry(row scanner) (AuditEntry, error) {
+	var (
+		entry AuditEntry
+		id    sql.NullInt64
+		name  sql.NullString
+		actor sql.NullString
+	)
+
+	if err := row.Scan(&entry.ID, &entry.Operation, &id, &name, &actor, &entry.CreatedAt, &entry.PrevHash, &entry.Hash); err != nil {
+		return AuditEntry{}, err
+	}
+
+	entry.SecretID, entry.SecretName, entry.Actor = int(id.Int64), name.String, actor.String
+
+	return entry, nil
+}
+
+// AuditCheckpoint is a signed attestation of the audit log chain hash
+// at a given entry.
+type AuditCheckpoint struct {
+	ID        int
+	EntryID   int
+	Hash      []byte
+	Signer    string
+	Signature []byte
+	CreatedAt time.Time
+}
+
+const insertAuditCheckpoint = `
+	INSERT INTO
+		audit_checkpoints (entry_id, hash, signer, signature)
+	VALUES
+		(?, ?, ?, ?)
+`
+
+func (s *VaultDB) InsertAuditCheckpoint(ctx context.Context, entryID int, hash []byte, signer string, signature []byte) error {
+	_, err := s.db.ExecContext(ctx, insertAuditCheckpoint, entryID, hash, signer, signature)
+	return err
+}
+
+const selectAuditCheckpoints = `
+	SELECT
+		id, entry_id, hash, signer, signature, created_at
+	FROM
+		audit_checkpoints
+	ORDER BY
+		entry_id, id
+`
+
+// AuditCheckpoints returns all audit checkpoints ordered by entry id.
+func (s *VaultDB) AuditCheckpoints(ctx context.Context) ([]AuditCheckpoint, error) {
+	rows, err := s.db.QueryContext(ctx, selectAuditCheckpoints)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var checkpoints []AuditCheckpoint
+	for rows.Next() {
+		var c AuditCheckpoint
+		if err := rows.Scan(&c.ID, &c.EntryID, &c.Hash, &c.Signer, &c.Signature, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		checkpoints = append(checkpoints, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return checkpoints, nil
+}
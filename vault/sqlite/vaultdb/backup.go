@@ -0,0 +1,336 @@
+package vaultdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// BackupSecret holds all the stored fields of a secret, as captured by a backup.
+type BackupSecret struct {
+	ID         int
+	UID        string
+	Name       string
+	Nonce      []byte
+	Ciphertext []byte
+	Labels     []string
+	Chunks     []SecretChunk // Chunks is nil if the value is stored inline.
+	URL        string
+	Username   string
+	Notes      string
+	Kind       string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time // UpdatedAt is zero if the secret was never updated.
+}
+
+// MaxAuditID returns the id of the most recent audit log entry, or zero if the log is empty.
+func (s *VaultDB) MaxAuditID(ctx context.Context) (int, error) {
+	var id int
+	err := s.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(id), 0) FROM audit_log").Scan(&id)
+
+	return id, err
+}
+
+const countSecretChanges = `
+	SELECT
+		COUNT(*)
+	FROM
+		audit_log
+	WHERE
+		id > ?
+		AND secret_id IS NOT NULL
+		AND operation IN ('insert', 'update', 'update_metadata', 'delete')
+`
+
+// CountSecretChanges returns the number of secret inserts, updates and
+// deletes recorded in the audit log after the entry with the given id.
+func (s *VaultDB) CountSecretChanges(ctx context.Context, afterAuditID int) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, countSecretChanges, afterAuditID).Scan(&n)
+
+	return n, err
+}
+
+const selectChangedSecretIDs = `
+	SELECT DISTINCT
+		secret_id
+	FROM
+		audit_log
+	WHERE
+		id > ?
+		AND secret_id IS NOT NULL
+		AND operation IN ('insert', 'update', 'update_metadata', 'delete')
+	ORDER BY
+		secret_id
+`
+
+// ChangedSecretIDs returns the ids of the secrets inserted, updated or
+// deleted after the audit log entry with the given id.
+//
+// Ids of deleted secrets are included, and may have been reused since.
+func (s *VaultDB) ChangedSecretIDs(ctx context.Context, afterAuditID int) ([]int, error) {
+	rows, err := s.db.QueryContext(ctx, selectChangedSecretIDs, afterAuditID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var ids []int
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// BackupSecrets returns the secrets with the given ids, ordered by id.
+// Ids of secrets that do not exist, or are in the trash, are ignored.
+func (s *VaultDB) BackupSecrets(ctx context.Context, ids []int) ([]BackupSecret, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	//nolint:gosec // placeholders only.
+	query := `
+	SELECT
+		s.id,
+		s.uid,
+		s.name,
+		s.nonce,
+		s.ciphertext,
+		s.url,
+		s.username,
+		s.notes,
+		s.kind,
+		s.created_at,
+		s.updated_at,
+		l.name
+	FROM
+		secrets s
+		` + joinLabels + `
+	WHERE
+		s.deleted_at IS NULL
+		AND s.id IN (` + strings.Join(placeholders, ",") + `)
+	ORDER BY
+		s.id, l.name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var secrets []BackupSecret
+
+	for rows.Next() {
+		var (
+			secret    BackupSecret
+			updatedAt sql.NullTime
+			label     sql.NullString
+		)
+
+		if err := rows.Scan(&secret.ID, &secret.UID, &secret.Name, &secret.Nonce, &secret.Ciphertext, &secret.URL, &secret.Username, &secret.Notes, &secret.Kind, &secret.CreatedAt, &updatedAt, &label); err != nil {
+			return nil, err
+		}
+
+		if n := len(secrets); n == 0 || secrets[n-1].ID != secret.ID {
+			secret.UpdatedAt = updatedAt.Time
+			secrets = append(secrets, secret)
+		}
+
+		if label.Valid {
+			last := &secrets[len(secrets)-1]
+			last.Labels = append(last.Labels, label.String)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// chunks are queried separately, not to repeat them for each label.
+	for i := range secrets {
+		if secrets[i].Chunks, err = s.SecretChunks(ctx, secrets[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return secrets, nil
+}
+
+// OplogEntries returns all oplog entries, ordered by device and sequence number.
+func (s *VaultDB) OplogEntries(ctx context.Context) ([]OplogEntry, error) {
+	return s.oplogEntries(ctx, selectOplogEntries+" ORDER BY device, seq")
+}
+
+const insertBackupSecret = `
+	INSERT INTO
+		secrets (id, uid, name, nonce, ciphertext, url, username, notes, kind, created_at, updated_at)
+	VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+// RestoreSecret inserts the given backed up secret along with its labels and chunks,
+// keeping its id, uid and timestamps.
+func (s *VaultDB) RestoreSecret(ctx context.Context, secret BackupSecret) error {
+	var updatedAt sql.NullTime
+	if !secret.UpdatedAt.IsZero() {
+		updatedAt = sql.NullTime{Time: secret.UpdatedAt.UTC(), Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx, insertBackupSecret, secret.ID, secret.UID, secret.Name, secret.Nonce, secret.Ciphertext, secret.URL, secret.Username, secret.Notes, secret.Kind, secret.CreatedAt.UTC(), updatedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, label := range secret.Labels {
+		if _, err := s.InsertLabel(ctx, label, secret.ID); err != nil {
+			return err
+		}
+	}
+
+	return s.ReplaceSecretChunks(ctx, secret.ID, secret.Chunks)
+}
+
+const insertBackupAuditEntry = `
+	INSERT INTO
+		audit_log (id, operation, secret_id, secret_name, actor, created_at, prev_hash, hash)
+	VALUES
+		(?, ?, NULLIF(?, 0), NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?)
+`
+
+// RestoreAuditEntry inserts the given backed up audit log entry,
+// keeping its id and chain hashes.
+func (s *VaultDB) RestoreAuditEntry(ctx context.Context, e AuditEntry) error {
+	_, err := s.db.ExecContext(ctx, insertBackupAuditEntry, e.ID, e.Operation, e.SecretID, e.SecretName, e.Actor, e.CreatedAt.UTC(), e.PrevHash, e.Hash)
+	return err
+}
+
+const insertBackupAuditCheckpoint = `
+	INSERT INTO
+		audit_checkpoints (id, entry_id, hash, signer, signature, created_at)
+	VALUES
+		(?, ?, ?, ?, ?, ?)
+`
+
+// RestoreAuditCheckpoints replaces all audit checkpoints with the given backed up ones.
+func (s *VaultDB) RestoreAuditCheckpoints(ctx context.Context, checkpoints []AuditCheckpoint) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM audit_checkpoints"); err != nil {
+		return err
+	}
+
+	for _, c := range checkpoints {
+		if _, err := s.db.ExecContext(ctx, insertBackupAuditCheckpoint, c.ID, c.EntryID, c.Hash, c.Signer, c.Signature, c.CreatedAt.UTC()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const insertBackupAPIToken = `
+	INSERT INTO
+		api_tokens (id, name, hash, scope, read_only, expires_at, created_at)
+	VALUES
+		(?, ?, ?, ?, ?, ?, ?)
+`
+
+// RestoreAPITokens replaces all api tokens with the given backed up ones.
+func (s *VaultDB) RestoreAPITokens(ctx context.Context, tokens []APIToken) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM api_tokens"); err != nil {
+		return err
+	}
+
+	for _, t := range tokens {
+		scope, err := json.Marshal(t.Scope)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.db.ExecContext(ctx, insertBackupAPIToken, t.ID, t.Name, t.Hash, string(scope), t.ReadOnly, t.ExpiresAt.UTC(), t.CreatedAt.UTC()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rowCountTables lists the vault tables whose row counts are captured by backups.
+var rowCountTables = []string{"secrets", "labels", "links", "cloud_secrets", "audit_log", "audit_checkpoints", "api_tokens", "oplog", "secret_chunks"}
+
+// RowCounts returns the number of rows of each vault table captured by backups.
+func (s *VaultDB) RowCounts(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int, len(rowCountTables))
+
+	for _, table := range rowCountTables {
+		var n int
+		if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&n); err != nil { //nolint:gosec // constant table names.
+			return nil, err
+		}
+
+		counts[table] = n
+	}
+
+	return counts, nil
+}
+
+const selectSampleSecrets = `
+	SELECT
+		id, nonce, ciphertext
+	FROM
+		secrets
+	ORDER BY
+		RANDOM()
+	LIMIT
+		?
+`
+
+// SampleSecret is the encrypted value of a secret.
+type SampleSecret struct {
+	ID         int
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+// SampleSecrets returns up to n randomly selected secrets, or all secrets if n is not positive.
+func (s *VaultDB) SampleSecrets(ctx context.Context, n int) ([]SampleSecret, error) {
+	if n <= 0 {
+		n = -1 // no limit.
+	}
+
+	rows, err := s.db.QueryContext(ctx, selectSampleSecrets, n)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var secrets []SampleSecret
+
+	for rows.Next() {
+		var secret SampleSecret
+		if err := rows.Scan(&secret.ID, &secret.Nonce, &secret.Ciphertext); err != nil {
+			return nil, err
+		}
+
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, rows.Err()
+}
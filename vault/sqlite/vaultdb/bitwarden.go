@@ -0,0 +1,104 @@
+package vaultdb
+
+import (
+	"context"
+	"time"
+)
+
+// BitwardenAccount is the stored Bitwarden compatibility account.
+type BitwardenAccount struct {
+	Email               string
+	KDFIterations       int
+	PasswordSalt        []byte
+	PasswordHash        []byte
+	ProtectedKey        string
+	PublicKey           string
+	ProtectedPrivateKey string
+	CreatedAt           time.Time
+}
+
+const upsertBitwardenAccount = `
+	INSERT INTO
+		bitwarden_account (
+			id,
+			email,
+			kdf_iterations,
+			password_salt,
+			password_hash,
+			protected_key,
+			public_key,
+			protected_private_key,
+			created_at
+		)
+	VALUES
+		(0, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		email = excluded.email,
+		kdf_iterations = excluded.kdf_iterations,
+		password_salt = excluded.password_salt,
+		password_hash = excluded.password_hash,
+		protected_key = excluded.protected_key,
+		public_key = excluded.public_key,
+		protected_private_key = excluded.protected_private_key,
+		created_at = excluded.created_at
+`
+
+// SetBitwardenAccount inserts or replaces the Bitwarden account.
+func (s *VaultDB) SetBitwardenAccount(ctx context.Context, a BitwardenAccount) error {
+	_, err := s.db.ExecContext(ctx, upsertBitwardenAccount,
+		a.Email, a.KDFIterations, a.PasswordSalt, a.PasswordHash, a.ProtectedKey, a.PublicKey, a.ProtectedPrivateKey, a.CreatedAt.UTC())
+
+	return err
+}
+
+const selectBitwardenAccount = `
+	SELECT
+		email,
+		kdf_iterations,
+		password_salt,
+		password_hash,
+		protected_key,
+		public_key,
+		protected_private_key,
+		created_at
+	FROM
+		bitwarden_account
+	WHERE
+		id = 0
+`
+
+// BitwardenAccount returns the Bitwarden account, or [sql.ErrNoRows] if none was set.
+func (s *VaultDB) BitwardenAccount(ctx context.Context) (BitwardenAccount, error) {
+	var a BitwardenAccount
+
+	err := s.db.QueryRowContext(ctx, selectBitwardenAccount).Scan(
+		&a.Email, &a.KDFIterations, &a.PasswordSalt, &a.PasswordHash, &a.ProtectedKey, &a.PublicKey, &a.ProtectedPrivateKey, &a.CreatedAt)
+
+	return a, err
+}
+
+// DeleteBitwardenAccount deletes the Bitwarden account,
+// returning the number of deleted rows.
+func (s *VaultDB) DeleteBitwardenAccount(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM bitwarden_account")
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// RestoreBitwardenAccount replaces the Bitwarden account with the given backed up one,
+// or deletes it if a is nil.
+func (s *VaultDB) RestoreBitwardenAccount(ctx context.Context, a *BitwardenAccount) error {
+	if _, err := s.DeleteBitwardenAccount(ctx); err != nil {
+		return err
+	}
+
+	if a == nil {
+		return nil
+	}
+
+	return s.SetBitwardenAccount(ctx, *a)
+}
@@ -0,0 +1,136 @@
+package vaultdb
+
+import (
+	"context"
+)
+
+// SecretChunk is an encrypted chunk of the value of a chunked secret.
+type SecretChunk struct {
+	Seq        int
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+const insertSecretChunk = `
+	INSERT INTO
+		secret_chunks (secret_id, seq, nonce, ciphertext)
+	VALUES
+		(?, ?, ?, ?)
+`
+
+// ReplaceSecretChunks replaces the chunks of the given secret. A nil slice
+// removes them, making the secret ciphertext hold its value inline.
+func (s *VaultDB) ReplaceSecretChunks(ctx context.Context, secretID int, chunks []SecretChunk) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM secret_chunks WHERE secret_id = ?", secretID); err != nil {
+		return err
+	}
+
+	for _, c := range chunks {
+		if _, err := s.db.ExecContext(ctx, insertSecretChunk, secretID, c.Seq, c.Nonce, c.Ciphertext); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const selectSecretChunks = `
+	SELECT
+		seq, nonce, ciphertext
+	FROM
+		secret_chunks
+	WHERE
+		secret_id = ?
+	ORDER BY
+		seq
+`
+
+// SecretChunks returns the chunks of the given secret ordered by sequence,
+// or nil if its value is stored inline.
+func (s *VaultDB) SecretChunks(ctx context.Context, secretID int) ([]SecretChunk, error) {
+	rows, err := s.db.QueryContext(ctx, selectSecretChunks, secretID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var chunks []SecretChunk
+
+	for rows.Next() {
+		var c SecretChunk
+		if err := rows.Scan(&c.Seq, &c.Nonce, &c.Ciphertext); err != nil {
+			return nil, err
+		}
+
+		chunks = append(chunks, c)
+	}
+
+	return chunks, rows.Err()
+}
+
+// EachSecretChunk calls fn with the chunks of the given secret ordered by
+// sequence, one at a time, stopping at the first error.
+func (s *VaultDB) EachSecretChunk(ctx context.Context, secretID int, fn func(SecretChunk) error) error {
+	rows, err := s.db.QueryContext(ctx, selectSecretChunks, secretID)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	for rows.Next() {
+		var c SecretChunk
+		if err := rows.Scan(&c.Seq, &c.Nonce, &c.Ciphertext); err != nil {
+			return err
+		}
+
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+const selectChunksTableExists = `
+	SELECT
+		count(*)
+	FROM
+		sqlite_master
+	WHERE
+		type = 'table'
+		AND name = 'secret_chunks'
+`
+
+// ChunkedSecretIDs returns the set of ids of the secrets whose values are chunked.
+//
+// Vaults predating the chunks table have none, as their integrity
+// is verified before they are migrated.
+func (s *VaultDB) ChunkedSecretIDs(ctx context.Context) (map[int]bool, error) {
+	ids := make(map[int]bool)
+
+	var n int
+	if err := s.db.QueryRowContext(ctx, selectChunksTableExists).Scan(&n); err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return ids, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT DISTINCT secret_id FROM secret_chunks")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids[id] = true
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,84 @@
+package vaultdb
+
+import (
+	"context"
+	"time"
+)
+
+// CloudSecret maps a secret to the cloud secret manager secret
+// it was last pushed to or pulled from.
+type CloudSecret struct {
+	SecretID   int
+	Provider   string
+	RemoteName string
+	Digest     []byte // Digest is the keyed digest of the secret value and labels last synced.
+	SyncedAt   time.Time
+}
+
+const upsertCloudSecret = `
+	INSERT INTO
+		cloud_secrets (secret_id, provider, remote_name, digest, synced_at)
+	VALUES
+		(?, ?, ?, ?, ?)
+	ON CONFLICT (secret_id, provider) DO UPDATE
+	SET
+		remote_name = excluded.remote_name,
+		digest = excluded.digest,
+		synced_at = excluded.synced_at
+`
+
+// SetCloudSecret inserts or replaces the mapping of c.SecretID for c.Provider.
+func (s *VaultDB) SetCloudSecret(ctx context.Context, c CloudSecret) error {
+	_, err := s.db.ExecContext(ctx, upsertCloudSecret, c.SecretID, c.Provider, c.RemoteName, c.Digest, c.SyncedAt.UTC())
+	return err
+}
+
+const selectCloudSecrets = `
+	SELECT
+		secret_id, provider, remote_name, digest, synced_at
+	FROM
+		cloud_secrets
+`
+
+// CloudSecrets returns the mappings of the given provider, or of all providers
+// if provider is empty, ordered by secret id.
+func (s *VaultDB) CloudSecrets(ctx context.Context, provider string) ([]CloudSecret, error) {
+	query, args := selectCloudSecrets, []any{}
+	if len(provider) > 0 {
+		query, args = query+" WHERE provider = ?", append(args, provider)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query+" ORDER BY secret_id, provider", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var secrets []CloudSecret
+
+	for rows.Next() {
+		var c CloudSecret
+		if err := rows.Scan(&c.SecretID, &c.Provider, &c.RemoteName, &c.Digest, &c.SyncedAt); err != nil {
+			return nil, err
+		}
+
+		secrets = append(secrets, c)
+	}
+
+	return secrets, rows.Err()
+}
+
+// RestoreCloudSecrets replaces all cloud secret mappings with the given backed up ones.
+func (s *VaultDB) RestoreCloudSecrets(ctx context.Context, secrets []CloudSecret) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM cloud_secrets"); err != nil {
+		return err
+	}
+
+	for _, c := range secrets {
+		if err := s.SetCloudSecret(ctx, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package vaultdb
+
+import "context"
+
+// FileKey returns the stored key material of standalone encrypted files,
+// or [sql.ErrNoRows] if none is stored.
+func (s *VaultDB) FileKey(ctx context.Context) ([]byte, error) {
+	var key []byte
+
+	err := s.db.QueryRowContext(ctx, "SELECT key FROM file_key WHERE id = 0").Scan(&key)
+
+	return key, err
+}
+
+// SetFileKey stores the key material of standalone encrypted files,
+// unless one is stored already.
+func (s *VaultDB) SetFileKey(ctx context.Context, key []byte) error {
+	_, err := s.db.ExecContext(ctx, "INSERT INTO file_key (id, key) VALUES (0, ?) ON CONFLICT (id) DO NOTHING", key)
+	return err
+}
@@ -0,0 +1,142 @@
+package vaultdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"iter"
+)
+
+// IntegrityRow holds the secret fields covered by the vault integrity MAC.
+type IntegrityRow struct {
+	ID         int
+	Name       string
+	Nonce      []byte
+	Ciphertext []byte
+	Labels     []string // Labels are sorted by name.
+}
+
+const selectIntegrityRows = `
+	SELECT
+		s.id,
+		s.name,
+		s.nonce,
+		s.ciphertext,
+		l.name
+	FROM
+		secrets s
+		` + joinLabels + `
+	ORDER BY
+		s.id, l.name
+`
+
+// IntegrityRows returns an iterator over all secrets, including unlabeled ones,
+// ordered by id.
+func (s *VaultDB) IntegrityRows(ctx context.Context) iter.Seq2[IntegrityRow, error] {
+	return func(yield func(IntegrityRow, error) bool) {
+		rows, err := s.db.QueryContext(ctx, selectIntegrityRows)
+		if err != nil {
+			yield(IntegrityRow{}, err)
+			return
+		}
+		defer func() { _ = rows.Close() }() //nolint:wsl
+
+		var curr *IntegrityRow
+
+		for rows.Next() {
+			var (
+				row   IntegrityRow
+				label sql.NullString
+			)
+
+			if err := rows.Scan(&row.ID, &row.Name, &row.Nonce, &row.Ciphertext, &label); err != nil {
+				yield(IntegrityRow{}, err)
+				return
+			}
+
+			if curr != nil && curr.ID != row.ID {
+				if !yield(*curr, nil) {
+					return
+				}
+
+				curr = nil
+			}
+
+			if curr == nil {
+				curr = &row
+			}
+
+			if label.Valid {
+				curr.Labels = append(curr.Labels, label.String)
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(IntegrityRow{}, err)
+			return
+		}
+
+		if curr != nil {
+			yield(*curr, nil)
+		}
+	}
+}
+
+// SchemaVersion returns the applied vault schema migration version.
+func (s *VaultDB) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := s.db.QueryRowContext(ctx, `SELECT version FROM schema_version WHERE id = 0`).Scan(&version)
+
+	return version, err
+}
+
+const selectIntegrityTableExists = `
+	SELECT
+		count(*)
+	FROM
+		sqlite_master
+	WHERE
+		type = 'table'
+		AND name = 'vault_integrity'
+`
+
+// IntegrityMAC returns the stored vault integrity MAC.
+//
+// It returns nil if no MAC was stored yet,
+// including vaults predating the integrity table.
+func (s *VaultDB) IntegrityMAC(ctx context.Context) ([]byte, error) {
+	var n int
+	if err := s.db.QueryRowContext(ctx, selectIntegrityTableExists).Scan(&n); err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return nil, nil
+	}
+
+	var mac []byte
+
+	err := s.db.QueryRowContext(ctx, `SELECT mac FROM vault_integrity WHERE id = 0`).Scan(&mac)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return mac, err
+}
+
+const upsertIntegrityMAC = `
+	INSERT INTO
+		vault_integrity (id, mac)
+	VALUES
+		(0, ?)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		mac = EXCLUDED.mac,
+		updated_at = CURRENT_TIMESTAMP
+`
+
+// SetIntegrityMAC stores the vault integrity MAC.
+func (s *VaultDB) SetIntegrityMAC(ctx context.Context, mac []byte) error {
+	_, err := s.db.ExecContext(ctx, upsertIntegrityMAC, mac)
+	return err
+}
@@ -0,0 +1,139 @@
+package vaultdb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+)
+
+// KeePassXCAssociation is a KeePassXC-Browser extension associated with the vault.
+type KeePassXCAssociation struct {
+	ID               string
+	IDKey            string    // IDKey is the extension identity public key, empty while pairing.
+	PairingExpiresAt time.Time // PairingExpiresAt is the time pairing expires at, zero once paired.
+	CreatedAt        time.Time
+}
+
+// Pending reports whether the association is waiting for an extension to pair.
+func (a KeePassXCAssociation) Pending() bool {
+	return len(a.IDKey) == 0
+}
+
+const upsertKeePassXCAssociation = `
+	INSERT INTO
+		keepassxc_associations (id, id_key, pairing_expires_at, created_at)
+	VALUES
+		(?, ?, ?, ?)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		id_key = excluded.id_key,
+		pairing_expires_at = excluded.pairing_expires_at,
+		created_at = excluded.created_at
+`
+
+// SetKeePassXCAssociation inserts or replaces the given association.
+func (s *VaultDB) SetKeePassXCAssociation(ctx context.Context, a KeePassXCAssociation) error {
+	var (
+		idKey     sql.NullString
+		expiresAt sql.NullTime
+	)
+
+	if len(a.IDKey) > 0 {
+		idKey = sql.NullString{String: a.IDKey, Valid: true}
+	}
+
+	if !a.PairingExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: a.PairingExpiresAt.UTC(), Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx, upsertKeePassXCAssociation, a.ID, idKey, expiresAt, a.CreatedAt.UTC())
+
+	return err
+}
+
+const selectKeePassXCAssociations = `
+	SELECT
+		id, id_key, pairing_expires_at, created_at
+	FROM
+		keepassxc_associations
+`
+
+// KeePassXCAssociation returns the association with the given id,
+// or [sql.ErrNoRows] if it does not exist.
+func (s *VaultDB) KeePassXCAssociation(ctx context.Context, id string) (KeePassXCAssociation, error) {
+	return scanKeePassXCAssociation(s.db.QueryRowContext(ctx, selectKeePassXCAssociations+" WHERE id = ?", id))
+}
+
+// KeePassXCAssociations returns all associations, ordered by creation time.
+func (s *VaultDB) KeePassXCAssociations(ctx context.Context) ([]KeePassXCAssociation, error) {
+	rows, err := s.db.QueryContext(ctx, selectKeePassXCAssociations+" ORDER BY created_at, id")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var associations []KeePassXCAssociation
+
+	for rows.Next() {
+		a, err := scanKeePassXCAssociation(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		associations = append(associations, a)
+	}
+
+	return associations, rows.Err()
+}
+
+// DeleteKeePassXCAssociations deletes the associations with the given ids,
+// returning the number of deleted associations.
+func (s *VaultDB) DeleteKeePassXCAssociations(ctx context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM keepassxc_associations WHERE id IN ("+placeholders+")", cmdutil.ToAnySlice(ids)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// RestoreKeePassXCAssociations replaces all associations with the given backed up ones.
+func (s *VaultDB) RestoreKeePassXCAssociations(ctx context.Context, associations []KeePassXCAssociation) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM keepassxc_associations"); err != nil {
+		return err
+	}
+
+	for _, a := range associations {
+		if err := s.SetKeePassXCAssociation(ctx, a); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func scanKeePassXCAssociation(row scanner) (KeePassXCAssociation, error) {
+	var (
+		a         KeePassXCAssociation
+		idKey     sql.NullString
+		expiresAt sql.NullTime
+	)
+
+	if err := row.Scan(&a.ID, &idKey, &expiresAt, &a.CreatedAt); err != nil {
+		return KeePassXCAssociation{}, err
+	}
+
+	a.IDKey = idKey.String
+	a.PairingExpiresAt = expiresAt.Time
+
+	return a, nil
+}
@@ -0,0 +1,75 @@
+package vaultdb
+
+import "context"
+
+// LabelMeta describes a label, shared by all the secrets having it.
+type LabelMeta struct {
+	Name        string
+	Description string
+	Color       string // Color is the color label badges are shown in, empty for none.
+}
+
+const upsertLabelMeta = `
+	INSERT INTO
+		label_meta (name, description, color)
+	VALUES
+		(?, ?, ?)
+	ON CONFLICT (name) DO UPDATE
+	SET
+		description = excluded.description,
+		color = excluded.color
+`
+
+// SetLabelMeta sets the metadata of the label named m.Name.
+func (s *VaultDB) SetLabelMeta(ctx context.Context, m LabelMeta) error {
+	_, err := s.db.ExecContext(ctx, upsertLabelMeta, m.Name, m.Description, m.Color)
+	return err
+}
+
+const deleteLabelMeta = `
+	DELETE FROM label_meta
+	WHERE
+		name = ?
+`
+
+// DeleteLabelMeta deletes the metadata of the given label,
+// returning the number of deleted rows.
+func (s *VaultDB) DeleteLabelMeta(ctx context.Context, name string) (int64, error) {
+	res, err := s.db.ExecContext(ctx, deleteLabelMeta, name)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+const selectLabelMetas = `
+	SELECT
+		name, description, color
+	FROM
+		label_meta
+	ORDER BY
+		name
+`
+
+// LabelMetas returns the metadata of all described labels, ordered by name.
+func (s *VaultDB) LabelMetas(ctx context.Context) ([]LabelMeta, error) {
+	rows, err := s.db.QueryContext(ctx, selectLabelMetas)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var metas []LabelMeta
+
+	for rows.Next() {
+		var m LabelMeta
+		if err := rows.Scan(&m.Name, &m.Description, &m.Color); err != nil {
+			return nil, err
+		}
+
+		metas = append(metas, m)
+	}
+
+	return metas, rows.Err()
+}
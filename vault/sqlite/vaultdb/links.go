@@ -0,0 +1,124 @@
+package vaultdb
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Link marks a secret whose value is resolved from an external provider.
+// The secret value caches the latest resolved value.
+type Link struct {
+	SecretID   int
+	URI        string
+	TTL        time.Duration // TTL is how long the cached value is used before resolving the link again.
+	ResolvedAt time.Time     // ResolvedAt is the time the link was last resolved, zero if never.
+}
+
+// Expired reports whether the cached value of the link expired at now.
+func (l Link) Expired(now time.Time) bool {
+	return l.ResolvedAt.IsZero() || !now.Before(l.ResolvedAt.Add(l.TTL))
+}
+
+const insertLink = `
+	INSERT INTO
+		links (secret_id, uri, ttl, resolved_at)
+	VALUES
+		(?, ?, ?, ?)
+`
+
+// InsertLink links the secret identified by l.SecretID.
+func (s *VaultDB) InsertLink(ctx context.Context, l Link) error {
+	var resolvedAt sql.NullTime
+	if !l.ResolvedAt.IsZero() {
+		resolvedAt = sql.NullTime{Time: l.ResolvedAt.UTC(), Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx, insertLink, l.SecretID, l.URI, int64(l.TTL.Seconds()), resolvedAt)
+
+	return err
+}
+
+const selectLinks = `
+	SELECT
+		secret_id, uri, ttl, resolved_at
+	FROM
+		links
+`
+
+// Link returns the link of the given secret,
+// or [sql.ErrNoRows] if the secret is not linked.
+func (s *VaultDB) Link(ctx context.Context, secretID int) (Link, error) {
+	return scanLink(s.db.QueryRowContext(ctx, selectLinks+" WHERE secret_id = ?", secretID))
+}
+
+// Links returns all links, ordered by secret id.
+func (s *VaultDB) Links(ctx context.Context) ([]Link, error) {
+	rows, err := s.db.QueryContext(ctx, selectLinks+" ORDER BY secret_id")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var links []Link
+
+	for rows.Next() {
+		l, err := scanLink(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		links = append(links, l)
+	}
+
+	return links, rows.Err()
+}
+
+const updateLinkResolvedAt = `
+	UPDATE links
+	SET
+		resolved_at = ?
+	WHERE
+		secret_id = ?
+`
+
+// SetLinkResolved sets the time the link of the given secret was last resolved.
+func (s *VaultDB) SetLinkResolved(ctx context.Context, secretID int, t time.Time) error {
+	_, err := s.db.ExecContext(ctx, updateLinkResolvedAt, t.UTC(), secretID)
+	return err
+}
+
+// RestoreLinks replaces all links with the given backed up ones.
+func (s *VaultDB) RestoreLinks(ctx context.Context, links []Link) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM links"); err != nil {
+		return err
+	}
+
+	for _, l := range links {
+		if err := s.InsertLink(ctx, l); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func scanLink(row scanner) (Link, error) {
+	var (
+		l          Link
+		ttl        int64
+		resolvedAt sql.NullTime
+	)
+
+	if err := row.Scan(&l.SecretID, &l.URI, &ttl, &resolvedAt); err != nil {
+		return Link{}, err
+	}
+
+	l.TTL = time.Duration(ttl) * time.Second
+
+	if resolvedAt.Valid {
+		l.ResolvedAt = resolvedAt.Time
+	}
+
+	return l, nil
+}
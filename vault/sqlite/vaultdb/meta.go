@@ -0,0 +1,246 @@
+package vaultdb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+)
+
+// SecretMeta holds the structured metadata of a secret.
+// Empty fields are unset.
+type SecretMeta struct {
+	URL      string
+	Username string
+	Notes    string
+
+	// Kind is the kind of secrets with a dedicated format, e.g., "totp",
+	// and empty for plain secrets.
+	Kind string
+
+	// CreatedAt and UpdatedAt are set by the database, and ignored on writes.
+	CreatedAt time.Time
+	UpdatedAt time.Time // UpdatedAt is zero if the secret was never updated.
+}
+
+// IsZero reports whether none of the url, username and notes are set,
+// regardless of the kind.
+func (m SecretMeta) IsZero() bool {
+	return len(m.URL) == 0 && len(m.Username) == 0 && len(m.Notes) == 0
+}
+
+//nolint:gosec
+const insertSecretWithMeta = `
+	INSERT INTO
+		secrets (name, url, username, notes, kind, nonce, ciphertext)
+	VALUES
+		(?, ?, ?, ?, ?, ?, ?)
+`
+
+// InsertSecretWithMeta inserts a secret along with its metadata.
+func (s *VaultDB) InsertSecretWithMeta(ctx context.Context, name string, meta SecretMeta, nonce []byte, ciphertext []byte) (int, error) {
+	res, err := s.db.ExecContext(ctx, insertSecretWithMeta, name, meta.URL, meta.Username, meta.Notes, meta.Kind, nonce, ciphertext)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+const updateSecretMeta = `
+	UPDATE secrets
+	SET
+		url = ?,
+		username = ?,
+		notes = ?,
+		kind = ?
+	WHERE
+		id = ?
+`
+
+// UpdateSecretMeta replaces the url, username, notes and kind of the given secret.
+func (s *VaultDB) UpdateSecretMeta(ctx context.Context, id int, meta SecretMeta) (int64, error) {
+	res, err := s.db.ExecContext(ctx, updateSecretMeta, meta.URL, meta.Username, meta.Notes, meta.Kind, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// SecretMetas returns the metadata of the given secrets, keyed by secret id.
+// Ids of secrets that do not exist are ignored.
+//
+// If the IDs slice is empty, the function returns [ErrNoIDsProvided].
+func (s *VaultDB) SecretMetas(ctx context.Context, ids []int) (map[int]SecretMeta, error) {
+	if len(ids) == 0 {
+		return nil, ErrNoIDsProvided
+	}
+
+	placeholders := make([]string, len(ids))
+	for i := range ids {
+		placeholders[i] = "?"
+	}
+
+	//nolint:gosec // placeholders only.
+	query := `
+	SELECT
+		id, url, username, notes, kind, created_at, updated_at
+	FROM
+		secrets
+	WHERE
+		deleted_at IS NULL
+		AND id IN (` + strings.Join(placeholders, ",") + ")"
+
+	rows, err := s.db.QueryContext(ctx, query, cmdutil.ToAnySlice(ids)...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	metas := make(map[int]SecretMeta, len(ids))
+
+	for rows.Next() {
+		var (
+			id        int
+			meta      SecretMeta
+			updatedAt sql.NullTime
+		)
+
+		if err := rows.Scan(&id, &meta.URL, &meta.Username, &meta.Notes, &meta.Kind, &meta.CreatedAt, &updatedAt); err != nil {
+			return nil, err
+		}
+
+		meta.UpdatedAt = updatedAt.Time
+		metas[id] = meta
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return metas, nil
+}
+
+const selectMetaColumnsExist = `
+	SELECT
+		count(*)
+	FROM
+		pragma_table_info('secrets')
+	WHERE
+		name = 'url'
+`
+
+const selectSecretsWithMeta = `
+	SELECT
+		id, url, username, notes
+	FROM
+		secrets
+	WHERE
+		url != ''
+		OR username != ''
+		OR notes != ''
+`
+
+// SecretsWithMeta returns the url, username and notes of the secrets having
+// any of them set, keyed by secret id.
+//
+// It is called prior to migrating, e.g., to verify the vault integrity,
+// and returns an empty map if the schema predates secret metadata.
+func (s *VaultDB) SecretsWithMeta(ctx context.Context) (map[int]SecretMeta, error) {
+	metas := make(map[int]SecretMeta)
+
+	var n int
+	if err := s.db.QueryRowContext(ctx, selectMetaColumnsExist).Scan(&n); err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return metas, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, selectSecretsWithMeta)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	for rows.Next() {
+		var (
+			id   int
+			meta SecretMeta
+		)
+
+		if err := rows.Scan(&id, &meta.URL, &meta.Username, &meta.Notes); err != nil {
+			return nil, err
+		}
+
+		metas[id] = meta
+	}
+
+	return metas, rows.Err()
+}
+
+const selectKindColumnExists = `
+	SELECT
+		count(*)
+	FROM
+		pragma_table_info('secrets')
+	WHERE
+		name = 'kind'
+`
+
+const selectSecretKinds = `
+	SELECT
+		id, kind
+	FROM
+		secrets
+	WHERE
+		kind != ''
+`
+
+// SecretKinds returns the kind of the secrets that are not plain secrets,
+// keyed by secret id.
+//
+// It is called prior to migrating, e.g., to verify the vault integrity,
+// and returns an empty map if the schema predates secret kinds.
+func (s *VaultDB) SecretKinds(ctx context.Context) (map[int]string, error) {
+	kinds := make(map[int]string)
+
+	var n int
+	if err := s.db.QueryRowContext(ctx, selectKindColumnExists).Scan(&n); err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return kinds, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, selectSecretKinds)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	for rows.Next() {
+		var (
+			id   int
+			kind string
+		)
+
+		if err := rows.Scan(&id, &kind); err != nil {
+			return nil, err
+		}
+
+		kinds[id] = kind
+	}
+
+	return kinds, rows.Err()
+}
@@ -0,0 +1,246 @@
+package vaultdb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// SyncState holds the sync configuration of this device.
+type SyncState struct {
+	Device string
+	Key    []byte
+}
+
+const selectSyncState = `
+	SELECT
+		device, key
+	FROM
+		sync_state
+	WHERE
+		id = 0
+`
+
+// SyncState returns the sync configuration,
+// or [sql.ErrNoRows] if sync is not initialized.
+func (s *VaultDB) SyncState(ctx context.Context) (SyncState, error) {
+	var state SyncState
+
+	err := s.db.QueryRowContext(ctx, selectSyncState).Scan(&state.Device, &state.Key)
+
+	return state, err
+}
+
+const upsertSyncState = `
+	INSERT INTO
+		sync_state (id, device, key)
+	VALUES
+		(0, ?, ?)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		device = excluded.device,
+		key = excluded.key
+`
+
+// SetSyncState sets the sync configuration.
+func (s *VaultDB) SetSyncState(ctx context.Context, state SyncState) error {
+	_, err := s.db.ExecContext(ctx, upsertSyncState, state.Device, state.Key)
+	return err
+}
+
+// OplogEntry is a single secret mutation recorded in the oplog.
+type OplogEntry struct {
+	Device    string
+	Seq       int
+	Clock     map[string]int // Clock is the vector clock of the entry, mapping device ids to sequence numbers.
+	SecretUID string
+	Operation string
+	Nonce     []byte
+	Payload   []byte // Payload is sealed using the sync key.
+	CreatedAt time.Time
+}
+
+const insertOplogEntry = `
+	INSERT INTO
+		oplog (device, seq, clock, secret_uid, operation, nonce, payload, created_at)
+	VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+// InsertOplogEntry appends the given entry to the oplog.
+func (s *VaultDB) InsertOplogEntry(ctx context.Context, e OplogEntry) error {
+	clock, err := json.Marshal(e.Clock)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, insertOplogEntry, e.Device, e.Seq, string(clock), e.SecretUID, e.Operation, e.Nonce, e.Payload, e.CreatedAt.UTC())
+
+	return err
+}
+
+const selectOplogClock = `
+	SELECT
+		device, MAX(seq)
+	FROM
+		oplog
+	GROUP BY
+		device
+`
+
+// OplogClock returns the latest sequence number of each device in the oplog.
+func (s *VaultDB) OplogClock(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx, selectOplogClock)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	clock := make(map[string]int)
+
+	for rows.Next() {
+		var (
+			device string
+			seq    int
+		)
+
+		if err := rows.Scan(&device, &seq); err != nil {
+			return nil, err
+		}
+
+		clock[device] = seq
+	}
+
+	return clock, rows.Err()
+}
+
+const selectOplogEntries = `
+	SELECT
+		device, seq, clock, secret_uid, operation, nonce, payload, created_at
+	FROM
+		oplog
+`
+
+// OplogEntriesByDevice returns the entries recorded by the given device, ordered by sequence number.
+func (s *VaultDB) OplogEntriesByDevice(ctx context.Context, device string) ([]OplogEntry, error) {
+	return s.oplogEntries(ctx, selectOplogEntries+" WHERE device = ? ORDER BY seq", device)
+}
+
+// OplogEntriesBySecret returns the entries recorded for the secret with the given uid.
+func (s *VaultDB) OplogEntriesBySecret(ctx context.Context, uid string) ([]OplogEntry, error) {
+	return s.oplogEntries(ctx, selectOplogEntries+" WHERE secret_uid = ? ORDER BY device, seq", uid)
+}
+
+func (s *VaultDB) oplogEntries(ctx context.Context, query string, args ...any) ([]OplogEntry, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var entries []OplogEntry
+
+	for rows.Next() {
+		var (
+			e     OplogEntry
+			clock string
+		)
+
+		if err := rows.Scan(&e.Device, &e.Seq, &clock, &e.SecretUID, &e.Operation, &e.Nonce, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(clock), &e.Clock); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+const shredOplogPayload = `
+	UPDATE oplog
+	SET
+		nonce = randomblob(length(nonce)),
+		payload = randomblob(length(payload))
+	WHERE
+		device = ?
+		AND seq = ?
+`
+
+const updateOplogPayload = `
+	UPDATE oplog
+	SET
+		nonce = ?,
+		payload = ?
+	WHERE
+		device = ?
+		AND seq = ?
+`
+
+// ReplaceOplogPayload replaces the sealed payload of the given entry,
+// overwriting the previous one with random bytes of the same length first,
+// so it does not survive in the database free pages.
+func (s *VaultDB) ReplaceOplogPayload(ctx context.Context, device string, seq int, nonce []byte, payload []byte) error {
+	if _, err := s.db.ExecContext(ctx, shredOplogPayload, device, seq); err != nil {
+		return err
+	}
+
+	_, err := s.db.ExecContext(ctx, updateOplogPayload, nonce, payload, device, seq)
+
+	return err
+}
+
+// SecretUID returns the uid of the secret with the given id.
+func (s *VaultDB) SecretUID(ctx context.Context, id int) (string, error) {
+	var uid string
+
+	err := s.db.QueryRowContext(ctx, "SELECT uid FROM secrets WHERE id = ?", id).Scan(&uid)
+
+	return uid, err
+}
+
+// SecretIDByUID returns the id of the secret with the given uid,
+// or [sql.ErrNoRows] if it does not exist.
+func (s *VaultDB) SecretIDByUID(ctx context.Context, uid string) (int, error) {
+	var id int
+
+	err := s.db.QueryRowContext(ctx, "SELECT id FROM secrets WHERE uid = ?", uid).Scan(&id)
+
+	return id, err
+}
+
+//nolint:gosec
+const insertSecretWithUID = `
+	INSERT INTO
+		secrets (uid, name, url, username, notes, kind, nonce, ciphertext)
+	VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+// InsertSecretWithUID inserts a secret with the given uid, e.g., one created on another device.
+func (s *VaultDB) InsertSecretWithUID(ctx context.Context, uid string, name string, meta SecretMeta, nonce []byte, ciphertext []byte) (int, error) {
+	res, err := s.db.ExecContext(ctx, insertSecretWithUID, uid, name, meta.URL, meta.Username, meta.Notes, meta.Kind, nonce, ciphertext)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// DeleteSecretLabels deletes all labels of the given secret.
+func (s *VaultDB) DeleteSecretLabels(ctx context.Context, secretID int) (int64, error) {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM labels WHERE secret_id = ?", secretID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
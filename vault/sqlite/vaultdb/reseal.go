@@ -0,0 +1,84 @@
+package vaultdb
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SealedValue is a value encrypted using the vault key,
+// identified by the table and rowid of the row holding it.
+type SealedValue struct {
+	Table      string
+	RowID      int64
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+// sealedTables lists the tables holding values encrypted using the vault key
+// in their nonce and ciphertext columns.
+var sealedTables = []string{"secrets", "secret_chunks", "secret_versions"}
+
+// AllSealedValues returns all values encrypted using the vault key: the values
+// of secrets, including trashed ones, their chunks and their previous versions.
+func (s *VaultDB) AllSealedValues(ctx context.Context) ([]SealedValue, error) {
+	var values []SealedValue
+
+	for _, table := range sealedTables {
+		rows, err := s.db.QueryContext(ctx, "SELECT rowid, nonce, ciphertext FROM "+table) //nolint:gosec // fixed table names.
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			v := SealedValue{Table: table}
+			if err := rows.Scan(&v.RowID, &v.Nonce, &v.Ciphertext); err != nil {
+				_ = rows.Close()
+				return nil, err
+			}
+
+			values = append(values, v)
+		}
+
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// UpdateSealedValues replaces the nonce and ciphertext of the given values,
+// as returned by [VaultDB.AllSealedValues].
+//
+// Secrets keep their updated_at timestamps: the values are re-encrypted,
+// not changed.
+func (s *VaultDB) UpdateSealedValues(ctx context.Context, values []SealedValue) error {
+	for _, v := range values {
+		// kept as stored, not to change its format.
+		var updatedAt sql.NullString
+
+		if v.Table == "secrets" {
+			if err := s.db.QueryRowContext(ctx, "SELECT CAST(updated_at AS TEXT) FROM secrets WHERE rowid = ?", v.RowID).Scan(&updatedAt); err != nil {
+				return err
+			}
+		}
+
+		//nolint:gosec // fixed table names.
+		if _, err := s.db.ExecContext(ctx, "UPDATE "+v.Table+" SET nonce = ?, ciphertext = ? WHERE rowid = ?", v.Nonce, v.Ciphertext, v.RowID); err != nil {
+			return err
+		}
+
+		// updated_at is set by a trigger, see '017_secret_meta'.
+		if v.Table == "secrets" {
+			if _, err := s.db.ExecContext(ctx, "UPDATE secrets SET updated_at = ? WHERE rowid = ?", updatedAt, v.RowID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package vaultdb
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// RotationSchedule is the rotation schedule of a secret.
+type RotationSchedule struct {
+	SecretID int
+	Every    time.Duration // Every is how often the secret is rotated, zero if scheduled by label.
+	DueAt    time.Time     // DueAt is the time the next rotation is due, zero if due by the interval only.
+}
+
+const upsertRotationEvery = `
+	INSERT INTO
+		rotation_schedule (secret_id, every)
+	VALUES
+		(?, ?)
+	ON CONFLICT (secret_id) DO UPDATE
+	SET
+		every = excluded.every
+`
+
+// SetRotationEvery sets how often the given secret is rotated.
+func (s *VaultDB) SetRotationEvery(ctx context.Context, secretID int, every time.Duration) error {
+	_, err := s.db.ExecContext(ctx, upsertRotationEvery, secretID, int64(every.Seconds()))
+	return err
+}
+
+const upsertRotationDue = `
+	INSERT INTO
+		rotation_schedule (secret_id, due_at)
+	VALUES
+		(?, ?)
+	ON CONFLICT (secret_id) DO UPDATE
+	SET
+		due_at = excluded.due_at
+`
+
+// SetRotationDue sets the time the next rotation of the given secret is due.
+func (s *VaultDB) SetRotationDue(ctx context.Context, secretID int, t time.Time) error {
+	_, err := s.db.ExecContext(ctx, upsertRotationDue, secretID, t.UTC())
+	return err
+}
+
+const deleteRotationSchedule = `
+	DELETE FROM rotation_schedule
+	WHERE
+		secret_id = ?
+`
+
+// DeleteRotationSchedule deletes the rotation schedule of the given secret,
+// returning the number of deleted rows.
+func (s *VaultDB) DeleteRotationSchedule(ctx context.Context, secretID int) (int64, error) {
+	res, err := s.db.ExecContext(ctx, deleteRotationSchedule, secretID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+const selectRotationSchedules = `
+	SELECT
+		secret_id, every, due_at
+	FROM
+		rotation_schedule
+`
+
+// RotationSchedules returns the rotation schedules of the secrets,
+// keyed by secret id.
+func (s *VaultDB) RotationSchedules(ctx context.Context) (map[int]RotationSchedule, error) {
+	rows, err := s.db.QueryContext(ctx, selectRotationSchedules)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	schedules := make(map[int]RotationSchedule)
+
+	for rows.Next() {
+		var (
+			rs    RotationSchedule
+			every int64
+			dueAt sql.NullTime
+		)
+
+		if err := rows.Scan(&rs.SecretID, &every, &dueAt); err != nil {
+			return nil, err
+		}
+
+		rs.Every = time.Duration(every) * time.Second
+
+		if dueAt.Valid {
+			rs.DueAt = dueAt.Time
+		}
+
+		schedules[rs.SecretID] = rs
+	}
+
+	return schedules, rows.Err()
+}
@@ -0,0 +1,48 @@
+package vaultdb
+
+import (
+	"context"
+	"strings"
+
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+)
+
+// SecretIDsByLabelGlobs returns the ids of secrets that have at least one
+// label matching any of the given glob patterns.
+func (s *VaultDB) SecretIDsByLabelGlobs(ctx context.Context, patterns []string) (map[int]struct{}, error) {
+	ids := make(map[int]struct{})
+
+	if len(patterns) == 0 {
+		return ids, nil
+	}
+
+	clauses := make([]string, len(patterns))
+	for i := range patterns {
+		clauses[i] = "name GLOB ?"
+	}
+
+	query := `
+	SELECT DISTINCT
+		secret_id
+	FROM
+		labels
+	WHERE
+		` + strings.Join(clauses, " OR ")
+
+	rows, err := s.db.QueryContext(ctx, query, cmdutil.ToAnySlice(patterns)...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids[id] = struct{}{}
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,79 @@
+package vaultdb
+
+import (
+	"context"
+	"time"
+)
+
+// SavedSearch is a named search query.
+type SavedSearch struct {
+	Name      string
+	Query     string
+	CreatedAt time.Time
+}
+
+const upsertSavedSearch = `
+	INSERT INTO
+		saved_searches (name, query)
+	VALUES
+		(?, ?)
+	ON CONFLICT (name) DO UPDATE
+	SET
+		query = excluded.query,
+		created_at = CURRENT_TIMESTAMP
+`
+
+// SetSavedSearch saves the query under the given name,
+// replacing any query saved under it.
+func (s *VaultDB) SetSavedSearch(ctx context.Context, name string, query string) error {
+	_, err := s.db.ExecContext(ctx, upsertSavedSearch, name, query)
+	return err
+}
+
+const deleteSavedSearch = `
+	DELETE FROM saved_searches
+	WHERE
+		name = ?
+`
+
+// DeleteSavedSearch deletes the search saved under the given name,
+// returning the number of deleted rows.
+func (s *VaultDB) DeleteSavedSearch(ctx context.Context, name string) (int64, error) {
+	res, err := s.db.ExecContext(ctx, deleteSavedSearch, name)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+const selectSavedSearches = `
+	SELECT
+		name, query, created_at
+	FROM
+		saved_searches
+	ORDER BY
+		name
+`
+
+// SavedSearches returns all saved searches, ordered by name.
+func (s *VaultDB) SavedSearches(ctx context.Context) ([]SavedSearch, error) {
+	rows, err := s.db.QueryContext(ctx, selectSavedSearches)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var searches []SavedSearch
+
+	for rows.Next() {
+		var ss SavedSearch
+		if err := rows.Scan(&ss.Name, &ss.Query, &ss.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		searches = append(searches, ss)
+	}
+
+	return searches, rows.Err()
+}
@@ -0,0 +1,205 @@
+package vaultdb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	cmdutil "github.com/ladzaretti/vlt-cli/util"
+)
+
+// TrashedSecret is a deleted secret kept in the trash.
+type TrashedSecret struct {
+	Name      string
+	Labels    []string
+	DeletedAt time.Time
+}
+
+// TrashSecretsByIDs moves the given secrets to the trash,
+// hiding them from all queries but those of the trash.
+func (s *VaultDB) TrashSecretsByIDs(ctx context.Context, ids []int) (int64, error) {
+	if len(ids) == 0 {
+		return 0, ErrNoIDsProvided
+	}
+
+	placeholders := make([]string, len(ids))
+	for i := range ids {
+		placeholders[i] = "?"
+	}
+
+	query := `
+	UPDATE secrets
+	SET
+		deleted_at = CURRENT_TIMESTAMP
+	WHERE
+		deleted_at IS NULL
+		AND id IN (` + strings.Join(placeholders, ",") + ")"
+
+	res, err := s.db.ExecContext(ctx, query, cmdutil.ToAnySlice(ids)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+const selectTrashedSecrets = `
+	SELECT
+		s.id,
+		s.name,
+		s.deleted_at,
+		l.name AS label
+	FROM
+		secrets s
+		` + joinLabels + `
+	WHERE
+		s.deleted_at IS NOT NULL
+	ORDER BY
+		s.id, l.name
+`
+
+// TrashedSecrets returns the secrets in the trash, keyed by secret id.
+func (s *VaultDB) TrashedSecrets(ctx context.Context) (map[int]TrashedSecret, error) {
+	rows, err := s.db.QueryContext(ctx, selectTrashedSecrets)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	trashed := make(map[int]TrashedSecret)
+
+	for rows.Next() {
+		var (
+			id    int
+			t     TrashedSecret
+			label sql.NullString
+		)
+
+		if err := rows.Scan(&id, &t.Name, &t.DeletedAt, &label); err != nil {
+			return nil, err
+		}
+
+		if prev, ok := trashed[id]; ok {
+			t.Labels = prev.Labels
+		}
+
+		if label.Valid {
+			t.Labels = append(t.Labels, label.String)
+		}
+
+		trashed[id] = t
+	}
+
+	return trashed, rows.Err()
+}
+
+const restoreTrashedSecret = `
+	UPDATE secrets
+	SET
+		deleted_at = NULL
+	WHERE
+		id = ?
+		AND deleted_at IS NOT NULL
+`
+
+// RestoreTrashedSecret moves the given secret out of the trash.
+// It returns 0 if the secret is not in the trash.
+func (s *VaultDB) RestoreTrashedSecret(ctx context.Context, id int) (int64, error) {
+	res, err := s.db.ExecContext(ctx, restoreTrashedSecret, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+const selectTrashedBefore = `
+	SELECT
+		id
+	FROM
+		secrets
+	WHERE
+		deleted_at IS NOT NULL
+		AND deleted_at <= ?
+`
+
+// TrashedBefore returns the ids of the secrets moved to the trash
+// at or before olderThan.
+func (s *VaultDB) TrashedBefore(ctx context.Context, olderThan time.Time) ([]int, error) {
+	rows, err := s.db.QueryContext(ctx, selectTrashedBefore, olderThan.UTC().Format(time.DateTime))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var ids []int
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+const selectDeletedAtColumnExists = `
+	SELECT
+		count(*)
+	FROM
+		pragma_table_info('secrets')
+	WHERE
+		name = 'deleted_at'
+`
+
+const selectTrashedAt = `
+	SELECT
+		id, deleted_at
+	FROM
+		secrets
+	WHERE
+		deleted_at IS NOT NULL
+`
+
+// TrashedAt returns the time each secret in the trash was deleted,
+// keyed by secret id.
+//
+// It is called prior to migrating, e.g., to verify the vault integrity,
+// and returns an empty map if the schema predates the trash.
+func (s *VaultDB) TrashedAt(ctx context.Context) (map[int]time.Time, error) {
+	deletedAt := make(map[int]time.Time)
+
+	var n int
+	if err := s.db.QueryRowContext(ctx, selectDeletedAtColumnExists).Scan(&n); err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return deletedAt, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, selectTrashedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	for rows.Next() {
+		var (
+			id int
+			t  time.Time
+		)
+
+		if err := rows.Scan(&id, &t); err != nil {
+			return nil, err
+		}
+
+		deletedAt[id] = t
+	}
+
+	return deletedAt, rows.Err()
+}
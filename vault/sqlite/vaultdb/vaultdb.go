@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"strings"
+	"time"
 
 	cmdutil "github.com/ladzaretti/vlt-cli/util"
 	"github.com/ladzaretti/vlt-cli/vault/types"
@@ -114,6 +115,7 @@ const selectSecret = `
 		secrets
 	WHERE
 		id = ?
+		AND deleted_at IS NULL
 `
 
 // ShowSecret returns the secret ciphertext and nonce associated with the given secret id.
@@ -207,9 +209,10 @@ func (s *VaultDB) SecretsByIDs(ctx context.Context, ids []int) (map[int]SecretWi
 		l.name AS label
 	FROM
 		secrets s
-		LEFT JOIN labels l ON s.id = l.secret_id
+		` + joinLabels + `
 	WHERE
-		s.id IN (` + strings.Join(placeholders, ",") + ")"
+		s.deleted_at IS NULL
+		AND s.id IN (` + strings.Join(placeholders, ",") + ")"
 
 	return s.secretsJoinLabels(ctx, query, cmdutil.ToAnySlice(ids)...)
 }
@@ -227,10 +230,64 @@ type Filters struct {
 	// Labels filters secrets by matching any of the provided label patterns.
 	// Multiple labels are ORed.
 	Labels []string
+
+	// URL and Username filter secrets by their metadata, see [SecretMeta].
+	URL      string
+	Username string
+
+	// Kind filters secrets by their exact kind, see [SecretMeta.Kind].
+	Kind string
 }
 
+// joinLabels joins secrets with their labels.
+//
+// labels.secret_id is declared TEXT: it is compared against the text form of
+// the secret id, so labels are looked up using the labels_secret_id index
+// instead of being scanned for every secret.
+const joinLabels = `LEFT JOIN labels l ON l.secret_id = CAST(s.id AS TEXT)`
+
 // FilterSecrets returns secrets that match the given filters.
 func (s *VaultDB) FilterSecrets(ctx context.Context, m Filters) (map[int]SecretWithLabels, error) {
+	query, args := filterSecretsQuery(m)
+	return s.secretsJoinLabels(ctx, query, args...)
+}
+
+// FilterSecretsPlan returns the sqlite query plan of [VaultDB.FilterSecrets]
+// for the given filters, one line per step.
+func (s *VaultDB) FilterSecretsPlan(ctx context.Context, m Filters) ([]string, error) {
+	query, args := filterSecretsQuery(m)
+
+	rows, err := s.db.QueryContext(ctx, "EXPLAIN QUERY PLAN "+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var plan []string
+	for rows.Next() {
+		var (
+			id, parent, notused int
+			detail              string
+		)
+
+		if err := rows.Scan(&id, &parent, &notused, &detail); err != nil {
+			return nil, err
+		}
+
+		plan = append(plan, detail)
+	}
+
+	return plan, rows.Err()
+}
+
+// filterSecretsQuery returns the query of [VaultDB.FilterSecrets] along with
+// its arguments.
+//
+// Label rows are filtered by the join itself, so only matching labels are
+// returned. Secrets are first narrowed down by id using subqueries over the
+// secrets_name and label name indexes, which glob patterns having a literal
+// prefix, e.g., 'prod/*', search by range instead of scanning.
+func filterSecretsQuery(m Filters) (string, []any) {
 	query := `
 		SELECT
 			s.id,
@@ -238,17 +295,24 @@ func (s *VaultDB) FilterSecrets(ctx context.Context, m Filters) (map[int]SecretW
 			l.name AS label
 		FROM
 			secrets s
-			LEFT JOIN labels l ON s.id = l.secret_id
+			` + joinLabels + `
 	`
 
 	var (
 		args         []any
-		whereClauses []string
+		whereClauses = []string{"s.deleted_at IS NULL"}
 	)
 
 	if len(m.Wildcard) > 0 {
-		whereClauses = append(whereClauses, "(s.name GLOB ? OR l.name GLOB ?)")
-		args = append(args, m.Wildcard, m.Wildcard)
+		whereClauses = append(whereClauses,
+			`s.id IN (
+				SELECT id FROM secrets WHERE name GLOB ?
+				UNION
+				SELECT CAST(secret_id AS INTEGER) FROM labels WHERE name GLOB ?
+			)`,
+			"(s.name GLOB ? OR l.name GLOB ?)",
+		)
+		args = append(args, m.Wildcard, m.Wildcard, m.Wildcard, m.Wildcard)
 	}
 
 	if len(m.Name) > 0 {
@@ -256,8 +320,30 @@ func (s *VaultDB) FilterSecrets(ctx context.Context, m Filters) (map[int]SecretW
 		args = append(args, m.Name)
 	}
 
+	if len(m.URL) > 0 {
+		whereClauses = append(whereClauses, "s.url GLOB ?")
+		args = append(args, m.URL)
+	}
+
+	if len(m.Username) > 0 {
+		whereClauses = append(whereClauses, "s.username GLOB ?")
+		args = append(args, m.Username)
+	}
+
+	if len(m.Kind) > 0 {
+		whereClauses = append(whereClauses, "s.kind = ?")
+		args = append(args, m.Kind)
+	}
+
 	if len(m.Labels) > 0 {
 		clauses := make([]string, len(m.Labels))
+		for i := range clauses {
+			clauses[i] = "name GLOB ?"
+			args = append(args, m.Labels[i]) //nolint:wsl
+		}
+
+		whereClauses = append(whereClauses, "s.id IN (SELECT CAST(secret_id AS INTEGER) FROM labels WHERE "+strings.Join(clauses, " OR ")+")")
+
 		for i := range clauses {
 			clauses[i] = "l.name GLOB ?"
 			args = append(args, m.Labels[i]) //nolint:wsl
@@ -266,11 +352,9 @@ func (s *VaultDB) FilterSecrets(ctx context.Context, m Filters) (map[int]SecretW
 		whereClauses = append(whereClauses, "("+strings.Join(clauses, " OR ")+")")
 	}
 
-	if len(whereClauses) > 0 {
-		query += " WHERE " + strings.Join(whereClauses, " AND ")
-	}
+	query += " WHERE " + strings.Join(whereClauses, " AND ")
 
-	return s.secretsJoinLabels(ctx, query, args...)
+	return query, args
 }
 
 // secretsJoinLabels executes a query to join secrets with their labels.
@@ -309,7 +393,9 @@ func (s *VaultDB) ExportSecrets(ctx context.Context) (map[int]SecretWithLabels,
 		l.name AS label
 	FROM
 		secrets s
-		JOIN labels l ON s.id = l.secret_id;
+		` + joinLabels + `
+	WHERE
+		s.deleted_at IS NULL;
 	`
 
 	rows, err := s.db.QueryContext(ctx, query)
@@ -335,6 +421,116 @@ func (s *VaultDB) ExportSecrets(ctx context.Context) (map[int]SecretWithLabels,
 	return reduce(secrets), nil
 }
 
+// ShredSecretsByIDs overwrites the ciphertext and nonce of the given secrets,
+// of their chunks and of their previous versions, with random bytes of the same length.
+//
+// It is meant to be called prior to [VaultDB.DeleteSecretsByIDs],
+// so the original values do not survive in the database free pages.
+func (s *VaultDB) ShredSecretsByIDs(ctx context.Context, ids []int) (int64, error) {
+	if len(ids) == 0 {
+		return 0, ErrNoIDsProvided
+	}
+
+	placeholders := make([]string, len(ids))
+	for i := range ids {
+		placeholders[i] = "?"
+	}
+
+	query := `
+	UPDATE secrets
+	SET
+		ciphertext = randomblob(length(ciphertext)),
+		nonce = randomblob(length(nonce))
+	WHERE
+		id IN (` + strings.Join(placeholders, ",") + ")"
+
+	res, err := s.db.ExecContext(ctx, query, cmdutil.ToAnySlice(ids)...)
+	if err != nil {
+		return 0, err
+	}
+
+	chunksQuery := `
+	UPDATE secret_chunks
+	SET
+		ciphertext = randomblob(length(ciphertext)),
+		nonce = randomblob(length(nonce))
+	WHERE
+		secret_id IN (` + strings.Join(placeholders, ",") + ")"
+
+	if _, err := s.db.ExecContext(ctx, chunksQuery, cmdutil.ToAnySlice(ids)...); err != nil {
+		return 0, err
+	}
+
+	versionsQuery := `
+	UPDATE secret_versions
+	SET
+		ciphertext = randomblob(length(ciphertext)),
+		nonce = randomblob(length(nonce))
+	WHERE
+		secret_id IN (` + strings.Join(placeholders, ",") + ")"
+
+	if _, err := s.db.ExecContext(ctx, versionsQuery, cmdutil.ToAnySlice(ids)...); err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+const selectSecretsChangedAt = `
+	SELECT
+		s.id,
+		s.created_at,
+		a.created_at
+	FROM
+		secrets s
+		LEFT JOIN audit_log a ON a.secret_id = s.id
+		AND a.operation IN ('insert', 'update')
+	WHERE
+		s.deleted_at IS NULL
+`
+
+// SecretsChangedAt returns the time each secret value was last set, keyed by
+// secret id.
+//
+// It is based on the audit log, falling back to the secret creation time
+// for secrets predating it.
+func (s *VaultDB) SecretsChangedAt(ctx context.Context) (map[int]time.Time, error) {
+	rows, err := s.db.QueryContext(ctx, selectSecretsChangedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	changedAt := make(map[int]time.Time)
+
+	for rows.Next() {
+		var (
+			id        int
+			createdAt time.Time
+			auditedAt sql.NullTime
+		)
+
+		if err := rows.Scan(&id, &createdAt, &auditedAt); err != nil {
+			return nil, err
+		}
+
+		t := createdAt
+		if auditedAt.Valid {
+			t = auditedAt.Time
+		}
+
+		if t.After(changedAt[id]) {
+			changedAt[id] = t
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changedAt, nil
+}
+
 // DeleteSecretsByIDs deletes secrets by their IDs, along with their labels.
 func (s *VaultDB) DeleteSecretsByIDs(ctx context.Context, ids []int) (int64, error) {
 	if len(ids) == 0 {
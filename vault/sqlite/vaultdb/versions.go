@@ -0,0 +1,172 @@
+package vaultdb
+
+import (
+	"context"
+	"time"
+)
+
+// SecretVersion is a previous value of a secret.
+type SecretVersion struct {
+	Version    int
+	ReplacedAt time.Time // ReplacedAt is the time the value was replaced.
+}
+
+// SealedSecretVersion is a previous value of a secret, as stored.
+type SealedSecretVersion struct {
+	Version    int
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+const insertSecretVersion = `
+	INSERT INTO
+		secret_versions (secret_id, version, nonce, ciphertext)
+	SELECT
+		?, coalesce(max(version), 0) + 1, ?, ?
+	FROM
+		secret_versions
+	WHERE
+		secret_id = ?
+	RETURNING
+		version
+`
+
+// InsertSecretVersion keeps the given sealed value as the next version of
+// the secret, returning its version number.
+func (s *VaultDB) InsertSecretVersion(ctx context.Context, secretID int, nonce []byte, ciphertext []byte) (int, error) {
+	var version int
+	err := s.db.QueryRowContext(ctx, insertSecretVersion, secretID, nonce, ciphertext, secretID).Scan(&version)
+
+	return version, err
+}
+
+const selectSecretHistory = `
+	SELECT
+		version, replaced_at
+	FROM
+		secret_versions
+	WHERE
+		secret_id = ?
+	ORDER BY
+		version DESC
+`
+
+// SecretHistory returns the previous values of the given secret,
+// newest first, without the values themselves.
+func (s *VaultDB) SecretHistory(ctx context.Context, secretID int) ([]SecretVersion, error) {
+	rows, err := s.db.QueryContext(ctx, selectSecretHistory, secretID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var versions []SecretVersion
+
+	for rows.Next() {
+		var v SecretVersion
+		if err := rows.Scan(&v.Version, &v.ReplacedAt); err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, v)
+	}
+
+	return versions, rows.Err()
+}
+
+const selectSecretAtVersion = `
+	SELECT
+		nonce, ciphertext
+	FROM
+		secret_versions
+	WHERE
+		secret_id = ?
+		AND version = ?
+`
+
+// SecretAtVersion returns the sealed value of the given secret version.
+//
+// It returns [sql.ErrNoRows] if no such version exists.
+func (s *VaultDB) SecretAtVersion(ctx context.Context, secretID int, version int) (nonce []byte, ciphertext []byte, _ error) {
+	err := s.db.QueryRowContext(ctx, selectSecretAtVersion, secretID, version).Scan(&nonce, &ciphertext)
+
+	return nonce, ciphertext, err
+}
+
+const selectVersionsTableExists = `
+	SELECT
+		count(*)
+	FROM
+		sqlite_master
+	WHERE
+		type = 'table'
+		AND name = 'secret_versions'
+`
+
+// VersionedSecretIDs returns the set of ids of the secrets having previous versions.
+//
+// Vaults predating the versions table have none, as their integrity
+// is verified before they are migrated.
+func (s *VaultDB) VersionedSecretIDs(ctx context.Context) (map[int]bool, error) {
+	ids := make(map[int]bool)
+
+	var n int
+	if err := s.db.QueryRowContext(ctx, selectVersionsTableExists).Scan(&n); err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return ids, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT DISTINCT secret_id FROM secret_versions")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids[id] = true
+	}
+
+	return ids, rows.Err()
+}
+
+const selectSecretVersions = `
+	SELECT
+		version, nonce, ciphertext
+	FROM
+		secret_versions
+	WHERE
+		secret_id = ?
+	ORDER BY
+		version
+`
+
+// SealedSecretVersions returns the sealed previous values of the given
+// secret, ordered by version.
+func (s *VaultDB) SealedSecretVersions(ctx context.Context, secretID int) ([]SealedSecretVersion, error) {
+	rows, err := s.db.QueryContext(ctx, selectSecretVersions, secretID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:wsl
+
+	var versions []SealedSecretVersion
+
+	for rows.Next() {
+		var v SealedSecretVersion
+		if err := rows.Scan(&v.Version, &v.Nonce, &v.Ciphertext); err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, v)
+	}
+
+	return versions, rows.Err()
+}
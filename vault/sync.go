@@ -0,0 +1,869 @@
+package vault
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaultcrypto"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// Oplog operations.
+const (
+	oplogPut    = "put"    // oplogPut sets the full state of a secret.
+	oplogDelete = "delete" // oplogDelete deletes a secret.
+)
+
+const (
+	// oplogMagic prefixes exported oplog files, and binds their ciphertext to the format version.
+	oplogMagic = "vlt-oplog-v1"
+
+	syncDeviceIDLen = 8
+	syncKeyLen      = 32
+)
+
+// oplogPayload is the secret state recorded by an oplog entry, sealed using the sync key.
+type oplogPayload struct {
+	Name   string   `json:"name,omitempty"`
+	Value  string   `json:"value,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+
+	URL      string `json:"url,omitempty"`
+	Username string `json:"username,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+
+	// Binary holds the value instead, if it is not valid UTF-8,
+	// which JSON strings cannot hold.
+	Binary []byte `json:"binary,omitempty"`
+}
+
+func (p *oplogPayload) meta() vaultdb.SecretMeta {
+	return vaultdb.SecretMeta{URL: p.URL, Username: p.Username, Notes: p.Notes, Kind: p.Kind}
+}
+
+// MarshalJSON encodes values that are not valid UTF-8 as [oplogPayload.Binary].
+func (p oplogPayload) MarshalJSON() ([]byte, error) {
+	type payload oplogPayload
+
+	if !utf8.ValidString(p.Value) {
+		p.Binary, p.Value = []byte(p.Value), ""
+	}
+
+	return json.Marshal(payload(p))
+}
+
+// UnmarshalJSON decodes [oplogPayload.Binary] values as [oplogPayload.Value].
+func (p *oplogPayload) UnmarshalJSON(b []byte) error {
+	type payload oplogPayload
+
+	if err := json.Unmarshal(b, (*payload)(p)); err != nil {
+		return err
+	}
+
+	if p.Binary != nil {
+		p.Value, p.Binary = string(p.Binary), nil
+	}
+
+	return nil
+}
+
+// oplogFile is the plaintext of an exported oplog.
+//
+//nolint:tagliatelle
+type oplogFile struct {
+	Device  string       `json:"device"`
+	Entries []oplogEntry `json:"entries"`
+}
+
+//nolint:tagliatelle
+type oplogEntry struct {
+	Seq       int            `json:"seq"`
+	Clock     map[string]int `json:"clock"`
+	SecretUID string         `json:"secret_uid"`
+	Operation string         `json:"operation"`
+	Nonce     []byte         `json:"nonce"`
+	Payload   []byte         `json:"payload"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// SyncStatus describes the sync state of the vault.
+type SyncStatus struct {
+	Device string         // Device is the id of this device.
+	Clock  map[string]int // Clock maps the id of every known device to its latest merged sequence number.
+}
+
+// SyncResult summarizes an oplog merge.
+type SyncResult struct {
+	Device   string // Device is the id of the device that wrote the oplog.
+	Entries  int    // Entries is the number of new entries merged.
+	Inserted int
+	Updated  int
+	Deleted  int
+
+	// Conflicts is the number of secrets changed concurrently
+	// by both devices, resolved using the given [ConflictResolver].
+	Conflicts int
+}
+
+// InitSync enables sync by recording the current state of every secret
+// in the oplog, returning the id of this device.
+//
+// Every other synced device must start from a copy of the vault, with
+// newDevice set to assign it a device id of its own while keeping the
+// sync key and the oplog.
+func (vlt *Vault) InitSync(ctx context.Context, newDevice bool) (device string, retErr error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return "", errf("init sync: %w", err)
+	}
+
+	if err := vlt.checkWritable(ctx); err != nil {
+		return "", errf("init sync: %w", err)
+	}
+
+	state, err := vlt.db.SyncState(ctx)
+	exists := err == nil
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", errf("init sync: %w", err)
+	}
+
+	if exists && !newDevice {
+		return "", errf("init sync: %w: device %s", vaulterrors.ErrSyncInitialized, state.Device)
+	}
+
+	if !exists && newDevice {
+		return "", errf("init sync: %w", vaulterrors.ErrSyncNotInitialized)
+	}
+
+	id, err := vaultcrypto.RandBytes(syncDeviceIDLen)
+	if err != nil {
+		return "", errf("init sync: %w", err)
+	}
+
+	state.Device = hex.EncodeToString(id)
+
+	if !exists {
+		if state.Key, err = vaultcrypto.RandBytes(syncKeyLen); err != nil {
+			return "", errf("init sync: %w", err)
+		}
+	}
+
+	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return "", errf("init sync: %w", err)
+	}
+	defer func() { //nolint:wsl
+		if retErr != nil {
+			if err := tx.Rollback(); err != nil {
+				retErr = errors.Join(retErr, errf("init sync: rollback: %w", err))
+			}
+		}
+	}()
+
+	storeTx := vlt.db.WithTx(tx)
+
+	if err := storeTx.SetSyncState(ctx, state); err != nil {
+		return "", errf("init sync: %w", err)
+	}
+
+	if !exists {
+		secrets, err := storeTx.ExportSecrets(ctx)
+		if err != nil {
+			return "", errf("init sync: %w", err)
+		}
+
+		for _, id := range slices.Sorted(maps.Keys(secrets)) {
+			if err := vlt.record(ctx, storeTx, oplogPut, id); err != nil {
+				return "", errf("init sync: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", errf("init sync: tx commit: %w", err)
+	}
+
+	return state.Device, nil
+}
+
+// SyncStatus returns the sync state of the vault.
+func (vlt *Vault) SyncStatus(ctx context.Context) (*SyncStatus, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return nil, errf("sync status: %w", err)
+	}
+
+	state, err := vlt.syncState(ctx, vlt.db)
+	if err != nil {
+		return nil, errf("sync status: %w", err)
+	}
+
+	clock, err := vlt.db.OplogClock(ctx)
+	if err != nil {
+		return nil, errf("sync status: %w", err)
+	}
+
+	if _, ok := clock[state.Device]; !ok {
+		clock[state.Device] = 0
+	}
+
+	return &SyncStatus{Device: state.Device, Clock: clock}, nil
+}
+
+// ExportOplog returns the oplog entries recorded by this device,
+// sealed using the sync key.
+//
+// The result is meant to be shared with the other synced devices
+// through any remote, see [Vault.ImportOplog].
+func (vlt *Vault) ExportOplog(ctx context.Context) ([]byte, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return nil, errf("export oplog: %w", err)
+	}
+
+	state, err := vlt.syncState(ctx, vlt.db)
+	if err != nil {
+		return nil, errf("export oplog: %w", err)
+	}
+
+	entries, err := vlt.db.OplogEntriesByDevice(ctx, state.Device)
+	if err != nil {
+		return nil, errf("export oplog: %w", err)
+	}
+
+	f := oplogFile{
+		Device:  state.Device,
+		Entries: make([]oplogEntry, 0, len(entries)),
+	}
+
+	for _, e := range entries {
+		f.Entries = append(f.Entries, oplogEntry{
+			Seq:       e.Seq,
+			Clock:     e.Clock,
+			SecretUID: e.SecretUID,
+			Operation: e.Operation,
+			Nonce:     e.Nonce,
+			Payload:   e.Payload,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	plaintext, err := json.Marshal(f)
+	if err != nil {
+		return nil, errf("export oplog: %w", err)
+	}
+
+	aes, err := vaultcrypto.NewAESGCM(state.Key)
+	if err != nil {
+		return nil, errf("export oplog: %w", err)
+	}
+
+	nonce, err := vaultcrypto.RandBytes(aes.AEAD().NonceSize())
+	if err != nil {
+		return nil, errf("export oplog: %w", err)
+	}
+
+	out := append([]byte(oplogMagic), nonce...)
+
+	return aes.AEAD().Seal(out, nonce, plaintext, []byte(oplogMagic)), nil
+}
+
+// ImportOplog merges an oplog exported by another synced device
+// using [Vault.ExportOplog].
+//
+// Entries already merged are skipped, so importing the same oplog is idempotent.
+// For every secret affected by new entries, the state recorded by the last entry
+// is applied, where entries are ordered consistently with causality, and
+// concurrent entries are ordered by their vector clock sum, device id and
+// sequence number. Devices that merged the same entries converge to the same state.
+//
+// If both devices changed a secret concurrently, resolve picks the version to keep,
+// see [ConflictResolver]. A nil resolve keeps the newer version. The resolution is
+// recorded as a new entry of this device, which follows both versions, so other
+// devices converge to it without resolving the conflict again.
+func (vlt *Vault) ImportOplog(ctx context.Context, data []byte, resolve ConflictResolver) (_ *SyncResult, retErr error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return nil, errf("import oplog: %w", err)
+	}
+
+	if err := vlt.checkWritable(ctx); err != nil {
+		return nil, errf("import oplog: %w", err)
+	}
+
+	state, err := vlt.syncState(ctx, vlt.db)
+	if err != nil {
+		return nil, errf("import oplog: %w", err)
+	}
+
+	f, err := openOplogFile(state.Key, data)
+	if err != nil {
+		return nil, errf("import oplog: %w", err)
+	}
+
+	clock, err := vlt.db.OplogClock(ctx)
+	if err != nil {
+		return nil, errf("import oplog: %w", err)
+	}
+
+	res := &SyncResult{Device: f.Device}
+
+	var newEntries []vaultdb.OplogEntry
+
+	for _, e := range f.Entries {
+		if e.Seq <= clock[f.Device] {
+			continue
+		}
+
+		if f.Device == state.Device {
+			return nil, errf("import oplog: %w: %s", vaulterrors.ErrSyncDeviceConflict, state.Device)
+		}
+
+		if e.Seq != clock[f.Device]+len(newEntries)+1 {
+			return nil, errf("import oplog: device %s: missing entries before sequence number %d", f.Device, e.Seq)
+		}
+
+		entry := vaultdb.OplogEntry{
+			Device:    f.Device,
+			Seq:       e.Seq,
+			Clock:     e.Clock,
+			SecretUID: e.SecretUID,
+			Operation: e.Operation,
+			Nonce:     e.Nonce,
+			Payload:   e.Payload,
+			CreatedAt: e.CreatedAt,
+		}
+
+		// verify the payload is authentic before it is merged.
+		if _, err := openOplogPayload(state.Key, entry); err != nil {
+			return nil, errf("import oplog: %w", err)
+		}
+
+		newEntries = append(newEntries, entry)
+	}
+
+	if len(newEntries) == 0 {
+		return res, nil
+	}
+
+	if resolve == nil {
+		resolve = ResolveNewer
+	}
+
+	var uids []string
+
+	for _, e := range newEntries {
+		if !slices.Contains(uids, e.SecretUID) {
+			uids = append(uids, e.SecretUID)
+		}
+	}
+
+	// conflicts are resolved before the merge transaction,
+	// since resolve may block on user input.
+	winners := make(map[string]vaultdb.OplogEntry, len(uids))
+	resolutions := make(map[string]*oplogPayload)
+
+	for _, uid := range uids {
+		known, err := vlt.db.OplogEntriesBySecret(ctx, uid)
+		if err != nil {
+			return nil, errf("import oplog: %w", err)
+		}
+
+		remote := slices.DeleteFunc(slices.Clone(newEntries), func(e vaultdb.OplogEntry) bool { return e.SecretUID != uid })
+
+		winner, conflict, err := mergeOplog(ctx, state.Key, known, remote, resolve)
+		if err != nil {
+			return nil, errf("import oplog: secret %s: %w", uid, err)
+		}
+
+		winners[uid] = winner
+
+		if !conflict {
+			continue
+		}
+
+		res.Conflicts++
+
+		p, err := openOplogPayload(state.Key, winner)
+		if err != nil {
+			return nil, errf("import oplog: secret %s: %w", uid, err)
+		}
+
+		resolutions[uid] = p
+	}
+
+	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, errf("import oplog: %w", err)
+	}
+	defer func() { //nolint:wsl
+		if retErr != nil {
+			if err := tx.Rollback(); err != nil {
+				retErr = errors.Join(retErr, errf("import oplog: rollback: %w", err))
+			}
+		}
+	}()
+
+	storeTx := vlt.db.WithTx(tx)
+
+	for _, e := range newEntries {
+		if err := storeTx.InsertOplogEntry(ctx, e); err != nil {
+			return nil, errf("import oplog: %w", err)
+		}
+	}
+
+	for _, uid := range uids {
+		p, ok := resolutions[uid]
+		if !ok {
+			continue
+		}
+
+		if err := vlt.appendOplog(ctx, storeTx, state, uid, winners[uid].Operation, p); err != nil {
+			return nil, errf("import oplog: secret %s: %w", uid, err)
+		}
+	}
+
+	res.Entries = len(newEntries)
+
+	// merged changes are attributed to the device that made them.
+	ctx = WithPrincipal(ctx, Principal{Actor: "sync:" + f.Device})
+
+	var audited []vaultdb.AuditEntry
+
+	for _, uid := range uids {
+		entry, ok, err := vlt.applyOplog(ctx, storeTx, state.Key, winners[uid])
+		if err != nil {
+			return nil, errf("import oplog: secret %s: %w", uid, err)
+		}
+
+		if !ok {
+			continue
+		}
+
+		switch entry.Operation {
+		case vaultdb.OpInsert:
+			res.Inserted++
+		case vaultdb.OpUpdate:
+			res.Updated++
+		case vaultdb.OpDelete:
+			res.Deleted++
+		}
+
+		audited = append(audited, entry)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errf("import oplog: tx commit: %w", err)
+	}
+
+	vlt.emit(audited...)
+
+	if res.Deleted > 0 {
+		if err := vlt.vacuum(ctx); err != nil {
+			return res, errf("import oplog: vacuum: %w", err)
+		}
+	}
+
+	return res, nil
+}
+
+// applyOplog applies the secret state recorded by the given oplog entry,
+// returning the audit entry of the change, if any.
+func (vlt *Vault) applyOplog(ctx context.Context, store *vaultdb.VaultDB, key []byte, last vaultdb.OplogEntry) (vaultdb.AuditEntry, bool, error) {
+	uid := last.SecretUID
+
+	id, err := store.SecretIDByUID(ctx, uid)
+	exists := err == nil
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return vaultdb.AuditEntry{}, false, err
+	}
+
+	if last.Operation == oplogDelete {
+		if !exists {
+			return vaultdb.AuditEntry{}, false, nil
+		}
+
+		// secrets already in the trash are left as is.
+		if n, err := store.TrashSecretsByIDs(ctx, []int{id}); err != nil || n == 0 {
+			return vaultdb.AuditEntry{}, false, err
+		}
+
+		entry, err := vlt.audit(ctx, store, vaultdb.OpDelete, id)
+
+		return entry, err == nil, err
+	}
+
+	p, err := openOplogPayload(key, last)
+	if err != nil {
+		return vaultdb.AuditEntry{}, false, err
+	}
+
+	if !exists {
+		return vlt.insertOplogSecret(ctx, store, uid, p)
+	}
+
+	// puts of secrets in the trash restore them, e.g., restored on another device.
+	if _, err := store.RestoreTrashedSecret(ctx, id); err != nil {
+		return vaultdb.AuditEntry{}, false, err
+	}
+
+	return vlt.updateOplogSecret(ctx, store, id, p)
+}
+
+func (vlt *Vault) insertOplogSecret(ctx context.Context, store *vaultdb.VaultDB, uid string, p *oplogPayload) (vaultdb.AuditEntry, bool, error) {
+	nonce, ciphertext, chunks, err := vlt.sealValue(p.Value)
+	if err != nil {
+		return vaultdb.AuditEntry{}, false, err
+	}
+
+	id, err := store.InsertSecretWithUID(ctx, uid, p.Name, p.meta(), nonce, ciphertext)
+	if err != nil {
+		return vaultdb.AuditEntry{}, false, err
+	}
+
+	if err := store.ReplaceSecretChunks(ctx, id, chunks); err != nil {
+		return vaultdb.AuditEntry{}, false, err
+	}
+
+	for _, l := range p.Labels {
+		if _, err := store.InsertLabel(ctx, l, id); err != nil {
+			return vaultdb.AuditEntry{}, false, err
+		}
+	}
+
+	entry, err := vlt.audit(ctx, store, vaultdb.OpInsert, id)
+
+	return entry, err == nil, err
+}
+
+func (vlt *Vault) updateOplogSecret(ctx context.Context, store *vaultdb.VaultDB, id int, p *oplogPayload) (vaultdb.AuditEntry, bool, error) {
+	curr, err := vlt.secretState(ctx, store, id)
+	if err != nil {
+		return vaultdb.AuditEntry{}, false, err
+	}
+
+	if equalPayloads(curr, p) {
+		return vaultdb.AuditEntry{}, false, nil
+	}
+
+	if curr.Name != p.Name {
+		if _, err := store.UpdateName(ctx, id, p.Name); err != nil {
+			return vaultdb.AuditEntry{}, false, err
+		}
+	}
+
+	if curr.Value != p.Value {
+		if err := vlt.keepVersion(ctx, store, id, p.Value); err != nil {
+			return vaultdb.AuditEntry{}, false, err
+		}
+
+		nonce, ciphertext, chunks, err := vlt.sealValue(p.Value)
+		if err != nil {
+			return vaultdb.AuditEntry{}, false, err
+		}
+
+		if _, err := store.UpdateSecret(ctx, id, nonce, ciphertext); err != nil {
+			return vaultdb.AuditEntry{}, false, err
+		}
+
+		if err := store.ReplaceSecretChunks(ctx, id, chunks); err != nil {
+			return vaultdb.AuditEntry{}, false, err
+		}
+	}
+
+	if curr.meta() != p.meta() {
+		if _, err := store.UpdateSecretMeta(ctx, id, p.meta()); err != nil {
+			return vaultdb.AuditEntry{}, false, err
+		}
+	}
+
+	if !slices.Equal(curr.Labels, p.Labels) {
+		if _, err := store.DeleteSecretLabels(ctx, id); err != nil {
+			return vaultdb.AuditEntry{}, false, err
+		}
+
+		for _, l := range p.Labels {
+			if _, err := store.InsertLabel(ctx, l, id); err != nil {
+				return vaultdb.AuditEntry{}, false, err
+			}
+		}
+	}
+
+	entry, err := vlt.audit(ctx, store, vaultdb.OpUpdate, id)
+
+	return entry, err == nil, err
+}
+
+// record appends an oplog entry recording the given operation on the
+// secret with the given id, if sync is initialized.
+//
+// Puts record the current state of the secret, so they must be recorded
+// after the secret is changed, and deletes before it is deleted.
+func (vlt *Vault) record(ctx context.Context, store *vaultdb.VaultDB, op string, id int) error {
+	state, err := store.SyncState(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("oplog: %w", err)
+	}
+
+	uid, err := store.SecretUID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("oplog: %w", err)
+	}
+
+	p := &oplogPayload{}
+
+	if op == oplogPut {
+		if p, err = vlt.secretState(ctx, store, id); err != nil {
+			return fmt.Errorf("oplog: %w", err)
+		}
+	}
+
+	return vlt.appendOplog(ctx, store, state, uid, op, p)
+}
+
+// shredOplog reseals the states recorded by the oplog entries of the secrets
+// with the given ids as empty ones, if sync is initialized, so that the values
+// of purged secrets do not survive in the oplog.
+//
+// The entries themselves are kept, since synced devices rely on their sequence
+// numbers. It must be called before the secrets are deleted.
+func (*Vault) shredOplog(ctx context.Context, store *vaultdb.VaultDB, ids []int) error {
+	state, err := store.SyncState(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("oplog: %w", err)
+	}
+
+	for _, id := range ids {
+		uid, err := store.SecretUID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("oplog: %w", err)
+		}
+
+		entries, err := store.OplogEntriesBySecret(ctx, uid)
+		if err != nil {
+			return fmt.Errorf("oplog: %w", err)
+		}
+
+		for _, e := range entries {
+			if e.Operation != oplogPut {
+				continue
+			}
+
+			nonce, payload, err := sealOplogPayload(state.Key, e, &oplogPayload{})
+			if err != nil {
+				return fmt.Errorf("oplog: %w", err)
+			}
+
+			if err := store.ReplaceOplogPayload(ctx, e.Device, e.Seq, nonce, payload); err != nil {
+				return fmt.Errorf("oplog: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// appendOplog appends an oplog entry of this device recording
+// the given operation and secret state.
+func (*Vault) appendOplog(ctx context.Context, store *vaultdb.VaultDB, state vaultdb.SyncState, uid string, op string, p *oplogPayload) error {
+	clock, err := store.OplogClock(ctx)
+	if err != nil {
+		return fmt.Errorf("oplog: %w", err)
+	}
+
+	clock[state.Device]++
+
+	e := vaultdb.OplogEntry{
+		Device:    state.Device,
+		Seq:       clock[state.Device],
+		Clock:     clock,
+		SecretUID: uid,
+		Operation: op,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if e.Nonce, e.Payload, err = sealOplogPayload(state.Key, e, p); err != nil {
+		return fmt.Errorf("oplog: %w", err)
+	}
+
+	if err := store.InsertOplogEntry(ctx, e); err != nil {
+		return fmt.Errorf("oplog: %w", err)
+	}
+
+	return nil
+}
+
+// secretState returns the current state of the secret with the given id, with sorted labels.
+func (vlt *Vault) secretState(ctx context.Context, store *vaultdb.VaultDB, id int) (*oplogPayload, error) {
+	secrets, err := store.SecretsByIDs(ctx, []int{id})
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := secrets[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	value, err := vlt.openValue(ctx, store, id)
+	if err != nil {
+		return nil, err
+	}
+
+	metas, err := store.SecretMetas(ctx, []int{id})
+	if err != nil {
+		return nil, err
+	}
+
+	labels := slices.Clone(s.Labels)
+	slices.Sort(labels)
+
+	meta := metas[id]
+
+	return &oplogPayload{
+		Name:     s.Name,
+		Value:    string(value),
+		Labels:   labels,
+		URL:      meta.URL,
+		Username: meta.Username,
+		Notes:    meta.Notes,
+		Kind:     meta.Kind,
+	}, nil
+}
+
+// sealSecret encrypts the given secret value using a fresh nonce.
+func (vlt *Vault) sealSecret(value string) (nonce []byte, ciphertext []byte, _ error) {
+	nonce, err := vaultcrypto.RandBytes(12)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ciphertext, err = vlt.aesgcm.Seal(nonce, []byte(value))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nonce, ciphertext, nil
+}
+
+func (*Vault) syncState(ctx context.Context, store *vaultdb.VaultDB) (vaultdb.SyncState, error) {
+	state, err := store.SyncState(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return vaultdb.SyncState{}, vaulterrors.ErrSyncNotInitialized
+	}
+
+	return state, err
+}
+
+// compareOplog orders oplog entries deterministically.
+//
+// If a happened before b, its vector clock is smaller in every component,
+// and therefore also in sum, so the order is consistent with causality.
+func compareOplog(a, b vaultdb.OplogEntry) int {
+	return cmp.Or(
+		cmp.Compare(clockSum(a.Clock), clockSum(b.Clock)),
+		cmp.Compare(a.Device, b.Device),
+		cmp.Compare(a.Seq, b.Seq),
+	)
+}
+
+func clockSum(clock map[string]int) int {
+	sum := 0
+	for _, n := range clock {
+		sum += n
+	}
+
+	return sum
+}
+
+// oplogAAD binds the sealed payload of an oplog entry to its metadata.
+func oplogAAD(e vaultdb.OplogEntry) []byte {
+	return []byte(oplogMagic + "\n" + e.Device + "\n" + strconv.Itoa(e.Seq) + "\n" + e.SecretUID + "\n" + e.Operation)
+}
+
+func sealOplogPayload(key []byte, e vaultdb.OplogEntry, p *oplogPayload) (nonce []byte, sealed []byte, _ error) {
+	plaintext, err := json.Marshal(p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aes, err := vaultcrypto.NewAESGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce, err = vaultcrypto.RandBytes(aes.AEAD().NonceSize())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nonce, aes.AEAD().Seal(nil, nonce, plaintext, oplogAAD(e)), nil
+}
+
+func openOplogPayload(key []byte, e vaultdb.OplogEntry) (*oplogPayload, error) {
+	aes, err := vaultcrypto.NewAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aes.AEAD().Open(nil, e.Nonce, e.Payload, oplogAAD(e))
+	if err != nil {
+		return nil, fmt.Errorf("device %s: entry %d: %w", e.Device, e.Seq, vaulterrors.ErrSyncKeyMismatch)
+	}
+
+	var p oplogPayload
+	if err := json.Unmarshal(plaintext, &p); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func openOplogFile(key []byte, data []byte) (*oplogFile, error) {
+	aes, err := vaultcrypto.NewAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	rest, ok := bytes.CutPrefix(data, []byte(oplogMagic))
+	if !ok || len(rest) < aes.AEAD().NonceSize() {
+		return nil, errors.New("not an oplog file")
+	}
+
+	nonce, ciphertext := rest[:aes.AEAD().NonceSize()], rest[aes.AEAD().NonceSize():]
+
+	plaintext, err := aes.AEAD().Open(nil, nonce, ciphertext, []byte(oplogMagic))
+	if err != nil {
+		return nil, vaulterrors.ErrSyncKeyMismatch
+	}
+
+	var f oplogFile
+	if err := json.Unmarshal(plaintext, &f); err != nil {
+		return nil, err
+	}
+
+	if len(f.Device) == 0 {
+		return nil, errors.New("oplog file: missing device id")
+	}
+
+	return &f, nil
+}
@@ -0,0 +1,261 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// syncedVaults returns two synced vaults, the second cloned from the first.
+func syncedVaults(t *testing.T) (a *Vault, b *Vault) {
+	t.Helper()
+
+	dir := t.TempDir()
+	pathA, pathB := filepath.Join(dir, "a.db"), filepath.Join(dir, "b.db")
+
+	a, err := New(t.Context(), pathA, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.InsertNewSecret(t.Context(), "shared", "v1", []string{"x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.InitSync(t.Context(), false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(pathA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(pathB, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err = Open(t.Context(), pathA, WithPassword("password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = Open(t.Context(), pathB, WithPassword("password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = a.Close(context.Background())
+		_ = b.Close(context.Background())
+	})
+
+	if _, err := b.InitSync(t.Context(), true); err != nil {
+		t.Fatal(err)
+	}
+
+	return a, b
+}
+
+func exchange(t *testing.T, from *Vault, to *Vault) *SyncResult {
+	t.Helper()
+
+	data, err := from.ExportOplog(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := to.ImportOplog(t.Context(), data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res
+}
+
+func secretsByName(t *testing.T, v *Vault) map[string]string {
+	t.Helper()
+
+	secrets, err := v.ExportSecrets(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := make(map[string]string, len(secrets))
+	for _, s := range secrets {
+		m[s.Name] = s.Value
+	}
+
+	return m
+}
+
+func TestVault_Sync(t *testing.T) {
+	a, b := syncedVaults(t)
+
+	sharedID := 1
+
+	if _, err := a.InsertNewSecret(t.Context(), "from-a", "a", []string{"x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	fromB, err := b.InsertNewSecret(t.Context(), "from-b", "b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// concurrent updates of the same secret.
+	if _, err := a.UpdateSecret(t.Context(), sharedID, "v2-a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.UpdateSecret(t.Context(), sharedID, "v2-b"); err != nil {
+		t.Fatal(err)
+	}
+
+	resB := exchange(t, a, b)
+	resA := exchange(t, b, a)
+
+	if resB.Inserted != 1 || resA.Inserted != 1 {
+		t.Errorf("inserted: got %d and %d, want 1 and 1", resB.Inserted, resA.Inserted)
+	}
+
+	gotA, gotB := secretsByName(t, a), secretsByName(t, b)
+	if len(gotA) != 3 {
+		t.Errorf("secrets: got %v, want 3 secrets", gotA)
+	}
+
+	for name, value := range gotA {
+		if gotB[name] != value {
+			t.Errorf("secret %q: diverged, got %q and %q", name, value, gotB[name])
+		}
+	}
+
+	// a delete that causally follows the merge wins on both devices.
+	if _, err := b.DeleteSecretsByIDs(t.Context(), fromB); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := exchange(t, b, a); res.Deleted != 1 {
+		t.Errorf("deleted: got %d, want 1", res.Deleted)
+	}
+
+	if _, ok := secretsByName(t, a)["from-b"]; ok {
+		t.Error("deleted secret was not removed")
+	}
+
+	// importing the same oplog again is a no-op.
+	if res := exchange(t, b, a); res.Entries != 0 {
+		t.Errorf("re-import: got %d new entries, want 0", res.Entries)
+	}
+}
+
+func TestVault_Sync_Binary(t *testing.T) {
+	a, b := syncedVaults(t)
+
+	value := string([]byte{0x30, 0x82, 0xff, 0x00, 0xfe, '\n'}) // not valid UTF-8.
+
+	if _, err := a.InsertNewSecret(t.Context(), "key.der", value, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	exchange(t, a, b)
+
+	if got := secretsByName(t, b)["key.der"]; got != value {
+		t.Errorf("synced binary value: got %q, want %q", got, value)
+	}
+}
+
+func TestVault_Sync_DeviceConflict(t *testing.T) {
+	a, b := syncedVaults(t)
+
+	// b takes over the device id of a, and diverges.
+	stateA, err := a.db.SyncState(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.db.SetSyncState(t.Context(), stateA); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.InsertNewSecret(t.Context(), "new", "secret", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := b.ExportOplog(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.ImportOplog(t.Context(), data, nil); !errors.Is(err, vaulterrors.ErrSyncDeviceConflict) {
+		t.Errorf("import: got err %v, want %v", err, vaulterrors.ErrSyncDeviceConflict)
+	}
+}
+
+func TestVault_Sync_Conflict(t *testing.T) {
+	tests := []struct {
+		name    string
+		resolve ConflictResolver
+		want    string
+	}{
+		{name: "local", resolve: ResolveLocal, want: "v2-b"},
+		{name: "remote", resolve: ResolveRemote, want: "v2-a"},
+		{name: "newer", resolve: ResolveNewer, want: "v2-b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := syncedVaults(t)
+
+			sharedID := 1
+
+			if _, err := a.UpdateSecret(t.Context(), sharedID, "v2-a"); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := b.UpdateSecret(t.Context(), sharedID, "v2-b"); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []SyncConflict
+
+			data, err := a.ExportOplog(t.Context())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := b.ImportOplog(t.Context(), data, func(ctx context.Context, c SyncConflict) (Resolution, error) {
+				got = append(got, c)
+				return tt.resolve(ctx, c)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Conflicts != 1 || len(got) != 1 {
+				t.Fatalf("conflicts: got %d, want 1", res.Conflicts)
+			}
+
+			if c := got[0]; c.Base == nil || c.Base.Name != "shared" || !c.Local.ValueChanged || !c.Remote.ValueChanged {
+				t.Errorf("conflict: got %+v, want a shared base and changed values", c)
+			}
+
+			// the resolution is followed without resolving the conflict again.
+			if res := exchange(t, b, a); res.Conflicts != 0 {
+				t.Errorf("conflicts: got %d after resolution, want 0", res.Conflicts)
+			}
+
+			if gotA, gotB := secretsByName(t, a)["shared"], secretsByName(t, b)["shared"]; gotA != tt.want || gotB != tt.want {
+				t.Errorf("shared: got %q and %q, want %q", gotA, gotB, tt.want)
+			}
+		})
+	}
+}
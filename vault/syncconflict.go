@@ -0,0 +1,145 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+// Resolution is the outcome of a sync conflict.
+type Resolution int
+
+const (
+	KeepLocal  Resolution = iota // KeepLocal keeps the version of this device.
+	KeepRemote                   // KeepRemote keeps the version of the imported oplog.
+)
+
+// SyncVersion describes a version of a secret recorded in the oplog.
+//
+// Secret values are never exposed, only whether they were changed.
+type SyncVersion struct {
+	Device  string    // Device is the id of the device that recorded the version.
+	Time    time.Time // Time is when the version was recorded, by the clock of its device.
+	Deleted bool      // Deleted reports whether the secret was deleted.
+	Name    string
+	Labels  []string
+
+	// ValueChanged reports whether the value differs from the base version.
+	// It is always set if the base version is unknown, and never for the base itself.
+	ValueChanged bool
+}
+
+// SyncConflict describes concurrent changes of the same secret by two devices.
+type SyncConflict struct {
+	UID    string       // UID is the sync id of the secret.
+	Base   *SyncVersion // Base is the last version known to both devices, nil if unknown.
+	Local  SyncVersion
+	Remote SyncVersion
+}
+
+// ConflictResolver picks the version of a secret to keep,
+// when it was changed concurrently by two devices.
+type ConflictResolver func(ctx context.Context, c SyncConflict) (Resolution, error)
+
+// ResolveNewer keeps the most recently recorded version, preferring the local one on ties.
+func ResolveNewer(_ context.Context, c SyncConflict) (Resolution, error) {
+	if c.Remote.Time.After(c.Local.Time) {
+		return KeepRemote, nil
+	}
+
+	return KeepLocal, nil
+}
+
+// ResolveLocal always keeps the version of this device.
+func ResolveLocal(context.Context, SyncConflict) (Resolution, error) { return KeepLocal, nil }
+
+// ResolveRemote always keeps the version of the imported oplog.
+func ResolveRemote(context.Context, SyncConflict) (Resolution, error) { return KeepRemote, nil }
+
+// mergeOplog returns the entry recording the secret state to apply,
+// given the known and the newly imported entries of the secret.
+//
+// The last entry by [compareOplog] is returned, unless the last known and the last
+// imported entries are concurrent and record different states, in which case
+// resolve picks between them and conflict is set.
+func mergeOplog(ctx context.Context, key []byte, known []vaultdb.OplogEntry, remote []vaultdb.OplogEntry, resolve ConflictResolver) (_ vaultdb.OplogEntry, conflict bool, _ error) {
+	r := slices.MaxFunc(remote, compareOplog)
+	if len(known) == 0 {
+		return r, false, nil
+	}
+
+	l := slices.MaxFunc(known, compareOplog)
+
+	// the imported entry follows the known one, this is not a conflict.
+	if r.Clock[l.Device] >= l.Seq {
+		return r, false, nil
+	}
+
+	lp, err := openOplogPayload(key, l)
+	if err != nil {
+		return vaultdb.OplogEntry{}, false, err
+	}
+
+	rp, err := openOplogPayload(key, r)
+	if err != nil {
+		return vaultdb.OplogEntry{}, false, err
+	}
+
+	if l.Operation == r.Operation && (l.Operation == oplogDelete || equalPayloads(lp, rp)) {
+		return slices.MaxFunc([]vaultdb.OplogEntry{l, r}, compareOplog), false, nil
+	}
+
+	c := SyncConflict{UID: r.SecretUID}
+
+	var base *oplogPayload
+
+	// the base is the last known entry the imported entries followed.
+	baseEntries := slices.DeleteFunc(slices.Clone(known), func(e vaultdb.OplogEntry) bool { return r.Clock[e.Device] < e.Seq })
+	if len(baseEntries) > 0 {
+		b := slices.MaxFunc(baseEntries, compareOplog)
+
+		if base, err = openOplogPayload(key, b); err != nil {
+			return vaultdb.OplogEntry{}, false, err
+		}
+
+		v := syncVersion(b, base, base)
+		c.Base = &v
+	}
+
+	c.Local, c.Remote = syncVersion(l, lp, base), syncVersion(r, rp, base)
+
+	res, err := resolve(ctx, c)
+	if err != nil {
+		return vaultdb.OplogEntry{}, false, err
+	}
+
+	switch res {
+	case KeepLocal:
+		return l, true, nil
+	case KeepRemote:
+		return r, true, nil
+	default:
+		return vaultdb.OplogEntry{}, false, fmt.Errorf("unknown conflict resolution: %d", res)
+	}
+}
+
+func syncVersion(e vaultdb.OplogEntry, p *oplogPayload, base *oplogPayload) SyncVersion {
+	if e.Operation == oplogDelete {
+		return SyncVersion{Device: e.Device, Time: e.CreatedAt, Deleted: true}
+	}
+
+	return SyncVersion{
+		Device:       e.Device,
+		Time:         e.CreatedAt,
+		Name:         p.Name,
+		Labels:       p.Labels,
+		ValueChanged: base == nil || base.Value != p.Value,
+	}
+}
+
+func equalPayloads(a, b *oplogPayload) bool {
+	return a.Name == b.Name && a.Value == b.Value && slices.Equal(a.Labels, b.Labels) && a.meta() == b.meta()
+}
@@ -0,0 +1,232 @@
+package vault
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultcontainer"
+	"github.com/ladzaretti/vlt-cli/vaultcrypto"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+const (
+	// tokenPrefix prefixes token strings, which have the form 'vlt_<id>_<secret>'.
+	tokenPrefix = "vlt_"
+
+	// tokenKeyInfo binds keys derived from token secrets to their purpose.
+	tokenKeyInfo = "vlt-token-v1"
+
+	tokenIDLen     = 8
+	tokenSecretLen = 32
+)
+
+// TokenOptions configure an access token issued by [Vault.IssueToken].
+type TokenOptions struct {
+	Scope    []string      // Scope are label globs, secrets with a matching label are accessible.
+	ReadOnly bool          // ReadOnly rejects mutations when the vault is opened with the token.
+	TTL      time.Duration // TTL is the token lifetime.
+}
+
+// tokenPayload is sealed using the token secret and stored in the vault container.
+//
+//nolint:tagliatelle
+type tokenPayload struct {
+	Key       []byte    `json:"key"`
+	Nonce     []byte    `json:"nonce"`
+	Scope     []string  `json:"scope"`
+	ReadOnly  bool      `json:"read_only"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	id string // id is the token id, set by [openToken].
+}
+
+// WithToken sets the access token used to unlock the vault.
+//
+// Access is limited to secrets with a label matching the token scope,
+// and vault-wide operations, such as the audit log, are rejected.
+// The scope is enforced by vlt: the token unwraps the vault key,
+// so it must be protected like the master password.
+func WithToken(token string) Option {
+	return func(c *config) {
+		c.token = token
+	}
+}
+
+// IssueToken creates a new access token scoped by the given options.
+//
+// The returned token string is the only copy of the token secret.
+func (vlt *Vault) IssueToken(ctx context.Context, opts TokenOptions) (string, vaultcontainer.Token, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return "", vaultcontainer.Token{}, errf("issue token: %w", err)
+	}
+
+	if err := vlt.checkWritable(ctx); err != nil {
+		return "", vaultcontainer.Token{}, errf("issue token: %w", err)
+	}
+
+	if len(opts.Scope) == 0 {
+		return "", vaultcontainer.Token{}, errf("issue token: empty scope")
+	}
+
+	if opts.TTL <= 0 {
+		return "", vaultcontainer.Token{}, errf("issue token: non-positive ttl")
+	}
+
+	id, err := vaultcrypto.RandBytes(tokenIDLen)
+	if err != nil {
+		return "", vaultcontainer.Token{}, errf("issue token: %w", err)
+	}
+
+	secret, err := vaultcrypto.RandBytes(tokenSecretLen)
+	if err != nil {
+		return "", vaultcontainer.Token{}, errf("issue token: %w", err)
+	}
+
+	t := vaultcontainer.Token{
+		ID:        hex.EncodeToString(id),
+		Scope:     opts.Scope,
+		ReadOnly:  opts.ReadOnly,
+		ExpiresAt: time.Now().UTC().Add(opts.TTL).Truncate(time.Second),
+	}
+
+	payload, err := json.Marshal(tokenPayload{
+		Key:       vlt.aesgcm.Key(),
+		Nonce:     vlt.nonce,
+		Scope:     t.Scope,
+		ReadOnly:  t.ReadOnly,
+		ExpiresAt: t.ExpiresAt,
+	})
+	if err != nil {
+		return "", vaultcontainer.Token{}, errf("issue token: %w", err)
+	}
+
+	aes, err := tokenAESGCM(t.ID, secret)
+	if err != nil {
+		return "", vaultcontainer.Token{}, errf("issue token: %w", err)
+	}
+
+	if t.Nonce, err = vaultcrypto.RandBytes(12); err != nil {
+		return "", vaultcontainer.Token{}, errf("issue token: %w", err)
+	}
+
+	if t.Sealed, err = aes.Seal(t.Nonce, payload); err != nil {
+		return "", vaultcontainer.Token{}, errf("issue token: %w", err)
+	}
+
+	if err := vlt.vaultContainerHandle.db.InsertToken(ctx, t); err != nil {
+		return "", vaultcontainer.Token{}, errf("issue token: %w", err)
+	}
+
+	token := tokenPrefix + t.ID + "_" + base64.RawURLEncoding.EncodeToString(secret)
+
+	return token, t, nil
+}
+
+// Tokens returns all issued access tokens, including expired ones.
+func (vlt *Vault) Tokens(ctx context.Context) ([]vaultcontainer.Token, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return nil, errf("tokens: %w", err)
+	}
+
+	return vlt.vaultContainerHandle.db.Tokens(ctx)
+}
+
+// RevokeTokens deletes the access tokens with the given ids,
+// returning the number of revoked tokens.
+func (vlt *Vault) RevokeTokens(ctx context.Context, ids ...string) (int64, error) {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return 0, errf("revoke tokens: %w", err)
+	}
+
+	if err := vlt.checkWritable(ctx); err != nil {
+		return 0, errf("revoke tokens: %w", err)
+	}
+
+	return vlt.vaultContainerHandle.db.DeleteTokens(ctx, ids)
+}
+
+// openToken unseals the payload of the given token string.
+func openToken(ctx context.Context, db *vaultcontainer.VaultContainer, token string, now time.Time) (*tokenPayload, error) {
+	id, secret, err := parseToken(tokenPrefix, token)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := db.Token(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, vaulterrors.ErrInvalidToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	aes, err := tokenAESGCM(id, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aes.Open(t.Nonce, t.Sealed)
+	if err != nil {
+		return nil, vaulterrors.ErrInvalidToken
+	}
+
+	var payload tokenPayload
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return nil, err
+	}
+
+	if !now.Before(payload.ExpiresAt) {
+		return nil, fmt.Errorf("%w: %s", vaulterrors.ErrTokenExpired, payload.ExpiresAt.Local().Format(time.DateTime))
+	}
+
+	if len(payload.Scope) == 0 {
+		return nil, vaulterrors.ErrInvalidToken
+	}
+
+	payload.id = id
+
+	return &payload, nil
+}
+
+// parseToken splits a token string with the given prefix into its id and secret.
+func parseToken(prefix string, token string) (id string, secret []byte, _ error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(token), prefix)
+	if !ok {
+		return "", nil, vaulterrors.ErrInvalidToken
+	}
+
+	id, encoded, ok := strings.Cut(rest, "_")
+	if !ok || len(id) != hex.EncodedLen(tokenIDLen) {
+		return "", nil, vaulterrors.ErrInvalidToken
+	}
+
+	secret, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(secret) != tokenSecretLen {
+		return "", nil, vaulterrors.ErrInvalidToken
+	}
+
+	return id, secret, nil
+}
+
+// tokenAESGCM returns the cipher sealing the payload of the token with the given id.
+func tokenAESGCM(id string, secret []byte) (*vaultcrypto.AESGCM, error) {
+	aes, err := vaultcrypto.NewAESGCM(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := aes.DeriveKey(tokenKeyInfo+":"+id, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	return vaultcrypto.NewAESGCM(key)
+}
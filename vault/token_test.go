@@ -0,0 +1,119 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_Token(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciID, err := v.InsertNewSecret(t.Context(), "deploy", "ci-secret", []string{"ci/deploy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	personalID, err := v.InsertNewSecret(t.Context(), "email", "personal-secret", []string{"personal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readOnly, _, err := v.IssueToken(t.Context(), TokenOptions{Scope: []string{"ci/*"}, ReadOnly: true, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readWrite, info, err := v.IssueToken(t.Context(), TokenOptions{Scope: []string{"ci/*"}, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err = Open(t.Context(), path, WithToken(readOnly))
+	if err != nil {
+		t.Fatalf("open with token: %v", err)
+	}
+
+	secrets, err := v.FilterSecrets(t.Context(), "*", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := secrets[ciID]; !ok || len(secrets) != 1 {
+		t.Errorf("filter secrets: got %v, want only secret %d", secrets, ciID)
+	}
+
+	if s, err := v.ShowSecret(t.Context(), ciID); err != nil || s != "ci-secret" {
+		t.Errorf("show scoped secret: got %q, %v", s, err)
+	}
+
+	if _, err := v.ShowSecret(t.Context(), personalID); !errors.Is(err, vaulterrors.ErrTokenScope) {
+		t.Errorf("show secret out of scope: got err %v, want %v", err, vaulterrors.ErrTokenScope)
+	}
+
+	if _, err := v.InsertNewSecret(t.Context(), "new", "secret", []string{"ci/new"}); !errors.Is(err, vaulterrors.ErrReadOnly) {
+		t.Errorf("insert with read-only token: got err %v, want %v", err, vaulterrors.ErrReadOnly)
+	}
+
+	if _, err := v.VerifyAuditLog(t.Context()); !errors.Is(err, vaulterrors.ErrTokenScope) {
+		t.Errorf("verify audit log: got err %v, want %v", err, vaulterrors.ErrTokenScope)
+	}
+
+	_ = v.Close(t.Context())
+
+	v, err = Open(t.Context(), path, WithToken(readWrite))
+	if err != nil {
+		t.Fatalf("open with token: %v", err)
+	}
+
+	if _, err := v.InsertNewSecret(t.Context(), "new", "secret", []string{"other"}); !errors.Is(err, vaulterrors.ErrTokenScope) {
+		t.Errorf("insert out of scope: got err %v, want %v", err, vaulterrors.ErrTokenScope)
+	}
+
+	if _, err := v.InsertNewSecret(t.Context(), "new", "secret", []string{"ci/new"}); err != nil {
+		t.Errorf("insert in scope: %v", err)
+	}
+
+	if _, err := v.DeleteSecretsByIDs(t.Context(), personalID); !errors.Is(err, vaulterrors.ErrTokenScope) {
+		t.Errorf("delete out of scope: got err %v, want %v", err, vaulterrors.ErrTokenScope)
+	}
+
+	if _, _, err := v.IssueToken(t.Context(), TokenOptions{Scope: []string{"*"}, TTL: time.Hour}); !errors.Is(err, vaulterrors.ErrTokenScope) {
+		t.Errorf("issue token with token: got err %v, want %v", err, vaulterrors.ErrTokenScope)
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := openToken(t.Context(), v.vaultContainerHandle.db, readWrite, time.Now().Add(2*time.Hour)); !errors.Is(err, vaulterrors.ErrTokenExpired) {
+		t.Errorf("open expired token: got err %v, want %v", err, vaulterrors.ErrTokenExpired)
+	}
+
+	v, err = Open(t.Context(), path, WithPassword("password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := v.RevokeTokens(t.Context(), info.ID); err != nil || n != 1 {
+		t.Fatalf("revoke token: got %d, %v", n, err)
+	}
+
+	_ = v.Close(t.Context())
+
+	if _, err := Open(t.Context(), path, WithToken(readWrite)); !errors.Is(err, vaulterrors.ErrInvalidToken) {
+		t.Errorf("open with revoked token: got err %v, want %v", err, vaulterrors.ErrInvalidToken)
+	}
+}
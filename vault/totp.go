@@ -0,0 +1,51 @@
+package vault
+
+import (
+	"context"
+
+	"github.com/ladzaretti/vlt-cli/totp"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// InsertTOTPSecret inserts a new secret holding the given TOTP seed,
+// see [totp.Parse]. The kind of meta is set to [totp.Kind].
+func (vlt *Vault) InsertTOTPSecret(ctx context.Context, name string, seed string, labels []string, meta vaultdb.SecretMeta) (int, error) {
+	if _, err := totp.Parse(seed); err != nil {
+		return 0, errf("insert totp secret: %w", err)
+	}
+
+	meta.Kind = totp.Kind
+
+	return vlt.InsertSecretWithMeta(ctx, name, seed, labels, meta)
+}
+
+// TOTPKey returns the key held by the TOTP secret identified by id.
+// Like [Vault.ShowSecret], the access is recorded.
+func (vlt *Vault) TOTPKey(ctx context.Context, id int) (totp.Key, error) {
+	metas, err := vlt.SecretMetas(ctx, id)
+	if err != nil {
+		return totp.Key{}, errf("totp key: %w", err)
+	}
+
+	meta, ok := metas[id]
+	if !ok {
+		return totp.Key{}, errf("totp key: secret %d: %w", id, vaulterrors.ErrSearchNoMatch)
+	}
+
+	if meta.Kind != totp.Kind {
+		return totp.Key{}, errf("totp key: secret %d: %w", id, vaulterrors.ErrNotTOTP)
+	}
+
+	seed, err := vlt.ShowSecret(ctx, id)
+	if err != nil {
+		return totp.Key{}, errf("totp key: %w", err)
+	}
+
+	key, err := totp.Parse(seed)
+	if err != nil {
+		return totp.Key{}, errf("totp key: secret %d: %w", id, err)
+	}
+
+	return key, nil
+}
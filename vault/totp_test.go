@@ -0,0 +1,110 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/totp"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+const testTOTPSeed = "JBSWY3DPEHPK3PXP"
+
+func TestVault_TOTPKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.InsertTOTPSecret(t.Context(), "bad", "not a seed!", nil, vaultdb.SecretMeta{}); !errors.Is(err, totp.ErrInvalidSeed) {
+		t.Errorf("InsertTOTPSecret() of an invalid seed: got err %v, want %v", err, totp.ErrInvalidSeed)
+	}
+
+	id, err := v.InsertTOTPSecret(t.Context(), "github", testTOTPSeed, nil, vaultdb.SecretMeta{Username: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := v.InsertNewSecret(t.Context(), "plain", testTOTPSeed, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the kind is covered by the integrity MAC.
+	v, err = Open(t.Context(), path, WithPassword("password"))
+	if err != nil {
+		t.Fatalf("reopen vault: %v", err)
+	}
+	t.Cleanup(func() { _ = v.Close(t.Context()) })
+
+	key, err := v.TOTPKey(t.Context(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := totp.Parse(testTOTPSeed)
+
+	now := time.Now()
+	if got := key.Code(now); got != want.Code(now) {
+		t.Errorf("TOTPKey() code: got %q, want %q", got, want.Code(now))
+	}
+
+	if _, err := v.TOTPKey(t.Context(), plain); !errors.Is(err, vaulterrors.ErrNotTOTP) {
+		t.Errorf("TOTPKey() of a plain secret: got err %v, want %v", err, vaulterrors.ErrNotTOTP)
+	}
+
+	if _, err := v.TOTPKey(t.Context(), plain+1); !errors.Is(err, vaulterrors.ErrSearchNoMatch) {
+		t.Errorf("TOTPKey() of a missing secret: got err %v, want %v", err, vaulterrors.ErrSearchNoMatch)
+	}
+
+	// the kind is kept by metadata updates of the other fields.
+	metas, err := v.SecretMetas(t.Context(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta := metas[id]
+	meta.Notes = "recovery codes in the safe"
+
+	if _, err := v.UpdateSecretMeta(t.Context(), id, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.TOTPKey(t.Context(), id); err != nil {
+		t.Errorf("TOTPKey() after a metadata update: %v", err)
+	}
+}
+
+func TestVault_Sync_TOTP(t *testing.T) {
+	a, b := syncedVaults(t)
+
+	if _, err := a.InsertTOTPSecret(t.Context(), "github", testTOTPSeed, nil, vaultdb.SecretMeta{}); err != nil {
+		t.Fatal(err)
+	}
+
+	exchange(t, a, b)
+
+	ids, err := b.FilterSecrets(t.Context(), "github", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ids) != 1 {
+		t.Fatalf("synced totp secrets: got %d, want 1", len(ids))
+	}
+
+	for id := range ids {
+		if _, err := b.TOTPKey(t.Context(), id); err != nil {
+			t.Errorf("TOTPKey() of a synced secret: %v", err)
+		}
+	}
+}
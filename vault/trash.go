@@ -0,0 +1,140 @@
+package vault
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// TrashedSecrets returns the secrets deleted using [Vault.DeleteSecretsByIDs]
+// and not purged yet, keyed by secret id.
+func (vlt *Vault) TrashedSecrets(ctx context.Context) (map[int]vaultdb.TrashedSecret, error) {
+	trashed, err := vlt.db.TrashedSecrets(ctx)
+	if err != nil {
+		return nil, errf("trashed secrets: %w", err)
+	}
+
+	return scoped(ctx, vlt, trashed)
+}
+
+// RestoreTrashedSecret moves the secret identified by id out of the trash.
+// Restores are audited and synced as inserts.
+func (vlt *Vault) RestoreTrashedSecret(ctx context.Context, id int) (retErr error) {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("restore secret: %w", err)
+	}
+
+	if err := vlt.checkScope(ctx, vlt.db, id); err != nil {
+		return errf("restore secret: %w", err)
+	}
+
+	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { //nolint:wsl
+		if retErr != nil {
+			if err := tx.Rollback(); err != nil {
+				retErr = errors.Join(retErr, errf("rollback: %w", err))
+			}
+		}
+	}()
+
+	restoreTx := vlt.db.WithTx(tx)
+
+	n, err := restoreTx.RestoreTrashedSecret(ctx, id)
+	if err != nil {
+		return errf("restore secret: %w", err)
+	}
+
+	if n == 0 {
+		return errf("restore secret: %d: %w", id, vaulterrors.ErrNotTrashed)
+	}
+
+	if err := vlt.record(ctx, restoreTx, oplogPut, id); err != nil {
+		return errf("restore secret: %w", err)
+	}
+
+	entry, err := vlt.audit(ctx, restoreTx, vaultdb.OpInsert, id)
+	if err != nil {
+		return errf("restore secret: audit: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errf("restore secret: tx commit: %w", err)
+	}
+
+	vlt.emit(entry)
+
+	return nil
+}
+
+// PurgeTrash permanently deletes the secrets moved to the trash at or before
+// olderThan, along with their labels and attachments, returning their number.
+//
+// Secret values, along with their previous versions, are overwritten with random
+// bytes before deletion, the states recorded for them in the sync oplog are
+// emptied, and the freed pages are vacuumed once the deletion is committed.
+func (vlt *Vault) PurgeTrash(ctx context.Context, olderThan time.Time) (_ int, retErr error) {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return 0, errf("purge trash: %w", err)
+	}
+
+	// the trash is purged as a whole, regardless of scopes.
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return 0, errf("purge trash: %w", err)
+	}
+
+	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { //nolint:wsl
+		if retErr != nil {
+			if err := tx.Rollback(); err != nil {
+				retErr = errors.Join(retErr, errf("rollback: %w", err))
+			}
+		}
+	}()
+
+	purgeTx := vlt.db.WithTx(tx)
+
+	ids, err := purgeTx.TrashedBefore(ctx, olderThan)
+	if err != nil {
+		return 0, errf("purge trash: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return 0, tx.Rollback()
+	}
+
+	if err := vlt.shredOplog(ctx, purgeTx, ids); err != nil {
+		return 0, errf("purge trash: %w", err)
+	}
+
+	if _, err := purgeTx.ShredSecretsByIDs(ctx, ids); err != nil {
+		return 0, errf("purge trash: shred: %w", err)
+	}
+
+	if _, err := purgeTx.DeleteSecretsByIDs(ctx, ids); err != nil {
+		return 0, errf("purge trash: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errf("purge trash: tx commit: %w", err)
+	}
+
+	if err := vlt.vaultContainerHandle.db.DeleteSecretsAttachments(ctx, ids); err != nil {
+		return len(ids), errf("purge trash: attachments: %w", err)
+	}
+
+	if err := vlt.vacuum(ctx); err != nil {
+		return len(ids), errf("purge trash: vacuum: %w", err)
+	}
+
+	return len(ids), nil
+}
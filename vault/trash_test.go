@@ -0,0 +1,237 @@
+package vault
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_Trash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := v.InsertNewSecret(t.Context(), "name", "value", []string{"l1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.DeleteSecretsByIDs(t.Context(), id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the trash is covered by the integrity MAC.
+	v, err = Open(t.Context(), path, WithPassword("password"))
+	if err != nil {
+		t.Fatalf("reopen vault: %v", err)
+	}
+	t.Cleanup(func() { _ = v.Close(t.Context()) })
+
+	if _, err := v.ShowSecret(t.Context(), id); err == nil {
+		t.Error("ShowSecret() of a trashed secret: want error")
+	}
+
+	trashed, err := v.TrashedSecrets(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := trashed[id]; len(trashed) != 1 || got.Name != "name" || len(got.Labels) != 1 || got.DeletedAt.IsZero() {
+		t.Fatalf("TrashedSecrets(): got %+v", trashed)
+	}
+
+	if err := v.RestoreTrashedSecret(t.Context(), id); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := v.ShowSecret(t.Context(), id); err != nil || got != "value" {
+		t.Errorf("ShowSecret() of a restored secret: got (%q, %v), want (%q, nil)", got, err, "value")
+	}
+
+	if err := v.RestoreTrashedSecret(t.Context(), id); !errors.Is(err, vaulterrors.ErrNotTrashed) {
+		t.Errorf("RestoreTrashedSecret() of a live secret: got err %v, want %v", err, vaulterrors.ErrNotTrashed)
+	}
+
+	if _, err := v.DeleteSecretsByIDs(t.Context(), id); err != nil {
+		t.Fatal(err)
+	}
+
+	// secrets trashed after olderThan are kept.
+	if n, err := v.PurgeTrash(t.Context(), time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Errorf("PurgeTrash() of an hour ago: got (%d, %v), want (0, nil)", n, err)
+	}
+
+	if n, err := v.PurgeTrash(t.Context(), time.Now().Add(time.Minute)); err != nil || n != 1 {
+		t.Errorf("PurgeTrash(): got (%d, %v), want (1, nil)", n, err)
+	}
+
+	if err := v.RestoreTrashedSecret(t.Context(), id); !errors.Is(err, vaulterrors.ErrNotTrashed) {
+		t.Errorf("RestoreTrashedSecret() of a purged secret: got err %v, want %v", err, vaulterrors.ErrNotTrashed)
+	}
+}
+
+func TestVault_Sync_Trash(t *testing.T) {
+	a, b := syncedVaults(t)
+
+	ids, err := a.FilterSecrets(t.Context(), "", "shared", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for id := range ids {
+		if _, err := a.DeleteSecretsByIDs(t.Context(), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exchange(t, a, b)
+
+	if got := secretsByName(t, b); len(got) != 0 {
+		t.Fatalf("secrets after synced delete: got %v, want none", got)
+	}
+
+	trashed, err := b.TrashedSecrets(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(trashed) != 1 {
+		t.Fatalf("trashed secrets after synced delete: got %d, want 1", len(trashed))
+	}
+
+	for id := range trashed {
+		if err := b.RestoreTrashedSecret(t.Context(), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exchange(t, b, a)
+
+	if got := secretsByName(t, a); got["shared"] != "v1" {
+		t.Errorf("secrets after synced restore: got %v, want shared=v1", got)
+	}
+}
+
+func TestVault_PurgeTrash_Shred(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := v.InsertNewSecret(t.Context(), "name", "v1", nil)
+	if err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	if _, err := v.InitSync(t.Context(), false); err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	if _, err := v.UpdateSecret(t.Context(), id, "v2"); err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	_, version, err := v.db.SecretAtVersion(t.Context(), id, 1)
+	if err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	uid, err := v.db.SecretUID(t.Context(), id)
+	if err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	if _, err := v.DeleteSecretsByIDs(t.Context(), id); err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	before, err := v.db.OplogEntriesBySecret(t.Context(), uid)
+	if err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	if n, err := v.PurgeTrash(t.Context(), time.Now().Add(time.Minute)); err != nil || n != 1 {
+		_ = v.Close(t.Context())
+		t.Fatalf("PurgeTrash(): got (%d, %v), want (1, nil)", n, err)
+	}
+
+	after, err := v.db.OplogEntriesBySecret(t.Context(), uid)
+	if err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	state, err := v.db.SyncState(t.Context())
+	if err != nil {
+		_ = v.Close(t.Context())
+		t.Fatal(err)
+	}
+
+	// entries are kept for synced devices, with their states emptied.
+	if len(after) != len(before) {
+		t.Errorf("oplog entries after purge: got %d, want %d", len(after), len(before))
+	}
+
+	for _, e := range after {
+		p, err := openOplogPayload(state.Key, e)
+		if err != nil {
+			t.Fatalf("open oplog entry %d: %v", e.Seq, err)
+		}
+
+		if p.Name != "" || p.Value != "" {
+			t.Errorf("oplog entry %d: got %+v, want an empty state", e.Seq, p)
+		}
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	var sealed []byte
+	if err := db.QueryRowContext(t.Context(), "SELECT vault_encrypted FROM vault_container").Scan(&sealed); err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := v.aesgcm.Open(v.nonce, sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(plaintext, version) {
+		t.Error("previous version of the purged secret found in the vault")
+	}
+
+	for _, e := range before {
+		if e.Operation == oplogPut && bytes.Contains(plaintext, e.Payload) {
+			t.Errorf("oplog entry %d of the purged secret found in the vault", e.Seq)
+		}
+	}
+}
@@ -2,15 +2,21 @@ package vault
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/subtle"
 	"database/sql"
 	"embed"
 	"errors"
 	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"time"
 
 	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultcontainer"
 	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
 	"github.com/ladzaretti/vlt-cli/vaultcrypto"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
 
 	"github.com/ladzaretti/migrate"
 
@@ -38,6 +44,10 @@ var (
 	}
 )
 
+// containerBusyTimeoutMS is how long vault container queries wait for the
+// database to be unlocked by other connections, in milliseconds.
+const containerBusyTimeoutMS = 5000
+
 type cleanupFunc func() error
 
 // Vault manages access to two related databases:
@@ -57,6 +67,24 @@ type Vault struct {
 	buf                  []byte                // buf holds the backing in-memory SQLite database. retained to prevent GC while the DB is active, released in [Vault.Close].
 	vaultContainerHandle *vaultContainerHandle // vaultContainerHandle connects to the vault container database.
 	cleanupFuncs         []cleanupFunc         // cleanupFuncs contains deferred cleanup functions.
+	auditSinks           []AuditSink           // auditSinks optionally mirror committed audit log entries.
+	auditSinkErrs        []error               // auditSinkErrs collects mirroring errors, reported by [Vault.Close].
+	resolver             Resolver              // resolver optionally resolves linked secrets.
+	readOnly             bool                  // readOnly rejects mutations and skips persisting the vault on [Vault.Close].
+	acceptChanges        bool                  // acceptChanges allows opening a vault that fails the integrity check.
+	changesAccepted      bool                  // changesAccepted is set if an integrity mismatch was accepted on open.
+	scope                []string              // scope are the label globs of the token the vault was opened with, nil otherwise.
+	actor                string                // actor identifies the token the vault was opened with in the audit log, empty otherwise.
+	shredHistory         bool                  // shredHistory is set once secrets are purged, shredding the container history on the next seal.
+	written              bool                  // written is set once a mutation is allowed by [Vault.checkWritable].
+	sealed               [32]byte              // sealed is the digest of the encrypted vault last loaded or stored, see [Vault.merge].
+	journal              []readRecord          // journal holds the records of read operations since the vault was last loaded or stored.
+}
+
+// AuditSink receives audit log entries once the
+// operations they record are committed.
+type AuditSink interface {
+	Write(vaultPath string, entry vaultdb.AuditEntry) error
 }
 
 type session struct {
@@ -65,8 +93,13 @@ type session struct {
 
 // config options for creating a [Vault] instance.
 type config struct {
-	snapshot []byte // snapshot is the serialized vault container database to restore from, if set.
-	password string
+	snapshot      []byte // snapshot is the serialized vault container database to restore from, if set.
+	password      string
+	auditSinks    []AuditSink
+	resolver      Resolver
+	readOnly      bool
+	acceptChanges bool
+	token         string
 	session
 }
 
@@ -99,12 +132,45 @@ func WithSessionKey(key, nonce []byte) Option {
 	}
 }
 
-func newVault(path string, nonce []byte, aesgcm *vaultcrypto.AESGCM, vch *vaultContainerHandle) *Vault {
+// WithAuditSink adds a sink that mirrors audit log entries.
+// Entries are mirrored to the sinks in the order they were added.
+func WithAuditSink(s AuditSink) Option {
+	return func(c *config) {
+		c.auditSinks = append(c.auditSinks, s)
+	}
+}
+
+// WithReadOnly opens the vault in read-only mode.
+//
+// Mutating methods fail with [vaulterrors.ErrReadOnly], and the vault
+// container is never written to, including on [Vault.Close].
+// Audit entries of read operations are still mirrored to the [AuditSink]s,
+// but are not persisted.
+func WithReadOnly() Option {
+	return func(c *config) {
+		c.readOnly = true
+	}
+}
+
+// WithAcceptChanges accepts vault contents that fail the integrity check
+// on open, e.g., after the vault was modified outside of vlt.
+// The integrity MAC is refreshed the next time the vault is sealed.
+func WithAcceptChanges() Option {
+	return func(c *config) {
+		c.acceptChanges = true
+	}
+}
+
+func newVault(path string, nonce []byte, aesgcm *vaultcrypto.AESGCM, vch *vaultContainerHandle, config *config) *Vault {
 	return &Vault{
 		Path:                 path,
 		nonce:                nonce,
 		aesgcm:               aesgcm,
 		vaultContainerHandle: vch,
+		auditSinks:           config.auditSinks,
+		resolver:             config.resolver,
+		readOnly:             config.readOnly,
+		acceptChanges:        config.acceptChanges,
 	}
 }
 
@@ -123,6 +189,10 @@ func New(ctx context.Context, path string, password string, opts ...Option) (vlt
 		opt(config)
 	}
 
+	if config.readOnly {
+		return nil, errf("new: %w", vaulterrors.ErrReadOnly)
+	}
+
 	vaultContainerHandle, err := newVaultContainerHandle(ctx, path, config.snapshot)
 	if err != nil {
 		return nil, errf("new: %w", err)
@@ -151,7 +221,7 @@ func New(ctx context.Context, path string, password string, opts ...Option) (vlt
 		return nil, errf("new: %w", err)
 	}
 
-	vlt = newVault(path, cipherdata.Nonce, aes, vaultContainerHandle)
+	vlt = newVault(path, cipherdata.Nonce, aes, vaultContainerHandle, config)
 
 	if err := vlt.open(ctx, nil); err != nil {
 		return vlt, errf("new: %w", err)
@@ -171,9 +241,66 @@ func New(ctx context.Context, path string, password string, opts ...Option) (vlt
 		return vlt, errf("new: %w", err)
 	}
 
+	vlt.sealed = sha256.Sum256(ciphervault)
+
+	// attachments of an overwritten vault are encrypted using its key.
+	if err := vaultContainerHandle.db.DeleteAllAttachments(ctx); err != nil {
+		return vlt, errf("new: %w", err)
+	}
+
 	return vlt, nil
 }
 
+// KDFParams returns the parameters the key of the vault at the given path is
+// derived with. The vault is not unlocked.
+func KDFParams(ctx context.Context, path string) (vaultcrypto.Argon2Params, error) {
+	vaultContainerHandle, err := newVaultContainerHandle(ctx, path, nil)
+	if err != nil {
+		return vaultcrypto.Argon2Params{}, errf("kdf params: %w", err)
+	}
+	defer func() { //nolint:wsl
+		_ = vaultContainerHandle.cleanup()
+	}()
+
+	cipherdata, err := vaultContainerHandle.db.SelectVault(ctx)
+	if err != nil {
+		return vaultcrypto.Argon2Params{}, errf("kdf params: %w", err)
+	}
+
+	phc, err := vaultcrypto.DecodeAragon2idPHC(cipherdata.KDFPHC)
+	if err != nil {
+		return vaultcrypto.Argon2Params{}, errf("kdf params: %w", err)
+	}
+
+	return phc.Argon2Params, nil
+}
+
+// ContainerSchemaVersion returns the schema version of the container of the
+// vault at the given path, along with the latest version supported by this
+// build. The vault is neither unlocked nor migrated.
+//
+// A version newer than the supported one means the vault was written by
+// a newer vlt version, which this build cannot open.
+func ContainerSchemaVersion(ctx context.Context, path string) (version int, supported int, _ error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, 0, errf("container schema version: %w", err)
+	}
+	defer func() { _ = db.Close() }() //nolint:wsl
+
+	schema, err := migrate.New(db, migrate.SQLiteDialect{}).CurrentSchemaVersion(ctx)
+	if err != nil {
+		return 0, 0, errf("container schema version: %w", err)
+	}
+
+	migrations, err := vaultContainerMigrations.List()
+	if err != nil {
+		return 0, 0, errf("container schema version: %w", err)
+	}
+
+	return schema.Version, len(migrations), nil
+}
+
 // Login verifies the password and derives the AES-GCM key
 // for the vault at the given path.
 func Login(ctx context.Context, path string, password string, opts ...Option) (key []byte, nonce []byte, _ error) {
@@ -240,10 +367,23 @@ func Open(ctx context.Context, path string, opts ...Option) (vlt *Vault, retErr
 	var (
 		aes   *vaultcrypto.AESGCM
 		nonce []byte
+		token *tokenPayload
 	)
 
-	// choose key derivation method: password-based or session-based
+	// choose key derivation method: token-based, password-based or session-based
 	switch {
+	case len(config.token) > 0:
+		p, err := openToken(ctx, vaultContainerHandle.db, config.token, time.Now())
+		if err != nil {
+			return nil, errf("open: %w", err)
+		}
+
+		a, err := vaultcrypto.NewAESGCM(p.Key)
+		if err != nil {
+			return nil, errf("open: %w", err)
+		}
+
+		aes, nonce, token = a, p.Nonce, p
 	case len(config.password) > 0:
 		a, err := deriveAESFromPassword(cipherdata, config.password)
 		if err != nil {
@@ -262,7 +402,7 @@ func Open(ctx context.Context, path string, opts ...Option) (vlt *Vault, retErr
 		return nil, errf("open: no password or session key provided")
 	}
 
-	vlt = newVault(path, nonce, aes, vaultContainerHandle)
+	vlt = newVault(path, nonce, aes, vaultContainerHandle, config)
 	defer func() { //nolint:wsl
 		if retErr != nil {
 			_ = vlt.cleanup()
@@ -270,6 +410,12 @@ func Open(ctx context.Context, path string, opts ...Option) (vlt *Vault, retErr
 		}
 	}()
 
+	if token != nil {
+		vlt.scope = token.Scope
+		vlt.actor = "token:" + token.id
+		vlt.readOnly = vlt.readOnly || token.ReadOnly
+	}
+
 	if err := vlt.open(ctx, cipherdata.Vault); err != nil {
 		return vlt, errf("open: %w", err)
 	}
@@ -297,22 +443,71 @@ func deriveAESFromPassword(cipherdata *vaultcontainer.CipherData, password strin
 
 // Close serializes the in-memory SQLite database, encrypts it, and stores the
 // resulting ciphertext in the vault container database.
+// In read-only mode, the vault container is left untouched.
 //
 // After calling Close, the in-memory database buffer [Vault.buf] is eligible for gc
 // and should not be used again unless reinitialized.
+//
+// Errors encountered while mirroring audit entries to the [AuditSink]s
+// are reported here, as the operations they record have already been committed.
 func (vlt *Vault) Close(ctx context.Context) error {
-	if err := vlt.seal(ctx); err != nil {
-		return err
+	if !vlt.readOnly {
+		if err := vlt.seal(ctx); err != nil {
+			return err
+		}
 	}
 
 	vlt.buf = nil // release backing buffer to allow garbage collection.
 
-	return vlt.cleanup()
+	if err := vlt.cleanup(); err != nil {
+		return err
+	}
+
+	if len(vlt.auditSinkErrs) > 0 {
+		return errf("audit sink: %w", errors.Join(vlt.auditSinkErrs...))
+	}
+
+	return nil
+}
+
+// Sync persists the current vault state to the vault container,
+// for long-lived vaults that are closed only on shutdown.
+// In read-only mode, it does nothing.
+func (vlt *Vault) Sync(ctx context.Context) error {
+	if vlt.readOnly {
+		return nil
+	}
+
+	return vlt.seal(ctx)
+}
+
+// emit mirrors the given committed audit entries to the audit sinks, if set.
+// Zero entries, of operations on secrets that do not exist, are skipped.
+func (vlt *Vault) emit(entries ...vaultdb.AuditEntry) {
+	for _, sink := range vlt.auditSinks {
+		for _, e := range entries {
+			if e.ID == 0 {
+				continue // nothing was audited.
+			}
+
+			if err := sink.Write(vlt.Path, e); err != nil {
+				vlt.auditSinkErrs = append(vlt.auditSinkErrs, err)
+			}
+		}
+	}
 }
 
 // seal serializes the in-memory SQLite database, encrypts it, and stores the
 // resulting ciphertext using the vault container.
 func (vlt *Vault) seal(ctx context.Context) error {
+	if err := vlt.merge(ctx); err != nil {
+		return errf("vault seal: %w", err)
+	}
+
+	if err := vlt.updateIntegrity(ctx); err != nil {
+		return errf("vault seal: integrity: %w", err)
+	}
+
 	serialized, err := Serialize(vlt.conn)
 	if err != nil {
 		return errf("vault seal: %w", err)
@@ -327,6 +522,17 @@ func (vlt *Vault) seal(ctx context.Context) error {
 		return errf("vault seal: %w", err)
 	}
 
+	vlt.sealed, vlt.journal = sha256.Sum256(ciphervault), nil
+
+	// the previous versions of the vault still hold the purged secrets.
+	if vlt.shredHistory {
+		if err := vlt.vaultContainerHandle.db.ShredVaultHistory(ctx); err != nil {
+			return errf("vault seal: shred history: %w", err)
+		}
+
+		vlt.shredHistory = false
+	}
+
 	return nil
 }
 
@@ -438,7 +644,9 @@ func newVaultContainerHandle(ctx context.Context, path string, snapshot []byte)
 		return nil
 	})
 
-	db, err := sql.Open("sqlite", path)
+	// wait out writes of processes not holding the vault [Lock], e.g., backups.
+	// secure_delete zeroes out deleted content, e.g., pruned vault history, instead of leaving it in free pages.
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout("+strconv.Itoa(containerBusyTimeoutMS)+")&_pragma=secure_delete(1)")
 	if err != nil {
 		return nil, errf("open vault container: %w", err)
 	}
@@ -514,6 +722,16 @@ func (vlt *Vault) open(ctx context.Context, ciphervault []byte) (retErr error) {
 		}
 	}()
 
+	if err := vlt.connect(ctx); err != nil {
+		return err
+	}
+
+	return vlt.load(ctx, ciphervault)
+}
+
+// connect opens a new in-memory database as the vault database.
+// Connections are closed by [Vault.cleanup].
+func (vlt *Vault) connect(ctx context.Context) error {
 	var (
 		db   *sql.DB
 		conn *sql.Conn
@@ -547,32 +765,99 @@ func (vlt *Vault) open(ctx context.Context, ciphervault []byte) (retErr error) {
 		return err
 	}
 
+	vlt.conn = conn
+	vlt.db = vaultdb.New(conn)
+
+	return nil
+}
+
+// load decrypts and deserializes the encrypted vault into the vault database
+// and migrates it. A nil ciphervault leaves the database empty, for new vaults.
+//
+// The database must be empty, a vault is loaded at most once per connection.
+func (vlt *Vault) load(ctx context.Context, ciphervault []byte) error {
 	if ciphervault != nil {
 		decrypted, err := vlt.aesgcm.Open(vlt.nonce, ciphervault)
 		if err != nil {
 			return err
 		}
 
+		if err := Deserialize(vlt.conn, decrypted); err != nil {
+			return err
+		}
+
 		vlt.buf = decrypted
 
-		if err := Deserialize(conn, vlt.buf); err != nil {
-			return err
+		// verified prior to migrating, as the MAC covers the schema version.
+		ok, err := vlt.verifyIntegrity(ctx)
+		if err != nil {
+			return fmt.Errorf("integrity: %w", err)
+		}
+
+		if !ok && !vlt.acceptChanges {
+			return vaulterrors.ErrVaultModified
 		}
+
+		vlt.changesAccepted = !ok
+		vlt.sealed = sha256.Sum256(ciphervault)
 	}
 
-	m := migrate.New(conn, migrate.SQLiteDialect{})
+	// zero out deleted content instead of leaving it in free pages,
+	// set once deserialized, as deserializing resets it.
+	if _, err := vlt.conn.ExecContext(ctx, "PRAGMA secure_delete = ON"); err != nil {
+		return err
+	}
 
-	_, err = m.Apply(vaultMigrations)
-	if err != nil {
+	m := migrate.New(vlt.conn, migrate.SQLiteDialect{})
+
+	if _, err := m.Apply(vaultMigrations); err != nil {
 		return err
 	}
 
-	vlt.conn = conn
-	vlt.db = vaultdb.New(conn)
+	return enableIncrementalVacuum(ctx, vlt.conn)
+}
+
+// enableIncrementalVacuum switches the vault database to incremental
+// auto-vacuum mode, so free pages can be reclaimed by [Vault.vacuum].
+//
+// Vaults created before incremental auto-vacuum was introduced are
+// converted using a one-time full VACUUM.
+func enableIncrementalVacuum(ctx context.Context, conn *sql.Conn) error {
+	const incremental = 2
+
+	var mode int
+	if err := conn.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&mode); err != nil {
+		return fmt.Errorf("auto vacuum: %w", err)
+	}
+
+	if mode == incremental {
+		return nil
+	}
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA auto_vacuum = INCREMENTAL"); err != nil {
+		return fmt.Errorf("auto vacuum: %w", err)
+	}
+
+	if _, err := conn.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("auto vacuum: %w", err)
+	}
 
 	return nil
 }
 
+// vacuum releases the free pages of the vault database,
+// truncating them from the serialized vault.
+//
+// As it follows purging secrets, the previous versions of the vault kept
+// in the vault container are shredded on the next seal, see [Vault.seal].
+func (vlt *Vault) vacuum(ctx context.Context) error {
+	vlt.shredHistory = true
+
+	_, err := vlt.conn.ExecContext(ctx, "PRAGMA incremental_vacuum")
+
+	return err
+}
+
 // deriveAESGCM derives an AES-GCM cipher using the given PHC and password.
 // The [vaultcrypto.Argon2idPHC] provides the key derivation parameters,
 // and the password is used to derive the encryption key.
@@ -597,7 +882,21 @@ func errf(format string, a ...any) error {
 // into the vault using a transaction.
 //
 // Returns the ID of the inserted secret or an error if the operation fails.
-func (vlt *Vault) InsertNewSecret(ctx context.Context, name string, secret string, labels []string) (id int, retErr error) {
+func (vlt *Vault) InsertNewSecret(ctx context.Context, name string, secret string, labels []string) (int, error) {
+	return vlt.InsertSecretWithMeta(ctx, name, secret, labels, vaultdb.SecretMeta{})
+}
+
+// InsertSecretWithMeta is like [Vault.InsertNewSecret], also setting the url,
+// username, notes and kind of the secret. The timestamps of meta are ignored.
+func (vlt *Vault) InsertSecretWithMeta(ctx context.Context, name string, secret string, labels []string, meta vaultdb.SecretMeta) (id int, retErr error) {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return 0, errf("insert new secret: %w", err)
+	}
+
+	if err := checkSecretSize(secret); err != nil {
+		return 0, errf("insert new secret: %w", err)
+	}
+
 	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return 0, err
@@ -605,7 +904,7 @@ func (vlt *Vault) InsertNewSecret(ctx context.Context, name string, secret strin
 
 	storeTx := vlt.db.WithTx(tx)
 
-	nonce, err := vaultcrypto.RandBytes(12)
+	nonce, ciphertext, chunks, err := vlt.sealValue(secret)
 	if err != nil {
 		if err2 := tx.Rollback(); err2 != nil {
 			return 0, errf("insert new secret: rollback: %w", errors.Join(err2, err))
@@ -614,7 +913,7 @@ func (vlt *Vault) InsertNewSecret(ctx context.Context, name string, secret strin
 		return 0, errf("insert new secret: %w", err)
 	}
 
-	ciphertext, err := vlt.aesgcm.Seal(nonce, []byte(secret))
+	secretID, err := storeTx.InsertSecretWithMeta(ctx, name, meta, nonce, ciphertext)
 	if err != nil {
 		if err2 := tx.Rollback(); err2 != nil {
 			return 0, errf("insert new secret: rollback: %w", errors.Join(err2, err))
@@ -623,8 +922,7 @@ func (vlt *Vault) InsertNewSecret(ctx context.Context, name string, secret strin
 		return 0, errf("insert new secret: %w", err)
 	}
 
-	secretID, err := storeTx.InsertNewSecret(ctx, name, nonce, ciphertext)
-	if err != nil {
+	if err := storeTx.ReplaceSecretChunks(ctx, secretID, chunks); err != nil {
 		if err2 := tx.Rollback(); err2 != nil {
 			return 0, errf("insert new secret: rollback: %w", errors.Join(err2, err))
 		}
@@ -642,15 +940,50 @@ func (vlt *Vault) InsertNewSecret(ctx context.Context, name string, secret strin
 		}
 	}
 
+	if err := vlt.checkScope(ctx, storeTx, secretID); err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return 0, errf("insert new secret: rollback: %w", errors.Join(err2, err))
+		}
+
+		return 0, errf("insert new secret: %w", err)
+	}
+
+	if err := vlt.record(ctx, storeTx, oplogPut, secretID); err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return 0, errf("insert new secret: rollback: %w", errors.Join(err2, err))
+		}
+
+		return 0, errf("insert new secret: %w", err)
+	}
+
+	entry, err := vlt.audit(ctx, storeTx, vaultdb.OpInsert, secretID)
+	if err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return 0, errf("insert new secret: audit: rollback: %w", errors.Join(err2, err))
+		}
+
+		return 0, errf("insert new secret: audit: %w", err)
+	}
+
 	if err := tx.Commit(); err != nil {
 		return 0, errf("insert new secret: tx commit: %w", err)
 	}
 
+	vlt.emit(entry)
+
 	return secretID, nil
 }
 
 // UpdateSecretMetadata updates the metadata of the secret identified by id.
 func (vlt *Vault) UpdateSecretMetadata(ctx context.Context, id int, newName string, removeLabels []string, addLabels []string) error {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return errf("update secret: %w", err)
+	}
+
+	if err := vlt.checkScope(ctx, vlt.db, id); err != nil {
+		return errf("update secret: %w", err)
+	}
+
 	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return err
@@ -689,26 +1022,120 @@ func (vlt *Vault) UpdateSecretMetadata(ctx context.Context, id int, newName stri
 		}
 	}
 
+	if err := vlt.checkScope(ctx, updateTx, id); err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return errf("update secret: rollback: %w", errors.Join(err2, err))
+		}
+
+		return errf("update secret: %w", err)
+	}
+
+	if err := vlt.record(ctx, updateTx, oplogPut, id); err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return errf("update secret: rollback: %w", errors.Join(err2, err))
+		}
+
+		return errf("update secret: %w", err)
+	}
+
+	entry, err := vlt.audit(ctx, updateTx, vaultdb.OpUpdateMetadata, id)
+	if err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return errf("update secret: audit: rollback: %w", errors.Join(err2, err))
+		}
+
+		return errf("update secret: audit: %w", err)
+	}
+
 	if err := tx.Commit(); err != nil {
 		return errf("update secret: tx commit: %w", err)
 	}
 
+	vlt.emit(entry)
+
 	return nil
 }
 
-// UpdateSecret updates the secret value of the secret identified by id.
+// UpdateSecret updates the secret value of the secret identified by id,
+// keeping the value replaced as a version, see [Vault.SecretHistory].
 func (vlt *Vault) UpdateSecret(ctx context.Context, id int, secret string) (int64, error) {
-	nonce, err := vaultcrypto.RandBytes(12)
+	if err := vlt.checkWritable(ctx); err != nil {
+		return 0, errf("update secret: %w", err)
+	}
+
+	if err := vlt.checkScope(ctx, vlt.db, id); err != nil {
+		return 0, errf("update secret: %w", err)
+	}
+
+	switch _, err := vlt.db.Link(ctx, id); {
+	case err == nil:
+		return 0, errf("update secret: %w", vaulterrors.ErrLinkedSecret)
+	case !errors.Is(err, sql.ErrNoRows):
+		return 0, errf("update secret: %w", err)
+	}
+
+	if err := checkSecretSize(secret); err != nil {
+		return 0, errf("update secret: %w", err)
+	}
+
+	nonce, ciphertext, chunks, err := vlt.sealValue(secret)
 	if err != nil {
 		return 0, errf("update secret: %w", err)
 	}
 
-	ciphertext, err := vlt.aesgcm.Seal(nonce, []byte(secret))
+	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return 0, errf("update secret: %w", err)
 	}
 
-	return vlt.db.UpdateSecret(ctx, id, nonce, ciphertext)
+	updateTx := vlt.db.WithTx(tx)
+
+	n, entry, err := vlt.updateSecret(ctx, updateTx, id, secret, nonce, ciphertext, chunks)
+	if err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return 0, errf("update secret: rollback: %w", errors.Join(err2, err))
+		}
+
+		return 0, errf("update secret: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errf("update secret: tx commit: %w", err)
+	}
+
+	vlt.emit(entry)
+
+	return n, nil
+}
+
+// updateSecret updates the secret value using store, keeping the replaced
+// value as a version, and records the update in the oplog and the audit log.
+func (vlt *Vault) updateSecret(ctx context.Context, store *vaultdb.VaultDB, id int, secret string, nonce, ciphertext []byte, chunks []vaultdb.SecretChunk) (int64, vaultdb.AuditEntry, error) {
+	if err := vlt.keepVersion(ctx, store, id, secret); err != nil {
+		return 0, vaultdb.AuditEntry{}, fmt.Errorf("version: %w", err)
+	}
+
+	n, err := store.UpdateSecret(ctx, id, nonce, ciphertext)
+	if err != nil {
+		return 0, vaultdb.AuditEntry{}, err
+	}
+
+	if n > 0 {
+		if err := store.ReplaceSecretChunks(ctx, id, chunks); err != nil {
+			return 0, vaultdb.AuditEntry{}, err
+		}
+
+		if err := vlt.record(ctx, store, oplogPut, id); err != nil {
+			return 0, vaultdb.AuditEntry{}, err
+		}
+	}
+
+	entry, err := vlt.audit(ctx, store, vaultdb.OpUpdate, id)
+	if err != nil {
+		return 0, vaultdb.AuditEntry{}, fmt.Errorf("audit: %w", err)
+	}
+
+	return n, entry, nil
 }
 
 // ExportSecrets exports all secret-related data stored in the database.
@@ -718,29 +1145,119 @@ func (vlt *Vault) ExportSecrets(ctx context.Context) (map[int]vaultdb.SecretWith
 		return nil, err
 	}
 
+	encryptedSecrets, err = scoped(ctx, vlt, encryptedSecrets)
+	if err != nil {
+		return nil, err
+	}
+
+	chunked, err := vlt.db.ChunkedSecretIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	for id, s := range encryptedSecrets {
 		decrypted, err := vlt.aesgcm.Open(s.Nonce, s.Ciphertext)
 		if err != nil {
 			return nil, err
 		}
 
+		if chunked[id] {
+			if decrypted, err = vlt.openValue(ctx, vlt.db, id); err != nil {
+				return nil, err
+			}
+		}
+
 		s.Value = string(decrypted)
 
 		encryptedSecrets[id] = s
 	}
 
+	entry, err := vlt.auditRead(ctx, vaultdb.OpExport, 0, false)
+	if err != nil {
+		return nil, errf("export secrets: audit: %w", err)
+	}
+
+	vlt.emit(entry)
+
 	return encryptedSecrets, nil
 }
 
+// ExportedSecret is a decrypted secret yielded by [Vault.StreamSecrets].
+type ExportedSecret struct {
+	ID     int
+	Name   string
+	Value  string
+	Labels []string // Labels are sorted by name.
+}
+
+// StreamSecrets is like [Vault.ExportSecrets], but returns an iterator over
+// the secrets ordered by id, decrypting one secret at a time, so memory stays
+// flat regardless of the vault size.
+//
+// The export is audited once all secrets were yielded.
+func (vlt *Vault) StreamSecrets(ctx context.Context) iter.Seq2[ExportedSecret, error] {
+	return func(yield func(ExportedSecret, error) bool) {
+		allowed, err := vlt.allowed(ctx, vlt.db)
+		if err != nil {
+			yield(ExportedSecret{}, errf("stream secrets: %w", err))
+			return
+		}
+
+		chunked, err := vlt.db.ChunkedSecretIDs(ctx)
+		if err != nil {
+			yield(ExportedSecret{}, errf("stream secrets: %w", err))
+			return
+		}
+
+		for row, err := range vlt.db.IntegrityRows(ctx) {
+			if err != nil {
+				yield(ExportedSecret{}, errf("stream secrets: %w", err))
+				return
+			}
+
+			if _, ok := allowed[row.ID]; allowed != nil && !ok {
+				continue
+			}
+
+			decrypted, err := vlt.aesgcm.Open(row.Nonce, row.Ciphertext)
+			if err == nil && chunked[row.ID] {
+				decrypted, err = vlt.openValue(ctx, vlt.db, row.ID)
+			}
+
+			if err != nil {
+				yield(ExportedSecret{}, errf("stream secrets: secret %d: %w", row.ID, err))
+				return
+			}
+
+			s := ExportedSecret{
+				ID:     row.ID,
+				Name:   row.Name,
+				Value:  string(decrypted),
+				Labels: row.Labels,
+			}
+
+			if !yield(s, nil) {
+				return
+			}
+		}
+
+		entry, err := vlt.auditRead(ctx, vaultdb.OpExport, 0, false)
+		if err != nil {
+			yield(ExportedSecret{}, errf("stream secrets: audit: %w", err))
+			return
+		}
+
+		vlt.emit(entry)
+	}
+}
+
 // FilterSecrets returns secrets that match the given filters.
 func (vlt *Vault) FilterSecrets(ctx context.Context, wildcard string, name string, labels []string) (map[int]vaultdb.SecretWithLabels, error) {
-	filters := vaultdb.Filters{
+	return vlt.FilterSecretsBy(ctx, vaultdb.Filters{
 		Wildcard: wildcard,
 		Name:     name,
 		Labels:   labels,
-	}
-
-	return vlt.db.FilterSecrets(ctx, filters)
+	})
 }
 
 // SecretsByIDs returns a map of secrets that match any of the provided IDs,
@@ -748,25 +1265,275 @@ func (vlt *Vault) FilterSecrets(ctx context.Context, wildcard string, name strin
 //
 // If the IDs slice is empty, the function returns [vaultdb.ErrNoIDsProvided].
 func (vlt *Vault) SecretsByIDs(ctx context.Context, ids ...int) (map[int]vaultdb.SecretWithLabels, error) {
-	return vlt.db.SecretsByIDs(ctx, ids)
+	secrets, err := vlt.db.SecretsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return scoped(ctx, vlt, secrets)
 }
 
 // ShowSecret returns the decrypted ciphertext associated with the given secret ID.
+// The access is recorded, ranking the secret by [Vault.Frecency].
+//
+// Linked secrets are resolved first if their cached value expired.
 func (vlt *Vault) ShowSecret(ctx context.Context, id int) (string, error) {
-	nonce, ciphertext, err := vlt.db.ShowSecret(ctx, id)
-	if err != nil {
+	if err := vlt.checkScope(ctx, vlt.db, id); err != nil {
 		return "", errf("secret: %w", err)
 	}
 
-	secret, err := vlt.aesgcm.Open(nonce, ciphertext)
+	if err := vlt.refreshLink(ctx, id); err != nil {
+		return "", errf("secret: resolve link: %w", err)
+	}
+
+	secret, err := vlt.openValue(ctx, vlt.db, id)
 	if err != nil {
 		return "", errf("secret: %w", err)
 	}
 
+	entry, err := vlt.auditRead(ctx, vaultdb.OpShow, id, true)
+	if err != nil {
+		return "", errf("secret: audit: %w", err)
+	}
+
+	vlt.emit(entry)
+
 	return string(secret), nil
 }
 
-// DeleteSecretsByIDs deletes secrets by their IDs, along with their labels.
+// WriteSecret writes the decrypted value of the given secret ID to w,
+// one chunk at a time, returning the number of bytes written.
+// Unlike [Vault.ShowSecret], the value is never held as a whole.
+//
+// The access is recorded once the value is written in full.
+func (vlt *Vault) WriteSecret(ctx context.Context, id int, w io.Writer) (int64, error) {
+	if err := vlt.checkScope(ctx, vlt.db, id); err != nil {
+		return 0, errf("write secret: %w", err)
+	}
+
+	if err := vlt.refreshLink(ctx, id); err != nil {
+		return 0, errf("write secret: resolve link: %w", err)
+	}
+
+	nonce, ciphertext, err := vlt.db.ShowSecret(ctx, id)
+	if err != nil {
+		return 0, errf("write secret: %w", err)
+	}
+
+	write := func(nonce []byte, ciphertext []byte) (int64, error) {
+		plaintext, err := vlt.aesgcm.Open(nonce, ciphertext)
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := w.Write(plaintext)
+
+		return int64(n), err
+	}
+
+	written, err := write(nonce, ciphertext)
+	if err != nil {
+		return written, errf("write secret: %w", err)
+	}
+
+	seq := 0
+
+	err = vlt.db.EachSecretChunk(ctx, id, func(c vaultdb.SecretChunk) error {
+		if c.Seq != seq {
+			return fmt.Errorf("unexpected chunk %d", c.Seq)
+		}
+
+		n, err := write(c.Nonce, c.Ciphertext)
+		written += n
+		seq++
+
+		return err
+	})
+	if err != nil {
+		return written, errf("write secret: %w", err)
+	}
+
+	entry, err := vlt.auditRead(ctx, vaultdb.OpShow, id, true)
+	if err != nil {
+		return written, errf("write secret: audit: %w", err)
+	}
+
+	vlt.emit(entry)
+
+	return written, nil
+}
+
+// DeleteSecretsByIDs moves secrets to the trash by their IDs, see
+// [Vault.TrashedSecrets]. Trashed secrets are restored using
+// [Vault.RestoreTrashedSecret], and deleted using [Vault.PurgeTrash].
 func (vlt *Vault) DeleteSecretsByIDs(ctx context.Context, ids ...int) (int64, error) {
-	return vlt.db.DeleteSecretsByIDs(ctx, ids)
+	if err := vlt.checkWritable(ctx); err != nil {
+		return 0, errf("delete secrets: %w", err)
+	}
+
+	if err := vlt.checkScope(ctx, vlt.db, ids...); err != nil {
+		return 0, errf("delete secrets: %w", err)
+	}
+
+	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	deleteTx := vlt.db.WithTx(tx)
+
+	entries := make([]vaultdb.AuditEntry, 0, len(ids))
+
+	for _, id := range ids {
+		entry, err := vlt.audit(ctx, deleteTx, vaultdb.OpDelete, id)
+		if err != nil {
+			if err2 := tx.Rollback(); err2 != nil {
+				return 0, errf("delete secrets: audit: rollback: %w", errors.Join(err2, err))
+			}
+
+			return 0, errf("delete secrets: audit: %w", err)
+		}
+
+		entries = append(entries, entry)
+
+		if err := vlt.record(ctx, deleteTx, oplogDelete, id); err != nil {
+			if err2 := tx.Rollback(); err2 != nil {
+				return 0, errf("delete secrets: rollback: %w", errors.Join(err2, err))
+			}
+
+			return 0, errf("delete secrets: %w", err)
+		}
+	}
+
+	n, err := deleteTx.TrashSecretsByIDs(ctx, ids)
+	if err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return 0, errf("delete secrets: rollback: %w", errors.Join(err2, err))
+		}
+
+		return 0, errf("delete secrets: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errf("delete secrets: tx commit: %w", err)
+	}
+
+	vlt.emit(entries...)
+
+	return n, nil
+}
+
+// PurgeSecretsByIDs permanently deletes secrets by their IDs, along with
+// their labels and attachments, bypassing the trash.
+//
+// Like [Vault.PurgeTrash], secret values and their previous versions are
+// overwritten with random bytes before deletion, the states recorded for them
+// in the sync oplog are emptied, and the freed pages are vacuumed once the
+// deletion is committed.
+func (vlt *Vault) PurgeSecretsByIDs(ctx context.Context, ids ...int) (int64, error) {
+	if err := vlt.checkWritable(ctx); err != nil {
+		return 0, errf("purge secrets: %w", err)
+	}
+
+	if err := vlt.checkScope(ctx, vlt.db, ids...); err != nil {
+		return 0, errf("purge secrets: %w", err)
+	}
+
+	tx, err := vlt.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	deleteTx := vlt.db.WithTx(tx)
+
+	// audit first, entries capture the secret names before they are gone.
+	entries := make([]vaultdb.AuditEntry, 0, len(ids))
+
+	for _, id := range ids {
+		entry, err := vlt.audit(ctx, deleteTx, vaultdb.OpDelete, id)
+		if err != nil {
+			if err2 := tx.Rollback(); err2 != nil {
+				return 0, errf("purge secrets: audit: rollback: %w", errors.Join(err2, err))
+			}
+
+			return 0, errf("purge secrets: audit: %w", err)
+		}
+
+		entries = append(entries, entry)
+
+		if err := vlt.record(ctx, deleteTx, oplogDelete, id); err != nil {
+			if err2 := tx.Rollback(); err2 != nil {
+				return 0, errf("purge secrets: rollback: %w", errors.Join(err2, err))
+			}
+
+			return 0, errf("purge secrets: %w", err)
+		}
+	}
+
+	if err := vlt.shredOplog(ctx, deleteTx, ids); err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return 0, errf("purge secrets: rollback: %w", errors.Join(err2, err))
+		}
+
+		return 0, errf("purge secrets: %w", err)
+	}
+
+	if _, err := deleteTx.ShredSecretsByIDs(ctx, ids); err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return 0, errf("purge secrets: shred: rollback: %w", errors.Join(err2, err))
+		}
+
+		return 0, errf("purge secrets: shred: %w", err)
+	}
+
+	n, err := deleteTx.DeleteSecretsByIDs(ctx, ids)
+	if err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			return 0, errf("purge secrets: rollback: %w", errors.Join(err2, err))
+		}
+
+		return 0, errf("purge secrets: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errf("purge secrets: tx commit: %w", err)
+	}
+
+	vlt.emit(entries...)
+
+	if err := vlt.vaultContainerHandle.db.DeleteSecretsAttachments(ctx, ids); err != nil {
+		return n, errf("purge secrets: attachments: %w", err)
+	}
+
+	if err := vlt.vacuum(ctx); err != nil {
+		return n, errf("purge secrets: vacuum: %w", err)
+	}
+
+	return n, nil
+}
+
+// SecretsChangedAt returns the time each secret value was last set,
+// keyed by secret id.
+func (vlt *Vault) SecretsChangedAt(ctx context.Context) (map[int]time.Time, error) {
+	changedAt, err := vlt.db.SecretsChangedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return scoped(ctx, vlt, changedAt)
+}
+
+// AuditLog returns an iterator over the audit log entries
+// that match the given filters.
+//
+// The audit log covers all secrets, so it is unavailable
+// if the vault was opened with a token.
+func (vlt *Vault) AuditLog(ctx context.Context, filters vaultdb.AuditFilters) iter.Seq2[vaultdb.AuditEntry, error] {
+	if err := vlt.checkUnscoped(ctx); err != nil {
+		return func(yield func(vaultdb.AuditEntry, error) bool) {
+			yield(vaultdb.AuditEntry{}, errf("audit log: %w", err))
+		}
+	}
+
+	return vlt.db.AuditLog(ctx, filters)
 }
@@ -2,9 +2,11 @@ package vault_test
 
 import (
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaultcrypto"
 )
 
 // https://github.com/spf13/cobra/issues/1419
@@ -52,3 +54,51 @@ func TestVault_New(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestKDFParams(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := vault.New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := vault.KDFParams(t.Context(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := vaultcrypto.NewArgon2idKDF().PHC().Argon2Params; got != want {
+		t.Errorf("kdf params: got %+v, want %+v", got, want)
+	}
+}
+
+func TestContainerSchemaVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := vault.New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	version, supported, err := vault.ContainerSchemaVersion(t.Context(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version == 0 || version != supported {
+		t.Errorf("container schema version: got %d, want %d", version, supported)
+	}
+
+	if _, _, err := vault.ContainerSchemaVersion(t.Context(), filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Error("container schema version of a missing vault: got nil error")
+	}
+}
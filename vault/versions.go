@@ -0,0 +1,102 @@
+package vault
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// SecretHistory returns the previous values of the secret identified by id,
+// newest first, without the values themselves.
+func (vlt *Vault) SecretHistory(ctx context.Context, id int) ([]vaultdb.SecretVersion, error) {
+	if err := vlt.checkScope(ctx, vlt.db, id); err != nil {
+		return nil, errf("secret history: %w", err)
+	}
+
+	versions, err := vlt.db.SecretHistory(ctx, id)
+	if err != nil {
+		return nil, errf("secret history: %w", err)
+	}
+
+	if len(versions) == 0 {
+		switch _, _, err := vlt.db.ShowSecret(ctx, id); {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, errf("secret history: secret %d: %w", id, vaulterrors.ErrSearchNoMatch)
+		case err != nil:
+			return nil, errf("secret history: %w", err)
+		}
+	}
+
+	return versions, nil
+}
+
+// SecretAtVersion returns the value of the secret identified by id at the
+// given version, as listed by [Vault.SecretHistory].
+func (vlt *Vault) SecretAtVersion(ctx context.Context, id int, version int) (string, error) {
+	if err := vlt.checkScope(ctx, vlt.db, id); err != nil {
+		return "", errf("secret at version: %w", err)
+	}
+
+	nonce, ciphertext, err := vlt.db.SecretAtVersion(ctx, id, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errf("secret at version: %d: %w", version, vaulterrors.ErrVersionNotFound)
+	}
+
+	if err != nil {
+		return "", errf("secret at version: %d: %w", version, err)
+	}
+
+	value, err := vlt.aesgcm.Open(nonce, ciphertext)
+	if err != nil {
+		return "", errf("secret at version: %d: %w", version, err)
+	}
+
+	return string(value), nil
+}
+
+// RestoreSecretVersion sets the value of the secret identified by id to its
+// value at the given version. The value replaced is kept as a new version.
+func (vlt *Vault) RestoreSecretVersion(ctx context.Context, id int, version int) (int64, error) {
+	value, err := vlt.SecretAtVersion(ctx, id, version)
+	if err != nil {
+		return 0, err
+	}
+
+	return vlt.UpdateSecret(ctx, id, value)
+}
+
+// keepVersion keeps the current value of the secret as its next version,
+// before it is replaced by value. Unchanged values are not kept, nor are
+// values that cannot be decrypted, e.g., tampered with, so that these can
+// still be replaced.
+func (vlt *Vault) keepVersion(ctx context.Context, store *vaultdb.VaultDB, id int, value string) error {
+	nonce, ciphertext, err := store.ShowSecret(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	chunks, err := store.SecretChunks(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	curr, err := vlt.openChunks(nonce, ciphertext, chunks)
+	if err != nil || string(curr) == value {
+		return nil //nolint:nilerr // unreadable values are not kept.
+	}
+
+	if nonce, ciphertext, err = vlt.sealSecret(string(curr)); err != nil {
+		return err
+	}
+
+	_, err = store.InsertSecretVersion(ctx, id, nonce, ciphertext)
+
+	return err
+}
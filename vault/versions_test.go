@@ -0,0 +1,130 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestVault_SecretHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(t.Context(), path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := v.InsertNewSecret(t.Context(), "name", "v1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// chunked values are kept as versions as well.
+	large := strings.Repeat("x", secretChunkSize+1)
+
+	for _, value := range []string{"v2", "v2", large, "v4"} {
+		if _, err := v.UpdateSecret(t.Context(), id, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := v.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the versions are covered by the integrity MAC.
+	v, err = Open(t.Context(), path, WithPassword("password"))
+	if err != nil {
+		t.Fatalf("reopen vault: %v", err)
+	}
+	t.Cleanup(func() { _ = v.Close(t.Context()) })
+
+	history, err := v.SecretHistory(t.Context(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// unchanged values are not kept, the newest version is listed first.
+	want := map[int]string{3: large, 2: "v2", 1: "v1"}
+
+	if len(history) != len(want) {
+		t.Fatalf("history: got %d versions, want %d", len(history), len(want))
+	}
+
+	for i, h := range history {
+		if wantVersion := len(want) - i; h.Version != wantVersion {
+			t.Errorf("history[%d]: got version %d, want %d", i, h.Version, wantVersion)
+		}
+
+		got, err := v.SecretAtVersion(t.Context(), id, h.Version)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != want[h.Version] {
+			t.Errorf("version %d: got value of length %d, want %d", h.Version, len(got), len(want[h.Version]))
+		}
+	}
+
+	if _, err := v.SecretAtVersion(t.Context(), id, 4); !errors.Is(err, vaulterrors.ErrVersionNotFound) {
+		t.Errorf("missing version: got err %v, want %v", err, vaulterrors.ErrVersionNotFound)
+	}
+
+	if _, err := v.SecretHistory(t.Context(), id+1); !errors.Is(err, vaulterrors.ErrSearchNoMatch) {
+		t.Errorf("history of missing secret: got err %v, want %v", err, vaulterrors.ErrSearchNoMatch)
+	}
+
+	if _, err := v.RestoreSecretVersion(t.Context(), id, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := v.openValue(t.Context(), v.db, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(value) != "v1" {
+		t.Errorf("restored value: got %q, want %q", value, "v1")
+	}
+
+	// the value replaced by the restore is kept as well.
+	if got, err := v.SecretAtVersion(t.Context(), id, 4); err != nil || got != "v4" {
+		t.Errorf("version 4: got (%q, %v), want (%q, nil)", got, err, "v4")
+	}
+}
+
+func TestVault_Sync_SecretHistory(t *testing.T) {
+	a, b := syncedVaults(t)
+
+	ids, err := a.FilterSecrets(t.Context(), "", "shared", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for id := range ids {
+		if _, err := a.UpdateSecret(t.Context(), id, "v2"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exchange(t, a, b)
+
+	ids, err = b.FilterSecrets(t.Context(), "", "shared", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for id := range ids {
+		got, err := b.SecretAtVersion(t.Context(), id, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != "v1" {
+			t.Errorf("synced version: got %q, want %q", got, "v1")
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package vaultbackup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+)
+
+// At returns the latest backup created at or before t.
+func (d *Dir) At(t time.Time) (Entry, error) {
+	for _, e := range slices.Backward(d.entries) {
+		if !e.CreatedAt.After(t) {
+			return e, nil
+		}
+	}
+
+	return Entry{}, fmt.Errorf("no backup created at or before %s", t.Format(time.DateTime))
+}
+
+// Snapshot returns the snapshot the given backup restores from,
+// i.e., the backup itself if it is a full snapshot, or the snapshot a delta applies to.
+func (d *Dir) Snapshot(e Entry) (Entry, error) {
+	if e.Kind == KindFull {
+		return e, nil
+	}
+
+	base, ok := d.Entry(e.Base)
+	if !ok {
+		return Entry{}, fmt.Errorf("snapshot %s of backup %s not found", e.Base, e.ID)
+	}
+
+	return base, nil
+}
+
+// Restore reconstructs the vault captured by the given backup into a new vault file at path.
+//
+// The snapshot of the backup is copied to path, and for deltas, opened using
+// the given options to apply the delta. The file at path must not exist.
+func (d *Dir) Restore(ctx context.Context, e Entry, path string, opts ...vault.Option) (retErr error) {
+	snapshot, err := d.Snapshot(e)
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(d.Path, snapshot.File))
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Clean(path), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	// a partially restored vault is never left behind.
+	defer func() {
+		if retErr != nil {
+			_ = os.Remove(path)
+		}
+	}()
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("restore: %w", errors.Join(err, f.Close()))
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	if e.Kind == KindFull {
+		return nil
+	}
+
+	delta, err := os.ReadFile(filepath.Join(d.Path, e.File))
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	v, err := vault.Open(ctx, path, opts...)
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	if err := v.ApplyDelta(ctx, delta); err != nil {
+		return fmt.Errorf("restore: %w", errors.Join(err, v.Close(ctx)))
+	}
+
+	if err := v.Close(ctx); err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package vaultbackup
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Retention is a backup retention policy.
+//
+// For each of the most recent Daily days, Weekly weeks and Monthly months,
+// the latest backup made during that period is kept. The latest backup is always kept.
+type Retention struct {
+	Daily   int `json:"daily,omitempty"`
+	Weekly  int `json:"weekly,omitempty"`
+	Monthly int `json:"monthly,omitempty"`
+}
+
+// Enabled reports whether the policy retains anything, i.e., if pruning is configured.
+func (r Retention) Enabled() bool {
+	return r.Daily > 0 || r.Weekly > 0 || r.Monthly > 0
+}
+
+// Prune removes the backups not retained by the given policy, returning the removed entries.
+//
+// A delta is useless without the snapshot it applies to, so snapshots of retained
+// deltas are retained as well. In dry-run mode, nothing is removed.
+func (d *Dir) Prune(r Retention, dryRun bool) ([]Entry, error) {
+	if !r.Enabled() {
+		return nil, errors.New("prune: empty retention policy")
+	}
+
+	keep := d.retained(r)
+
+	var kept, removed []Entry
+
+	for _, e := range d.entries {
+		if keep[e.ID] {
+			kept = append(kept, e)
+		} else {
+			removed = append(removed, e)
+		}
+	}
+
+	if dryRun || len(removed) == 0 {
+		return removed, nil
+	}
+
+	// the index is updated first, a failed removal only leaves an unreferenced file behind.
+	prev := d.entries
+	d.entries = kept
+
+	if err := d.saveIndex(); err != nil {
+		d.entries = prev
+		return nil, fmt.Errorf("prune: %w", err)
+	}
+
+	var errs []error
+
+	for _, e := range removed {
+		if err := os.Remove(filepath.Join(d.Path, e.File)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return removed, fmt.Errorf("prune: %w", err)
+	}
+
+	return removed, nil
+}
+
+// retained returns the ids of the backups retained by the given policy.
+func (d *Dir) retained(r Retention) map[string]bool {
+	keep := make(map[string]bool)
+
+	if len(d.entries) == 0 {
+		return keep
+	}
+
+	keep[d.entries[len(d.entries)-1].ID] = true
+
+	periods := []struct {
+		n   int
+		key func(time.Time) string
+	}{
+		{r.Daily, func(t time.Time) string { return t.Format(time.DateOnly) }},
+		{r.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{r.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+
+	for _, p := range periods {
+		seen := make(map[string]bool)
+
+		for _, e := range slices.Backward(d.entries) {
+			if len(seen) >= p.n {
+				break
+			}
+
+			k := p.key(e.CreatedAt.Local())
+			if seen[k] {
+				continue
+			}
+
+			seen[k] = true
+			keep[e.ID] = true
+		}
+	}
+
+	for _, e := range d.entries {
+		if e.Kind == KindDelta && keep[e.ID] {
+			keep[e.Base] = true
+		}
+	}
+
+	return keep
+}
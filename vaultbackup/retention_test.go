@@ -0,0 +1,100 @@
+package vaultbackup
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDir_Prune(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2024, time.June, day, hour, 0, 0, 0, time.Local) }
+
+	entries := []Entry{
+		{ID: "f1", Kind: KindFull, CreatedAt: at(3, 12)}, // monday
+		{ID: "d1", Kind: KindDelta, Base: "f1", CreatedAt: at(4, 12)},
+		{ID: "d2", Kind: KindDelta, Base: "f1", CreatedAt: at(9, 12)}, // sunday
+		{ID: "f2", Kind: KindFull, CreatedAt: at(10, 12)},
+		{ID: "d3", Kind: KindDelta, Base: "f2", CreatedAt: at(10, 18)},
+		{ID: "d4", Kind: KindDelta, Base: "f2", CreatedAt: at(11, 12)},
+	}
+
+	tests := []struct {
+		name      string
+		retention Retention
+		want      []string
+	}{
+		{
+			name:      "daily",
+			retention: Retention{Daily: 2},
+			want:      []string{"f1", "d1", "d2"},
+		},
+		{
+			name:      "daily and weekly",
+			retention: Retention{Daily: 2, Weekly: 2},
+			want:      []string{"d1"},
+		},
+		{
+			name:      "monthly",
+			retention: Retention{Monthly: 1},
+			want:      []string{"f1", "d1", "d2", "d3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			for i := range entries {
+				entries[i].File = "vlt-" + entries[i].ID
+
+				if err := os.WriteFile(filepath.Join(dir, entries[i].File), nil, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			d := &Dir{Path: dir, entries: slices.Clone(entries)}
+
+			removed, err := d.Prune(tt.retention, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := ids(removed); !slices.Equal(got, tt.want) {
+				t.Errorf("dry run: got %v, want %v", got, tt.want)
+			}
+
+			if len(d.Entries()) != len(entries) {
+				t.Errorf("dry run: got %d entries, want %d", len(d.Entries()), len(entries))
+			}
+
+			if _, err := d.Prune(tt.retention, false); err != nil {
+				t.Fatal(err)
+			}
+
+			reopened, err := Open(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, e := range entries {
+				_, statErr := os.Stat(filepath.Join(dir, e.File))
+				kept := slices.Contains(ids(reopened.Entries()), e.ID)
+
+				if pruned := slices.Contains(tt.want, e.ID); pruned == kept || pruned == (statErr == nil) {
+					t.Errorf("%s: got kept %t (file exists %t), want pruned %t", e.ID, kept, statErr == nil, pruned)
+				}
+			}
+		})
+	}
+}
+
+func ids(entries []Entry) []string {
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,263 @@
+// Package vaultbackup manages a directory of encrypted vault backups.
+//
+// A backup is either a full snapshot of the vault, which can be opened as a
+// vault file on its own, or an incremental delta holding the changes made since
+// the latest snapshot. Backups are tracked by an index file in the directory.
+package vaultbackup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// Kind is the kind of a backup.
+type Kind string
+
+const (
+	KindFull  Kind = "full"  // KindFull is a full snapshot of the vault.
+	KindDelta Kind = "delta" // KindDelta holds the changes made since a full snapshot.
+)
+
+const (
+	// indexFile is the name of the index file in the backup directory.
+	indexFile = "index.json"
+
+	indexVersion = 1
+
+	// idLayout formats backup ids from their creation time.
+	idLayout = "20060102T150405Z"
+
+	// DefaultFullEvery is the default number of backups per full snapshot,
+	// i.e., a weekly snapshot for daily backups.
+	DefaultFullEvery = 7
+)
+
+// Entry describes a backup in the index.
+//
+//nolint:tagliatelle
+type Entry struct {
+	ID        string            `json:"id"`
+	Kind      Kind              `json:"kind"`
+	Base      string            `json:"base,omitempty"` // Base is the id of the snapshot a delta applies to.
+	File      string            `json:"file"`           // File is the backup file name, relative to the backup directory.
+	Size      int64             `json:"size"`
+	CreatedAt time.Time         `json:"created_at"`
+	State     vault.BackupState `json:"state"`
+}
+
+type index struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// Dir is a backup directory.
+type Dir struct {
+	Path    string
+	entries []Entry
+}
+
+// Open opens the backup directory at the given path, creating it if needed.
+func Open(path string) (*Dir, error) {
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return nil, fmt.Errorf("backup dir: %w", err)
+	}
+
+	d := &Dir{Path: path}
+
+	data, err := os.ReadFile(filepath.Join(path, indexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return d, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("backup dir: %w", err)
+	}
+
+	var idx index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("backup dir: %s: %w", indexFile, err)
+	}
+
+	if idx.Version != indexVersion {
+		return nil, fmt.Errorf("backup dir: %s: unsupported version %d", indexFile, idx.Version)
+	}
+
+	d.entries = idx.Entries
+
+	return d, nil
+}
+
+// Entries returns the backups in the directory, ordered by creation time.
+func (d *Dir) Entries() []Entry {
+	return slices.Clone(d.entries)
+}
+
+// Latest returns the most recent backup in the directory, or false if there is none.
+func (d *Dir) Latest() (Entry, bool) {
+	if len(d.entries) == 0 {
+		return Entry{}, false
+	}
+
+	return d.entries[len(d.entries)-1], true
+}
+
+// Entry returns the backup with the given id, or false if there is none.
+func (d *Dir) Entry(id string) (Entry, bool) {
+	i := slices.IndexFunc(d.entries, func(e Entry) bool { return e.ID == id })
+	if i < 0 {
+		return Entry{}, false
+	}
+
+	return d.entries[i], true
+}
+
+// EntryByFile returns the backup stored in the given file, or false if there is none.
+func (d *Dir) EntryByFile(name string) (Entry, bool) {
+	i := slices.IndexFunc(d.entries, func(e Entry) bool { return e.File == name })
+	if i < 0 {
+		return Entry{}, false
+	}
+
+	return d.entries[i], true
+}
+
+// Options configures [Dir.Backup].
+type Options struct {
+	// Incremental creates a delta if possible, instead of a full snapshot.
+	Incremental bool
+
+	// FullEvery is the number of backups per full snapshot,
+	// a full snapshot is created once the latest one has FullEvery-1 deltas.
+	FullEvery int
+}
+
+// Backup backs up the given vault to the directory, returning the index entry of the new backup.
+//
+// In incremental mode, a full snapshot is still created if there is no snapshot to
+// apply a delta to, the latest one has enough deltas, or the vault no longer
+// descends from it, e.g., after the master password was changed.
+func (d *Dir) Backup(ctx context.Context, v *vault.Vault, opts Options) (Entry, error) {
+	now := time.Now().UTC()
+
+	e := Entry{
+		ID:        d.newID(now),
+		Kind:      KindFull,
+		CreatedAt: now,
+	}
+
+	var (
+		data  []byte
+		state *vault.BackupState
+		err   error
+	)
+
+	if base, ok := d.deltaBase(opts); ok {
+		data, state, err = v.Delta(ctx, &base.State)
+		if err == nil {
+			e.Kind, e.Base = KindDelta, base.ID
+		}
+
+		if err != nil && !errors.Is(err, vaulterrors.ErrBackupBaseMismatch) {
+			return Entry{}, fmt.Errorf("backup: %w", err)
+		}
+	}
+
+	if e.Kind == KindFull {
+		if data, state, err = v.Snapshot(ctx); err != nil {
+			return Entry{}, fmt.Errorf("backup: %w", err)
+		}
+	}
+
+	e.File = fmt.Sprintf("vlt-%s.%s", e.ID, e.Kind)
+	e.Size = int64(len(data))
+	e.State = *state
+
+	if err := writeFile(filepath.Join(d.Path, e.File), data); err != nil {
+		return Entry{}, fmt.Errorf("backup: %w", err)
+	}
+
+	d.entries = append(d.entries, e)
+
+	if err := d.saveIndex(); err != nil {
+		return Entry{}, fmt.Errorf("backup: %w", err)
+	}
+
+	return e, nil
+}
+
+// deltaBase returns the snapshot the next incremental backup should apply to,
+// or false if a full snapshot is due.
+func (d *Dir) deltaBase(opts Options) (Entry, bool) {
+	if !opts.Incremental {
+		return Entry{}, false
+	}
+
+	i := -1
+
+	for j, e := range slices.Backward(d.entries) {
+		if e.Kind == KindFull {
+			i = j
+			break
+		}
+	}
+
+	if i < 0 {
+		return Entry{}, false
+	}
+
+	base := d.entries[i]
+
+	deltas := 0
+
+	for _, e := range d.entries[i+1:] {
+		if e.Base == base.ID {
+			deltas++
+		}
+	}
+
+	if fullEvery := max(opts.FullEvery, 1); deltas+1 >= fullEvery {
+		return Entry{}, false
+	}
+
+	return base, true
+}
+
+// newID returns a unique backup id for the given creation time.
+func (d *Dir) newID(t time.Time) string {
+	id := t.Format(idLayout)
+
+	for n := 2; slices.ContainsFunc(d.entries, func(e Entry) bool { return e.ID == id }); n++ {
+		id = fmt.Sprintf("%s-%d", t.Format(idLayout), n)
+	}
+
+	return id
+}
+
+func (d *Dir) saveIndex() error {
+	data, err := json.MarshalIndent(index{Version: indexVersion, Entries: d.entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFile(filepath.Join(d.Path, indexFile), data)
+}
+
+// writeFile atomically writes data to the named file.
+func writeFile(name string, data []byte) error {
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, name)
+}
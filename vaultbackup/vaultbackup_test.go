@@ -0,0 +1,99 @@
+package vaultbackup
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+)
+
+func TestDir_BackupRestore(t *testing.T) {
+	dir := t.TempDir()
+
+	v, err := vault.New(t.Context(), filepath.Join(dir, "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = v.Close(t.Context()) })
+
+	d, err := Open(filepath.Join(dir, "backups"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{Incremental: true, FullEvery: 2}
+
+	var backups []Entry
+
+	for _, name := range []string{"first", "second", "third"} {
+		if _, err := v.InsertNewSecret(t.Context(), name, name, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		e, err := d.Backup(t.Context(), v, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		backups = append(backups, e)
+	}
+
+	// a full snapshot every other backup.
+	for i, want := range []Kind{KindFull, KindDelta, KindFull} {
+		if backups[i].Kind != want {
+			t.Errorf("backup %d: got kind %s, want %s", i, backups[i].Kind, want)
+		}
+	}
+
+	for i, e := range backups {
+		res, err := d.Verify(t.Context(), e, 0, vault.WithPassword("password"))
+		if err != nil {
+			t.Fatalf("verify backup %d: %v", i, err)
+		}
+
+		if res.Decrypted != i+1 {
+			t.Errorf("verify backup %d: got %d secrets decrypted, want %d", i, res.Decrypted, i+1)
+		}
+	}
+
+	if _, err := d.Verify(t.Context(), backups[1], 0, vault.WithPassword("wrong")); err == nil {
+		t.Error("verify using a wrong password: got nil err")
+	}
+
+	// the delta is the latest backup at the time it was created.
+	e, err := d.At(backups[1].CreatedAt.Add(time.Nanosecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.ID != backups[1].ID {
+		t.Fatalf("backup at: got %s, want %s", e.ID, backups[1].ID)
+	}
+
+	path := filepath.Join(dir, "restored.db")
+	if err := d.Restore(t.Context(), e, path, vault.WithPassword("password")); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := vault.Open(t.Context(), path, vault.WithPassword("password"), vault.WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = restored.Close(t.Context()) })
+
+	secrets, err := restored.ExportSecrets(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secrets) != 2 {
+		t.Errorf("restored secrets: got %d, want 2", len(secrets))
+	}
+
+	if err := d.Restore(t.Context(), e, path, vault.WithPassword("password")); err == nil {
+		t.Error("restore to an existing file: got nil err")
+	}
+}
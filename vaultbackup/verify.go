@@ -0,0 +1,70 @@
+package vaultbackup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+)
+
+// Verification is the result of verifying a backup.
+type Verification struct {
+	// State is the state of the restored vault, it matches the
+	// state captured by the backup, if recorded in the index.
+	State *vault.BackupState
+
+	Audit     *vault.AuditVerification
+	Decrypted int // Decrypted is the number of secrets test-decrypted.
+}
+
+// Verify checks that the given backup restores.
+//
+// The backup is restored into a temporary file, which is opened read-only using
+// the given options, verifying the vault integrity. The restored vault state is
+// compared to the state recorded in the index, its audit log chain is verified,
+// and up to sample secrets are test-decrypted, or all secrets if sample is not positive.
+func (d *Dir) Verify(ctx context.Context, e Entry, sample int, opts ...vault.Option) (_ *Verification, retErr error) {
+	tmp, err := os.MkdirTemp("", "vlt-verify-")
+	if err != nil {
+		return nil, fmt.Errorf("verify: %w", err)
+	}
+	defer func() { retErr = errors.Join(retErr, os.RemoveAll(tmp)) }() //nolint:wsl
+
+	path := filepath.Join(tmp, "vault")
+
+	if err := d.Restore(ctx, e, path, opts...); err != nil {
+		return nil, fmt.Errorf("verify: %w", err)
+	}
+
+	v, err := vault.Open(ctx, path, append(opts, vault.WithReadOnly())...)
+	if err != nil {
+		return nil, fmt.Errorf("verify: %w", err)
+	}
+	defer func() { retErr = errors.Join(retErr, v.Close(ctx)) }() //nolint:wsl
+
+	res := &Verification{}
+
+	if res.State, err = v.BackupState(ctx); err != nil {
+		return nil, fmt.Errorf("verify: %w", err)
+	}
+
+	// standalone snapshots have no recorded state.
+	if len(e.State.KeyID) > 0 {
+		if err := e.State.Compare(res.State); err != nil {
+			return nil, fmt.Errorf("verify: restored state does not match the index: %w", err)
+		}
+	}
+
+	if res.Audit, err = v.VerifyAuditLog(ctx); err != nil {
+		return nil, fmt.Errorf("verify: %w", err)
+	}
+
+	if res.Decrypted, err = v.VerifyDecryption(ctx, sample); err != nil {
+		return nil, fmt.Errorf("verify: %w", err)
+	}
+
+	return res, nil
+}
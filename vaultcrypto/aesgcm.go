@@ -1,8 +1,11 @@
 package vaultcrypto
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
 	"errors"
 )
 
@@ -11,6 +14,7 @@ var ErrNilAESGCM = errors.New("AESGCM is nil")
 // AESGCM wraps an [cipher.AEAD] using AES in GCM mode.
 type AESGCM struct {
 	aead cipher.AEAD
+	key  []byte
 }
 
 // NewAESGCM creates a new AES-GCM cipher using the provided key.
@@ -25,7 +29,7 @@ func NewAESGCM(key []byte) (*AESGCM, error) {
 		return nil, err
 	}
 
-	return &AESGCM{aesgcm}, nil
+	return &AESGCM{aead: aesgcm, key: key}, nil
 }
 
 // Seal encrypts the plaintext using the given nonce.
@@ -46,6 +50,25 @@ func (g *AESGCM) Open(nonce, ciphertext []byte) ([]byte, error) {
 	return g.aead.Open(nil, nonce, ciphertext, nil)
 }
 
+// DeriveKey derives a subkey of the given length from the AES key
+// using HKDF-SHA256, info binds the subkey to its purpose.
+func (g *AESGCM) DeriveKey(info string, length int) ([]byte, error) {
+	if g == nil {
+		return nil, ErrNilAESGCM
+	}
+
+	return hkdf.Key(sha256.New, g.key, nil, info, length)
+}
+
+// Key returns a copy of the AES key.
+func (g *AESGCM) Key() []byte {
+	if g == nil {
+		return nil
+	}
+
+	return bytes.Clone(g.key)
+}
+
 // AEAD returns the underlying cipher.AEAD instance.
 func (g *AESGCM) AEAD() cipher.AEAD {
 	return g.aead
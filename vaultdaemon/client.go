@@ -3,15 +3,13 @@ package vaultdaemon
 import (
 	"context"
 	"errors"
-	"fmt"
-	"os"
-	"syscall"
 	"time"
 
 	pb "github.com/ladzaretti/vlt-cli/vaultdaemon/proto/sessionpb"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -21,35 +19,14 @@ var (
 
 // SessionClient wraps the gRPC SessionHandlerClient and provides
 // a higher-level interface for session operations.
+//
+// On Windows, sessions are kept in DPAPI protected files instead of the
+// daemon, and conn is nil, see [dpapiSessions].
 type SessionClient struct {
 	conn *grpc.ClientConn
 	pb   pb.SessionClient
 }
 
-// NewSessionClient connects to the local vault daemon over a UNIX socket
-// and returns a SessionClient.
-//
-// It returns [ErrSocketUnavailable] if the daemon socket is missing or inaccessible.
-func NewSessionClient() (*SessionClient, error) {
-	if err := verifySocketSecure(socketPath, os.Getuid()); err != nil {
-		return nil, err
-	}
-
-	conn, err := grpc.NewClient("unix://"+socketPath,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("%w: failed to connect: %v", ErrSocketUnavailable, err)
-	}
-
-	c := &SessionClient{
-		conn: conn,
-		pb:   pb.NewSessionClient(conn),
-	}
-
-	return c, nil
-}
-
 // Login starts a new session by storing cipher data for the given vault path.
 func (sc *SessionClient) Login(ctx context.Context, vaultPath string, key []byte, nonce []byte, duration time.Duration) error {
 	if sc == nil {
@@ -107,6 +84,17 @@ func (sc *SessionClient) GetSessionKey(ctx context.Context, vaultPath string) (k
 	return vaultKey.GetKey(), vaultKey.GetNonce(), nil
 }
 
+// Ping checks that sessions can be looked up, by looking up the session
+// of the given vault path. A missing session is not an error.
+func (sc *SessionClient) Ping(ctx context.Context, vaultPath string) error {
+	_, _, err := sc.GetSessionKey(ctx, vaultPath)
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+
+	return err
+}
+
 // Close safely shuts down the gRPC connection.
 // No-op if the client or connection is nil.
 func (sc *SessionClient) Close() error {
@@ -116,33 +104,3 @@ func (sc *SessionClient) Close() error {
 
 	return sc.conn.Close()
 }
-
-func verifySocketSecure(path string, uid int) (retErr error) {
-	fi, err := os.Stat(path)
-	if err != nil {
-		return fmt.Errorf("%w: could not stat socket: %w", ErrSocketUnavailable, err)
-	}
-
-	stat, ok := fi.Sys().(*syscall.Stat_t)
-	if !ok {
-		return errors.New("socket verify: unexpected file stat type")
-	}
-
-	if int(stat.Uid) != uid {
-		return fmt.Errorf("socket verify: unexpected socket owner uid: got %d, want %d", stat.Uid, uid)
-	}
-
-	if (fi.Mode() & os.ModeSymlink) != 0 {
-		return fmt.Errorf("socket verify: refusing to follow symlink: %s", path)
-	}
-
-	if (fi.Mode() & os.ModeSocket) == 0 {
-		return fmt.Errorf("socket verify: file is not a socket: %s", path)
-	}
-
-	if fi.Mode().Perm() != socketPerm {
-		return fmt.Errorf("socket verify: socket file has insecure permissions: %v", fi.Mode().Perm())
-	}
-
-	return nil
-}
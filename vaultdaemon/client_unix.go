@@ -0,0 +1,69 @@
+//go:build !windows
+
+package vaultdaemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+
+	pb "github.com/ladzaretti/vlt-cli/vaultdaemon/proto/sessionpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// NewSessionClient connects to the local vault daemon over a UNIX socket
+// and returns a SessionClient.
+//
+// It returns [ErrSocketUnavailable] if the daemon socket is missing or inaccessible.
+func NewSessionClient() (*SessionClient, error) {
+	if err := verifySocketSecure(socketPath, os.Getuid()); err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient("unix://"+socketPath,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to connect: %v", ErrSocketUnavailable, err)
+	}
+
+	c := &SessionClient{
+		conn: conn,
+		pb:   pb.NewSessionClient(conn),
+	}
+
+	return c, nil
+}
+
+func verifySocketSecure(path string, uid int) (retErr error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%w: could not stat socket: %w", ErrSocketUnavailable, err)
+	}
+
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return errors.New("socket verify: unexpected file stat type")
+	}
+
+	if int(stat.Uid) != uid {
+		return fmt.Errorf("socket verify: unexpected socket owner uid: got %d, want %d", stat.Uid, uid)
+	}
+
+	if (fi.Mode() & os.ModeSymlink) != 0 {
+		return fmt.Errorf("socket verify: refusing to follow symlink: %s", path)
+	}
+
+	if (fi.Mode() & os.ModeSocket) == 0 {
+		return fmt.Errorf("socket verify: file is not a socket: %s", path)
+	}
+
+	if fi.Mode().Perm() != socketPerm {
+		return fmt.Errorf("socket verify: socket file has insecure permissions: %v", fi.Mode().Perm())
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package vaultdaemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// NewSessionClient returns a SessionClient keeping sessions in DPAPI
+// protected files under the user cache directory, '%LocalAppData%\vlt\sessions'.
+//
+// It returns [ErrSocketUnavailable] if the directory is inaccessible.
+func NewSessionClient() (*SessionClient, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSocketUnavailable, err)
+	}
+
+	dir := filepath.Join(cache, "vlt", "sessions")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSocketUnavailable, err)
+	}
+
+	return &SessionClient{pb: &dpapiSessions{dir: dir}}, nil
+}
@@ -1,3 +1,5 @@
+//go:build !windows
+
 package vaultdaemon
 
 import (
@@ -11,17 +13,12 @@ import (
 
 	pb "github.com/ladzaretti/vlt-cli/vaultdaemon/proto/sessionpb"
 
-	"golang.org/x/sys/unix"
 	"google.golang.org/grpc"
 )
 
 // socketPerm is the file permission mode for the unix domain socket.
 const socketPerm = 0o600
 
-// socketPath is the path of the unix domain socket
-// used by the daemon.
-var socketPath = fmt.Sprintf("/run/user/%d/vlt.sock", os.Getuid())
-
 // Run starts the vltd daemon and serves grpc over a unix domain socket
 // that only allows connections from the same user that runs the daemon.
 func Run() error {
@@ -114,7 +111,7 @@ func (l *secureUnixListener) Accept() (net.Conn, error) {
 			return nil, err
 		}
 
-		ucred, err := getCred(conn)
+		uid, err := peerUID(conn)
 		if err != nil {
 			log.Printf("uid check failed: %v", err)
 			_ = conn.Close() //nolint:wsl
@@ -122,8 +119,8 @@ func (l *secureUnixListener) Accept() (net.Conn, error) {
 			continue
 		}
 
-		if int(ucred.Uid) != l.allowedUID {
-			log.Printf("connection from disallowed uid: %d", ucred.Uid)
+		if uid != l.allowedUID {
+			log.Printf("connection from disallowed uid: %d", uid)
 			_ = conn.Close() //nolint:wsl
 
 			continue
@@ -134,38 +131,33 @@ func (l *secureUnixListener) Accept() (net.Conn, error) {
 	}
 }
 
-// getCred returns the credentials from the remote end of a unix socket.
-func getCred(conn net.Conn) (*unix.Ucred, error) {
+// peerUID returns the uid of the process at the remote end of a unix socket.
+func peerUID(conn net.Conn) (int, error) {
 	unixConn, ok := conn.(*net.UnixConn)
 	if !ok {
-		return nil, fmt.Errorf("connection is not a *net.UnixConn: got %T", conn)
+		return 0, fmt.Errorf("connection is not a *net.UnixConn: got %T", conn)
 	}
 
 	rawConn, err := unixConn.SyscallConn()
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
 	var (
-		ucred    *unix.Ucred
-		ucredErr error
+		uid    int
+		uidErr error
 	)
 
 	err = rawConn.Control(func(fd uintptr) {
-		// Getsockopt syscall to retrieve peer credentials (uid, gid, pid)
-		// from the remote end of the connected unix socket
-		//
-		// see SO_PEERCRED:
-		// https://man7.org/linux/man-pages/man7/unix.7.html
-		ucred, ucredErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+		uid, uidErr = socketPeerUID(int(fd))
 	})
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	if ucredErr != nil {
-		return nil, ucredErr
+	if uidErr != nil {
+		return 0, uidErr
 	}
 
-	return ucred, nil
+	return uid, nil
 }
@@ -0,0 +1,26 @@
+package vaultdaemon
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// socketPath is the path of the unix domain socket used by the daemon.
+// macOS has no /run/user, $TMPDIR is a per-user directory instead.
+var socketPath = filepath.Join(os.TempDir(), "vlt.sock")
+
+// socketPeerUID returns the uid of the remote end of the connected unix socket fd.
+func socketPeerUID(fd int) (int, error) {
+	// Getsockopt syscall to retrieve the peer credentials (uid, groups)
+	// from the remote end of the connected unix socket, like getpeereid(3)
+	//
+	// see LOCAL_PEERCRED in unix(4).
+	xucred, err := unix.GetsockoptXucred(fd, unix.SOL_LOCAL, unix.LOCAL_PEERCRED)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(xucred.Uid), nil
+}
@@ -0,0 +1,27 @@
+package vaultdaemon
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// socketPath is the path of the unix domain socket
+// used by the daemon.
+var socketPath = fmt.Sprintf("/run/user/%d/vlt.sock", os.Getuid())
+
+// socketPeerUID returns the uid of the remote end of the connected unix socket fd.
+func socketPeerUID(fd int) (int, error) {
+	// Getsockopt syscall to retrieve peer credentials (uid, gid, pid)
+	// from the remote end of the connected unix socket
+	//
+	// see SO_PEERCRED:
+	// https://man7.org/linux/man-pages/man7/unix.7.html
+	ucred, err := unix.GetsockoptUcred(fd, unix.SOL_SOCKET, unix.SO_PEERCRED)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(ucred.Uid), nil
+}
@@ -0,0 +1,9 @@
+package vaultdaemon
+
+import "errors"
+
+// Run fails on Windows, where sessions are kept in DPAPI protected files
+// by the vlt client itself, see [dpapiSessions].
+func Run() error {
+	return errors.New("vltd is not needed on windows: sessions are kept using DPAPI")
+}
@@ -0,0 +1,181 @@
+package vaultdaemon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+	"unsafe"
+
+	pb "github.com/ladzaretti/vlt-cli/vaultdaemon/proto/sessionpb"
+
+	"golang.org/x/sys/windows"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// sessionFilePerm is the permission session files are written with.
+const sessionFilePerm = 0o600
+
+// dpapiSessions implements [pb.SessionClient] on Windows, where there is no
+// daemon, by keeping each session in a file encrypted using DPAPI.
+//
+// DPAPI encrypts the file for the current Windows user, other users and
+// other machines cannot decrypt it. The vault path is used as additional
+// entropy, binding each file to its vault.
+type dpapiSessions struct {
+	dir string
+}
+
+var _ pb.SessionClient = &dpapiSessions{}
+
+// sessionFile is the DPAPI protected content of a session file.
+type sessionFile struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	Key       []byte    `json:"key"`
+	Nonce     []byte    `json:"nonce"`
+}
+
+func (d *dpapiSessions) Login(_ context.Context, in *pb.LoginRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	if in.GetDurationSeconds() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid duration: %v", in.GetDurationSeconds())
+	}
+
+	raw, err := json.Marshal(sessionFile{
+		ExpiresAt: time.Now().Add(time.Duration(in.GetDurationSeconds()) * time.Second),
+		Key:       in.GetVaultKey().GetKey(),
+		Nonce:     in.GetVaultKey().GetNonce(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	protected, err := protect(raw, []byte(in.GetVaultPath()))
+	if err != nil {
+		return nil, err
+	}
+
+	path := d.path(in.GetVaultPath())
+
+	// written to a temporary file first, so that a session is never read partially written.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, protected, sessionFilePerm); err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (d *dpapiSessions) GetSessionKey(_ context.Context, in *pb.SessionRequest, _ ...grpc.CallOption) (*pb.VaultKey, error) {
+	path := d.path(in.GetVaultPath())
+
+	protected, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, status.Error(codes.NotFound, "no session found for the given path")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := unprotect(protected, []byte(in.GetVaultPath()))
+	if err != nil {
+		return nil, err
+	}
+
+	var s sessionFile
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+
+	clear(raw)
+
+	if !time.Now().Before(s.ExpiresAt) {
+		_ = os.Remove(path)
+		return nil, status.Error(codes.NotFound, "no session found for the given path")
+	}
+
+	return &pb.VaultKey{Key: s.Key, Nonce: s.Nonce}, nil
+}
+
+func (d *dpapiSessions) Logout(_ context.Context, in *pb.SessionRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	err := os.Remove(d.path(in.GetVaultPath()))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, status.Error(codes.NotFound, "no session found for the given path")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// path returns the path of the session file of the given vault.
+func (d *dpapiSessions) path(vaultPath string) string {
+	sum := sha256.Sum256([]byte(vaultPath))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".session")
+}
+
+// protect encrypts data for the current user using CryptProtectData.
+func protect(data []byte, entropy []byte) ([]byte, error) {
+	var out windows.DataBlob
+
+	err := windows.CryptProtectData(blob(data), nil, blob(entropy), 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return takeBlob(&out, false), nil
+}
+
+// unprotect decrypts data encrypted by [protect] using CryptUnprotectData.
+func unprotect(data []byte, entropy []byte) ([]byte, error) {
+	var out windows.DataBlob
+
+	err := windows.CryptUnprotectData(blob(data), nil, blob(entropy), 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return takeBlob(&out, true), nil
+}
+
+func blob(b []byte) *windows.DataBlob {
+	if len(b) == 0 {
+		return &windows.DataBlob{}
+	}
+
+	return &windows.DataBlob{Size: uint32(len(b)), Data: &b[0]} //nolint:gosec // session files are small.
+}
+
+// takeBlob copies the content of a blob allocated by DPAPI and frees it,
+// clearing it first if it holds plaintext.
+func takeBlob(b *windows.DataBlob, plaintext bool) []byte {
+	if b.Data == nil {
+		return nil
+	}
+
+	data := unsafe.Slice(b.Data, b.Size)
+	out := append([]byte(nil), data...)
+
+	if plaintext {
+		clear(data)
+	}
+
+	_, _ = windows.LocalFree(windows.Handle(unsafe.Pointer(b.Data))) //nolint:gosec // the blob is allocated by DPAPI.
+
+	return out
+}
@@ -0,0 +1,143 @@
+package vaulterrors
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// Code identifies the kind of an error, for tooling to branch on instead of
+// matching error messages. Codes are shared by the JSON error output of the
+// CLI, and the REST and gRPC APIs of 'vlt serve'.
+//
+// The set of codes is frozen: existing codes are never renamed, removed,
+// or given a different meaning. New codes may be added.
+type Code string
+
+const (
+	// CodeInternal is an unexpected error, or one without a more specific code.
+	CodeInternal Code = "INTERNAL"
+
+	// CodeInvalidArgument is an invalid request or input, e.g., an empty name.
+	CodeInvalidArgument Code = "INVALID_ARGUMENT"
+
+	// CodeUnauthenticated is a request without credentials.
+	CodeUnauthenticated Code = "UNAUTHENTICATED"
+
+	// CodePermissionDenied is an operation outside of the access token scope.
+	CodePermissionDenied Code = "PERMISSION_DENIED"
+
+	// CodeRateLimited is a request rejected by a rate limit or ban.
+	CodeRateLimited Code = "RATE_LIMITED"
+
+	// CodeSecretNotFound is a search that matched no secret.
+	CodeSecretNotFound Code = "SECRET_NOT_FOUND"
+
+	// CodeAmbiguousMatch is a search that matched several secrets,
+	// where exactly one was expected.
+	CodeAmbiguousMatch Code = "AMBIGUOUS_MATCH"
+
+	// CodeSecretTooLarge is a secret value exceeding the size limit.
+	CodeSecretTooLarge Code = "SECRET_TOO_LARGE"
+
+	// CodeNotFound is a missing resource other than a secret,
+	// e.g., an attachment or a saved search.
+	CodeNotFound Code = "NOT_FOUND"
+
+	// CodeVaultNotFound is a missing vault file.
+	CodeVaultNotFound Code = "VAULT_NOT_FOUND"
+
+	// CodeVaultExists is a vault file that already exists.
+	CodeVaultExists Code = "VAULT_EXISTS"
+
+	// CodeWrongPassword is an incorrect vault password.
+	CodeWrongPassword Code = "WRONG_PASSWORD"
+
+	// CodeVaultLocked is a vault without a session or an access token,
+	// that cannot be unlocked non-interactively.
+	CodeVaultLocked Code = "VAULT_LOCKED"
+
+	// CodeVaultBusy is a vault in use by another vlt command.
+	CodeVaultBusy Code = "VAULT_BUSY"
+
+	// CodeVaultModified is a vault modified outside of vlt.
+	CodeVaultModified Code = "VAULT_MODIFIED"
+
+	// CodeReadOnly is a modification of a read-only vault.
+	CodeReadOnly Code = "READ_ONLY"
+
+	// CodeUnsafePermissions is a vault file accessible by other users, in strict mode.
+	CodeUnsafePermissions Code = "UNSAFE_PERMISSIONS"
+
+	// CodeTokenInvalid is an invalid or revoked access token.
+	CodeTokenInvalid Code = "TOKEN_INVALID"
+
+	// CodeTokenExpired is an expired access token.
+	CodeTokenExpired Code = "TOKEN_EXPIRED"
+
+	// CodeInteractiveOnly is a command run non-interactively that requires a terminal.
+	CodeInteractiveOnly Code = "INTERACTIVE_ONLY"
+
+	// CodeSyncNotInitialized is a sync operation on a vault that sync is not initialized for.
+	CodeSyncNotInitialized Code = "SYNC_NOT_INITIALIZED"
+
+	// CodeSyncConflict is an oplog that cannot be exchanged with the vault.
+	CodeSyncConflict Code = "SYNC_CONFLICT"
+
+	// CodeFailedPrecondition is an operation the vault is not in a state to
+	// perform, e.g., resolving a linked secret without a configured provider.
+	CodeFailedPrecondition Code = "FAILED_PRECONDITION"
+
+	// CodeDaemonUnavailable is a session operation without a running 'vltd' daemon.
+	CodeDaemonUnavailable Code = "DAEMON_UNAVAILABLE"
+)
+
+// codes maps errors to their codes, matched in order using [errors.Is].
+var codes = []struct {
+	err  error
+	code Code
+}{
+	{ErrEmptyName, CodeInvalidArgument},
+	{ErrEmptySecret, CodeInvalidArgument},
+	{ErrMissingLabels, CodeInvalidArgument},
+	{ErrNotTOTP, CodeInvalidArgument},
+	{ErrTokenScope, CodePermissionDenied},
+	{ErrSearchNoMatch, CodeSecretNotFound},
+	{sql.ErrNoRows, CodeSecretNotFound},
+	{ErrAmbiguousSecretMatch, CodeAmbiguousMatch},
+	{ErrSecretTooLarge, CodeSecretTooLarge},
+	{ErrAttachmentNotFound, CodeNotFound},
+	{ErrSavedSearchNotFound, CodeNotFound},
+	{ErrVersionNotFound, CodeNotFound},
+	{ErrNotTrashed, CodeNotFound},
+	{ErrRotationNotScheduled, CodeNotFound},
+	{ErrVaultFileNotFound, CodeVaultNotFound},
+	{ErrVaultFileExists, CodeVaultExists},
+	{ErrWrongPassword, CodeWrongPassword},
+	{ErrVaultLocked, CodeVaultLocked},
+	{ErrVaultBusy, CodeVaultBusy},
+	{ErrVaultModified, CodeVaultModified},
+	{ErrReadOnly, CodeReadOnly},
+	{ErrUnsafePermissions, CodeUnsafePermissions},
+	{ErrInvalidToken, CodeTokenInvalid},
+	{ErrTokenExpired, CodeTokenExpired},
+	{ErrNonInteractiveUnsupported, CodeInteractiveOnly},
+	{ErrSyncNotInitialized, CodeSyncNotInitialized},
+	{ErrSyncDeviceConflict, CodeSyncConflict},
+	{ErrSyncKeyMismatch, CodeSyncConflict},
+	{ErrSyncInitialized, CodeFailedPrecondition},
+	{ErrBackupBaseMismatch, CodeFailedPrecondition},
+	{ErrNoLinkResolver, CodeFailedPrecondition},
+	{ErrLinkedSecret, CodeFailedPrecondition},
+	{ErrNotPairing, CodeFailedPrecondition},
+}
+
+// CodeOf returns the code of err, or [CodeInternal] if it is not known.
+func CodeOf(err error) Code {
+	for _, c := range codes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+
+	return CodeInternal
+}
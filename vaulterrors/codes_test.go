@@ -0,0 +1,35 @@
+package vaulterrors_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want vaulterrors.Code
+	}{
+		{"no match", vaulterrors.ErrSearchNoMatch, vaulterrors.CodeSecretNotFound},
+		{"wrapped", fmt.Errorf("show: %w", vaulterrors.ErrSearchNoMatch), vaulterrors.CodeSecretNotFound},
+		{"no rows", fmt.Errorf("get: %w", sql.ErrNoRows), vaulterrors.CodeSecretNotFound},
+		{"joined", errors.Join(errors.New("other"), vaulterrors.ErrVaultBusy), vaulterrors.CodeVaultBusy},
+		{"wrong password", vaulterrors.ErrWrongPassword, vaulterrors.CodeWrongPassword},
+		{"token expired", vaulterrors.ErrTokenExpired, vaulterrors.CodeTokenExpired},
+		{"token scope", vaulterrors.ErrTokenScope, vaulterrors.CodePermissionDenied},
+		{"unknown", errors.New("boom"), vaulterrors.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vaulterrors.CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -20,4 +20,52 @@ var (
 	ErrSearchNoMatch = errors.New("no match found")
 
 	ErrAmbiguousSecretMatch = errors.New("ambiguous secret match: multiple secrets match the search criteria")
+
+	ErrReadOnly = errors.New("vault is read-only")
+
+	ErrVaultModified = errors.New("vault contents were modified outside of vlt")
+
+	ErrVaultLocked = errors.New("vault is locked: no session or access token found")
+
+	ErrUnsafePermissions = errors.New("unsafe vault permissions")
+
+	ErrInvalidToken = errors.New("invalid or revoked access token")
+
+	ErrTokenExpired = errors.New("access token expired")
+
+	ErrTokenScope = errors.New("operation outside of the access token scope")
+
+	ErrSyncNotInitialized = errors.New("sync is not initialized")
+
+	ErrSyncInitialized = errors.New("sync is already initialized")
+
+	ErrSyncDeviceConflict = errors.New("sync device id is used by another device")
+
+	ErrSyncKeyMismatch = errors.New("oplog was not written by a device synced with this vault")
+
+	ErrBackupBaseMismatch = errors.New("vault does not descend from the backup snapshot")
+
+	ErrNoLinkResolver = errors.New("no provider configured to resolve linked secrets")
+
+	ErrLinkedSecret = errors.New("secret value is resolved from a link")
+
+	ErrSecretTooLarge = errors.New("secret value exceeds the size limit")
+
+	ErrAttachmentNotFound = errors.New("attachment not found")
+
+	ErrSavedSearchNotFound = errors.New("saved search not found")
+
+	ErrVersionNotFound = errors.New("secret version not found")
+
+	ErrNotTrashed = errors.New("secret is not in the trash")
+
+	ErrNotTOTP = errors.New("secret is not a totp seed")
+
+	ErrNotPairing = errors.New("no browser extension pairing in progress, run 'vlt keepassxc pair' first")
+
+	ErrRotationNotScheduled = errors.New("secret rotation not scheduled")
+
+	ErrVaultBusy = errors.New("vault is in use by another vlt command")
+
+	ErrVaultChanged = errors.New("vault was written by another vlt command while open")
 )
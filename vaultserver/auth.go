@@ -0,0 +1,89 @@
+package vaultserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+)
+
+// errMissingToken is returned for requests without a bearer token.
+var errMissingToken = errors.New("missing bearer token")
+
+// rejection is a request rejected before reaching the vault,
+// shared by the REST and gRPC APIs.
+type rejection struct {
+	reason     string        // reason is one of the rejection reasons reported by the metrics.
+	retryAfter time.Duration // retryAfter is set for rate limited and banned clients.
+	err        error
+}
+
+// admit applies the ban list and the client rate limit to a request of the given client.
+func (s *Server) admit(client string, now time.Time) *rejection {
+	if until, ok := s.bans.banned(client, now); ok {
+		s.metrics.reject(rejectBanned)
+		return &rejection{reason: rejectBanned, retryAfter: until.Sub(now), err: errors.New("too many authentication failures")}
+	}
+
+	if wait, ok := s.clientLimits.allow(client, now); !ok {
+		s.metrics.reject(rejectClientRate)
+		return &rejection{reason: rejectClientRate, retryAfter: wait, err: errors.New("rate limit exceeded")}
+	}
+
+	return nil
+}
+
+// authorize verifies the given authorization value ('Bearer <token>') of
+// a request of the given client, and applies the token rate limit.
+//
+// The caller must hold s.mu.
+func (s *Server) authorize(ctx context.Context, client string, authorization string) (vault.Principal, *rejection) {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		s.metrics.reject(rejectAuth)
+		return vault.Principal{}, &rejection{reason: rejectAuth, err: errMissingToken}
+	}
+
+	now := s.now()
+
+	p, err := s.vault.AuthenticateAPIToken(ctx, token, now)
+	if err != nil {
+		s.metrics.reject(rejectAuth)
+
+		if s.bans.fail(client, now) {
+			s.metrics.bans.Add(1)
+		}
+
+		return vault.Principal{}, &rejection{reason: rejectAuth, err: err}
+	}
+
+	if wait, ok := s.tokenLimits.allow(p.Actor, now); !ok {
+		s.metrics.reject(rejectTokenRate)
+		return vault.Principal{}, &rejection{reason: rejectTokenRate, retryAfter: wait, err: errors.New("token rate limit exceeded")}
+	}
+
+	s.metrics.unlock(p.Actor)
+
+	return p, nil
+}
+
+// writeRejection writes the REST response of a rejected request.
+func writeRejection(w http.ResponseWriter, rej *rejection) {
+	if rej.reason != rejectAuth {
+		tooManyRequests(w, rej.retryAfter, rej.err)
+		return
+	}
+
+	if errors.Is(rej.err, errMissingToken) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="vlt"`)
+		writeError(w, http.StatusUnauthorized, rej.err)
+
+		return
+	}
+
+	w.Header().Set("WWW-Authenticate", `Bearer realm="vlt", error="invalid_token"`)
+	writeError(w, statusCode(rej.err), rej.err)
+}
@@ -0,0 +1,875 @@
+package vaultserver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/bitwarden"
+	"github.com/ladzaretti/vlt-cli/vault"
+)
+
+const (
+	// bitwardenServerVersion is the Bitwarden server version reported to clients,
+	// which refuse to connect to servers they consider outdated.
+	bitwardenServerVersion = "2025.1.0"
+
+	bitwardenAccessTTL  = 2 * time.Hour
+	bitwardenRefreshTTL = 30 * 24 * time.Hour
+
+	// bitwardenLabelsField is the name of the custom field holding the secret labels, comma-separated.
+	bitwardenLabelsField = "labels"
+
+	bitwardenCipherLogin = 1
+)
+
+// bitwardenState holds the Bitwarden client sessions.
+//
+// Sessions are kept in memory, clients log in again once the server restarts.
+type bitwardenState struct {
+	jwtKey  []byte
+	refresh map[string]bitwardenSession // refresh is keyed by refresh token.
+}
+
+type bitwardenSession struct {
+	device    string
+	expiresAt time.Time
+}
+
+// WithBitwarden enables the Bitwarden compatible API, serving the account set
+// up using [vault.Vault.SetBitwardenAccount] to the official Bitwarden clients.
+//
+// Only the parts of the API required to log in, sync and edit login items are
+// implemented. Each secret is served as a login item, with its value as the
+// password, and its labels in a 'labels' custom field. Folders, organizations,
+// attachments, sends and two-factor authentication are not supported.
+func WithBitwarden() Option {
+	return func(s *Server) {
+		key := make([]byte, 32)
+		_, _ = rand.Read(key) // never fails.
+
+		s.bitwarden = &bitwardenState{jwtKey: key, refresh: make(map[string]bitwardenSession)}
+	}
+}
+
+func (s *Server) handleBitwarden() {
+	s.mux.HandleFunc("POST /identity/accounts/prelogin", s.bitwardenPrelogin)
+	s.mux.HandleFunc("POST /api/accounts/prelogin", s.bitwardenPrelogin)
+	s.mux.HandleFunc("POST /identity/connect/token", s.bitwardenToken)
+	s.mux.HandleFunc("GET /api/config", bitwardenConfig)
+	s.mux.HandleFunc("GET /api/devices/knowndevice", bitwardenKnownDevice)
+	s.mux.HandleFunc("GET /api/accounts/revision-date", s.bitwardenAuthenticated(s.bitwardenRevisionDate))
+	s.mux.HandleFunc("GET /api/accounts/profile", s.bitwardenAuthenticated(s.bitwardenProfile))
+	s.mux.HandleFunc("GET /api/sync", s.bitwardenAuthenticated(s.bitwardenSync))
+	s.mux.HandleFunc("GET /api/ciphers/{id}", s.bitwardenAuthenticated(s.bitwardenShowCipher))
+	s.mux.HandleFunc("POST /api/ciphers", s.bitwardenAuthenticated(s.bitwardenCreateCipher))
+	s.mux.HandleFunc("PUT /api/ciphers/{id}", s.bitwardenAuthenticated(s.bitwardenUpdateCipher))
+	s.mux.HandleFunc("POST /api/ciphers/{id}", s.bitwardenAuthenticated(s.bitwardenUpdateCipher))
+	s.mux.HandleFunc("DELETE /api/ciphers/{id}", s.bitwardenAuthenticated(s.bitwardenDeleteCipher))
+	s.mux.HandleFunc("PUT /api/ciphers/{id}/delete", s.bitwardenAuthenticated(s.bitwardenDeleteCipher))
+}
+
+type bitwardenPreloginResponse struct {
+	KDF            int  `json:"kdf"`
+	KDFIterations  int  `json:"kdfIterations"`
+	KDFMemory      *int `json:"kdfMemory"`
+	KDFParallelism *int `json:"kdfParallelism"`
+}
+
+// bitwardenPrelogin returns the KDF parameters of the account.
+// Unknown emails get the default parameters, not revealing the account email.
+func (s *Server) bitwardenPrelogin(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := bitwardenPreloginResponse{KDF: bitwarden.KDFPBKDF2, KDFIterations: bitwarden.DefaultKDFIterations}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBitwardenError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	a, _, err := s.vault.BitwardenAccount(r.Context())
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		writeBitwardenError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err == nil && a.MatchesEmail(req.Email) {
+		res.KDFIterations = a.KDFIterations
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+type bitwardenTokenResponse struct {
+	AccessToken           string                         `json:"access_token"`
+	ExpiresIn             int                            `json:"expires_in"`
+	TokenType             string                         `json:"token_type"`
+	RefreshToken          string                         `json:"refresh_token"`
+	Scope                 string                         `json:"scope"`
+	Key                   string                         `json:"Key"`
+	PrivateKey            string                         `json:"PrivateKey"`
+	KDF                   int                            `json:"Kdf"`
+	KDFIterations         int                            `json:"KdfIterations"`
+	KDFMemory             *int                           `json:"KdfMemory"`
+	KDFParallelism        *int                           `json:"KdfParallelism"`
+	ResetMasterPassword   bool                           `json:"ResetMasterPassword"`
+	ForcePasswordReset    bool                           `json:"ForcePasswordReset"`
+	UnofficialServer      bool                           `json:"unofficialServer"`
+	UserDecryptionOptions bitwardenUserDecryptionOptions `json:"UserDecryptionOptions"`
+}
+
+type bitwardenUserDecryptionOptions struct {
+	HasMasterPassword bool   `json:"HasMasterPassword"`
+	Object            string `json:"Object"`
+}
+
+// bitwardenToken logs clients in using the password or refresh token grants.
+func (s *Server) bitwardenToken(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	if err := r.ParseForm(); err != nil {
+		writeBitwardenError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	a, _, err := s.vault.BitwardenAccount(r.Context())
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		writeBitwardenError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	now := s.now()
+	accountFound := err == nil
+
+	var device string
+
+	switch r.PostForm.Get("grant_type") {
+	case "password":
+		if !accountFound || !a.MatchesEmail(r.PostForm.Get("username")) || !a.VerifyPassword(r.PostForm.Get("password")) {
+			s.bitwardenLoginFailed(w, r, now)
+			return
+		}
+
+		device = r.PostForm.Get("deviceIdentifier")
+	case "refresh_token":
+		token := r.PostForm.Get("refresh_token")
+
+		session, ok := s.bitwarden.refresh[token]
+		if !accountFound || !ok || !now.Before(session.expiresAt) {
+			delete(s.bitwarden.refresh, token)
+			s.bitwardenLoginFailed(w, r, now)
+
+			return
+		}
+
+		delete(s.bitwarden.refresh, token) // refresh tokens are rotated.
+
+		device = session.device
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	access, err := s.bitwardenAccessToken(a, device, now)
+	if err != nil {
+		writeBitwardenError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refresh := rand.Text()
+	s.bitwarden.refresh[refresh] = bitwardenSession{device: device, expiresAt: now.Add(bitwardenRefreshTTL)}
+
+	s.metrics.unlock(bitwardenActor(device))
+
+	writeJSON(w, http.StatusOK, bitwardenTokenResponse{
+		AccessToken:           access,
+		ExpiresIn:             int(bitwardenAccessTTL.Seconds()),
+		TokenType:             "Bearer",
+		RefreshToken:          refresh,
+		Scope:                 "api offline_access",
+		Key:                   a.ProtectedKey,
+		PrivateKey:            a.ProtectedPrivateKey,
+		KDF:                   bitwarden.KDFPBKDF2,
+		KDFIterations:         a.KDFIterations,
+		UnofficialServer:      true,
+		UserDecryptionOptions: bitwardenUserDecryptionOptions{HasMasterPassword: true, Object: "userDecryptionOptions"},
+	})
+}
+
+// bitwardenLoginFailed rejects a login attempt, counting it towards a ban of the client.
+func (s *Server) bitwardenLoginFailed(w http.ResponseWriter, r *http.Request, now time.Time) {
+	s.metrics.reject(rejectAuth)
+
+	if s.bans.fail(clientIP(r), now) {
+		s.metrics.bans.Add(1)
+	}
+
+	writeJSON(w, http.StatusBadRequest, map[string]any{
+		"error":             "invalid_grant",
+		"error_description": "invalid_username_or_password",
+		"ErrorModel": map[string]string{
+			"Message": "Username or password is incorrect. Try again",
+			"Object":  "error",
+		},
+	})
+}
+
+type bitwardenClaims struct {
+	NotBefore     int64    `json:"nbf"`
+	ExpiresAt     int64    `json:"exp"`
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Premium       bool     `json:"premium"`
+	Name          string   `json:"name"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	SecurityStamp string   `json:"sstamp"`
+	Device        string   `json:"device"`
+	Scope         []string `json:"scope"`
+	AMR           []string `json:"amr"`
+}
+
+// bitwardenAccessToken returns a signed JWT access token, which clients decode for account details.
+func (s *Server) bitwardenAccessToken(a bitwarden.Account, device string, now time.Time) (string, error) {
+	claims, err := json.Marshal(bitwardenClaims{
+		NotBefore:     now.Unix(),
+		ExpiresAt:     now.Add(bitwardenAccessTTL).Unix(),
+		Issuer:        "vlt",
+		Subject:       bitwardenUserID(a),
+		Premium:       true,
+		Name:          a.Email,
+		Email:         a.Email,
+		EmailVerified: true,
+		SecurityStamp: bitwardenSecurityStamp(a),
+		Device:        device,
+		Scope:         []string{"api", "offline_access"},
+		AMR:           []string{"Application"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	payload := b64([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + b64(claims)
+
+	return payload + "." + b64(s.bitwardenSign(payload)), nil
+}
+
+// verifyBitwardenAccessToken verifies the given access token at time now,
+// returning its claims.
+func (s *Server) verifyBitwardenAccessToken(token string, now time.Time) (bitwardenClaims, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if ok {
+		var claims string
+
+		claims, sig, ok = strings.Cut(sig, ".")
+		payload += "." + claims
+	}
+
+	if !ok {
+		return bitwardenClaims{}, errors.New("malformed access token")
+	}
+
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, s.bitwardenSign(payload)) {
+		return bitwardenClaims{}, errors.New("invalid access token")
+	}
+
+	_, encoded, _ := strings.Cut(payload, ".")
+
+	b, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return bitwardenClaims{}, errors.New("malformed access token")
+	}
+
+	var claims bitwardenClaims
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return bitwardenClaims{}, errors.New("malformed access token")
+	}
+
+	if now.Unix() >= claims.ExpiresAt {
+		return bitwardenClaims{}, errors.New("access token expired")
+	}
+
+	return claims, nil
+}
+
+func (s *Server) bitwardenSign(payload string) []byte {
+	mac := hmac.New(sha256.New, s.bitwarden.jwtKey)
+	mac.Write([]byte(payload))
+
+	return mac.Sum(nil)
+}
+
+// bitwardenRequest is an authenticated Bitwarden API request.
+type bitwardenRequest struct {
+	*http.Request
+
+	account bitwarden.Account
+	userKey bitwarden.Key
+}
+
+type bitwardenHandlerFunc func(r *bitwardenRequest) (int, any, error)
+
+// bitwardenAuthenticated wraps h with access token authentication,
+// see [Server.authenticated].
+//
+// h is called with the vault locked, on behalf of the client device.
+func (s *Server) bitwardenAuthenticated(h bitwardenHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		claims, err := s.verifyBitwardenAccessToken(token, s.now())
+		if err != nil {
+			s.metrics.reject(rejectAuth)
+			writeBitwardenError(w, http.StatusUnauthorized, err)
+
+			return
+		}
+
+		a, userKey, err := s.vault.BitwardenAccount(r.Context())
+		if err != nil {
+			writeBitwardenError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		// the account was reset since the token was issued.
+		if claims.SecurityStamp != bitwardenSecurityStamp(a) {
+			s.metrics.reject(rejectAuth)
+			writeBitwardenError(w, http.StatusUnauthorized, errors.New("invalid access token"))
+
+			return
+		}
+
+		ctx := vault.WithPrincipal(r.Context(), vault.Principal{Actor: bitwardenActor(claims.Device)})
+		r = r.WithContext(ctx)
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+		code, body, err := h(&bitwardenRequest{Request: r, account: a, userKey: userKey})
+
+		// sync even on failure, audit entries of reads are recorded regardless.
+		if syncErr := s.vault.Sync(context.WithoutCancel(ctx)); syncErr != nil {
+			writeBitwardenError(w, http.StatusInternalServerError, syncErr)
+			return
+		}
+
+		if err != nil {
+			writeBitwardenError(w, statusCode(err), err)
+			return
+		}
+
+		writeJSON(w, code, body)
+	}
+}
+
+type bitwardenConfigResponse struct {
+	Version       string                     `json:"version"`
+	GitHash       string                     `json:"gitHash"`
+	Server        bitwardenConfigServer      `json:"server"`
+	Environment   bitwardenConfigEnvironment `json:"environment"`
+	FeatureStates map[string]bool            `json:"featureStates"`
+	Object        string                     `json:"object"`
+}
+
+type bitwardenConfigServer struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type bitwardenConfigEnvironment struct {
+	Vault         string `json:"vault"`
+	API           string `json:"api"`
+	Identity      string `json:"identity"`
+	Notifications string `json:"notifications"`
+	SSO           string `json:"sso"`
+}
+
+func bitwardenConfig(w http.ResponseWriter, r *http.Request) {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	base := scheme + "://" + r.Host
+
+	writeJSON(w, http.StatusOK, bitwardenConfigResponse{
+		Version: bitwardenServerVersion,
+		GitHash: "vlt",
+		Server:  bitwardenConfigServer{Name: "vlt", URL: "https://github.com/ladzaretti/vlt-cli"},
+		Environment: bitwardenConfigEnvironment{
+			Vault:         base,
+			API:           base + "/api",
+			Identity:      base + "/identity",
+			Notifications: base + "/notifications",
+		},
+		FeatureStates: map[string]bool{},
+		Object:        "config",
+	})
+}
+
+// bitwardenKnownDevice reports every device as known, new device
+// verification e-mails are not supported.
+func bitwardenKnownDevice(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, true)
+}
+
+// bitwardenRevisionDate returns the current time, as changes are not tracked
+// per account, making clients sync whenever they check for changes.
+func (s *Server) bitwardenRevisionDate(*bitwardenRequest) (int, any, error) {
+	return http.StatusOK, s.now().UnixMilli(), nil
+}
+
+type bitwardenProfile struct {
+	ID                 string   `json:"id"`
+	Name               string   `json:"name"`
+	Email              string   `json:"email"`
+	EmailVerified      bool     `json:"emailVerified"`
+	Premium            bool     `json:"premium"`
+	Key                string   `json:"key"`
+	PrivateKey         string   `json:"privateKey"`
+	SecurityStamp      string   `json:"securityStamp"`
+	Culture            string   `json:"culture"`
+	TwoFactorEnabled   bool     `json:"twoFactorEnabled"`
+	ForcePasswordReset bool     `json:"forcePasswordReset"`
+	UsesKeyConnector   bool     `json:"usesKeyConnector"`
+	Organizations      []string `json:"organizations"`
+	Providers          []string `json:"providers"`
+	ProviderOrgs       []string `json:"providerOrganizations"`
+	Object             string   `json:"object"`
+}
+
+func (*Server) bitwardenProfile(r *bitwardenRequest) (int, any, error) {
+	return http.StatusOK, newBitwardenProfile(r.account), nil
+}
+
+func newBitwardenProfile(a bitwarden.Account) bitwardenProfile {
+	return bitwardenProfile{
+		ID:            bitwardenUserID(a),
+		Name:          a.Email,
+		Email:         a.Email,
+		EmailVerified: true,
+		Premium:       true,
+		Key:           a.ProtectedKey,
+		PrivateKey:    a.ProtectedPrivateKey,
+		SecurityStamp: bitwardenSecurityStamp(a),
+		Culture:       "en-US",
+		Organizations: []string{},
+		Providers:     []string{},
+		ProviderOrgs:  []string{},
+		Object:        "profile",
+	}
+}
+
+type bitwardenSyncResponse struct {
+	Profile     bitwardenProfile  `json:"profile"`
+	Folders     []string          `json:"folders"`
+	Collections []string          `json:"collections"`
+	Policies    []string          `json:"policies"`
+	Ciphers     []bitwardenCipher `json:"ciphers"`
+	Domains     map[string]any    `json:"domains"`
+	Sends       []string          `json:"sends"`
+	Object      string            `json:"object"`
+}
+
+// bitwardenSync returns the whole account, including all secrets, encrypted by the user key.
+func (s *Server) bitwardenSync(r *bitwardenRequest) (int, any, error) {
+	secrets, err := s.vault.ExportSecrets(r.Context())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	changedAt, err := s.vault.SecretsChangedAt(r.Context())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	ids := slices.Sorted(func(yield func(int) bool) {
+		for id := range secrets {
+			if !yield(id) {
+				return
+			}
+		}
+	})
+
+	ciphers := make([]bitwardenCipher, 0, len(secrets))
+
+	for _, id := range ids {
+		secret := secrets[id]
+
+		c, err := newBitwardenCipher(r.userKey, Secret{ID: id, Name: secret.Name, Labels: secret.Labels, Secret: secret.Value}, changedAt[id])
+		if err != nil {
+			return 0, nil, err
+		}
+
+		ciphers = append(ciphers, c)
+	}
+
+	res := bitwardenSyncResponse{
+		Profile:     newBitwardenProfile(r.account),
+		Folders:     []string{},
+		Collections: []string{},
+		Policies:    []string{},
+		Ciphers:     ciphers,
+		Domains:     map[string]any{"equivalentDomains": []string{}, "globalEquivalentDomains": []string{}, "object": "domains"},
+		Sends:       []string{},
+		Object:      "sync",
+	}
+
+	return http.StatusOK, res, nil
+}
+
+func (s *Server) bitwardenShowCipher(r *bitwardenRequest) (int, any, error) {
+	id, err := bitwardenCipherID(r.Request)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	secret, err := s.show(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	changedAt, err := s.vault.SecretsChangedAt(r.Context())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	c, err := newBitwardenCipher(r.userKey, secret, changedAt[id])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, c, nil
+}
+
+func (s *Server) bitwardenCreateCipher(r *bitwardenRequest) (int, any, error) {
+	secret, err := decodeBitwardenCipher(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	created, err := s.create(r.Context(), secret)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	created.Secret = secret.Secret
+
+	c, err := newBitwardenCipher(r.userKey, created, s.now())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, c, nil
+}
+
+// bitwardenUpdateCipher replaces the name, labels and value of a secret.
+// Only the changed parts are updated, keeping the audit log meaningful.
+func (s *Server) bitwardenUpdateCipher(r *bitwardenRequest) (int, any, error) {
+	id, err := bitwardenCipherID(r.Request)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	secret, err := decodeBitwardenCipher(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	current, err := s.show(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if secret.Secret != current.Secret {
+		if err := s.update(r.Context(), id, secret.Secret); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	newName := ""
+	if secret.Name != current.Name {
+		newName = secret.Name
+	}
+
+	removed := slices.DeleteFunc(slices.Clone(current.Labels), func(l string) bool { return slices.Contains(secret.Labels, l) })
+	added := slices.DeleteFunc(slices.Clone(secret.Labels), func(l string) bool { return slices.Contains(current.Labels, l) })
+
+	if len(newName) > 0 || len(removed) > 0 || len(added) > 0 {
+		if err := s.vault.UpdateSecretMetadata(r.Context(), id, newName, removed, added); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	secret.ID = id
+
+	c, err := newBitwardenCipher(r.userKey, secret, s.now())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, c, nil
+}
+
+func (s *Server) bitwardenDeleteCipher(r *bitwardenRequest) (int, any, error) {
+	id, err := bitwardenCipherID(r.Request)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err := s.delete(r.Context(), id); err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, struct{}{}, nil
+}
+
+type bitwardenCipher struct {
+	ID                  string           `json:"id"`
+	OrganizationID      *string          `json:"organizationId"`
+	FolderID            *string          `json:"folderId"`
+	Type                int              `json:"type"`
+	Name                string           `json:"name"`
+	Notes               *string          `json:"notes"`
+	Login               bitwardenLogin   `json:"login"`
+	Fields              []bitwardenField `json:"fields"`
+	Favorite            bool             `json:"favorite"`
+	Reprompt            int              `json:"reprompt"`
+	Edit                bool             `json:"edit"`
+	ViewPassword        bool             `json:"viewPassword"`
+	OrganizationUseTotp bool             `json:"organizationUseTotp"`
+	RevisionDate        time.Time        `json:"revisionDate"`
+	CreationDate        time.Time        `json:"creationDate"`
+	DeletedDate         *time.Time       `json:"deletedDate"`
+	CollectionIDs       []string         `json:"collectionIds"`
+	Key                 *string          `json:"key"`
+	Object              string           `json:"object"`
+}
+
+type bitwardenLogin struct {
+	Username *string `json:"username"`
+	Password *string `json:"password"`
+	URIs     []any   `json:"uris"`
+	TOTP     *string `json:"totp"`
+}
+
+type bitwardenField struct {
+	Type  int     `json:"type"`
+	Name  *string `json:"name"`
+	Value *string `json:"value"`
+}
+
+// bitwardenCipherRequest holds the fields of a cipher sent by clients vlt uses.
+type bitwardenCipherRequest struct {
+	Type   int              `json:"type"`
+	Name   string           `json:"name"`
+	Key    *string          `json:"key"`
+	Login  *bitwardenLogin  `json:"login"`
+	Fields []bitwardenField `json:"fields"`
+}
+
+// newBitwardenCipher returns the given secret as a login cipher encrypted by key.
+func newBitwardenCipher(key bitwarden.Key, secret Secret, changedAt time.Time) (bitwardenCipher, error) {
+	name, err := key.EncryptString(secret.Name)
+	if err != nil {
+		return bitwardenCipher{}, err
+	}
+
+	password, err := key.EncryptString(secret.Secret)
+	if err != nil {
+		return bitwardenCipher{}, err
+	}
+
+	fields := []bitwardenField{}
+
+	if len(secret.Labels) > 0 {
+		fieldName, err := key.EncryptString(bitwardenLabelsField)
+		if err != nil {
+			return bitwardenCipher{}, err
+		}
+
+		fieldValue, err := key.EncryptString(strings.Join(secret.Labels, ","))
+		if err != nil {
+			return bitwardenCipher{}, err
+		}
+
+		fields = append(fields, bitwardenField{Name: &fieldName, Value: &fieldValue})
+	}
+
+	c := bitwardenCipher{
+		ID:            newBitwardenCipherID(secret.ID),
+		Type:          bitwardenCipherLogin,
+		Name:          name,
+		Login:         bitwardenLogin{Password: &password},
+		Fields:        fields,
+		Edit:          true,
+		ViewPassword:  true,
+		RevisionDate:  changedAt.UTC(),
+		CreationDate:  changedAt.UTC(),
+		CollectionIDs: []string{},
+		Object:        "cipherDetails",
+	}
+
+	return c, nil
+}
+
+// decodeBitwardenCipher decodes and decrypts the login cipher in the request body.
+func decodeBitwardenCipher(r *bitwardenRequest) (Secret, error) {
+	var req bitwardenCipherRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return Secret{}, &badRequestError{err}
+	}
+
+	if req.Type != bitwardenCipherLogin {
+		return Secret{}, &badRequestError{errors.New("only login items are supported")}
+	}
+
+	key := r.userKey
+
+	// ciphers may be encrypted by their own key, encrypted by the user key.
+	if req.Key != nil && len(*req.Key) > 0 {
+		b, err := r.userKey.Decrypt(*req.Key)
+		if err != nil {
+			return Secret{}, &badRequestError{fmt.Errorf("cipher key: %w", err)}
+		}
+
+		if key, err = bitwarden.NewKey(b); err != nil {
+			return Secret{}, &badRequestError{fmt.Errorf("cipher key: %w", err)}
+		}
+	}
+
+	decrypt := func(s *string) (string, error) {
+		if s == nil || len(*s) == 0 {
+			return "", nil
+		}
+
+		plaintext, err := key.DecryptString(*s)
+		if err != nil {
+			return "", &badRequestError{err}
+		}
+
+		return plaintext, nil
+	}
+
+	var (
+		secret Secret
+		err    error
+	)
+
+	if secret.Name, err = decrypt(&req.Name); err != nil {
+		return Secret{}, err
+	}
+
+	if req.Login != nil {
+		if secret.Secret, err = decrypt(req.Login.Password); err != nil {
+			return Secret{}, err
+		}
+	}
+
+	for _, f := range req.Fields {
+		name, err := decrypt(f.Name)
+		if err != nil {
+			return Secret{}, err
+		}
+
+		if name != bitwardenLabelsField {
+			continue
+		}
+
+		value, err := decrypt(f.Value)
+		if err != nil {
+			return Secret{}, err
+		}
+
+		for l := range strings.SplitSeq(value, ",") {
+			if l = strings.TrimSpace(l); len(l) > 0 && !slices.Contains(secret.Labels, l) {
+				secret.Labels = append(secret.Labels, l)
+			}
+		}
+	}
+
+	return secret, nil
+}
+
+// newBitwardenCipherID returns the GUID identifying the secret with the given id.
+func newBitwardenCipherID(id int) string {
+	return fmt.Sprintf("00000000-0000-0000-0000-%012x", id)
+}
+
+func bitwardenCipherID(r *http.Request) (int, error) {
+	hexID, ok := strings.CutPrefix(r.PathValue("id"), "00000000-0000-0000-0000-")
+	if !ok {
+		return 0, &badRequestError{errors.New("invalid cipher id")}
+	}
+
+	id, err := strconv.ParseInt(hexID, 16, 64)
+	if err != nil || id <= 0 {
+		return 0, &badRequestError{errors.New("invalid cipher id")}
+	}
+
+	return int(id), nil
+}
+
+// bitwardenUserID returns the GUID identifying the account, derived from its email.
+func bitwardenUserID(a bitwarden.Account) string {
+	return guid(sha256.Sum256([]byte("vlt-bitwarden-user:" + a.Email)))
+}
+
+// bitwardenSecurityStamp returns the account security stamp, which changes
+// when the account is reset, invalidating issued access tokens.
+func bitwardenSecurityStamp(a bitwarden.Account) string {
+	return guid(sha256.Sum256(a.PasswordSalt))
+}
+
+func guid(sum [sha256.Size]byte) string {
+	h := hex.EncodeToString(sum[:16])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+func bitwardenActor(device string) string {
+	return "bitwarden:" + device
+}
+
+// writeBitwardenError writes an error in the format Bitwarden clients display, see [writeError].
+func writeBitwardenError(w http.ResponseWriter, code int, err error) {
+	msg := err.Error()
+
+	// avoid leaking internal details, e.g., sql errors.
+	if code == http.StatusInternalServerError {
+		msg = http.StatusText(code)
+	}
+
+	if code == http.StatusNotFound {
+		msg = "secret not found"
+	}
+
+	writeJSON(w, code, map[string]any{
+		"message":          msg,
+		"validationErrors": nil,
+		"object":           "error",
+	})
+}
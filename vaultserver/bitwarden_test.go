@@ -0,0 +1,233 @@
+package vaultserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/bitwarden"
+	"github.com/ladzaretti/vlt-cli/vault"
+)
+
+func TestServer_Bitwarden(t *testing.T) {
+	const (
+		email      = "me@example.com"
+		password   = "client-password"
+		iterations = 1000
+	)
+
+	v, err := vault.New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	if _, err := v.InsertNewSecret(t.Context(), "github", "gh-secret", []string{"dev", "personal"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.SetBitwardenAccount(t.Context(), email, password, iterations); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(New(v, WithBitwarden()))
+	defer srv.Close()
+
+	do := func(method, path, token, contentType, body string) (int, []byte) {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(t.Context(), method, srv.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", contentType)
+
+		if len(token) > 0 {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = res.Body.Close() }() //nolint:wsl
+
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return res.StatusCode, b
+	}
+
+	var prelogin bitwardenPreloginResponse
+
+	code, b := do(http.MethodPost, "/identity/accounts/prelogin", "", "application/json", `{"email":"Me@Example.com"}`)
+	if code != http.StatusOK || json.Unmarshal(b, &prelogin) != nil || prelogin.KDFIterations != iterations {
+		t.Fatalf("prelogin: got %d %s, want %d iterations", code, b, iterations)
+	}
+
+	// log in the way clients do.
+	masterKey, err := bitwarden.MasterKey(password, email, prelogin.KDFIterations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := bitwarden.MasterPasswordHash(masterKey, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := url.Values{"grant_type": {"password"}, "username": {email}, "password": {"wrong"}, "deviceIdentifier": {"test-device"}}
+
+	if code, _ := do(http.MethodPost, "/identity/connect/token", "", "application/x-www-form-urlencoded", form.Encode()); code != http.StatusBadRequest {
+		t.Errorf("token using wrong password: got %d, want %d", code, http.StatusBadRequest)
+	}
+
+	form.Set("password", hash)
+
+	var token bitwardenTokenResponse
+
+	code, b = do(http.MethodPost, "/identity/connect/token", "", "application/x-www-form-urlencoded", form.Encode())
+	if code != http.StatusOK || json.Unmarshal(b, &token) != nil {
+		t.Fatalf("token: got %d %s, want %d", code, b, http.StatusOK)
+	}
+
+	stretched, err := bitwarden.StretchMasterKey(masterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = stretched.Decrypt(token.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userKey, err := bitwarden.NewKey(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code, _ := do(http.MethodGet, "/api/sync", token.AccessToken+"x", "", ""); code != http.StatusUnauthorized {
+		t.Errorf("sync using invalid token: got %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	var sync bitwardenSyncResponse
+
+	code, b = do(http.MethodGet, "/api/sync", token.AccessToken, "", "")
+	if code != http.StatusOK || json.Unmarshal(b, &sync) != nil || len(sync.Ciphers) != 1 {
+		t.Fatalf("sync: got %d %s, want one cipher", code, b)
+	}
+
+	got := decryptCipher(t, userKey, sync.Ciphers[0])
+	if want := (Secret{Name: "github", Labels: []string{"dev", "personal"}, Secret: "gh-secret"}); !equalSecrets(got, want) {
+		t.Errorf("sync cipher: got %+v, want %+v", got, want)
+	}
+
+	// clients send back the cipher as is, re-encrypting edited fields.
+	encrypt := func(s string) *string {
+		enc, err := userKey.EncryptString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return &enc
+	}
+
+	cipherRequest := func(name, secret, labels string) string {
+		req := bitwardenCipherRequest{
+			Type:   bitwardenCipherLogin,
+			Name:   *encrypt(name),
+			Login:  &bitwardenLogin{Password: encrypt(secret)},
+			Fields: []bitwardenField{{Name: encrypt("labels"), Value: encrypt(labels)}},
+		}
+
+		b, err := json.Marshal(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(b)
+	}
+
+	var created bitwardenCipher
+
+	code, b = do(http.MethodPost, "/api/ciphers", token.AccessToken, "application/json", cipherRequest("email", "mail-secret", "personal"))
+	if code != http.StatusOK || json.Unmarshal(b, &created) != nil {
+		t.Fatalf("create cipher: got %d %s, want %d", code, b, http.StatusOK)
+	}
+
+	if created.ID != newBitwardenCipherID(2) {
+		t.Errorf("created cipher id: got %s, want %s", created.ID, newBitwardenCipherID(2))
+	}
+
+	code, b = do(http.MethodPut, "/api/ciphers/"+created.ID, token.AccessToken, "application/json", cipherRequest("mail", "new-secret", "personal, work"))
+	if code != http.StatusOK {
+		t.Fatalf("update cipher: got %d %s, want %d", code, b, http.StatusOK)
+	}
+
+	var shown bitwardenCipher
+
+	code, b = do(http.MethodGet, "/api/ciphers/"+created.ID, token.AccessToken, "", "")
+	if code != http.StatusOK || json.Unmarshal(b, &shown) != nil {
+		t.Fatalf("show cipher: got %d %s, want %d", code, b, http.StatusOK)
+	}
+
+	got = decryptCipher(t, userKey, shown)
+	if want := (Secret{Name: "mail", Labels: []string{"personal", "work"}, Secret: "new-secret"}); !equalSecrets(got, want) {
+		t.Errorf("updated cipher: got %+v, want %+v", got, want)
+	}
+
+	if code, b := do(http.MethodDelete, "/api/ciphers/"+created.ID, token.AccessToken, "", ""); code != http.StatusOK {
+		t.Fatalf("delete cipher: got %d %s, want %d", code, b, http.StatusOK)
+	}
+
+	if code, _ := do(http.MethodGet, "/api/ciphers/"+created.ID, token.AccessToken, "", ""); code != http.StatusNotFound {
+		t.Errorf("show deleted cipher: got %d, want %d", code, http.StatusNotFound)
+	}
+
+	refresh := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {token.RefreshToken}}.Encode()
+
+	if code, b := do(http.MethodPost, "/identity/connect/token", "", "application/x-www-form-urlencoded", refresh); code != http.StatusOK {
+		t.Errorf("refresh: got %d %s, want %d", code, b, http.StatusOK)
+	}
+
+	// refresh tokens are single use.
+	if code, _ := do(http.MethodPost, "/identity/connect/token", "", "application/x-www-form-urlencoded", refresh); code != http.StatusBadRequest {
+		t.Errorf("reused refresh token: got %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func decryptCipher(t *testing.T, key bitwarden.Key, c bitwardenCipher) Secret {
+	t.Helper()
+
+	decrypt := func(s string) string {
+		plaintext, err := key.DecryptString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return plaintext
+	}
+
+	secret := Secret{Name: decrypt(c.Name), Secret: decrypt(*c.Login.Password)}
+
+	for _, f := range c.Fields {
+		if decrypt(*f.Name) == "labels" {
+			secret.Labels = strings.Split(decrypt(*f.Value), ",")
+		}
+	}
+
+	return secret
+}
+
+func equalSecrets(a, b Secret) bool {
+	return a.Name == b.Name && a.Secret == b.Secret && slices.Equal(a.Labels, b.Labels)
+}
@@ -0,0 +1,365 @@
+package vaultserver
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+	"github.com/ladzaretti/vlt-cli/vaultserver/proto/vaultpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// defaultWatchInterval is the interval the audit log is polled at for watched changes.
+	defaultWatchInterval = time.Second
+
+	// ErrorCodeKey is the trailer metadata key of the [vaulterrors.Code] of failed calls.
+	ErrorCodeKey = "vlt-error-code"
+)
+
+// GRPCServer returns a gRPC server serving the [vaultpb.VaultServer] service.
+//
+// Calls are authenticated using the 'authorization' metadata, and are
+// subject to the same rate limits and bans as REST requests. Like REST
+// requests, unary calls are serialized, and the vault is synced to disk
+// after each one. Failed calls carry the code of the error in the
+// [ErrorCodeKey] trailer.
+func (s *Server) GRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	)
+
+	g := grpc.NewServer(opts...)
+	vaultpb.RegisterVaultServer(g, &grpcService{s: s})
+
+	return g
+}
+
+// unaryInterceptor authenticates unary calls, and runs them with the vault locked.
+func (s *Server) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (_ any, retErr error) {
+	defer func() { s.metrics.grpcRequest(path.Base(info.FullMethod), status.Code(retErr)) }()
+
+	if rej := s.admit(peerIP(ctx), s.now()); rej != nil {
+		return nil, rejectionStatus(ctx, rej)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, rej := s.authorize(ctx, peerIP(ctx), authorization(ctx))
+	if rej != nil {
+		return nil, rejectionStatus(ctx, rej)
+	}
+
+	res, err := handler(vault.WithPrincipal(ctx, p), req)
+
+	// sync even on failure, audit entries of reads are recorded regardless.
+	if syncErr := s.vault.Sync(context.WithoutCancel(ctx)); syncErr != nil {
+		return nil, grpcError(ctx, syncErr)
+	}
+
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return res, nil
+}
+
+// streamInterceptor authenticates streaming calls. Unlike unary calls,
+// streaming calls lock the vault only while accessing it.
+func (s *Server) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (retErr error) {
+	defer func() { s.metrics.grpcRequest(path.Base(info.FullMethod), status.Code(retErr)) }()
+
+	ctx := ss.Context()
+
+	if rej := s.admit(peerIP(ctx), s.now()); rej != nil {
+		return rejectionStatus(ctx, rej)
+	}
+
+	s.mu.Lock()
+	p, rej := s.authorize(ctx, peerIP(ctx), authorization(ctx))
+	s.mu.Unlock()
+
+	if rej != nil {
+		return rejectionStatus(ctx, rej)
+	}
+
+	stream := &principalStream{ServerStream: ss, ctx: context.WithValue(ctx, principalKey{}, p)}
+
+	if err := handler(srv, stream); err != nil {
+		return grpcError(ctx, err)
+	}
+
+	return nil
+}
+
+// principalKey holds the principal of an authenticated streaming call.
+//
+// It is not set using [vault.WithPrincipal], as streaming calls may access
+// vault-wide data, e.g., the audit log, filtering it for the principal.
+type principalKey struct{}
+
+// principalStream carries the principal of an authenticated streaming call in its context.
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context //nolint:containedctx // overrides the stream context.
+}
+
+func (s *principalStream) Context() context.Context { return s.ctx }
+
+// peerIP returns the IP address of the client of the call, see [clientIP].
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+// authorization returns the authorization metadata of the call.
+func authorization(ctx context.Context) string {
+	if v := metadata.ValueFromIncomingContext(ctx, "authorization"); len(v) > 0 {
+		return v[0]
+	}
+
+	return ""
+}
+
+// rejectionStatus returns the gRPC status of a rejected call.
+func rejectionStatus(ctx context.Context, rej *rejection) error {
+	if rej.reason != rejectAuth {
+		setErrorCode(ctx, vaulterrors.CodeRateLimited)
+		return status.Error(codes.ResourceExhausted, rej.err.Error())
+	}
+
+	if errors.Is(rej.err, errMissingToken) {
+		setErrorCode(ctx, vaulterrors.CodeUnauthenticated)
+		return status.Error(codes.Unauthenticated, rej.err.Error())
+	}
+
+	return grpcError(ctx, rej.err)
+}
+
+// grpcCodes maps the gRPC status codes returned by the service
+// to the matching HTTP status codes, see [errorCode].
+var grpcCodes = map[codes.Code]int{
+	codes.InvalidArgument:  http.StatusBadRequest,
+	codes.Unauthenticated:  http.StatusUnauthorized,
+	codes.PermissionDenied: http.StatusForbidden,
+	codes.NotFound:         http.StatusNotFound,
+}
+
+// grpcError maps vault errors to gRPC status errors, see [statusCode],
+// setting the [ErrorCodeKey] trailer of the call.
+func grpcError(ctx context.Context, err error) error {
+	if st, ok := status.FromError(err); ok {
+		setErrorCode(ctx, errorCode(cmp.Or(grpcCodes[st.Code()], http.StatusInternalServerError), err))
+		return err
+	}
+
+	setErrorCode(ctx, errorCode(statusCode(err), err))
+
+	code := codes.Internal
+
+	switch statusCode(err) {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		return status.Error(codes.NotFound, "secret not found")
+	}
+
+	// avoid leaking internal details, e.g., sql errors.
+	if code == codes.Internal {
+		return status.Error(code, "internal error")
+	}
+
+	return status.Error(code, err.Error())
+}
+
+// setErrorCode sets the [ErrorCodeKey] trailer of the call.
+func setErrorCode(ctx context.Context, code vaulterrors.Code) {
+	_ = grpc.SetTrailer(ctx, metadata.Pairs(ErrorCodeKey, string(code)))
+}
+
+// grpcService implements the gRPC API over the server operations.
+type grpcService struct {
+	vaultpb.UnimplementedVaultServer
+	s *Server
+}
+
+var _ vaultpb.VaultServer = &grpcService{}
+
+func (g *grpcService) Search(ctx context.Context, req *vaultpb.SearchRequest) (*vaultpb.SearchResponse, error) {
+	secrets, err := g.s.search(ctx, req.GetQuery(), req.GetName(), req.GetLabels())
+	if err != nil {
+		return nil, err
+	}
+
+	res := &vaultpb.SearchResponse{Secrets: make([]*vaultpb.Secret, 0, len(secrets))}
+	for _, secret := range secrets {
+		res.Secrets = append(res.Secrets, toProto(secret))
+	}
+
+	return res, nil
+}
+
+func (g *grpcService) Get(ctx context.Context, req *vaultpb.GetRequest) (*vaultpb.Secret, error) {
+	id, err := protoID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := g.s.show(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toProto(secret), nil
+}
+
+func (g *grpcService) Put(ctx context.Context, req *vaultpb.PutRequest) (*vaultpb.Secret, error) {
+	secret, err := g.s.put(ctx, Secret{ID: int(req.GetId()), Name: req.GetName(), Labels: req.GetLabels(), Secret: req.GetSecret()})
+	if err != nil {
+		return nil, err
+	}
+
+	return toProto(secret), nil
+}
+
+func (g *grpcService) Delete(ctx context.Context, req *vaultpb.DeleteRequest) (*emptypb.Empty, error) {
+	id, err := protoID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.s.delete(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// Totp returns the current code of a TOTP secret, see [Server.totp].
+func (g *grpcService) Totp(ctx context.Context, req *vaultpb.TotpRequest) (*vaultpb.TotpCode, error) {
+	id, err := protoID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := g.s.totp(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vaultpb.TotpCode{Code: code.Code, RemainingSeconds: int64(code.RemainingSeconds)}, nil
+}
+
+// Watch streams the changes recorded in the audit log after the call,
+// to secrets within the scope of the calling token. Response headers are
+// sent once the watch started.
+func (g *grpcService) Watch(_ *vaultpb.WatchRequest, stream grpc.ServerStreamingServer[vaultpb.Event]) error {
+	p, ok := stream.Context().Value(principalKey{}).(vault.Principal)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "unauthenticated stream")
+	}
+
+	w, err := g.s.newWatcher(stream.Context(), p)
+	if err != nil {
+		return err
+	}
+
+	// headers mark the start of the watch, for clients to sync on.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(g.s.watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		events, err := w.poll(stream.Context())
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range events {
+			typ, _ := eventType(entry.Operation)
+
+			e := &vaultpb.Event{
+				Type:       typ,
+				SecretId:   int64(entry.SecretID),
+				SecretName: entry.SecretName,
+				Actor:      entry.Actor,
+				Time:       timestamppb.New(entry.CreatedAt),
+			}
+
+			if err := stream.Send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// eventType returns the event type of the given audit log operation, if any.
+func eventType(op vaultdb.Operation) (vaultpb.Event_Type, bool) {
+	switch op { //nolint:exhaustive // read operations are not events.
+	case vaultdb.OpInsert:
+		return vaultpb.Event_TYPE_ADD, true
+	case vaultdb.OpUpdateMetadata:
+		return vaultpb.Event_TYPE_UPDATE, true
+	case vaultdb.OpUpdate:
+		return vaultpb.Event_TYPE_ROTATE, true
+	case vaultdb.OpDelete:
+		return vaultpb.Event_TYPE_DELETE, true
+	default:
+		return vaultpb.Event_TYPE_UNSPECIFIED, false
+	}
+}
+
+func toProto(s Secret) *vaultpb.Secret {
+	return &vaultpb.Secret{
+		Id:     int64(s.ID),
+		Name:   s.Name,
+		Labels: s.Labels,
+		Secret: s.Secret,
+	}
+}
+
+func protoID(id int64) (int, error) {
+	if id <= 0 {
+		return 0, &badRequestError{errors.New("invalid secret id")}
+	}
+
+	return int(id), nil
+}
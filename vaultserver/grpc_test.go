@@ -0,0 +1,182 @@
+package vaultserver
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+	"github.com/ladzaretti/vlt-cli/vaultserver/proto/vaultpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer_GRPC(t *testing.T) {
+	v, err := vault.New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	if _, err := v.InsertNewSecret(t.Context(), "email", "personal-secret", []string{"personal"}); err != nil {
+		t.Fatal(err)
+	}
+
+	token, _, err := v.IssueAPIToken(t.Context(), "ci", vault.TokenOptions{Scope: []string{"ci/*"}, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(v)
+	s.watchInterval = 10 * time.Millisecond
+
+	l, err := (&net.ListenConfig{}).Listen(t.Context(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := s.GRPCServer()
+	defer g.Stop()
+
+	go func() { _ = g.Serve(l) }()
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }() //nolint:wsl
+
+	client := vaultpb.NewVaultClient(conn)
+	ctx := metadata.AppendToOutgoingContext(t.Context(), "authorization", "Bearer "+token)
+
+	if _, err := client.Search(t.Context(), &vaultpb.SearchRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Search(no token): got %v, want %v", err, codes.Unauthenticated)
+	}
+
+	watch, err := client.Watch(ctx, &vaultpb.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := watch.Header(); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := client.Put(ctx, &vaultpb.PutRequest{Name: "deploy", Labels: []string{"ci/deploy"}, Secret: "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Put(ctx, &vaultpb.PutRequest{Id: created.GetId(), Secret: "s2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := client.Get(ctx, &vaultpb.GetRequest{Id: created.GetId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.GetSecret() != "s2" {
+		t.Errorf("Get(): got secret %q, want %q", got.GetSecret(), "s2")
+	}
+
+	res, err := client.Search(ctx, &vaultpb.SearchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(res.GetSecrets()); n != 1 {
+		t.Errorf("Search(): got %d secrets, want 1 (in scope)", n)
+	}
+
+	var trailer metadata.MD
+
+	if _, err := client.Get(ctx, &vaultpb.GetRequest{Id: 1}, grpc.Trailer(&trailer)); status.Code(err) != codes.NotFound {
+		t.Errorf("Get(out of scope): got %v, want %v", err, codes.NotFound)
+	}
+
+	if got := trailer.Get(ErrorCodeKey); len(got) != 1 || got[0] != string(vaulterrors.CodeSecretNotFound) {
+		t.Errorf("Get(out of scope): got error code %v, want %s", got, vaulterrors.CodeSecretNotFound)
+	}
+
+	if _, err := client.Put(ctx, &vaultpb.PutRequest{Name: "x", Labels: []string{"other"}, Secret: "s"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Put(out of scope): got %v, want %v", err, codes.PermissionDenied)
+	}
+
+	if _, err := client.Totp(ctx, &vaultpb.TotpRequest{Id: created.GetId()}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Totp(plain secret): got %v, want %v", err, codes.InvalidArgument)
+	}
+
+	if _, err := client.Delete(ctx, &vaultpb.DeleteRequest{Id: created.GetId()}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []vaultpb.Event_Type{vaultpb.Event_TYPE_ADD, vaultpb.Event_TYPE_ROTATE, vaultpb.Event_TYPE_DELETE}
+	for _, typ := range want {
+		e, err := watch.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if e.GetType() != typ || e.GetSecretId() != created.GetId() {
+			t.Errorf("Watch(): got %s event of secret %d, want %s of %d", e.GetType(), e.GetSecretId(), typ, created.GetId())
+		}
+	}
+}
+
+func TestServer_GRPC_Totp(t *testing.T) {
+	v, err := vault.New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	id, err := v.InsertTOTPSecret(t.Context(), "github", "JBSWY3DPEHPK3PXP", []string{"ci/otp"}, vaultdb.SecretMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, _, err := v.IssueAPIToken(t.Context(), "ci", vault.TokenOptions{Scope: []string{"ci/*"}, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := (&net.ListenConfig{}).Listen(t.Context(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := New(v).GRPCServer()
+	defer g.Stop()
+
+	go func() { _ = g.Serve(l) }()
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }() //nolint:wsl
+
+	client := vaultpb.NewVaultClient(conn)
+	ctx := metadata.AppendToOutgoingContext(t.Context(), "authorization", "Bearer "+token)
+
+	got, err := client.Totp(ctx, &vaultpb.TotpRequest{Id: int64(id)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.GetCode()) != 6 || got.GetRemainingSeconds() < 1 || got.GetRemainingSeconds() > 30 {
+		t.Errorf("Totp(): got %+v", got)
+	}
+
+	if _, err := client.Totp(ctx, &vaultpb.TotpRequest{Id: int64(id) + 1}); status.Code(err) != codes.NotFound {
+		t.Errorf("Totp(missing): got %v, want %v", err, codes.NotFound)
+	}
+}
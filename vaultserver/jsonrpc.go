@@ -0,0 +1,264 @@
+package vaultserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// JSON-RPC 2.0 error codes, see https://www.jsonrpc.org/specification#error_object.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+
+	// server defined codes, mapped from vault errors.
+	rpcUnauthorized = -32001
+	rpcForbidden    = -32003
+	rpcNotFound     = -32004
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"` // ID is nil for notifications.
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// rpcMethod handles a JSON-RPC call, returning its non-nil result.
+type rpcMethod func(ctx context.Context, params json.RawMessage) (any, error)
+
+// ServeJSONRPC serves JSON-RPC 2.0 requests read from r, one per line,
+// writing a response line to w for each request that is not a notification,
+// until r is exhausted or ctx is done.
+//
+// Unlike the REST and gRPC APIs, calls are not authenticated: they are
+// performed on behalf of the party that opened the vault, which is expected
+// to have spawned the caller, e.g., an editor plugin. Like REST requests,
+// the vault is synced to disk after each call.
+//
+// Methods mirror the gRPC API, taking named params:
+//
+//	search  {"query", "name", "labels"}            ->  [{"id", "name", "labels"}, ...]
+//	get     {"id"}                                 ->  {"id", "name", "labels", "secret"}
+//	put     {"id", "name", "labels", "secret"}     ->  {"id", "name", "labels"}
+//	delete  {"id"}                                 ->  {}
+//
+// put creates a secret if id is zero, and replaces the value of the secret
+// with the given id otherwise.
+//
+// Batch requests are not supported.
+func (s *Server) ServeJSONRPC(ctx context.Context, r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxBodySize)
+
+	enc := json.NewEncoder(w)
+
+	for sc.Scan() {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		res := s.handleRPC(ctx, line)
+		if res == nil {
+			continue
+		}
+
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+	}
+
+	return sc.Err()
+}
+
+// handleRPC handles a single JSON-RPC message, returning nil for notifications.
+func (s *Server) handleRPC(ctx context.Context, msg []byte) *rpcResponse {
+	if msg[0] == '[' {
+		return rpcErrorResponse(nil, rpcInvalidRequest, "batch requests are not supported")
+	}
+
+	var req rpcRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return rpcErrorResponse(nil, rpcParseError, "parse error")
+	}
+
+	if req.JSONRPC != "2.0" || len(req.Method) == 0 {
+		return rpcErrorResponse(req.ID, rpcInvalidRequest, "invalid request")
+	}
+
+	m, ok := s.rpcMethod(req.Method)
+	if !ok {
+		if req.ID == nil {
+			return nil
+		}
+
+		return rpcErrorResponse(req.ID, rpcMethodNotFound, "method not found: "+req.Method)
+	}
+
+	res, err := s.callRPC(ctx, m, req.Params)
+
+	if req.ID == nil {
+		return nil
+	}
+
+	if err != nil {
+		code, msg := rpcErrorCode(err)
+		return rpcErrorResponse(req.ID, code, msg)
+	}
+
+	return &rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: res}
+}
+
+// callRPC calls m with the vault locked, and syncs the vault.
+func (s *Server) callRPC(ctx context.Context, m rpcMethod, params json.RawMessage) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := m(ctx, params)
+
+	// sync even on failure, audit entries of reads are recorded regardless.
+	if syncErr := s.vault.Sync(context.WithoutCancel(ctx)); syncErr != nil {
+		return nil, syncErr
+	}
+
+	return res, err
+}
+
+func (s *Server) rpcMethod(name string) (rpcMethod, bool) {
+	switch name {
+	case "search":
+		return s.rpcSearch, true
+	case "get":
+		return s.rpcGet, true
+	case "put":
+		return s.rpcPut, true
+	case "delete":
+		return s.rpcDelete, true
+	default:
+		return nil, false
+	}
+}
+
+type rpcSearchParams struct {
+	Query  string   `json:"query"`
+	Name   string   `json:"name"`
+	Labels []string `json:"labels"`
+}
+
+type rpcIDParams struct {
+	ID int `json:"id"`
+}
+
+func (s *Server) rpcSearch(ctx context.Context, params json.RawMessage) (any, error) {
+	var p rpcSearchParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	return s.search(ctx, p.Query, p.Name, p.Labels)
+}
+
+func (s *Server) rpcGet(ctx context.Context, params json.RawMessage) (any, error) {
+	id, err := decodeID(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.show(ctx, id)
+}
+
+func (s *Server) rpcPut(ctx context.Context, params json.RawMessage) (any, error) {
+	var p Secret
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	return s.put(ctx, p)
+}
+
+func (s *Server) rpcDelete(ctx context.Context, params json.RawMessage) (any, error) {
+	id, err := decodeID(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.delete(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return struct{}{}, nil
+}
+
+// decodeParams decodes named params into v. Missing params are left zero.
+func decodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return &badRequestError{err}
+	}
+
+	return nil
+}
+
+func decodeID(params json.RawMessage) (int, error) {
+	var p rpcIDParams
+	if err := decodeParams(params, &p); err != nil {
+		return 0, err
+	}
+
+	if p.ID <= 0 {
+		return 0, &badRequestError{errors.New("invalid secret id")}
+	}
+
+	return p.ID, nil
+}
+
+// rpcErrorCode maps vault errors to JSON-RPC error codes and messages, see [statusCode].
+func rpcErrorCode(err error) (int, string) {
+	switch statusCode(err) {
+	case http.StatusBadRequest:
+		return rpcInvalidParams, err.Error()
+	case http.StatusUnauthorized:
+		return rpcUnauthorized, err.Error()
+	case http.StatusForbidden:
+		return rpcForbidden, err.Error()
+	case http.StatusNotFound:
+		return rpcNotFound, "secret not found"
+	default:
+		// avoid leaking internal details, e.g., sql errors.
+		return rpcInternalError, "internal error"
+	}
+}
+
+func rpcErrorResponse(id json.RawMessage, code int, msg string) *rpcResponse {
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: msg}}
+}
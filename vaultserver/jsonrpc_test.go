@@ -0,0 +1,68 @@
+package vaultserver
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+)
+
+func TestServer_ServeJSONRPC(t *testing.T) {
+	v, err := vault.New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	if _, err := v.InsertNewSecret(t.Context(), "deploy", "ci-secret", []string{"ci/deploy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		request string
+		want    string
+	}{
+		{"search", `{"jsonrpc":"2.0","id":1,"method":"search"}`, `{"jsonrpc":"2.0","id":1,"result":[{"id":1,"name":"deploy","labels":["ci/deploy"]}]}`},
+		{"get", `{"jsonrpc":"2.0","id":"a","method":"get","params":{"id":1}}`, `{"jsonrpc":"2.0","id":"a","result":{"id":1,"name":"deploy","labels":["ci/deploy"],"secret":"ci-secret"}}`},
+		{"put create", `{"jsonrpc":"2.0","id":2,"method":"put","params":{"name":"email","secret":"s","labels":["personal"]}}`, `{"jsonrpc":"2.0","id":2,"result":{"id":2,"name":"email","labels":["personal"]}}`},
+		{"put update", `{"jsonrpc":"2.0","id":3,"method":"put","params":{"id":2,"secret":"new"}}`, `{"jsonrpc":"2.0","id":3,"result":{"id":2,"name":"email","labels":["personal"]}}`},
+		{"search by label", `{"jsonrpc":"2.0","id":4,"method":"search","params":{"labels":["personal"]}}`, `{"jsonrpc":"2.0","id":4,"result":[{"id":2,"name":"email","labels":["personal"]}]}`},
+		{"notification", `{"jsonrpc":"2.0","method":"delete","params":{"id":2}}`, ``},
+		{"get deleted", `{"jsonrpc":"2.0","id":5,"method":"get","params":{"id":2}}`, `{"jsonrpc":"2.0","id":5,"error":{"code":-32004,"message":"secret not found"}}`},
+		{"invalid params", `{"jsonrpc":"2.0","id":6,"method":"get","params":{"id":"1"}}`, `{"jsonrpc":"2.0","id":6,"error":{"code":-32602,`},
+		{"unknown param", `{"jsonrpc":"2.0","id":7,"method":"search","params":{"q":"x"}}`, `{"jsonrpc":"2.0","id":7,"error":{"code":-32602,`},
+		{"method not found", `{"jsonrpc":"2.0","id":8,"method":"totp"}`, `{"jsonrpc":"2.0","id":8,"error":{"code":-32601,"message":"method not found: totp"}}`},
+		{"invalid request", `{"id":9,"method":"search"}`, `{"jsonrpc":"2.0","id":9,"error":{"code":-32600,"message":"invalid request"}}`},
+		{"parse error", `{"jsonrpc"`, `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`},
+		{"batch", `[{"jsonrpc":"2.0","id":10,"method":"search"}]`, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,`},
+	}
+
+	s := New(v)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			if err := s.ServeJSONRPC(t.Context(), strings.NewReader(tt.request+"\n"), &out); err != nil {
+				t.Fatalf("serve: %v", err)
+			}
+
+			got := strings.TrimSpace(out.String())
+
+			if len(tt.want) == 0 {
+				if len(got) > 0 {
+					t.Errorf("got response %s, want none", got)
+				}
+
+				return
+			}
+
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("got response %s, want prefix %s", got, tt.want)
+			}
+		})
+	}
+}
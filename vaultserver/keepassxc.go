@@ -0,0 +1,501 @@
+package vaultserver
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ladzaretti/vlt-cli/keepassxc"
+	"github.com/ladzaretti/vlt-cli/urlmatch"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// keepassxcRequest is a native message sent by a KeePassXC-Browser extension.
+// Apart from key exchange, the action parameters are encrypted in Message.
+type keepassxcRequest struct {
+	Action    string `json:"action"`
+	Message   string `json:"message"`
+	Nonce     string `json:"nonce"`
+	ClientID  string `json:"clientID"`
+	PublicKey string `json:"publicKey"`
+}
+
+// keepassxcMessage holds the decrypted parameters of the actions vlt supports.
+type keepassxcMessage struct {
+	Action   string         `json:"action"`
+	Key      string         `json:"key"`
+	IDKey    string         `json:"idKey"`
+	ID       string         `json:"id"`
+	URL      string         `json:"url"`
+	Keys     []keepassxcKey `json:"keys"`
+	Login    string         `json:"login"`
+	Password string         `json:"password"`
+	UUID     string         `json:"uuid"`
+}
+
+type keepassxcKey struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+type keepassxcEntry struct {
+	Login        string `json:"login"`
+	Name         string `json:"name"`
+	Password     string `json:"password"`
+	UUID         string `json:"uuid"`
+	Group        string `json:"group"`
+	TOTP         string `json:"totp"`
+	Expired      string `json:"expired"`
+	StringFields []any  `json:"stringFields"`
+}
+
+// keepassxcError is a protocol error, reported to the extension.
+type keepassxcError struct {
+	code keepassxc.ErrorCode
+	err  error // err overrides the code message, if set.
+}
+
+func (e *keepassxcError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+
+	return e.code.String()
+}
+
+// keepassxcConn is a connection with a KeePassXC-Browser extension.
+type keepassxcConn struct {
+	s       *Server
+	session *keepassxc.Session
+
+	// verified holds the association ids the extension proved owning during the connection.
+	verified map[string]bool
+}
+
+// ServeKeePassXC serves the KeePassXC-Browser native messaging protocol,
+// reading messages from r and writing responses to w, until r is exhausted
+// or ctx is done.
+//
+// Like JSON-RPC calls, messages are handled on behalf of the party that opened
+// the vault, and the vault is synced to disk after each message. Access to
+// secrets requires an association, which extensions only get while pairing,
+// see [vault.Vault.PairKeePassXC].
+//
+// Secrets are matched to pages by labels holding URLs, as imported from
+// browsers, and listed from the best match to the worst, see [urlmatch].
+// Saved logins are stored as secrets named by the login, and
+// labeled by the page origin.
+//
+// Supported actions are change-public-keys, get-databasehash, associate,
+// test-associate, get-logins, set-login and get-database-groups.
+func (s *Server) ServeKeePassXC(ctx context.Context, r io.Reader, w io.Writer) error {
+	c := &keepassxcConn{s: s, verified: make(map[string]bool)}
+
+	for {
+		msg, err := keepassxc.ReadMessage(r, maxBodySize)
+		if errors.Is(err, io.EOF) || ctx.Err() != nil {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		b, err := json.Marshal(c.handle(ctx, msg))
+		if err != nil {
+			return err
+		}
+
+		if err := keepassxc.WriteMessage(w, b); err != nil {
+			return err
+		}
+	}
+}
+
+// handle handles a single message, returning the response.
+func (c *keepassxcConn) handle(ctx context.Context, msg []byte) map[string]any {
+	var req keepassxcRequest
+	if err := json.Unmarshal(msg, &req); err != nil || len(req.Action) == 0 {
+		return keepassxcErrorResponse("", &keepassxcError{code: keepassxc.ErrorEmptyMessageReceived})
+	}
+
+	if req.Action == "change-public-keys" {
+		return c.changePublicKeys(req)
+	}
+
+	if c.session == nil {
+		return keepassxcErrorResponse(req.Action, &keepassxcError{code: keepassxc.ErrorClientPublicKeyNotReceived})
+	}
+
+	plaintext, err := c.session.Open(req.Message, req.Nonce)
+	if err != nil {
+		return keepassxcErrorResponse(req.Action, &keepassxcError{code: keepassxc.ErrorCannotDecryptMessage})
+	}
+
+	var m keepassxcMessage
+	if err := json.Unmarshal(plaintext, &m); err != nil || m.Action != req.Action {
+		return keepassxcErrorResponse(req.Action, &keepassxcError{code: keepassxc.ErrorIncorrectAction})
+	}
+
+	params, err := c.call(ctx, m)
+	if err != nil {
+		return keepassxcErrorResponse(req.Action, err)
+	}
+
+	return c.response(req.Action, req.Nonce, params)
+}
+
+// call handles the given action with the vault locked, and syncs the vault.
+func (c *keepassxcConn) call(ctx context.Context, m keepassxcMessage) (map[string]any, error) {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+
+	var (
+		params map[string]any
+		err    error
+	)
+
+	switch m.Action {
+	case "get-databasehash":
+		params, err = c.databaseHash()
+	case "associate":
+		params, err = c.associate(ctx, m)
+	case "test-associate":
+		params, err = c.testAssociate(ctx, m)
+	case "get-logins":
+		params, err = c.logins(ctx, m)
+	case "set-login":
+		params, err = c.setLogin(ctx, m)
+	case "get-database-groups":
+		params, err = c.databaseGroups()
+	default:
+		return nil, &keepassxcError{code: keepassxc.ErrorIncorrectAction}
+	}
+
+	if syncErr := c.s.vault.Sync(context.WithoutCancel(ctx)); syncErr != nil {
+		return nil, &keepassxcError{code: keepassxc.ErrorDatabaseNotOpened, err: syncErr}
+	}
+
+	return params, err
+}
+
+// changePublicKeys starts an encrypted session with the extension.
+// The response is not encrypted.
+func (c *keepassxcConn) changePublicKeys(req keepassxcRequest) map[string]any {
+	session, err := keepassxc.NewSession(req.PublicKey)
+	if err != nil {
+		return keepassxcErrorResponse(req.Action, &keepassxcError{code: keepassxc.ErrorClientPublicKeyNotReceived})
+	}
+
+	nonce, err := keepassxc.IncrementNonce(req.Nonce)
+	if err != nil {
+		return keepassxcErrorResponse(req.Action, &keepassxcError{code: keepassxc.ErrorClientPublicKeyNotReceived})
+	}
+
+	c.session = session
+
+	return map[string]any{
+		"action":    req.Action,
+		"version":   keepassxc.Version,
+		"publicKey": session.PublicKey(),
+		"nonce":     nonce,
+		"success":   "true",
+	}
+}
+
+func (c *keepassxcConn) databaseHash() (map[string]any, error) {
+	hash, err := c.s.vault.KeePassXCDatabaseHash()
+	if err != nil {
+		return nil, &keepassxcError{code: keepassxc.ErrorDatabaseHashNotReceived}
+	}
+
+	return map[string]any{"hash": hash}, nil
+}
+
+// associate associates the extension with the vault, if pairing is in progress.
+// Unlike KeePassXC, the user is not prompted, pairing must be started beforehand.
+func (c *keepassxcConn) associate(ctx context.Context, m keepassxcMessage) (map[string]any, error) {
+	if m.Key != c.session.ClientKey() || len(m.IDKey) == 0 {
+		return nil, &keepassxcError{code: keepassxc.ErrorAssociationFailed}
+	}
+
+	id, err := c.s.vault.AssociateKeePassXC(ctx, m.IDKey, c.s.now())
+	if errors.Is(err, vaulterrors.ErrNotPairing) {
+		return nil, &keepassxcError{code: keepassxc.ErrorActionCancelledOrDenied, err: err}
+	}
+
+	if err != nil {
+		return nil, &keepassxcError{code: keepassxc.ErrorAssociationFailed, err: err}
+	}
+
+	c.verified[id] = true
+
+	return c.withHash(map[string]any{"id": id})
+}
+
+func (c *keepassxcConn) testAssociate(ctx context.Context, m keepassxcMessage) (map[string]any, error) {
+	if err := c.verify(ctx, m.ID, m.Key); err != nil {
+		return nil, err
+	}
+
+	return c.withHash(map[string]any{"id": m.ID})
+}
+
+// logins returns the logins of the page with the given URL.
+func (c *keepassxcConn) logins(ctx context.Context, m keepassxcMessage) (map[string]any, error) {
+	var id string
+
+	for _, k := range m.Keys {
+		if err := c.verify(ctx, k.ID, k.Key); err == nil {
+			id = k.ID
+			break
+		}
+	}
+
+	if len(id) == 0 {
+		return nil, &keepassxcError{code: keepassxc.ErrorAssociationFailed}
+	}
+
+	page, err := urlmatch.ParsePage(m.URL)
+	if err != nil {
+		return nil, &keepassxcError{code: keepassxc.ErrorNoURLProvided}
+	}
+
+	ctx = vault.WithPrincipal(ctx, vault.Principal{Actor: keepassxcActor(id)})
+
+	secrets, err := c.s.search(ctx, "", "", nil)
+	if err != nil {
+		return nil, &keepassxcError{code: keepassxc.ErrorActionCancelledOrDenied, err: err}
+	}
+
+	ranks := make(map[int]int, len(secrets)) // secret id to rank
+	for _, secret := range secrets {
+		ranks[secret.ID] = urlmatch.RankAny(secret.Labels, page)
+	}
+
+	secrets = slices.DeleteFunc(secrets, func(s Secret) bool { return ranks[s.ID] == 0 })
+	slices.SortStableFunc(secrets, func(a, b Secret) int { return ranks[b.ID] - ranks[a.ID] })
+
+	if len(secrets) == 0 {
+		return nil, &keepassxcError{code: keepassxc.ErrorNoLoginsFound}
+	}
+
+	ids := make([]int, 0, len(secrets))
+	for _, secret := range secrets {
+		ids = append(ids, secret.ID)
+	}
+
+	metas, err := c.s.vault.SecretMetas(ctx, ids...)
+	if err != nil {
+		return nil, &keepassxcError{code: keepassxc.ErrorActionCancelledOrDenied, err: err}
+	}
+
+	entries := make([]keepassxcEntry, 0, len(secrets))
+
+	for _, secret := range secrets {
+		value, err := c.s.vault.ShowSecret(ctx, secret.ID)
+		if err != nil {
+			return nil, &keepassxcError{code: keepassxc.ErrorActionCancelledOrDenied, err: err}
+		}
+
+		entries = append(entries, keepassxcEntry{
+			Login:        cmp.Or(metas[secret.ID].Username, secret.Name),
+			Name:         secret.Name,
+			Password:     value,
+			UUID:         keepassxcUUID(secret.ID),
+			Group:        "vlt",
+			Expired:      "false",
+			StringFields: []any{},
+		})
+	}
+
+	return c.withHash(map[string]any{"id": id, "count": len(entries), "entries": entries})
+}
+
+// setLogin saves a login submitted on the page with the given URL,
+// updating the secret with the given uuid, or creating a new one.
+func (c *keepassxcConn) setLogin(ctx context.Context, m keepassxcMessage) (map[string]any, error) {
+	// set-login carries no key, the association must have been verified during the connection.
+	if !c.verified[m.ID] {
+		return nil, &keepassxcError{code: keepassxc.ErrorAssociationFailed}
+	}
+
+	origin := urlOrigin(m.URL)
+	if len(origin) == 0 {
+		return nil, &keepassxcError{code: keepassxc.ErrorNoURLProvided}
+	}
+
+	name := m.Login
+	if len(name) == 0 {
+		name = urlHost(m.URL)
+	}
+
+	ctx = vault.WithPrincipal(ctx, vault.Principal{Actor: keepassxcActor(m.ID)})
+
+	if err := c.saveLogin(ctx, m.UUID, name, m.Password, origin); err != nil {
+		var kerr *keepassxcError
+		if errors.As(err, &kerr) {
+			return nil, kerr
+		}
+
+		return nil, &keepassxcError{code: keepassxc.ErrorActionCancelledOrDenied, err: err}
+	}
+
+	return c.withHash(map[string]any{"count": nil, "entries": nil, "error": ""})
+}
+
+func (c *keepassxcConn) saveLogin(ctx context.Context, uuid string, name string, password string, origin string) error {
+	if len(uuid) == 0 {
+		_, err := c.s.create(ctx, Secret{Name: name, Secret: password, Labels: []string{origin}})
+		return err
+	}
+
+	id, err := parseKeePassXCUUID(uuid)
+	if err != nil {
+		return err
+	}
+
+	current, err := c.s.show(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &keepassxcError{code: keepassxc.ErrorNoValidUUIDProvided}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if password != current.Secret {
+		if err := c.s.update(ctx, id, password); err != nil {
+			return err
+		}
+	}
+
+	if name != current.Name {
+		return c.s.vault.UpdateSecretMetadata(ctx, id, name, nil, nil)
+	}
+
+	return nil
+}
+
+// databaseGroups returns a single group, secrets are not grouped.
+func (c *keepassxcConn) databaseGroups() (map[string]any, error) {
+	hash, err := c.s.vault.KeePassXCDatabaseHash()
+	if err != nil {
+		return nil, &keepassxcError{code: keepassxc.ErrorDatabaseHashNotReceived}
+	}
+
+	group := map[string]any{"name": "vlt", "uuid": hash[:32], "children": []any{}}
+
+	return map[string]any{"groups": map[string]any{"groups": []any{group}}}, nil
+}
+
+// verify verifies the extension owns the association with the given id.
+func (c *keepassxcConn) verify(ctx context.Context, id string, idKey string) error {
+	ok, err := c.s.vault.VerifyKeePassXCAssociation(ctx, id, idKey)
+	if err != nil {
+		return &keepassxcError{code: keepassxc.ErrorAssociationFailed, err: err}
+	}
+
+	if !ok {
+		return &keepassxcError{code: keepassxc.ErrorAssociationFailed}
+	}
+
+	c.verified[id] = true
+
+	return nil
+}
+
+func (c *keepassxcConn) withHash(params map[string]any) (map[string]any, error) {
+	hash, err := c.s.vault.KeePassXCDatabaseHash()
+	if err != nil {
+		return nil, &keepassxcError{code: keepassxc.ErrorDatabaseHashNotReceived}
+	}
+
+	params["hash"] = hash
+
+	return params, nil
+}
+
+// response returns the encrypted response to an action, using the incremented request nonce.
+func (c *keepassxcConn) response(action string, nonce string, params map[string]any) map[string]any {
+	next, err := keepassxc.IncrementNonce(nonce)
+	if err != nil {
+		return keepassxcErrorResponse(action, &keepassxcError{code: keepassxc.ErrorCannotEncryptMessage})
+	}
+
+	params["version"] = keepassxc.Version
+	params["success"] = "true"
+	params["nonce"] = next
+
+	b, err := json.Marshal(params)
+	if err != nil {
+		return keepassxcErrorResponse(action, &keepassxcError{code: keepassxc.ErrorCannotEncryptMessage})
+	}
+
+	message, err := c.session.Seal(b, next)
+	if err != nil {
+		return keepassxcErrorResponse(action, &keepassxcError{code: keepassxc.ErrorCannotEncryptMessage})
+	}
+
+	return map[string]any{"action": action, "message": message, "nonce": next}
+}
+
+func keepassxcErrorResponse(action string, err error) map[string]any {
+	kerr := &keepassxcError{code: keepassxc.ErrorActionCancelledOrDenied, err: err}
+	errors.As(err, &kerr)
+
+	return map[string]any{
+		"action":    action,
+		"errorCode": strconv.Itoa(int(kerr.code)),
+		"error":     kerr.Error(),
+	}
+}
+
+// keepassxcUUID returns the entry uuid of the secret with the given id.
+func keepassxcUUID(id int) string {
+	return fmt.Sprintf("%032x", id)
+}
+
+func parseKeePassXCUUID(uuid string) (int, error) {
+	id, err := strconv.ParseInt(uuid, 16, 64)
+	if err != nil || id <= 0 {
+		return 0, &keepassxcError{code: keepassxc.ErrorNoValidUUIDProvided}
+	}
+
+	return int(id), nil
+}
+
+func keepassxcActor(id string) string {
+	return "keepassxc:" + id
+}
+
+// urlHost returns the lowercased host of rawURL, without its port,
+// or an empty string if rawURL is not a URL.
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Hostname())
+}
+
+// urlOrigin returns the scheme and host of rawURL,
+// or an empty string if rawURL is not a URL.
+func urlOrigin(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return ""
+	}
+
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
@@ -0,0 +1,216 @@
+package vaultserver
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/keepassxc"
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// keepassxcClient plays the KeePassXC-Browser extension side of a connection.
+type keepassxcClient struct {
+	t    *testing.T
+	conn *keepassxcConn
+
+	publicKey, privateKey *[keepassxc.KeySize]byte
+	serverKey             *[keepassxc.KeySize]byte
+}
+
+func newKeePassXCClient(t *testing.T, s *Server) *keepassxcClient {
+	t.Helper()
+
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &keepassxcClient{
+		t:          t,
+		conn:       &keepassxcConn{s: s, verified: make(map[string]bool)},
+		publicKey:  publicKey,
+		privateKey: privateKey,
+	}
+
+	res := c.conn.handle(t.Context(), c.marshal(map[string]any{
+		"action":    "change-public-keys",
+		"publicKey": c.key(),
+		"nonce":     c.nonce(),
+		"clientID":  "test",
+	}))
+
+	serverKey, err := base64.StdEncoding.DecodeString(res["publicKey"].(string)) //nolint:forcetypeassert
+	if err != nil {
+		t.Fatalf("change-public-keys: %v: %v", err, res)
+	}
+
+	c.serverKey = (*[keepassxc.KeySize]byte)(serverKey)
+
+	return c
+}
+
+// send sends the given encrypted action, returning the decrypted response,
+// or the unencrypted error response.
+func (c *keepassxcClient) send(action string, params map[string]any) map[string]any {
+	c.t.Helper()
+
+	params["action"] = action
+	nonce := c.nonce()
+
+	n, _ := base64.StdEncoding.DecodeString(nonce)
+	sealed := box.Seal(nil, c.marshal(params), (*[keepassxc.NonceSize]byte)(n), c.serverKey, c.privateKey)
+
+	res := c.conn.handle(c.t.Context(), c.marshal(map[string]any{
+		"action":   action,
+		"message":  base64.StdEncoding.EncodeToString(sealed),
+		"nonce":    nonce,
+		"clientID": "test",
+	}))
+
+	if _, ok := res["errorCode"]; ok {
+		return res
+	}
+
+	want, err := keepassxc.IncrementNonce(nonce)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	if res["nonce"] != want {
+		c.t.Fatalf("%s: got nonce %v, want %s", action, res["nonce"], want)
+	}
+
+	b, _ := base64.StdEncoding.DecodeString(res["message"].(string)) //nolint:forcetypeassert
+	n, _ = base64.StdEncoding.DecodeString(want)
+
+	plaintext, ok := box.Open(nil, b, (*[keepassxc.NonceSize]byte)(n), c.serverKey, c.privateKey)
+	if !ok {
+		c.t.Fatalf("%s: cannot decrypt response", action)
+	}
+
+	var decrypted map[string]any
+	if err := json.Unmarshal(plaintext, &decrypted); err != nil {
+		c.t.Fatal(err)
+	}
+
+	return decrypted
+}
+
+func (c *keepassxcClient) key() string {
+	return base64.StdEncoding.EncodeToString(c.publicKey[:])
+}
+
+func (c *keepassxcClient) nonce() string {
+	n := make([]byte, keepassxc.NonceSize)
+	_, _ = rand.Read(n)
+
+	return base64.StdEncoding.EncodeToString(n)
+}
+
+func (c *keepassxcClient) marshal(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestServer_ServeKeePassXC(t *testing.T) {
+	v, err := vault.New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	if _, err := v.InsertNewSecret(t.Context(), "me@example.com", "gh-secret", []string{"https://github.com/login", "personal"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.InsertNewSecret(t.Context(), "other", "other-secret", []string{"github.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the login is the username of the secret metadata, if set.
+	if _, err := v.InsertSecretWithMeta(t.Context(), "gist", "gist-secret", []string{"https://gist.github.com/"}, vaultdb.SecretMeta{Username: "gist@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newKeePassXCClient(t, New(v))
+
+	idKey := base64.StdEncoding.EncodeToString(make([]byte, keepassxc.KeySize))
+
+	if res := c.send("associate", map[string]any{"key": c.key(), "idKey": idKey}); res["errorCode"] != "6" {
+		t.Errorf("associate without pairing: got %v, want error code 6", res)
+	}
+
+	if _, err := v.PairKeePassXC(t.Context(), "firefox", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := c.send("associate", map[string]any{"key": c.key(), "idKey": idKey}); res["id"] != "firefox" || res["success"] != "true" {
+		t.Fatalf("associate: got %v, want id firefox", res)
+	}
+
+	if res := c.send("test-associate", map[string]any{"id": "firefox", "key": c.key()}); res["errorCode"] != "8" {
+		t.Errorf("test-associate using wrong key: got %v, want error code 8", res)
+	}
+
+	if res := c.send("test-associate", map[string]any{"id": "firefox", "key": idKey}); res["id"] != "firefox" {
+		t.Errorf("test-associate: got %v, want id firefox", res)
+	}
+
+	keys := []map[string]string{{"id": "firefox", "key": idKey}}
+
+	res := c.send("get-logins", map[string]any{"url": "https://gist.github.com/", "keys": keys})
+
+	entries, _ := res["entries"].([]any)
+	if len(entries) != 2 {
+		t.Fatalf("get-logins: got %v, want two entries", res)
+	}
+
+	// the exact host match ranks first.
+	if entry, _ := entries[0].(map[string]any); entry["login"] != "gist@example.com" {
+		t.Errorf("get-logins: got first entry %v, want gist@example.com", entry)
+	}
+
+	entry, _ := entries[1].(map[string]any)
+	if entry["login"] != "me@example.com" || entry["password"] != "gh-secret" || entry["uuid"] != keepassxcUUID(1) {
+		t.Errorf("get-logins: got entry %v", entry)
+	}
+
+	if res := c.send("get-logins", map[string]any{"url": "https://example.com/", "keys": keys}); res["errorCode"] != "15" {
+		t.Errorf("get-logins of unknown site: got %v, want error code 15", res)
+	}
+
+	if res := c.send("set-login", map[string]any{"url": "https://example.com/login", "id": "firefox", "login": "me", "password": "new-secret"}); res["success"] != "true" {
+		t.Errorf("set-login: got %v, want success", res)
+	}
+
+	if res := c.send("set-login", map[string]any{"url": "https://github.com/", "id": "firefox", "login": "me@example.com", "password": "rotated", "uuid": keepassxcUUID(1)}); res["success"] != "true" {
+		t.Errorf("set-login update: got %v, want success", res)
+	}
+
+	secrets, err := v.FilterSecrets(t.Context(), "", "", []string{"https://example.com"})
+	if err != nil || len(secrets) != 1 {
+		t.Errorf("saved login: got %v (%v), want a single secret", secrets, err)
+	}
+
+	if value, err := v.ShowSecret(t.Context(), 1); err != nil || value != "rotated" {
+		t.Errorf("updated login: got %q (%v), want %q", value, err, "rotated")
+	}
+
+	// another connection must prove owning the association before saving logins.
+	other := newKeePassXCClient(t, New(v))
+
+	if res := other.send("set-login", map[string]any{"url": "https://example.com/", "id": "firefox", "login": "x", "password": "y"}); res["errorCode"] != "8" {
+		t.Errorf("set-login before verifying: got %v, want error code 8", res)
+	}
+}
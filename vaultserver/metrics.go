@@ -0,0 +1,162 @@
+package vaultserver
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Rejection reasons reported by the rejected requests metric.
+const (
+	rejectClientRate = "client_rate_limit"
+	rejectTokenRate  = "token_rate_limit"
+	rejectBanned     = "banned"
+	rejectAuth       = "auth_failure"
+)
+
+var rejectReasons = []string{rejectClientRate, rejectTokenRate, rejectBanned, rejectAuth}
+
+// requestKey labels the requests metric.
+type requestKey struct {
+	method string
+	code   int
+}
+
+// grpcRequestKey labels the gRPC requests metric.
+type grpcRequestKey struct {
+	method string
+	code   codes.Code
+}
+
+// metrics holds the server counters.
+type metrics struct {
+	rejected map[string]*atomic.Uint64 // rejected counts rejected requests by reason, the map itself is read-only.
+	bans     atomic.Uint64
+
+	mu           sync.Mutex
+	requests     map[requestKey]uint64
+	grpcRequests map[grpcRequestKey]uint64
+	unlocks      map[string]uint64 // unlocks counts authenticated requests by token actor.
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		rejected:     make(map[string]*atomic.Uint64, len(rejectReasons)),
+		requests:     make(map[requestKey]uint64),
+		grpcRequests: make(map[grpcRequestKey]uint64),
+		unlocks:      make(map[string]uint64),
+	}
+
+	for _, reason := range rejectReasons {
+		m.rejected[reason] = &atomic.Uint64{}
+	}
+
+	return m
+}
+
+func (m *metrics) reject(reason string) { m.rejected[reason].Add(1) }
+
+func (m *metrics) request(method string, code int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{method: method, code: code}]++
+}
+
+func (m *metrics) grpcRequest(method string, code codes.Code) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.grpcRequests[grpcRequestKey{method: method, code: code}]++
+}
+
+func (m *metrics) unlock(actor string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.unlocks[actor]++
+}
+
+// vaultGauges are the vault gauges, sampled on scrape.
+type vaultGauges struct {
+	secrets    int
+	lastBackup time.Time // lastBackup is zero if unknown.
+}
+
+// writeTo writes the metrics in the Prometheus text exposition format.
+func (m *metrics) writeTo(w io.Writer, g vaultGauges, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP vlt_serve_requests_total Requests served, by method and status code.")
+	fmt.Fprintln(w, "# TYPE vlt_serve_requests_total counter")
+
+	keys := slices.SortedFunc(maps.Keys(m.requests), func(a, b requestKey) int {
+		return cmp.Or(cmp.Compare(a.method, b.method), cmp.Compare(a.code, b.code))
+	})
+
+	for _, k := range keys {
+		fmt.Fprintf(w, "vlt_serve_requests_total{method=%q,code=\"%d\"} %d\n", k.method, k.code, m.requests[k])
+	}
+
+	fmt.Fprintln(w, "# HELP vlt_serve_grpc_requests_total gRPC calls served, by method and status code.")
+	fmt.Fprintln(w, "# TYPE vlt_serve_grpc_requests_total counter")
+
+	grpcKeys := slices.SortedFunc(maps.Keys(m.grpcRequests), func(a, b grpcRequestKey) int {
+		return cmp.Or(cmp.Compare(a.method, b.method), cmp.Compare(a.code, b.code))
+	})
+
+	for _, k := range grpcKeys {
+		fmt.Fprintf(w, "vlt_serve_grpc_requests_total{method=%q,code=%q} %d\n", k.method, k.code.String(), m.grpcRequests[k])
+	}
+
+	fmt.Fprintln(w, "# HELP vlt_serve_rejected_requests_total Requests rejected before reaching the vault, by reason.")
+	fmt.Fprintln(w, "# TYPE vlt_serve_rejected_requests_total counter")
+
+	for _, reason := range rejectReasons {
+		fmt.Fprintf(w, "vlt_serve_rejected_requests_total{reason=%q} %d\n", reason, m.rejected[reason].Load())
+	}
+
+	fmt.Fprintln(w, "# HELP vlt_serve_client_bans_total Client IPs banned after repeated authentication failures.")
+	fmt.Fprintln(w, "# TYPE vlt_serve_client_bans_total counter")
+	fmt.Fprintf(w, "vlt_serve_client_bans_total %d\n", m.bans.Load())
+
+	fmt.Fprintln(w, "# HELP vlt_serve_unlocks_total Requests unlocking the vault on behalf of an API token, by token.")
+	fmt.Fprintln(w, "# TYPE vlt_serve_unlocks_total counter")
+
+	for _, actor := range slices.Sorted(maps.Keys(m.unlocks)) {
+		fmt.Fprintf(w, "vlt_serve_unlocks_total{token=%q} %d\n", actor, m.unlocks[actor])
+	}
+
+	fmt.Fprintln(w, "# HELP vlt_vault_secrets Secrets stored in the vault.")
+	fmt.Fprintln(w, "# TYPE vlt_vault_secrets gauge")
+	fmt.Fprintf(w, "vlt_vault_secrets %d\n", g.secrets)
+
+	if !g.lastBackup.IsZero() {
+		fmt.Fprintln(w, "# HELP vlt_vault_backup_age_seconds Seconds since the last vault backup.")
+		fmt.Fprintln(w, "# TYPE vlt_vault_backup_age_seconds gauge")
+		fmt.Fprintf(w, "vlt_vault_backup_age_seconds %.0f\n", now.Sub(g.lastBackup).Seconds())
+	}
+}
+
+// statusRecorder records the status code written to the wrapped response writer.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap returns the wrapped response writer, for [http.ResponseController].
+func (r *statusRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
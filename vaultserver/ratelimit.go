@@ -0,0 +1,180 @@
+package vaultserver
+
+import (
+	"sync"
+	"time"
+)
+
+// Limits configure request rate limiting and brute-force protection.
+type Limits struct {
+	ClientRate      float64       // ClientRate is the number of requests per second allowed per client IP, zero disables the limit.
+	TokenRate       float64       // TokenRate is the number of requests per second allowed per API token, zero disables the limit.
+	Burst           int           // Burst is the number of requests allowed to exceed the rates momentarily.
+	MaxAuthFailures int           // MaxAuthFailures is the number of authentication failures after which a client IP is banned, zero disables bans.
+	BanDuration     time.Duration // BanDuration is how long a client IP is banned for, failures older than it are forgotten.
+}
+
+// DefaultLimits are the limits used unless overridden using [WithLimits].
+var DefaultLimits = Limits{
+	ClientRate:      10,
+	TokenRate:       10,
+	Burst:           20,
+	MaxAuthFailures: 5,
+	BanDuration:     15 * time.Minute,
+}
+
+// pruneInterval is how often idle limiter state is discarded.
+const pruneInterval = time.Minute
+
+// rateLimiter is a token bucket rate limiter keyed by client.
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(max(burst, 1)),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow reports whether a request by key is allowed at time now.
+// If not, it returns the duration after which it is.
+func (l *rateLimiter) allow(key string, now time.Time) (time.Duration, bool) {
+	if l.rate <= 0 {
+		return 0, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.prune(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), false
+	}
+
+	b.tokens--
+
+	return 0, true
+}
+
+// prune discards buckets that have refilled completely.
+func (l *rateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < pruneInterval {
+		return
+	}
+
+	l.lastPrune = now
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// banList bans clients after repeated authentication failures.
+type banList struct {
+	mu          sync.Mutex
+	maxFailures int
+	duration    time.Duration
+	clients     map[string]*failures
+	lastPrune   time.Time
+}
+
+type failures struct {
+	count       int
+	last        time.Time
+	bannedUntil time.Time
+}
+
+func newBanList(maxFailures int, duration time.Duration) *banList {
+	return &banList{
+		maxFailures: maxFailures,
+		duration:    duration,
+		clients:     make(map[string]*failures),
+	}
+}
+
+// banned returns the time the ban of client ends,
+// or false if the client is not banned at time now.
+func (b *banList) banned(client string, now time.Time) (time.Time, bool) {
+	if b.maxFailures <= 0 {
+		return time.Time{}, false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	f, ok := b.clients[client]
+	if !ok || !now.Before(f.bannedUntil) {
+		return time.Time{}, false
+	}
+
+	return f.bannedUntil, true
+}
+
+// fail records an authentication failure of client at time now,
+// and reports whether the client got banned.
+func (b *banList) fail(client string, now time.Time) bool {
+	if b.maxFailures <= 0 {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.prune(now)
+
+	f, ok := b.clients[client]
+	if !ok || now.Sub(f.last) >= b.duration {
+		f = &failures{}
+		b.clients[client] = f
+	}
+
+	f.count++
+	f.last = now
+
+	if f.count < b.maxFailures {
+		return false
+	}
+
+	f.count, f.bannedUntil = 0, now.Add(b.duration)
+
+	return true
+}
+
+// prune discards clients that are neither banned nor have recent failures.
+func (b *banList) prune(now time.Time) {
+	if now.Sub(b.lastPrune) < pruneInterval {
+		return
+	}
+
+	b.lastPrune = now
+
+	for client, f := range b.clients {
+		if now.Sub(f.last) >= b.duration && !now.Before(f.bannedUntil) {
+			delete(b.clients, client)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package vaultserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(2, 3)
+	now := time.Now()
+
+	for i := range 3 {
+		if _, ok := l.allow("a", now); !ok {
+			t.Fatalf("request %d within burst rejected", i)
+		}
+	}
+
+	wait, ok := l.allow("a", now)
+	if ok {
+		t.Fatal("request exceeding burst allowed")
+	}
+
+	if wait != 500*time.Millisecond {
+		t.Errorf("wait: got %s, want %s", wait, 500*time.Millisecond)
+	}
+
+	if _, ok := l.allow("b", now); !ok {
+		t.Error("request by another client rejected")
+	}
+
+	if _, ok := l.allow("a", now.Add(wait)); !ok {
+		t.Error("request after refill rejected")
+	}
+}
+
+func TestBanList(t *testing.T) {
+	b := newBanList(3, time.Minute)
+	now := time.Now()
+
+	b.fail("a", now)
+	b.fail("a", now)
+
+	// failures older than the ban duration are forgotten.
+	if b.fail("a", now.Add(time.Minute)) {
+		t.Fatal("banned after expired failures")
+	}
+
+	b.fail("a", now.Add(time.Minute))
+
+	if !b.fail("a", now.Add(time.Minute)) {
+		t.Fatal("not banned after max failures")
+	}
+
+	if _, ok := b.banned("a", now.Add(90*time.Second)); !ok {
+		t.Error("ban lifted early")
+	}
+
+	if _, ok := b.banned("a", now.Add(2*time.Minute)); ok {
+		t.Error("ban not lifted")
+	}
+}
+
+func TestServer_Ban(t *testing.T) {
+	v, err := vault.New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	token, _, err := v.IssueAPIToken(t.Context(), "ci", vault.TokenOptions{Scope: []string{"*"}, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(v, WithLimits(Limits{MaxAuthFailures: 2, BanDuration: time.Minute}))
+
+	do := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/secrets", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	for range 2 {
+		if rec := do("vltapi_invalid"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("invalid token: got status %d, want %d", rec.Code, http.StatusUnauthorized)
+		}
+	}
+
+	rec := do(token)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("banned client: got status %d, retry after %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.RemoteAddr = "192.0.2.2:1234"
+	req.Header.Set("Authorization", "Bearer "+token)
+	s.ServeHTTP(rec, req)
+
+	for _, want := range []string{
+		`vlt_serve_rejected_requests_total{reason="banned"} 1`,
+		`vlt_serve_rejected_requests_total{reason="auth_failure"} 2`,
+		`vlt_serve_client_bans_total 1`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("metrics: got %s, want to contain %s", rec.Body, want)
+		}
+	}
+}
@@ -0,0 +1,576 @@
+// Package vaultserver implements a REST API over an unlocked vault.
+//
+// Every request except the health check must be authenticated with an
+// API token issued by [vault.Vault.IssueAPIToken], passed as a bearer token:
+//
+//	Authorization: Bearer vltapi_<id>_<secret>
+//
+// Requests are performed on behalf of the token, limited to its scope,
+// and attributed to it in the vault audit log.
+//
+// Server and vault metrics are exposed at /metrics in the Prometheus text format.
+//
+// Changes to secrets are streamed as server-sent events at /v1/watch.
+//
+// A minimal web interface over the REST API is optionally served at /ui/.
+//
+// The same operations are served over gRPC, see [Server.GRPCServer], and over
+// JSON-RPC for spawned local tools, see [Server.ServeJSONRPC].
+package vaultserver
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vaulterrors"
+)
+
+// maxBodySize bounds the size of request bodies.
+const maxBodySize = 1 << 20
+
+// Server serves the vault REST API.
+//
+// Requests are serialized, and the vault is synced to disk
+// after each authenticated request.
+//
+// Requests are rate limited per client IP and per API token,
+// and client IPs are temporarily banned after repeated
+// authentication failures, see [Limits].
+type Server struct {
+	mu           sync.Mutex
+	vault        *vault.Vault
+	mux          *http.ServeMux
+	now          func() time.Time
+	limits       Limits
+	clientLimits *rateLimiter
+	tokenLimits  *rateLimiter
+	bans         *banList
+	metrics      *metrics
+	lastBackup   func() (time.Time, bool)
+	bitwarden    *bitwardenState // bitwarden is set if the Bitwarden compatible API is enabled.
+	webui        bool            // webui enables the web interface.
+
+	watchInterval time.Duration
+}
+
+var _ http.Handler = &Server{}
+
+type Option func(*Server)
+
+// WithLimits overrides [DefaultLimits].
+func WithLimits(l Limits) Option {
+	return func(s *Server) {
+		s.limits = l
+	}
+}
+
+// WithLastBackup sets the function returning the time of the last vault backup,
+// exposed as a metric. It returns false if the vault was never backed up.
+func WithLastBackup(f func() (time.Time, bool)) Option {
+	return func(s *Server) {
+		s.lastBackup = f
+	}
+}
+
+// New returns a server for the given unlocked vault.
+func New(v *vault.Vault, opts ...Option) *Server {
+	s := &Server{
+		vault:   v,
+		mux:     http.NewServeMux(),
+		now:     time.Now,
+		limits:  DefaultLimits,
+		metrics: newMetrics(),
+
+		watchInterval: defaultWatchInterval,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.clientLimits = newRateLimiter(s.limits.ClientRate, s.limits.Burst)
+	s.tokenLimits = newRateLimiter(s.limits.TokenRate, s.limits.Burst)
+	s.bans = newBanList(s.limits.MaxAuthFailures, s.limits.BanDuration)
+
+	s.mux.HandleFunc("GET /v1/health", s.health)
+	s.mux.HandleFunc("GET /metrics", s.serveMetrics)
+	s.mux.HandleFunc("GET /v1/secrets", s.authenticated(s.listSecrets))
+	s.mux.HandleFunc("POST /v1/secrets", s.authenticated(s.createSecret))
+	s.mux.HandleFunc("GET /v1/secrets/{id}", s.authenticated(s.showSecret))
+	s.mux.HandleFunc("PUT /v1/secrets/{id}", s.authenticated(s.updateSecret))
+	s.mux.HandleFunc("DELETE /v1/secrets/{id}", s.authenticated(s.deleteSecret))
+	s.mux.HandleFunc("GET /v1/secrets/{id}/totp", s.authenticated(s.showTOTP))
+	s.mux.HandleFunc("GET /v1/watch", s.watch)
+
+	if s.bitwarden != nil {
+		s.handleBitwarden()
+	}
+
+	if s.webui {
+		s.handleWebUI()
+	}
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+	defer func() { s.metrics.request(r.Method, rec.code) }()
+
+	s.serveHTTP(rec, r)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	if rej := s.admit(clientIP(r), s.now()); rej != nil {
+		writeRejection(w, rej)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// clientIP returns the IP address of the client that sent r.
+//
+// Forwarding headers are not trusted, the server is expected
+// to be reached directly.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// Secret is the JSON representation of a vault secret.
+type Secret struct {
+	ID     int      `json:"id"`
+	Name   string   `json:"name"`
+	Labels []string `json:"labels"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+// TOTPCode is the JSON representation of the current code of a TOTP secret.
+type TOTPCode struct {
+	Code             string `json:"code"`
+	RemainingSeconds int    `json:"remaining_seconds"` // RemainingSeconds is how long the code is valid for.
+}
+
+// errorResponse is the body of failed requests. Code is one of [vaulterrors.Code].
+type errorResponse struct {
+	Error string           `json:"error"`
+	Code  vaulterrors.Code `json:"code"`
+}
+
+// handlerFunc handles an authenticated request, returning the response
+// status code and body. A nil body results in an empty response.
+type handlerFunc func(r *http.Request) (int, any, error)
+
+// authenticated wraps h with bearer token authentication.
+//
+// h is called with the vault locked, on behalf of the token principal.
+// The response is written once the vault is synced.
+func (s *Server) authenticated(h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		p, ok := s.authenticate(w, r)
+		if !ok {
+			return
+		}
+
+		r = r.WithContext(vault.WithPrincipal(r.Context(), p))
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+		code, body, err := h(r)
+
+		// sync even on failure, audit entries of reads are recorded regardless.
+		if syncErr := s.vault.Sync(context.WithoutCancel(r.Context())); syncErr != nil {
+			writeError(w, http.StatusInternalServerError, syncErr)
+			return
+		}
+
+		switch {
+		case err != nil:
+			writeError(w, statusCode(err), err)
+		case body == nil:
+			w.WriteHeader(code)
+		default:
+			writeJSON(w, code, body)
+		}
+	}
+}
+
+// authenticate verifies the bearer token of r, and applies the token rate limit.
+// If it fails, the error response is written and false is returned.
+//
+// The caller must hold s.mu.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (vault.Principal, bool) {
+	p, rej := s.authorize(r.Context(), clientIP(r), r.Header.Get("Authorization"))
+	if rej != nil {
+		writeRejection(w, rej)
+		return vault.Principal{}, false
+	}
+
+	return p, true
+}
+
+func (*Server) health(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// serveMetrics writes the server and vault metrics. Any valid API token
+// is accepted, regardless of its scope.
+func (s *Server) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+
+	// sampled outside of the principal context, gauges cover the whole vault.
+	secrets, err := s.vault.FilterSecrets(r.Context(), "*", "", nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	g := vaultGauges{secrets: len(secrets)}
+
+	if s.lastBackup != nil {
+		if t, ok := s.lastBackup(); ok {
+			g.lastBackup = t
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	s.metrics.writeTo(w, g, s.now())
+}
+
+func (s *Server) listSecrets(r *http.Request) (int, any, error) {
+	q := r.URL.Query()
+
+	res, err := s.search(r.Context(), q.Get("q"), q.Get("name"), q["label"])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, res, nil
+}
+
+func (s *Server) createSecret(r *http.Request) (int, any, error) {
+	var req Secret
+	if err := decodeJSON(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	res, err := s.create(r.Context(), req)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusCreated, res, nil
+}
+
+func (s *Server) showSecret(r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	res, err := s.show(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, res, nil
+}
+
+func (s *Server) showTOTP(r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	res, err := s.totp(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, res, nil
+}
+
+func (s *Server) updateSecret(r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req Secret
+	if err := decodeJSON(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	if err := s.update(r.Context(), id, req.Secret); err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+func (s *Server) deleteSecret(r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err := s.delete(r.Context(), id); err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// The operations below are shared by the REST, gRPC and JSON-RPC APIs.
+// They are called with s.mu held, on behalf of the principal of ctx.
+
+// search returns the secrets matching the given filters, without their values.
+// All secrets are returned if no filter is set.
+func (s *Server) search(ctx context.Context, wildcard string, name string, labels []string) ([]Secret, error) {
+	if len(wildcard) == 0 && len(name) == 0 && len(labels) == 0 {
+		wildcard = "*"
+	}
+
+	secrets, err := s.vault.FilterSecrets(ctx, wildcard, name, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Secret, 0, len(secrets))
+	for id, secret := range secrets {
+		res = append(res, Secret{ID: id, Name: secret.Name, Labels: secret.Labels})
+	}
+
+	slices.SortFunc(res, func(a, b Secret) int { return cmp.Compare(a.ID, b.ID) })
+
+	return res, nil
+}
+
+// show returns the secret with the given id, including its value.
+func (s *Server) show(ctx context.Context, id int) (Secret, error) {
+	secret, err := s.metadata(ctx, id)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	secret.Secret, err = s.vault.ShowSecret(ctx, id)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	return secret, nil
+}
+
+// totp returns the current code of the TOTP secret with the given id,
+// see [vault.Vault.TOTPKey].
+func (s *Server) totp(ctx context.Context, id int) (TOTPCode, error) {
+	key, err := s.vault.TOTPKey(ctx, id)
+	if err != nil {
+		return TOTPCode{}, err
+	}
+
+	now := s.now()
+
+	return TOTPCode{Code: key.Code(now), RemainingSeconds: int(key.Remaining(now) / time.Second)}, nil
+}
+
+// metadata returns the secret with the given id, without its value.
+func (s *Server) metadata(ctx context.Context, id int) (Secret, error) {
+	secrets, err := s.vault.SecretsByIDs(ctx, id)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	secret, ok := secrets[id]
+	if !ok {
+		return Secret{}, sql.ErrNoRows
+	}
+
+	return Secret{ID: id, Name: secret.Name, Labels: secret.Labels}, nil
+}
+
+// create creates the given secret, returning it without its value.
+func (s *Server) create(ctx context.Context, secret Secret) (Secret, error) {
+	if len(secret.Name) == 0 || len(secret.Secret) == 0 {
+		return Secret{}, &badRequestError{errors.New("name and secret are required")}
+	}
+
+	id, err := s.vault.InsertNewSecret(ctx, secret.Name, secret.Secret, secret.Labels)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	return Secret{ID: id, Name: secret.Name, Labels: secret.Labels}, nil
+}
+
+// update replaces the value of the secret with the given id.
+func (s *Server) update(ctx context.Context, id int, value string) error {
+	if len(value) == 0 {
+		return &badRequestError{errors.New("secret is required")}
+	}
+
+	n, err := s.vault.UpdateSecret(ctx, id, value)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// put creates the given secret if its id is zero, and replaces the value
+// of the secret with the given id otherwise. It returns the secret without its value.
+func (s *Server) put(ctx context.Context, secret Secret) (Secret, error) {
+	if secret.ID == 0 {
+		return s.create(ctx, secret)
+	}
+
+	if secret.ID < 0 {
+		return Secret{}, &badRequestError{errors.New("invalid secret id")}
+	}
+
+	if err := s.update(ctx, secret.ID, secret.Secret); err != nil {
+		return Secret{}, err
+	}
+
+	return s.metadata(ctx, secret.ID)
+}
+
+// delete deletes the secret with the given id.
+func (s *Server) delete(ctx context.Context, id int) error {
+	n, err := s.vault.DeleteSecretsByIDs(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+type badRequestError struct {
+	err error
+}
+
+func (e *badRequestError) Error() string { return e.err.Error() }
+
+func (e *badRequestError) Unwrap() error { return e.err }
+
+func pathID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		return 0, &badRequestError{errors.New("invalid secret id")}
+	}
+
+	return id, nil
+}
+
+func decodeJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return &badRequestError{err}
+	}
+
+	return nil
+}
+
+// statusCode maps vault errors to http status codes.
+func statusCode(err error) int {
+	var badRequest *badRequestError
+
+	switch {
+	case errors.As(err, &badRequest), errors.Is(err, vaulterrors.ErrNotTOTP):
+		return http.StatusBadRequest
+	case errors.Is(err, vaulterrors.ErrInvalidToken), errors.Is(err, vaulterrors.ErrTokenExpired):
+		return http.StatusUnauthorized
+	case errors.Is(err, vaulterrors.ErrTokenScope), errors.Is(err, vaulterrors.ErrReadOnly):
+		return http.StatusForbidden
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, vaulterrors.ErrSearchNoMatch):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// tooManyRequests writes a 429 response, asking the client to retry after the given duration.
+func tooManyRequests(w http.ResponseWriter, retryAfter time.Duration, err error) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	writeError(w, http.StatusTooManyRequests, err)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	msg := err.Error()
+
+	// avoid leaking internal details, e.g., sql errors.
+	if code == http.StatusInternalServerError {
+		msg = http.StatusText(code)
+	}
+
+	if code == http.StatusNotFound {
+		msg = "secret not found"
+	}
+
+	writeJSON(w, code, errorResponse{Error: msg, Code: errorCode(code, err)})
+}
+
+// errorCode returns the code of err, see [vaulterrors.CodeOf],
+// falling back to the code matching the HTTP status code of the response.
+func errorCode(status int, err error) vaulterrors.Code {
+	if code := vaulterrors.CodeOf(err); code != vaulterrors.CodeInternal {
+		return code
+	}
+
+	switch status {
+	case http.StatusBadRequest:
+		return vaulterrors.CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return vaulterrors.CodeUnauthenticated
+	case http.StatusForbidden:
+		return vaulterrors.CodePermissionDenied
+	case http.StatusNotFound:
+		return vaulterrors.CodeSecretNotFound
+	case http.StatusTooManyRequests:
+		return vaulterrors.CodeRateLimited
+	default:
+		return vaulterrors.CodeInternal
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
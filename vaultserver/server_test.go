@@ -0,0 +1,379 @@
+package vaultserver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+func TestServer(t *testing.T) {
+	v, err := vault.New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	if _, err := v.InsertNewSecret(t.Context(), "deploy", "ci-secret", []string{"ci/deploy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.InsertNewSecret(t.Context(), "email", "personal-secret", []string{"personal"}); err != nil {
+		t.Fatal(err)
+	}
+
+	readWrite, _, err := v.IssueAPIToken(t.Context(), "ci", vault.TokenOptions{Scope: []string{"ci/*"}, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readOnly, _, err := v.IssueAPIToken(t.Context(), "ci-ro", vault.TokenOptions{Scope: []string{"ci/*"}, ReadOnly: true, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(New(v))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{"health", http.MethodGet, "/v1/health", "", "", http.StatusOK, `{"status":"ok"}`},
+		{"no token", http.MethodGet, "/v1/secrets", "", "", http.StatusUnauthorized, `"code":"UNAUTHENTICATED"`},
+		{"invalid token", http.MethodGet, "/v1/secrets", readWrite + "x", "", http.StatusUnauthorized, `"code":"TOKEN_INVALID"`},
+		{"list scoped", http.MethodGet, "/v1/secrets", readOnly, "", http.StatusOK, `[{"id":1,"name":"deploy","labels":["ci/deploy"]}]`},
+		{"show", http.MethodGet, "/v1/secrets/1", readOnly, "", http.StatusOK, `"secret":"ci-secret"`},
+		{"show out of scope", http.MethodGet, "/v1/secrets/2", readOnly, "", http.StatusNotFound, `{"error":"secret not found","code":"SECRET_NOT_FOUND"}`},
+		{"update read-only", http.MethodPut, "/v1/secrets/1", readOnly, `{"secret":"new"}`, http.StatusForbidden, `"code":"READ_ONLY"`},
+		{"delete out of scope", http.MethodDelete, "/v1/secrets/2", readWrite, "", http.StatusForbidden, `"code":"PERMISSION_DENIED"`},
+		{"create out of scope", http.MethodPost, "/v1/secrets", readWrite, `{"name":"x","secret":"s","labels":["other"]}`, http.StatusForbidden, ""},
+		{"create", http.MethodPost, "/v1/secrets", readWrite, `{"name":"x","secret":"s","labels":["ci/x"]}`, http.StatusCreated, `"id":3`},
+		{"update", http.MethodPut, "/v1/secrets/3", readWrite, `{"secret":"new"}`, http.StatusNoContent, ""},
+		{"bad request", http.MethodPut, "/v1/secrets/3", readWrite, `{"value":"new"}`, http.StatusBadRequest, `"code":"INVALID_ARGUMENT"`},
+		{"delete", http.MethodDelete, "/v1/secrets/3", readWrite, "", http.StatusNoContent, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(tt.token) > 0 {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = res.Body.Close() }() //nolint:wsl
+
+			body, _ := io.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wantCode {
+				t.Errorf("status: got %d, want %d (body %s)", res.StatusCode, tt.wantCode, body)
+			}
+
+			if !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("body: got %s, want to contain %s", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	v, err := vault.New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	if _, err := v.InsertNewSecret(t.Context(), "deploy", "ci-secret", []string{"ci/deploy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	token, info, err := v.IssueAPIToken(t.Context(), "ci", vault.TokenOptions{Scope: []string{"none"}, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backupAt := time.Now().Add(-time.Hour)
+
+	srv := httptest.NewServer(New(v, WithLastBackup(func() (time.Time, bool) { return backupAt, true })))
+	defer srv.Close()
+
+	get := func(path string, token string) (int, string) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(token) > 0 {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = res.Body.Close() }() //nolint:wsl
+
+		body, _ := io.ReadAll(res.Body)
+
+		return res.StatusCode, string(body)
+	}
+
+	get("/v1/secrets", "")
+	get("/v1/secrets", token)
+
+	code, body := get("/metrics", token)
+	if code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d (body %s)", code, http.StatusOK, body)
+	}
+
+	for _, want := range []string{
+		`vlt_serve_requests_total{method="GET",code="200"} 1`,
+		`vlt_serve_requests_total{method="GET",code="401"} 1`,
+		`vlt_serve_rejected_requests_total{reason="auth_failure"} 1`,
+		`vlt_serve_unlocks_total{token="api:` + info.ID + `"} 2`,
+		"vlt_vault_secrets 1", // out of the token scope, but counted.
+		"vlt_vault_backup_age_seconds 36",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics: got %s, want to contain %s", body, want)
+		}
+	}
+}
+
+func TestServer_Watch(t *testing.T) {
+	v, err := vault.New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	token, _, err := v.IssueAPIToken(t.Context(), "ci", vault.TokenOptions{Scope: []string{"ci/*"}, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(v)
+	s.watchInterval = 10 * time.Millisecond
+
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+"/v1/watch", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = res.Body.Close() }() //nolint:wsl
+
+	if got := res.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Content-Type: got %q, want text/event-stream", got)
+	}
+
+	r := bufio.NewReader(res.Body)
+
+	// the watch started once the leading comment is received.
+	if line, err := r.ReadString('\n'); err != nil || line != ": watching\n" {
+		t.Fatalf("first line: got %q, %v", line, err)
+	}
+
+	if line, err := r.ReadString('\n'); err != nil || line != "\n" {
+		t.Fatalf("second line: got %q, %v", line, err)
+	}
+
+	if _, err := v.InsertNewSecret(t.Context(), "email", "personal-secret", []string{"personal"}); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := v.InsertNewSecret(t.Context(), "deploy", "ci-secret", []string{"ci/deploy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// out of scope changes are not streamed.
+	readEvent(t, r, "add", `{"type":"add","secret_id":2,"secret_name":"deploy",`)
+
+	if _, err := v.DeleteSecretsByIDs(t.Context(), id); err != nil {
+		t.Fatal(err)
+	}
+
+	readEvent(t, r, "delete", `{"type":"delete","secret_id":2,"secret_name":"deploy",`)
+}
+
+// readEvent reads a server-sent event, checking its name and data prefix.
+func readEvent(t *testing.T, r *bufio.Reader, name string, dataPrefix string) {
+	t.Helper()
+
+	want := []string{"event: " + name + "\n", "data: " + dataPrefix, "\n"}
+
+	for _, w := range want {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(line, w) {
+			t.Fatalf("got line %q, want prefix %q", line, w)
+		}
+	}
+}
+
+func TestServer_WebUI(t *testing.T) {
+	v, err := vault.New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	get := func(srv *httptest.Server, path string) (*http.Response, string) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = res.Body.Close() }() //nolint:wsl
+
+		body, _ := io.ReadAll(res.Body)
+
+		return res, string(body)
+	}
+
+	disabled := httptest.NewServer(New(v))
+	defer disabled.Close()
+
+	if res, _ := get(disabled, "/ui/"); res.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /ui/ (disabled): got status %d, want %d", res.StatusCode, http.StatusNotFound)
+	}
+
+	srv := httptest.NewServer(New(v, WithWebUI()))
+	defer srv.Close()
+
+	res, _ := get(srv, "/")
+	if res.StatusCode != http.StatusFound || res.Header.Get("Location") != "/ui/" {
+		t.Errorf("GET /: got status %d, location %q, want a redirect to /ui/", res.StatusCode, res.Header.Get("Location"))
+	}
+
+	res, body := get(srv, "/ui/")
+	if res.StatusCode != http.StatusOK || !strings.Contains(body, `<script src="app.js"`) {
+		t.Errorf("GET /ui/: got status %d, body %q", res.StatusCode, body)
+	}
+
+	if csp := res.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "script-src 'self'") {
+		t.Errorf("GET /ui/: got Content-Security-Policy %q", csp)
+	}
+
+	if res, _ := get(srv, "/ui/app.js"); !strings.Contains(res.Header.Get("Content-Type"), "javascript") {
+		t.Errorf("GET /ui/app.js: got Content-Type %q", res.Header.Get("Content-Type"))
+	}
+
+	if res, _ := get(srv, "/v1/secrets"); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET /v1/secrets: got status %d, want %d", res.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestServer_TOTP(t *testing.T) {
+	v, err := vault.New(t.Context(), filepath.Join(t.TempDir(), "vault.db"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = v.Close(t.Context()) }() //nolint:wsl
+
+	id, err := v.InsertTOTPSecret(t.Context(), "github", "JBSWY3DPEHPK3PXP", []string{"ci/otp"}, vaultdb.SecretMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.InsertNewSecret(t.Context(), "deploy", "ci-secret", []string{"ci/deploy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	token, _, err := v.IssueAPIToken(t.Context(), "ci", vault.TokenOptions{Scope: []string{"ci/*"}, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := v.TOTPKey(t.Context(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1111111109, 0)
+
+	s := New(v)
+	s.now = func() time.Time { return now }
+
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{"totp", fmt.Sprintf("/v1/secrets/%d/totp", id), http.StatusOK, fmt.Sprintf(`{"code":%q,"remaining_seconds":1}`, key.Code(now))},
+		{"not totp", fmt.Sprintf("/v1/secrets/%d/totp", id+1), http.StatusBadRequest, `"code":"INVALID_ARGUMENT"`},
+		{"missing", fmt.Sprintf("/v1/secrets/%d/totp", id+2), http.StatusNotFound, `"code":"SECRET_NOT_FOUND"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = res.Body.Close() }() //nolint:wsl
+
+			body, _ := io.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wantCode {
+				t.Errorf("status: got %d, want %d (body %s)", res.StatusCode, tt.wantCode, body)
+			}
+
+			if !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("body: got %s, want to contain %s", body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package vaultserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault"
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/webhook"
+)
+
+// Event is the JSON representation of a change to a secret,
+// streamed by the watch endpoint.
+//
+//nolint:tagliatelle
+type Event struct {
+	Type       webhook.Event `json:"type"`
+	SecretID   int           `json:"secret_id"`
+	SecretName string        `json:"secret_name"`
+	Actor      string        `json:"actor,omitempty"` // Actor identifies who made the change, empty for the vault owner.
+	Time       time.Time     `json:"time"`
+}
+
+// watch streams the changes recorded in the audit log after the request,
+// to secrets within the scope of the token, as server-sent events.
+//
+// Each event is named after its type, with the JSON encoded [Event] as data.
+// A comment is sent once the watch started, for clients to sync on.
+func (s *Server) watch(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	p, ok := s.authenticate(w, r)
+	s.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+
+	wt, err := s.newWatcher(ctx, p)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprint(w, ": watching\n\n"); err != nil {
+		return
+	}
+
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		entries, err := wt.poll(ctx)
+		if err != nil {
+			return
+		}
+
+		for _, entry := range entries {
+			typ, _ := webhook.EventOf(entry.Operation)
+
+			data, err := json.Marshal(Event{
+				Type:       typ,
+				SecretID:   entry.SecretID,
+				SecretName: entry.SecretName,
+				Actor:      entry.Actor,
+				Time:       entry.CreatedAt,
+			})
+			if err != nil {
+				return
+			}
+
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", typ, data); err != nil {
+				return
+			}
+		}
+
+		if len(entries) > 0 {
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// watcher tracks the changes of secrets within the scope of a principal.
+type watcher struct {
+	s         *Server
+	principal vault.Principal
+	afterID   int              // afterID is the id of the last audit log entry seen.
+	visible   map[int]struct{} // visible are the ids of the secrets within the scope, to report their deletion.
+}
+
+func (s *Server) newWatcher(ctx context.Context, p vault.Principal) (*watcher, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	head, _, err := s.vault.AuditHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := s.vault.FilterSecrets(vault.WithPrincipal(ctx, p), "*", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &watcher{s: s, principal: p, afterID: head.ID, visible: make(map[int]struct{}, len(secrets))}
+	for id := range secrets {
+		w.visible[id] = struct{}{}
+	}
+
+	return w, nil
+}
+
+// poll returns the audit log entries of the changes recorded since the last poll.
+func (w *watcher) poll(ctx context.Context) ([]vaultdb.AuditEntry, error) {
+	w.s.mu.Lock()
+	defer w.s.mu.Unlock()
+
+	var entries []vaultdb.AuditEntry
+
+	for entry, err := range w.s.vault.AuditLog(ctx, vaultdb.AuditFilters{AfterID: w.afterID}) {
+		if err != nil {
+			return nil, err
+		}
+
+		w.afterID = entry.ID
+
+		if _, ok := webhook.EventOf(entry.Operation); !ok {
+			continue
+		}
+
+		visible, err := w.visibleAfter(ctx, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if !visible {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// visibleAfter reports whether the secret of the entry is within the scope
+// of the principal, updating the visible secrets accordingly.
+// Deleted secrets are reported if they were visible.
+func (w *watcher) visibleAfter(ctx context.Context, entry vaultdb.AuditEntry) (bool, error) {
+	if _, ok := w.visible[entry.SecretID]; ok && entry.Operation == vaultdb.OpDelete {
+		delete(w.visible, entry.SecretID)
+		return true, nil
+	}
+
+	secrets, err := w.s.vault.SecretsByIDs(vault.WithPrincipal(ctx, w.principal), entry.SecretID)
+	if err != nil {
+		return false, err
+	}
+
+	if _, ok := secrets[entry.SecretID]; !ok {
+		delete(w.visible, entry.SecretID)
+		return false, nil
+	}
+
+	w.visible[entry.SecretID] = struct{}{}
+
+	return true, nil
+}
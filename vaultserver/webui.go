@@ -0,0 +1,46 @@
+package vaultserver
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed webui
+var webuiFS embed.FS
+
+// webuiCSP restricts the web UI to its own scripts, styles and API,
+// and forbids framing it.
+const webuiCSP = "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; img-src 'self'; form-action 'none'; frame-ancestors 'none'; base-uri 'none'"
+
+// WithWebUI enables a minimal web interface served at /ui/, for searching,
+// viewing, copying, adding and editing secrets from a browser.
+//
+// The interface is a static page without any vault data. It asks for an
+// API token, kept in the session storage of the browser tab, and calls
+// the REST API on its behalf, so it is limited to the scope of the token.
+func WithWebUI() Option {
+	return func(s *Server) {
+		s.webui = true
+	}
+}
+
+func (s *Server) handleWebUI() {
+	sub, _ := fs.Sub(webuiFS, "webui") // never fails, the directory is embedded.
+	files := http.StripPrefix("/ui/", http.FileServerFS(sub))
+
+	s.mux.Handle("GET /ui/", webuiHeaders(files))
+	s.mux.Handle("GET /{$}", http.RedirectHandler("/ui/", http.StatusFound))
+}
+
+// webuiHeaders sets the security headers of the web UI responses.
+func webuiHeaders(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", webuiCSP)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,318 @@
+// vlt web UI: a thin client of the REST API, on behalf of an API token
+// kept in the session storage of the tab. Secret values are only ever
+// set as text content, never parsed as markup.
+"use strict";
+
+const tokenKey = "vlt-token";
+const clearClipboardAfter = 30 * 1000;
+
+const $ = (id) => document.getElementById(id);
+
+let current = null; // current is the secret shown, including its value.
+let totpTimer = null; // totpTimer counts down the validity of the TOTP code shown.
+
+class APIError extends Error {
+  constructor(status, code, message) {
+    super(message);
+    this.status = status;
+    this.code = code;
+  }
+}
+
+async function api(method, path, body) {
+  const res = await fetch(path, {
+    method,
+    headers: {
+      "Authorization": "Bearer " + sessionStorage.getItem(tokenKey),
+      "Content-Type": "application/json",
+    },
+    body: body === undefined ? undefined : JSON.stringify(body),
+    cache: "no-store",
+    credentials: "omit",
+  });
+
+  if (res.status === 204) {
+    return null;
+  }
+
+  const data = await res.json().catch(() => ({}));
+  if (!res.ok) {
+    throw new APIError(res.status, data.code || "INTERNAL", data.error || res.statusText);
+  }
+
+  return data;
+}
+
+function status(msg, isError) {
+  $("status").textContent = msg || "";
+  $("status").className = isError ? "error" : "";
+}
+
+function fail(err) {
+  if (err instanceof APIError && (err.status === 401 || err.code === "TOKEN_EXPIRED")) {
+    lock();
+    status("The token was rejected: " + err.message, true);
+    return;
+  }
+
+  status(err.message, true);
+}
+
+function show(section) {
+  for (const id of ["view", "editor"]) {
+    $(id).hidden = id !== section;
+  }
+}
+
+function lock() {
+  sessionStorage.removeItem(tokenKey);
+  current = null;
+  hideTOTP();
+  $("secrets").replaceChildren();
+  $("view-secret").textContent = "";
+  $("app").hidden = true;
+  $("lock").hidden = true;
+  $("login").hidden = false;
+  show(null);
+}
+
+async function unlock() {
+  $("login").hidden = true;
+  $("app").hidden = false;
+  $("lock").hidden = false;
+  await search();
+}
+
+async function search() {
+  const term = $("search").value.trim();
+  const q = term === "" ? "" : (/[*?[]/.test(term) ? term : "*" + term + "*");
+
+  try {
+    const secrets = await api("GET", "/v1/secrets" + (q === "" ? "" : "?q=" + encodeURIComponent(q)));
+    renderList(secrets);
+    status(secrets.length === 1 ? "1 secret" : secrets.length + " secrets");
+  } catch (err) {
+    fail(err);
+  }
+}
+
+function renderList(secrets) {
+  const items = secrets.map((s) => {
+    const li = document.createElement("li");
+    const button = document.createElement("button");
+    const name = document.createElement("span");
+    const labels = document.createElement("span");
+
+    button.type = "button";
+    name.textContent = s.name;
+    labels.className = "labels";
+    labels.textContent = (s.labels || []).join(", ");
+    button.append(name, labels);
+    button.addEventListener("click", () => open(s.id));
+    li.append(button);
+
+    return li;
+  });
+
+  $("secrets").replaceChildren(...items);
+}
+
+async function open(id) {
+  try {
+    current = await api("GET", "/v1/secrets/" + id);
+  } catch (err) {
+    fail(err);
+    return;
+  }
+
+  $("view-name").textContent = current.name;
+  $("view-labels").textContent = (current.labels || []).join(", ");
+  conceal();
+  hideTOTP();
+  show("view");
+  status("");
+  await showTOTP(id);
+}
+
+// showTOTP shows the current code of the TOTP secret with the given id,
+// refreshing it once expired. Nothing is shown for other secrets.
+async function showTOTP(id) {
+  let totp;
+
+  try {
+    totp = await api("GET", "/v1/secrets/" + id + "/totp");
+  } catch (err) {
+    // not a TOTP secret.
+    if (!(err instanceof APIError && err.status === 400)) {
+      fail(err);
+    }
+
+    return;
+  }
+
+  // another secret was opened meanwhile.
+  if (current === null || current.id !== id) {
+    return;
+  }
+
+  $("totp-code").textContent = totp.code;
+  $("view-totp").hidden = false;
+  countdown(id, Math.max(totp.remaining_seconds, 1));
+}
+
+function countdown(id, remaining) {
+  if (remaining === 0) {
+    showTOTP(id);
+    return;
+  }
+
+  $("totp-remaining").textContent = "valid for " + remaining + "s";
+  totpTimer = setTimeout(() => countdown(id, remaining - 1), 1000);
+}
+
+function hideTOTP() {
+  clearTimeout(totpTimer);
+  totpTimer = null;
+  $("view-totp").hidden = true;
+  $("totp-code").textContent = "";
+  $("totp-remaining").textContent = "";
+}
+
+function conceal() {
+  $("view-secret").textContent = "••••••••";
+  $("view-secret").className = "masked";
+  $("reveal").textContent = "Reveal";
+}
+
+function toggleReveal() {
+  if ($("view-secret").className !== "masked") {
+    conceal();
+    return;
+  }
+
+  $("view-secret").textContent = current.secret;
+  $("view-secret").className = "";
+  $("reveal").textContent = "Hide";
+}
+
+async function copy() {
+  const value = current.secret;
+
+  try {
+    await navigator.clipboard.writeText(value);
+  } catch (err) {
+    status("Copy failed: " + err.message, true);
+    return;
+  }
+
+  status("Copied, the clipboard is cleared in " + clearClipboardAfter / 1000 + " seconds");
+
+  // best effort: the clipboard can only be written while the page is focused.
+  setTimeout(async () => {
+    try {
+      if (await navigator.clipboard.readText() === value) {
+        await navigator.clipboard.writeText("");
+      }
+    } catch {
+      // not permitted or not focused, nothing to do.
+    }
+  }, clearClipboardAfter);
+}
+
+function openEditor(secret) {
+  const adding = secret === null;
+
+  $("editor-title").textContent = adding ? "Add secret" : "Edit " + secret.name;
+  $("editor-name").value = adding ? "" : secret.name;
+  $("editor-labels").value = adding ? "" : (secret.labels || []).join(", ");
+  $("editor-secret").value = adding ? "" : secret.secret;
+
+  // only the value of existing secrets can be updated over the API.
+  $("editor-name").disabled = !adding;
+  $("editor-labels").disabled = !adding;
+
+  show("editor");
+  (adding ? $("editor-name") : $("editor-secret")).focus();
+}
+
+async function save(event) {
+  event.preventDefault();
+
+  const secret = $("editor-secret").value;
+
+  try {
+    if ($("editor-name").disabled) {
+      await api("PUT", "/v1/secrets/" + current.id, { secret });
+      status("Updated " + current.name);
+      await open(current.id);
+    } else {
+      const labels = $("editor-labels").value.split(",").map((l) => l.trim()).filter((l) => l !== "");
+      const created = await api("POST", "/v1/secrets", { name: $("editor-name").value.trim(), secret, labels });
+      await open(created.id);
+      status("Added " + created.name);
+    }
+  } catch (err) {
+    fail(err);
+    return;
+  } finally {
+    $("editor-secret").value = "";
+  }
+
+  await search();
+}
+
+async function remove() {
+  if (!confirm("Delete " + current.name + "?")) {
+    return;
+  }
+
+  try {
+    await api("DELETE", "/v1/secrets/" + current.id);
+  } catch (err) {
+    fail(err);
+    return;
+  }
+
+  status("Deleted " + current.name);
+  current = null;
+  hideTOTP();
+  show(null);
+  await search();
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  $("login-form").addEventListener("submit", async (event) => {
+    event.preventDefault();
+    sessionStorage.setItem(tokenKey, $("token").value.trim());
+    $("token").value = "";
+    await unlock();
+  });
+
+  $("search-form").addEventListener("submit", (event) => {
+    event.preventDefault();
+    search();
+  });
+
+  let debounce;
+  $("search").addEventListener("input", () => {
+    clearTimeout(debounce);
+    debounce = setTimeout(search, 250);
+  });
+
+  $("lock").addEventListener("click", () => {
+    lock();
+    status("Locked");
+  });
+
+  $("new").addEventListener("click", () => openEditor(null));
+  $("reveal").addEventListener("click", toggleReveal);
+  $("copy").addEventListener("click", copy);
+  $("edit").addEventListener("click", () => openEditor(current));
+  $("delete").addEventListener("click", remove);
+  $("editor-form").addEventListener("submit", save);
+  $("cancel").addEventListener("click", () => show(current === null ? null : "view"));
+
+  if (sessionStorage.getItem(tokenKey)) {
+    unlock();
+  }
+});
@@ -0,0 +1,226 @@
+// Package webhook posts vault events to HTTP endpoints, e.g., of a
+// self-hosted alerting system.
+//
+// Only event metadata is posted; secret values never leave the vault.
+// Payloads are JSON encoded [Payload]s. If an endpoint has a signing key,
+// the request body is signed using HMAC-SHA256, and the hex encoded signature
+// is sent in the [SignatureHeader] header as 'sha256=<signature>'.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+)
+
+const (
+	// SignatureHeader is the header holding the payload signature.
+	SignatureHeader = "X-Vlt-Signature"
+
+	// EventHeader is the header holding the event of the payload.
+	EventHeader = "X-Vlt-Event"
+
+	// defaultTimeout bounds a single delivery.
+	defaultTimeout = 5 * time.Second
+
+	userAgent = "vlt-cli"
+)
+
+// Event is a vault event endpoints are notified of.
+type Event string
+
+const (
+	EventAdd    Event = "add"    // EventAdd is posted when a secret is added.
+	EventUpdate Event = "update" // EventUpdate is posted when the name or labels of a secret are updated.
+	EventRotate Event = "rotate" // EventRotate is posted when the value of a secret is replaced.
+	EventDelete Event = "delete" // EventDelete is posted when a secret is deleted.
+	EventUnlock Event = "unlock" // EventUnlock is posted when the vault is unlocked using the master password or an access token.
+)
+
+// Events lists the supported events.
+var Events = []Event{EventAdd, EventUpdate, EventRotate, EventDelete, EventUnlock}
+
+// IsValidEvent reports whether e is a supported event.
+func IsValidEvent(e string) bool {
+	return slices.Contains(Events, Event(e))
+}
+
+// EventOf returns the event of the given audit log operation,
+// if endpoints are notified of it.
+func EventOf(op vaultdb.Operation) (Event, bool) {
+	switch op { //nolint:exhaustive // read operations are not events.
+	case vaultdb.OpInsert:
+		return EventAdd, true
+	case vaultdb.OpUpdateMetadata:
+		return EventUpdate, true
+	case vaultdb.OpUpdate:
+		return EventRotate, true
+	case vaultdb.OpDelete:
+		return EventDelete, true
+	default:
+		return "", false
+	}
+}
+
+// Payload is the body posted to endpoints.
+//
+//nolint:tagliatelle
+type Payload struct {
+	Event      Event     `json:"event"`
+	Vault      string    `json:"vault"` // Vault is the path of the vault file.
+	Time       time.Time `json:"time"`
+	AuditID    int       `json:"audit_id,omitempty"`    // AuditID is the id of the audit log entry of the event, if recorded.
+	SecretID   int       `json:"secret_id,omitempty"`   // SecretID is zero if the event is not secret specific.
+	SecretName string    `json:"secret_name,omitempty"` // SecretName is the name of the secret at the time of the event.
+	Actor      string    `json:"actor,omitempty"`       // Actor identifies who caused the event, empty for the vault owner.
+}
+
+// Endpoint is an HTTP endpoint notified of vault events.
+type Endpoint struct {
+	Name   string
+	URL    string
+	Events []Event // Events are the events posted to the endpoint, all events if empty.
+	Key    []byte  // Key is the key payloads are signed with, unsigned if empty.
+}
+
+// accepts reports whether the endpoint is notified of e.
+func (ep Endpoint) accepts(e Event) bool {
+	return len(ep.Events) == 0 || slices.Contains(ep.Events, e)
+}
+
+// DeliveryError is returned when a payload could not be delivered to an endpoint.
+type DeliveryError struct {
+	Endpoint string
+	Event    Event
+	Err      error
+}
+
+func (e *DeliveryError) Error() string {
+	return "webhook " + e.Endpoint + ": " + string(e.Event) + ": " + e.Err.Error()
+}
+
+func (e *DeliveryError) Unwrap() error { return e.Err }
+
+// Notifier posts vault events to its endpoints.
+type Notifier struct {
+	endpoints []Endpoint
+	http      *http.Client
+	now       func() time.Time
+}
+
+type Opt func(*Notifier)
+
+// WithHTTPClient sets the http client used for deliveries.
+func WithHTTPClient(c *http.Client) Opt {
+	return func(n *Notifier) {
+		n.http = c
+	}
+}
+
+// New returns a new [Notifier] posting to the given endpoints.
+func New(endpoints []Endpoint, opts ...Opt) *Notifier {
+	n := &Notifier{
+		endpoints: endpoints,
+		http:      &http.Client{Timeout: defaultTimeout},
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
+}
+
+// Write notifies the endpoints of the event of the given audit log entry,
+// if any, implementing the vault audit sink interface.
+func (n *Notifier) Write(vaultPath string, entry vaultdb.AuditEntry) error {
+	event, ok := EventOf(entry.Operation)
+	if !ok {
+		return nil
+	}
+
+	return n.Notify(context.Background(), Payload{
+		Event:      event,
+		Vault:      vaultPath,
+		Time:       entry.CreatedAt,
+		AuditID:    entry.ID,
+		SecretID:   entry.SecretID,
+		SecretName: entry.SecretName,
+		Actor:      entry.Actor,
+	})
+}
+
+// Notify posts p to the endpoints notified of its event.
+// The time of p is set to the current UTC time if zero.
+// Delivery errors are returned as [*DeliveryError]s, once all endpoints were notified.
+func (n *Notifier) Notify(ctx context.Context, p Payload) error {
+	if p.Time.IsZero() {
+		p.Time = n.now()
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+
+	var errs []error
+
+	for _, ep := range n.endpoints {
+		if !ep.accepts(p.Event) {
+			continue
+		}
+
+		if err := n.post(ctx, ep, p.Event, body); err != nil {
+			errs = append(errs, &DeliveryError{Endpoint: ep.Name, Event: p.Event, Err: err})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) post(ctx context.Context, ep Endpoint, event Event, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(event))
+
+	if len(ep.Key) > 0 {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(ep.Key, body))
+	}
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:wsl
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of body using key,
+// as sent in the [SignatureHeader] header.
+func Sign(key []byte, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
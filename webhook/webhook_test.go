@@ -0,0 +1,90 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ladzaretti/vlt-cli/vault/sqlite/vaultdb"
+	"github.com/ladzaretti/vlt-cli/webhook"
+)
+
+type delivery struct {
+	path      string
+	event     string
+	signature string
+	body      []byte
+}
+
+func TestNotifier(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		deliveries []delivery
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		deliveries = append(deliveries, delivery{r.URL.Path, r.Header.Get(webhook.EventHeader), r.Header.Get(webhook.SignatureHeader), body})
+		mu.Unlock()
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	n := webhook.New([]webhook.Endpoint{
+		{Name: "all", URL: srv.URL + "/all", Key: []byte("key")},
+		{Name: "deletes", URL: srv.URL + "/deletes", Events: []webhook.Event{webhook.EventDelete}},
+		{Name: "fail", URL: srv.URL + "/fail", Events: []webhook.Event{webhook.EventRotate}},
+	})
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if err := n.Write("/vault", vaultdb.AuditEntry{ID: 7, Operation: vaultdb.OpShow, SecretID: 1, SecretName: "db"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := n.Write("/vault", vaultdb.AuditEntry{ID: 8, Operation: vaultdb.OpDelete, SecretID: 1, SecretName: "db", Actor: "token:abc", CreatedAt: created}); err != nil {
+		t.Fatal(err)
+	}
+
+	var deliveryErr *webhook.DeliveryError
+	if err := n.Write("/vault", vaultdb.AuditEntry{ID: 9, Operation: vaultdb.OpUpdate, SecretID: 2, SecretName: "api"}); !errors.As(err, &deliveryErr) || deliveryErr.Endpoint != "fail" {
+		t.Errorf("rotate: got err %v, want delivery error of the fail endpoint", err)
+	}
+
+	if len(deliveries) != 4 {
+		t.Fatalf("got %d deliveries, want 4 (show is not an event)", len(deliveries))
+	}
+
+	got := deliveries[0]
+	if got.path != "/all" || got.event != "delete" {
+		t.Errorf("got delivery of %s to %s, want delete to /all", got.event, got.path)
+	}
+
+	if want := "sha256=" + webhook.Sign([]byte("key"), got.body); got.signature != want {
+		t.Errorf("got signature %q, want %q", got.signature, want)
+	}
+
+	var p webhook.Payload
+	if err := json.Unmarshal(got.body, &p); err != nil {
+		t.Fatal(err)
+	}
+
+	want := webhook.Payload{Event: webhook.EventDelete, Vault: "/vault", Time: created, AuditID: 8, SecretID: 1, SecretName: "db", Actor: "token:abc"}
+	if p != want {
+		t.Errorf("got payload %+v, want %+v", p, want)
+	}
+
+	if got := deliveries[1]; got.path != "/deletes" || len(got.signature) > 0 {
+		t.Errorf("got unsigned delivery to %s with signature %q, want /deletes unsigned", got.path, got.signature)
+	}
+}